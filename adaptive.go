@@ -0,0 +1,265 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// defaultAdaptiveMaxConcurrency is the default value of
+	// AdaptiveConcurrencyOptions.MaxConcurrency.
+	defaultAdaptiveMaxConcurrency = 32
+	// defaultAdaptiveDecreaseFactor is the default value of
+	// AdaptiveConcurrencyOptions.DecreaseFactor.
+	defaultAdaptiveDecreaseFactor = 0.5
+	// defaultAdaptiveDecreaseInterval is the default value of
+	// AdaptiveConcurrencyOptions.DecreaseInterval.
+	defaultAdaptiveDecreaseInterval = time.Second
+)
+
+// AdaptiveConcurrencyOptions contains parameters for
+// [oras.NewAdaptiveConcurrency].
+type AdaptiveConcurrencyOptions struct {
+	// MinConcurrency is the lower bound of the concurrency.
+	// If less than or equal to 0, 1 is used.
+	MinConcurrency int
+	// MaxConcurrency is the upper bound of the concurrency.
+	// If less than or equal to 0, a default (currently 32) is used.
+	MaxConcurrency int
+	// InitialConcurrency is the concurrency of a host before any request to
+	// it is observed.
+	// If less than or equal to 0, a default (currently 3) is used.
+	InitialConcurrency int
+	// LatencyThreshold, if greater than 0, treats requests slower than the
+	// threshold as congestion signals. If 0, only failed requests are.
+	LatencyThreshold time.Duration
+	// DecreaseFactor is the factor the concurrency is multiplied by on
+	// congestion. If not in (0, 1), a default (currently 0.5) is used.
+	DecreaseFactor float64
+	// DecreaseInterval is the minimum interval between two decreases of the
+	// concurrency of a host, so that a burst of failures of the requests in
+	// flight decreases the concurrency once.
+	// If less than or equal to 0, a default (currently 1s) is used.
+	DecreaseInterval time.Duration
+}
+
+// AdaptiveConcurrency is a Scheduler tuning the concurrency of copying with
+// the additive-increase/multiplicative-decrease (AIMD) algorithm, based on
+// the latency and the errors of the requests observed per host.
+//
+// The concurrency of a host is increased by 1 after a window of successful
+// requests to the host, and is multiplied by DecreaseFactor on failed
+// requests, on 429 Too Many Requests and 5xx responses, and on requests
+// slower than LatencyThreshold. The effective concurrency is the lowest of
+// all the hosts observed.
+//
+// Requests are observed by the transport returned by Transport, or reported
+// by Observe.
+type AdaptiveConcurrency struct {
+	opts AdaptiveConcurrencyOptions
+
+	lock   sync.Mutex
+	hosts  map[string]*adaptiveHost
+	inUse  int
+	notify chan struct{}
+
+	// now returns the current time. Used for testing.
+	now func() time.Time
+}
+
+// adaptiveHost is the state of a host.
+type adaptiveHost struct {
+	limit        int
+	successes    int
+	lastDecrease time.Time
+}
+
+// NewAdaptiveConcurrency creates a new AdaptiveConcurrency.
+func NewAdaptiveConcurrency(opts AdaptiveConcurrencyOptions) *AdaptiveConcurrency {
+	if opts.MinConcurrency <= 0 {
+		opts.MinConcurrency = 1
+	}
+	if opts.MaxConcurrency <= 0 {
+		opts.MaxConcurrency = defaultAdaptiveMaxConcurrency
+	}
+	if opts.MaxConcurrency < opts.MinConcurrency {
+		opts.MaxConcurrency = opts.MinConcurrency
+	}
+	if opts.InitialConcurrency <= 0 {
+		opts.InitialConcurrency = defaultConcurrency
+	}
+	if opts.InitialConcurrency < opts.MinConcurrency {
+		opts.InitialConcurrency = opts.MinConcurrency
+	}
+	if opts.InitialConcurrency > opts.MaxConcurrency {
+		opts.InitialConcurrency = opts.MaxConcurrency
+	}
+	if opts.DecreaseFactor <= 0 || opts.DecreaseFactor >= 1 {
+		opts.DecreaseFactor = defaultAdaptiveDecreaseFactor
+	}
+	if opts.DecreaseInterval <= 0 {
+		opts.DecreaseInterval = defaultAdaptiveDecreaseInterval
+	}
+	return &AdaptiveConcurrency{
+		opts:   opts,
+		hosts:  make(map[string]*adaptiveHost),
+		notify: make(chan struct{}),
+		now:    time.Now,
+	}
+}
+
+// Limit returns the effective concurrency.
+func (c *AdaptiveConcurrency) Limit() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.limit()
+}
+
+// HostLimit returns the concurrency of the host.
+func (c *AdaptiveConcurrency) HostLimit(host string) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if h, ok := c.hosts[host]; ok {
+		return h.limit
+	}
+	return c.opts.InitialConcurrency
+}
+
+// Observe records the outcome of a request to the host, which took latency
+// to complete. failed reports whether the request failed or was throttled.
+func (c *AdaptiveConcurrency) Observe(host string, latency time.Duration, failed bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	h, ok := c.hosts[host]
+	if !ok {
+		h = &adaptiveHost{
+			limit: c.opts.InitialConcurrency,
+		}
+		c.hosts[host] = h
+	}
+	congested := failed || (c.opts.LatencyThreshold > 0 && latency > c.opts.LatencyThreshold)
+	if congested {
+		now := c.now()
+		if now.Sub(h.lastDecrease) < c.opts.DecreaseInterval {
+			return
+		}
+		h.lastDecrease = now
+		h.successes = 0
+		h.limit = int(float64(h.limit) * c.opts.DecreaseFactor)
+		if h.limit < c.opts.MinConcurrency {
+			h.limit = c.opts.MinConcurrency
+		}
+		return
+	}
+	// increase by 1 after a window of as many successful requests as the
+	// limit
+	h.successes++
+	if h.successes >= h.limit && h.limit < c.opts.MaxConcurrency {
+		h.successes = 0
+		h.limit++
+		c.broadcast()
+	}
+}
+
+// Transport returns an http.RoundTripper observing the requests sent through
+// base. If base is nil, http.DefaultTransport is used.
+func (c *AdaptiveConcurrency) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &adaptiveTransport{
+		base:       base,
+		controller: c,
+	}
+}
+
+// Submit returns the nodes as is.
+func (c *AdaptiveConcurrency) Submit(_ context.Context, nodes []ocispec.Descriptor) []ocispec.Descriptor {
+	return nodes
+}
+
+// Acquire blocks until the number of nodes being processed is below the
+// effective concurrency or ctx is done.
+func (c *AdaptiveConcurrency) Acquire(ctx context.Context, _ ocispec.Descriptor) error {
+	for {
+		c.lock.Lock()
+		if c.inUse < c.limit() {
+			c.inUse++
+			c.lock.Unlock()
+			return nil
+		}
+		notify := c.notify
+		c.lock.Unlock()
+
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Release releases the slot acquired for the node.
+func (c *AdaptiveConcurrency) Release(_ ocispec.Descriptor) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.inUse--
+	c.broadcast()
+}
+
+// limit returns the effective concurrency, which is the lowest concurrency
+// of all the hosts. The caller must hold the lock.
+func (c *AdaptiveConcurrency) limit() int {
+	if len(c.hosts) == 0 {
+		return c.opts.InitialConcurrency
+	}
+	limit := c.opts.MaxConcurrency
+	for _, h := range c.hosts {
+		if h.limit < limit {
+			limit = h.limit
+		}
+	}
+	return limit
+}
+
+// broadcast wakes up the goroutines waiting in Acquire.
+// The caller must hold the lock.
+func (c *AdaptiveConcurrency) broadcast() {
+	close(c.notify)
+	c.notify = make(chan struct{})
+}
+
+// adaptiveTransport observes the requests for an AdaptiveConcurrency.
+type adaptiveTransport struct {
+	base       http.RoundTripper
+	controller *AdaptiveConcurrency
+}
+
+// RoundTrip sends the request and observes its outcome.
+func (t *adaptiveTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := t.controller.now()
+	resp, err := t.base.RoundTrip(req)
+	failed := err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+	t.controller.Observe(req.URL.Host, t.controller.now().Sub(start), failed)
+	return resp, err
+}
@@ -0,0 +1,171 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestAdaptiveConcurrency_AIMD(t *testing.T) {
+	c := NewAdaptiveConcurrency(AdaptiveConcurrencyOptions{
+		MaxConcurrency:     8,
+		InitialConcurrency: 4,
+		LatencyThreshold:   time.Second,
+	})
+	now := time.Unix(0, 0)
+	c.now = func() time.Time { return now }
+
+	if got, want := c.Limit(), 4; got != want {
+		t.Fatalf("Limit() = %d, want %d", got, want)
+	}
+
+	// additive increase: 4 successes per step at limit 4
+	for i := 0; i < 4; i++ {
+		c.Observe("a", time.Millisecond, false)
+	}
+	if got, want := c.HostLimit("a"), 5; got != want {
+		t.Errorf("HostLimit() = %d, want %d", got, want)
+	}
+	for i := 0; i < 100; i++ {
+		c.Observe("a", time.Millisecond, false)
+	}
+	if got, want := c.HostLimit("a"), 8; got != want {
+		t.Errorf("HostLimit() = %d, want %d", got, want)
+	}
+
+	// multiplicative decrease once per interval
+	c.Observe("a", time.Millisecond, true)
+	c.Observe("a", time.Millisecond, true)
+	if got, want := c.HostLimit("a"), 4; got != want {
+		t.Errorf("HostLimit() = %d, want %d", got, want)
+	}
+	now = now.Add(2 * time.Second)
+	c.Observe("a", 2*time.Second, false) // slow request
+	if got, want := c.HostLimit("a"), 2; got != want {
+		t.Errorf("HostLimit() = %d, want %d", got, want)
+	}
+	now = now.Add(2 * time.Second)
+	for i := 0; i < 5; i++ {
+		now = now.Add(2 * time.Second)
+		c.Observe("a", time.Millisecond, true)
+	}
+	if got, want := c.HostLimit("a"), 1; got != want {
+		t.Errorf("HostLimit() = %d, want %d", got, want)
+	}
+
+	// the effective limit is the lowest of all hosts
+	c.Observe("b", time.Millisecond, false)
+	if got, want := c.Limit(), 1; got != want {
+		t.Errorf("Limit() = %d, want %d", got, want)
+	}
+	if got, want := c.HostLimit("b"), 4; got != want {
+		t.Errorf("HostLimit() = %d, want %d", got, want)
+	}
+}
+
+func TestAdaptiveConcurrency_Acquire(t *testing.T) {
+	ctx := context.Background()
+	c := NewAdaptiveConcurrency(AdaptiveConcurrencyOptions{
+		InitialConcurrency: 1,
+	})
+	node := ocispec.Descriptor{}
+	if err := c.Acquire(ctx, node); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- c.Acquire(ctx, node)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("Acquire() returned beyond the limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+	// increasing the limit unblocks the waiter
+	c.Observe("a", time.Millisecond, false)
+	if err := <-acquired; err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := c.Acquire(cancelCtx, node); err == nil {
+		t.Error("Acquire() error = nil, want context error")
+	}
+	c.Release(node)
+	c.Release(node)
+	if err := c.Acquire(ctx, node); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+}
+
+func TestAdaptiveConcurrency_Transport(t *testing.T) {
+	status := http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewAdaptiveConcurrency(AdaptiveConcurrencyOptions{
+		InitialConcurrency: 4,
+	})
+	client := &http.Client{Transport: c.Transport(nil)}
+	get := func() {
+		resp, err := client.Get(ts.URL)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		resp.Body.Close()
+	}
+	get()
+	if got := c.HostLimit(u.Host); got != 4 {
+		t.Errorf("HostLimit() = %d, want %d", got, 4)
+	}
+	status = http.StatusTooManyRequests
+	get()
+	if got := c.HostLimit(u.Host); got != 2 {
+		t.Errorf("HostLimit() = %d, want %d", got, 2)
+	}
+}
+
+func TestCopyGraph_AdaptiveConcurrency(t *testing.T) {
+	ctx := context.Background()
+	src, root := pushImage(t, []byte(`{}`), []byte("hello world"), "latest")
+	dst := memory.New()
+
+	c := NewAdaptiveConcurrency(AdaptiveConcurrencyOptions{
+		InitialConcurrency: 1,
+	})
+	if err := CopyGraph(ctx, src, dst, root, CopyGraphOptions{Scheduler: c}); err != nil {
+		t.Fatalf("CopyGraph() error = %v", err)
+	}
+	if exists, err := dst.Exists(ctx, root); err != nil || !exists {
+		t.Errorf("dst.Exists() = %v, %v, want true", exists, err)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/spec"
+)
+
+// AnnotateOptions contains parameters for [oras.Annotate].
+type AnnotateOptions struct {
+	// Set adds the annotations, overwriting the existing values of the same
+	// keys.
+	Set map[string]string
+	// Remove removes the annotations of the keys.
+	// Annotations in Set are not removed.
+	Remove []string
+	// Tags are the tags to be tagged to the new manifest, such as the
+	// reference itself to move it to the new manifest.
+	Tags []string
+}
+
+// Annotate fetches the manifest or the index identified by reference in the
+// target, applies the annotation changes, and pushes the result as a new
+// manifest or index, optionally tagged by opts.Tags.
+//
+// All the successors, such as configs, layers and child manifests, are reused
+// as is, and fields other than the annotations are kept unchanged.
+// Existing referrers of the original manifest do not refer to the new one.
+// Returns the descriptor of the new manifest or index on success.
+func Annotate(ctx context.Context, target Target, reference string, opts AnnotateOptions) (ocispec.Descriptor, error) {
+	if target == nil {
+		return ocispec.Descriptor{}, errors.New("nil target")
+	}
+	desc, err := target.Resolve(ctx, reference)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageManifest, ocispec.MediaTypeImageIndex, spec.MediaTypeArtifactManifest:
+	default:
+		return ocispec.Descriptor{}, fmt.Errorf("%s: %s: annotations not supported: %w", desc.Digest, desc.MediaType, errdef.ErrUnsupported)
+	}
+
+	manifestJSON, err := content.FetchAll(ctx, target, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	var manifest map[string]json.RawMessage
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to decode %s: %s: %w", desc.Digest, desc.MediaType, err)
+	}
+	var annotations map[string]string
+	if raw, ok := manifest["annotations"]; ok {
+		if err := json.Unmarshal(raw, &annotations); err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("failed to decode %s: %s: %w", desc.Digest, desc.MediaType, err)
+		}
+	}
+	annotations = applyAnnotationChanges(annotations, opts)
+	if len(annotations) == 0 {
+		annotations = nil
+		delete(manifest, "annotations")
+	} else if manifest["annotations"], err = json.Marshal(annotations); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if manifestJSON, err = json.Marshal(manifest); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	newDesc := content.NewDescriptorFromBytes(desc.MediaType, manifestJSON)
+	newDesc.ArtifactType = desc.ArtifactType
+	newDesc.Annotations = annotations
+	if err := target.Push(ctx, newDesc, bytes.NewReader(manifestJSON)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to push %s: %w", newDesc.Digest, err)
+	}
+	for _, tag := range opts.Tags {
+		if err := target.Tag(ctx, newDesc, tag); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+	return newDesc, nil
+}
+
+// applyAnnotationChanges returns a copy of annotations with the changes in
+// opts applied.
+func applyAnnotationChanges(annotations map[string]string, opts AnnotateOptions) map[string]string {
+	result := make(map[string]string, len(annotations)+len(opts.Set))
+	for k, v := range annotations {
+		result[k] = v
+	}
+	for _, k := range opts.Remove {
+		delete(result, k)
+	}
+	for k, v := range opts.Set {
+		result[k] = v
+	}
+	return result
+}
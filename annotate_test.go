@@ -0,0 +1,136 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/docker"
+	"oras.land/oras-go/v2/orastest"
+)
+
+func TestAnnotate(t *testing.T) {
+	ctx := context.Background()
+	src, manifestDesc := pushImage(t, []byte("{}"), []byte("layer"), "v1")
+	target := orastest.NewTarget()
+	if _, err := Copy(ctx, src, "v1", target, "", DefaultCopyOptions); err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+	target.Reset()
+
+	desc, err := Annotate(ctx, target, "v1", AnnotateOptions{
+		Set:  map[string]string{"foo": "bar", "hello": "world"},
+		Tags: []string{"v1", "v2"},
+	})
+	if err != nil {
+		t.Fatal("Annotate() error =", err)
+	}
+	wantAnnotations := map[string]string{"foo": "bar", "hello": "world"}
+	if !reflect.DeepEqual(desc.Annotations, wantAnnotations) {
+		t.Errorf("Annotate() annotations = %v, want %v", desc.Annotations, wantAnnotations)
+	}
+	// only the new manifest is pushed
+	if calls := target.CallsOf(orastest.MethodPush); len(calls) != 1 || calls[0].Descriptor.Digest != desc.Digest {
+		t.Errorf("Push() calls = %v, want only %s", calls, desc.Digest)
+	}
+	for _, tag := range []string{"v1", "v2"} {
+		if got, err := target.Resolve(ctx, tag); err != nil || got.Digest != desc.Digest {
+			t.Errorf("Resolve(%s) = %v, %v, want %s", tag, got, err, desc.Digest)
+		}
+	}
+
+	// the other fields are unchanged
+	oldJSON, err := content.FetchAll(ctx, src, manifestDesc)
+	if err != nil {
+		t.Fatal("FetchAll() error =", err)
+	}
+	newJSON, err := content.FetchAll(ctx, target, desc)
+	if err != nil {
+		t.Fatal("FetchAll() error =", err)
+	}
+	var oldManifest, newManifest ocispec.Manifest
+	if err := json.Unmarshal(oldJSON, &oldManifest); err != nil {
+		t.Fatal("json.Unmarshal() error =", err)
+	}
+	if err := json.Unmarshal(newJSON, &newManifest); err != nil {
+		t.Fatal("json.Unmarshal() error =", err)
+	}
+	if !reflect.DeepEqual(newManifest.Annotations, wantAnnotations) {
+		t.Errorf("Manifest.Annotations = %v, want %v", newManifest.Annotations, wantAnnotations)
+	}
+	newManifest.Annotations = nil
+	if !reflect.DeepEqual(newManifest, oldManifest) {
+		t.Errorf("Manifest = %v, want %v", newManifest, oldManifest)
+	}
+
+	// remove and overwrite annotations
+	desc, err = Annotate(ctx, target, "v2", AnnotateOptions{
+		Set:    map[string]string{"foo": "baz"},
+		Remove: []string{"hello", "foo"},
+	})
+	if err != nil {
+		t.Fatal("Annotate() error =", err)
+	}
+	if want := map[string]string{"foo": "baz"}; !reflect.DeepEqual(desc.Annotations, want) {
+		t.Errorf("Annotate() annotations = %v, want %v", desc.Annotations, want)
+	}
+	if got, err := target.Resolve(ctx, "v2"); err != nil || got.Digest == desc.Digest {
+		t.Errorf("Resolve(v2) = %v, %v, want untouched", got, err)
+	}
+
+	// removing all annotations drops the field
+	desc, err = Annotate(ctx, target, "v1", AnnotateOptions{
+		Remove: []string{"hello", "foo"},
+	})
+	if err != nil {
+		t.Fatal("Annotate() error =", err)
+	}
+	if len(desc.Annotations) != 0 {
+		t.Errorf("Annotate() annotations = %v, want none", desc.Annotations)
+	}
+	newJSON, err = content.FetchAll(ctx, target, desc)
+	if err != nil {
+		t.Fatal("FetchAll() error =", err)
+	}
+	if bytes.Contains(newJSON, []byte(`"annotations"`)) {
+		t.Errorf("manifest %s contains annotations", newJSON)
+	}
+}
+
+func TestAnnotate_Unsupported(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	manifestJSON := []byte(`{"schemaVersion":2,"mediaType":"` + docker.MediaTypeManifest + `"}`)
+	desc := content.NewDescriptorFromBytes(docker.MediaTypeManifest, manifestJSON)
+	if err := s.Push(ctx, desc, bytes.NewReader(manifestJSON)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	if err := s.Tag(ctx, desc, "v1"); err != nil {
+		t.Fatal("Tag() error =", err)
+	}
+	if _, err := Annotate(ctx, s, "v1", AnnotateOptions{Set: map[string]string{"foo": "bar"}}); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Annotate() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package annotation provides typed accessors to the well-known annotations,
+// so that the annotations are set and read uniformly.
+// Reference: https://github.com/opencontainers/image-spec/blob/v1.1.0-rc3/annotations.md#pre-defined-annotation-keys
+package annotation
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// KeyUnpackDigest is the annotation key for the digest of the
+	// uncompressed content of a directory packed as a tarball.
+	KeyUnpackDigest = "io.deis.oras.content.digest"
+
+	// KeyUnpack is the annotation key for the indication that the content
+	// needs to be unpacked as a directory.
+	KeyUnpack = "io.deis.oras.content.unpack"
+)
+
+// ErrInvalidDateTimeFormat is returned when the value of a date and time
+// annotation, such as org.opencontainers.image.created, does not conform to
+// RFC 3339.
+var ErrInvalidDateTimeFormat = errors.New("invalid date and time format")
+
+// Title returns the value of the org.opencontainers.image.title annotation,
+// which is the human-readable title of the content, or the file name of a
+// layer.
+func Title(annotations map[string]string) string {
+	return annotations[ocispec.AnnotationTitle]
+}
+
+// WithTitle returns a copy of annotations with the
+// org.opencontainers.image.title annotation set to title.
+func WithTitle(annotations map[string]string, title string) map[string]string {
+	return with(annotations, ocispec.AnnotationTitle, title)
+}
+
+// Source returns the value of the org.opencontainers.image.source annotation,
+// which is the URL to get the source code for building the content.
+func Source(annotations map[string]string) string {
+	return annotations[ocispec.AnnotationSource]
+}
+
+// WithSource returns a copy of annotations with the
+// org.opencontainers.image.source annotation set to source.
+func WithSource(annotations map[string]string, source string) map[string]string {
+	return with(annotations, ocispec.AnnotationSource, source)
+}
+
+// Created returns the time of the org.opencontainers.image.created
+// annotation. The returned bool is false if the annotation is absent.
+// Returns ErrInvalidDateTimeFormat if the value does not conform to RFC 3339.
+func Created(annotations map[string]string) (time.Time, bool, error) {
+	return parseTime(annotations, ocispec.AnnotationCreated)
+}
+
+// WithCreated returns a copy of annotations with the
+// org.opencontainers.image.created annotation set to t in RFC 3339 format.
+func WithCreated(annotations map[string]string, t time.Time) map[string]string {
+	return with(annotations, ocispec.AnnotationCreated, t.UTC().Format(time.RFC3339))
+}
+
+// ArtifactCreated returns the time of the
+// org.opencontainers.artifact.created annotation of artifact manifests.
+// The returned bool is false if the annotation is absent.
+// Returns ErrInvalidDateTimeFormat if the value does not conform to RFC 3339.
+func ArtifactCreated(annotations map[string]string) (time.Time, bool, error) {
+	return parseTime(annotations, ocispec.AnnotationArtifactCreated)
+}
+
+// WithArtifactCreated returns a copy of annotations with the
+// org.opencontainers.artifact.created annotation set to t in RFC 3339 format.
+func WithArtifactCreated(annotations map[string]string, t time.Time) map[string]string {
+	return with(annotations, ocispec.AnnotationArtifactCreated, t.UTC().Format(time.RFC3339))
+}
+
+// NeedsUnpack returns true if the content is a packed directory to be
+// unpacked, as indicated by the io.deis.oras.content.unpack annotation.
+func NeedsUnpack(annotations map[string]string) bool {
+	return annotations[KeyUnpack] == "true"
+}
+
+// UnpackDigest returns the digest of the uncompressed content of a packed
+// directory, as recorded by the io.deis.oras.content.digest annotation.
+// Returns an empty digest if the annotation is absent.
+func UnpackDigest(annotations map[string]string) (digest.Digest, error) {
+	value, ok := annotations[KeyUnpackDigest]
+	if !ok {
+		return "", nil
+	}
+	dgst, err := digest.Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid annotation %s: %w", KeyUnpackDigest, err)
+	}
+	return dgst, nil
+}
+
+// WithUnpack returns a copy of annotations indicating that the content is a
+// packed directory to be unpacked, whose uncompressed content is digested as
+// unpackDigest. The digest annotation is omitted if unpackDigest is empty.
+func WithUnpack(annotations map[string]string, unpackDigest digest.Digest) map[string]string {
+	copied := with(annotations, KeyUnpack, "true")
+	if unpackDigest != "" {
+		copied[KeyUnpackDigest] = unpackDigest.String()
+	}
+	return copied
+}
+
+// parseTime parses the value of the annotation key in RFC 3339 format.
+func parseTime(annotations map[string]string, key string) (time.Time, bool, error) {
+	value, ok := annotations[key]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, true, fmt.Errorf("%w: %v", ErrInvalidDateTimeFormat, err)
+	}
+	return t, true, nil
+}
+
+// with returns a copy of annotations with key set to value.
+func with(annotations map[string]string, key, value string) map[string]string {
+	copied := make(map[string]string, len(annotations)+1)
+	for k, v := range annotations {
+		copied[k] = v
+	}
+	copied[key] = value
+	return copied
+}
@@ -0,0 +1,133 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package annotation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestTitle(t *testing.T) {
+	if got := Title(nil); got != "" {
+		t.Errorf("Title() = %q, want empty", got)
+	}
+	original := map[string]string{"foo": "bar"}
+	annotations := WithTitle(original, "hello.txt")
+	want := map[string]string{
+		"foo":                   "bar",
+		ocispec.AnnotationTitle: "hello.txt",
+	}
+	if !reflect.DeepEqual(annotations, want) {
+		t.Errorf("WithTitle() = %v, want %v", annotations, want)
+	}
+	if _, ok := original[ocispec.AnnotationTitle]; ok {
+		t.Error("WithTitle() modified the original annotations")
+	}
+	if got := Title(annotations); got != "hello.txt" {
+		t.Errorf("Title() = %q, want %q", got, "hello.txt")
+	}
+}
+
+func TestSource(t *testing.T) {
+	annotations := WithSource(nil, "https://github.com/oras-project/oras-go")
+	if got := annotations[ocispec.AnnotationSource]; got != "https://github.com/oras-project/oras-go" {
+		t.Errorf("WithSource() = %v", annotations)
+	}
+	if got := Source(annotations); got != "https://github.com/oras-project/oras-go" {
+		t.Errorf("Source() = %q", got)
+	}
+}
+
+func TestCreated(t *testing.T) {
+	// absent
+	if _, ok, err := Created(nil); ok || err != nil {
+		t.Errorf("Created() = _, %v, %v, want false, nil", ok, err)
+	}
+
+	// round trip
+	now := time.Date(2023, 5, 1, 10, 30, 0, 0, time.FixedZone("UTC+8", 8*3600))
+	annotations := WithCreated(nil, now)
+	if got, want := annotations[ocispec.AnnotationCreated], "2023-05-01T02:30:00Z"; got != want {
+		t.Errorf("WithCreated() = %q, want %q", got, want)
+	}
+	got, ok, err := Created(annotations)
+	if err != nil || !ok {
+		t.Fatalf("Created() = _, %v, %v, want true, nil", ok, err)
+	}
+	if !got.Equal(now) {
+		t.Errorf("Created() = %v, want %v", got, now)
+	}
+
+	// invalid
+	annotations[ocispec.AnnotationCreated] = "2023-05-01"
+	if _, ok, err := Created(annotations); !ok || !errors.Is(err, ErrInvalidDateTimeFormat) {
+		t.Errorf("Created() = _, %v, %v, want true, %v", ok, err, ErrInvalidDateTimeFormat)
+	}
+}
+
+func TestArtifactCreated(t *testing.T) {
+	now := time.Date(2023, 5, 1, 10, 30, 0, 0, time.UTC)
+	annotations := WithArtifactCreated(nil, now)
+	if _, ok := annotations[ocispec.AnnotationCreated]; ok {
+		t.Errorf("WithArtifactCreated() = %v, want no image created annotation", annotations)
+	}
+	got, ok, err := ArtifactCreated(annotations)
+	if err != nil || !ok {
+		t.Fatalf("ArtifactCreated() = _, %v, %v, want true, nil", ok, err)
+	}
+	if !got.Equal(now) {
+		t.Errorf("ArtifactCreated() = %v, want %v", got, now)
+	}
+}
+
+func TestUnpack(t *testing.T) {
+	if NeedsUnpack(nil) {
+		t.Error("NeedsUnpack() = true, want false")
+	}
+	if dgst, err := UnpackDigest(nil); err != nil || dgst != "" {
+		t.Errorf("UnpackDigest() = %v, %v, want empty", dgst, err)
+	}
+
+	dgst := digest.FromString("hello")
+	annotations := WithUnpack(nil, dgst)
+	want := map[string]string{
+		KeyUnpack:       "true",
+		KeyUnpackDigest: dgst.String(),
+	}
+	if !reflect.DeepEqual(annotations, want) {
+		t.Errorf("WithUnpack() = %v, want %v", annotations, want)
+	}
+	if !NeedsUnpack(annotations) {
+		t.Error("NeedsUnpack() = false, want true")
+	}
+	if got, err := UnpackDigest(annotations); err != nil || got != dgst {
+		t.Errorf("UnpackDigest() = %v, %v, want %v", got, err, dgst)
+	}
+
+	annotations = WithUnpack(nil, "")
+	if _, ok := annotations[KeyUnpackDigest]; ok {
+		t.Errorf("WithUnpack() = %v, want no digest annotation", annotations)
+	}
+	annotations[KeyUnpackDigest] = "invalid"
+	if _, err := UnpackDigest(annotations); err == nil {
+		t.Error("UnpackDigest() error = nil, want error")
+	}
+}
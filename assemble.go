@@ -0,0 +1,130 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/docker"
+	"oras.land/oras-go/v2/internal/platform"
+)
+
+// PlatformImage is a single-platform image to be assembled into an index by
+// AssembleIndex.
+type PlatformImage struct {
+	// Source is the target holding the image.
+	Source ReadOnlyTarget
+	// Reference is the reference of the image manifest in Source.
+	Reference string
+	// Platform is the platform of the image.
+	// If nil, the platform is read from the image config.
+	Platform *ocispec.Platform
+}
+
+// AssembleIndexOptions contains parameters for [oras.AssembleIndex].
+type AssembleIndexOptions struct {
+	CopyGraphOptions
+	// ManifestAnnotations is the annotation map of the index.
+	ManifestAnnotations map[string]string
+}
+
+// AssembleIndex copies the single-platform images, possibly from different
+// sources, to the destination, and then creates an image index referencing
+// the copied image manifests with their platforms, tagged by dstRef in the
+// destination.
+//
+// The images must be image manifests of distinct platforms. If the platform
+// of an image is not specified, it is populated from the image config.
+// Returns the descriptor of the index on success.
+func AssembleIndex(ctx context.Context, images []PlatformImage, dst Target, dstRef string, opts AssembleIndexOptions) (ocispec.Descriptor, error) {
+	if len(images) == 0 {
+		return ocispec.Descriptor{}, errors.New("no images to assemble")
+	}
+	if dst == nil {
+		return ocispec.Descriptor{}, errors.New("nil destination target")
+	}
+
+	manifests := make([]ocispec.Descriptor, 0, len(images))
+	for _, image := range images {
+		if image.Source == nil {
+			return ocispec.Descriptor{}, fmt.Errorf("%s: nil source target", image.Reference)
+		}
+		desc, err := image.Source.Resolve(ctx, image.Reference)
+		if err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("failed to resolve %s: %w", image.Reference, err)
+		}
+		switch desc.MediaType {
+		case ocispec.MediaTypeImageManifest, docker.MediaTypeManifest:
+		default:
+			return ocispec.Descriptor{}, fmt.Errorf("%s: %s: %s: not a single-platform image: %w", image.Reference, desc.Digest, desc.MediaType, errdef.ErrUnsupported)
+		}
+
+		p := image.Platform
+		if p == nil {
+			if p, err = platform.FromManifest(ctx, image.Source, desc); err != nil {
+				return ocispec.Descriptor{}, fmt.Errorf("failed to get platform of %s: %w", image.Reference, err)
+			}
+		}
+		if p.OS == "" || p.Architecture == "" {
+			return ocispec.Descriptor{}, fmt.Errorf("%s: missing platform os or architecture", image.Reference)
+		}
+		for _, m := range manifests {
+			if platform.Match(m.Platform, p) && platform.Match(p, m.Platform) {
+				return ocispec.Descriptor{}, fmt.Errorf("%s: duplicate platform %s/%s", image.Reference, p.OS, p.Architecture)
+			}
+		}
+
+		if err := CopyGraph(ctx, image.Source, dst, desc, opts.CopyGraphOptions); err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("failed to copy %s: %w", image.Reference, err)
+		}
+		manifests = append(manifests, ocispec.Descriptor{
+			MediaType: desc.MediaType,
+			Digest:    desc.Digest,
+			Size:      desc.Size,
+			Platform:  p,
+		})
+	}
+
+	index := ocispec.Index{
+		Versioned: specs.Versioned{
+			SchemaVersion: 2, // historical value. does not pertain to OCI or docker version
+		},
+		MediaType:   ocispec.MediaTypeImageIndex,
+		Manifests:   manifests,
+		Annotations: opts.ManifestAnnotations,
+	}
+	indexJSON, err := json.Marshal(index)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to marshal index: %w", err)
+	}
+	indexDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageIndex, indexJSON)
+	indexDesc.Annotations = index.Annotations
+	if err := dst.Push(ctx, indexDesc, bytes.NewReader(indexJSON)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to push index: %w", err)
+	}
+	if err := dst.Tag(ctx, indexDesc, dstRef); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return indexDesc, nil
+}
@@ -0,0 +1,185 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// pushImage pushes a single-platform image with the config into a new
+// memory store, and tags it by ref.
+func pushImage(t *testing.T, config []byte, layer []byte, ref string) (*memory.Store, ocispec.Descriptor) {
+	ctx := context.Background()
+	s := memory.New()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal("Store.Push() error =", err)
+		}
+		return desc
+	}
+	configDesc := push(ocispec.MediaTypeImageConfig, config)
+	layerDesc := push(ocispec.MediaTypeImageLayer, layer)
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    configDesc,
+		Layers:    []ocispec.Descriptor{layerDesc},
+	})
+	if err != nil {
+		t.Fatal("json.Marshal() error =", err)
+	}
+	manifestDesc := push(ocispec.MediaTypeImageManifest, manifestJSON)
+	if err := s.Tag(ctx, manifestDesc, ref); err != nil {
+		t.Fatal("Store.Tag() error =", err)
+	}
+	return s, manifestDesc
+}
+
+func TestAssembleIndex(t *testing.T) {
+	amd64, amd64Manifest := pushImage(t, []byte(`{"architecture":"amd64","os":"linux"}`), []byte("amd64"), "amd64")
+	arm64, arm64Manifest := pushImage(t, []byte(`{"architecture":"arm64","os":"linux","variant":"v8"}`), []byte("arm64"), "latest")
+	windows, windowsManifest := pushImage(t, []byte(`{}`), []byte("windows"), "latest")
+	windowsPlatform := &ocispec.Platform{
+		Architecture: "amd64",
+		OS:           "windows",
+		OSVersion:    "10.0.20348.1726",
+	}
+
+	ctx := context.Background()
+	dst := memory.New()
+	annotations := map[string]string{"foo": "bar"}
+	root, err := AssembleIndex(ctx, []PlatformImage{
+		{Source: amd64, Reference: "amd64"},
+		{Source: arm64, Reference: "latest"},
+		{Source: windows, Reference: "latest", Platform: windowsPlatform},
+	}, dst, "v1", AssembleIndexOptions{
+		ManifestAnnotations: annotations,
+	})
+	if err != nil {
+		t.Fatal("AssembleIndex() error =", err)
+	}
+	if root.MediaType != ocispec.MediaTypeImageIndex {
+		t.Errorf("AssembleIndex() mediaType = %v, want %v", root.MediaType, ocispec.MediaTypeImageIndex)
+	}
+	if got, err := dst.Resolve(ctx, "v1"); err != nil || !content.Equal(got, root) {
+		t.Errorf("Store.Resolve() = %v, %v, want %v", got, err, root)
+	}
+
+	indexJSON, err := content.FetchAll(ctx, dst, root)
+	if err != nil {
+		t.Fatal("Store.Fetch() error =", err)
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(indexJSON, &index); err != nil {
+		t.Fatal("json.Unmarshal() error =", err)
+	}
+	if !reflect.DeepEqual(index.Annotations, annotations) {
+		t.Errorf("Index.Annotations = %v, want %v", index.Annotations, annotations)
+	}
+	want := []ocispec.Descriptor{
+		{
+			MediaType: amd64Manifest.MediaType,
+			Digest:    amd64Manifest.Digest,
+			Size:      amd64Manifest.Size,
+			Platform:  &ocispec.Platform{Architecture: "amd64", OS: "linux"},
+		},
+		{
+			MediaType: arm64Manifest.MediaType,
+			Digest:    arm64Manifest.Digest,
+			Size:      arm64Manifest.Size,
+			Platform:  &ocispec.Platform{Architecture: "arm64", OS: "linux", Variant: "v8"},
+		},
+		{
+			MediaType: windowsManifest.MediaType,
+			Digest:    windowsManifest.Digest,
+			Size:      windowsManifest.Size,
+			Platform:  windowsPlatform,
+		},
+	}
+	if !reflect.DeepEqual(index.Manifests, want) {
+		t.Errorf("Index.Manifests = %v, want %v", index.Manifests, want)
+	}
+
+	// test the images are copied
+	for _, m := range want {
+		if err := CopyGraph(ctx, dst, memory.New(), m, CopyGraphOptions{}); err != nil {
+			t.Errorf("image %s not fully copied: %v", m.Digest, err)
+		}
+	}
+}
+
+func TestAssembleIndex_Invalid(t *testing.T) {
+	amd64, _ := pushImage(t, []byte(`{"architecture":"amd64","os":"linux"}`), []byte("amd64"), "latest")
+	amd64Again, _ := pushImage(t, []byte(`{"architecture":"amd64","os":"linux"}`), []byte("amd64 again"), "latest")
+	noPlatform, _ := pushImage(t, []byte(`{}`), []byte("unknown"), "latest")
+	ctx := context.Background()
+
+	// test duplicate platforms
+	_, err := AssembleIndex(ctx, []PlatformImage{
+		{Source: amd64, Reference: "latest"},
+		{Source: amd64Again, Reference: "latest"},
+	}, memory.New(), "v1", AssembleIndexOptions{})
+	if err == nil {
+		t.Error("AssembleIndex() error = nil, wantErr true")
+	}
+
+	// test missing platform
+	_, err = AssembleIndex(ctx, []PlatformImage{
+		{Source: noPlatform, Reference: "latest"},
+	}, memory.New(), "v1", AssembleIndexOptions{})
+	if err == nil {
+		t.Error("AssembleIndex() error = nil, wantErr true")
+	}
+
+	// test multi-platform image
+	dst := memory.New()
+	if _, err := AssembleIndex(ctx, []PlatformImage{
+		{Source: amd64, Reference: "latest"},
+	}, dst, "v1", AssembleIndexOptions{}); err != nil {
+		t.Fatal("AssembleIndex() error =", err)
+	}
+	_, err = AssembleIndex(ctx, []PlatformImage{
+		{Source: dst, Reference: "v1"},
+	}, memory.New(), "v2", AssembleIndexOptions{})
+	if !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("AssembleIndex() error = %v, wantErr %v", err, errdef.ErrUnsupported)
+	}
+
+	// test not found
+	_, err = AssembleIndex(ctx, []PlatformImage{
+		{Source: amd64, Reference: "missing"},
+	}, memory.New(), "v1", AssembleIndexOptions{})
+	if !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("AssembleIndex() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+
+	// test no images
+	if _, err := AssembleIndex(ctx, nil, memory.New(), "v1", AssembleIndexOptions{}); err == nil {
+		t.Error("AssembleIndex() error = nil, wantErr true")
+	}
+}
@@ -0,0 +1,264 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package attestation provides helpers for attaching SBOMs and in-toto
+// attestations to subjects as referrers, and for retrieving them by type.
+//
+// SBOMs and attestations are packed as image manifests with the subject
+// field set, whose config media type is the artifact type and whose single
+// layer is the document. This layout is accepted by registries both with and
+// without the Referrers API.
+package attestation
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/annotation"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+)
+
+// Media types of SBOMs and attestations, which are also used as the artifact
+// types of the referrers.
+const (
+	// MediaTypeSPDX is the media type of SPDX SBOMs in JSON.
+	MediaTypeSPDX = "application/spdx+json"
+	// MediaTypeCycloneDX is the media type of CycloneDX SBOMs in JSON.
+	MediaTypeCycloneDX = "application/vnd.cyclonedx+json"
+	// MediaTypeInTotoStatement is the media type of in-toto statements.
+	MediaTypeInTotoStatement = "application/vnd.in-toto+json"
+	// MediaTypeDSSEEnvelope is the media type of DSSE envelopes, which wrap
+	// signed in-toto statements.
+	MediaTypeDSSEEnvelope = "application/vnd.dsse.envelope.v1+json"
+)
+
+// AnnotationPredicateType is the manifest annotation for the predicate type
+// of an in-toto attestation.
+const AnnotationPredicateType = "in-toto.io/predicate-type"
+
+// ErrInvalidDocument is returned when an SBOM or an attestation is malformed.
+var ErrInvalidDocument = errors.New("invalid document")
+
+// in-toto statement types.
+// Reference: https://github.com/in-toto/attestation/tree/main/spec
+var statementTypes = map[string]bool{
+	"https://in-toto.io/Statement/v0.1": true,
+	"https://in-toto.io/Statement/v1":   true,
+}
+
+// AttachOptions contains parameters for AttachSBOM and AttachAttestation.
+type AttachOptions struct {
+	// ManifestAnnotations is the annotation map of the referrer manifest.
+	ManifestAnnotations map[string]string
+	// Title is the title of the document, set as the
+	// org.opencontainers.image.title annotation of the layer.
+	// If empty, a default title based on the media type is used.
+	Title string
+	// VerifySubject, if true, verifies that the subject exists in the pusher
+	// before attaching, copying it from SubjectSource if it is missing. The
+	// pusher must be a content.Storage.
+	VerifySubject bool
+	// SubjectSource is the storage from which a missing subject is copied
+	// when VerifySubject is true.
+	SubjectSource content.ReadOnlyStorage
+}
+
+// AttachSBOM attaches an SBOM of the given media type, such as MediaTypeSPDX
+// or MediaTypeCycloneDX, to the subject.
+// If succeeded, returns the descriptor of the referrer manifest.
+func AttachSBOM(ctx context.Context, pusher content.Pusher, subject ocispec.Descriptor, mediaType string, sbom []byte, opts AttachOptions) (ocispec.Descriptor, error) {
+	switch mediaType {
+	case MediaTypeSPDX, MediaTypeCycloneDX:
+	default:
+		return ocispec.Descriptor{}, fmt.Errorf("unsupported SBOM media type %q: %w", mediaType, errdef.ErrUnsupported)
+	}
+	if !json.Valid(sbom) {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: malformed JSON: %w", mediaType, ErrInvalidDocument)
+	}
+	return attach(ctx, pusher, subject, mediaType, sbom, opts.ManifestAnnotations, opts)
+}
+
+// AttachAttestation attaches an in-toto attestation to the subject. The
+// attestation is either an in-toto statement, or a DSSE envelope wrapping
+// one. The predicate type of the statement is recorded as the
+// AnnotationPredicateType annotation of the referrer manifest.
+// If succeeded, returns the descriptor of the referrer manifest.
+func AttachAttestation(ctx context.Context, pusher content.Pusher, subject ocispec.Descriptor, attestation []byte, opts AttachOptions) (ocispec.Descriptor, error) {
+	mediaType, predicateType, err := parseAttestation(attestation)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	annotations := make(map[string]string, len(opts.ManifestAnnotations)+1)
+	for k, v := range opts.ManifestAnnotations {
+		annotations[k] = v
+	}
+	annotations[AnnotationPredicateType] = predicateType
+	return attach(ctx, pusher, subject, mediaType, attestation, annotations, opts)
+}
+
+// attach pushes the document and a referrer manifest of the artifact type
+// mediaType.
+func attach(ctx context.Context, pusher content.Pusher, subject ocispec.Descriptor, mediaType string, doc []byte, annotations map[string]string, opts AttachOptions) (ocispec.Descriptor, error) {
+	if opts.VerifySubject {
+		storage, ok := pusher.(content.Storage)
+		if !ok {
+			return ocispec.Descriptor{}, fmt.Errorf("subject verification on pusher: %w", errdef.ErrUnsupported)
+		}
+		if err := oras.EnsureSubject(ctx, storage, subject, oras.EnsureSubjectOptions{Source: opts.SubjectSource}); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+	title := opts.Title
+	if title == "" {
+		title = defaultTitle(mediaType)
+	}
+	layer := content.NewDescriptorFromBytes(mediaType, doc)
+	layer.Annotations = annotation.WithTitle(nil, title)
+	if err := pusher.Push(ctx, layer, bytes.NewReader(doc)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to push %s: %w", mediaType, err)
+	}
+	return oras.Pack(ctx, pusher, mediaType, []ocispec.Descriptor{layer}, oras.PackOptions{
+		Subject:             &subject,
+		ManifestAnnotations: annotations,
+		PackImageManifest:   true,
+	})
+}
+
+// defaultTitle returns the default title of a document of the media type.
+func defaultTitle(mediaType string) string {
+	switch mediaType {
+	case MediaTypeSPDX:
+		return "sbom.spdx.json"
+	case MediaTypeCycloneDX:
+		return "sbom.cdx.json"
+	case MediaTypeDSSEEnvelope:
+		return "attestation.dsse.json"
+	default:
+		return "attestation.intoto.json"
+	}
+}
+
+// parseAttestation parses an in-toto statement or a DSSE envelope, and
+// returns its media type and the predicate type of the statement.
+func parseAttestation(attestation []byte) (string, string, error) {
+	var envelope struct {
+		PayloadType string `json:"payloadType"`
+		Payload     string `json:"payload"`
+	}
+	if err := json.Unmarshal(attestation, &envelope); err != nil {
+		return "", "", fmt.Errorf("malformed attestation: %v: %w", err, ErrInvalidDocument)
+	}
+	if envelope.PayloadType == "" {
+		predicateType, err := parseStatement(attestation)
+		if err != nil {
+			return "", "", err
+		}
+		return MediaTypeInTotoStatement, predicateType, nil
+	}
+
+	if envelope.PayloadType != MediaTypeInTotoStatement {
+		return "", "", fmt.Errorf("unsupported DSSE payload type %q: %w", envelope.PayloadType, ErrInvalidDocument)
+	}
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return "", "", fmt.Errorf("malformed DSSE payload: %v: %w", err, ErrInvalidDocument)
+	}
+	predicateType, err := parseStatement(payload)
+	if err != nil {
+		return "", "", err
+	}
+	return MediaTypeDSSEEnvelope, predicateType, nil
+}
+
+// parseStatement parses an in-toto statement and returns its predicate type.
+func parseStatement(statement []byte) (string, error) {
+	var s struct {
+		Type          string `json:"_type"`
+		PredicateType string `json:"predicateType"`
+	}
+	if err := json.Unmarshal(statement, &s); err != nil {
+		return "", fmt.Errorf("malformed in-toto statement: %v: %w", err, ErrInvalidDocument)
+	}
+	if !statementTypes[s.Type] {
+		return "", fmt.Errorf("unsupported in-toto statement type %q: %w", s.Type, ErrInvalidDocument)
+	}
+	if s.PredicateType == "" {
+		return "", fmt.Errorf("missing predicate type: %w", ErrInvalidDocument)
+	}
+	return s.PredicateType, nil
+}
+
+// FindSBOMs returns the referrer manifests of the SBOMs attached to the
+// subject, in both SPDX and CycloneDX formats.
+func FindSBOMs(ctx context.Context, target content.ReadOnlyStorage, subject ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	var results []ocispec.Descriptor
+	for _, artifactType := range []string{MediaTypeSPDX, MediaTypeCycloneDX} {
+		referrers, err := registry.Referrers(ctx, target, subject, artifactType)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, referrers...)
+	}
+	return results, nil
+}
+
+// FindAttestations returns the referrer manifests of the attestations
+// attached to the subject. If predicateType is not empty, only attestations
+// of the predicate type are returned.
+func FindAttestations(ctx context.Context, target content.ReadOnlyStorage, subject ocispec.Descriptor, predicateType string) ([]ocispec.Descriptor, error) {
+	var results []ocispec.Descriptor
+	for _, artifactType := range []string{MediaTypeInTotoStatement, MediaTypeDSSEEnvelope} {
+		referrers, err := registry.Referrers(ctx, target, subject, artifactType)
+		if err != nil {
+			return nil, err
+		}
+		for _, referrer := range referrers {
+			if predicateType == "" || referrer.Annotations[AnnotationPredicateType] == predicateType {
+				results = append(results, referrer)
+			}
+		}
+	}
+	return results, nil
+}
+
+// FetchDocument fetches the SBOM or the attestation packed in the referrer
+// manifest, and returns its descriptor and content.
+func FetchDocument(ctx context.Context, fetcher content.Fetcher, referrer ocispec.Descriptor) (ocispec.Descriptor, []byte, error) {
+	manifestJSON, err := content.FetchAll(ctx, fetcher, referrer)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return ocispec.Descriptor{}, nil, fmt.Errorf("failed to decode manifest: %s: %s: %w", referrer.Digest, referrer.MediaType, err)
+	}
+	if len(manifest.Layers) != 1 {
+		return ocispec.Descriptor{}, nil, fmt.Errorf("%s: %s: expected 1 layer, got %d: %w", referrer.Digest, referrer.MediaType, len(manifest.Layers), ErrInvalidDocument)
+	}
+	desc := manifest.Layers[0]
+	doc, err := content.FetchAll(ctx, fetcher, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	return desc, doc, nil
+}
@@ -0,0 +1,169 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package attestation
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+const (
+	testStatement = `{"_type":"https://in-toto.io/Statement/v1","subject":[],"predicateType":"https://slsa.dev/provenance/v1","predicate":{}}`
+	testSPDX      = `{"spdxVersion":"SPDX-2.3"}`
+	testCycloneDX = `{"bomFormat":"CycloneDX"}`
+)
+
+func TestAttach(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	subject, err := oras.Pack(ctx, store, "", nil, oras.PackOptions{PackImageManifest: true})
+	if err != nil {
+		t.Fatal("Pack() error =", err)
+	}
+
+	spdx, err := AttachSBOM(ctx, store, subject, MediaTypeSPDX, []byte(testSPDX), AttachOptions{})
+	if err != nil {
+		t.Fatalf("AttachSBOM() error = %v", err)
+	}
+	if spdx.ArtifactType != MediaTypeSPDX {
+		t.Errorf("AttachSBOM().ArtifactType = %v, want %v", spdx.ArtifactType, MediaTypeSPDX)
+	}
+	if _, err := AttachSBOM(ctx, store, subject, MediaTypeCycloneDX, []byte(testCycloneDX), AttachOptions{Title: "bom.json"}); err != nil {
+		t.Fatalf("AttachSBOM() error = %v", err)
+	}
+	statement, err := AttachAttestation(ctx, store, subject, []byte(testStatement), AttachOptions{})
+	if err != nil {
+		t.Fatalf("AttachAttestation() error = %v", err)
+	}
+	if got, want := statement.Annotations[AnnotationPredicateType], "https://slsa.dev/provenance/v1"; got != want {
+		t.Errorf("AttachAttestation() predicate type = %v, want %v", got, want)
+	}
+	envelope := `{"payloadType":"application/vnd.in-toto+json","payload":"` + base64.StdEncoding.EncodeToString([]byte(testStatement)) + `","signatures":[]}`
+	dsse, err := AttachAttestation(ctx, store, subject, []byte(envelope), AttachOptions{})
+	if err != nil {
+		t.Fatalf("AttachAttestation() error = %v", err)
+	}
+	if dsse.ArtifactType != MediaTypeDSSEEnvelope {
+		t.Errorf("AttachAttestation().ArtifactType = %v, want %v", dsse.ArtifactType, MediaTypeDSSEEnvelope)
+	}
+
+	sboms, err := FindSBOMs(ctx, store, subject)
+	if err != nil {
+		t.Fatalf("FindSBOMs() error = %v", err)
+	}
+	if len(sboms) != 2 {
+		t.Fatalf("FindSBOMs() = %v, want 2 SBOMs", sboms)
+	}
+	desc, doc, err := FetchDocument(ctx, store, spdx)
+	if err != nil {
+		t.Fatalf("FetchDocument() error = %v", err)
+	}
+	if desc.MediaType != MediaTypeSPDX || desc.Annotations[ocispec.AnnotationTitle] != "sbom.spdx.json" {
+		t.Errorf("FetchDocument() descriptor = %v", desc)
+	}
+	if !bytes.Equal(doc, []byte(testSPDX)) {
+		t.Errorf("FetchDocument() = %s, want %s", doc, testSPDX)
+	}
+
+	attestations, err := FindAttestations(ctx, store, subject, "https://slsa.dev/provenance/v1")
+	if err != nil {
+		t.Fatalf("FindAttestations() error = %v", err)
+	}
+	if len(attestations) != 2 {
+		t.Errorf("FindAttestations() = %v, want 2 attestations", attestations)
+	}
+	attestations, err = FindAttestations(ctx, store, subject, "https://spdx.dev/Document")
+	if err != nil {
+		t.Fatalf("FindAttestations() error = %v", err)
+	}
+	if len(attestations) != 0 {
+		t.Errorf("FindAttestations() = %v, want none", attestations)
+	}
+	all, err := FindAttestations(ctx, store, subject, "")
+	if err != nil {
+		t.Fatalf("FindAttestations() error = %v", err)
+	}
+	if len(all) != 2 || !content.Equal(all[0], statement) || !content.Equal(all[1], dsse) {
+		t.Errorf("FindAttestations() = %v, want %v", all, []ocispec.Descriptor{statement, dsse})
+	}
+}
+
+func TestAttach_VerifySubject(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	subject, err := oras.Pack(ctx, src, "", nil, oras.PackOptions{PackImageManifest: true})
+	if err != nil {
+		t.Fatal("Pack() error =", err)
+	}
+
+	dst := memory.New()
+	opts := AttachOptions{VerifySubject: true}
+	if _, err := AttachSBOM(ctx, dst, subject, MediaTypeSPDX, []byte(testSPDX), opts); !errors.Is(err, errdef.ErrNotFound) {
+		t.Fatalf("AttachSBOM() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+	sbom := content.NewDescriptorFromBytes(MediaTypeSPDX, []byte(testSPDX))
+	if exists, err := dst.Exists(ctx, sbom); err != nil || exists {
+		t.Errorf("Exists() = %v, %v, want false", exists, err)
+	}
+
+	opts.SubjectSource = src
+	if _, err := AttachSBOM(ctx, dst, subject, MediaTypeSPDX, []byte(testSPDX), opts); err != nil {
+		t.Fatalf("AttachSBOM() error = %v", err)
+	}
+	if exists, err := dst.Exists(ctx, subject); err != nil || !exists {
+		t.Errorf("Exists() = %v, %v, want true", exists, err)
+	}
+	sboms, err := FindSBOMs(ctx, dst, subject)
+	if err != nil {
+		t.Fatalf("FindSBOMs() error = %v", err)
+	}
+	if len(sboms) != 1 {
+		t.Errorf("FindSBOMs() = %v, want 1 SBOM", sboms)
+	}
+}
+
+func TestAttach_Invalid(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	subject := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, []byte("{}"))
+
+	if _, err := AttachSBOM(ctx, store, subject, "text/plain", []byte(testSPDX), AttachOptions{}); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("AttachSBOM() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+	if _, err := AttachSBOM(ctx, store, subject, MediaTypeSPDX, []byte("{"), AttachOptions{}); !errors.Is(err, ErrInvalidDocument) {
+		t.Errorf("AttachSBOM() error = %v, want %v", err, ErrInvalidDocument)
+	}
+	for _, attestation := range []string{
+		`{`,
+		`{"_type":"unknown","predicateType":"https://slsa.dev/provenance/v1"}`,
+		`{"_type":"https://in-toto.io/Statement/v1"}`,
+		`{"payloadType":"text/plain","payload":""}`,
+		`{"payloadType":"application/vnd.in-toto+json","payload":"!"}`,
+	} {
+		if _, err := AttachAttestation(ctx, store, subject, []byte(attestation), AttachOptions{}); !errors.Is(err, ErrInvalidDocument) {
+			t.Errorf("AttachAttestation(%s) error = %v, want %v", attestation, err, ErrInvalidDocument)
+		}
+	}
+}
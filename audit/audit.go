@@ -0,0 +1,125 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package audit provides a Target wrapper recording the operations on the
+// wrapped target, so that transfer audit logs can be produced without
+// instrumenting every call site.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Operation is the type of a recorded operation.
+type Operation string
+
+// Operations recorded by the audit target.
+const (
+	OperationResolve Operation = "resolve"
+	OperationFetch   Operation = "fetch"
+	OperationPush    Operation = "push"
+	OperationTag     Operation = "tag"
+	OperationDelete  Operation = "delete"
+)
+
+// Record is a record of an operation.
+type Record struct {
+	// Time is the time when the operation is started.
+	Time time.Time
+	// Duration is the duration of the operation. The duration of a fetch
+	// operation lasts until the fetched content is closed.
+	Duration time.Duration
+	// Operation is the type of the operation.
+	Operation Operation
+	// Reference is the reference being resolved or tagged, if any.
+	Reference string
+	// Descriptor is the descriptor of the content. For a failed resolve
+	// operation, Descriptor is empty.
+	Descriptor ocispec.Descriptor
+	// Bytes is the number of bytes transferred by a fetch or push operation.
+	Bytes int64
+	// Err is the error of the operation, or nil if the operation succeeded.
+	Err error
+}
+
+// Sink receives the records of the operations.
+// Record may be called concurrently.
+type Sink interface {
+	// Record receives the record of an operation.
+	Record(ctx context.Context, r Record)
+}
+
+// SinkFunc is a function implementing Sink.
+type SinkFunc func(ctx context.Context, r Record)
+
+// Record calls fn(ctx, r).
+func (fn SinkFunc) Record(ctx context.Context, r Record) {
+	fn(ctx, r)
+}
+
+// jsonRecord is the JSON representation of a Record.
+type jsonRecord struct {
+	Time       time.Time          `json:"time"`
+	Duration   time.Duration      `json:"duration"`
+	Operation  Operation          `json:"operation"`
+	Reference  string             `json:"reference,omitempty"`
+	Descriptor ocispec.Descriptor `json:"descriptor"`
+	Bytes      int64              `json:"bytes,omitempty"`
+	Error      string             `json:"error,omitempty"`
+}
+
+// jsonSink is a Sink writing the records to a writer as JSON lines.
+type jsonSink struct {
+	lock    sync.Mutex
+	encoder *json.Encoder
+	onError func(error)
+}
+
+// NewJSONSink returns a Sink writing the records to w, one JSON object per
+// line. The errors of writing to w are passed to onError if it is not nil.
+// Writes to w are serialized.
+func NewJSONSink(w io.Writer, onError func(error)) Sink {
+	return &jsonSink{
+		encoder: json.NewEncoder(w),
+		onError: onError,
+	}
+}
+
+// Record writes the record as a JSON line.
+func (s *jsonSink) Record(_ context.Context, r Record) {
+	jr := jsonRecord{
+		Time:       r.Time,
+		Duration:   r.Duration,
+		Operation:  r.Operation,
+		Reference:  r.Reference,
+		Descriptor: r.Descriptor,
+		Bytes:      r.Bytes,
+	}
+	if r.Err != nil {
+		jr.Error = r.Err.Error()
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.encoder.Encode(jr); err != nil && s.onError != nil {
+		s.onError(err)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+func TestJSONSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONSink(&buf, func(err error) {
+		t.Error("unexpected error =", err)
+	})
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, []byte("hello"))
+	start := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	sink.Record(context.Background(), Record{
+		Time:       start,
+		Duration:   time.Second,
+		Operation:  OperationPush,
+		Descriptor: desc,
+		Bytes:      5,
+	})
+	sink.Record(context.Background(), Record{
+		Time:      start,
+		Operation: OperationResolve,
+		Reference: "latest",
+		Err:       errors.New("not found"),
+	})
+
+	decoder := json.NewDecoder(&buf)
+	var got []jsonRecord
+	for decoder.More() {
+		var r jsonRecord
+		if err := decoder.Decode(&r); err != nil {
+			t.Fatal("Decode() error =", err)
+		}
+		got = append(got, r)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len(records) = %d, want 2", len(got))
+	}
+	if got[0].Operation != OperationPush || got[0].Descriptor.Digest != desc.Digest || got[0].Bytes != 5 || got[0].Error != "" || !got[0].Time.Equal(start) {
+		t.Errorf("records[0] = %+v", got[0])
+	}
+	if got[1].Operation != OperationResolve || got[1].Reference != "latest" || got[1].Error != "not found" {
+		t.Errorf("records[1] = %+v", got[1])
+	}
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestJSONSink_Error(t *testing.T) {
+	var got error
+	sink := NewJSONSink(errWriter{}, func(err error) {
+		got = err
+	})
+	sink.Record(context.Background(), Record{Operation: OperationTag})
+	if got == nil {
+		t.Error("onError is not called")
+	}
+}
@@ -0,0 +1,221 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+)
+
+// Target is an oras.Target which also supports deletion and pushing with a
+// reference.
+type Target interface {
+	oras.Target
+	content.Deleter
+	registry.ReferencePusher
+}
+
+// target is a Target recording the operations on the wrapped target.
+type target struct {
+	oras.Target
+	sink Sink
+}
+
+// NewTarget wraps t to record the resolve, fetch, push, tag and delete
+// operations to sink, with their outcomes.
+//
+// Fetch operations are recorded when the fetched content is closed, or when
+// the fetch fails. PushReference is recorded as a push operation with the
+// reference. Delete returns errdef.ErrUnsupported if t does not support
+// deletion. Other operations, such as Exists, are not recorded.
+func NewTarget(t oras.Target, sink Sink) Target {
+	return &target{
+		Target: t,
+		sink:   sink,
+	}
+}
+
+// Resolve resolves a reference to a descriptor.
+func (t *target) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+	start := time.Now()
+	desc, err := t.Target.Resolve(ctx, reference)
+	t.sink.Record(ctx, Record{
+		Time:       start,
+		Duration:   time.Since(start),
+		Operation:  OperationResolve,
+		Reference:  reference,
+		Descriptor: desc,
+		Err:        err,
+	})
+	return desc, err
+}
+
+// Fetch fetches the content identified by the descriptor.
+func (t *target) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	start := time.Now()
+	rc, err := t.Target.Fetch(ctx, target)
+	if err != nil {
+		t.sink.Record(ctx, Record{
+			Time:       start,
+			Duration:   time.Since(start),
+			Operation:  OperationFetch,
+			Descriptor: target,
+			Err:        err,
+		})
+		return nil, err
+	}
+	return &fetchRecorder{
+		ReadCloser: rc,
+		ctx:        ctx,
+		sink:       t.sink,
+		record: Record{
+			Time:       start,
+			Operation:  OperationFetch,
+			Descriptor: target,
+		},
+	}, nil
+}
+
+// Push pushes the content, matching the expected descriptor.
+func (t *target) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	start := time.Now()
+	cr := &countReader{Reader: content}
+	err := t.Target.Push(ctx, expected, cr)
+	t.sink.Record(ctx, Record{
+		Time:       start,
+		Duration:   time.Since(start),
+		Operation:  OperationPush,
+		Descriptor: expected,
+		Bytes:      cr.n,
+		Err:        err,
+	})
+	return err
+}
+
+// PushReference pushes the manifest with a reference tag.
+// If the wrapped target does not support pushing with a reference, the
+// manifest is pushed and then tagged.
+func (t *target) PushReference(ctx context.Context, expected ocispec.Descriptor, content io.Reader, reference string) error {
+	refPusher, ok := t.Target.(registry.ReferencePusher)
+	if !ok {
+		if err := t.Push(ctx, expected, content); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+			return err
+		}
+		return t.Tag(ctx, expected, reference)
+	}
+
+	start := time.Now()
+	cr := &countReader{Reader: content}
+	err := refPusher.PushReference(ctx, expected, cr, reference)
+	t.sink.Record(ctx, Record{
+		Time:       start,
+		Duration:   time.Since(start),
+		Operation:  OperationPush,
+		Reference:  reference,
+		Descriptor: expected,
+		Bytes:      cr.n,
+		Err:        err,
+	})
+	return err
+}
+
+// Tag tags a descriptor with a reference string.
+func (t *target) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	start := time.Now()
+	err := t.Target.Tag(ctx, desc, reference)
+	t.sink.Record(ctx, Record{
+		Time:       start,
+		Duration:   time.Since(start),
+		Operation:  OperationTag,
+		Reference:  reference,
+		Descriptor: desc,
+		Err:        err,
+	})
+	return err
+}
+
+// Delete removes the content identified by the descriptor.
+func (t *target) Delete(ctx context.Context, target ocispec.Descriptor) error {
+	start := time.Now()
+	var err error
+	if deleter, ok := t.Target.(content.Deleter); ok {
+		err = deleter.Delete(ctx, target)
+	} else {
+		err = fmt.Errorf("%s: %s: delete: %w", target.Digest, target.MediaType, errdef.ErrUnsupported)
+	}
+	t.sink.Record(ctx, Record{
+		Time:       start,
+		Duration:   time.Since(start),
+		Operation:  OperationDelete,
+		Descriptor: target,
+		Err:        err,
+	})
+	return err
+}
+
+// countReader counts the bytes read from the underlying reader.
+type countReader struct {
+	io.Reader
+	n int64
+}
+
+// Read reads from the underlying reader and counts the bytes read.
+func (r *countReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// fetchRecorder records a fetch operation when the fetched content is
+// closed.
+type fetchRecorder struct {
+	io.ReadCloser
+	ctx    context.Context
+	sink   Sink
+	record Record
+	once   sync.Once
+}
+
+// Read reads the fetched content, counting the bytes read and recording the
+// first read error other than io.EOF.
+func (r *fetchRecorder) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.record.Bytes += int64(n)
+	if err != nil && err != io.EOF && r.record.Err == nil {
+		r.record.Err = err
+	}
+	return n, err
+}
+
+// Close closes the fetched content and records the fetch operation.
+func (r *fetchRecorder) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(func() {
+		r.record.Duration = time.Since(r.record.Time)
+		r.sink.Record(r.ctx, r.record)
+	})
+	return err
+}
@@ -0,0 +1,154 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// recordingSink collects the records.
+type recordingSink struct {
+	lock    sync.Mutex
+	records []Record
+}
+
+func (s *recordingSink) Record(_ context.Context, r Record) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.records = append(s.records, r)
+}
+
+func TestTarget(t *testing.T) {
+	ctx := context.Background()
+	sink := &recordingSink{}
+	target := NewTarget(memory.New(), sink)
+
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+	if err := target.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	if err := target.Tag(ctx, desc, "latest"); err != nil {
+		t.Fatal("Tag() error =", err)
+	}
+	if _, err := target.Resolve(ctx, "latest"); err != nil {
+		t.Fatal("Resolve() error =", err)
+	}
+	if _, err := target.Resolve(ctx, "missing"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Fatalf("Resolve() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+	rc, err := target.Fetch(ctx, desc)
+	if err != nil {
+		t.Fatal("Fetch() error =", err)
+	}
+	if _, err := io.ReadAll(rc); err != nil {
+		t.Fatal("ReadAll() error =", err)
+	}
+	if err := rc.Close(); err != nil {
+		t.Fatal("Close() error =", err)
+	}
+	// closing twice records once
+	rc.Close()
+	if err := target.Delete(ctx, desc); err != nil {
+		t.Fatal("Delete() error =", err)
+	}
+
+	want := []struct {
+		op        Operation
+		reference string
+		bytes     int64
+		failed    bool
+	}{
+		{OperationPush, "", int64(len(blob)), false},
+		{OperationTag, "latest", 0, false},
+		{OperationResolve, "latest", 0, false},
+		{OperationResolve, "missing", 0, true},
+		{OperationFetch, "", int64(len(blob)), false},
+		{OperationDelete, "", 0, false},
+	}
+	if len(sink.records) != len(want) {
+		t.Fatalf("len(records) = %d, want %d", len(sink.records), len(want))
+	}
+	for i, w := range want {
+		r := sink.records[i]
+		if r.Operation != w.op || r.Reference != w.reference || r.Bytes != w.bytes || (r.Err != nil) != w.failed {
+			t.Errorf("records[%d] = %+v, want %+v", i, r, w)
+		}
+		if r.Time.IsZero() {
+			t.Errorf("records[%d].Time is zero", i)
+		}
+		if !w.failed && r.Descriptor.Digest != desc.Digest {
+			t.Errorf("records[%d].Descriptor = %v, want %v", i, r.Descriptor.Digest, desc.Digest)
+		}
+	}
+}
+
+func TestTarget_PushReference(t *testing.T) {
+	ctx := context.Background()
+	sink := &recordingSink{}
+	target := NewTarget(memory.New(), sink)
+
+	manifest := []byte(`{"layers":[]}`)
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifest)
+	if err := target.PushReference(ctx, desc, bytes.NewReader(manifest), "v1"); err != nil {
+		t.Fatal("PushReference() error =", err)
+	}
+	if len(sink.records) != 2 || sink.records[0].Operation != OperationPush || sink.records[1].Operation != OperationTag || sink.records[1].Reference != "v1" {
+		t.Errorf("records = %+v, want push and tag", sink.records)
+	}
+}
+
+func TestTarget_DeleteUnsupported(t *testing.T) {
+	ctx := context.Background()
+	sink := &recordingSink{}
+	target := NewTarget(struct{ oras.Target }{memory.New()}, sink)
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, []byte("foo"))
+	if err := target.Delete(ctx, desc); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Delete() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+	if len(sink.records) != 1 || !errors.Is(sink.records[0].Err, errdef.ErrUnsupported) {
+		t.Errorf("records = %+v, want a failed delete", sink.records)
+	}
+}
+
+func TestTarget_Copy(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	blob := []byte("hello")
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+	if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	sink := &recordingSink{}
+	dst := NewTarget(memory.New(), sink)
+	if err := oras.CopyGraph(ctx, src, dst, desc, oras.DefaultCopyGraphOptions); err != nil {
+		t.Fatal("CopyGraph() error =", err)
+	}
+	if len(sink.records) != 1 || sink.records[0].Operation != OperationPush || sink.records[0].Bytes != int64(len(blob)) {
+		t.Errorf("records = %+v, want a push", sink.records)
+	}
+}
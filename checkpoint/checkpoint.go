@@ -0,0 +1,211 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package checkpoint provides transfer sessions persisting their in-flight
+// state, so that a crashed process can resume a transfer where it left off.
+//
+// A Session records the nodes copied to the destination and the upload
+// sessions of the blobs being pushed to remote registries. The state is
+// saved to a pluggable Store after every change. To resume a transfer, a new
+// Session is created with the same ID and Store, and the destination is
+// wrapped by Session.Target:
+//
+//	session, err := checkpoint.NewSession(ctx, store, "replicate-v1")
+//	if err != nil {
+//		return err
+//	}
+//	desc, err := oras.Copy(ctx, src, "v1", session.Target(dst), "v1", oras.DefaultCopyOptions)
+//	if err != nil {
+//		return err // rerun to resume
+//	}
+//	return session.Finish(ctx)
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+)
+
+// Session is a transfer session persisting its state to a Store.
+// Session implements registry.UploadTracker.
+type Session struct {
+	id    string
+	store Store
+
+	// lock protects the fields below.
+	lock      sync.Mutex
+	completed map[digest.Digest]bool
+	uploads   map[digest.Digest]registry.UploadSession
+}
+
+// NewSession returns the session identified by id, restoring its state from
+// the store if saved by a previous run.
+func NewSession(ctx context.Context, store Store, id string) (*Session, error) {
+	s := &Session{
+		id:        id,
+		store:     store,
+		completed: make(map[digest.Digest]bool),
+		uploads:   make(map[digest.Digest]registry.UploadSession),
+	}
+	state, err := store.Load(ctx, id)
+	if err != nil {
+		if errors.Is(err, errdef.ErrNotFound) {
+			return s, nil
+		}
+		return nil, err
+	}
+	for _, dgst := range state.Completed {
+		s.completed[dgst] = true
+	}
+	for dgst, upload := range state.Uploads {
+		s.uploads[dgst] = upload
+	}
+	return s, nil
+}
+
+// ID returns the ID of the session.
+func (s *Session) ID() string {
+	return s.id
+}
+
+// Completed returns true if the node described by desc has been copied to
+// the destination.
+func (s *Session) Completed(desc ocispec.Descriptor) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.completed[desc.Digest]
+}
+
+// Complete marks the node described by desc as copied to the destination.
+func (s *Session) Complete(ctx context.Context, desc ocispec.Descriptor) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.completed[desc.Digest] = true
+	delete(s.uploads, desc.Digest)
+	return s.save(ctx)
+}
+
+// Committed returns true if the node described by desc has been copied to
+// the destination.
+// Committed and Commit implement oras.CopyStateStore, so that a Session can
+// be set as CopyGraphOptions.StateStore instead of wrapping the destination
+// by Target.
+func (s *Session) Committed(_ context.Context, desc ocispec.Descriptor) (bool, error) {
+	return s.Completed(desc), nil
+}
+
+// Commit marks the node described by desc as copied to the destination.
+func (s *Session) Commit(ctx context.Context, desc ocispec.Descriptor) error {
+	return s.Complete(ctx, desc)
+}
+
+// LoadUpload returns the saved upload session of the blob described by desc.
+func (s *Session) LoadUpload(_ context.Context, desc ocispec.Descriptor) (registry.UploadSession, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	upload, ok := s.uploads[desc.Digest]
+	return upload, ok, nil
+}
+
+// SaveUpload saves the upload session of the blob described by desc.
+func (s *Session) SaveUpload(ctx context.Context, desc ocispec.Descriptor, upload registry.UploadSession) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.uploads[desc.Digest] = upload
+	return s.save(ctx)
+}
+
+// DeleteUpload deletes the upload session of the blob described by desc.
+func (s *Session) DeleteUpload(ctx context.Context, desc ocispec.Descriptor) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.uploads[desc.Digest]; !ok {
+		return nil
+	}
+	delete(s.uploads, desc.Digest)
+	return s.save(ctx)
+}
+
+// Context returns a context with the session attached as the upload tracker,
+// so that blobs pushed to remote repositories with the context are uploaded
+// resumably.
+func (s *Session) Context(ctx context.Context) context.Context {
+	return registry.WithUploadTracker(ctx, s)
+}
+
+// Finish deletes the state of the completed session from the store.
+func (s *Session) Finish(ctx context.Context) error {
+	return s.store.Delete(ctx, s.id)
+}
+
+// save saves the state to the store. The caller must hold the lock.
+func (s *Session) save(ctx context.Context) error {
+	state := &State{}
+	for dgst := range s.completed {
+		state.Completed = append(state.Completed, dgst)
+	}
+	if len(s.uploads) > 0 {
+		state.Uploads = make(map[digest.Digest]registry.UploadSession, len(s.uploads))
+		for dgst, upload := range s.uploads {
+			state.Uploads[dgst] = upload
+		}
+	}
+	return s.store.Save(ctx, s.id, state)
+}
+
+// target is a Target recording the pushed content in a session.
+type target struct {
+	oras.Target
+	session *Session
+}
+
+// Target wraps dst to record the pushed content in the session.
+// Content completed by a previous run is reported as existing without
+// accessing dst, and blobs are pushed resumably if dst is a remote
+// repository.
+func (s *Session) Target(dst oras.Target) oras.Target {
+	return &target{
+		Target:  dst,
+		session: s,
+	}
+}
+
+// Exists returns true if the described content is completed in the session
+// or exists in the destination.
+func (t *target) Exists(ctx context.Context, desc ocispec.Descriptor) (bool, error) {
+	if t.session.Completed(desc) {
+		return true, nil
+	}
+	return t.Target.Exists(ctx, desc)
+}
+
+// Push pushes the content to the destination, and marks it as completed.
+func (t *target) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	err := t.Target.Push(t.session.Context(ctx), expected, content)
+	if err == nil || errors.Is(err, errdef.ErrAlreadyExists) {
+		if err := t.session.Complete(ctx, expected); err != nil {
+			return err
+		}
+	}
+	return err
+}
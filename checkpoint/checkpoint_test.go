@@ -0,0 +1,210 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/registry"
+)
+
+// crashingTarget is a target failing to push after the given number of
+// pushes, and counting the existence checks.
+type crashingTarget struct {
+	oras.Target
+	pushes int
+	exists int
+	pushed []ocispec.Descriptor
+}
+
+func (t *crashingTarget) Push(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	if t.pushes == 0 {
+		return errors.New("crashed")
+	}
+	t.pushes--
+	t.pushed = append(t.pushed, expected)
+	return t.Target.Push(ctx, expected, r)
+}
+
+func (t *crashingTarget) Exists(ctx context.Context, desc ocispec.Descriptor) (bool, error) {
+	t.exists++
+	return t.Target.Exists(ctx, desc)
+}
+
+func TestSession_Target(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal("Push() error =", err)
+		}
+		return desc
+	}
+	config := push(ocispec.MediaTypeImageConfig, []byte("{}"))
+	layer := push(ocispec.MediaTypeImageLayer, []byte("layer"))
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    []ocispec.Descriptor{layer},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := push(ocispec.MediaTypeImageManifest, manifestJSON)
+	if err := src.Tag(ctx, manifest, "v1"); err != nil {
+		t.Fatal("Tag() error =", err)
+	}
+
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	opts := oras.CopyOptions{}
+	opts.Concurrency = 1
+
+	// crash after pushing a single blob
+	dst := &crashingTarget{Target: memory.New(), pushes: 1}
+	session, err := NewSession(ctx, store, "test")
+	if err != nil {
+		t.Fatalf("NewSession() error = %v", err)
+	}
+	if _, err := oras.Copy(ctx, src, "v1", session.Target(dst), "v1", opts); err == nil {
+		t.Fatal("Copy() error = nil, want error")
+	}
+	if len(dst.pushed) != 1 {
+		t.Fatalf("pushed = %v, want 1 node", dst.pushed)
+	}
+	completed := dst.pushed[0]
+
+	// resume in a new session
+	dst.pushes = 10
+	dst.pushed = nil
+	dst.exists = 0
+	session, err = NewSession(ctx, store, "test")
+	if err != nil {
+		t.Fatalf("NewSession() error = %v", err)
+	}
+	if !session.Completed(completed) {
+		t.Fatalf("Session.Completed(%v) = false, want true", completed)
+	}
+	if _, err := oras.Copy(ctx, src, "v1", session.Target(dst), "v1", opts); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	for _, desc := range dst.pushed {
+		if content.Equal(desc, completed) {
+			t.Errorf("completed node %v is pushed again", completed)
+		}
+	}
+	if len(dst.pushed) != 2 {
+		t.Errorf("pushed = %v, want 2 nodes", dst.pushed)
+	}
+	if dst.exists != 2 {
+		t.Errorf("existence checks = %d, want 2", dst.exists)
+	}
+
+	if err := session.Finish(ctx); err != nil {
+		t.Fatalf("Session.Finish() error = %v", err)
+	}
+	session, err = NewSession(ctx, store, "test")
+	if err != nil {
+		t.Fatalf("NewSession() error = %v", err)
+	}
+	if session.Completed(completed) {
+		t.Error("Session.Completed() = true after Finish(), want false")
+	}
+}
+
+func TestSession_UploadTracker(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	session, err := NewSession(ctx, store, "test")
+	if err != nil {
+		t.Fatalf("NewSession() error = %v", err)
+	}
+	if got := registry.UploadTrackerFromContext(session.Context(ctx)); got != session {
+		t.Fatalf("UploadTrackerFromContext() = %v, want %v", got, session)
+	}
+
+	desc := content.NewDescriptorFromBytes("test", []byte("hello world"))
+	want := registry.UploadSession{Location: "https://localhost:5000/v2/test/blobs/uploads/uuid", Offset: 4}
+	if err := session.SaveUpload(ctx, desc, want); err != nil {
+		t.Fatalf("Session.SaveUpload() error = %v", err)
+	}
+
+	// restore in a new session
+	session, err = NewSession(ctx, store, "test")
+	if err != nil {
+		t.Fatalf("NewSession() error = %v", err)
+	}
+	got, ok, err := session.LoadUpload(ctx, desc)
+	if err != nil || !ok || !reflect.DeepEqual(got, want) {
+		t.Fatalf("Session.LoadUpload() = (%v, %v, %v), want (%v, true, nil)", got, ok, err, want)
+	}
+	if err := session.DeleteUpload(ctx, desc); err != nil {
+		t.Fatalf("Session.DeleteUpload() error = %v", err)
+	}
+	if _, ok, _ := session.LoadUpload(ctx, desc); ok {
+		t.Error("Session.LoadUpload() = true after DeleteUpload(), want false")
+	}
+}
+
+func TestSession_StateStore(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	blob := []byte("layer")
+	layer := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+	if err := src.Push(ctx, layer, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+
+	session, err := NewSession(ctx, NewMemoryStore(), "test")
+	if err != nil {
+		t.Fatalf("NewSession() error = %v", err)
+	}
+	dst := &crashingTarget{Target: memory.New(), pushes: 1}
+	opts := oras.CopyGraphOptions{StateStore: session}
+	if err := oras.CopyGraph(ctx, src, dst, layer, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v", err)
+	}
+	if committed, err := session.Committed(ctx, layer); err != nil || !committed {
+		t.Fatalf("Session.Committed() = %v, %v, want true, nil", committed, err)
+	}
+
+	// the committed node is neither checked nor pushed again
+	dst.exists = 0
+	if err := oras.CopyGraph(ctx, src, dst, layer, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v", err)
+	}
+	if dst.exists != 0 {
+		t.Errorf("existence checks = %d, want 0", dst.exists)
+	}
+	if len(dst.pushed) != 1 {
+		t.Errorf("pushed = %v, want 1 node", dst.pushed)
+	}
+}
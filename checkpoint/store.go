@@ -0,0 +1,166 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+)
+
+// State is the persisted state of a transfer session.
+type State struct {
+	// Completed lists the digests of the nodes copied to the destination.
+	Completed []digest.Digest `json:"completed,omitempty"`
+	// Uploads maps the digests of the blobs being uploaded to their upload
+	// sessions.
+	Uploads map[digest.Digest]registry.UploadSession `json:"uploads,omitempty"`
+}
+
+// Store persists the states of transfer sessions.
+type Store interface {
+	// Load loads the state of the session identified by id.
+	// Returns ErrNotFound if there is no saved state.
+	Load(ctx context.Context, id string) (*State, error)
+	// Save saves the state of the session identified by id.
+	Save(ctx context.Context, id string, state *State) error
+	// Delete deletes the state of the session identified by id.
+	Delete(ctx context.Context, id string) error
+}
+
+// memoryStore is a Store in memory.
+type memoryStore struct {
+	states sync.Map // map[string][]byte
+}
+
+// NewMemoryStore returns a Store keeping the states in memory, which is
+// useful for resuming transfers within the same process.
+func NewMemoryStore() Store {
+	return &memoryStore{}
+}
+
+// Load loads the state of the session identified by id.
+func (s *memoryStore) Load(_ context.Context, id string) (*State, error) {
+	value, ok := s.states.Load(id)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", id, errdef.ErrNotFound)
+	}
+	var state State
+	if err := json.Unmarshal(value.([]byte), &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// Save saves the state of the session identified by id.
+func (s *memoryStore) Save(_ context.Context, id string, state *State) error {
+	stateJSON, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	s.states.Store(id, stateJSON)
+	return nil
+}
+
+// Delete deletes the state of the session identified by id.
+func (s *memoryStore) Delete(_ context.Context, id string) error {
+	s.states.Delete(id)
+	return nil
+}
+
+// FileStore is a Store keeping the states as JSON files in a directory.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a FileStore keeping the states in the given directory.
+// The directory is created if it does not exist.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Load loads the state of the session identified by id.
+func (s *FileStore) Load(_ context.Context, id string) (*State, error) {
+	stateJSON, err := os.ReadFile(s.path(id))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%s: %w", id, errdef.ErrNotFound)
+		}
+		return nil, err
+	}
+	var state State
+	if err := json.Unmarshal(stateJSON, &state); err != nil {
+		return nil, fmt.Errorf("failed to decode state of %s: %w", id, err)
+	}
+	return &state, nil
+}
+
+// Save saves the state of the session identified by id.
+// The state file is replaced atomically, so that a crash never leaves a
+// corrupted state behind.
+func (s *FileStore) Save(_ context.Context, id string, state *State) (err error) {
+	stateJSON, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	fp, err := os.CreateTemp(s.dir, "state_*.json.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(fp.Name())
+		}
+	}()
+	if _, err := fp.Write(stateJSON); err != nil {
+		fp.Close()
+		return err
+	}
+	if err := fp.Sync(); err != nil {
+		fp.Close()
+		return err
+	}
+	if err := fp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(fp.Name(), s.path(id))
+}
+
+// Delete deletes the state of the session identified by id.
+func (s *FileStore) Delete(_ context.Context, id string) error {
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path returns the path of the state file of the session identified by id.
+// Session IDs are hashed so that arbitrary IDs such as references are safe
+// to be used as file names.
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, digest.FromString(id).Encoded()+".json")
+}
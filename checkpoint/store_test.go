@@ -0,0 +1,74 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+)
+
+func TestStore(t *testing.T) {
+	fileStore, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	stores := map[string]Store{
+		"memory": NewMemoryStore(),
+		"file":   fileStore,
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			id := "localhost:5000/test:v1"
+			if _, err := store.Load(ctx, id); !errors.Is(err, errdef.ErrNotFound) {
+				t.Fatalf("Store.Load() error = %v, want %v", err, errdef.ErrNotFound)
+			}
+
+			want := &State{
+				Completed: []digest.Digest{digest.FromString("foo")},
+				Uploads: map[digest.Digest]registry.UploadSession{
+					digest.FromString("bar"): {Location: "https://localhost:5000/v2/test/blobs/uploads/uuid", Offset: 42},
+				},
+			}
+			if err := store.Save(ctx, id, want); err != nil {
+				t.Fatalf("Store.Save() error = %v", err)
+			}
+			got, err := store.Load(ctx, id)
+			if err != nil {
+				t.Fatalf("Store.Load() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Store.Load() = %v, want %v", got, want)
+			}
+
+			if err := store.Delete(ctx, id); err != nil {
+				t.Fatalf("Store.Delete() error = %v", err)
+			}
+			if _, err := store.Load(ctx, id); !errors.Is(err, errdef.ErrNotFound) {
+				t.Errorf("Store.Load() error = %v, want %v", err, errdef.ErrNotFound)
+			}
+			if err := store.Delete(ctx, id); err != nil {
+				t.Errorf("Store.Delete() error = %v", err)
+			}
+		})
+	}
+}
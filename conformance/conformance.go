@@ -0,0 +1,174 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conformance validates descriptors and manifests against the OCI
+// image-spec, for users validating artifacts and registries.
+//
+// The validation is stricter than what ORAS tolerates by default. Violations
+// are reported with errdef.ErrNonConformant.
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/docker"
+	"oras.land/oras-go/v2/internal/spec"
+)
+
+// MaxManifestSize is the maximum size of manifests that registries are
+// expected to accept.
+// Reference: https://github.com/opencontainers/distribution-spec/blob/v1.1.0-rc1/spec.md#pushing-manifests
+const MaxManifestSize int64 = 4 * 1024 * 1024 // 4 MiB
+
+// mediaTypeRegexp matches media types conforming to RFC 6838.
+// Reference: https://github.com/opencontainers/image-spec/blob/v1.1.0-rc3/descriptor.md#properties
+var mediaTypeRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9!#$&^_.+-]{0,126}/[A-Za-z0-9][A-Za-z0-9!#$&^_.+-]{0,126}$`)
+
+// ValidateDescriptor validates the media type, the digest and the size of
+// the descriptor.
+func ValidateDescriptor(desc ocispec.Descriptor) error {
+	if !mediaTypeRegexp.MatchString(desc.MediaType) {
+		return fmt.Errorf("%s: invalid media type %q: %w", desc.Digest, desc.MediaType, errdef.ErrNonConformant)
+	}
+	if err := content.ValidateDigest(desc.Digest); err != nil {
+		return fmt.Errorf("%s: %s: invalid digest: %v: %w", desc.Digest, desc.MediaType, err, errdef.ErrNonConformant)
+	}
+	if desc.Size < 0 {
+		return fmt.Errorf("%s: %s: negative size %d: %w", desc.Digest, desc.MediaType, desc.Size, errdef.ErrNonConformant)
+	}
+	if desc.ArtifactType != "" && !mediaTypeRegexp.MatchString(desc.ArtifactType) {
+		return fmt.Errorf("%s: %s: invalid artifact type %q: %w", desc.Digest, desc.MediaType, desc.ArtifactType, errdef.ErrNonConformant)
+	}
+	for key := range desc.Annotations {
+		if key == "" {
+			return fmt.Errorf("%s: %s: empty annotation key: %w", desc.Digest, desc.MediaType, errdef.ErrNonConformant)
+		}
+	}
+	return nil
+}
+
+// ValidateManifest validates the manifest content described by desc.
+// The content must match the descriptor, and the manifest must have the
+// mediaType field equal to the media type of the descriptor. Image
+// manifests, image indexes, artifact manifests and docker manifests are
+// further validated against their schemas. Manifests of other media types
+// are only checked to be JSON objects.
+func ValidateManifest(desc ocispec.Descriptor, manifestJSON []byte) error {
+	if err := ValidateDescriptor(desc); err != nil {
+		return err
+	}
+	if desc.Size > MaxManifestSize {
+		return fmt.Errorf("%s: %s: size %d exceeds %d: %w", desc.Digest, desc.MediaType, desc.Size, MaxManifestSize, errdef.ErrNonConformant)
+	}
+	if int64(len(manifestJSON)) != desc.Size || desc.Digest.Algorithm().FromBytes(manifestJSON) != desc.Digest {
+		return fmt.Errorf("%s: %s: content mismatches the descriptor: %w", desc.Digest, desc.MediaType, errdef.ErrNonConformant)
+	}
+
+	var header struct {
+		SchemaVersion *int   `json:"schemaVersion"`
+		MediaType     string `json:"mediaType"`
+	}
+	if err := json.Unmarshal(manifestJSON, &header); err != nil {
+		return fmt.Errorf("%s: %s: malformed manifest: %v: %w", desc.Digest, desc.MediaType, err, errdef.ErrNonConformant)
+	}
+	if header.MediaType != desc.MediaType {
+		return fmt.Errorf("%s: %s: mismatch mediaType field %q: %w", desc.Digest, desc.MediaType, header.MediaType, errdef.ErrNonConformant)
+	}
+
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%s: %s: %s: %w", desc.Digest, desc.MediaType, fmt.Sprintf(format, args...), errdef.ErrNonConformant)
+	}
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageManifest, docker.MediaTypeManifest:
+		if header.SchemaVersion == nil || *header.SchemaVersion != 2 {
+			return invalid("schemaVersion must be 2")
+		}
+		var manifest struct {
+			Config  *ocispec.Descriptor  `json:"config"`
+			Layers  []ocispec.Descriptor `json:"layers"`
+			Subject *ocispec.Descriptor  `json:"subject"`
+		}
+		if err := decodeStrict(manifestJSON, &manifest); err != nil {
+			return invalid("malformed manifest: %v", err)
+		}
+		if manifest.Config == nil {
+			return invalid("missing config")
+		}
+		if manifest.Layers == nil {
+			return invalid("missing layers")
+		}
+		return validateDescriptors(invalid, manifest.Subject, append([]ocispec.Descriptor{*manifest.Config}, manifest.Layers...)...)
+	case ocispec.MediaTypeImageIndex, docker.MediaTypeManifestList:
+		if header.SchemaVersion == nil || *header.SchemaVersion != 2 {
+			return invalid("schemaVersion must be 2")
+		}
+		var index struct {
+			Manifests []ocispec.Descriptor `json:"manifests"`
+			Subject   *ocispec.Descriptor  `json:"subject"`
+		}
+		if err := decodeStrict(manifestJSON, &index); err != nil {
+			return invalid("malformed index: %v", err)
+		}
+		if index.Manifests == nil {
+			return invalid("missing manifests")
+		}
+		return validateDescriptors(invalid, index.Subject, index.Manifests...)
+	case spec.MediaTypeArtifactManifest:
+		var manifest spec.Artifact
+		if err := decodeStrict(manifestJSON, &manifest); err != nil {
+			return invalid("malformed manifest: %v", err)
+		}
+		if !mediaTypeRegexp.MatchString(manifest.ArtifactType) {
+			return invalid("invalid artifactType %q", manifest.ArtifactType)
+		}
+		return validateDescriptors(invalid, manifest.Subject, manifest.Blobs...)
+	default:
+		if !bytes.HasPrefix(bytes.TrimSpace(manifestJSON), []byte("{")) {
+			return invalid("manifest is not a JSON object")
+		}
+		return nil
+	}
+}
+
+// validateDescriptors validates the descriptors in a manifest.
+func validateDescriptors(invalid func(format string, args ...interface{}) error, subject *ocispec.Descriptor, descs ...ocispec.Descriptor) error {
+	if subject != nil {
+		descs = append(descs, *subject)
+	}
+	for _, desc := range descs {
+		if err := ValidateDescriptor(desc); err != nil {
+			return invalid("invalid descriptor: %v", err)
+		}
+	}
+	return nil
+}
+
+// decodeStrict decodes the JSON into v, rejecting trailing data.
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return fmt.Errorf("unexpected data after the JSON object")
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestValidateDescriptor(t *testing.T) {
+	valid := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, []byte("hello"))
+	if err := ValidateDescriptor(valid); err != nil {
+		t.Errorf("ValidateDescriptor() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(desc *ocispec.Descriptor)
+	}{
+		{name: "missing media type", modify: func(desc *ocispec.Descriptor) { desc.MediaType = "" }},
+		{name: "invalid media type", modify: func(desc *ocispec.Descriptor) { desc.MediaType = "layer" }},
+		{name: "invalid digest", modify: func(desc *ocispec.Descriptor) { desc.Digest = "sha256:foo" }},
+		{name: "negative size", modify: func(desc *ocispec.Descriptor) { desc.Size = -1 }},
+		{name: "invalid artifact type", modify: func(desc *ocispec.Descriptor) { desc.ArtifactType = "sbom" }},
+		{name: "empty annotation key", modify: func(desc *ocispec.Descriptor) { desc.Annotations = map[string]string{"": "foo"} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desc := valid
+			tt.modify(&desc)
+			if err := ValidateDescriptor(desc); !errors.Is(err, errdef.ErrNonConformant) {
+				t.Errorf("ValidateDescriptor() error = %v, want %v", err, errdef.ErrNonConformant)
+			}
+		})
+	}
+}
+
+func TestValidateManifest(t *testing.T) {
+	config := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, []byte("{}"))
+	layer := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, []byte("hello"))
+	manifest := func(modify func(m map[string]interface{})) []byte {
+		m := map[string]interface{}{
+			"schemaVersion": 2,
+			"mediaType":     ocispec.MediaTypeImageManifest,
+			"config":        config,
+			"layers":        []ocispec.Descriptor{layer},
+		}
+		if modify != nil {
+			modify(m)
+		}
+		manifestJSON, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return manifestJSON
+	}
+	indexJSON, err := json.Marshal(ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifest(nil))},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		mediaType    string
+		manifestJSON []byte
+		wantErr      bool
+	}{
+		{name: "valid manifest", mediaType: ocispec.MediaTypeImageManifest, manifestJSON: manifest(nil)},
+		{name: "valid index", mediaType: ocispec.MediaTypeImageIndex, manifestJSON: indexJSON},
+		{name: "unknown media type", mediaType: "application/vnd.example+json", manifestJSON: []byte(`{"mediaType":"application/vnd.example+json"}`)},
+		{name: "mismatch content type", mediaType: ocispec.MediaTypeImageIndex, manifestJSON: manifest(nil), wantErr: true},
+		{name: "missing media type", mediaType: ocispec.MediaTypeImageManifest, manifestJSON: manifest(func(m map[string]interface{}) { delete(m, "mediaType") }), wantErr: true},
+		{name: "wrong schema version", mediaType: ocispec.MediaTypeImageManifest, manifestJSON: manifest(func(m map[string]interface{}) { m["schemaVersion"] = 1 }), wantErr: true},
+		{name: "missing config", mediaType: ocispec.MediaTypeImageManifest, manifestJSON: manifest(func(m map[string]interface{}) { delete(m, "config") }), wantErr: true},
+		{name: "missing layers", mediaType: ocispec.MediaTypeImageManifest, manifestJSON: manifest(func(m map[string]interface{}) { delete(m, "layers") }), wantErr: true},
+		{name: "invalid layer", mediaType: ocispec.MediaTypeImageManifest, manifestJSON: manifest(func(m map[string]interface{}) {
+			m["layers"] = []ocispec.Descriptor{{MediaType: "layer", Digest: layer.Digest, Size: layer.Size}}
+		}), wantErr: true},
+		{name: "wrong field type", mediaType: ocispec.MediaTypeImageManifest, manifestJSON: manifest(func(m map[string]interface{}) { m["layers"] = "foo" }), wantErr: true},
+		{name: "not JSON", mediaType: "application/vnd.example+json", manifestJSON: []byte("foo"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desc := content.NewDescriptorFromBytes(tt.mediaType, tt.manifestJSON)
+			err := ValidateManifest(desc, tt.manifestJSON)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, errdef.ErrNonConformant) {
+				t.Errorf("ValidateManifest() error = %v, want %v", err, errdef.ErrNonConformant)
+			}
+		})
+	}
+
+	// content mismatch
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifest(nil))
+	desc.Digest = digest.FromString("foo")
+	if err := ValidateManifest(desc, manifest(nil)); !errors.Is(err, errdef.ErrNonConformant) {
+		t.Errorf("ValidateManifest() error = %v, want %v", err, errdef.ErrNonConformant)
+	}
+}
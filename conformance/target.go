@@ -0,0 +1,93 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/internal/descriptor"
+)
+
+// Target is a CAS with generic tags. It has the same method set as
+// oras.Target.
+type Target interface {
+	content.Storage
+	content.TagResolver
+}
+
+// target is a Target rejecting non-conformant content.
+type target struct {
+	Target
+}
+
+// NewTarget wraps t to reject pushing, fetching and tagging non-conformant
+// descriptors and manifests with errdef.ErrNonConformant.
+func NewTarget(t Target) Target {
+	return &target{Target: t}
+}
+
+// Fetch fetches the content identified by the descriptor. Manifests are
+// validated before being returned.
+func (t *target) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	if err := ValidateDescriptor(target); err != nil {
+		return nil, err
+	}
+	if !descriptor.IsManifest(target) {
+		return t.Target.Fetch(ctx, target)
+	}
+	manifestJSON, err := content.FetchAll(ctx, t.Target, target)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateManifest(target, manifestJSON); err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(manifestJSON)), nil
+}
+
+// Push pushes the content, matching the expected descriptor. Manifests are
+// validated before being pushed.
+func (t *target) Push(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	if err := ValidateDescriptor(expected); err != nil {
+		return err
+	}
+	if !descriptor.IsManifest(expected) {
+		return t.Target.Push(ctx, expected, r)
+	}
+	if expected.Size > MaxManifestSize {
+		return ValidateManifest(expected, nil)
+	}
+	manifestJSON, err := content.ReadAll(r, expected)
+	if err != nil {
+		return err
+	}
+	if err := ValidateManifest(expected, manifestJSON); err != nil {
+		return err
+	}
+	return t.Target.Push(ctx, expected, bytes.NewReader(manifestJSON))
+}
+
+// Tag tags a descriptor with a reference string.
+func (t *target) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	if err := ValidateDescriptor(desc); err != nil {
+		return err
+	}
+	return t.Target.Tag(ctx, desc, reference)
+}
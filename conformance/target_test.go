@@ -0,0 +1,68 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestTarget(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	target := NewTarget(store)
+
+	// conformant content
+	manifestJSON := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[]}`)
+	manifest := content.NewDescriptorFromBytes(ocispec.MediaTypeImageIndex, manifestJSON)
+	if err := target.Push(ctx, manifest, bytes.NewReader(manifestJSON)); err != nil {
+		t.Fatalf("Target.Push() error = %v", err)
+	}
+	if err := target.Tag(ctx, manifest, "latest"); err != nil {
+		t.Fatalf("Target.Tag() error = %v", err)
+	}
+	got, err := content.FetchAll(ctx, target, manifest)
+	if err != nil {
+		t.Fatalf("Target.Fetch() error = %v", err)
+	}
+	if !bytes.Equal(got, manifestJSON) {
+		t.Errorf("Target.Fetch() = %s, want %s", got, manifestJSON)
+	}
+
+	// non-conformant content
+	badJSON := []byte(`{"manifests":[]}`)
+	bad := content.NewDescriptorFromBytes(ocispec.MediaTypeImageIndex, badJSON)
+	if err := target.Push(ctx, bad, bytes.NewReader(badJSON)); !errors.Is(err, errdef.ErrNonConformant) {
+		t.Errorf("Target.Push() error = %v, want %v", err, errdef.ErrNonConformant)
+	}
+	if err := store.Push(ctx, bad, bytes.NewReader(badJSON)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	if _, err := target.Fetch(ctx, bad); !errors.Is(err, errdef.ErrNonConformant) {
+		t.Errorf("Target.Fetch() error = %v, want %v", err, errdef.ErrNonConformant)
+	}
+	blob := []byte("hello")
+	if err := target.Push(ctx, content.NewDescriptorFromBytes("blob", blob), bytes.NewReader(blob)); !errors.Is(err, errdef.ErrNonConformant) {
+		t.Errorf("Target.Push() error = %v, want %v", err, errdef.ErrNonConformant)
+	}
+}
@@ -188,7 +188,58 @@ type ResolveOptions struct {
 	MaxMetadataBytes int64
 }
 
+// ParsePlatform parses the platform specifier in the format of
+// `os/arch[/variant][:os_version]`, such as `linux/amd64`, `linux/arm64/v8`
+// or `windows/amd64:10.0.20348.1726`, for selecting the manifest of the
+// platform by CopyOptions.WithTargetPlatform or ResolveOptions.TargetPlatform.
+func ParsePlatform(specifier string) (*ocispec.Platform, error) {
+	return platform.Parse(specifier)
+}
+
+// PlatformNotFoundError is returned when no manifest matches the target
+// platform.
+type PlatformNotFoundError struct {
+	// Descriptor describes the manifest or the index searched.
+	Descriptor ocispec.Descriptor
+	// Platform is the target platform.
+	Platform *ocispec.Platform
+	// Available lists the platforms found in the index, including the
+	// nested indexes, or the platform of the manifest.
+	Available []ocispec.Platform
+
+	err error
+}
+
+// Error returns the error message.
+func (e *PlatformNotFoundError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error, which matches errdef.ErrNotFound.
+func (e *PlatformNotFoundError) Unwrap() error {
+	return e.err
+}
+
+// selectManifest selects the manifest matching the platform p from root, and
+// returns a *PlatformNotFoundError if not found.
+func selectManifest(ctx context.Context, src content.ReadOnlyStorage, root ocispec.Descriptor, p *ocispec.Platform) (ocispec.Descriptor, error) {
+	desc, err := platform.SelectManifest(ctx, src, root, p)
+	var nfErr *platform.NotFoundError
+	if errors.As(err, &nfErr) {
+		return ocispec.Descriptor{}, &PlatformNotFoundError{
+			Descriptor: nfErr.Descriptor,
+			Platform:   nfErr.Platform,
+			Available:  nfErr.Available,
+			err:        err,
+		}
+	}
+	return desc, err
+}
+
 // Resolve resolves a descriptor with provided reference from the target.
+// If opts.TargetPlatform is set, indexes are descended into to select the
+// manifest matching the platform, and a *PlatformNotFoundError is returned
+// if no manifest matches.
 func Resolve(ctx context.Context, target ReadOnlyTarget, reference string, opts ResolveOptions) (ocispec.Descriptor, error) {
 	if opts.TargetPlatform == nil {
 		return target.Resolve(ctx, reference)
@@ -230,7 +281,7 @@ func resolve(ctx context.Context, target ReadOnlyTarget, proxy *cas.Proxy, refer
 			}
 			// stop caching as SelectManifest may fetch a config blob
 			proxy.StopCaching = true
-			return platform.SelectManifest(ctx, proxy, desc, opts.TargetPlatform)
+			return selectManifest(ctx, proxy, desc, opts.TargetPlatform)
 		default:
 			return ocispec.Descriptor{}, fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, errdef.ErrUnsupported)
 		}
@@ -240,7 +291,25 @@ func resolve(ctx context.Context, target ReadOnlyTarget, proxy *cas.Proxy, refer
 	if err != nil {
 		return ocispec.Descriptor{}, err
 	}
-	return platform.SelectManifest(ctx, target, desc, opts.TargetPlatform)
+	return selectManifest(ctx, target, desc, opts.TargetPlatform)
+}
+
+// ExistsReference returns true if the reference exists in the target.
+// If the target implements registry.ReferenceExister, the existence is
+// checked without resolving the reference, such as a HEAD request to a
+// remote registry. Otherwise, the reference is resolved, and a reference
+// resolved with errdef.ErrNotFound is reported as not existing.
+func ExistsReference(ctx context.Context, target content.Resolver, reference string) (bool, error) {
+	if exister, ok := target.(registry.ReferenceExister); ok {
+		return exister.ExistsReference(ctx, reference)
+	}
+	if _, err := target.Resolve(ctx, reference); err != nil {
+		if errors.Is(err, errdef.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
 }
 
 // DefaultFetchOptions provides the default FetchOptions.
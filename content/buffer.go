@@ -0,0 +1,37 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content
+
+import "oras.land/oras-go/v2/internal/pool"
+
+// SetFileBufferSize sets the size of the pooled buffers used by the stores
+// for copying content from and to files, such as the ingestion of oci.Store
+// and file.Store. Larger buffers lead to less disk I/O at the cost of more
+// memory per concurrent copy.
+// If size is less than or equal to 0, the default size (currently 1 MiB) is
+// used.
+func SetFileBufferSize(size int) {
+	pool.File.SetSize(size)
+}
+
+// SetStreamBufferSize sets the size of the pooled buffers used for copying
+// content between streams, such as the blobs copied by oras.Copy and the
+// content read for verification.
+// If size is less than or equal to 0, the default size (currently 32 KiB) is
+// used.
+func SetStreamBufferSize(size int) {
+	pool.Stream.SetSize(size)
+}
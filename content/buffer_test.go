@@ -0,0 +1,46 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content_test
+
+import (
+	"testing"
+
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/internal/pool"
+)
+
+func TestSetBufferSize(t *testing.T) {
+	defer content.SetFileBufferSize(0)
+	defer content.SetStreamBufferSize(0)
+
+	content.SetFileBufferSize(4 << 20)
+	if got := pool.File.Size(); got != 4<<20 {
+		t.Errorf("file buffer size = %d, want %d", got, 4<<20)
+	}
+	content.SetStreamBufferSize(64 << 10)
+	if got := pool.Stream.Size(); got != 64<<10 {
+		t.Errorf("stream buffer size = %d, want %d", got, 64<<10)
+	}
+
+	content.SetFileBufferSize(0)
+	if got := pool.File.Size(); got != pool.DefaultFileBufferSize {
+		t.Errorf("file buffer size = %d, want %d", got, pool.DefaultFileBufferSize)
+	}
+	content.SetStreamBufferSize(-1)
+	if got := pool.Stream.Size(); got != pool.DefaultStreamBufferSize {
+		t.Errorf("stream buffer size = %d, want %d", got, pool.DefaultStreamBufferSize)
+	}
+}
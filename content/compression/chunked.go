@@ -0,0 +1,132 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compression
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Layer annotations of zstd:chunked layers.
+// Reference: https://github.com/containers/storage/tree/main/pkg/chunked
+const (
+	// AnnotationZstdChunkedManifestChecksum is the annotation for the digest
+	// of the zstd:chunked manifest.
+	AnnotationZstdChunkedManifestChecksum = "io.github.containers.zstd-chunked.manifest-checksum"
+	// AnnotationZstdChunkedManifestPosition is the annotation for the
+	// position of the zstd:chunked manifest in the layer, in the format of
+	// "offset:length:uncompressedLength:type".
+	AnnotationZstdChunkedManifestPosition = "io.github.containers.zstd-chunked.manifest-position"
+	// AnnotationZstdChunkedTarSplitChecksum is the annotation for the digest
+	// of the tar-split data.
+	AnnotationZstdChunkedTarSplitChecksum = "io.github.containers.zstd-chunked.tarsplit-checksum"
+	// AnnotationZstdChunkedTarSplitPosition is the annotation for the
+	// position of the tar-split data in the layer, in the format of
+	// "offset:length:uncompressedLength".
+	AnnotationZstdChunkedTarSplitPosition = "io.github.containers.zstd-chunked.tarsplit-position"
+)
+
+// zstdChunkedAnnotations are the annotations carrying the zstd:chunked
+// metadata.
+var zstdChunkedAnnotations = []string{
+	AnnotationZstdChunkedManifestChecksum,
+	AnnotationZstdChunkedManifestPosition,
+	AnnotationZstdChunkedTarSplitChecksum,
+	AnnotationZstdChunkedTarSplitPosition,
+}
+
+// Position is the position of a zstd:chunked metadata frame in a layer.
+type Position struct {
+	// Offset is the offset of the compressed frame in the layer.
+	Offset int64
+	// Length is the length of the compressed frame.
+	Length int64
+	// UncompressedLength is the length of the uncompressed metadata.
+	UncompressedLength int64
+}
+
+// IsZstdChunked checks if desc describes a zstd:chunked layer.
+// zstd:chunked layers are zstd layers annotated with the position of the
+// zstd:chunked manifest.
+func IsZstdChunked(desc ocispec.Descriptor) bool {
+	if alg, ok := FromMediaType(desc.MediaType); !ok || alg != Zstd {
+		return false
+	}
+	_, ok := desc.Annotations[AnnotationZstdChunkedManifestPosition]
+	return ok
+}
+
+// ZstdChunkedAnnotations returns the zstd:chunked metadata annotations of
+// desc, which should be preserved whenever the descriptor of the layer is
+// rewritten. Returns nil if desc has no such annotations.
+func ZstdChunkedAnnotations(desc ocispec.Descriptor) map[string]string {
+	var annotations map[string]string
+	for _, key := range zstdChunkedAnnotations {
+		if value, ok := desc.Annotations[key]; ok {
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			annotations[key] = value
+		}
+	}
+	return annotations
+}
+
+// ZstdChunkedManifestPosition returns the position of the zstd:chunked
+// manifest in the layer described by desc. The manifest can then be fetched
+// by content.FetchRange.
+func ZstdChunkedManifestPosition(desc ocispec.Descriptor) (Position, error) {
+	value, ok := desc.Annotations[AnnotationZstdChunkedManifestPosition]
+	if !ok {
+		return Position{}, fmt.Errorf("%s: %s: not a zstd:chunked layer", desc.Digest, desc.MediaType)
+	}
+	pos, err := parsePosition(value)
+	if err != nil {
+		return Position{}, fmt.Errorf("%s: %s: invalid manifest position %q: %w", desc.Digest, desc.MediaType, value, err)
+	}
+	if pos.Offset+pos.Length > desc.Size {
+		return Position{}, fmt.Errorf("%s: %s: manifest position %q out of range", desc.Digest, desc.MediaType, value)
+	}
+	return pos, nil
+}
+
+// parsePosition parses a position in the format of
+// "offset:length:uncompressedLength" with optional trailing fields.
+func parsePosition(value string) (Position, error) {
+	fields := strings.Split(value, ":")
+	if len(fields) < 3 {
+		return Position{}, fmt.Errorf("expected at least 3 fields, got %d", len(fields))
+	}
+	var numbers [3]int64
+	for i := range numbers {
+		n, err := strconv.ParseInt(fields[i], 10, 64)
+		if err != nil {
+			return Position{}, err
+		}
+		if n < 0 {
+			return Position{}, fmt.Errorf("negative value %d", n)
+		}
+		numbers[i] = n
+	}
+	return Position{
+		Offset:             numbers[0],
+		Length:             numbers[1],
+		UncompressedLength: numbers[2],
+	}, nil
+}
@@ -0,0 +1,72 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compression
+
+import (
+	"reflect"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestZstdChunked(t *testing.T) {
+	desc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageLayerZstd,
+		Size:      1024,
+		Annotations: map[string]string{
+			AnnotationZstdChunkedManifestChecksum: "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae",
+			AnnotationZstdChunkedManifestPosition: "512:256:1024:1",
+			ocispec.AnnotationTitle:               "layer",
+		},
+	}
+	if !IsZstdChunked(desc) {
+		t.Error("IsZstdChunked() = false, want true")
+	}
+	want := map[string]string{
+		AnnotationZstdChunkedManifestChecksum: desc.Annotations[AnnotationZstdChunkedManifestChecksum],
+		AnnotationZstdChunkedManifestPosition: desc.Annotations[AnnotationZstdChunkedManifestPosition],
+	}
+	if got := ZstdChunkedAnnotations(desc); !reflect.DeepEqual(got, want) {
+		t.Errorf("ZstdChunkedAnnotations() = %v, want %v", got, want)
+	}
+	pos, err := ZstdChunkedManifestPosition(desc)
+	if err != nil {
+		t.Fatalf("ZstdChunkedManifestPosition() error = %v", err)
+	}
+	if want := (Position{Offset: 512, Length: 256, UncompressedLength: 1024}); pos != want {
+		t.Errorf("ZstdChunkedManifestPosition() = %v, want %v", pos, want)
+	}
+
+	// invalid positions
+	for _, value := range []string{"512:256", "a:256:1024", "-1:256:1024", "1000:256:1024"} {
+		desc.Annotations[AnnotationZstdChunkedManifestPosition] = value
+		if _, err := ZstdChunkedManifestPosition(desc); err == nil {
+			t.Errorf("ZstdChunkedManifestPosition(%q) error = nil, want error", value)
+		}
+	}
+
+	// plain zstd layer
+	plain := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayerZstd}
+	if IsZstdChunked(plain) {
+		t.Error("IsZstdChunked() = true, want false")
+	}
+	if got := ZstdChunkedAnnotations(plain); got != nil {
+		t.Errorf("ZstdChunkedAnnotations() = %v, want nil", got)
+	}
+	if _, err := ZstdChunkedManifestPosition(plain); err == nil {
+		t.Error("ZstdChunkedManifestPosition() error = nil, want error")
+	}
+}
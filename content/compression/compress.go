@@ -0,0 +1,75 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compression
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+
+	"oras.land/oras-go/v2/errdef"
+)
+
+// Compressor returns a writer compressing the content written to it into w.
+// The content is flushed to w when the writer is closed.
+type Compressor func(w io.Writer) (io.WriteCloser, error)
+
+// compressors maps algorithms to the registered compressors.
+var compressors sync.Map // map[Algorithm]Compressor
+
+func init() {
+	RegisterCompressor(Gzip, func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriter(w), nil
+	})
+	RegisterCompressor(Uncompressed, func(w io.Writer) (io.WriteCloser, error) {
+		return nopWriteCloser{w}, nil
+	})
+}
+
+// RegisterCompressor registers the compressor of the given algorithm,
+// replacing the previously registered one if any.
+// Like decompressors, a zstd compressor must be registered before content
+// can be compressed by zstd. For example, with
+// github.com/klauspost/compress/zstd:
+//
+//	compression.RegisterCompressor(compression.Zstd, func(w io.Writer) (io.WriteCloser, error) {
+//		return zstd.NewWriter(w)
+//	})
+func RegisterCompressor(alg Algorithm, c Compressor) {
+	compressors.Store(alg, c)
+}
+
+// Compress returns a writer compressing the content written to it into w
+// with the given algorithm.
+// Returns ErrUnsupported if no compressor is registered for the algorithm.
+func Compress(alg Algorithm, w io.Writer) (io.WriteCloser, error) {
+	value, ok := compressors.Load(alg)
+	if !ok {
+		return nil, fmt.Errorf("%s: no registered compressor: %w", alg, errdef.ErrUnsupported)
+	}
+	return value.(Compressor)(w)
+}
+
+// nopWriteCloser is an io.WriteCloser with a no-op Close.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close does nothing.
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compression
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestCompress(t *testing.T) {
+	content := []byte("hello world")
+	for _, alg := range []Algorithm{Gzip, Uncompressed} {
+		t.Run(string(alg), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := Compress(alg, &buf)
+			if err != nil {
+				t.Fatalf("Compress() error = %v", err)
+			}
+			if _, err := w.Write(content); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			rc, got, err := NewReader(&buf)
+			if err != nil {
+				t.Fatalf("NewReader() error = %v", err)
+			}
+			defer rc.Close()
+			if got != alg {
+				t.Errorf("NewReader() algorithm = %v, want %v", got, alg)
+			}
+			data, err := io.ReadAll(rc)
+			if err != nil {
+				t.Fatal("failed to read:", err)
+			}
+			if !bytes.Equal(data, content) {
+				t.Errorf("decompressed content = %q, want %q", data, content)
+			}
+		})
+	}
+}
+
+func TestCompress_Zstd(t *testing.T) {
+	// no zstd compressor registered by default
+	if _, err := Compress(Zstd, io.Discard); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Fatalf("Compress() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+}
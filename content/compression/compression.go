@@ -0,0 +1,112 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package compression provides detection, compression and decompression of
+// layers, including layers in the zstd and zstd:chunked formats.
+//
+// Gzip is supported out of the box. Since the standard library has no zstd
+// decoder, a zstd decompressor must be registered by RegisterDecompressor
+// before zstd layers can be decompressed. For example, with
+// github.com/klauspost/compress/zstd:
+//
+//	compression.RegisterDecompressor(compression.Zstd, func(r io.Reader) (io.ReadCloser, error) {
+//		zr, err := zstd.NewReader(r)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return zr.IOReadCloser(), nil
+//	})
+package compression
+
+import (
+	"bytes"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/internal/docker"
+)
+
+// Algorithm is a compression algorithm.
+type Algorithm string
+
+// Compression algorithms.
+const (
+	// Uncompressed indicates that the content is not compressed.
+	Uncompressed Algorithm = "uncompressed"
+	// Gzip is the gzip compression algorithm.
+	Gzip Algorithm = "gzip"
+	// Zstd is the zstd compression algorithm.
+	Zstd Algorithm = "zstd"
+)
+
+// magic numbers of the compression algorithms.
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// headerSize is the number of bytes needed by Detect.
+const headerSize = 4
+
+// Detect detects the compression algorithm based on the magic number at the
+// beginning of the content.
+func Detect(header []byte) Algorithm {
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		return Gzip
+	case bytes.HasPrefix(header, zstdMagic):
+		return Zstd
+	default:
+		return Uncompressed
+	}
+}
+
+// FromMediaType returns the compression algorithm of the layers of the given
+// media type. Returns false if the media type is not a known layer media type.
+func FromMediaType(mediaType string) (Algorithm, bool) {
+	switch mediaType {
+	case ocispec.MediaTypeImageLayer, ocispec.MediaTypeImageLayerNonDistributable:
+		return Uncompressed, true
+	case ocispec.MediaTypeImageLayerGzip, ocispec.MediaTypeImageLayerNonDistributableGzip,
+		docker.MediaTypeLayer, docker.MediaTypeForeignLayer:
+		return Gzip, true
+	case ocispec.MediaTypeImageLayerZstd, ocispec.MediaTypeImageLayerNonDistributableZstd:
+		return Zstd, true
+	}
+	// media types with structured syntax suffixes, such as
+	// application/vnd.example.layer.v1.tar+zstd
+	switch {
+	case strings.HasSuffix(mediaType, "+gzip"):
+		return Gzip, true
+	case strings.HasSuffix(mediaType, "+zstd"):
+		return Zstd, true
+	}
+	return "", false
+}
+
+// LayerMediaType returns the OCI layer media type for the given compression
+// algorithm. Returns an empty string if the algorithm is unknown.
+func LayerMediaType(alg Algorithm) string {
+	switch alg {
+	case Uncompressed:
+		return ocispec.MediaTypeImageLayer
+	case Gzip:
+		return ocispec.MediaTypeImageLayerGzip
+	case Zstd:
+		return ocispec.MediaTypeImageLayerZstd
+	default:
+		return ""
+	}
+}
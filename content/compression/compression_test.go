@@ -0,0 +1,81 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compression
+
+import (
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/internal/docker"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   Algorithm
+	}{
+		{name: "gzip", header: []byte{0x1f, 0x8b, 0x08, 0x00}, want: Gzip},
+		{name: "zstd", header: []byte{0x28, 0xb5, 0x2f, 0xfd}, want: Zstd},
+		{name: "tar", header: []byte("test"), want: Uncompressed},
+		{name: "short", header: []byte{0x28}, want: Uncompressed},
+		{name: "empty", want: Uncompressed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.header); got != tt.want {
+				t.Errorf("Detect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromMediaType(t *testing.T) {
+	tests := []struct {
+		mediaType string
+		want      Algorithm
+		wantOK    bool
+	}{
+		{mediaType: ocispec.MediaTypeImageLayer, want: Uncompressed, wantOK: true},
+		{mediaType: ocispec.MediaTypeImageLayerGzip, want: Gzip, wantOK: true},
+		{mediaType: ocispec.MediaTypeImageLayerZstd, want: Zstd, wantOK: true},
+		{mediaType: ocispec.MediaTypeImageLayerNonDistributableZstd, want: Zstd, wantOK: true},
+		{mediaType: docker.MediaTypeLayer, want: Gzip, wantOK: true},
+		{mediaType: docker.MediaTypeForeignLayer, want: Gzip, wantOK: true},
+		{mediaType: "application/vnd.example.layer.v1.tar+zstd", want: Zstd, wantOK: true},
+		{mediaType: ocispec.MediaTypeImageManifest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mediaType, func(t *testing.T) {
+			got, ok := FromMediaType(tt.mediaType)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("FromMediaType() = (%v, %v), want (%v, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLayerMediaType(t *testing.T) {
+	for _, alg := range []Algorithm{Uncompressed, Gzip, Zstd} {
+		mediaType := LayerMediaType(alg)
+		if got, ok := FromMediaType(mediaType); !ok || got != alg {
+			t.Errorf("FromMediaType(LayerMediaType(%v)) = (%v, %v), want (%v, true)", alg, got, ok, alg)
+		}
+	}
+	if got := LayerMediaType("unknown"); got != "" {
+		t.Errorf("LayerMediaType() = %v, want empty", got)
+	}
+}
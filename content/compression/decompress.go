@@ -0,0 +1,79 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compression
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+
+	"oras.land/oras-go/v2/errdef"
+)
+
+// Decompressor returns a reader decompressing the content read from r.
+type Decompressor func(r io.Reader) (io.ReadCloser, error)
+
+// decompressors maps algorithms to the registered decompressors.
+var decompressors sync.Map // map[Algorithm]Decompressor
+
+func init() {
+	RegisterDecompressor(Gzip, func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	})
+	RegisterDecompressor(Uncompressed, func(r io.Reader) (io.ReadCloser, error) {
+		return io.NopCloser(r), nil
+	})
+}
+
+// RegisterDecompressor registers the decompressor of the given algorithm,
+// replacing the previously registered one if any.
+// Since zstd:chunked layers are valid zstd streams whose metadata are stored
+// in skippable frames, the zstd decompressor also decompresses zstd:chunked
+// layers.
+func RegisterDecompressor(alg Algorithm, d Decompressor) {
+	decompressors.Store(alg, d)
+}
+
+// Decompress returns a reader decompressing the content read from r with the
+// given algorithm.
+// Returns ErrUnsupported if no decompressor is registered for the algorithm.
+func Decompress(alg Algorithm, r io.Reader) (io.ReadCloser, error) {
+	value, ok := decompressors.Load(alg)
+	if !ok {
+		return nil, fmt.Errorf("%s: no registered decompressor: %w", alg, errdef.ErrUnsupported)
+	}
+	return value.(Decompressor)(r)
+}
+
+// NewReader detects the compression algorithm of the content read from r,
+// and returns a reader decompressing the content.
+// Returns ErrUnsupported if no decompressor is registered for the detected
+// algorithm.
+func NewReader(r io.Reader) (io.ReadCloser, Algorithm, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(headerSize)
+	if err != nil && err != io.EOF {
+		return nil, "", err
+	}
+	alg := Detect(header)
+	rc, err := Decompress(alg, br)
+	if err != nil {
+		return nil, "", err
+	}
+	return rc, alg, nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"testing"
+
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestNewReader(t *testing.T) {
+	content := []byte("hello world")
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want Algorithm
+	}{
+		{name: "gzip", data: buf.Bytes(), want: Gzip},
+		{name: "uncompressed", data: content, want: Uncompressed},
+		{name: "short", data: []byte("hi"), want: Uncompressed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc, alg, err := NewReader(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("NewReader() error = %v", err)
+			}
+			defer rc.Close()
+			if alg != tt.want {
+				t.Errorf("NewReader() algorithm = %v, want %v", alg, tt.want)
+			}
+			got, err := io.ReadAll(rc)
+			if err != nil {
+				t.Fatal("failed to read:", err)
+			}
+			want := content
+			if tt.want == Uncompressed {
+				want = tt.data
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("NewReader() content = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestNewReader_Zstd(t *testing.T) {
+	frame := append([]byte{0x28, 0xb5, 0x2f, 0xfd}, "frame"...)
+
+	// no zstd decompressor registered by default
+	if _, _, err := NewReader(bytes.NewReader(frame)); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Fatalf("NewReader() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+
+	// fake decompressor returning the frame as is
+	RegisterDecompressor(Zstd, func(r io.Reader) (io.ReadCloser, error) {
+		return io.NopCloser(r), nil
+	})
+	defer decompressors.Delete(Zstd)
+	rc, alg, err := NewReader(bytes.NewReader(frame))
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	defer rc.Close()
+	if alg != Zstd {
+		t.Errorf("NewReader() algorithm = %v, want %v", alg, Zstd)
+	}
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal("failed to read:", err)
+	}
+	if !bytes.Equal(got, frame) {
+		t.Errorf("NewReader() content = %q, want %q", got, frame)
+	}
+}
@@ -0,0 +1,290 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package containerd provides a Target backed by the content store and the
+// image store of containerd, so that copied images are recorded as images
+// in a containerd namespace.
+//
+// containerd is accessed over gRPC, which is not a dependency of this module.
+// Instead, the Target is built on the ContentStore and ImageStore interfaces,
+// which are satisfied by thin adapters over the content.Store and
+// images.Store of a containerd client, mapping the errors to errdef.
+package containerd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/docker"
+	"oras.land/oras-go/v2/internal/spec"
+)
+
+// DefaultNamespace is the default namespace of containerd.
+const DefaultNamespace = "default"
+
+// labelGCRefContent is the prefix of the labels by which the garbage collector
+// of containerd finds the content referenced by a piece of content.
+// Reference: https://github.com/containerd/containerd/blob/main/docs/garbage-collection.md
+const labelGCRefContent = "containerd.io/gc.ref.content."
+
+// ContentInfo describes a piece of content in the content store.
+type ContentInfo struct {
+	// Digest is the digest of the content.
+	Digest digest.Digest
+	// Size is the size of the content.
+	Size int64
+	// Labels are the labels of the content.
+	Labels map[string]string
+}
+
+// ContentStore is the subset of the containerd content store used by Target.
+// The namespace of each call is obtained by NamespaceFromContext.
+type ContentStore interface {
+	// Info returns the information of the content identified by dgst.
+	// Returns errdef.ErrNotFound if the content does not exist.
+	Info(ctx context.Context, dgst digest.Digest) (ContentInfo, error)
+	// Fetch fetches the content identified by the descriptor.
+	// Returns errdef.ErrNotFound if the content does not exist.
+	Fetch(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error)
+	// Write writes the content, verifies it against the descriptor, and
+	// commits it with the given labels.
+	// Returns errdef.ErrAlreadyExists if the content exists.
+	Write(ctx context.Context, desc ocispec.Descriptor, r io.Reader, labels map[string]string) error
+}
+
+// Image is an image record in the containerd image store.
+type Image struct {
+	// Name is the name of the image, such as
+	// "docker.io/library/hello-world:latest".
+	Name string
+	// Target is the descriptor of the root manifest of the image.
+	Target ocispec.Descriptor
+	// Labels are the labels of the image.
+	Labels map[string]string
+}
+
+// ImageStore is the subset of the containerd image store used by Target.
+// The namespace of each call is obtained by NamespaceFromContext.
+type ImageStore interface {
+	// Get returns the image record with the given name.
+	// Returns errdef.ErrNotFound if the image does not exist.
+	Get(ctx context.Context, name string) (Image, error)
+	// Create creates an image record.
+	// Returns errdef.ErrAlreadyExists if the image exists.
+	Create(ctx context.Context, image Image) (Image, error)
+	// Update updates the fields of an image record specified by fieldpaths,
+	// such as "target". All fields are updated if fieldpaths is empty.
+	// Returns errdef.ErrNotFound if the image does not exist.
+	Update(ctx context.Context, image Image, fieldpaths ...string) (Image, error)
+}
+
+// namespaceKey is the context key of the containerd namespace.
+type namespaceKey struct{}
+
+// WithNamespace returns a context with the containerd namespace attached.
+func WithNamespace(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, namespaceKey{}, namespace)
+}
+
+// NamespaceFromContext returns the containerd namespace attached to the
+// context. Returns DefaultNamespace if there is none.
+func NamespaceFromContext(ctx context.Context) string {
+	if namespace, ok := ctx.Value(namespaceKey{}).(string); ok && namespace != "" {
+		return namespace
+	}
+	return DefaultNamespace
+}
+
+// Target is a Target backed by the containerd content and image stores.
+//
+// Manifests and indexes are committed with the garbage collection labels
+// referencing their successors, and tagging a manifest creates or updates
+// the image record, so that the image is protected from the garbage
+// collector and listed by the runtime. Unpacking the layers into a
+// snapshotter is left to the runtime, e.g. `ctr image unpack`.
+type Target struct {
+	// Namespace is the containerd namespace of the images and content.
+	// If empty, the namespace attached to the context is used, falling back
+	// to DefaultNamespace.
+	Namespace string
+	// ImageLabels are the labels set on the image records created or
+	// updated by Tag.
+	ImageLabels map[string]string
+
+	contentStore ContentStore
+	imageStore   ImageStore
+}
+
+// New creates a Target over the content store and the image store of
+// containerd.
+func New(contentStore ContentStore, imageStore ImageStore) *Target {
+	return &Target{
+		contentStore: contentStore,
+		imageStore:   imageStore,
+	}
+}
+
+// withNamespace returns the context with the namespace of the target.
+func (t *Target) withNamespace(ctx context.Context) context.Context {
+	if t.Namespace == "" {
+		return WithNamespace(ctx, NamespaceFromContext(ctx))
+	}
+	return WithNamespace(ctx, t.Namespace)
+}
+
+// Fetch fetches the content identified by the descriptor.
+func (t *Target) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	return t.contentStore.Fetch(t.withNamespace(ctx), target)
+}
+
+// Push pushes the content, matching the expected descriptor.
+// Manifests and indexes are committed with the garbage collection labels
+// referencing their successors.
+func (t *Target) Push(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	ctx = t.withNamespace(ctx)
+	if !isManifest(expected) {
+		return t.contentStore.Write(ctx, expected, r, nil)
+	}
+
+	manifestJSON, err := content.ReadAll(r, expected)
+	if err != nil {
+		return err
+	}
+	labels, err := gcLabels(expected, manifestJSON)
+	if err != nil {
+		return fmt.Errorf("%s: %s: failed to generate gc labels: %w", expected.Digest, expected.MediaType, err)
+	}
+	return t.contentStore.Write(ctx, expected, bytes.NewReader(manifestJSON), labels)
+}
+
+// Exists returns true if the described content exists.
+func (t *Target) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	info, err := t.contentStore.Info(t.withNamespace(ctx), target.Digest)
+	if err != nil {
+		if errors.Is(err, errdef.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return info.Size == target.Size, nil
+}
+
+// Resolve resolves the image name, such as
+// "docker.io/library/hello-world:latest", to the descriptor of its root
+// manifest.
+func (t *Target) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+	if reference == "" {
+		return ocispec.Descriptor{}, errdef.ErrMissingReference
+	}
+	image, err := t.imageStore.Get(t.withNamespace(ctx), reference)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: %w", reference, err)
+	}
+	return image.Target, nil
+}
+
+// Tag creates or updates the image record with the name reference, such as
+// "docker.io/library/hello-world:latest", pointing to the content described
+// by desc.
+// Returns ErrNotFound if the content does not exist.
+func (t *Target) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	if reference == "" {
+		return errdef.ErrMissingReference
+	}
+	ctx = t.withNamespace(ctx)
+	exists, err := t.Exists(ctx, desc)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, errdef.ErrNotFound)
+	}
+
+	image := Image{
+		Name:   reference,
+		Target: desc,
+		Labels: t.ImageLabels,
+	}
+	if _, err := t.imageStore.Create(ctx, image); err == nil || !errors.Is(err, errdef.ErrAlreadyExists) {
+		return err
+	}
+	fieldpaths := []string{"target"}
+	for key := range t.ImageLabels {
+		fieldpaths = append(fieldpaths, "labels."+key)
+	}
+	_, err = t.imageStore.Update(ctx, image, fieldpaths...)
+	return err
+}
+
+// isManifest reports whether desc describes a manifest or an index, which
+// references other content.
+func isManifest(desc ocispec.Descriptor) bool {
+	switch desc.MediaType {
+	case docker.MediaTypeManifest, docker.MediaTypeManifestList,
+		ocispec.MediaTypeImageManifest, ocispec.MediaTypeImageIndex,
+		spec.MediaTypeArtifactManifest:
+		return true
+	}
+	return false
+}
+
+// gcLabels generates the garbage collection labels referencing the
+// successors of the manifest or index, following the label keys used by
+// containerd:
+//   - "containerd.io/gc.ref.content.config" for the config of a manifest.
+//   - "containerd.io/gc.ref.content.l.<i>" for the layers of a manifest.
+//   - "containerd.io/gc.ref.content.m.<i>" for the manifests of an index.
+func gcLabels(desc ocispec.Descriptor, manifestJSON []byte) (map[string]string, error) {
+	labels := make(map[string]string)
+	switch desc.MediaType {
+	case docker.MediaTypeManifest, ocispec.MediaTypeImageManifest:
+		var manifest ocispec.Manifest
+		if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+			return nil, err
+		}
+		if manifest.Config.Digest != "" {
+			labels[labelGCRefContent+"config"] = manifest.Config.Digest.String()
+		}
+		for i, layer := range manifest.Layers {
+			labels[labelGCRefContent+"l."+strconv.Itoa(i)] = layer.Digest.String()
+		}
+	case docker.MediaTypeManifestList, ocispec.MediaTypeImageIndex:
+		var index ocispec.Index
+		if err := json.Unmarshal(manifestJSON, &index); err != nil {
+			return nil, err
+		}
+		for i, manifest := range index.Manifests {
+			labels[labelGCRefContent+"m."+strconv.Itoa(i)] = manifest.Digest.String()
+		}
+	case spec.MediaTypeArtifactManifest:
+		var manifest spec.Artifact
+		if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+			return nil, err
+		}
+		for i, blob := range manifest.Blobs {
+			labels[labelGCRefContent+"l."+strconv.Itoa(i)] = blob.Digest.String()
+		}
+	}
+	return labels, nil
+}
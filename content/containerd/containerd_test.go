@@ -0,0 +1,294 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package containerd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// testStore is an in-memory namespaced content store and image store.
+type testStore struct {
+	lock    sync.Mutex
+	content map[string]map[digest.Digest][]byte
+	labels  map[string]map[digest.Digest]map[string]string
+	images  map[string]map[string]Image
+}
+
+func newTestStore() *testStore {
+	return &testStore{
+		content: make(map[string]map[digest.Digest][]byte),
+		labels:  make(map[string]map[digest.Digest]map[string]string),
+		images:  make(map[string]map[string]Image),
+	}
+}
+
+func (s *testStore) Info(ctx context.Context, dgst digest.Digest) (ContentInfo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	ns := NamespaceFromContext(ctx)
+	data, ok := s.content[ns][dgst]
+	if !ok {
+		return ContentInfo{}, fmt.Errorf("%s: %w", dgst, errdef.ErrNotFound)
+	}
+	return ContentInfo{Digest: dgst, Size: int64(len(data)), Labels: s.labels[ns][dgst]}, nil
+}
+
+func (s *testStore) Fetch(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	data, ok := s.content[NamespaceFromContext(ctx)][desc.Digest]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", desc.Digest, errdef.ErrNotFound)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *testStore) Write(ctx context.Context, desc ocispec.Descriptor, r io.Reader, labels map[string]string) error {
+	data, err := content.ReadAll(r, desc)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	ns := NamespaceFromContext(ctx)
+	if _, ok := s.content[ns][desc.Digest]; ok {
+		return fmt.Errorf("%s: %w", desc.Digest, errdef.ErrAlreadyExists)
+	}
+	if s.content[ns] == nil {
+		s.content[ns] = make(map[digest.Digest][]byte)
+		s.labels[ns] = make(map[digest.Digest]map[string]string)
+	}
+	s.content[ns][desc.Digest] = data
+	s.labels[ns][desc.Digest] = labels
+	return nil
+}
+
+func (s *testStore) Get(ctx context.Context, name string) (Image, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	image, ok := s.images[NamespaceFromContext(ctx)][name]
+	if !ok {
+		return Image{}, fmt.Errorf("image %q: %w", name, errdef.ErrNotFound)
+	}
+	return image, nil
+}
+
+func (s *testStore) Create(ctx context.Context, image Image) (Image, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	ns := NamespaceFromContext(ctx)
+	if _, ok := s.images[ns][image.Name]; ok {
+		return Image{}, fmt.Errorf("image %q: %w", image.Name, errdef.ErrAlreadyExists)
+	}
+	if s.images[ns] == nil {
+		s.images[ns] = make(map[string]Image)
+	}
+	s.images[ns][image.Name] = image
+	return image, nil
+}
+
+func (s *testStore) Update(ctx context.Context, image Image, fieldpaths ...string) (Image, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	ns := NamespaceFromContext(ctx)
+	if _, ok := s.images[ns][image.Name]; !ok {
+		return Image{}, fmt.Errorf("image %q: %w", image.Name, errdef.ErrNotFound)
+	}
+	s.images[ns][image.Name] = image
+	return image, nil
+}
+
+// pushTestImage pushes an image with a config and two layers to the store,
+// and returns the descriptors of the manifest and its blobs.
+func pushTestImage(t *testing.T, ctx context.Context, store *memory.Store) (ocispec.Descriptor, []ocispec.Descriptor) {
+	t.Helper()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := store.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatalf("failed to push test content: %v", err)
+		}
+		return desc
+	}
+	config := push(ocispec.MediaTypeImageConfig, []byte("{}"))
+	layers := []ocispec.Descriptor{
+		push(ocispec.MediaTypeImageLayer, []byte("foo")),
+		push(ocispec.MediaTypeImageLayer, []byte("bar")),
+	}
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    layers,
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal manifest: %v", err)
+	}
+	manifestDesc := push(ocispec.MediaTypeImageManifest, manifestJSON)
+	if err := store.Tag(ctx, manifestDesc, "latest"); err != nil {
+		t.Fatalf("failed to tag test manifest: %v", err)
+	}
+	return manifestDesc, append([]ocispec.Descriptor{config}, layers...)
+}
+
+func TestTarget_Copy(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	manifestDesc, blobs := pushTestImage(t, ctx, src)
+
+	store := newTestStore()
+	target := New(store, store)
+	target.Namespace = "k8s.io"
+	target.ImageLabels = map[string]string{"io.cri-containerd.image": "managed"}
+	ref := "docker.io/library/test:latest"
+	desc, err := oras.Copy(ctx, src, "latest", target, ref, oras.DefaultCopyOptions)
+	if err != nil {
+		t.Fatalf("oras.Copy() error = %v", err)
+	}
+	if !content.Equal(desc, manifestDesc) {
+		t.Errorf("oras.Copy() = %v, want %v", desc, manifestDesc)
+	}
+
+	// the image record is created in the namespace
+	nsCtx := WithNamespace(ctx, "k8s.io")
+	image, err := store.Get(nsCtx, ref)
+	if err != nil {
+		t.Fatalf("ImageStore.Get() error = %v", err)
+	}
+	want := Image{Name: ref, Target: manifestDesc, Labels: target.ImageLabels}
+	if !reflect.DeepEqual(image, want) {
+		t.Errorf("image = %v, want %v", image, want)
+	}
+	if _, err := store.Get(ctx, ref); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("ImageStore.Get() in the default namespace error = %v, want %v", err, errdef.ErrNotFound)
+	}
+
+	// the manifest is labeled to protect its blobs from the garbage collector
+	info, err := store.Info(nsCtx, manifestDesc.Digest)
+	if err != nil {
+		t.Fatalf("ContentStore.Info() error = %v", err)
+	}
+	wantLabels := map[string]string{
+		"containerd.io/gc.ref.content.config": blobs[0].Digest.String(),
+		"containerd.io/gc.ref.content.l.0":    blobs[1].Digest.String(),
+		"containerd.io/gc.ref.content.l.1":    blobs[2].Digest.String(),
+	}
+	if !reflect.DeepEqual(info.Labels, wantLabels) {
+		t.Errorf("manifest labels = %v, want %v", info.Labels, wantLabels)
+	}
+
+	// resolve and fetch back
+	got, err := target.Resolve(ctx, ref)
+	if err != nil {
+		t.Fatalf("Target.Resolve() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, manifestDesc) {
+		t.Errorf("Target.Resolve() = %v, want %v", got, manifestDesc)
+	}
+	for _, blob := range blobs {
+		exists, err := target.Exists(ctx, blob)
+		if err != nil {
+			t.Fatalf("Target.Exists() error = %v", err)
+		}
+		if !exists {
+			t.Errorf("Target.Exists(%s) = false, want true", blob.Digest)
+		}
+	}
+}
+
+func TestTarget_Tag(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	manifestDesc, _ := pushTestImage(t, ctx, src)
+	store := newTestStore()
+	target := New(store, store)
+	ref := "docker.io/library/test:latest"
+
+	// tagging missing content fails
+	if err := target.Tag(ctx, manifestDesc, ref); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Target.Tag() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+	if err := target.Tag(ctx, manifestDesc, ""); !errors.Is(err, errdef.ErrMissingReference) {
+		t.Errorf("Target.Tag() error = %v, want %v", err, errdef.ErrMissingReference)
+	}
+	if _, err := target.Resolve(ctx, ref); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Target.Resolve() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+
+	// the namespace attached to the context is used
+	nsCtx := WithNamespace(ctx, "test")
+	if _, err := oras.Copy(nsCtx, src, "latest", target, ref, oras.DefaultCopyOptions); err != nil {
+		t.Fatalf("oras.Copy() error = %v", err)
+	}
+	if _, err := target.Resolve(ctx, ref); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Target.Resolve() in the default namespace error = %v, want %v", err, errdef.ErrNotFound)
+	}
+
+	// re-tagging updates the image record
+	configDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, []byte("{}"))
+	if err := target.Tag(nsCtx, configDesc, ref); err != nil {
+		t.Fatalf("Target.Tag() error = %v", err)
+	}
+	got, err := target.Resolve(nsCtx, ref)
+	if err != nil {
+		t.Fatalf("Target.Resolve() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, configDesc) {
+		t.Errorf("Target.Resolve() = %v, want %v", got, configDesc)
+	}
+}
+
+func TestGCLabels_Index(t *testing.T) {
+	manifests := []ocispec.Descriptor{
+		content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, []byte("foo")),
+		content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, []byte("bar")),
+	}
+	indexJSON, err := json.Marshal(ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: manifests,
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal index: %v", err)
+	}
+	indexDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageIndex, indexJSON)
+	got, err := gcLabels(indexDesc, indexJSON)
+	if err != nil {
+		t.Fatalf("gcLabels() error = %v", err)
+	}
+	want := map[string]string{
+		"containerd.io/gc.ref.content.m.0": manifests[0].Digest.String(),
+		"containerd.io/gc.ref.content.m.1": manifests[1].Digest.String(),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("gcLabels() = %v, want %v", got, want)
+	}
+}
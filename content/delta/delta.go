@@ -0,0 +1,394 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package delta provides delta transfer between versions of an artifact.
+//
+// Given a previous version of an artifact present in a local storage, a
+// delta Target serves the blobs of a new version from the local storage
+// whenever possible, and fetches only the differing parts from the source:
+//
+//   - Blobs shared by both versions are not fetched at all.
+//   - For eStargz layers, the compressed chunks whose digests are found in
+//     the layers of the previous version are reused, and only the remaining
+//     byte ranges are fetched with range requests.
+//
+// Reconstructed layers are always verified against their digests, and are
+// fetched in whole on mismatch. The Target can be used as the source of
+// oras.Copy and oras.CopyGraph.
+package delta
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/lazy"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/logging"
+)
+
+// Stats is the statistics of a delta transfer.
+type Stats struct {
+	// Reused is the number of bytes served from the previous version.
+	Reused int64
+	// Fetched is the number of bytes fetched from the source.
+	Fetched int64
+}
+
+// Target is a read-only target serving the content of a new version of an
+// artifact from a source target and a previous version.
+type Target struct {
+	oras.ReadOnlyTarget
+
+	// TempDir is the directory for the temporary files holding the
+	// reconstructed layers.
+	// If empty, the default directory for temporary files is used.
+	TempDir string
+
+	base       content.ReadOnlyStorage
+	baseLayers []ocispec.Descriptor
+
+	indexOnce sync.Once
+	chunks    map[digest.Digest]chunk // keyed by chunk digest
+	reused    int64
+	fetched   int64
+}
+
+// chunk is a compressed chunk in a layer of the previous version.
+type chunk struct {
+	// layer is the layer holding the chunk.
+	layer ocispec.Descriptor
+	// offset is the offset of the chunk in the layer.
+	offset int64
+	// end is the offset where the next chunk or the TOC begins.
+	end int64
+}
+
+// New returns a Target serving the content of src, reusing the content of
+// the previous version rooted at baseRoot in the base storage.
+func New(ctx context.Context, src oras.ReadOnlyTarget, base content.ReadOnlyStorage, baseRoot ocispec.Descriptor) (*Target, error) {
+	layers, err := leaves(ctx, base, baseRoot)
+	if err != nil {
+		return nil, err
+	}
+	return &Target{
+		ReadOnlyTarget: src,
+		base:           base,
+		baseLayers:     layers,
+	}, nil
+}
+
+// Stats returns the statistics of the transfer so far.
+func (t *Target) Stats() Stats {
+	return Stats{
+		Reused:  atomic.LoadInt64(&t.reused),
+		Fetched: atomic.LoadInt64(&t.fetched),
+	}
+}
+
+// Fetch fetches the content identified by the descriptor.
+// The content is read from the previous version if it exists there. eStargz
+// layers are reconstructed from the chunks of the previous version and the
+// differing byte ranges fetched from the source.
+func (t *Target) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	exists, err := t.base.Exists(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		rc, err := t.base.Fetch(ctx, target)
+		if err == nil {
+			atomic.AddInt64(&t.reused, target.Size)
+			return rc, nil
+		}
+		if !errors.Is(err, errdef.ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	if _, ok := target.Annotations[lazy.AnnotationTOCDigest]; ok {
+		rc, err := t.fetchDelta(ctx, target)
+		if err == nil {
+			return rc, nil
+		}
+		logging.FromContext(ctx).Debug("delta transfer failed, fetching in whole", "digest", target.Digest, "error", err)
+	}
+	rc, err := t.ReadOnlyTarget.Fetch(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	atomic.AddInt64(&t.fetched, target.Size)
+	return rc, nil
+}
+
+// part is a part of a layer to be reconstructed. Either data is reused, or
+// the range [offset, offset+length) is fetched from the source.
+type part struct {
+	data   []byte
+	offset int64
+	length int64
+}
+
+// fetchDelta reconstructs the eStargz layer described by target into a
+// temporary file, and returns the reader of the file.
+func (t *Target) fetchDelta(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	t.indexOnce.Do(func() {
+		t.chunks = t.indexChunks(ctx)
+	})
+	if len(t.chunks) == 0 {
+		return nil, errors.New("no chunks to reuse")
+	}
+
+	layer, err := lazy.Open(ctx, t.ReadOnlyTarget, target)
+	if err != nil {
+		return nil, err
+	}
+	atomic.AddInt64(&t.fetched, target.Size-layer.TOCOffset())
+	parts, err := t.plan(ctx, layer, target.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	fp, err := os.CreateTemp(t.TempDir, "oras_delta_*")
+	if err != nil {
+		return nil, err
+	}
+	rc := &tempFile{File: fp}
+	if err := t.reconstruct(ctx, fp, target, parts); err != nil {
+		rc.Close()
+		return nil, err
+	}
+	if _, err := fp.Seek(0, io.SeekStart); err != nil {
+		rc.Close()
+		return nil, err
+	}
+	return rc, nil
+}
+
+// plan plans the parts of the layer to be reused and fetched.
+func (t *Target) plan(ctx context.Context, layer *lazy.Layer, size int64) ([]part, error) {
+	offsets := chunkOffsets(layer)
+	var parts []part
+	fetch := func(offset, end int64) {
+		if offset >= end {
+			return
+		}
+		if n := len(parts); n > 0 && parts[n-1].data == nil && parts[n-1].offset+parts[n-1].length == offset {
+			parts[n-1].length += end - offset
+			return
+		}
+		parts = append(parts, part{offset: offset, length: end - offset})
+	}
+
+	var start int64
+	for i, entry := range chunkEntries(layer) {
+		end := layer.TOCOffset()
+		if i+1 < len(offsets) {
+			end = offsets[i+1]
+		}
+		fetch(start, entry.Offset)
+		start = entry.Offset
+		c, ok := t.chunks[digest.Digest(entry.ChunkDigest)]
+		if !ok {
+			continue
+		}
+		member, err := t.readMember(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(member)) > end-entry.Offset {
+			continue
+		}
+		parts = append(parts, part{data: member})
+		start += int64(len(member))
+	}
+	fetch(start, layer.TOCOffset())
+	// the TOC and the footer are fetched again, since they are small
+	// compared to the file contents
+	fetch(layer.TOCOffset(), size)
+	return parts, nil
+}
+
+// reconstruct writes the parts into w and verifies the written content.
+func (t *Target) reconstruct(ctx context.Context, w io.Writer, target ocispec.Descriptor, parts []part) error {
+	verifier, err := content.NewVerifier(target.Digest)
+	if err != nil {
+		return err
+	}
+	w = io.MultiWriter(w, verifier)
+	var written int64
+	for _, p := range parts {
+		if p.data != nil {
+			if _, err := w.Write(p.data); err != nil {
+				return err
+			}
+			atomic.AddInt64(&t.reused, int64(len(p.data)))
+			written += int64(len(p.data))
+			continue
+		}
+		rc, err := content.FetchRange(ctx, t.ReadOnlyTarget, target, p.offset, p.length)
+		if err != nil {
+			return err
+		}
+		n, err := io.Copy(w, rc)
+		rc.Close()
+		atomic.AddInt64(&t.fetched, n)
+		if err != nil {
+			return err
+		}
+		written += n
+	}
+	if written != target.Size || !verifier.Verified() {
+		return fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, content.ErrMismatchedDigest)
+	}
+	return nil
+}
+
+// readMember reads the compressed chunk of the previous version, and returns
+// the gzip member holding the chunk.
+func (t *Target) readMember(ctx context.Context, c chunk) ([]byte, error) {
+	rc, err := content.FetchRange(ctx, t.base, c.layer, c.offset, c.end-c.offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	// bytes.Reader implements io.ByteReader, so that the gzip reader does
+	// not read beyond the end of the member.
+	r := bytes.NewReader(data)
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	zr.Multistream(false)
+	if _, err := io.Copy(io.Discard, zr); err != nil {
+		return nil, err
+	}
+	return data[:len(data)-r.Len()], nil
+}
+
+// indexChunks indexes the chunks in the eStargz layers of the previous
+// version. Layers not in the eStargz format are skipped.
+func (t *Target) indexChunks(ctx context.Context) map[digest.Digest]chunk {
+	chunks := make(map[digest.Digest]chunk)
+	for _, desc := range t.baseLayers {
+		layer, err := lazy.Open(ctx, t.base, desc)
+		if err != nil {
+			continue
+		}
+		offsets := chunkOffsets(layer)
+		for i, entry := range chunkEntries(layer) {
+			if entry.ChunkDigest == "" {
+				continue
+			}
+			end := layer.TOCOffset()
+			if i+1 < len(offsets) {
+				end = offsets[i+1]
+			}
+			chunks[digest.Digest(entry.ChunkDigest)] = chunk{
+				layer:  desc,
+				offset: entry.Offset,
+				end:    end,
+			}
+		}
+	}
+	return chunks
+}
+
+// chunkEntries returns the TOC entries holding file contents, sorted by
+// offset.
+func chunkEntries(layer *lazy.Layer) []*lazy.TOCEntry {
+	var entries []*lazy.TOCEntry
+	for _, entry := range layer.Entries() {
+		switch entry.Type {
+		case lazy.EntryTypeReg:
+			if entry.Size == 0 {
+				continue
+			}
+		case lazy.EntryTypeChunk:
+		default:
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Offset < entries[j].Offset
+	})
+	return entries
+}
+
+// chunkOffsets returns the sorted offsets of the chunks.
+func chunkOffsets(layer *lazy.Layer) []int64 {
+	entries := chunkEntries(layer)
+	offsets := make([]int64, len(entries))
+	for i, entry := range entries {
+		offsets[i] = entry.Offset
+	}
+	return offsets
+}
+
+// leaves returns the non-manifest nodes reachable from root.
+func leaves(ctx context.Context, fetcher content.Fetcher, root ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	var results []ocispec.Descriptor
+	visited := make(map[descriptor.Descriptor]bool)
+	stack := []ocispec.Descriptor{root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		key := descriptor.FromOCI(node)
+		if visited[key] {
+			continue
+		}
+		visited[key] = true
+		successors, err := content.Successors(ctx, fetcher, node)
+		if err != nil {
+			return nil, err
+		}
+		if len(successors) == 0 {
+			results = append(results, node)
+		}
+		stack = append(stack, successors...)
+	}
+	return results, nil
+}
+
+// tempFile is a temporary file removed on close.
+type tempFile struct {
+	*os.File
+}
+
+// Close closes and removes the file.
+func (f *tempFile) Close() error {
+	closeErr := f.File.Close()
+	if err := os.Remove(f.Name()); err != nil {
+		return err
+	}
+	return closeErr
+}
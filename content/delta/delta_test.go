@@ -0,0 +1,227 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package delta
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/lazy"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+// buildEStargz builds an eStargz layer holding the files, compressed with
+// the given level. Each file is a single chunk.
+func buildEStargz(t *testing.T, files map[string]string, names []string, level int) (ocispec.Descriptor, []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	writeMember := func(data []byte) {
+		zw, err := gzip.NewWriterLevel(&buf, level)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := zw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var entries []*lazy.TOCEntry
+	for _, name := range names {
+		data := []byte(files[name])
+		var tb bytes.Buffer
+		tw := tar.NewWriter(&tb)
+		if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0644, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := tw.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		writeMember(tb.Bytes()[:512])
+		entries = append(entries, &lazy.TOCEntry{
+			Name:        name,
+			Type:        lazy.EntryTypeReg,
+			Size:        int64(len(data)),
+			Offset:      int64(buf.Len()),
+			ChunkDigest: digest.FromBytes(data).String(),
+		})
+		writeMember(data)
+		if pad := tb.Bytes()[512+len(data):]; len(pad) > 0 {
+			writeMember(pad)
+		}
+	}
+
+	tocOffset := int64(buf.Len())
+	tocJSON, err := json.Marshal(map[string]interface{}{"version": 1, "entries": entries})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tb bytes.Buffer
+	tw := tar.NewWriter(&tb)
+	if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "stargz.index.json", Mode: 0644, Size: int64(len(tocJSON))}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(tocJSON); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	writeMember(tb.Bytes())
+	footer := []byte{0x1f, 0x8b, 8, 4, 0, 0, 0, 0, 0, 0xff, 26, 0, 'S', 'G', 22, 0}
+	footer = append(footer, fmt.Sprintf("%016xSTARGZ", tocOffset)...)
+	footer = append(footer, 1, 0, 0, 0xff, 0xff, 0, 0, 0, 0, 0, 0, 0, 0)
+	buf.Write(footer)
+
+	blob := buf.Bytes()
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayerGzip, blob)
+	desc.Annotations = map[string]string{
+		lazy.AnnotationTOCDigest: digest.FromBytes(tocJSON).String(),
+	}
+	return desc, blob
+}
+
+// pushImage pushes an image with the given layers to the store.
+func pushImage(t *testing.T, store *memory.Store, layers []ocispec.Descriptor, blobs [][]byte) ocispec.Descriptor {
+	t.Helper()
+	ctx := context.Background()
+	push := func(desc ocispec.Descriptor, blob []byte) {
+		if exists, err := store.Exists(ctx, desc); err != nil || exists {
+			return
+		}
+		if err := store.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal("Push() error =", err)
+		}
+	}
+	config := []byte("{}")
+	configDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, config)
+	push(configDesc, config)
+	for i, layer := range layers {
+		push(layer, blobs[i])
+	}
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    configDesc,
+		Layers:    layers,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifestJSON)
+	push(manifestDesc, manifestJSON)
+	return manifestDesc
+}
+
+func TestTarget(t *testing.T) {
+	ctx := context.Background()
+	big := make([]byte, 64*1024)
+	rand.New(rand.NewSource(1)).Read(big)
+	files := map[string]string{
+		"big.txt":   string(big),
+		"hello.txt": "hello world",
+		"new.txt":   "new file",
+	}
+
+	// previous version
+	base := memory.New()
+	baseLayer, baseBlob := buildEStargz(t, files, []string{"hello.txt", "big.txt"}, gzip.DefaultCompression)
+	sharedLayer, sharedBlob := buildEStargz(t, files, []string{"hello.txt"}, gzip.DefaultCompression)
+	baseRoot := pushImage(t, base, []ocispec.Descriptor{baseLayer, sharedLayer}, [][]byte{baseBlob, sharedBlob})
+
+	// new version
+	src := memory.New()
+	newLayer, newBlob := buildEStargz(t, files, []string{"new.txt", "big.txt", "hello.txt"}, gzip.DefaultCompression)
+	root := pushImage(t, src, []ocispec.Descriptor{newLayer, sharedLayer}, [][]byte{newBlob, sharedBlob})
+	if err := src.Tag(ctx, root, "v2"); err != nil {
+		t.Fatal("Tag() error =", err)
+	}
+
+	target, err := New(ctx, src, base, baseRoot)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	target.TempDir = t.TempDir()
+	dst := memory.New()
+	if _, err := oras.Copy(ctx, target, "v2", dst, "v2", oras.DefaultCopyOptions); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	got, err := content.FetchAll(ctx, dst, newLayer)
+	if err != nil {
+		t.Fatalf("FetchAll() error = %v", err)
+	}
+	if !bytes.Equal(got, newBlob) {
+		t.Error("reconstructed layer mismatch")
+	}
+
+	stats := target.Stats()
+	if stats.Reused <= sharedLayer.Size {
+		t.Errorf("Stats().Reused = %d, want > %d", stats.Reused, sharedLayer.Size)
+	}
+	if stats.Fetched >= newLayer.Size {
+		t.Errorf("Stats().Fetched = %d, want < %d", stats.Fetched, newLayer.Size)
+	}
+}
+
+func TestTarget_Fallback(t *testing.T) {
+	ctx := context.Background()
+	files := map[string]string{
+		"big.txt": string(bytes.Repeat([]byte("0123456789abcdef"), 1024)),
+	}
+
+	// chunks in the previous version are compressed differently
+	base := memory.New()
+	baseLayer, baseBlob := buildEStargz(t, files, []string{"big.txt"}, gzip.BestSpeed)
+	baseRoot := pushImage(t, base, []ocispec.Descriptor{baseLayer}, [][]byte{baseBlob})
+
+	src := memory.New()
+	newLayer, newBlob := buildEStargz(t, files, []string{"big.txt"}, gzip.BestCompression)
+	pushImage(t, src, []ocispec.Descriptor{newLayer}, [][]byte{newBlob})
+
+	target, err := New(ctx, src, base, baseRoot)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	target.TempDir = t.TempDir()
+	rc, err := target.Fetch(ctx, newLayer)
+	if err != nil {
+		t.Fatalf("Target.Fetch() error = %v", err)
+	}
+	defer rc.Close()
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal("failed to read:", err)
+	}
+	if !bytes.Equal(got, newBlob) {
+		t.Error("Target.Fetch() content mismatch")
+	}
+}
@@ -38,3 +38,13 @@ func NewDescriptorFromBytes(mediaType string, content []byte) ocispec.Descriptor
 func Equal(a, b ocispec.Descriptor) bool {
 	return a.Size == b.Size && a.Digest == b.Digest && a.MediaType == b.MediaType
 }
+
+// TotalSize returns the total size of the content described by the
+// descriptors, such as the number of bytes freed by deleting them.
+func TotalSize(descs []ocispec.Descriptor) int64 {
+	var total int64
+	for _, desc := range descs {
+		total += desc.Size
+	}
+	return total
+}
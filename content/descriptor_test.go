@@ -165,3 +165,16 @@ func TestEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestTotalSize(t *testing.T) {
+	descs := []ocispec.Descriptor{
+		NewDescriptorFromBytes("test", []byte("hello")),
+		NewDescriptorFromBytes("test", []byte("world!")),
+	}
+	if got := TotalSize(descs); got != 11 {
+		t.Errorf("TotalSize() = %d, want %d", got, 11)
+	}
+	if got := TotalSize(nil); got != 0 {
+		t.Errorf("TotalSize(nil) = %d, want 0", got)
+	}
+}
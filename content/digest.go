@@ -0,0 +1,194 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+)
+
+var (
+	// algorithms maps the registered digest algorithms to their
+	// implementations.
+	algorithms sync.Map // map[digest.Algorithm]func() hash.Hash
+
+	// algorithmRegexp matches the algorithm identifiers defined by the OCI
+	// image spec.
+	// Reference: https://github.com/opencontainers/image-spec/blob/v1.1.0-rc3/descriptor.md#digests
+	algorithmRegexp = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*$`)
+)
+
+// RegisterAlgorithm registers an additional digest algorithm, such as BLAKE3,
+// under its algorithm identifier, so that digests of the algorithm can be
+// validated, verified, and computed by this package and the packages built on
+// top of it.
+// The encoded portion of the digests is the lowercase hex encoding of the
+// hash sum.
+// The algorithms built into github.com/opencontainers/go-digest cannot be
+// overridden.
+// RegisterAlgorithm is expected to be called during initialization.
+func RegisterAlgorithm(alg digest.Algorithm, newHash func() hash.Hash) error {
+	if !algorithmRegexp.MatchString(string(alg)) {
+		return fmt.Errorf("%s: invalid digest algorithm", alg)
+	}
+	if newHash == nil {
+		return fmt.Errorf("%s: nil hash function", alg)
+	}
+	if alg.Available() {
+		return fmt.Errorf("%s: digest algorithm already registered", alg)
+	}
+	if _, loaded := algorithms.LoadOrStore(alg, newHash); loaded {
+		return fmt.Errorf("%s: digest algorithm already registered", alg)
+	}
+	return nil
+}
+
+// AlgorithmAvailable returns true if the digest algorithm is either built into
+// github.com/opencontainers/go-digest or registered by RegisterAlgorithm.
+// In FIPS mode, only the FIPS-approved algorithms are available.
+func AlgorithmAvailable(alg digest.Algorithm) bool {
+	if checkFIPS(alg) != nil {
+		return false
+	}
+	_, ok := lookupAlgorithm(alg)
+	return ok
+}
+
+// lookupAlgorithm returns the hash function of the digest algorithm.
+func lookupAlgorithm(alg digest.Algorithm) (func() hash.Hash, bool) {
+	if alg.Available() {
+		return alg.Hash, true
+	}
+	if newHash, ok := algorithms.Load(alg); ok {
+		return newHash.(func() hash.Hash), true
+	}
+	return nil, false
+}
+
+// ValidateDigest checks that the digest is well formed and its algorithm is
+// available.
+func ValidateDigest(dgst digest.Digest) error {
+	if !strings.Contains(string(dgst), ":") {
+		return digest.ErrDigestInvalidFormat
+	}
+	alg := dgst.Algorithm()
+	if err := checkFIPS(alg); err != nil {
+		return err
+	}
+	if alg.Available() {
+		return dgst.Validate()
+	}
+	newHash, ok := lookupAlgorithm(alg)
+	if !ok {
+		return digest.ErrDigestUnsupported
+	}
+	encoded := dgst.Encoded()
+	if len(encoded) != newHash().Size()*2 {
+		return digest.ErrDigestInvalidLength
+	}
+	if _, err := hex.DecodeString(encoded); err != nil || strings.ToLower(encoded) != encoded {
+		return digest.ErrDigestInvalidFormat
+	}
+	return nil
+}
+
+// ParseDigest parses s and returns the validated digest.
+// Unlike digest.Parse, the digest algorithms registered by RegisterAlgorithm
+// are accepted.
+func ParseDigest(s string) (digest.Digest, error) {
+	dgst := digest.Digest(s)
+	return dgst, ValidateDigest(dgst)
+}
+
+// NewDigester returns a digester of the digest algorithm.
+func NewDigester(alg digest.Algorithm) (digest.Digester, error) {
+	if err := checkFIPS(alg); err != nil {
+		return nil, err
+	}
+	newHash, ok := lookupAlgorithm(alg)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", alg, digest.ErrDigestUnsupported)
+	}
+	return &digester{
+		alg:  alg,
+		hash: newHash(),
+	}, nil
+}
+
+// NewVerifier returns a verifier of the digest.
+func NewVerifier(dgst digest.Digest) (digest.Verifier, error) {
+	if !strings.Contains(string(dgst), ":") {
+		return nil, fmt.Errorf("%s: %w", dgst, digest.ErrDigestInvalidFormat)
+	}
+	d, err := NewDigester(dgst.Algorithm())
+	if err != nil {
+		return nil, err
+	}
+	return &verifier{
+		digester: d,
+		expected: dgst,
+	}, nil
+}
+
+// ComputeDigest computes the digest of the content using the digest
+// algorithm.
+func ComputeDigest(alg digest.Algorithm, content []byte) (digest.Digest, error) {
+	d, err := NewDigester(alg)
+	if err != nil {
+		return "", err
+	}
+	// writes to a hash never fail
+	d.Hash().Write(content)
+	return d.Digest(), nil
+}
+
+// digester implements digest.Digester for the registered algorithms.
+type digester struct {
+	alg  digest.Algorithm
+	hash hash.Hash
+}
+
+// Hash returns the underlying hash.
+func (d *digester) Hash() hash.Hash {
+	return d.hash
+}
+
+// Digest returns the current digest of the written content.
+func (d *digester) Digest() digest.Digest {
+	return digest.NewDigest(d.alg, d.hash)
+}
+
+// verifier implements digest.Verifier for any available algorithm.
+type verifier struct {
+	digester digest.Digester
+	expected digest.Digest
+}
+
+// Write writes the content to be verified.
+func (v *verifier) Write(p []byte) (int, error) {
+	return v.digester.Hash().Write(p)
+}
+
+// Verified returns true if the written content matches the expected digest.
+func (v *verifier) Verified() bool {
+	return v.digester.Digest() == v.expected
+}
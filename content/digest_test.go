@@ -0,0 +1,216 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content
+
+import (
+	"bytes"
+	"errors"
+	"hash"
+	"hash/fnv"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// testAlgorithm is a digest algorithm registered for testing.
+const testAlgorithm = digest.Algorithm("fnv128a")
+
+var registerTestAlgorithm sync.Once
+
+func setupTestAlgorithm(t *testing.T) {
+	registerTestAlgorithm.Do(func() {
+		if err := RegisterAlgorithm(testAlgorithm, fnv.New128a); err != nil {
+			t.Fatal("RegisterAlgorithm() error =", err)
+		}
+	})
+}
+
+func TestRegisterAlgorithm(t *testing.T) {
+	setupTestAlgorithm(t)
+
+	tests := []struct {
+		name    string
+		alg     digest.Algorithm
+		newHash func() hash.Hash
+	}{
+		{
+			name:    "invalid algorithm",
+			alg:     "FNV",
+			newHash: fnv.New128,
+		},
+		{
+			name: "nil hash",
+			alg:  "fnv128",
+		},
+		{
+			name:    "built-in algorithm",
+			alg:     digest.SHA256,
+			newHash: fnv.New128,
+		},
+		{
+			name:    "registered algorithm",
+			alg:     testAlgorithm,
+			newHash: fnv.New128,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RegisterAlgorithm(tt.alg, tt.newHash); err == nil {
+				t.Errorf("RegisterAlgorithm() error = %v, wantErr %v", err, true)
+			}
+		})
+	}
+
+	if !AlgorithmAvailable(testAlgorithm) {
+		t.Errorf("AlgorithmAvailable(%s) = %v, want %v", testAlgorithm, false, true)
+	}
+	if !AlgorithmAvailable(digest.SHA256) {
+		t.Errorf("AlgorithmAvailable(%s) = %v, want %v", digest.SHA256, false, true)
+	}
+	if AlgorithmAvailable("fnv128") {
+		t.Errorf("AlgorithmAvailable(%s) = %v, want %v", "fnv128", true, false)
+	}
+}
+
+func TestValidateDigest(t *testing.T) {
+	setupTestAlgorithm(t)
+
+	tests := []struct {
+		name    string
+		dgst    digest.Digest
+		wantErr error
+	}{
+		{
+			name: "built-in algorithm",
+			dgst: digest.FromString("foo"),
+		},
+		{
+			name: "registered algorithm",
+			dgst: "fnv128a:" + digest.Digest(strings.Repeat("0a", 16)),
+		},
+		{
+			name:    "invalid length",
+			dgst:    "fnv128a:" + digest.Digest(strings.Repeat("0a", 8)),
+			wantErr: digest.ErrDigestInvalidLength,
+		},
+		{
+			name:    "upper case",
+			dgst:    "fnv128a:" + digest.Digest(strings.Repeat("0A", 16)),
+			wantErr: digest.ErrDigestInvalidFormat,
+		},
+		{
+			name:    "non-hex",
+			dgst:    "fnv128a:" + digest.Digest(strings.Repeat("zz", 16)),
+			wantErr: digest.ErrDigestInvalidFormat,
+		},
+		{
+			name:    "unsupported algorithm",
+			dgst:    "fnv64:" + digest.Digest(strings.Repeat("0a", 8)),
+			wantErr: digest.ErrDigestUnsupported,
+		},
+		{
+			name:    "no algorithm",
+			dgst:    digest.Digest(strings.Repeat("0a", 16)),
+			wantErr: digest.ErrDigestInvalidFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDigest(tt.dgst)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateDigest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got, err := ParseDigest(string(tt.dgst))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseDigest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.dgst {
+				t.Errorf("ParseDigest() = %v, want %v", got, tt.dgst)
+			}
+		})
+	}
+}
+
+func TestComputeDigest(t *testing.T) {
+	setupTestAlgorithm(t)
+	data := []byte("hello world")
+
+	got, err := ComputeDigest(digest.SHA256, data)
+	if err != nil {
+		t.Fatal("ComputeDigest() error =", err)
+	}
+	if want := digest.FromBytes(data); got != want {
+		t.Errorf("ComputeDigest() = %v, want %v", got, want)
+	}
+
+	got, err = ComputeDigest(testAlgorithm, data)
+	if err != nil {
+		t.Fatal("ComputeDigest() error =", err)
+	}
+	h := fnv.New128a()
+	h.Write(data)
+	if want := digest.NewDigest(testAlgorithm, h); got != want {
+		t.Errorf("ComputeDigest() = %v, want %v", got, want)
+	}
+	if err := ValidateDigest(got); err != nil {
+		t.Errorf("ValidateDigest() error = %v", err)
+	}
+
+	if _, err := ComputeDigest("fnv64", data); !errors.Is(err, digest.ErrDigestUnsupported) {
+		t.Errorf("ComputeDigest() error = %v, wantErr %v", err, digest.ErrDigestUnsupported)
+	}
+}
+
+func TestReadAll_RegisteredAlgorithm(t *testing.T) {
+	setupTestAlgorithm(t)
+	data := []byte("hello world")
+	dgst, err := ComputeDigest(testAlgorithm, data)
+	if err != nil {
+		t.Fatal("ComputeDigest() error =", err)
+	}
+	desc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageLayer,
+		Digest:    dgst,
+		Size:      int64(len(data)),
+	}
+
+	got, err := ReadAll(bytes.NewReader(data), desc)
+	if err != nil {
+		t.Fatal("ReadAll() error =", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("ReadAll() = %v, want %v", got, data)
+	}
+
+	// test mismatched digest
+	if _, err := ReadAll(bytes.NewReader([]byte("hello World")), desc); !errors.Is(err, ErrMismatchedDigest) {
+		t.Errorf("ReadAll() error = %v, wantErr %v", err, ErrMismatchedDigest)
+	}
+
+	// test unsupported algorithm
+	desc.Digest = "fnv64:" + digest.Digest(strings.Repeat("0a", 8))
+	vr := NewVerifyReader(bytes.NewReader(data), desc)
+	if _, err := io.ReadAll(vr); !errors.Is(err, digest.ErrDigestUnsupported) {
+		t.Errorf("VerifyReader.Read() error = %v, wantErr %v", err, digest.ErrDigestUnsupported)
+	}
+	if err := vr.Verify(); !errors.Is(err, digest.ErrDigestUnsupported) {
+		t.Errorf("VerifyReader.Verify() error = %v, wantErr %v", err, digest.ErrDigestUnsupported)
+	}
+}
@@ -0,0 +1,41 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dockerarchive provides writers and readers of the archive format
+// used by `docker save` and `docker load`.
+//
+// Archives written by this package contain both the `manifest.json` file read
+// by `docker load` and an OCI image layout, matching the format written by
+// recent versions of Docker Engine.
+package dockerarchive
+
+// manifestFile is the name of the file listing the images in a Docker
+// archive.
+const manifestFile = "manifest.json"
+
+// annotationImageName is the annotation key for the full name of an image, as
+// used by containerd and Docker Engine.
+const annotationImageName = "io.containerd.image.name"
+
+// manifestEntry is an image entry in the manifest.json file.
+type manifestEntry struct {
+	// Config is the path of the image config.
+	Config string
+	// RepoTags are the repository tags of the image, such as
+	// "docker.io/library/hello-world:latest".
+	RepoTags []string
+	// Layers are the paths of the image layers.
+	Layers []string
+}
@@ -0,0 +1,261 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dockerarchive
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/compression"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// maxManifestFileBytes limits the size of the manifest.json file.
+const maxManifestFileBytes int64 = 4 * 1024 * 1024 // 4 MiB
+
+// Image is an image loaded from a Docker archive.
+type Image struct {
+	// Descriptor is the descriptor of the OCI image manifest generated for
+	// the image.
+	Descriptor ocispec.Descriptor
+	// RepoTags are the repository tags of the image recorded in the archive.
+	RepoTags []string
+}
+
+// archiveFile is a file spooled from a Docker archive.
+type archiveFile struct {
+	// path is the path of the spooled file.
+	path string
+	// linkname is the target of a symbolic link or a hard link.
+	linkname string
+	digest   digest.Digest
+	size     int64
+	// header are the first bytes of the file, used for compression
+	// detection.
+	header []byte
+}
+
+// Load reads a Docker archive written by `docker save` from r, pushes the
+// images to dst, and tags them with their repository tags.
+// An OCI image manifest is generated for each image, with the layer media
+// types detected from the layer content.
+// The archive is spooled to a temporary directory while loading.
+func Load(ctx context.Context, r io.Reader, dst oras.Target) ([]Image, error) {
+	tempDir, err := os.MkdirTemp("", "oras_docker_archive_*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	files, err := spool(r, tempDir)
+	if err != nil {
+		return nil, err
+	}
+
+	manifestFileInfo, err := resolveFile(files, manifestFile)
+	if err != nil {
+		return nil, err
+	}
+	if manifestFileInfo.size > maxManifestFileBytes {
+		return nil, fmt.Errorf("%s: %w", manifestFile, errdef.ErrSizeExceedsLimit)
+	}
+	manifestJSON, err := os.ReadFile(manifestFileInfo.path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []manifestEntry
+	if err := json.Unmarshal(manifestJSON, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", manifestFile, err)
+	}
+
+	var images []Image
+	for _, entry := range entries {
+		desc, err := loadImage(ctx, dst, files, entry)
+		if err != nil {
+			return nil, err
+		}
+		for _, repoTag := range entry.RepoTags {
+			if err := dst.Tag(ctx, desc, repoTag); err != nil {
+				return nil, err
+			}
+		}
+		images = append(images, Image{
+			Descriptor: desc,
+			RepoTags:   entry.RepoTags,
+		})
+	}
+	return images, nil
+}
+
+// loadImage pushes the image described by entry to dst, and returns the
+// descriptor of the generated image manifest.
+func loadImage(ctx context.Context, dst content.Pusher, files map[string]*archiveFile, entry manifestEntry) (ocispec.Descriptor, error) {
+	configFile, err := resolveFile(files, entry.Config)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	config := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageConfig,
+		Digest:    configFile.digest,
+		Size:      configFile.size,
+	}
+	if err := pushFile(ctx, dst, config, configFile); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	manifest := ocispec.Manifest{
+		Versioned: specs.Versioned{
+			SchemaVersion: 2, // historical value
+		},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    []ocispec.Descriptor{},
+	}
+	for _, name := range entry.Layers {
+		layerFile, err := resolveFile(files, name)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		layer := ocispec.Descriptor{
+			MediaType: compression.LayerMediaType(compression.Detect(layerFile.header)),
+			Digest:    layerFile.digest,
+			Size:      layerFile.size,
+		}
+		if err := pushFile(ctx, dst, layer, layerFile); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		manifest.Layers = append(manifest.Layers, layer)
+	}
+
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifestJSON)
+	if err := dst.Push(ctx, desc, bytes.NewReader(manifestJSON)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return ocispec.Descriptor{}, err
+	}
+	return desc, nil
+}
+
+// pushFile pushes the spooled file to dst.
+func pushFile(ctx context.Context, dst content.Pusher, desc ocispec.Descriptor, file *archiveFile) error {
+	fp, err := os.Open(file.path)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	if err := dst.Push(ctx, desc, fp); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return err
+	}
+	return nil
+}
+
+// spool reads the regular files, the symbolic links and the hard links in the
+// archive, and writes the regular files into dir.
+func spool(r io.Reader, dir string) (map[string]*archiveFile, error) {
+	files := make(map[string]*archiveFile)
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			if err == io.EOF {
+				return files, nil
+			}
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		name := path.Clean(header.Name)
+		switch header.Typeflag {
+		case tar.TypeReg:
+			file, err := spoolFile(tr, filepath.Join(dir, strconv.Itoa(len(files))))
+			if err != nil {
+				return nil, err
+			}
+			files[name] = file
+		case tar.TypeSymlink:
+			files[name] = &archiveFile{
+				linkname: path.Join(path.Dir(name), header.Linkname),
+			}
+		case tar.TypeLink:
+			files[name] = &archiveFile{
+				linkname: path.Clean(header.Linkname),
+			}
+		}
+	}
+}
+
+// spoolFile writes the content read from r into the file at filePath, and
+// returns the spooled file.
+func spoolFile(r io.Reader, filePath string) (*archiveFile, error) {
+	fp, err := os.Create(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+
+	digester := digest.Canonical.Digester()
+	header := make([]byte, 4)
+	n, err := io.ReadFull(r, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	header = header[:n]
+	w := io.MultiWriter(fp, digester.Hash())
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	size, err := io.Copy(w, r)
+	if err != nil {
+		return nil, err
+	}
+	return &archiveFile{
+		path:   filePath,
+		digest: digester.Digest(),
+		size:   size + int64(n),
+		header: header,
+	}, nil
+}
+
+// resolveFile returns the regular file at the given path in the archive,
+// following links.
+func resolveFile(files map[string]*archiveFile, name string) (*archiveFile, error) {
+	name = path.Clean(name)
+	for i := 0; i < 255; i++ {
+		file, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", name, errdef.ErrNotFound)
+		}
+		if file.linkname == "" {
+			return file, nil
+		}
+		name = file.linkname
+	}
+	return nil, fmt.Errorf("%s: too many levels of links", name)
+}
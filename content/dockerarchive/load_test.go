@@ -0,0 +1,190 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dockerarchive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// legacyArchive builds an archive in the legacy format of `docker save`.
+func legacyArchive(t *testing.T, config []byte, layers [][]byte, symlinks map[string]string, entries []manifestEntry) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	writeFile := func(name string, data []byte) {
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Size:     int64(len(data)),
+			Mode:     0644,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("config.json", config)
+	for i, layer := range layers {
+		writeFile(string(rune('a'+i))+"/layer.tar", layer)
+	}
+	for name, target := range symlinks {
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeSymlink,
+			Name:     name,
+			Linkname: target,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	manifestJSON, err := json.Marshal(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(manifestFile, manifestJSON)
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestLoad(t *testing.T) {
+	ctx := context.Background()
+	config := []byte(`{"architecture":"amd64","os":"linux"}`)
+	var gzipped bytes.Buffer
+	zw := gzip.NewWriter(&gzipped)
+	if _, err := zw.Write([]byte("foo")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	layers := [][]byte{[]byte("uncompressed"), gzipped.Bytes()}
+	archive := legacyArchive(t, config, layers, map[string]string{
+		"c/layer.tar": "../a/layer.tar",
+	}, []manifestEntry{
+		{
+			Config:   "config.json",
+			RepoTags: []string{"example.com/foo:v1", "example.com/foo:latest"},
+			Layers:   []string{"a/layer.tar", "b/layer.tar", "c/layer.tar"},
+		},
+	})
+
+	store := memory.New()
+	images, err := Load(ctx, bytes.NewReader(archive), store)
+	if err != nil {
+		t.Fatal("Load() error =", err)
+	}
+	if len(images) != 1 {
+		t.Fatalf("Load() = %v, want 1 image", images)
+	}
+	if want := []string{"example.com/foo:v1", "example.com/foo:latest"}; !reflect.DeepEqual(images[0].RepoTags, want) {
+		t.Errorf("Load() RepoTags = %v, want %v", images[0].RepoTags, want)
+	}
+	for _, ref := range images[0].RepoTags {
+		desc, err := store.Resolve(ctx, ref)
+		if err != nil {
+			t.Fatalf("Resolve(%s) error = %v", ref, err)
+		}
+		if !content.Equal(desc, images[0].Descriptor) {
+			t.Errorf("Resolve(%s) = %v, want %v", ref, desc, images[0].Descriptor)
+		}
+	}
+
+	manifestJSON, err := content.FetchAll(ctx, store, images[0].Descriptor)
+	if err != nil {
+		t.Fatal("FetchAll() error =", err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if want := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, config); !reflect.DeepEqual(manifest.Config, want) {
+		t.Errorf("manifest config = %v, want %v", manifest.Config, want)
+	}
+	wantLayers := []ocispec.Descriptor{
+		content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layers[0]),
+		content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayerGzip, layers[1]),
+		content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layers[0]),
+	}
+	if !reflect.DeepEqual(manifest.Layers, wantLayers) {
+		t.Errorf("manifest layers = %v, want %v", manifest.Layers, wantLayers)
+	}
+	for _, layer := range wantLayers {
+		exists, err := store.Exists(ctx, layer)
+		if err != nil {
+			t.Fatal("Exists() error =", err)
+		}
+		if !exists {
+			t.Errorf("layer %s is not loaded", layer.Digest)
+		}
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	ctx := context.Background()
+	archive := legacyArchive(t, []byte("{}"), nil, nil, []manifestEntry{
+		{
+			Config: "config.json",
+			Layers: []string{"missing/layer.tar"},
+		},
+	})
+	if _, err := Load(ctx, bytes.NewReader(archive), memory.New()); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Load() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	img := pushTestImage(t, src, "example.com/foo:v1", []byte("foo"), []byte("bar"))
+
+	var buf bytes.Buffer
+	if err := Save(ctx, &buf, src, []string{"example.com/foo:v1"}, SaveOptions{}); err != nil {
+		t.Fatal("Save() error =", err)
+	}
+	dst := memory.New()
+	images, err := Load(ctx, &buf, dst)
+	if err != nil {
+		t.Fatal("Load() error =", err)
+	}
+	if len(images) != 1 {
+		t.Fatalf("Load() = %v, want 1 image", images)
+	}
+	for _, desc := range append([]ocispec.Descriptor{img.config}, img.layers...) {
+		exists, err := dst.Exists(ctx, desc)
+		if err != nil {
+			t.Fatal("Exists() error =", err)
+		}
+		if !exists {
+			t.Errorf("blob %s is not loaded", desc.Digest)
+		}
+	}
+	if _, err := dst.Resolve(ctx, "example.com/foo:v1"); err != nil {
+		t.Errorf("Resolve() error = %v", err)
+	}
+}
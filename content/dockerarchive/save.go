@@ -0,0 +1,256 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dockerarchive
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/docker"
+	"oras.land/oras-go/v2/internal/platform"
+	"oras.land/oras-go/v2/registry"
+)
+
+// Writer writes images into a Docker archive.
+type Writer struct {
+	tw        *tar.Writer
+	written   map[descriptor.Descriptor]bool
+	manifests []manifestEntry
+	index     []ocispec.Descriptor
+	closed    bool
+}
+
+// NewWriter creates a new Writer writing to w.
+// The caller must call Close() after adding all the images.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		tw:      tar.NewWriter(w),
+		written: make(map[descriptor.Descriptor]bool),
+	}
+}
+
+// Add writes the image described by desc from src into the archive.
+// desc must describe an OCI image manifest or a Docker image manifest.
+// repoTags are the full names recorded for the image, such as
+// "docker.io/library/hello-world:latest".
+func (w *Writer) Add(ctx context.Context, src content.ReadOnlyStorage, desc ocispec.Descriptor, repoTags ...string) error {
+	if w.closed {
+		return errors.New("writer closed")
+	}
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageManifest, docker.MediaTypeManifest:
+	default:
+		return fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, errdef.ErrUnsupported)
+	}
+	manifestJSON, err := content.FetchAll(ctx, src, desc)
+	if err != nil {
+		return err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return fmt.Errorf("failed to decode manifest %s: %w", desc.Digest, err)
+	}
+
+	entry := manifestEntry{
+		Config:   blobPath(manifest.Config),
+		RepoTags: repoTags,
+	}
+	if err := w.writeBlob(ctx, src, manifest.Config); err != nil {
+		return err
+	}
+	for _, layer := range manifest.Layers {
+		if err := w.writeBlob(ctx, src, layer); err != nil {
+			return err
+		}
+		entry.Layers = append(entry.Layers, blobPath(layer))
+	}
+	if err := w.writeFile(blobPath(desc), manifestJSON); err != nil {
+		return err
+	}
+	w.manifests = append(w.manifests, entry)
+
+	// record the image in the OCI index
+	if len(repoTags) == 0 {
+		w.index = append(w.index, descriptor.Plain(desc))
+	}
+	for _, repoTag := range repoTags {
+		indexDesc := descriptor.Plain(desc)
+		indexDesc.Annotations = map[string]string{
+			annotationImageName: repoTag,
+		}
+		if ref, err := registry.ParseReference(repoTag); err == nil && ref.Reference != "" {
+			indexDesc.Annotations[ocispec.AnnotationRefName] = ref.Reference
+		}
+		w.index = append(w.index, indexDesc)
+	}
+	return nil
+}
+
+// Close writes the metadata files and closes the archive.
+// It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	layoutJSON, err := json.Marshal(ocispec.ImageLayout{
+		Version: ocispec.ImageLayoutVersion,
+	})
+	if err != nil {
+		return err
+	}
+	if err := w.writeFile(ocispec.ImageLayoutFile, layoutJSON); err != nil {
+		return err
+	}
+	indexJSON, err := json.Marshal(ocispec.Index{
+		Versioned: specs.Versioned{
+			SchemaVersion: 2, // historical value
+		},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: w.index,
+	})
+	if err != nil {
+		return err
+	}
+	if err := w.writeFile("index.json", indexJSON); err != nil {
+		return err
+	}
+	manifests := w.manifests
+	if manifests == nil {
+		manifests = []manifestEntry{}
+	}
+	manifestJSON, err := json.Marshal(manifests)
+	if err != nil {
+		return err
+	}
+	if err := w.writeFile(manifestFile, manifestJSON); err != nil {
+		return err
+	}
+	return w.tw.Close()
+}
+
+// writeBlob writes the blob described by desc from src into the archive if it
+// is not written yet.
+func (w *Writer) writeBlob(ctx context.Context, src content.ReadOnlyStorage, desc ocispec.Descriptor) error {
+	key := descriptor.FromOCI(desc)
+	if w.written[key] {
+		return nil
+	}
+	if err := content.ValidateDigest(desc.Digest); err != nil {
+		return fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, errdef.ErrInvalidDigest)
+	}
+
+	rc, err := src.Fetch(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	vr := content.NewVerifyReader(rc, desc)
+	if err := w.tw.WriteHeader(fileHeader(blobPath(desc), desc.Size)); err != nil {
+		return err
+	}
+	if _, err := io.Copy(w.tw, vr); err != nil {
+		return err
+	}
+	if err := vr.Verify(); err != nil {
+		return err
+	}
+	w.written[key] = true
+	return nil
+}
+
+// writeFile writes a regular file into the archive.
+func (w *Writer) writeFile(name string, data []byte) error {
+	if err := w.tw.WriteHeader(fileHeader(name, int64(len(data)))); err != nil {
+		return err
+	}
+	_, err := w.tw.Write(data)
+	return err
+}
+
+// fileHeader returns the tar header of a regular file.
+// The modification time is fixed to make archives reproducible.
+func fileHeader(name string, size int64) *tar.Header {
+	return &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0444,
+		ModTime:  time.Unix(0, 0),
+		Format:   tar.FormatPAX,
+	}
+}
+
+// blobPath returns the path of the blob in the OCI image layout.
+func blobPath(desc ocispec.Descriptor) string {
+	return path.Join("blobs", desc.Digest.Algorithm().String(), desc.Digest.Encoded())
+}
+
+// SaveOptions contains parameters for [dockerarchive.Save].
+type SaveOptions struct {
+	// TargetPlatform selects the image manifest if a reference resolves to
+	// an image index or a manifest list.
+	// If nil, references resolving to an image index or a manifest list are
+	// not supported.
+	TargetPlatform *ocispec.Platform
+	// RepoTag maps a reference in the source target to the repository tag
+	// recorded in the archive, such as "docker.io/library/hello-world:latest".
+	// If nil, the reference is recorded as is.
+	RepoTag func(reference string) string
+}
+
+// Save writes the images tagged by refs in src into a Docker archive, which
+// can be loaded by `docker load`.
+func Save(ctx context.Context, w io.Writer, src oras.ReadOnlyTarget, refs []string, opts SaveOptions) error {
+	writer := NewWriter(w)
+	for _, ref := range refs {
+		desc, err := src.Resolve(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", ref, err)
+		}
+		switch desc.MediaType {
+		case ocispec.MediaTypeImageIndex, docker.MediaTypeManifestList:
+			if opts.TargetPlatform == nil {
+				return fmt.Errorf("%s: %s: target platform required: %w", desc.Digest, desc.MediaType, errdef.ErrUnsupported)
+			}
+			desc, err = platform.SelectManifest(ctx, src, desc, opts.TargetPlatform)
+			if err != nil {
+				return err
+			}
+		}
+		repoTag := ref
+		if opts.RepoTag != nil {
+			repoTag = opts.RepoTag(ref)
+		}
+		if err := writer.Add(ctx, src, desc, repoTag); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
@@ -0,0 +1,196 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dockerarchive
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// testImage is an image pushed by pushTestImage.
+type testImage struct {
+	manifest ocispec.Descriptor
+	config   ocispec.Descriptor
+	layers   []ocispec.Descriptor
+}
+
+// pushTestImage pushes an image with the given layers to the store and tags it
+// with ref.
+func pushTestImage(t *testing.T, store *memory.Store, ref string, layers ...[]byte) testImage {
+	ctx := context.Background()
+	var img testImage
+	var err error
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := store.Push(ctx, desc, bytes.NewReader(blob)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+			t.Fatal("Push() error =", err)
+		}
+		return desc
+	}
+	img.config = push(ocispec.MediaTypeImageConfig, []byte(fmt.Sprintf(`{"architecture":"amd64","os":"linux","comment":%q}`, ref)))
+	for _, layer := range layers {
+		img.layers = append(img.layers, push(ocispec.MediaTypeImageLayer, layer))
+	}
+	img.manifest, err = oras.Pack(ctx, store, "", img.layers, oras.PackOptions{
+		PackImageManifest: true,
+		ConfigDescriptor:  &img.config,
+	})
+	if err != nil {
+		t.Fatal("Pack() error =", err)
+	}
+	if err := store.Tag(ctx, img.manifest, ref); err != nil {
+		t.Fatal("Tag() error =", err)
+	}
+	return img
+}
+
+// readArchive reads the regular files in the tar archive.
+func readArchive(t *testing.T, r io.Reader) map[string][]byte {
+	files := make(map[string][]byte)
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatal("failed to read archive:", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal("failed to read archive:", err)
+		}
+		files[header.Name] = data
+	}
+}
+
+func TestSave(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	foo := pushTestImage(t, store, "example.com/foo:v1", []byte("foo"), []byte("shared"))
+	bar := pushTestImage(t, store, "example.com/bar:v1", []byte("bar"), []byte("shared"))
+
+	var buf bytes.Buffer
+	refs := []string{"example.com/foo:v1", "example.com/bar:v1"}
+	if err := Save(ctx, &buf, store, refs, SaveOptions{}); err != nil {
+		t.Fatal("Save() error =", err)
+	}
+	files := readArchive(t, &buf)
+
+	// verify manifest.json
+	var entries []manifestEntry
+	if err := json.Unmarshal(files[manifestFile], &entries); err != nil {
+		t.Fatal("failed to decode manifest.json:", err)
+	}
+	want := []manifestEntry{
+		{
+			Config:   blobPath(foo.config),
+			RepoTags: []string{"example.com/foo:v1"},
+			Layers:   []string{blobPath(foo.layers[0]), blobPath(foo.layers[1])},
+		},
+		{
+			Config:   blobPath(bar.config),
+			RepoTags: []string{"example.com/bar:v1"},
+			Layers:   []string{blobPath(bar.layers[0]), blobPath(bar.layers[1])},
+		},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("manifest.json = %v, want %v", entries, want)
+	}
+
+	// verify blobs
+	for _, desc := range []ocispec.Descriptor{foo.manifest, foo.config, foo.layers[0], foo.layers[1], bar.manifest, bar.layers[0]} {
+		data, ok := files[blobPath(desc)]
+		if !ok {
+			t.Errorf("blob %s is not written", desc.Digest)
+			continue
+		}
+		if got := content.NewDescriptorFromBytes(desc.MediaType, data); got.Digest != desc.Digest {
+			t.Errorf("blob %s has digest %s", desc.Digest, got.Digest)
+		}
+	}
+
+	// verify OCI layout
+	if _, ok := files[ocispec.ImageLayoutFile]; !ok {
+		t.Errorf("%s is not written", ocispec.ImageLayoutFile)
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(files["index.json"], &index); err != nil {
+		t.Fatal("failed to decode index.json:", err)
+	}
+	if len(index.Manifests) != 2 {
+		t.Fatalf("index.json manifests = %v, want 2 manifests", index.Manifests)
+	}
+	if got := index.Manifests[0]; got.Digest != foo.manifest.Digest ||
+		got.Annotations[annotationImageName] != "example.com/foo:v1" ||
+		got.Annotations[ocispec.AnnotationRefName] != "v1" {
+		t.Errorf("index.json manifests[0] = %v", got)
+	}
+}
+
+func TestSave_Index(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	img := pushTestImage(t, store, "manifest", []byte("foo"))
+	manifest := img.manifest
+	manifest.Platform = &ocispec.Platform{OS: "linux", Architecture: "amd64"}
+	indexJSON, err := json.Marshal(ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{manifest},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := oras.TagBytes(ctx, store, ocispec.MediaTypeImageIndex, indexJSON, "index"); err != nil {
+		t.Fatal("TagBytes() error =", err)
+	}
+
+	if err := Save(ctx, io.Discard, store, []string{"index"}, SaveOptions{}); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Save() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+
+	var buf bytes.Buffer
+	opts := SaveOptions{
+		TargetPlatform: &ocispec.Platform{OS: "linux", Architecture: "amd64"},
+		RepoTag: func(reference string) string {
+			return "example.com/foo:" + reference
+		},
+	}
+	if err := Save(ctx, &buf, store, []string{"index"}, opts); err != nil {
+		t.Fatal("Save() error =", err)
+	}
+	files := readArchive(t, &buf)
+	var entries []manifestEntry
+	if err := json.Unmarshal(files[manifestFile], &entries); err != nil {
+		t.Fatal("failed to decode manifest.json:", err)
+	}
+	if len(entries) != 1 || entries[0].Config != blobPath(img.config) || !reflect.DeepEqual(entries[0].RepoTags, []string{"example.com/foo:index"}) {
+		t.Errorf("manifest.json = %v", entries)
+	}
+}
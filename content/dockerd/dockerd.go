@@ -0,0 +1,270 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dockerd provides a Target backed by the Docker Engine API, so that
+// images can be copied into and out of a local Docker daemon.
+//
+// Images are transferred using the `docker save` and `docker load` archive
+// format. Content pushed to the Target is staged in memory until it is
+// tagged, and content resolved from the daemon is cached in memory.
+package dockerd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content/dockerarchive"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// DefaultHost is the default address of the Docker daemon.
+const DefaultHost = "unix:///var/run/docker.sock"
+
+// Target is a Target backed by the Docker Engine API.
+//
+// Pushed content is staged until its manifest is tagged, and then loaded into
+// the daemon with the tag. Only image manifests can be tagged. Therefore, to
+// copy a multi-platform image, a platform must be selected, e.g. using
+// CopyOptions.WithTargetPlatform.
+//
+// Resolving a reference exports the image from the daemon. The manifest of the
+// exported image is generated, and thus its digest may differ from the digest
+// of the manifest in the registry the image was pulled from.
+type Target struct {
+	// Client is the HTTP client used to access the Docker Engine API.
+	Client *http.Client
+
+	// baseURL is the base URL of the Docker Engine API.
+	baseURL string
+	// staging stores the pushed content.
+	staging *memory.Store
+	// cache stores the content exported from the daemon.
+	cache *memory.Store
+	// names maps the digests of the cached manifests to the image names in
+	// the daemon.
+	names sync.Map // map[digest.Digest]string
+}
+
+// New creates a Target accessing the Docker daemon at host, such as
+// "unix:///var/run/docker.sock" or "tcp://localhost:2375".
+// If host is empty, DefaultHost is used.
+func New(host string) (*Target, error) {
+	if host == "" {
+		host = DefaultHost
+	}
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host %q: %w", host, err)
+	}
+
+	t := &Target{
+		staging: memory.New(),
+		cache:   memory.New(),
+	}
+	switch u.Scheme {
+	case "unix":
+		socketPath := u.Path
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socketPath)
+		}
+		t.Client = &http.Client{Transport: transport}
+		// the host name is ignored when dialing a unix socket
+		t.baseURL = "http://docker"
+	case "tcp", "http":
+		t.Client = http.DefaultClient
+		t.baseURL = "http://" + u.Host
+	case "https":
+		t.Client = http.DefaultClient
+		t.baseURL = "https://" + u.Host
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q in host %q", u.Scheme, host)
+	}
+	return t, nil
+}
+
+// Fetch fetches the content identified by the descriptor.
+func (t *Target) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	rc, err := t.staging.Fetch(ctx, target)
+	if err == nil || !errors.Is(err, errdef.ErrNotFound) {
+		return rc, err
+	}
+	return t.cache.Fetch(ctx, target)
+}
+
+// Push stages the content, matching the expected descriptor.
+// The content is loaded into the daemon when its manifest is tagged.
+func (t *Target) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	return t.staging.Push(ctx, expected, content)
+}
+
+// Exists returns true if the described content is staged or cached.
+func (t *Target) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	exists, err := t.staging.Exists(ctx, target)
+	if err != nil || exists {
+		return exists, err
+	}
+	return t.cache.Exists(ctx, target)
+}
+
+// Resolve exports the image referenced by reference from the daemon, and
+// returns the descriptor of the generated image manifest.
+// reference is an image name, such as "hello-world:latest", or an image ID.
+func (t *Target) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+	if reference == "" {
+		return ocispec.Descriptor{}, errdef.ErrMissingReference
+	}
+	resp, err := t.do(ctx, http.MethodGet, "/images/"+reference+"/get", nil, nil)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: %w", reference, err)
+	}
+	defer resp.Body.Close()
+
+	images, err := dockerarchive.Load(ctx, resp.Body, t.cache)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to load exported image %s: %w", reference, err)
+	}
+	if len(images) != 1 {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: expected 1 exported image, got %d", reference, len(images))
+	}
+	desc := images[0].Descriptor
+	t.names.Store(desc.Digest, reference)
+	return desc, nil
+}
+
+// Tag tags the image manifest described by desc with reference in the
+// daemon, such as "example.com/hello-world:v1".
+//   - If the manifest is staged, the image is loaded into the daemon.
+//   - If the manifest is resolved from the daemon, the image is tagged in the
+//     daemon.
+//   - Otherwise ErrNotFound is returned.
+func (t *Target) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	if reference == "" {
+		return errdef.ErrMissingReference
+	}
+	staged, err := t.staging.Exists(ctx, desc)
+	if err != nil {
+		return err
+	}
+	if staged {
+		return t.load(ctx, desc, reference)
+	}
+
+	value, ok := t.names.Load(desc.Digest)
+	if !ok {
+		return fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, errdef.ErrNotFound)
+	}
+	repo, tag := splitReference(reference)
+	query := url.Values{}
+	query.Set("repo", repo)
+	query.Set("tag", tag)
+	resp, err := t.do(ctx, http.MethodPost, "/images/"+value.(string)+"/tag", query, nil)
+	if err != nil {
+		return fmt.Errorf("failed to tag %s: %w", reference, err)
+	}
+	return resp.Body.Close()
+}
+
+// load loads the staged image into the daemon with the given repository tag.
+func (t *Target) load(ctx context.Context, desc ocispec.Descriptor, repoTag string) error {
+	pr, pw := io.Pipe()
+	go func() {
+		writer := dockerarchive.NewWriter(pw)
+		err := writer.Add(ctx, t.staging, desc, repoTag)
+		if err == nil {
+			err = writer.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	defer pr.Close()
+
+	query := url.Values{}
+	query.Set("quiet", "1")
+	resp, err := t.do(ctx, http.MethodPost, "/images/load", query, pr)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", repoTag, err)
+	}
+	defer resp.Body.Close()
+
+	// errors occurred while loading are reported in the response stream
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var message struct {
+			Error string `json:"error"`
+		}
+		if err := decoder.Decode(&message); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("failed to load %s: %w", repoTag, err)
+		}
+		if message.Error != "" {
+			return fmt.Errorf("failed to load %s: %s", repoTag, message.Error)
+		}
+	}
+}
+
+// do sends a request to the Docker Engine API, and returns the response if
+// the request succeeds.
+func (t *Target) do(ctx context.Context, method, path string, query url.Values, body io.Reader) (*http.Response, error) {
+	u := (&url.URL{Path: path, RawQuery: query.Encode()}).String()
+	req, err := http.NewRequestWithContext(ctx, method, t.baseURL+u, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-tar")
+	}
+	resp, err := t.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	var apiErr struct {
+		Message string `json:"message"`
+	}
+	lr := io.LimitReader(resp.Body, 8*1024) // 8 KiB
+	if err := json.NewDecoder(lr).Decode(&apiErr); err != nil || apiErr.Message == "" {
+		apiErr.Message = resp.Status
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s: %w", apiErr.Message, errdef.ErrNotFound)
+	}
+	return nil, fmt.Errorf("%s %q: unexpected status code %d: %s", method, path, resp.StatusCode, apiErr.Message)
+}
+
+// splitReference splits an image name into the repository and the tag.
+// The tag defaults to "latest".
+func splitReference(reference string) (repo, tag string) {
+	if i := strings.LastIndex(reference, ":"); i > strings.LastIndex(reference, "/") {
+		return reference[:i], reference[i+1:]
+	}
+	return reference, "latest"
+}
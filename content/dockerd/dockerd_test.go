@@ -0,0 +1,237 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dockerd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/dockerarchive"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// fakeDaemon emulates the image endpoints of the Docker Engine API.
+type fakeDaemon struct {
+	t      *testing.T
+	images *memory.Store
+}
+
+func (d *fakeDaemon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/images/load":
+		if r.URL.Query().Get("quiet") != "1" {
+			d.t.Errorf("unexpected query: %v", r.URL.RawQuery)
+		}
+		if _, err := dockerarchive.Load(ctx, r.Body, d.images); err != nil {
+			fmt.Fprintf(w, `{"error":%q}`, err.Error())
+			return
+		}
+		fmt.Fprint(w, `{"stream":"Loaded image"}`)
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/get"):
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/images/"), "/get")
+		if _, err := d.images.Resolve(ctx, name); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"message":"No such image: %s"}`, name)
+			return
+		}
+		if err := dockerarchive.Save(ctx, w, d.images, []string{name}, dockerarchive.SaveOptions{}); err != nil {
+			d.t.Errorf("failed to save %s: %v", name, err)
+		}
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/tag"):
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/images/"), "/tag")
+		desc, err := d.images.Resolve(ctx, name)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		query := r.URL.Query()
+		if err := d.images.Tag(ctx, desc, query.Get("repo")+":"+query.Get("tag")); err != nil {
+			d.t.Errorf("failed to tag: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+	default:
+		d.t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// pushTestImage pushes an image to the store, and tags it with ref.
+func pushTestImage(t *testing.T, store oras.Target, ref string) []ocispec.Descriptor {
+	ctx := context.Background()
+	config, err := oras.PushBytes(ctx, store, ocispec.MediaTypeImageConfig, []byte(`{"architecture":"amd64","os":"linux"}`))
+	if err != nil {
+		t.Fatal("PushBytes() error =", err)
+	}
+	layer, err := oras.PushBytes(ctx, store, ocispec.MediaTypeImageLayer, []byte("foo"))
+	if err != nil {
+		t.Fatal("PushBytes() error =", err)
+	}
+	manifest, err := oras.Pack(ctx, store, "", []ocispec.Descriptor{layer}, oras.PackOptions{
+		PackImageManifest: true,
+		ConfigDescriptor:  &config,
+	})
+	if err != nil {
+		t.Fatal("Pack() error =", err)
+	}
+	if err := store.Tag(ctx, manifest, ref); err != nil {
+		t.Fatal("Tag() error =", err)
+	}
+	return []ocispec.Descriptor{config, layer}
+}
+
+func testTarget(t *testing.T, target *Target, daemon *fakeDaemon) {
+	ctx := context.Background()
+
+	// copy into the daemon
+	src := memory.New()
+	blobs := pushTestImage(t, src, "example.com/foo:v1")
+	if _, err := oras.Copy(ctx, src, "example.com/foo:v1", target, "", oras.DefaultCopyOptions); err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+	if _, err := daemon.images.Resolve(ctx, "example.com/foo:v1"); err != nil {
+		t.Fatal("image is not loaded into the daemon:", err)
+	}
+
+	// copy out of the daemon
+	dst := memory.New()
+	if _, err := oras.Copy(ctx, target, "example.com/foo:v1", dst, "", oras.DefaultCopyOptions); err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+	for _, desc := range blobs {
+		exists, err := dst.Exists(ctx, desc)
+		if err != nil {
+			t.Fatal("Exists() error =", err)
+		}
+		if !exists {
+			t.Errorf("blob %s is not copied", desc.Digest)
+		}
+	}
+
+	// tag an image in the daemon
+	desc, err := target.Resolve(ctx, "example.com/foo:v1")
+	if err != nil {
+		t.Fatal("Resolve() error =", err)
+	}
+	if err := target.Tag(ctx, desc, "example.com/foo:v2"); err != nil {
+		t.Fatal("Tag() error =", err)
+	}
+	if _, err := daemon.images.Resolve(ctx, "example.com/foo:v2"); err != nil {
+		t.Error("image is not tagged in the daemon:", err)
+	}
+
+	// resolve unknown image
+	if _, err := target.Resolve(ctx, "example.com/foo:unknown"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Resolve() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+
+	// tag unknown content
+	unknown := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, []byte("{}"))
+	if err := target.Tag(ctx, unknown, "example.com/foo:v3"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Tag() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+}
+
+func TestTarget_TCP(t *testing.T) {
+	daemon := &fakeDaemon{t: t, images: memory.New()}
+	ts := httptest.NewServer(daemon)
+	defer ts.Close()
+
+	target, err := New("tcp://" + strings.TrimPrefix(ts.URL, "http://"))
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	testTarget(t, target, daemon)
+}
+
+func TestTarget_UnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skip("unix socket not supported:", err)
+	}
+	daemon := &fakeDaemon{t: t, images: memory.New()}
+	ts := httptest.NewUnstartedServer(daemon)
+	ts.Listener = listener
+	ts.Start()
+	defer ts.Close()
+
+	target, err := New("unix://" + socketPath)
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	testTarget(t, target, daemon)
+}
+
+func TestTarget_LoadError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"error":"disk full"}`)
+	}))
+	defer ts.Close()
+	target, err := New(ts.URL)
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+
+	ctx := context.Background()
+	src := memory.New()
+	pushTestImage(t, src, "foo")
+	if _, err := oras.Copy(ctx, src, "foo", target, "", oras.DefaultCopyOptions); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("Copy() error = %v, want disk full", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New("ftp://localhost"); err == nil {
+		t.Error("New() error = nil, want unsupported scheme")
+	}
+	target, err := New("")
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	if target.baseURL != "http://docker" {
+		t.Errorf("New() baseURL = %v, want %v", target.baseURL, "http://docker")
+	}
+}
+
+func TestSplitReference(t *testing.T) {
+	tests := []struct {
+		reference string
+		repo, tag string
+	}{
+		{"hello-world", "hello-world", "latest"},
+		{"hello-world:v1", "hello-world", "v1"},
+		{"localhost:5000/hello-world", "localhost:5000/hello-world", "latest"},
+		{"localhost:5000/hello-world:v1", "localhost:5000/hello-world", "v1"},
+	}
+	for _, tt := range tests {
+		repo, tag := splitReference(tt.reference)
+		if repo != tt.repo || tag != tt.tag {
+			t.Errorf("splitReference(%q) = %q, %q, want %q, %q", tt.reference, repo, tag, tt.repo, tt.tag)
+		}
+	}
+}
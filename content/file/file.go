@@ -28,31 +28,21 @@ import (
 
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/annotation"
 	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/internal/cas"
 	"oras.land/oras-go/v2/internal/graph"
 	"oras.land/oras-go/v2/internal/ioutil"
+	"oras.land/oras-go/v2/internal/pool"
 	"oras.land/oras-go/v2/internal/resolver"
 )
 
-// bufPool is a pool of byte buffers that can be reused for copying content
-// between files.
-var bufPool = sync.Pool{
-	New: func() interface{} {
-		// the buffer size should be larger than or equal to 128 KiB
-		// for performance considerations.
-		// we choose 1 MiB here so there will be less disk I/O.
-		buffer := make([]byte, 1<<20) // buffer size = 1 MiB
-		return &buffer
-	},
-}
-
 const (
 	// AnnotationDigest is the annotation key for the digest of the uncompressed content.
-	AnnotationDigest = "io.deis.oras.content.digest"
+	AnnotationDigest = annotation.KeyUnpackDigest
 	// AnnotationUnpack is the annotation key for indication of unpacking.
-	AnnotationUnpack = "io.deis.oras.content.unpack"
+	AnnotationUnpack = annotation.KeyUnpack
 	// defaultBlobMediaType specifies the default blob media type.
 	defaultBlobMediaType = ocispec.MediaTypeImageLayer
 	// defaultBlobDirMediaType specifies the default blob directory media type.
@@ -187,7 +177,7 @@ func (s *Store) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCl
 	}
 
 	// if the target has name, check if the name exists.
-	name := target.Annotations[ocispec.AnnotationTitle]
+	name := annotation.Title(target.Annotations)
 	if name != "" && !s.nameExists(name) {
 		return nil, fmt.Errorf("%s: %s: %w", name, target.MediaType, errdef.ErrNotFound)
 	}
@@ -243,7 +233,7 @@ func (s *Store) Push(ctx context.Context, expected ocispec.Descriptor, content i
 // the fallback storage by default, or will be discarded when
 // Store.IgnoreNoName is true.
 func (s *Store) push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
-	name := expected.Annotations[ocispec.AnnotationTitle]
+	name := annotation.Title(expected.Annotations)
 	if name == "" {
 		if s.IgnoreNoName {
 			return errSkipUnnamed
@@ -265,7 +255,7 @@ func (s *Store) push(ctx context.Context, expected ocispec.Descriptor, content i
 		return fmt.Errorf("failed to resolve path for writing: %w", err)
 	}
 
-	if needUnpack := expected.Annotations[AnnotationUnpack]; needUnpack == "true" {
+	if annotation.NeedsUnpack(expected.Annotations) {
 		err = s.pushDir(name, target, expected, content)
 	} else {
 		err = s.pushFile(target, expected, content)
@@ -287,7 +277,7 @@ func (s *Store) restoreDuplicates(ctx context.Context, desc ocispec.Descriptor)
 		return err
 	}
 	for _, successor := range successors {
-		name := successor.Annotations[ocispec.AnnotationTitle]
+		name := annotation.Title(successor.Annotations)
 		if name == "" || s.nameExists(name) {
 			continue
 		}
@@ -320,7 +310,7 @@ func (s *Store) Exists(ctx context.Context, target ocispec.Descriptor) (bool, er
 	}
 
 	// if the target has name, check if the name exists.
-	name := target.Annotations[ocispec.AnnotationTitle]
+	name := annotation.Title(target.Annotations)
 	if name != "" && !s.nameExists(name) {
 		return false, nil
 	}
@@ -421,10 +411,7 @@ func (s *Store) Add(_ context.Context, name, mediaType, path string) (ocispec.De
 		return ocispec.Descriptor{}, fmt.Errorf("failed to generate descriptor from %s: %w", path, err)
 	}
 
-	if desc.Annotations == nil {
-		desc.Annotations = make(map[string]string)
-	}
-	desc.Annotations[ocispec.AnnotationTitle] = name
+	desc.Annotations = annotation.WithTitle(desc.Annotations, name)
 
 	// update the name status as existed
 	status.exists = true
@@ -441,8 +428,8 @@ func (s *Store) saveFile(fp *os.File, expected ocispec.Descriptor, content io.Re
 	}()
 	path := fp.Name()
 
-	buf := bufPool.Get().(*[]byte)
-	defer bufPool.Put(buf)
+	buf := pool.File.Get()
+	defer pool.File.Put(buf)
 	if err := ioutil.CopyBuffer(fp, content, *buf, expected); err != nil {
 		return fmt.Errorf("failed to copy content to %s: %w", path, err)
 	}
@@ -483,9 +470,9 @@ func (s *Store) pushDir(name, target string, expected ocispec.Descriptor, conten
 	}
 
 	checksum := expected.Annotations[AnnotationDigest]
-	buf := bufPool.Get().(*[]byte)
-	defer bufPool.Put(buf)
-	if err := extractTarGzip(target, name, gzPath, checksum, *buf); err != nil {
+	buf := pool.File.Get()
+	defer pool.File.Put(buf)
+	if err := extractTarArchive(target, name, gzPath, checksum, *buf); err != nil {
 		return fmt.Errorf("failed to extract tar to %s: %w", target, err)
 	}
 	return nil
@@ -517,8 +504,8 @@ func (s *Store) descriptorFromDir(name, mediaType, dir string) (desc ocispec.Des
 
 	tarDigester := digest.Canonical.Digester()
 	tw := io.MultiWriter(gzw, tarDigester.Hash())
-	buf := bufPool.Get().(*[]byte)
-	defer bufPool.Put(buf)
+	buf := pool.File.Get()
+	defer pool.File.Put(buf)
 	if err := tarDirectory(dir, name, tw, s.TarReproducible, *buf); err != nil {
 		return ocispec.Descriptor{}, fmt.Errorf("failed to tar %s: %w", dir, err)
 	}
@@ -549,10 +536,8 @@ func (s *Store) descriptorFromDir(name, mediaType, dir string) (desc ocispec.Des
 		MediaType: mediaType,
 		Digest:    gzDigest, // digest for the compressed content
 		Size:      fi.Size(),
-		Annotations: map[string]string{
-			AnnotationDigest: tarDigester.Digest().String(), // digest fot the uncompressed content
-			AnnotationUnpack: "true",                        // the content needs to be unpacked
-		},
+		// the content needs to be unpacked to the digested uncompressed content
+		Annotations: annotation.WithUnpack(nil, tarDigester.Digest()),
 	}, nil
 }
 
@@ -0,0 +1,97 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package file
+
+import (
+	"context"
+	"errors"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/annotation"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/progress"
+)
+
+// PullFilesOptions contains parameters for [file.PullFiles].
+type PullFilesOptions struct {
+	oras.CopyOptions
+	// Progress, if not nil, tracks the contents fetched from the source.
+	Progress *progress.Manager
+	// MediaTypes, if not empty, limits the files to the layers of the media
+	// types. Other layers with file names are skipped.
+	MediaTypes []string
+	// AllowPathTraversalOnWrite controls if files can be written outside
+	// dir. See Store for details.
+	AllowPathTraversalOnWrite bool
+	// DisableOverwrite controls if existing files in dir can be overwritten.
+	// See Store for details.
+	DisableOverwrite bool
+}
+
+// PullFiles copies the artifact tagged by ref in the source, and saves its
+// layers with file names into the directory dir, using a Store.
+// The file name of a layer is given by its "org.opencontainers.image.title"
+// annotation. Directory layers, which are tarballs annotated by
+// "io.deis.oras.content.unpack", are extracted into the directory of the
+// name.
+// Returns the descriptor of the root node on success.
+func PullFiles(ctx context.Context, src oras.ReadOnlyTarget, ref string, dir string, opts PullFilesOptions) (ocispec.Descriptor, error) {
+	if src == nil {
+		return ocispec.Descriptor{}, errors.New("nil source target")
+	}
+	store, err := New(dir)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	defer store.Close()
+	store.AllowPathTraversalOnWrite = opts.AllowPathTraversalOnWrite
+	store.DisableOverwrite = opts.DisableOverwrite
+
+	if opts.Progress != nil {
+		src = oras.WithReadOnlyFetcherMiddleware(src, progress.FetcherMiddleware(opts.Progress))
+	}
+	if len(opts.MediaTypes) > 0 {
+		findSuccessors := opts.FindSuccessors
+		if findSuccessors == nil {
+			findSuccessors = content.Successors
+		}
+		opts.FindSuccessors = func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+			successors, err := findSuccessors(ctx, fetcher, desc)
+			if err != nil {
+				return nil, err
+			}
+			var filtered []ocispec.Descriptor
+			for _, s := range successors {
+				if annotation.Title(s.Annotations) == "" || containsString(opts.MediaTypes, s.MediaType) {
+					filtered = append(filtered, s)
+				}
+			}
+			return filtered, nil
+		}
+	}
+	return oras.Copy(ctx, src, ref, store, ref, opts.CopyOptions)
+}
+
+// containsString returns true if s is in the list.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package file
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/progress"
+)
+
+// pushFiles pushes an artifact with a file layer and a directory layer into
+// a new memory store, and tags it by ref.
+func pushFiles(t *testing.T, ref string) *memory.Store {
+	ctx := context.Background()
+	srcDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(srcDir, "hello.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal("os.WriteFile() error =", err)
+	}
+	if err := os.MkdirAll(filepath.Join(srcDir, "docs"), 0755); err != nil {
+		t.Fatal("os.MkdirAll() error =", err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "docs", "readme.md"), []byte("readme"), 0644); err != nil {
+		t.Fatal("os.WriteFile() error =", err)
+	}
+
+	fs, err := New(srcDir)
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	defer fs.Close()
+	fileDesc, err := fs.Add(ctx, "hello.txt", "text/plain", "")
+	if err != nil {
+		t.Fatal("Store.Add() error =", err)
+	}
+	dirDesc, err := fs.Add(ctx, "docs", "", "")
+	if err != nil {
+		t.Fatal("Store.Add() error =", err)
+	}
+	root, err := oras.Pack(ctx, fs, "", []ocispec.Descriptor{fileDesc, dirDesc}, oras.PackOptions{})
+	if err != nil {
+		t.Fatal("Pack() error =", err)
+	}
+	if err := fs.Tag(ctx, root, ref); err != nil {
+		t.Fatal("Store.Tag() error =", err)
+	}
+	s := memory.New()
+	if _, err := oras.Copy(ctx, fs, ref, s, "", oras.DefaultCopyOptions); err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+	return s
+}
+
+func TestPullFiles(t *testing.T) {
+	ctx := context.Background()
+	src := pushFiles(t, "v1")
+	dir := t.TempDir()
+	manager := progress.NewManager(nil)
+	root, err := PullFiles(ctx, src, "v1", dir, PullFilesOptions{
+		CopyOptions: oras.DefaultCopyOptions,
+		Progress:    manager,
+	})
+	if err != nil {
+		t.Fatal("PullFiles() error =", err)
+	}
+	if got, err := src.Resolve(ctx, "v1"); err != nil || got.Digest != root.Digest {
+		t.Errorf("PullFiles() = %v, want %v", root, got)
+	}
+	for name, want := range map[string][]byte{
+		"hello.txt":                        []byte("hello"),
+		filepath.Join("docs", "readme.md"): []byte("readme"),
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("os.ReadFile(%s) error = %v", name, err)
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("file %s = %s, want %s", name, got, want)
+		}
+	}
+	if summary := manager.Summary(); len(summary.Items) == 0 {
+		t.Error("no progress tracked")
+	}
+}
+
+func TestPullFiles_MediaTypes(t *testing.T) {
+	ctx := context.Background()
+	src := pushFiles(t, "v1")
+	dir := t.TempDir()
+	if _, err := PullFiles(ctx, src, "v1", dir, PullFilesOptions{
+		CopyOptions: oras.DefaultCopyOptions,
+		MediaTypes:  []string{"text/plain"},
+	}); err != nil {
+		t.Fatal("PullFiles() error =", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "hello.txt")); err != nil {
+		t.Errorf("os.Stat(hello.txt) error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "docs")); !os.IsNotExist(err) {
+		t.Errorf("os.Stat(docs) error = %v, want not exist", err)
+	}
+}
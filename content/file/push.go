@@ -0,0 +1,102 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package file
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/progress"
+)
+
+// PushFilesOptions contains parameters for [file.PushFiles].
+type PushFilesOptions struct {
+	oras.CopyOptions
+	// WorkingDir is the directory the relative paths are resolved against.
+	// If empty, the current directory is used.
+	WorkingDir string
+	// MediaType is the media type of the layers.
+	// If empty, the default media types of Store.Add are used.
+	MediaType string
+	// ManifestAnnotations is the annotation map of the manifest.
+	ManifestAnnotations map[string]string
+	// Subject is the subject of the manifest.
+	Subject *ocispec.Descriptor
+	// TarReproducible controls if the tarballs of the directories are
+	// reproducible. See Store for details.
+	TarReproducible bool
+	// Progress, if not nil, tracks the contents pushed to the destination.
+	Progress *progress.Manager
+}
+
+// PushFiles adds the files and directories at the paths as layers, packs
+// them into an image manifest of the artifact type, and copies the artifact
+// to the destination, tagged by ref.
+//
+// Relative paths are used as the names of the layers as is, and absolute
+// paths are named by their base names. Directories are packed as tarballs,
+// which are extracted by PullFiles.
+// Returns the descriptor of the manifest on success.
+func PushFiles(ctx context.Context, dst oras.Target, ref string, paths []string, artifactType string, opts PushFilesOptions) (ocispec.Descriptor, error) {
+	if dst == nil {
+		return ocispec.Descriptor{}, errors.New("nil destination target")
+	}
+	if len(paths) == 0 {
+		return ocispec.Descriptor{}, errors.New("no files to push")
+	}
+	workingDir := opts.WorkingDir
+	if workingDir == "" {
+		workingDir = "."
+	}
+	store, err := New(workingDir)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	defer store.Close()
+	store.TarReproducible = opts.TarReproducible
+
+	layers := make([]ocispec.Descriptor, 0, len(paths))
+	for _, path := range paths {
+		name := filepath.ToSlash(filepath.Clean(path))
+		if filepath.IsAbs(path) {
+			name = filepath.Base(path)
+		}
+		desc, err := store.Add(ctx, name, opts.MediaType, path)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		layers = append(layers, desc)
+	}
+	root, err := oras.Pack(ctx, store, artifactType, layers, oras.PackOptions{
+		PackImageManifest:   true,
+		ManifestAnnotations: opts.ManifestAnnotations,
+		Subject:             opts.Subject,
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := store.Tag(ctx, root, ref); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	if opts.Progress != nil {
+		dst = oras.WithPusherMiddleware(dst, progress.PusherMiddleware(opts.Progress))
+	}
+	return oras.Copy(ctx, store, ref, dst, ref, opts.CopyOptions)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/progress"
+)
+
+func TestPushFiles(t *testing.T) {
+	ctx := context.Background()
+	srcDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(srcDir, "hello.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal("os.WriteFile() error =", err)
+	}
+	if err := os.MkdirAll(filepath.Join(srcDir, "docs"), 0755); err != nil {
+		t.Fatal("os.MkdirAll() error =", err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "docs", "readme.md"), []byte("readme"), 0644); err != nil {
+		t.Fatal("os.WriteFile() error =", err)
+	}
+	absPath := filepath.Join(t.TempDir(), "abs.txt")
+	if err := os.WriteFile(absPath, []byte("abs"), 0644); err != nil {
+		t.Fatal("os.WriteFile() error =", err)
+	}
+
+	dst := memory.New()
+	manager := progress.NewManager(nil)
+	artifactType := "application/vnd.test"
+	annotations := map[string]string{"foo": "bar"}
+	root, err := PushFiles(ctx, dst, "v1", []string{"hello.txt", "docs", absPath}, artifactType, PushFilesOptions{
+		CopyOptions:         oras.DefaultCopyOptions,
+		WorkingDir:          srcDir,
+		ManifestAnnotations: annotations,
+		Progress:            manager,
+	})
+	if err != nil {
+		t.Fatal("PushFiles() error =", err)
+	}
+	if got, err := dst.Resolve(ctx, "v1"); err != nil || !content.Equal(got, root) {
+		t.Errorf("Resolve() = %v, %v, want %v", got, err, root)
+	}
+	manifestJSON, err := content.FetchAll(ctx, dst, root)
+	if err != nil {
+		t.Fatal("FetchAll() error =", err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		t.Fatal("json.Unmarshal() error =", err)
+	}
+	if manifest.Config.MediaType != artifactType {
+		t.Errorf("Manifest.Config.MediaType = %v, want %v", manifest.Config.MediaType, artifactType)
+	}
+	if manifest.Annotations["foo"] != "bar" {
+		t.Errorf("Manifest.Annotations = %v, want foo=bar", manifest.Annotations)
+	}
+	var names []string
+	for _, layer := range manifest.Layers {
+		names = append(names, layer.Annotations[ocispec.AnnotationTitle])
+	}
+	if want := []string{"hello.txt", "docs", "abs.txt"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("layer names = %v, want %v", names, want)
+	}
+	if summary := manager.Summary(); len(summary.Items) == 0 {
+		t.Error("no progress tracked")
+	}
+
+	// round trip
+	dir := t.TempDir()
+	if _, err := PullFiles(ctx, dst, "v1", dir, PullFilesOptions{CopyOptions: oras.DefaultCopyOptions}); err != nil {
+		t.Fatal("PullFiles() error =", err)
+	}
+	for name, want := range map[string][]byte{
+		"hello.txt":                        []byte("hello"),
+		filepath.Join("docs", "readme.md"): []byte("readme"),
+		"abs.txt":                          []byte("abs"),
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("os.ReadFile(%s) error = %v", name, err)
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("file %s = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestPushFiles_Invalid(t *testing.T) {
+	ctx := context.Background()
+	if _, err := PushFiles(ctx, memory.New(), "v1", nil, "", PushFilesOptions{}); err == nil {
+		t.Error("PushFiles() error = nil, want error")
+	}
+	if _, err := PushFiles(ctx, memory.New(), "v1", []string{"missing"}, "", PushFilesOptions{WorkingDir: t.TempDir()}); err == nil {
+		t.Error("PushFiles() error = nil, want error")
+	}
+}
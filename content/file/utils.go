@@ -17,7 +17,6 @@ package file
 
 import (
 	"archive/tar"
-	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
@@ -27,6 +26,7 @@ import (
 	"time"
 
 	"github.com/opencontainers/go-digest"
+	"oras.land/oras-go/v2/content/compression"
 )
 
 // tarDirectory walks the directory specified by path, and tar those files with a new
@@ -102,9 +102,10 @@ func tarDirectory(root, prefix string, w io.Writer, removeTimes bool, buf []byte
 	})
 }
 
-// extractTarGzip decompresses the gzip
+// extractTarArchive decompresses the tar archive, which is compressed by any
+// algorithm with a registered decompressor such as gzip or zstd,
 // and extracts tar file to a directory specified by the `dir` parameter.
-func extractTarGzip(dir, prefix, filename, checksum string, buf []byte) (err error) {
+func extractTarArchive(dir, prefix, filename, checksum string, buf []byte) (err error) {
 	fp, err := os.Open(filename)
 	if err != nil {
 		return err
@@ -116,18 +117,18 @@ func extractTarGzip(dir, prefix, filename, checksum string, buf []byte) (err err
 		}
 	}()
 
-	gzr, err := gzip.NewReader(fp)
+	zr, _, err := compression.NewReader(fp)
 	if err != nil {
 		return err
 	}
 	defer func() {
-		closeErr := gzr.Close()
+		closeErr := zr.Close()
 		if err == nil {
 			err = closeErr
 		}
 	}()
 
-	var r io.Reader = gzr
+	var r io.Reader = zr
 	var verifier digest.Verifier
 	if checksum != "" {
 		if digest, err := digest.Parse(checksum); err == nil {
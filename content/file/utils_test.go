@@ -16,9 +16,14 @@ limitations under the License.
 package file
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/opencontainers/go-digest"
 )
 
 func Test_ensureBasePath(t *testing.T) {
@@ -153,3 +158,73 @@ func Test_ensureLinkPath(t *testing.T) {
 		})
 	}
 }
+
+func Test_extractTarArchive(t *testing.T) {
+	content := []byte("hello world")
+	var tarBuf bytes.Buffer
+	tw := tar.NewWriter(&tarBuf)
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     "testdir/",
+		Mode:     0755,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     "testdir/test.txt",
+		Mode:     0644,
+		Size:     int64(len(content)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	tarball := tarBuf.Bytes()
+	var gzBuf bytes.Buffer
+	gw := gzip.NewWriter(&gzBuf)
+	if _, err := gw.Write(tarball); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		archive []byte
+	}{
+		{name: "gzip", archive: gzBuf.Bytes()},
+		{name: "uncompressed", archive: tarball},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			filename := filepath.Join(tempDir, "archive")
+			if err := os.WriteFile(filename, tt.archive, 0644); err != nil {
+				t.Fatal(err)
+			}
+			dir := filepath.Join(tempDir, "testdir")
+			checksum := digest.FromBytes(tarball).String()
+			if err := extractTarArchive(dir, "testdir", filename, checksum, make([]byte, 1024)); err != nil {
+				t.Fatalf("extractTarArchive() error = %v", err)
+			}
+			got, err := os.ReadFile(filepath.Join(dir, "test.txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, content) {
+				t.Errorf("extracted content = %q, want %q", got, content)
+			}
+
+			// mismatched checksum
+			if err := extractTarArchive(filepath.Join(tempDir, "another"), "testdir", filename, digest.FromString("foo").String(), make([]byte, 1024)); err == nil {
+				t.Error("extractTarArchive() error = nil, want content digest mismatch")
+			}
+		})
+	}
+}
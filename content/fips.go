@@ -0,0 +1,86 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/opencontainers/go-digest"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// fipsMode indicates whether the digest algorithms are restricted to the
+// FIPS-approved ones.
+var fipsMode atomic.Bool
+
+// fipsApprovedAlgorithms lists the digest algorithms approved by FIPS 180-4
+// and accepted in FIPS mode.
+var fipsApprovedAlgorithms = map[digest.Algorithm]bool{
+	digest.SHA256: true,
+	digest.SHA384: true,
+	digest.SHA512: true,
+}
+
+// SetFIPSMode enables or disables the FIPS mode.
+// In FIPS mode, only the FIPS-approved digest algorithms (sha256, sha384 and
+// sha512) are accepted. Digests of any other algorithm, including the ones
+// registered by RegisterAlgorithm, are rejected by ValidateDigest,
+// NewDigester, NewVerifier and the packages built on top of them with an
+// *UnapprovedAlgorithmError.
+// SetFIPSMode is expected to be called during initialization.
+func SetFIPSMode(enabled bool) {
+	fipsMode.Store(enabled)
+}
+
+// FIPSMode returns true if the FIPS mode is enabled.
+func FIPSMode() bool {
+	return fipsMode.Load()
+}
+
+// FIPSApproved returns true if the digest algorithm is approved by FIPS.
+func FIPSApproved(alg digest.Algorithm) bool {
+	return fipsApprovedAlgorithms[alg]
+}
+
+// UnapprovedAlgorithmError is returned in FIPS mode when a digest of an
+// algorithm not approved by FIPS is encountered.
+// It matches both errdef.ErrUnsupported and digest.ErrDigestUnsupported by
+// errors.Is.
+type UnapprovedAlgorithmError struct {
+	// Algorithm is the rejected digest algorithm.
+	Algorithm digest.Algorithm
+}
+
+// Error returns the error message.
+func (e *UnapprovedAlgorithmError) Error() string {
+	return fmt.Sprintf("%s: digest algorithm not approved in FIPS mode", e.Algorithm)
+}
+
+// Is returns true if target is errdef.ErrUnsupported or
+// digest.ErrDigestUnsupported.
+func (e *UnapprovedAlgorithmError) Is(target error) bool {
+	return target == errdef.ErrUnsupported || target == digest.ErrDigestUnsupported
+}
+
+// checkFIPS returns an *UnapprovedAlgorithmError if the FIPS mode is enabled
+// and the digest algorithm is not approved.
+func checkFIPS(alg digest.Algorithm) error {
+	if fipsMode.Load() && !fipsApprovedAlgorithms[alg] {
+		return &UnapprovedAlgorithmError{Algorithm: alg}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content
+
+import (
+	"bytes"
+	_ "crypto/sha512"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func enableFIPSMode(t *testing.T) {
+	SetFIPSMode(true)
+	t.Cleanup(func() {
+		SetFIPSMode(false)
+	})
+}
+
+func TestFIPSMode(t *testing.T) {
+	setupTestAlgorithm(t)
+	blob := []byte("hello world")
+	unapproved, err := ComputeDigest(testAlgorithm, blob)
+	if err != nil {
+		t.Fatal("ComputeDigest() error =", err)
+	}
+
+	enableFIPSMode(t)
+	if !FIPSMode() {
+		t.Fatal("FIPSMode() = false, want true")
+	}
+
+	// approved algorithms
+	for _, alg := range []digest.Algorithm{digest.SHA256, digest.SHA384, digest.SHA512} {
+		if !AlgorithmAvailable(alg) {
+			t.Errorf("AlgorithmAvailable(%s) = false, want true", alg)
+		}
+		if err := ValidateDigest(alg.FromBytes(blob)); err != nil {
+			t.Errorf("ValidateDigest(%s) error = %v", alg, err)
+		}
+	}
+
+	// unapproved algorithm
+	if AlgorithmAvailable(testAlgorithm) {
+		t.Errorf("AlgorithmAvailable(%s) = true, want false", testAlgorithm)
+	}
+	var uae *UnapprovedAlgorithmError
+	err = ValidateDigest(unapproved)
+	if !errors.As(err, &uae) || uae.Algorithm != testAlgorithm {
+		t.Errorf("ValidateDigest() error = %v, want %T", err, uae)
+	}
+	if !errors.Is(err, errdef.ErrUnsupported) || !errors.Is(err, digest.ErrDigestUnsupported) {
+		t.Errorf("ValidateDigest() error = %v, want errdef.ErrUnsupported and digest.ErrDigestUnsupported", err)
+	}
+	if _, err := NewDigester(testAlgorithm); !errors.As(err, &uae) {
+		t.Errorf("NewDigester() error = %v, want %T", err, uae)
+	}
+	if _, err := NewVerifier(unapproved); !errors.As(err, &uae) {
+		t.Errorf("NewVerifier() error = %v, want %T", err, uae)
+	}
+	desc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageLayer,
+		Digest:    unapproved,
+		Size:      int64(len(blob)),
+	}
+	if _, err := ReadAll(bytes.NewReader(blob), desc); !errors.As(err, &uae) {
+		t.Errorf("ReadAll() error = %v, want %T", err, uae)
+	}
+
+	// disabling FIPS mode accepts the registered algorithm again
+	SetFIPSMode(false)
+	if err := ValidateDigest(unapproved); err != nil {
+		t.Errorf("ValidateDigest() error = %v, want nil", err)
+	}
+	vr := NewVerifyReader(bytes.NewReader(blob), desc)
+	if _, err := io.ReadAll(vr); err != nil {
+		t.Errorf("io.ReadAll() error = %v", err)
+	}
+	if err := vr.Verify(); err != nil {
+		t.Errorf("VerifyReader.Verify() error = %v", err)
+	}
+}
@@ -0,0 +1,228 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package httpurl provides a read-only storage fetching blobs from HTTP(S)
+// URLs, so that content hosted on plain web servers, object storage or
+// release pages can be copied with the standard Copy path.
+package httpurl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// Placeholders substituted in URL templates.
+const (
+	// PlaceholderDigest is replaced by the digest of the blob,
+	// e.g. "sha256:9834876dcfb05cb167a5c24953eba58c4ac89b1adf57f28f2f9d09af107ee8f0".
+	PlaceholderDigest = "{digest}"
+	// PlaceholderAlgorithm is replaced by the algorithm of the digest,
+	// e.g. "sha256".
+	PlaceholderAlgorithm = "{algorithm}"
+	// PlaceholderEncoded is replaced by the encoded portion of the digest,
+	// e.g. "9834876dcfb05cb167a5c24953eba58c4ac89b1adf57f28f2f9d09af107ee8f0".
+	PlaceholderEncoded = "{encoded}"
+)
+
+// Storage is a read-only storage fetching blobs from HTTP(S) URLs.
+//
+// The URLs of a blob are the URLs listed in its descriptor, followed by the
+// URL templates of the storage with the placeholders substituted. The URLs
+// are tried in order until the blob is found. The fetched content is verified
+// against the size and the digest of the descriptor.
+type Storage struct {
+	// Client is the HTTP client used to fetch the blobs.
+	// If nil, http.DefaultClient is used.
+	Client *http.Client
+	// Header is the additional header sent with each request.
+	Header http.Header
+	// IgnoreDescriptorURLs, if true, ignores the URLs listed in the
+	// descriptors.
+	IgnoreDescriptorURLs bool
+
+	// templates are the URL templates.
+	templates []string
+}
+
+// New creates a new Storage with the URL templates, such as
+// "https://example.com/blobs/{algorithm}/{encoded}".
+// Templates must be absolute HTTP or HTTPS URLs.
+func New(templates ...string) (*Storage, error) {
+	for _, template := range templates {
+		if err := validateURL(expand(template, ocispec.Descriptor{})); err != nil {
+			return nil, fmt.Errorf("invalid template %q: %w", template, err)
+		}
+	}
+	return &Storage{
+		templates: templates,
+	}, nil
+}
+
+// Fetch fetches the content identified by the descriptor from the first URL
+// having it. The returned reader verifies the content against the descriptor
+// on reaching EOF.
+// Returns ErrNotFound if none of the URLs has the content.
+func (s *Storage) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	if err := content.ValidateDigest(target.Digest); err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, err)
+	}
+	var lastErr error
+	for _, u := range s.urls(target) {
+		resp, err := s.do(ctx, http.MethodGet, u)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+			if resp.ContentLength != -1 && resp.ContentLength != target.Size {
+				resp.Body.Close()
+				lastErr = fmt.Errorf("%s %q: mismatch Content-Length %d: %w", http.MethodGet, u, resp.ContentLength, content.ErrInvalidDescriptorSize)
+				continue
+			}
+			return &verifyReadCloser{
+				vr: content.NewVerifyReader(resp.Body, target),
+				rc: resp.Body,
+			}, nil
+		case http.StatusNotFound:
+			resp.Body.Close()
+		default:
+			resp.Body.Close()
+			lastErr = fmt.Errorf("%s %q: unexpected status code %d", http.MethodGet, u, resp.StatusCode)
+		}
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, errdef.ErrNotFound)
+}
+
+// Exists returns true if any of the URLs has the described content.
+func (s *Storage) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	if err := content.ValidateDigest(target.Digest); err != nil {
+		return false, fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, err)
+	}
+	var lastErr error
+	for _, u := range s.urls(target) {
+		resp, err := s.do(ctx, http.MethodHead, u)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusOK:
+			if resp.ContentLength == -1 || resp.ContentLength == target.Size {
+				return true, nil
+			}
+		case http.StatusNotFound:
+		default:
+			lastErr = fmt.Errorf("%s %q: unexpected status code %d", http.MethodHead, u, resp.StatusCode)
+		}
+	}
+	return false, lastErr
+}
+
+// urls returns the URLs of the target in order.
+func (s *Storage) urls(target ocispec.Descriptor) []string {
+	var urls []string
+	if !s.IgnoreDescriptorURLs {
+		for _, u := range target.URLs {
+			if validateURL(u) == nil {
+				urls = append(urls, u)
+			}
+		}
+	}
+	for _, template := range s.templates {
+		urls = append(urls, expand(template, target))
+	}
+	return urls
+}
+
+// do sends an HTTP request to u.
+func (s *Storage) do(ctx context.Context, method, u string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range s.Header {
+		req.Header[key] = append(req.Header[key], values...)
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+// expand substitutes the placeholders in the template with the digest of
+// the target.
+func expand(template string, target ocispec.Descriptor) string {
+	var algorithm, encoded string
+	if alg, enc, ok := strings.Cut(target.Digest.String(), ":"); ok {
+		algorithm, encoded = alg, enc
+	}
+	return strings.NewReplacer(
+		PlaceholderDigest, target.Digest.String(),
+		PlaceholderAlgorithm, algorithm,
+		PlaceholderEncoded, encoded,
+	).Replace(template)
+}
+
+// validateURL ensures u is an absolute HTTP or HTTPS URL.
+func validateURL(u string) error {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", parsed.Scheme)
+	}
+	if parsed.Host == "" {
+		return errors.New("missing host")
+	}
+	return nil
+}
+
+// verifyReadCloser verifies the content on reaching EOF.
+type verifyReadCloser struct {
+	vr *content.VerifyReader
+	rc io.Closer
+}
+
+// Read reads from the underlying reader, and verifies the content on EOF.
+func (r *verifyReadCloser) Read(p []byte) (int, error) {
+	n, err := r.vr.Read(p)
+	if err == io.EOF {
+		if verr := r.vr.Verify(); verr != nil {
+			return n, verr
+		}
+	}
+	return n, err
+}
+
+// Close closes the underlying reader.
+func (r *verifyReadCloser) Close() error {
+	return r.rc.Close()
+}
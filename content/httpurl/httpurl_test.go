@@ -0,0 +1,165 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpurl
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestStorage(t *testing.T) {
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+	corrupted := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, []byte("hello WORLD"))
+	missing := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, []byte("missing"))
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/releases/" + desc.Digest.Encoded(), "/releases/" + corrupted.Digest.Encoded():
+			w.Write(blob)
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	s, err := New(ts.URL+"/mirror/{digest}", ts.URL+"/releases/{encoded}")
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	s.Header = http.Header{"Authorization": {"Bearer token"}}
+	ctx := context.Background()
+
+	// test fetching from the templates
+	rc, err := s.Fetch(ctx, desc)
+	if err != nil {
+		t.Fatal("Storage.Fetch() error =", err)
+	}
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal("Storage.Fetch().Read() error =", err)
+	}
+	rc.Close()
+	if string(got) != string(blob) {
+		t.Errorf("Storage.Fetch() = %s, want %s", got, blob)
+	}
+	want := []string{
+		"GET /mirror/" + desc.Digest.String(),
+		"GET /releases/" + desc.Digest.Encoded(),
+	}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("requested paths = %v, want %v", paths, want)
+	}
+
+	// test fetching from the descriptor URLs first
+	paths = nil
+	withURLs := desc
+	withURLs.URLs = []string{"file:///etc/passwd", ts.URL + "/releases/" + desc.Digest.Encoded()}
+	rc, err = s.Fetch(ctx, withURLs)
+	if err != nil {
+		t.Fatal("Storage.Fetch() error =", err)
+	}
+	rc.Close()
+	if want := "GET /releases/" + desc.Digest.Encoded(); len(paths) != 1 || paths[0] != want {
+		t.Errorf("requested paths = %v, want %v", paths, want)
+	}
+
+	// test corrupted content
+	rc, err = s.Fetch(ctx, corrupted)
+	if err != nil {
+		t.Fatal("Storage.Fetch() error =", err)
+	}
+	if _, err := io.ReadAll(rc); !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("Storage.Fetch().Read() error = %v, wantErr %v", err, content.ErrMismatchedDigest)
+	}
+	rc.Close()
+
+	// test missing content
+	if _, err := s.Fetch(ctx, missing); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Storage.Fetch() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+
+	// test size mismatch
+	wrongSize := desc
+	wrongSize.Size++
+	if _, err := s.Fetch(ctx, wrongSize); !errors.Is(err, content.ErrInvalidDescriptorSize) {
+		t.Errorf("Storage.Fetch() error = %v, wantErr %v", err, content.ErrInvalidDescriptorSize)
+	}
+
+	// test Exists
+	if exists, err := s.Exists(ctx, desc); err != nil || !exists {
+		t.Errorf("Storage.Exists() = %v, %v, want true", exists, err)
+	}
+	if exists, err := s.Exists(ctx, missing); err != nil || exists {
+		t.Errorf("Storage.Exists() = %v, %v, want false", exists, err)
+	}
+
+	// test server errors
+	failing, err := New(ts.URL + "/error?{digest}")
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	failing.Header = s.Header
+	if _, err := failing.Fetch(ctx, desc); err == nil || errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Storage.Fetch() error = %v, want unexpected status code", err)
+	}
+	if _, err := failing.Exists(ctx, desc); err == nil {
+		t.Error("Storage.Exists() error = nil, wantErr true")
+	}
+
+	// test ignoring the descriptor URLs
+	paths = nil
+	s.IgnoreDescriptorURLs = true
+	withURLs.URLs = []string{ts.URL + "/elsewhere"}
+	rc, err = s.Fetch(ctx, withURLs)
+	if err != nil {
+		t.Fatal("Storage.Fetch() error =", err)
+	}
+	rc.Close()
+	for _, path := range paths {
+		if path == "GET /elsewhere" {
+			t.Errorf("descriptor URL requested with IgnoreDescriptorURLs")
+		}
+	}
+}
+
+func TestNew_InvalidTemplate(t *testing.T) {
+	for _, template := range []string{
+		"file:///blobs/{digest}",
+		"/blobs/{digest}",
+		"https:///blobs/{digest}",
+	} {
+		if _, err := New(template); err == nil {
+			t.Errorf("New(%q) error = nil, wantErr true", template)
+		}
+	}
+}
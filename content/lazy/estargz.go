@@ -0,0 +1,381 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lazy
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+const (
+	// footerSize is the size of the eStargz footer.
+	footerSize = 51
+	// legacyFooterSize is the size of the legacy stargz footer.
+	legacyFooterSize = 47
+	// tocName is the name of the TOC file in the layer.
+	tocName = "stargz.index.json"
+	// maxTOCBytes limits the size of the TOC.
+	maxTOCBytes int64 = 64 * 1024 * 1024 // 64 MiB
+)
+
+// AnnotationTOCDigest is the layer annotation for the digest of the
+// uncompressed TOC of an eStargz layer.
+const AnnotationTOCDigest = "containerd.io/snapshot/stargz/toc.digest"
+
+// Entry types in the TOC.
+const (
+	EntryTypeDir      = "dir"
+	EntryTypeReg      = "reg"
+	EntryTypeSymlink  = "symlink"
+	EntryTypeHardlink = "hardlink"
+	EntryTypeChar     = "char"
+	EntryTypeBlock    = "block"
+	EntryTypeFIFO     = "fifo"
+	EntryTypeChunk    = "chunk"
+)
+
+// ErrNotSeekable is returned when a layer is not in the eStargz format.
+var ErrNotSeekable = errors.New("layer is not seekable")
+
+// TOCEntry is an entry in the table of contents (TOC) of an eStargz layer.
+type TOCEntry struct {
+	// Name is the name of the file.
+	Name string `json:"name"`
+	// Type is the type of the entry, such as "reg" or "chunk".
+	Type string `json:"type"`
+	// Size is the size of a regular file.
+	Size int64 `json:"size,omitempty"`
+	// ModTime3339 is the modification time in RFC 3339 format.
+	ModTime3339 string `json:"modtime,omitempty"`
+	// LinkName is the link target of a symbolic link or a hard link.
+	LinkName string `json:"linkName,omitempty"`
+	// Mode is the permission and mode bits.
+	Mode int64 `json:"mode,omitempty"`
+	// UID is the user ID of the owner.
+	UID int `json:"uid,omitempty"`
+	// GID is the group ID of the owner.
+	GID int `json:"gid,omitempty"`
+	// Offset is the offset of the gzip stream holding the chunk in the
+	// layer.
+	Offset int64 `json:"offset,omitempty"`
+	// ChunkOffset is the offset of the chunk in the file.
+	ChunkOffset int64 `json:"chunkOffset,omitempty"`
+	// ChunkSize is the size of the chunk. 0 indicates the rest of the file.
+	ChunkSize int64 `json:"chunkSize,omitempty"`
+	// Digest is the digest of a regular file.
+	Digest string `json:"digest,omitempty"`
+	// ChunkDigest is the digest of the chunk.
+	ChunkDigest string `json:"chunkDigest,omitempty"`
+}
+
+// toc is the table of contents of an eStargz layer.
+type toc struct {
+	Version int         `json:"version"`
+	Entries []*TOCEntry `json:"entries"`
+}
+
+// Layer is an opened eStargz layer whose files are fetched on demand.
+type Layer struct {
+	fetcher content.Fetcher
+	desc    ocispec.Descriptor
+
+	// tocOffset is the offset of the TOC in the layer.
+	tocOffset int64
+	// entries are the TOC entries.
+	entries []*TOCEntry
+	// chunks maps file names to their chunk entries, ordered by chunk
+	// offset.
+	chunks map[string][]*TOCEntry
+	// offsets are the sorted offsets of the chunks.
+	offsets []int64
+}
+
+// Open reads the footer and the TOC of the eStargz layer described by desc
+// using range requests, and returns the opened layer.
+// If the layer has the AnnotationTOCDigest annotation, the TOC is verified
+// against it.
+// Returns ErrNotSeekable if the layer is not in the eStargz format.
+func Open(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) (*Layer, error) {
+	if desc.Size < legacyFooterSize {
+		return nil, fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, ErrNotSeekable)
+	}
+	size := int64(footerSize)
+	if desc.Size < size {
+		size = legacyFooterSize
+	}
+	footer, err := readRange(ctx, fetcher, desc, desc.Size-size, size)
+	if err != nil {
+		return nil, err
+	}
+	tocOffset, footerLen, err := parseFooter(footer)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %v: %w", desc.Digest, desc.MediaType, err, ErrNotSeekable)
+	}
+	tocEnd := desc.Size - int64(footerLen)
+	if tocOffset < 0 || tocOffset > tocEnd || tocEnd-tocOffset > maxTOCBytes {
+		return nil, fmt.Errorf("%s: %s: invalid TOC offset %d: %w", desc.Digest, desc.MediaType, tocOffset, ErrNotSeekable)
+	}
+
+	tocGzip, err := readRange(ctx, fetcher, desc, tocOffset, tocEnd-tocOffset)
+	if err != nil {
+		return nil, err
+	}
+	tocJSON, err := readTOC(tocGzip)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, err)
+	}
+	if want := desc.Annotations[AnnotationTOCDigest]; want != "" {
+		if got := digest.FromBytes(tocJSON); got.String() != want {
+			return nil, fmt.Errorf("%s: %s: TOC digest mismatch: got %s, want %s", desc.Digest, desc.MediaType, got, want)
+		}
+	}
+	var t toc
+	if err := json.Unmarshal(tocJSON, &t); err != nil {
+		return nil, fmt.Errorf("%s: %s: failed to decode TOC: %w", desc.Digest, desc.MediaType, err)
+	}
+
+	layer := &Layer{
+		fetcher:   fetcher,
+		desc:      desc,
+		tocOffset: tocOffset,
+		entries:   t.Entries,
+		chunks:    make(map[string][]*TOCEntry),
+	}
+	for _, entry := range t.Entries {
+		switch entry.Type {
+		case EntryTypeReg, EntryTypeChunk:
+			if entry.Type == EntryTypeReg && entry.Size == 0 {
+				continue
+			}
+			layer.chunks[entry.Name] = append(layer.chunks[entry.Name], entry)
+			layer.offsets = append(layer.offsets, entry.Offset)
+		}
+	}
+	for _, chunks := range layer.chunks {
+		sort.Slice(chunks, func(i, j int) bool {
+			return chunks[i].ChunkOffset < chunks[j].ChunkOffset
+		})
+	}
+	sort.Slice(layer.offsets, func(i, j int) bool {
+		return layer.offsets[i] < layer.offsets[j]
+	})
+	return layer, nil
+}
+
+// Entries returns the entries in the TOC of the layer.
+func (l *Layer) Entries() []*TOCEntry {
+	return l.entries
+}
+
+// TOCOffset returns the offset of the TOC in the layer, which is also the end
+// of the compressed file contents.
+func (l *Layer) TOCOffset() int64 {
+	return l.tocOffset
+}
+
+// Lookup returns the TOC entry of the file with the given name.
+func (l *Layer) Lookup(name string) (*TOCEntry, bool) {
+	for _, entry := range l.entries {
+		if entry.Name == name && entry.Type != EntryTypeChunk {
+			return entry, true
+		}
+	}
+	return nil, false
+}
+
+// OpenFile returns a reader of the regular file with the given name.
+// The chunks of the file are fetched on demand as the file is read, and each
+// chunk is verified against its digest.
+func (l *Layer) OpenFile(ctx context.Context, name string) (io.ReadCloser, error) {
+	entry, ok := l.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", name, errdef.ErrNotFound)
+	}
+	if entry.Type != EntryTypeReg {
+		return nil, fmt.Errorf("%s: not a regular file", name)
+	}
+	return &fileReader{
+		ctx:    ctx,
+		layer:  l,
+		chunks: l.chunks[name],
+		size:   entry.Size,
+	}, nil
+}
+
+// ReadFile reads the regular file with the given name.
+func (l *Layer) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	rc, err := l.OpenFile(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// readChunk fetches and decompresses the chunk of a file whose size is size.
+func (l *Layer) readChunk(ctx context.Context, chunk *TOCEntry, size int64) ([]byte, error) {
+	chunkSize := chunk.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = size - chunk.ChunkOffset
+	}
+
+	// the compressed chunk ends at the next chunk or at the TOC
+	end := l.tocOffset
+	i := sort.Search(len(l.offsets), func(i int) bool {
+		return l.offsets[i] > chunk.Offset
+	})
+	if i < len(l.offsets) && l.offsets[i] < end {
+		end = l.offsets[i]
+	}
+	compressed, err := readRange(ctx, l.fetcher, l.desc, chunk.Offset, end-chunk.Offset)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to decompress chunk at %d: %w", chunk.Name, chunk.ChunkOffset, err)
+	}
+	zr.Multistream(false)
+	data := make([]byte, chunkSize)
+	if _, err := io.ReadFull(zr, data); err != nil {
+		return nil, fmt.Errorf("%s: failed to decompress chunk at %d: %w", chunk.Name, chunk.ChunkOffset, err)
+	}
+	if chunk.ChunkDigest != "" {
+		want, err := digest.Parse(chunk.ChunkDigest)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid chunk digest: %w", chunk.Name, err)
+		}
+		if got := want.Algorithm().FromBytes(data); got != want {
+			return nil, fmt.Errorf("%s: chunk at %d: %w", chunk.Name, chunk.ChunkOffset, content.ErrMismatchedDigest)
+		}
+	}
+	return data, nil
+}
+
+// fileReader reads a file chunk by chunk.
+type fileReader struct {
+	ctx    context.Context
+	layer  *Layer
+	chunks []*TOCEntry
+	size   int64
+	buf    []byte
+}
+
+// Read reads the file, fetching the next chunk when needed.
+func (r *fileReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if len(r.chunks) == 0 {
+			return 0, io.EOF
+		}
+		data, err := r.layer.readChunk(r.ctx, r.chunks[0], r.size)
+		if err != nil {
+			return 0, err
+		}
+		r.chunks = r.chunks[1:]
+		r.buf = data
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Close closes the reader.
+func (r *fileReader) Close() error {
+	r.chunks = nil
+	r.buf = nil
+	return nil
+}
+
+// parseFooter parses the footer of an eStargz layer or a legacy stargz layer,
+// and returns the TOC offset and the footer size.
+// footer holds the last footerSize bytes of the layer, or the last
+// legacyFooterSize bytes if the layer is smaller.
+func parseFooter(footer []byte) (int64, int, error) {
+	if len(footer) >= footerSize {
+		if offset, err := parseFooterExtra(footer[len(footer)-footerSize:], true); err == nil {
+			return offset, footerSize, nil
+		}
+	}
+	offset, err := parseFooterExtra(footer[len(footer)-legacyFooterSize:], false)
+	if err != nil {
+		return 0, 0, err
+	}
+	return offset, legacyFooterSize, nil
+}
+
+// parseFooterExtra parses the TOC offset in the gzip extra field of the
+// footer. The extra field of an eStargz footer is a subfield with ID "SG".
+func parseFooterExtra(footer []byte, subfield bool) (int64, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(footer))
+	if err != nil {
+		return 0, fmt.Errorf("invalid footer: %w", err)
+	}
+	extra := zr.Header.Extra
+	if subfield {
+		if len(extra) < 4 || extra[0] != 'S' || extra[1] != 'G' {
+			return 0, errors.New("invalid footer: missing stargz subfield")
+		}
+		extra = extra[4:]
+	}
+	if len(extra) != 22 || string(extra[16:]) != "STARGZ" {
+		return 0, errors.New("invalid footer: missing stargz magic")
+	}
+	return strconv.ParseInt(string(extra[:16]), 16, 64)
+}
+
+// readTOC decompresses the TOC tar stream and returns the TOC JSON.
+func readTOC(tocGzip []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(tocGzip))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress TOC: %w", err)
+	}
+	tr := tar.NewReader(zr)
+	header, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TOC: %w", err)
+	}
+	if header.Name != tocName {
+		return nil, fmt.Errorf("unexpected TOC file %q", header.Name)
+	}
+	return io.ReadAll(io.LimitReader(tr, maxTOCBytes))
+}
+
+// readRange reads length bytes of the content starting at offset.
+func readRange(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor, offset, length int64) ([]byte, error) {
+	rc, err := content.FetchRange(ctx, fetcher, desc, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data := make([]byte, length)
+	if _, err := io.ReadFull(rc, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
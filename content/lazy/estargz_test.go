@@ -0,0 +1,239 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lazy
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// testFile is a file in a test eStargz layer.
+type testFile struct {
+	name string
+	data []byte
+}
+
+// buildEStargz builds an eStargz layer holding the files, split into chunks
+// of chunkSize bytes.
+func buildEStargz(t *testing.T, files []testFile, chunkSize int) ([]byte, []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	// writeMember writes the data as a gzip member.
+	writeMember := func(data []byte) {
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// tarFile returns the tar header block and the padded data of a file.
+	tarFile := func(name string, data []byte) ([]byte, []byte) {
+		var tb bytes.Buffer
+		tw := tar.NewWriter(&tb)
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(data)),
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := tw.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		return tb.Bytes()[:512], tb.Bytes()[512:]
+	}
+
+	var entries []*TOCEntry
+	for _, file := range files {
+		header, padded := tarFile(file.name, file.data)
+		writeMember(header)
+		for off := 0; off < len(file.data); off += chunkSize {
+			end := off + chunkSize
+			if end > len(file.data) {
+				end = len(file.data)
+			}
+			entry := &TOCEntry{
+				Name:        file.name,
+				Type:        EntryTypeChunk,
+				Offset:      int64(buf.Len()),
+				ChunkOffset: int64(off),
+				ChunkSize:   int64(end - off),
+				ChunkDigest: digest.FromBytes(file.data[off:end]).String(),
+			}
+			if off == 0 {
+				entry.Type = EntryTypeReg
+				entry.Size = int64(len(file.data))
+				entry.Mode = 0644
+				entry.Digest = digest.FromBytes(file.data).String()
+			}
+			if end == len(file.data) {
+				entry.ChunkSize = 0
+			}
+			entries = append(entries, entry)
+			writeMember(file.data[off:end])
+		}
+		if len(file.data) == 0 {
+			entries = append(entries, &TOCEntry{Name: file.name, Type: EntryTypeReg, Mode: 0644})
+		}
+		// pad the file to the tar block size
+		if pad := padded[len(file.data):]; len(pad) > 0 {
+			writeMember(pad)
+		}
+	}
+
+	tocOffset := int64(buf.Len())
+	tocJSON, err := json.Marshal(toc{Version: 1, Entries: entries})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tb bytes.Buffer
+	tw := tar.NewWriter(&tb)
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     tocName,
+		Mode:     0644,
+		Size:     int64(len(tocJSON)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(tocJSON); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	writeMember(tb.Bytes())
+
+	// the footer is an empty gzip member with a stored deflate block
+	footer := []byte{0x1f, 0x8b, 8, 4, 0, 0, 0, 0, 0, 0xff, 26, 0, 'S', 'G', 22, 0}
+	footer = append(footer, fmt.Sprintf("%016xSTARGZ", tocOffset)...)
+	footer = append(footer, 1, 0, 0, 0xff, 0xff, 0, 0, 0, 0, 0, 0, 0, 0)
+	if len(footer) != footerSize {
+		t.Fatalf("unexpected footer size: %d", len(footer))
+	}
+	buf.Write(footer)
+	return buf.Bytes(), tocJSON
+}
+
+func TestOpen(t *testing.T) {
+	ctx := context.Background()
+	files := []testFile{
+		{name: "hello.txt", data: []byte("hello world")},
+		{name: "big.txt", data: bytes.Repeat([]byte("0123456789"), 10)},
+		{name: "empty.txt"},
+	}
+	blob, tocJSON := buildEStargz(t, files, 16)
+	if got := int64(len(blob)); got < footerSize {
+		t.Fatalf("unexpected layer size: %d", got)
+	}
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayerGzip, blob)
+	desc.Annotations = map[string]string{
+		AnnotationTOCDigest: digest.FromBytes(tocJSON).String(),
+	}
+	store := memory.New()
+	if err := store.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+
+	layer, err := Open(ctx, store, desc)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if got := layer.TOCOffset(); got <= 0 || got >= desc.Size-footerSize {
+		t.Errorf("Layer.TOCOffset() = %d, want in (0, %d)", got, desc.Size-footerSize)
+	}
+	for _, file := range files[:2] {
+		entry, ok := layer.Lookup(file.name)
+		if !ok {
+			t.Fatalf("Layer.Lookup(%s) not found", file.name)
+		}
+		if entry.Size != int64(len(file.data)) {
+			t.Errorf("Layer.Lookup(%s).Size = %d, want %d", file.name, entry.Size, len(file.data))
+		}
+		got, err := layer.ReadFile(ctx, file.name)
+		if err != nil {
+			t.Fatalf("Layer.ReadFile(%s) error = %v", file.name, err)
+		}
+		if !bytes.Equal(got, file.data) {
+			t.Errorf("Layer.ReadFile(%s) = %q, want %q", file.name, got, file.data)
+		}
+	}
+	if _, err := layer.ReadFile(ctx, "missing.txt"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Layer.ReadFile() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+
+	// TOC digest mismatch
+	badDesc := desc
+	badDesc.Annotations = map[string]string{
+		AnnotationTOCDigest: digest.FromString("foo").String(),
+	}
+	if _, err := Open(ctx, store, badDesc); err == nil {
+		t.Error("Open() error = nil, want TOC digest mismatch")
+	}
+}
+
+func TestOpen_ChunkDigestMismatch(t *testing.T) {
+	ctx := context.Background()
+	blob, _ := buildEStargz(t, []testFile{{name: "hello.txt", data: []byte("hello world")}}, 4)
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayerGzip, blob)
+	store := memory.New()
+	if err := store.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	layer, err := Open(ctx, store, desc)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	layer.chunks["hello.txt"][1].ChunkDigest = digest.FromString("foo").String()
+	if _, err := layer.ReadFile(ctx, "hello.txt"); !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("Layer.ReadFile() error = %v, want %v", err, content.ErrMismatchedDigest)
+	}
+}
+
+func TestOpen_NotSeekable(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(bytes.Repeat([]byte("foo"), 100))
+	zw.Close()
+	blob := buf.Bytes()
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayerGzip, blob)
+	store := memory.New()
+	if err := store.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	if _, err := Open(ctx, store, desc); !errors.Is(err, ErrNotSeekable) {
+		t.Errorf("Open() error = %v, want %v", err, ErrNotSeekable)
+	}
+}
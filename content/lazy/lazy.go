@@ -0,0 +1,22 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lazy provides on-demand access to the files in seekable layers, so
+// that only the needed files are fetched from huge layers.
+//
+// Layers in the eStargz format can be opened by Open, after which individual
+// files are fetched with range requests. For layers indexed by SOCI, the zTOCs
+// describing the layers can be located by FindZTOCs.
+package lazy
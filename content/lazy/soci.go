@@ -0,0 +1,81 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lazy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+)
+
+// SOCI artifact types and annotations.
+// Reference: https://github.com/awslabs/soci-snapshotter
+const (
+	// ArtifactTypeSOCIIndex is the artifact type of SOCI indexes.
+	ArtifactTypeSOCIIndex = "application/vnd.amazon.soci.index.v1+json"
+	// AnnotationSOCIImageLayerDigest is the zTOC annotation for the digest of
+	// the indexed layer.
+	AnnotationSOCIImageLayerDigest = "com.amazon.soci.image-layer-digest"
+	// AnnotationSOCIImageLayerMediaType is the zTOC annotation for the media
+	// type of the indexed layer.
+	AnnotationSOCIImageLayerMediaType = "com.amazon.soci.image-layer-mediatype"
+)
+
+// FindZTOCs finds the SOCI index referring to the image manifest, and returns
+// the descriptors of the zTOCs in the index keyed by the digests of the
+// indexed layers.
+// A zTOC records the spans of a layer, which can be fetched by FetchRange.
+// Decoding the zTOCs is left to the caller.
+// Returns ErrNotFound if the manifest has no SOCI index.
+func FindZTOCs(ctx context.Context, target content.ReadOnlyStorage, manifest ocispec.Descriptor) (map[digest.Digest]ocispec.Descriptor, error) {
+	referrers, err := registry.Referrers(ctx, target, manifest, ArtifactTypeSOCIIndex)
+	if err != nil {
+		return nil, err
+	}
+	if len(referrers) == 0 {
+		return nil, fmt.Errorf("%s: %s: SOCI index: %w", manifest.Digest, manifest.MediaType, errdef.ErrNotFound)
+	}
+
+	indexJSON, err := content.FetchAll(ctx, target, referrers[0])
+	if err != nil {
+		return nil, err
+	}
+	// SOCI indexes are either artifact manifests with blobs, or image
+	// manifests with layers.
+	var index struct {
+		Blobs  []ocispec.Descriptor `json:"blobs"`
+		Layers []ocispec.Descriptor `json:"layers"`
+	}
+	if err := json.Unmarshal(indexJSON, &index); err != nil {
+		return nil, fmt.Errorf("failed to decode SOCI index %s: %w", referrers[0].Digest, err)
+	}
+
+	ztocs := make(map[digest.Digest]ocispec.Descriptor)
+	for _, ztoc := range append(index.Blobs, index.Layers...) {
+		layerDigest, err := content.ParseDigest(ztoc.Annotations[AnnotationSOCIImageLayerDigest])
+		if err != nil {
+			continue
+		}
+		ztocs[layerDigest] = ztoc
+	}
+	return ztocs, nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lazy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestFindZTOCs(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := store.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal("Push() error =", err)
+		}
+		return desc
+	}
+	pushManifest := func(manifest ocispec.Manifest) ocispec.Descriptor {
+		manifest.Versioned = specs.Versioned{SchemaVersion: 2}
+		manifest.MediaType = ocispec.MediaTypeImageManifest
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return push(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+
+	config := push(ocispec.MediaTypeImageConfig, []byte("{}"))
+	layer := push(ocispec.MediaTypeImageLayerGzip, []byte("layer"))
+	image := pushManifest(ocispec.Manifest{
+		Config: config,
+		Layers: []ocispec.Descriptor{layer},
+	})
+
+	// no SOCI index
+	if _, err := FindZTOCs(ctx, store, image); !errors.Is(err, errdef.ErrNotFound) {
+		t.Fatalf("FindZTOCs() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+
+	ztoc := push("application/octet-stream", []byte("ztoc"))
+	ztoc.Annotations = map[string]string{
+		AnnotationSOCIImageLayerDigest:    layer.Digest.String(),
+		AnnotationSOCIImageLayerMediaType: layer.MediaType,
+	}
+	pushManifest(ocispec.Manifest{
+		Config:  push(ArtifactTypeSOCIIndex, []byte("{}")),
+		Layers:  []ocispec.Descriptor{ztoc},
+		Subject: &image,
+	})
+
+	got, err := FindZTOCs(ctx, store, image)
+	if err != nil {
+		t.Fatalf("FindZTOCs() error = %v", err)
+	}
+	if len(got) != 1 || !content.Equal(got[layer.Digest], ztoc) {
+		t.Errorf("FindZTOCs() = %v, want %v", got, map[digest.Digest]ocispec.Descriptor{layer.Digest: ztoc})
+	}
+}
@@ -20,19 +20,20 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sort"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
-	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/internal/cas"
 	"oras.land/oras-go/v2/internal/graph"
 	"oras.land/oras-go/v2/internal/resolver"
+	"oras.land/oras-go/v2/tracing"
 )
 
 // Store represents a memory based store, which implements `oras.Target`.
 type Store struct {
-	storage  content.Storage
-	resolver content.TagResolver
+	storage  *cas.Memory
+	resolver *resolver.Memory
 	graph    *graph.Memory
 }
 
@@ -46,35 +47,43 @@ func New() *Store {
 }
 
 // Fetch fetches the content identified by the descriptor.
-func (s *Store) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+func (s *Store) Fetch(ctx context.Context, target ocispec.Descriptor) (_ io.ReadCloser, err error) {
+	ctx, span := tracing.Start(ctx, "memory.Store.Fetch", tracing.DescriptorAttributes(target)...)
+	defer func() { span.End(err) }()
 	return s.storage.Fetch(ctx, target)
 }
 
 // Push pushes the content, matching the expected descriptor.
-func (s *Store) Push(ctx context.Context, expected ocispec.Descriptor, reader io.Reader) error {
+func (s *Store) Push(ctx context.Context, expected ocispec.Descriptor, reader io.Reader) (err error) {
+	ctx, span := tracing.Start(ctx, "memory.Store.Push", tracing.DescriptorAttributes(expected)...)
+	defer func() { span.End(err) }()
 	if err := s.storage.Push(ctx, expected, reader); err != nil {
 		return err
 	}
 
 	// index predecessors.
-	// there is no data consistency issue as long as deletion is not implemented
-	// for the memory store.
 	return s.graph.Index(ctx, s.storage, expected)
 }
 
 // Exists returns true if the described content exists.
-func (s *Store) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+func (s *Store) Exists(ctx context.Context, target ocispec.Descriptor) (_ bool, err error) {
+	ctx, span := tracing.Start(ctx, "memory.Store.Exists", tracing.DescriptorAttributes(target)...)
+	defer func() { span.End(err) }()
 	return s.storage.Exists(ctx, target)
 }
 
 // Resolve resolves a reference to a descriptor.
-func (s *Store) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+func (s *Store) Resolve(ctx context.Context, reference string) (_ ocispec.Descriptor, err error) {
+	ctx, span := tracing.Start(ctx, "memory.Store.Resolve", tracing.String(tracing.AttributeKeyReference, reference))
+	defer func() { span.End(err) }()
 	return s.resolver.Resolve(ctx, reference)
 }
 
 // Tag tags a descriptor with a reference string.
 // Returns ErrNotFound if the tagged content does not exist.
-func (s *Store) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+func (s *Store) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) (err error) {
+	ctx, span := tracing.Start(ctx, "memory.Store.Tag", append(tracing.DescriptorAttributes(desc), tracing.String(tracing.AttributeKeyReference, reference))...)
+	defer func() { span.End(err) }()
 	exists, err := s.storage.Exists(ctx, desc)
 	if err != nil {
 		return err
@@ -85,6 +94,40 @@ func (s *Store) Tag(ctx context.Context, desc ocispec.Descriptor, reference stri
 	return s.resolver.Tag(ctx, desc, reference)
 }
 
+// Delete removes the content identified by the descriptor, and removes all the
+// references tagging it.
+// Returns ErrNotFound if the content does not exist.
+// Integrity of the graph is not checked: the predecessors of the deleted
+// content are kept.
+func (s *Store) Delete(ctx context.Context, target ocispec.Descriptor) (err error) {
+	ctx, span := tracing.Start(ctx, "memory.Store.Delete", tracing.DescriptorAttributes(target)...)
+	defer func() { span.End(err) }()
+	if err := s.storage.Delete(ctx, target); err != nil {
+		return err
+	}
+	s.graph.Remove(ctx, target)
+	s.resolver.UntagAll(target)
+	return nil
+}
+
+// Tags lists the tags presented in the store, returned in ascending order.
+// If `last` is NOT empty, the entries in the response start after the tag
+// specified by `last`. Otherwise, the response starts from the top of the tags
+// list.
+//
+// See also `Tags()` in the package `registry`.
+func (s *Store) Tags(ctx context.Context, last string, fn func(tags []string) error) error {
+	var tags []string
+	for tag := range s.resolver.Map() {
+		if last != "" && tag <= last {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return fn(tags)
+}
+
 // Predecessors returns the nodes directly pointing to the current node.
 // Predecessors returns nil without error if the node does not exists in the
 // store.
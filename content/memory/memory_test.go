@@ -33,8 +33,6 @@ import (
 	"oras.land/oras-go/v2"
 	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/errdef"
-	"oras.land/oras-go/v2/internal/cas"
-	"oras.land/oras-go/v2/internal/resolver"
 	"oras.land/oras-go/v2/internal/spec"
 )
 
@@ -77,7 +75,7 @@ func TestStoreSuccess(t *testing.T) {
 	if !reflect.DeepEqual(gotDesc, desc) {
 		t.Errorf("Store.Resolve() = %v, want %v", gotDesc, desc)
 	}
-	internalResolver := s.resolver.(*resolver.Memory)
+	internalResolver := s.resolver
 	if got := len(internalResolver.Map()); got != 1 {
 		t.Errorf("resolver.Map() = %v, want %v", got, 1)
 	}
@@ -105,7 +103,7 @@ func TestStoreSuccess(t *testing.T) {
 	if !bytes.Equal(got, content) {
 		t.Errorf("Store.Fetch() = %v, want %v", got, content)
 	}
-	internalStorage := s.storage.(*cas.Memory)
+	internalStorage := s.storage
 	if got := len(internalStorage.Map()); got != 1 {
 		t.Errorf("storage.Map() = %v, want %v", got, 1)
 	}
@@ -219,7 +217,7 @@ func TestStoreRepeatTag(t *testing.T) {
 	ctx := context.Background()
 
 	// get internal resolver
-	internalResolver := s.resolver.(*resolver.Memory)
+	internalResolver := s.resolver
 
 	// initial tag
 	content := []byte("hello world")
@@ -421,3 +419,106 @@ func equalDescriptorSet(actual []ocispec.Descriptor, expected []ocispec.Descript
 	}
 	return true
 }
+
+func TestStoreDelete(t *testing.T) {
+	s := New()
+	ctx := context.Background()
+
+	layer := []byte("hello world")
+	layerDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageLayer,
+		Digest:    digest.FromBytes(layer),
+		Size:      int64(len(layer)),
+	}
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Config: layerDesc,
+		Layers: []ocispec.Descriptor{layerDesc},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(manifestJSON),
+		Size:      int64(len(manifestJSON)),
+	}
+	if err := s.Push(ctx, layerDesc, bytes.NewReader(layer)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+	if err := s.Push(ctx, manifestDesc, bytes.NewReader(manifestJSON)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+	for _, ref := range []string{"foo", "bar"} {
+		if err := s.Tag(ctx, manifestDesc, ref); err != nil {
+			t.Fatal("Store.Tag() error =", err)
+		}
+	}
+
+	if err := s.Delete(ctx, manifestDesc); err != nil {
+		t.Fatal("Store.Delete() error =", err)
+	}
+	exists, err := s.Exists(ctx, manifestDesc)
+	if err != nil {
+		t.Fatal("Store.Exists() error =", err)
+	}
+	if exists {
+		t.Errorf("Store.Exists() = %v, want %v", exists, false)
+	}
+	for _, ref := range []string{"foo", "bar"} {
+		if _, err := s.Resolve(ctx, ref); !errors.Is(err, errdef.ErrNotFound) {
+			t.Errorf("Store.Resolve(%s) error = %v, want %v", ref, err, errdef.ErrNotFound)
+		}
+	}
+	predecessors, err := s.Predecessors(ctx, layerDesc)
+	if err != nil {
+		t.Fatal("Store.Predecessors() error =", err)
+	}
+	if len(predecessors) != 0 {
+		t.Errorf("Store.Predecessors() = %v, want none", predecessors)
+	}
+
+	if err := s.Delete(ctx, manifestDesc); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Store.Delete() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+}
+
+func TestStoreTags(t *testing.T) {
+	s := New()
+	ctx := context.Background()
+
+	content := []byte("hello world")
+	desc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(content),
+		Size:      int64(len(content)),
+	}
+	if err := s.Push(ctx, desc, bytes.NewReader(content)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+	for _, ref := range []string{"v2", "v1", "latest"} {
+		if err := s.Tag(ctx, desc, ref); err != nil {
+			t.Fatal("Store.Tag() error =", err)
+		}
+	}
+
+	tests := []struct {
+		last string
+		want []string
+	}{
+		{last: "", want: []string{"latest", "v1", "v2"}},
+		{last: "latest", want: []string{"v1", "v2"}},
+		{last: "v2", want: nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		if err := s.Tags(ctx, tt.last, func(tags []string) error {
+			got = append(got, tags...)
+			return nil
+		}); err != nil {
+			t.Fatalf("Store.Tags(%q) error = %v", tt.last, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Store.Tags(%q) = %v, want %v", tt.last, got, tt.want)
+		}
+	}
+}
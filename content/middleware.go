@@ -0,0 +1,176 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// FetcherMiddleware decorates a Fetcher with additional behavior, such as
+// verification, throttling, metrics, caching or logging.
+// The returned Fetcher is expected to call next for fetching the content.
+type FetcherMiddleware func(next Fetcher) Fetcher
+
+// ChainFetcher composes the middlewares into a single FetcherMiddleware.
+// The first middleware is the outermost one, i.e. it is the first to see
+// a Fetch call and the last to see its result.
+func ChainFetcher(middlewares ...FetcherMiddleware) FetcherMiddleware {
+	return func(next Fetcher) Fetcher {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
+
+// WithFetcherMiddleware returns a storage whose Fetch calls go through the
+// middlewares before reaching s. Other methods are served by s directly.
+func WithFetcherMiddleware(s Storage, middlewares ...FetcherMiddleware) Storage {
+	return &fetcherMiddlewareStorage{
+		Storage: s,
+		fetcher: ChainFetcher(middlewares...)(s),
+	}
+}
+
+// fetcherMiddlewareStorage is a Storage with a decorated Fetcher.
+type fetcherMiddlewareStorage struct {
+	Storage
+	fetcher Fetcher
+}
+
+// Fetch fetches the content identified by the descriptor through the
+// middlewares.
+func (s *fetcherMiddlewareStorage) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	return s.fetcher.Fetch(ctx, target)
+}
+
+// VerifyFetcher is a FetcherMiddleware verifying the fetched content against
+// the size and the digest of the descriptor.
+// A read of the fetched content returns an error instead of io.EOF if the
+// verification fails.
+func VerifyFetcher(next Fetcher) Fetcher {
+	return FetcherFunc(func(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+		rc, err := next.Fetch(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		return &verifyReadCloser{
+			vr:     NewVerifyReader(rc, target),
+			Closer: rc,
+		}, nil
+	})
+}
+
+// verifyReadCloser verifies the content on reaching EOF.
+type verifyReadCloser struct {
+	vr *VerifyReader
+	io.Closer
+}
+
+// Read reads the content and verifies it on reaching EOF.
+func (r *verifyReadCloser) Read(p []byte) (int, error) {
+	n, err := r.vr.Read(p)
+	if err == io.EOF {
+		if verr := r.vr.Verify(); verr != nil {
+			return n, verr
+		}
+	}
+	return n, err
+}
+
+// PusherFunc is the basic Push method defined in Pusher.
+type PusherFunc func(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error
+
+// Push performs Push operation by the PusherFunc.
+func (fn PusherFunc) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	return fn(ctx, expected, content)
+}
+
+// PusherMiddleware decorates a Pusher with additional behavior, such as size
+// limits, verification or progress tracking.
+// The returned Pusher is expected to call next for pushing the content.
+type PusherMiddleware func(next Pusher) Pusher
+
+// ChainPusher composes the middlewares into a single PusherMiddleware.
+// The first middleware is the outermost one, i.e. it is the first to see
+// a Push call and the last to see its result.
+func ChainPusher(middlewares ...PusherMiddleware) PusherMiddleware {
+	return func(next Pusher) Pusher {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
+
+// WithPusherMiddleware returns a storage whose Push calls go through the
+// middlewares before reaching s. Other methods are served by s directly.
+func WithPusherMiddleware(s Storage, middlewares ...PusherMiddleware) Storage {
+	return &pusherMiddlewareStorage{
+		Storage: s,
+		pusher:  ChainPusher(middlewares...)(s),
+	}
+}
+
+// pusherMiddlewareStorage is a Storage with a decorated Pusher.
+type pusherMiddlewareStorage struct {
+	Storage
+	pusher Pusher
+}
+
+// Push pushes the content through the middlewares.
+func (s *pusherMiddlewareStorage) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	return s.pusher.Push(ctx, expected, content)
+}
+
+// LimitPusher returns a PusherMiddleware rejecting contents larger than n
+// bytes with errdef.ErrSizeExceedsLimit before any content is transferred.
+func LimitPusher(n int64) PusherMiddleware {
+	return func(next Pusher) Pusher {
+		return PusherFunc(func(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+			if expected.Size > n {
+				return fmt.Errorf("%s: %s: content size %v exceeds push size limit %v: %w",
+					expected.Digest, expected.MediaType, expected.Size, n, errdef.ErrSizeExceedsLimit)
+			}
+			return next.Push(ctx, expected, content)
+		})
+	}
+}
+
+// VerifyPusher is a PusherMiddleware verifying the pushed content against
+// the size and the digest of the expected descriptor.
+// The underlying pusher sees a read error instead of io.EOF if the
+// verification fails, and Push returns the verification error if the
+// underlying pusher does not read the content to the end.
+func VerifyPusher(next Pusher) Pusher {
+	return PusherFunc(func(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+		vr := &verifyReadCloser{
+			vr: NewVerifyReader(content, expected),
+		}
+		if err := next.Push(ctx, expected, vr); err != nil {
+			return err
+		}
+		if err := vr.vr.Verify(); err != nil {
+			return fmt.Errorf("%s: %s: %w", expected.Digest, expected.MediaType, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,191 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// recordFetcher returns a middleware appending name to calls on Fetch.
+func recordFetcher(calls *[]string, name string) content.FetcherMiddleware {
+	return func(next content.Fetcher) content.Fetcher {
+		return content.FetcherFunc(func(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+			*calls = append(*calls, name)
+			return next.Fetch(ctx, target)
+		})
+	}
+}
+
+func TestWithFetcherMiddleware(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+	if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+
+	var calls []string
+	storage := content.WithFetcherMiddleware(s, recordFetcher(&calls, "a"), recordFetcher(&calls, "b"), content.VerifyFetcher)
+	got, err := content.FetchAll(ctx, storage, desc)
+	if err != nil {
+		t.Fatal("FetchAll() error =", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("FetchAll() = %s, want %s", got, blob)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("middleware calls = %v, want %v", calls, want)
+	}
+
+	exists, err := storage.Exists(ctx, desc)
+	if err != nil {
+		t.Fatal("Exists() error =", err)
+	}
+	if !exists {
+		t.Errorf("Exists() = %v, want %v", exists, true)
+	}
+}
+
+func TestVerifyFetcher(t *testing.T) {
+	ctx := context.Background()
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+
+	tests := []struct {
+		name    string
+		content []byte
+		wantErr error
+	}{
+		{
+			name:    "valid content",
+			content: blob,
+		},
+		{
+			name:    "mismatched digest",
+			content: []byte("hello wOrld"),
+			wantErr: content.ErrMismatchedDigest,
+		},
+		{
+			name:    "trailing data",
+			content: []byte("hello world!"),
+			wantErr: content.ErrTrailingData,
+		},
+		{
+			name:    "short content",
+			content: []byte("hello"),
+			wantErr: io.ErrUnexpectedEOF,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := content.VerifyFetcher(content.FetcherFunc(func(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(tt.content)), nil
+			}))
+			rc, err := fetcher.Fetch(ctx, desc)
+			if err != nil {
+				t.Fatal("Fetch() error =", err)
+			}
+			defer rc.Close()
+			_, err = io.ReadAll(rc)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ReadAll() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// recordPusher returns a middleware appending name to calls on Push.
+func recordPusher(calls *[]string, name string) content.PusherMiddleware {
+	return func(next content.Pusher) content.Pusher {
+		return content.PusherFunc(func(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+			*calls = append(*calls, name)
+			return next.Push(ctx, expected, r)
+		})
+	}
+}
+
+func TestWithPusherMiddleware(t *testing.T) {
+	ctx := context.Background()
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+
+	var calls []string
+	s := memory.New()
+	storage := content.WithPusherMiddleware(s, recordPusher(&calls, "a"), content.LimitPusher(desc.Size), recordPusher(&calls, "b"), content.VerifyPusher)
+	if err := storage.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("middleware calls = %v, want %v", calls, want)
+	}
+	exists, err := s.Exists(ctx, desc)
+	if err != nil {
+		t.Fatal("Exists() error =", err)
+	}
+	if !exists {
+		t.Errorf("Exists() = %v, want %v", exists, true)
+	}
+
+	// size limit is enforced before reaching inner middlewares
+	calls = nil
+	large := []byte("hello world!")
+	largeDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, large)
+	if err := storage.Push(ctx, largeDesc, bytes.NewReader(large)); !errors.Is(err, errdef.ErrSizeExceedsLimit) {
+		t.Errorf("Push() error = %v, want %v", err, errdef.ErrSizeExceedsLimit)
+	}
+	if want := []string{"a"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("middleware calls = %v, want %v", calls, want)
+	}
+}
+
+func TestVerifyPusher(t *testing.T) {
+	ctx := context.Background()
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+
+	// the underlying pusher reading the whole content sees the error
+	pusher := content.VerifyPusher(content.PusherFunc(func(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+		_, err := io.ReadAll(r)
+		return err
+	}))
+	if err := pusher.Push(ctx, desc, bytes.NewReader([]byte("hello wOrld"))); !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("Push() error = %v, want %v", err, content.ErrMismatchedDigest)
+	}
+
+	// the content is verified even if the underlying pusher stops reading
+	// at the expected size
+	pusher = content.VerifyPusher(content.PusherFunc(func(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+		_, err := io.CopyN(io.Discard, r, expected.Size)
+		return err
+	}))
+	if err := pusher.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Errorf("Push() error = %v", err)
+	}
+	if err := pusher.Push(ctx, desc, bytes.NewReader([]byte("hello world!"))); !errors.Is(err, content.ErrTrailingData) {
+		t.Errorf("Push() error = %v, want %v", err, content.ErrTrailingData)
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package objectstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"oras.land/oras-go/v2/errdef"
+)
+
+// azureVersion is the version of the Azure Storage REST API.
+const azureVersion = "2021-08-06"
+
+// AzureDriver is a Driver storing objects as block blobs in an Azure Blob
+// Storage container using the REST API.
+// Reference: https://learn.microsoft.com/rest/api/storageservices/blob-service-rest-api
+type AzureDriver struct {
+	// Client is the HTTP client used to access the container.
+	// If the container URL does not carry a SAS token, the client is
+	// expected to authorize the requests.
+	// If nil, http.DefaultClient is used.
+	Client *http.Client
+
+	// container is the container URL without the query.
+	container *url.URL
+	// sas is the shared access signature query of the container URL.
+	sas string
+}
+
+// NewAzureDriver creates an AzureDriver storing objects in the container at
+// containerURL, such as "https://account.blob.core.windows.net/container".
+// containerURL may carry a shared access signature (SAS) token as its query,
+// which is then appended to every request.
+func NewAzureDriver(containerURL string) (*AzureDriver, error) {
+	u, err := url.Parse(containerURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid container URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid container URL: unsupported scheme %q", u.Scheme)
+	}
+	if u.Host == "" || strings.Trim(u.Path, "/") == "" {
+		return nil, fmt.Errorf("invalid container URL: missing host or container")
+	}
+	sas := u.RawQuery
+	u.RawQuery = ""
+	u.Fragment = ""
+	return &AzureDriver{
+		container: u,
+		sas:       sas,
+	}, nil
+}
+
+// Put uploads the object as a block blob in a single request.
+func (d *AzureDriver) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	req, err := d.newRequest(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := d.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return httpError(resp)
+	}
+	return nil
+}
+
+// Get downloads the blob.
+func (d *AzureDriver) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := d.do(ctx, http.MethodGet, key)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %w", key, errdef.ErrNotFound)
+	default:
+		defer resp.Body.Close()
+		return nil, httpError(resp)
+	}
+}
+
+// Stat returns the size of the blob from its properties.
+func (d *AzureDriver) Stat(ctx context.Context, key string) (int64, error) {
+	resp, err := d.do(ctx, http.MethodHead, key)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if resp.ContentLength < 0 {
+			return 0, fmt.Errorf("%s: missing Content-Length", key)
+		}
+		return resp.ContentLength, nil
+	case http.StatusNotFound:
+		return 0, fmt.Errorf("%s: %w", key, errdef.ErrNotFound)
+	default:
+		return 0, httpError(resp)
+	}
+}
+
+// Delete deletes the blob.
+func (d *AzureDriver) Delete(ctx context.Context, key string) error {
+	resp, err := d.do(ctx, http.MethodDelete, key)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusAccepted, http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("%s: %w", key, errdef.ErrNotFound)
+	default:
+		return httpError(resp)
+	}
+}
+
+// do sends a request without body on the blob.
+func (d *AzureDriver) do(ctx context.Context, method, key string) (*http.Response, error) {
+	req, err := d.newRequest(ctx, method, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	return d.client().Do(req)
+}
+
+// newRequest creates a request on the blob.
+func (d *AzureDriver) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	u := *d.container
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + key
+	u.RawPath = ""
+	u.RawQuery = d.sas
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-ms-version", azureVersion)
+	return req, nil
+}
+
+// client returns the HTTP client.
+func (d *AzureDriver) client() *http.Client {
+	if d.Client == nil {
+		return http.DefaultClient
+	}
+	return d.Client
+}
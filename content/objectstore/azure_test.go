@@ -0,0 +1,132 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package objectstore
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeAzure is a minimal Azure Blob Storage server serving a single
+// container.
+type fakeAzure struct {
+	container string
+	lock      sync.Mutex
+	blobs     map[string][]byte
+}
+
+func (f *fakeAzure) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("sig") != "secret" || r.Header.Get("x-ms-version") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, "AuthenticationFailed")
+		return
+	}
+	prefix := "/" + f.container + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, prefix)
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if r.Method == http.MethodPut {
+		if r.Header.Get("x-ms-blob-type") != "BlockBlob" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.blobs[name] = data
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	data, ok := f.blobs[name]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		w.Write(data)
+	case http.MethodHead:
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	case http.MethodDelete:
+		delete(f.blobs, name)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestStore_Azure(t *testing.T) {
+	ts := httptest.NewServer(&fakeAzure{
+		container: "container",
+		blobs:     make(map[string][]byte),
+	})
+	defer ts.Close()
+
+	d, err := NewAzureDriver(ts.URL + "/container?sv=2021-08-06&sig=secret")
+	if err != nil {
+		t.Fatal("NewAzureDriver() error =", err)
+	}
+	testStore(t, d)
+}
+
+func TestAzureDriver_Error(t *testing.T) {
+	ts := httptest.NewServer(&fakeAzure{
+		container: "container",
+		blobs:     make(map[string][]byte),
+	})
+	defer ts.Close()
+
+	d, err := NewAzureDriver(ts.URL + "/container?sig=wrong")
+	if err != nil {
+		t.Fatal("NewAzureDriver() error =", err)
+	}
+	_, err = d.Get(context.Background(), "foo")
+	if err == nil {
+		t.Fatal("AzureDriver.Get() error = nil, wantErr true")
+	}
+	if strings.Contains(err.Error(), "wrong") {
+		t.Errorf("AzureDriver.Get() error = %v, leaking the SAS token", err)
+	}
+	if !strings.Contains(err.Error(), "AuthenticationFailed") {
+		t.Errorf("AzureDriver.Get() error = %v, want AuthenticationFailed", err)
+	}
+}
+
+func TestNewAzureDriver(t *testing.T) {
+	for _, u := range []string{
+		"ftp://account.blob.core.windows.net/container",
+		"https:///container",
+		"https://account.blob.core.windows.net",
+		"https://account.blob.core.windows.net/",
+	} {
+		if _, err := NewAzureDriver(u); err == nil {
+			t.Errorf("NewAzureDriver(%q) error = nil, wantErr true", u)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"oras.land/oras-go/v2/errdef"
+)
+
+// FileSystemDriver is a Driver storing objects as files under a root
+// directory.
+type FileSystemDriver struct {
+	root string
+}
+
+// NewFileSystemDriver creates a FileSystemDriver storing objects under root.
+// The root directory is created if not exists.
+func NewFileSystemDriver(root string) (*FileSystemDriver, error) {
+	rootAbs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve absolute path for %s: %w", root, err)
+	}
+	if err := os.MkdirAll(rootAbs, 0777); err != nil {
+		return nil, err
+	}
+	return &FileSystemDriver{
+		root: rootAbs,
+	}, nil
+}
+
+// Put stores the object read from r as a file. The content is written to a
+// temporary file first, and then renamed to the object path on success.
+func (d *FileSystemDriver) Put(_ context.Context, key string, r io.Reader, size int64) (err error) {
+	target, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(target)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+	fp, err := os.CreateTemp(dir, filepath.Base(target)+"_*")
+	if err != nil {
+		return err
+	}
+	tempPath := fp.Name()
+	defer func() {
+		if err != nil {
+			os.Remove(tempPath)
+		}
+	}()
+
+	n, err := io.Copy(fp, r)
+	if closeErr := fp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if n != size {
+		return fmt.Errorf("%s: written %d bytes, want %d", key, n, size)
+	}
+	return os.Rename(tempPath, target)
+}
+
+// Get returns a reader of the object file.
+func (d *FileSystemDriver) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	target, err := d.path(key)
+	if err != nil {
+		return nil, err
+	}
+	fp, err := os.Open(target)
+	if err != nil {
+		return nil, notFound(key, err)
+	}
+	return fp, nil
+}
+
+// Stat returns the size of the object file.
+func (d *FileSystemDriver) Stat(_ context.Context, key string) (int64, error) {
+	target, err := d.path(key)
+	if err != nil {
+		return 0, err
+	}
+	fi, err := os.Stat(target)
+	if err != nil {
+		return 0, notFound(key, err)
+	}
+	if !fi.Mode().IsRegular() {
+		return 0, fmt.Errorf("%s: not a regular file: %w", key, errdef.ErrNotFound)
+	}
+	return fi.Size(), nil
+}
+
+// Delete removes the object file.
+func (d *FileSystemDriver) Delete(_ context.Context, key string) error {
+	target, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	return notFound(key, os.Remove(target))
+}
+
+// path returns the file path of the object, ensuring it is under the root.
+func (d *FileSystemDriver) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" || strings.TrimPrefix(cleaned, "/") != key {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+	return filepath.Join(d.root, filepath.FromSlash(key)), nil
+}
+
+// notFound wraps the not exist error with ErrNotFound.
+func notFound(key string, err error) error {
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%s: %w", key, errdef.ErrNotFound)
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestFileSystemDriver(t *testing.T) {
+	root := t.TempDir()
+	d, err := NewFileSystemDriver(root)
+	if err != nil {
+		t.Fatal("NewFileSystemDriver() error =", err)
+	}
+	ctx := context.Background()
+
+	if err := d.Put(ctx, "a/b", strings.NewReader("foo"), 3); err != nil {
+		t.Fatal("FileSystemDriver.Put() error =", err)
+	}
+	if got, err := os.ReadFile(filepath.Join(root, "a", "b")); err != nil || string(got) != "foo" {
+		t.Errorf("file content = %s, %v, want foo", got, err)
+	}
+
+	// test size mismatch
+	if err := d.Put(ctx, "a/c", strings.NewReader("foo"), 4); err == nil {
+		t.Error("FileSystemDriver.Put() error = nil, wantErr true")
+	}
+	if _, err := d.Stat(ctx, "a/c"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("FileSystemDriver.Stat() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+	entries, err := os.ReadDir(filepath.Join(root, "a"))
+	if err != nil {
+		t.Fatal("os.ReadDir() error =", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("temporary files left: %v", entries)
+	}
+
+	// test directories are not objects
+	if _, err := d.Stat(ctx, "a"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("FileSystemDriver.Stat() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+
+	// test invalid keys
+	for _, key := range []string{"", "/a/b", "../a", "a/../b", "a//b", "a/"} {
+		if _, err := d.Stat(ctx, key); err == nil || errors.Is(err, errdef.ErrNotFound) {
+			t.Errorf("FileSystemDriver.Stat(%q) error = %v, want invalid key", key, err)
+		}
+	}
+}
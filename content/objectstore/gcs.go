@@ -0,0 +1,173 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package objectstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"oras.land/oras-go/v2/errdef"
+)
+
+// DefaultGCSEndpoint is the default endpoint of the Google Cloud Storage
+// JSON API.
+const DefaultGCSEndpoint = "https://storage.googleapis.com"
+
+// GCSDriver is a Driver storing objects in a Google Cloud Storage bucket
+// using the JSON API.
+// Reference: https://cloud.google.com/storage/docs/json_api
+type GCSDriver struct {
+	// Client is the HTTP client used to access the bucket, which is
+	// expected to authorize the requests, e.g. an OAuth 2.0 client.
+	// If nil, http.DefaultClient is used.
+	Client *http.Client
+	// Endpoint is the endpoint of the JSON API.
+	// If empty, DefaultGCSEndpoint is used.
+	Endpoint string
+
+	// bucket is the bucket name.
+	bucket string
+}
+
+// NewGCSDriver creates a GCSDriver storing objects in the bucket.
+func NewGCSDriver(bucket string) *GCSDriver {
+	return &GCSDriver{
+		bucket: bucket,
+	}
+}
+
+// Put uploads the object in a single request.
+func (d *GCSDriver) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	query := url.Values{
+		"uploadType": {"media"},
+		"name":       {key},
+	}
+	u := d.endpoint() + "/upload/storage/v1/b/" + url.PathEscape(d.bucket) + "/o?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := d.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return httpError(resp)
+	}
+	return nil
+}
+
+// Get downloads the object.
+func (d *GCSDriver) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := d.do(ctx, http.MethodGet, key, "alt=media")
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %w", key, errdef.ErrNotFound)
+	default:
+		defer resp.Body.Close()
+		return nil, httpError(resp)
+	}
+}
+
+// Stat returns the size of the object from its metadata.
+func (d *GCSDriver) Stat(ctx context.Context, key string) (int64, error) {
+	resp, err := d.do(ctx, http.MethodGet, key, "")
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return 0, fmt.Errorf("%s: %w", key, errdef.ErrNotFound)
+	default:
+		return 0, httpError(resp)
+	}
+
+	const maxMetadataBytes = 64 * 1024
+	var metadata struct {
+		// Size is a uint64 formatted as a string.
+		Size string `json:"size"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxMetadataBytes)).Decode(&metadata); err != nil {
+		return 0, fmt.Errorf("%s: failed to decode metadata: %w", key, err)
+	}
+	size, err := strconv.ParseInt(metadata.Size, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid size %q: %w", key, metadata.Size, err)
+	}
+	return size, nil
+}
+
+// Delete deletes the object.
+func (d *GCSDriver) Delete(ctx context.Context, key string) error {
+	resp, err := d.do(ctx, http.MethodDelete, key, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("%s: %w", key, errdef.ErrNotFound)
+	default:
+		return httpError(resp)
+	}
+}
+
+// do sends a request without body on the object.
+func (d *GCSDriver) do(ctx context.Context, method, key, rawQuery string) (*http.Response, error) {
+	u := d.endpoint() + "/storage/v1/b/" + url.PathEscape(d.bucket) + "/o/" + url.PathEscape(key)
+	if rawQuery != "" {
+		u += "?" + rawQuery
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return d.client().Do(req)
+}
+
+// endpoint returns the endpoint of the JSON API.
+func (d *GCSDriver) endpoint() string {
+	if d.Endpoint == "" {
+		return DefaultGCSEndpoint
+	}
+	return d.Endpoint
+}
+
+// client returns the HTTP client.
+func (d *GCSDriver) client() *http.Client {
+	if d.Client == nil {
+		return http.DefaultClient
+	}
+	return d.Client
+}
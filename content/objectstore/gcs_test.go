@@ -0,0 +1,109 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package objectstore
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeGCS is a minimal Google Cloud Storage JSON API server serving a single
+// bucket.
+type fakeGCS struct {
+	bucket  string
+	lock    sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeGCS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	uploadPath := "/upload/storage/v1/b/" + f.bucket + "/o"
+	objectPrefix := "/storage/v1/b/" + f.bucket + "/o/"
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == uploadPath:
+		if r.URL.Query().Get("uploadType") != "media" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.objects[r.URL.Query().Get("name")] = data
+		fmt.Fprint(w, "{}")
+	case strings.HasPrefix(r.URL.Path, objectPrefix):
+		// object names are escaped as a single path segment
+		if strings.Contains(strings.TrimPrefix(r.URL.EscapedPath(), objectPrefix), "/") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		name := strings.TrimPrefix(r.URL.Path, objectPrefix)
+		data, ok := f.objects[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Query().Get("alt") == "media":
+			w.Write(data)
+		case r.Method == http.MethodGet:
+			fmt.Fprintf(w, `{"name":%q,"size":"%d"}`, name, len(data))
+		case r.Method == http.MethodDelete:
+			delete(f.objects, name)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// authTransport adds the authorization header to the requests.
+type authTransport struct {
+	authorization string
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", t.authorization)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestStore_GCS(t *testing.T) {
+	ts := httptest.NewServer(&fakeGCS{
+		bucket:  "bucket",
+		objects: make(map[string][]byte),
+	})
+	defer ts.Close()
+
+	d := NewGCSDriver("bucket")
+	d.Endpoint = ts.URL
+	d.Client = &http.Client{Transport: &authTransport{authorization: "Bearer token"}}
+	testStore(t, d)
+}
@@ -0,0 +1,194 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package objectstore provides a content store on top of a generic object
+// store driver, so that new storage backends can be added by implementing a
+// small key-value interface.
+//
+// Blobs are stored as objects keyed by digest, in the same layout as the
+// blobs of an OCI image layout: <prefix>/blobs/<alg>/<encoded>.
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// Driver stores objects identified by slash-separated keys.
+//
+// Implementations return errors wrapping errdef.ErrNotFound for missing
+// objects.
+type Driver interface {
+	// Put stores the object read from r with the given size.
+	// If reading r fails, the object should not be created. Otherwise,
+	// Store deletes the object.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	// Get returns a reader of the object.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Stat returns the size of the object.
+	Stat(ctx context.Context, key string) (int64, error)
+	// Delete removes the object.
+	Delete(ctx context.Context, key string) error
+}
+
+// Store is a content-addressable storage backed by a Driver.
+type Store struct {
+	// Prefix is the key prefix of the stored blobs.
+	Prefix string
+
+	// driver is the underlying object store driver.
+	driver Driver
+}
+
+// New creates a Store backed by the driver.
+func New(driver Driver) *Store {
+	return &Store{
+		driver: driver,
+	}
+}
+
+// Fetch fetches the content identified by the descriptor.
+func (s *Store) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	key, err := s.blobKey(target)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := s.driver.Get(ctx, key)
+	if err != nil {
+		return nil, s.wrapError(target, err)
+	}
+	return rc, nil
+}
+
+// Push pushes the content, matching the expected descriptor.
+// The content is verified while being stored, and the object is deleted if
+// the verification fails.
+func (s *Store) Push(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	key, err := s.blobKey(expected)
+	if err != nil {
+		return err
+	}
+	exists, err := s.Exists(ctx, expected)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("%s: %s: %w", expected.Digest, expected.MediaType, errdef.ErrAlreadyExists)
+	}
+	vr := &verifyReader{
+		vr: content.NewVerifyReader(r, expected),
+	}
+	putErr := s.driver.Put(ctx, key, vr, expected.Size)
+	if putErr == nil && vr.err == nil {
+		// ensure the content is fully read and verified in case the driver
+		// does not read to EOF
+		vr.err = vr.vr.Verify()
+	}
+	if vr.err != nil {
+		// the driver may have stored the object before reaching the
+		// failure, e.g. when the content is fully sent before EOF is read
+		s.driver.Delete(ctx, key)
+		return fmt.Errorf("%s: %s: %w", expected.Digest, expected.MediaType, vr.err)
+	}
+	return putErr
+}
+
+// Exists returns true if the described content exists.
+func (s *Store) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	key, err := s.blobKey(target)
+	if err != nil {
+		return false, err
+	}
+	if _, err := s.driver.Stat(ctx, key); err != nil {
+		if errors.Is(err, errdef.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Delete removes the content identified by the descriptor.
+// Returns ErrNotFound if the content does not exist.
+func (s *Store) Delete(ctx context.Context, target ocispec.Descriptor) error {
+	key, err := s.blobKey(target)
+	if err != nil {
+		return err
+	}
+	if err := s.driver.Delete(ctx, key); err != nil {
+		return s.wrapError(target, err)
+	}
+	return nil
+}
+
+// blobKey returns the object key of the blob.
+func (s *Store) blobKey(desc ocispec.Descriptor) (string, error) {
+	if err := content.ValidateDigest(desc.Digest); err != nil {
+		return "", fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, errdef.ErrInvalidDigest)
+	}
+	key := path.Join(s.Prefix, "blobs", desc.Digest.Algorithm().String(), desc.Digest.Encoded())
+	return strings.TrimPrefix(key, "/"), nil
+}
+
+// wrapError annotates ErrNotFound with the descriptor.
+func (s *Store) wrapError(desc ocispec.Descriptor, err error) error {
+	if errors.Is(err, errdef.ErrNotFound) {
+		return fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, errdef.ErrNotFound)
+	}
+	return err
+}
+
+// verifyReader verifies the content on reaching EOF, and records the
+// verification failure.
+type verifyReader struct {
+	vr  *content.VerifyReader
+	err error
+}
+
+// Read reads from the underlying reader, and verifies the content on EOF.
+func (r *verifyReader) Read(p []byte) (int, error) {
+	n, err := r.vr.Read(p)
+	if err == io.EOF {
+		if verr := r.vr.Verify(); verr != nil {
+			r.err = verr
+			return n, verr
+		}
+	} else if err != nil {
+		r.err = err
+	}
+	return n, err
+}
+
+// httpError returns an error for the unexpected response.
+func httpError(resp *http.Response) error {
+	const maxErrorBytes = 4 * 1024
+	// strip the query, which may carry credentials such as SAS tokens
+	u := *resp.Request.URL
+	u.RawQuery = ""
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBytes))
+	if message := strings.TrimSpace(string(data)); message != "" {
+		return fmt.Errorf("%s %q: response status code %d: %s", resp.Request.Method, u.Redacted(), resp.StatusCode, message)
+	}
+	return fmt.Errorf("%s %q: response status code %d: %s", resp.Request.Method, u.Redacted(), resp.StatusCode, http.StatusText(resp.StatusCode))
+}
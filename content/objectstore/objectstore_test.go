@@ -0,0 +1,96 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// testStore runs the common tests of a Store backed by the driver.
+func testStore(t *testing.T, driver Driver) {
+	s := New(driver)
+	s.Prefix = "layouts/foo"
+	ctx := context.Background()
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+
+	if exists, err := s.Exists(ctx, desc); err != nil || exists {
+		t.Fatalf("Store.Exists() = %v, %v, want false", exists, err)
+	}
+	if _, err := s.Fetch(ctx, desc); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Store.Fetch() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+	if err := s.Delete(ctx, desc); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Store.Delete() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+
+	if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+	size, err := driver.Stat(ctx, "layouts/foo/blobs/sha256/"+desc.Digest.Encoded())
+	if err != nil || size != desc.Size {
+		t.Errorf("Driver.Stat() = %v, %v, want %v", size, err, desc.Size)
+	}
+	if err := s.Push(ctx, desc, bytes.NewReader(blob)); !errors.Is(err, errdef.ErrAlreadyExists) {
+		t.Errorf("Store.Push() error = %v, wantErr %v", err, errdef.ErrAlreadyExists)
+	}
+	if exists, err := s.Exists(ctx, desc); err != nil || !exists {
+		t.Errorf("Store.Exists() = %v, %v, want true", exists, err)
+	}
+	got, err := content.FetchAll(ctx, s, desc)
+	if err != nil {
+		t.Fatal("Store.Fetch() error =", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("Store.Fetch() = %s, want %s", got, blob)
+	}
+	if err := s.Delete(ctx, desc); err != nil {
+		t.Fatal("Store.Delete() error =", err)
+	}
+	if exists, err := s.Exists(ctx, desc); err != nil || exists {
+		t.Errorf("Store.Exists() = %v, %v, want false", exists, err)
+	}
+
+	// test mismatched content
+	if err := s.Push(ctx, desc, strings.NewReader("hello WORLD")); !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("Store.Push() error = %v, wantErr %v", err, content.ErrMismatchedDigest)
+	}
+	if exists, err := s.Exists(ctx, desc); err != nil || exists {
+		t.Errorf("Store.Exists() = %v, %v, want false", exists, err)
+	}
+
+	// test invalid digest
+	invalid := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayer, Digest: "invalid", Size: 1}
+	if _, err := s.Fetch(ctx, invalid); !errors.Is(err, errdef.ErrInvalidDigest) {
+		t.Errorf("Store.Fetch() error = %v, wantErr %v", err, errdef.ErrInvalidDigest)
+	}
+}
+
+func TestStore_FileSystem(t *testing.T) {
+	driver, err := NewFileSystemDriver(t.TempDir())
+	if err != nil {
+		t.Fatal("NewFileSystemDriver() error =", err)
+	}
+	testStore(t, driver)
+}
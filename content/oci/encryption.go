@@ -0,0 +1,366 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// KeyWrapper wraps and unwraps the data keys encrypting the blob files at
+// rest. It can be backed by a key management service (KMS) so that the key
+// encryption key never leaves the service.
+type KeyWrapper interface {
+	// WrapKey encrypts a data key.
+	WrapKey(ctx context.Context, key []byte) ([]byte, error)
+	// UnwrapKey decrypts a data key encrypted by WrapKey.
+	UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// aesKeyWrapper wraps data keys with AES-GCM.
+type aesKeyWrapper struct {
+	aead cipher.AEAD
+}
+
+// NewAESKeyWrapper returns a KeyWrapper wrapping data keys with AES-GCM using
+// the key encryption key kek, which must be 16, 24, or 32 bytes long.
+func NewAESKeyWrapper(kek []byte) (KeyWrapper, error) {
+	aead, err := newAEAD(kek)
+	if err != nil {
+		return nil, err
+	}
+	return &aesKeyWrapper{aead: aead}, nil
+}
+
+// WrapKey encrypts a data key with a random nonce prepended.
+func (w *aesKeyWrapper) WrapKey(_ context.Context, key []byte) ([]byte, error) {
+	nonce := make([]byte, w.aead.NonceSize(), w.aead.NonceSize()+len(key)+w.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return w.aead.Seal(nonce, nonce, key, nil), nil
+}
+
+// UnwrapKey decrypts a data key encrypted by WrapKey.
+func (w *aesKeyWrapper) UnwrapKey(_ context.Context, wrapped []byte) ([]byte, error) {
+	nonceSize := w.aead.NonceSize()
+	if len(wrapped) < nonceSize {
+		return nil, errors.New("wrapped key too short")
+	}
+	return w.aead.Open(nil, wrapped[:nonceSize], wrapped[nonceSize:], nil)
+}
+
+const (
+	// encryptedBlobMagic identifies the encrypted blob files.
+	encryptedBlobMagic = "ORASENC1"
+	// dataKeySize is the size of the AES-256 data keys.
+	dataKeySize = 32
+	// segmentSize is the size of the plaintext segments sealed individually,
+	// so that blobs can be encrypted and decrypted in a streaming manner.
+	segmentSize = 64 * 1024
+)
+
+// errMalformedEncryptedBlob is returned when an encrypted blob file cannot be
+// decrypted.
+var errMalformedEncryptedBlob = errors.New("malformed encrypted blob")
+
+// The encrypted blob file consists of a header and the sealed segments.
+// The header is
+//
+//	magic (8 bytes) | plaintext size (8 bytes) | wrapped key length (2 bytes) | wrapped key
+//
+// Each data key is random and used for a single blob. The plaintext is split
+// into segments of segmentSize, where the last segment may be shorter or
+// empty. The segments are sealed with AES-GCM in the STREAM construction: the
+// nonce of a segment is its big-endian index, with the last byte set to 1 for
+// the last segment, so that reordering or truncating segments is detected.
+// The additional data of every segment is the blob digest followed by the
+// header, which binds the file to the blob.
+
+// newAEAD creates an AES-GCM AEAD.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// segmentNonce returns the nonce of the segment at index.
+func segmentNonce(aead cipher.AEAD, index uint64, last bool) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-9:], index)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
+
+// encryptedBlobHeader is the header of an encrypted blob file.
+type encryptedBlobHeader struct {
+	size       int64
+	wrappedKey []byte
+}
+
+// marshal encodes the header.
+func (h encryptedBlobHeader) marshal() []byte {
+	buf := make([]byte, 0, len(encryptedBlobMagic)+10+len(h.wrappedKey))
+	buf = append(buf, encryptedBlobMagic...)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(h.size))
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(h.wrappedKey)))
+	return append(buf, h.wrappedKey...)
+}
+
+// readEncryptedBlobHeader reads the header of an encrypted blob file.
+func readEncryptedBlobHeader(r io.Reader) (encryptedBlobHeader, error) {
+	var fixed [len(encryptedBlobMagic) + 10]byte
+	if _, err := io.ReadFull(r, fixed[:]); err != nil {
+		return encryptedBlobHeader{}, fmt.Errorf("%w: %v", errMalformedEncryptedBlob, err)
+	}
+	if string(fixed[:len(encryptedBlobMagic)]) != encryptedBlobMagic {
+		return encryptedBlobHeader{}, fmt.Errorf("%w: bad magic", errMalformedEncryptedBlob)
+	}
+	size := binary.BigEndian.Uint64(fixed[len(encryptedBlobMagic):])
+	wrappedKey := make([]byte, binary.BigEndian.Uint16(fixed[len(encryptedBlobMagic)+8:]))
+	if _, err := io.ReadFull(r, wrappedKey); err != nil {
+		return encryptedBlobHeader{}, fmt.Errorf("%w: %v", errMalformedEncryptedBlob, err)
+	}
+	return encryptedBlobHeader{
+		size:       int64(size),
+		wrappedKey: wrappedKey,
+	}, nil
+}
+
+// newEncryptingWriter writes the header of the blob described by desc into
+// w, and returns a writer encrypting the blob content into w.
+// The returned writer must be closed to seal the last segment.
+func newEncryptingWriter(ctx context.Context, w io.Writer, wrapper KeyWrapper, desc ocispec.Descriptor) (io.WriteCloser, error) {
+	key := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	wrappedKey, err := wrapper.WrapKey(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap key: %w", err)
+	}
+	if len(wrappedKey) > 0xffff {
+		return nil, errors.New("wrapped key too long")
+	}
+	header := encryptedBlobHeader{
+		size:       desc.Size,
+		wrappedKey: wrappedKey,
+	}.marshal()
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &encryptingWriter{
+		w:    w,
+		aead: aead,
+		aad:  append([]byte(desc.Digest), header...),
+		buf:  make([]byte, 0, segmentSize),
+	}, nil
+}
+
+// encryptingWriter seals the written content segment by segment.
+type encryptingWriter struct {
+	w     io.Writer
+	aead  cipher.AEAD
+	aad   []byte
+	index uint64
+	buf   []byte
+}
+
+// Write buffers p, and seals the full segments except the last one.
+func (ew *encryptingWriter) Write(p []byte) (int, error) {
+	var written int
+	for len(p) > 0 {
+		if len(ew.buf) == segmentSize {
+			// more content to come, so the buffered segment is not the last
+			if err := ew.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(ew.buf[len(ew.buf):segmentSize], p)
+		ew.buf = ew.buf[:len(ew.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close seals the last segment.
+func (ew *encryptingWriter) Close() error {
+	return ew.seal(true)
+}
+
+// seal seals the buffered segment and writes it out.
+func (ew *encryptingWriter) seal(last bool) error {
+	sealed := ew.aead.Seal(nil, segmentNonce(ew.aead, ew.index, last), ew.buf, ew.aad)
+	if _, err := ew.w.Write(sealed); err != nil {
+		return err
+	}
+	ew.index++
+	ew.buf = ew.buf[:0]
+	return nil
+}
+
+// newDecryptingReader reads the header of the encrypted blob described by
+// desc from rc, and returns a reader decrypting the blob content.
+func newDecryptingReader(ctx context.Context, rc io.ReadCloser, wrapper KeyWrapper, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	br := bufio.NewReader(rc)
+	h, err := readEncryptedBlobHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := unwrapAEAD(ctx, wrapper, h)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptingReader{
+		r:      br,
+		closer: rc,
+		aead:   aead,
+		aad:    append([]byte(desc.Digest), h.marshal()...),
+		sealed: make([]byte, segmentSize+aead.Overhead()),
+	}, nil
+}
+
+// newDecryptingRangeReader reads the header of the encrypted blob file, and
+// returns a reader decrypting the content starting at offset. The file is
+// seeked to the segment containing offset so that the segments before it
+// are not read.
+func newDecryptingRangeReader(ctx context.Context, rs io.ReadSeeker, closer io.Closer, wrapper KeyWrapper, desc ocispec.Descriptor, offset int64) (io.ReadCloser, error) {
+	h, err := readEncryptedBlobHeader(rs)
+	if err != nil {
+		return nil, err
+	}
+	if offset > h.size {
+		return nil, fmt.Errorf("%w: offset %d exceeds size %d", errMalformedEncryptedBlob, offset, h.size)
+	}
+	aead, err := unwrapAEAD(ctx, wrapper, h)
+	if err != nil {
+		return nil, err
+	}
+	header := h.marshal()
+	index := offset / segmentSize
+	if _, err := rs.Seek(int64(len(header))+index*int64(segmentSize+aead.Overhead()), io.SeekStart); err != nil {
+		return nil, err
+	}
+	dr := &decryptingReader{
+		r:      bufio.NewReader(rs),
+		closer: closer,
+		aead:   aead,
+		aad:    append([]byte(desc.Digest), header...),
+		index:  uint64(index),
+		sealed: make([]byte, segmentSize+aead.Overhead()),
+	}
+	if _, err := io.CopyN(io.Discard, dr, offset-index*segmentSize); err != nil {
+		return nil, err
+	}
+	return dr, nil
+}
+
+// unwrapAEAD unwraps the data key in the header, and returns its AEAD.
+func unwrapAEAD(ctx context.Context, wrapper KeyWrapper, h encryptedBlobHeader) (cipher.AEAD, error) {
+	key, err := wrapper.UnwrapKey(ctx, h.wrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap key: %w", err)
+	}
+	return newAEAD(key)
+}
+
+// decryptingReader opens the sealed content segment by segment.
+type decryptingReader struct {
+	r      *bufio.Reader
+	closer io.Closer
+	aead   cipher.AEAD
+	aad    []byte
+	index  uint64
+	sealed []byte
+	buf    []byte
+	done   bool
+	err    error
+}
+
+// Read reads the decrypted content.
+func (dr *decryptingReader) Read(p []byte) (int, error) {
+	for len(dr.buf) == 0 {
+		if dr.err != nil {
+			return 0, dr.err
+		}
+		if dr.done {
+			dr.err = io.EOF
+			continue
+		}
+		dr.err = dr.open()
+	}
+	n := copy(p, dr.buf)
+	dr.buf = dr.buf[n:]
+	return n, nil
+}
+
+// open reads and opens the next segment.
+func (dr *decryptingReader) open() error {
+	n, err := io.ReadFull(dr.r, dr.sealed)
+	switch err {
+	case nil:
+		// a full segment is the last one only if nothing follows
+		_, peekErr := dr.r.Peek(1)
+		dr.done = peekErr == io.EOF
+	case io.ErrUnexpectedEOF:
+		dr.done = true
+	case io.EOF:
+		return fmt.Errorf("%w: truncated", errMalformedEncryptedBlob)
+	default:
+		return err
+	}
+	nonce := segmentNonce(dr.aead, dr.index, dr.done)
+	plain, err := dr.aead.Open(dr.sealed[:0], nonce, dr.sealed[:n], dr.aad)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errMalformedEncryptedBlob, err)
+	}
+	dr.index++
+	dr.buf = plain
+	return nil
+}
+
+// Close closes the underlying file.
+func (dr *decryptingReader) Close() error {
+	return dr.closer.Close()
+}
+
+// encryptedBlobSize returns the plaintext size recorded in the header of the
+// encrypted blob file.
+func encryptedBlobSize(r io.Reader) (int64, error) {
+	h, err := readEncryptedBlobHeader(r)
+	if err != nil {
+		return 0, err
+	}
+	return h.size, nil
+}
@@ -0,0 +1,359 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+func newTestKeyWrapper(t *testing.T, seed byte) KeyWrapper {
+	kek := bytes.Repeat([]byte{seed}, 32)
+	wrapper, err := NewAESKeyWrapper(kek)
+	if err != nil {
+		t.Fatal("NewAESKeyWrapper() error =", err)
+	}
+	return wrapper
+}
+
+func TestAESKeyWrapper(t *testing.T) {
+	ctx := context.Background()
+	wrapper := newTestKeyWrapper(t, 1)
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	wrapped, err := wrapper.WrapKey(ctx, key)
+	if err != nil {
+		t.Fatal("KeyWrapper.WrapKey() error =", err)
+	}
+	if bytes.Contains(wrapped, key) {
+		t.Errorf("KeyWrapper.WrapKey() = %x, contains the key", wrapped)
+	}
+	got, err := wrapper.UnwrapKey(ctx, wrapped)
+	if err != nil {
+		t.Fatal("KeyWrapper.UnwrapKey() error =", err)
+	}
+	if !bytes.Equal(got, key) {
+		t.Errorf("KeyWrapper.UnwrapKey() = %x, want %x", got, key)
+	}
+
+	// test wrong key encryption key
+	if _, err := newTestKeyWrapper(t, 2).UnwrapKey(ctx, wrapped); err == nil {
+		t.Error("KeyWrapper.UnwrapKey() error = nil, wantErr true")
+	}
+	// test short input
+	if _, err := wrapper.UnwrapKey(ctx, []byte("short")); err == nil {
+		t.Error("KeyWrapper.UnwrapKey() error = nil, wantErr true")
+	}
+	// test invalid key size
+	if _, err := NewAESKeyWrapper([]byte("invalid")); err == nil {
+		t.Error("NewAESKeyWrapper() error = nil, wantErr true")
+	}
+}
+
+func TestEncryptedStorage(t *testing.T) {
+	tempDir := t.TempDir()
+	s, err := NewEncryptedStorage(tempDir, newTestKeyWrapper(t, 1))
+	if err != nil {
+		t.Fatal("NewEncryptedStorage() error =", err)
+	}
+	ctx := context.Background()
+
+	blobs := [][]byte{
+		{},
+		[]byte("hello world"),
+		bytes.Repeat([]byte("a"), segmentSize),
+		bytes.Repeat([]byte("b"), 2*segmentSize),
+		bytes.Repeat([]byte("c"), 2*segmentSize+1),
+	}
+	descs := make([]ocispec.Descriptor, len(blobs))
+	for i, blob := range blobs {
+		descs[i] = content.NewDescriptorFromBytes("test", blob)
+		if err := s.Push(ctx, descs[i], bytes.NewReader(blob)); err != nil {
+			t.Fatalf("Storage.Push(%d) error = %v", i, err)
+		}
+	}
+
+	for i, desc := range descs {
+		exists, err := s.Exists(ctx, desc)
+		if err != nil {
+			t.Fatalf("Storage.Exists(%d) error = %v", i, err)
+		}
+		if !exists {
+			t.Errorf("Storage.Exists(%d) = %v, want %v", i, exists, true)
+		}
+		got, err := content.FetchAll(ctx, s, desc)
+		if err != nil {
+			t.Fatalf("Storage.Fetch(%d) error = %v", i, err)
+		}
+		if !bytes.Equal(got, blobs[i]) {
+			t.Errorf("Storage.Fetch(%d) = %q, want %q", i, got, blobs[i])
+		}
+	}
+
+	// test blob files are encrypted
+	onDisk, err := os.ReadFile(filepath.Join(tempDir, "blobs", "sha256", descs[1].Digest.Encoded()))
+	if err != nil {
+		t.Fatal("os.ReadFile() error =", err)
+	}
+	if bytes.Contains(onDisk, blobs[1]) {
+		t.Errorf("blob file %q contains the plaintext", onDisk)
+	}
+
+	// test Blobs reporting the plaintext sizes
+	sizes := make(map[digest.Digest]int64)
+	if err := s.Blobs(ctx, func(desc ocispec.Descriptor) error {
+		sizes[desc.Digest] = desc.Size
+		return nil
+	}); err != nil {
+		t.Fatal("Storage.Blobs() error =", err)
+	}
+	for i, desc := range descs {
+		if got := sizes[desc.Digest]; got != desc.Size {
+			t.Errorf("Storage.Blobs(%d) size = %d, want %d", i, got, desc.Size)
+		}
+	}
+
+	// test reading with a wrong key
+	wrong, err := NewEncryptedStorage(tempDir, newTestKeyWrapper(t, 2))
+	if err != nil {
+		t.Fatal("NewEncryptedStorage() error =", err)
+	}
+	if _, err := wrong.Fetch(ctx, descs[1]); err == nil {
+		t.Error("Storage.Fetch() error = nil, wantErr true")
+	}
+
+	// test nil key wrapper
+	if _, err := NewEncryptedStorage(tempDir, nil); err == nil {
+		t.Error("NewEncryptedStorage() error = nil, wantErr true")
+	}
+}
+
+func TestEncryptedStorage_Tampered(t *testing.T) {
+	ctx := context.Background()
+	blob := bytes.Repeat([]byte("hello world"), segmentSize/5)
+	desc := content.NewDescriptorFromBytes("test", blob)
+	other := []byte("foo")
+	otherDesc := content.NewDescriptorFromBytes("test", other)
+
+	tests := []struct {
+		name   string
+		tamper func(data, otherData []byte) []byte
+	}{
+		{
+			name: "modified",
+			tamper: func(data, _ []byte) []byte {
+				data[len(data)/2] ^= 1
+				return data
+			},
+		},
+		{
+			name: "truncated segment",
+			tamper: func(data, _ []byte) []byte {
+				return data[:len(data)-1]
+			},
+		},
+		{
+			name: "truncated at segment boundary",
+			tamper: func(data, _ []byte) []byte {
+				return data[:len(data)-(len(blob)-segmentSize)-16]
+			},
+		},
+		{
+			name: "trailing data",
+			tamper: func(data, _ []byte) []byte {
+				return append(data, 0)
+			},
+		},
+		{
+			name: "swapped",
+			tamper: func(_, otherData []byte) []byte {
+				return otherData
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			s, err := NewEncryptedStorage(tempDir, newTestKeyWrapper(t, 1))
+			if err != nil {
+				t.Fatal("NewEncryptedStorage() error =", err)
+			}
+			if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+				t.Fatal("Storage.Push() error =", err)
+			}
+			if err := s.Push(ctx, otherDesc, bytes.NewReader(other)); err != nil {
+				t.Fatal("Storage.Push() error =", err)
+			}
+			path := filepath.Join(tempDir, "blobs", "sha256", desc.Digest.Encoded())
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal("os.ReadFile() error =", err)
+			}
+			otherData, err := os.ReadFile(filepath.Join(tempDir, "blobs", "sha256", otherDesc.Digest.Encoded()))
+			if err != nil {
+				t.Fatal("os.ReadFile() error =", err)
+			}
+			if err := os.Chmod(path, 0644); err != nil {
+				t.Fatal("os.Chmod() error =", err)
+			}
+			if err := os.WriteFile(path, tt.tamper(data, otherData), 0644); err != nil {
+				t.Fatal("os.WriteFile() error =", err)
+			}
+
+			rc, err := s.Fetch(ctx, desc)
+			if err != nil {
+				return
+			}
+			defer rc.Close()
+			if _, err := io.ReadAll(rc); err == nil {
+				t.Error("Storage.Fetch().Read() error = nil, wantErr true")
+			}
+		})
+	}
+}
+
+func TestNewEncrypted(t *testing.T) {
+	tempDir := t.TempDir()
+	ctx := context.Background()
+	s, err := NewEncrypted(ctx, tempDir, newTestKeyWrapper(t, 1))
+	if err != nil {
+		t.Fatal("NewEncrypted() error =", err)
+	}
+
+	layer := []byte("hello world")
+	layerDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+	config := []byte("{}")
+	configDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, config)
+	manifest, err := json.Marshal(ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    configDesc,
+		Layers:    []ocispec.Descriptor{layerDesc},
+	})
+	if err != nil {
+		t.Fatal("json.Marshal() error =", err)
+	}
+	manifestDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifest)
+	for _, item := range []struct {
+		desc ocispec.Descriptor
+		data []byte
+	}{
+		{layerDesc, layer},
+		{configDesc, config},
+		{manifestDesc, manifest},
+	} {
+		if err := s.Push(ctx, item.desc, bytes.NewReader(item.data)); err != nil {
+			t.Fatal("Store.Push() error =", err)
+		}
+	}
+	ref := "latest"
+	if err := s.Tag(ctx, manifestDesc, ref); err != nil {
+		t.Fatal("Store.Tag() error =", err)
+	}
+
+	// test reloading the store
+	s, err = NewEncrypted(ctx, tempDir, newTestKeyWrapper(t, 1))
+	if err != nil {
+		t.Fatal("NewEncrypted() error =", err)
+	}
+	gotDesc, err := s.Resolve(ctx, ref)
+	if err != nil {
+		t.Fatal("Store.Resolve() error =", err)
+	}
+	if !content.Equal(gotDesc, manifestDesc) {
+		t.Errorf("Store.Resolve() = %v, want %v", gotDesc, manifestDesc)
+	}
+	predecessors, err := s.Predecessors(ctx, layerDesc)
+	if err != nil {
+		t.Fatal("Store.Predecessors() error =", err)
+	}
+	if len(predecessors) != 1 || !content.Equal(predecessors[0], manifestDesc) {
+		t.Errorf("Store.Predecessors() = %v, want %v", predecessors, []ocispec.Descriptor{manifestDesc})
+	}
+	got, err := content.FetchAll(ctx, s, layerDesc)
+	if err != nil {
+		t.Fatal("Store.Fetch() error =", err)
+	}
+	if !bytes.Equal(got, layer) {
+		t.Errorf("Store.Fetch() = %q, want %q", got, layer)
+	}
+
+	// test the index file is not encrypted
+	indexJSON, err := os.ReadFile(filepath.Join(tempDir, ociImageIndexFile))
+	if err != nil {
+		t.Fatal("os.ReadFile() error =", err)
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(indexJSON, &index); err != nil {
+		t.Fatal("json.Unmarshal() error =", err)
+	}
+	if len(index.Manifests) != 1 || index.Manifests[0].Digest != manifestDesc.Digest {
+		t.Errorf("index manifests = %v, want %v", index.Manifests, []ocispec.Descriptor{manifestDesc})
+	}
+	if _, err := NewEncrypted(ctx, tempDir, nil); err == nil {
+		t.Error("NewEncrypted() error = nil, wantErr true")
+	}
+}
+
+func TestEncryptedStorage_FetchRange(t *testing.T) {
+	s, err := NewEncryptedStorage(t.TempDir(), newTestKeyWrapper(t, 1))
+	if err != nil {
+		t.Fatal("NewEncryptedStorage() error =", err)
+	}
+	ctx := context.Background()
+	blob := make([]byte, 2*segmentSize+100)
+	for i := range blob {
+		blob[i] = byte(i % 251)
+	}
+	desc := content.NewDescriptorFromBytes("test", blob)
+	if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Storage.Push() error =", err)
+	}
+
+	tests := []struct {
+		offset int64
+		length int64
+	}{
+		{0, 10},
+		{segmentSize - 5, 10},
+		{segmentSize, segmentSize},
+		{2*segmentSize + 50, 50},
+		{100, 2 * segmentSize},
+		{int64(len(blob)), 0},
+	}
+	for _, tt := range tests {
+		rc, err := s.FetchRange(ctx, desc, tt.offset, tt.length)
+		if err != nil {
+			t.Fatalf("Storage.FetchRange(%d, %d) error = %v", tt.offset, tt.length, err)
+		}
+		got, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("Storage.FetchRange(%d, %d).Read() error = %v", tt.offset, tt.length, err)
+		}
+		if want := blob[tt.offset : tt.offset+tt.length]; !bytes.Equal(got, want) {
+			t.Errorf("Storage.FetchRange(%d, %d) content mismatch", tt.offset, tt.length)
+		}
+	}
+}
@@ -26,16 +26,17 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/opencontainers/go-digest"
 	specs "github.com/opencontainers/image-spec/specs-go"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
-	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/internal/container/set"
 	"oras.land/oras-go/v2/internal/descriptor"
 	"oras.land/oras-go/v2/internal/graph"
 	"oras.land/oras-go/v2/internal/resolver"
+	"oras.land/oras-go/v2/tracing"
 )
 
 // ociImageIndexFile is the file name of the index
@@ -55,14 +56,28 @@ type Store struct {
 	//     to manually call SaveIndex() when needed.
 	//   - Default value: true.
 	AutoSaveIndex bool
-	root          string
-	indexPath     string
-	index         *ocispec.Index
-	indexLock     sync.Mutex
-
-	storage     content.Storage
+	// TrackAccessTimes controls if the OCI store records the last access
+	// times of the tags, which are used by Prune.
+	//   - If TrackAccessTimes is set to true, the access times are saved
+	//     along with the index file in the "oras-access.json" file, which is
+	//     not part of the OCI Image Layout Specification.
+	//   - If TrackAccessTimes is set to false, the access times are neither
+	//     recorded nor saved, and the layout is not modified beyond the
+	//     specification.
+	//   - Default value: false.
+	TrackAccessTimes bool
+	root             string
+	indexPath        string
+	index            *ocispec.Index
+	indexLock        sync.Mutex
+
+	storage     *Storage
 	tagResolver *resolver.Memory
 	graph       *graph.Memory
+
+	accessPath  string
+	accessTimes map[string]time.Time
+	accessLock  sync.Mutex
 }
 
 // New creates a new OCI store with context.Background().
@@ -80,11 +95,32 @@ func NewWithContext(ctx context.Context, root string) (*Store, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create storage: %w", err)
 	}
+	return newStore(ctx, rootAbs, storage)
+}
+
+// NewEncrypted creates a new OCI store, where the blob files are encrypted at
+// rest with data keys wrapped by wrapper. The index and the layout files are
+// not encrypted.
+// See NewEncryptedStorage for details.
+func NewEncrypted(ctx context.Context, root string, wrapper KeyWrapper) (*Store, error) {
+	rootAbs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve absolute path for %s: %w", root, err)
+	}
+	storage, err := NewEncryptedStorage(rootAbs, wrapper)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage: %w", err)
+	}
+	return newStore(ctx, rootAbs, storage)
+}
 
+// newStore creates a new OCI store rooted at the absolute path rootAbs.
+func newStore(ctx context.Context, rootAbs string, storage *Storage) (*Store, error) {
 	store := &Store{
 		AutoSaveIndex: true,
 		root:          rootAbs,
 		indexPath:     filepath.Join(rootAbs, ociImageIndexFile),
+		accessPath:    filepath.Join(rootAbs, accessTimesFile),
 		storage:       storage,
 		tagResolver:   resolver.NewMemory(),
 		graph:         graph.NewMemory(),
@@ -99,17 +135,22 @@ func NewWithContext(ctx context.Context, root string) (*Store, error) {
 	if err := store.loadIndexFile(ctx); err != nil {
 		return nil, fmt.Errorf("invalid OCI Image Layout: %w", err)
 	}
+	store.loadAccessTimes()
 
 	return store, nil
 }
 
 // Fetch fetches the content identified by the descriptor.
-func (s *Store) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+func (s *Store) Fetch(ctx context.Context, target ocispec.Descriptor) (_ io.ReadCloser, err error) {
+	ctx, span := tracing.Start(ctx, "oci.Store.Fetch", tracing.DescriptorAttributes(target)...)
+	defer func() { span.End(err) }()
 	return s.storage.Fetch(ctx, target)
 }
 
 // Push pushes the content, matching the expected descriptor.
-func (s *Store) Push(ctx context.Context, expected ocispec.Descriptor, reader io.Reader) error {
+func (s *Store) Push(ctx context.Context, expected ocispec.Descriptor, reader io.Reader) (err error) {
+	ctx, span := tracing.Start(ctx, "oci.Store.Push", tracing.DescriptorAttributes(expected)...)
+	defer func() { span.End(err) }()
 	if err := s.storage.Push(ctx, expected, reader); err != nil {
 		return err
 	}
@@ -123,15 +164,25 @@ func (s *Store) Push(ctx context.Context, expected ocispec.Descriptor, reader io
 	return nil
 }
 
+// FetchRange fetches length bytes of the content identified by the
+// descriptor, starting at offset.
+func (s *Store) FetchRange(ctx context.Context, target ocispec.Descriptor, offset, length int64) (io.ReadCloser, error) {
+	return s.storage.FetchRange(ctx, target, offset, length)
+}
+
 // Exists returns true if the described content exists.
-func (s *Store) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+func (s *Store) Exists(ctx context.Context, target ocispec.Descriptor) (_ bool, err error) {
+	ctx, span := tracing.Start(ctx, "oci.Store.Exists", tracing.DescriptorAttributes(target)...)
+	defer func() { span.End(err) }()
 	return s.storage.Exists(ctx, target)
 }
 
 // Tag tags a descriptor with a reference string.
 // reference should be a valid tag (e.g. "latest").
 // Reference: https://github.com/opencontainers/image-spec/blob/v1.1.0-rc2/image-layout.md#indexjson-file
-func (s *Store) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+func (s *Store) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) (err error) {
+	ctx, span := tracing.Start(ctx, "oci.Store.Tag", append(tracing.DescriptorAttributes(desc), tracing.String(tracing.AttributeKeyReference, reference))...)
+	defer func() { span.End(err) }()
 	if err := validateReference(reference); err != nil {
 		return err
 	}
@@ -159,6 +210,9 @@ func (s *Store) tag(ctx context.Context, desc ocispec.Descriptor, reference stri
 	if err := s.tagResolver.Tag(ctx, desc, reference); err != nil {
 		return err
 	}
+	if reference != dgst {
+		s.recordAccess(reference)
+	}
 	if s.AutoSaveIndex {
 		return s.SaveIndex()
 	}
@@ -170,7 +224,9 @@ func (s *Store) tag(ctx context.Context, desc ocispec.Descriptor, reference stri
 // github.com/opencontainers/image-spec/specs-go/v1. If the reference is a
 // digest the returned descriptor will be a plain descriptor (containing only
 // the digest, media type and size).
-func (s *Store) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+func (s *Store) Resolve(ctx context.Context, reference string) (_ ocispec.Descriptor, err error) {
+	ctx, span := tracing.Start(ctx, "oci.Store.Resolve", tracing.String(tracing.AttributeKeyReference, reference))
+	defer func() { span.End(err) }()
 	if reference == "" {
 		return ocispec.Descriptor{}, errdef.ErrMissingReference
 	}
@@ -189,9 +245,34 @@ func (s *Store) Resolve(ctx context.Context, reference string) (ocispec.Descript
 		return descriptor.Plain(desc), nil
 	}
 
+	s.recordAccess(reference)
 	return desc, nil
 }
 
+// Blobs calls fn with the descriptors of the blobs in the store.
+// See ReadOnlyStorage.Blobs for details.
+func (s *Store) Blobs(ctx context.Context, fn func(desc ocispec.Descriptor) error) error {
+	return s.storage.Blobs(ctx, fn)
+}
+
+// Delete removes the content identified by the descriptor, and removes all the
+// references tagging it.
+// Returns ErrNotFound if the content does not exist.
+// Integrity of the graph is not checked: the predecessors of the deleted
+// content are kept.
+func (s *Store) Delete(ctx context.Context, target ocispec.Descriptor) (err error) {
+	ctx, span := tracing.Start(ctx, "oci.Store.Delete", tracing.DescriptorAttributes(target)...)
+	defer func() { span.End(err) }()
+	if err := s.storage.Delete(ctx, target); err != nil {
+		return err
+	}
+	s.graph.Remove(ctx, target)
+	if refs := s.tagResolver.UntagAll(target); len(refs) > 0 && s.AutoSaveIndex {
+		return s.SaveIndex()
+	}
+	return nil
+}
+
 // Predecessors returns the nodes directly pointing to the current node.
 // Predecessors returns nil without error if the node does not exists in the
 // store.
@@ -271,6 +352,9 @@ func (s *Store) loadIndexFile(ctx context.Context) error {
 //     the OCI store will automatically call this method on each Tag() call.
 //   - If AutoSaveIndex is set to false, it's the caller's responsibility
 //     to manually call this method when needed.
+//
+// If TrackAccessTimes is set to true, the last access times of the tags, used
+// by Prune, are saved along with the index file.
 func (s *Store) SaveIndex() error {
 	s.indexLock.Lock()
 	defer s.indexLock.Unlock()
@@ -303,7 +387,10 @@ func (s *Store) SaveIndex() error {
 	}
 
 	s.index.Manifests = manifests
-	return s.writeIndexFile()
+	if err := s.writeIndexFile(); err != nil {
+		return err
+	}
+	return s.saveAccessTimes(refMap)
 }
 
 // writeIndexFile writes the `index.json` file.
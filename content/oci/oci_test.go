@@ -42,6 +42,7 @@ import (
 	"oras.land/oras-go/v2/internal/descriptor"
 	"oras.land/oras-go/v2/internal/spec"
 	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/tracing"
 )
 
 // storageTracker tracks storage API counts.
@@ -1994,3 +1995,131 @@ func equalDescriptorSet(actual []ocispec.Descriptor, expected []ocispec.Descript
 	}
 	return true
 }
+
+func TestStore_Delete(t *testing.T) {
+	tempDir := t.TempDir()
+	s, err := New(tempDir)
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	ctx := context.Background()
+
+	layer := []byte("hello world")
+	layerDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Config: layerDesc,
+		Layers: []ocispec.Descriptor{layerDesc},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifestJSON)
+	if err := s.Push(ctx, layerDesc, bytes.NewReader(layer)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+	if err := s.Push(ctx, manifestDesc, bytes.NewReader(manifestJSON)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+	if err := s.Tag(ctx, manifestDesc, "latest"); err != nil {
+		t.Fatal("Store.Tag() error =", err)
+	}
+
+	if err := s.Delete(ctx, manifestDesc); err != nil {
+		t.Fatal("Store.Delete() error =", err)
+	}
+	exists, err := s.Exists(ctx, manifestDesc)
+	if err != nil {
+		t.Fatal("Store.Exists() error =", err)
+	}
+	if exists {
+		t.Errorf("Store.Exists() = %v, want %v", exists, false)
+	}
+	for _, ref := range []string{"latest", manifestDesc.Digest.String()} {
+		if _, err := s.Resolve(ctx, ref); !errors.Is(err, errdef.ErrNotFound) {
+			t.Errorf("Store.Resolve(%s) error = %v, want %v", ref, err, errdef.ErrNotFound)
+		}
+	}
+	predecessors, err := s.Predecessors(ctx, layerDesc)
+	if err != nil {
+		t.Fatal("Store.Predecessors() error =", err)
+	}
+	if len(predecessors) != 0 {
+		t.Errorf("Store.Predecessors() = %v, want none", predecessors)
+	}
+
+	// verify the index file is updated
+	indexJSON, err := os.ReadFile(filepath.Join(tempDir, "index.json"))
+	if err != nil {
+		t.Fatal("failed to read index.json:", err)
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(indexJSON, &index); err != nil {
+		t.Fatal("failed to decode index.json:", err)
+	}
+	if len(index.Manifests) != 0 {
+		t.Errorf("index.json manifests = %v, want none", index.Manifests)
+	}
+
+	// delete blob
+	if err := s.Delete(ctx, layerDesc); err != nil {
+		t.Fatal("Store.Delete() error =", err)
+	}
+	if err := s.Delete(ctx, layerDesc); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Store.Delete() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+}
+
+// spanNameRecorder records the names of the started spans.
+type spanNameRecorder struct {
+	names []string
+}
+
+func (r *spanNameRecorder) Start(ctx context.Context, name string, _ ...tracing.Attribute) (context.Context, tracing.Span) {
+	r.names = append(r.names, name)
+	return ctx, r
+}
+
+func (r *spanNameRecorder) SetAttributes(...tracing.Attribute) {}
+
+func (r *spanNameRecorder) End(error) {}
+
+func TestStore_Tracing(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	recorder := &spanNameRecorder{}
+	ctx := tracing.WithTracer(context.Background(), recorder)
+
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+	if err := s.Tag(ctx, desc, "latest"); err != nil {
+		t.Fatal("Store.Tag() error =", err)
+	}
+	if _, err := s.Resolve(ctx, "latest"); err != nil {
+		t.Fatal("Store.Resolve() error =", err)
+	}
+	if _, err := s.Exists(ctx, desc); err != nil {
+		t.Fatal("Store.Exists() error =", err)
+	}
+	if _, err := content.FetchAll(ctx, s, desc); err != nil {
+		t.Fatal("FetchAll() error =", err)
+	}
+	if err := s.Delete(ctx, desc); err != nil {
+		t.Fatal("Store.Delete() error =", err)
+	}
+	want := []string{
+		"oci.Store.Push",
+		"oci.Store.Tag",
+		"oci.Store.Resolve",
+		"oci.Store.Exists",
+		"oci.Store.Fetch",
+		"oci.Store.Delete",
+	}
+	if !reflect.DeepEqual(recorder.names, want) {
+		t.Errorf("span names = %v, want %v", recorder.names, want)
+	}
+}
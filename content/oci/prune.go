@@ -0,0 +1,372 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/registry"
+)
+
+// accessTimesFile is the file name of the last access times of the tags,
+// which is not part of the OCI Image Layout Specification.
+const accessTimesFile = "oras-access.json"
+
+// PruneOptions contains parameters for Store.Prune.
+type PruneOptions struct {
+	// MaxAge, if positive, removes the tags not accessed within MaxAge.
+	MaxAge time.Duration
+	// MaxTags, if positive, keeps at most MaxTags most recently accessed
+	// tags per repository prefix, and removes the others.
+	MaxTags int
+	// RepositoryPrefix returns the repository prefix of the reference, by
+	// which the tags are grouped for MaxTags.
+	// If RepositoryPrefix is nil, the part of the reference before the tag
+	// separator ':' is used (e.g. "example.com/hello" for
+	// "example.com/hello:v1"), and the references without the separator
+	// share the empty prefix.
+	RepositoryPrefix func(reference string) string
+	// DryRun, if true, only reports the tags and the content which would be
+	// removed without modifying the store.
+	DryRun bool
+}
+
+// PruneResult is the result of Store.Prune.
+type PruneResult struct {
+	// Untagged are the removed tags.
+	Untagged []string
+	// Deleted are the descriptors of the deleted content, in the deletion
+	// order. The blobs not referenced by any manifest are described as
+	// application/octet-stream.
+	Deleted []ocispec.Descriptor
+}
+
+// Prune removes the tags not accessed within opts.MaxAge or beyond the
+// opts.MaxTags most recently accessed tags per repository prefix, and then
+// deletes the content not reachable from the remaining tags.
+//
+// A tag is accessed when it is tagged or resolved, if TrackAccessTimes is
+// true. The access times are persisted by SaveIndex, and the tags without
+// recorded access times are considered accessed when the store is opened.
+// If TrackAccessTimes is false, the tags are only ordered by the access times
+// persisted before, if any.
+// The content reachable from a tag includes the tagged node, and recursively
+// its successors and its referrers. Manifests not reachable from any tag are
+// deleted even if they are listed in the index file by digest.
+//
+// If AutoSaveIndex is false, the caller should call SaveIndex after pruning to
+// keep the index file consistent with the deleted content.
+// Prune should not be called concurrently with other write operations on the
+// store.
+func (s *Store) Prune(ctx context.Context, opts PruneOptions) (PruneResult, error) {
+	var result PruneResult
+	refMap := s.tagResolver.Map()
+	for ref, desc := range refMap {
+		if ref == desc.Digest.String() {
+			delete(refMap, ref)
+		}
+	}
+	result.Untagged = s.expiredTags(refMap, opts)
+	for _, ref := range result.Untagged {
+		delete(refMap, ref)
+	}
+
+	// mark the content reachable from the remaining tags
+	var roots []ocispec.Descriptor
+	for _, desc := range refMap {
+		roots = append(roots, desc)
+	}
+	reachable, err := s.walk(ctx, roots, func(ctx context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		if !descriptor.IsManifest(desc) {
+			return nil, nil
+		}
+		return registry.Referrers(ctx, s, desc, "")
+	})
+	if err != nil {
+		return PruneResult{}, err
+	}
+
+	// discover the content listed in the index file and the orphan blobs
+	var indexed []ocispec.Descriptor
+	for _, desc := range s.tagResolver.Map() {
+		indexed = append(indexed, desc)
+	}
+	known, err := s.walk(ctx, indexed, func(context.Context, ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		return nil, nil
+	})
+	if err != nil {
+		return PruneResult{}, err
+	}
+	result.Deleted = known.deletionOrder(reachable)
+	if err := s.Blobs(ctx, func(desc ocispec.Descriptor) error {
+		if !known.contains(desc.Digest.String()) && !reachable.contains(desc.Digest.String()) {
+			result.Deleted = append(result.Deleted, desc)
+		}
+		return nil
+	}); err != nil {
+		return PruneResult{}, err
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	for _, ref := range result.Untagged {
+		s.tagResolver.Untag(ref)
+	}
+	s.accessLock.Lock()
+	for _, ref := range result.Untagged {
+		delete(s.accessTimes, ref)
+	}
+	s.accessLock.Unlock()
+
+	deleted := result.Deleted[:0]
+	for _, desc := range result.Deleted {
+		if err := s.Delete(ctx, desc); err != nil {
+			if errors.Is(err, errdef.ErrNotFound) {
+				continue
+			}
+			result.Deleted = deleted
+			return result, err
+		}
+		deleted = append(deleted, desc)
+	}
+	result.Deleted = deleted
+	if s.AutoSaveIndex {
+		if err := s.SaveIndex(); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// expiredTags returns the tags in refMap to be removed according to opts, in
+// ascending order.
+func (s *Store) expiredTags(refMap map[string]ocispec.Descriptor, opts PruneOptions) []string {
+	repositoryPrefix := opts.RepositoryPrefix
+	if repositoryPrefix == nil {
+		repositoryPrefix = defaultRepositoryPrefix
+	}
+	now := time.Now()
+
+	s.accessLock.Lock()
+	accessTimes := make(map[string]time.Time, len(refMap))
+	for ref := range refMap {
+		accessTimes[ref] = s.accessTimes[ref]
+	}
+	s.accessLock.Unlock()
+
+	expired := make(map[string]bool)
+	groups := make(map[string][]string)
+	for ref, accessed := range accessTimes {
+		if opts.MaxAge > 0 && now.Sub(accessed) > opts.MaxAge {
+			expired[ref] = true
+		}
+		prefix := repositoryPrefix(ref)
+		groups[prefix] = append(groups[prefix], ref)
+	}
+	if opts.MaxTags > 0 {
+		for _, refs := range groups {
+			if len(refs) <= opts.MaxTags {
+				continue
+			}
+			// most recently accessed first
+			sort.Slice(refs, func(i, j int) bool {
+				ti, tj := accessTimes[refs[i]], accessTimes[refs[j]]
+				if !ti.Equal(tj) {
+					return ti.After(tj)
+				}
+				return refs[i] < refs[j]
+			})
+			for _, ref := range refs[opts.MaxTags:] {
+				expired[ref] = true
+			}
+		}
+	}
+
+	tags := make([]string, 0, len(expired))
+	for ref := range expired {
+		tags = append(tags, ref)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// defaultRepositoryPrefix returns the part of the reference before the tag
+// separator.
+func defaultRepositoryPrefix(reference string) string {
+	i := strings.LastIndex(reference, ":")
+	if i < 0 || strings.Contains(reference[i+1:], "/") {
+		// no tag separator, or the separator of a port number
+		return ""
+	}
+	return reference[:i]
+}
+
+// pruneWalk records the nodes visited by Store.walk.
+type pruneWalk struct {
+	// nodes are the visited nodes in the visiting order.
+	nodes []ocispec.Descriptor
+	// successors maps the digests of the visited nodes to their successors.
+	successors map[string][]ocispec.Descriptor
+}
+
+// contains returns true if the node identified by the digest is visited.
+func (w *pruneWalk) contains(dgst string) bool {
+	_, ok := w.successors[dgst]
+	return ok
+}
+
+// walk visits the nodes reachable from the given nodes through their
+// successors and the nodes returned by extra.
+// Nodes not found in the store are skipped.
+func (s *Store) walk(ctx context.Context, nodes []ocispec.Descriptor, extra func(context.Context, ocispec.Descriptor) ([]ocispec.Descriptor, error)) (*pruneWalk, error) {
+	walk := &pruneWalk{
+		successors: make(map[string][]ocispec.Descriptor),
+	}
+	queue := nodes
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		dgst := node.Digest.String()
+		if walk.contains(dgst) {
+			continue
+		}
+
+		successors, err := content.Successors(ctx, s, node)
+		if err != nil {
+			if errors.Is(err, errdef.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		walk.successors[dgst] = successors
+		walk.nodes = append(walk.nodes, node)
+		queue = append(queue, successors...)
+
+		others, err := extra(ctx, node)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, others...)
+	}
+	return walk, nil
+}
+
+// deletionOrder returns the visited nodes not visited by the excluded walk,
+// ordered such that predecessors come before their successors.
+func (w *pruneWalk) deletionOrder(excluded *pruneWalk) []ocispec.Descriptor {
+	var postOrder []ocispec.Descriptor
+	done := make(map[string]bool)
+	var visit func(node ocispec.Descriptor)
+	visit = func(node ocispec.Descriptor) {
+		dgst := node.Digest.String()
+		if done[dgst] || excluded.contains(dgst) {
+			return
+		}
+		done[dgst] = true
+		for _, successor := range w.successors[dgst] {
+			if w.contains(successor.Digest.String()) {
+				visit(successor)
+			}
+		}
+		postOrder = append(postOrder, node)
+	}
+	for _, node := range w.nodes {
+		visit(node)
+	}
+
+	// reverse the post-order so that predecessors come first
+	for i, j := 0, len(postOrder)-1; i < j; i, j = i+1, j-1 {
+		postOrder[i], postOrder[j] = postOrder[j], postOrder[i]
+	}
+	return postOrder
+}
+
+// recordAccess records the current time as the last access time of the
+// reference.
+func (s *Store) recordAccess(reference string) {
+	if !s.TrackAccessTimes {
+		return
+	}
+	s.accessLock.Lock()
+	defer s.accessLock.Unlock()
+	s.accessTimes[reference] = time.Now()
+}
+
+// loadAccessTimes reads the last access times of the tags from the file
+// system, if any. An unreadable or corrupt file is treated as empty. The tags
+// without recorded access times are considered accessed now.
+func (s *Store) loadAccessTimes() {
+	s.accessTimes = make(map[string]time.Time)
+	if accessJSON, err := os.ReadFile(s.accessPath); err == nil && len(accessJSON) > 0 {
+		if err := json.Unmarshal(accessJSON, &s.accessTimes); err != nil {
+			s.accessTimes = make(map[string]time.Time)
+		}
+	}
+
+	now := time.Now()
+	refMap := s.tagResolver.Map()
+	for ref, desc := range refMap {
+		if _, ok := s.accessTimes[ref]; !ok && ref != desc.Digest.String() {
+			s.accessTimes[ref] = now
+		}
+	}
+	for ref := range s.accessTimes {
+		if _, ok := refMap[ref]; !ok {
+			delete(s.accessTimes, ref)
+		}
+	}
+}
+
+// saveAccessTimes writes the last access times of the tags in refMap to the
+// file system if TrackAccessTimes is true. The file is removed if there is no
+// tag.
+func (s *Store) saveAccessTimes(refMap map[string]ocispec.Descriptor) error {
+	if !s.TrackAccessTimes {
+		return nil
+	}
+	s.accessLock.Lock()
+	accessTimes := make(map[string]time.Time)
+	for ref, accessed := range s.accessTimes {
+		if _, ok := refMap[ref]; ok {
+			accessTimes[ref] = accessed
+		}
+	}
+	s.accessLock.Unlock()
+
+	if len(accessTimes) == 0 {
+		if err := os.Remove(s.accessPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	accessJSON, err := json.Marshal(accessTimes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal access times file: %w", err)
+	}
+	return os.WriteFile(s.accessPath, accessJSON, 0666)
+}
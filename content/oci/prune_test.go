@@ -0,0 +1,259 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// pushTestImage pushes an image with a unique layer to s, tags it with ref,
+// and returns the descriptors of the manifest and the layer.
+func pushTestImage(t *testing.T, s *Store, ref string, layer []byte) (ocispec.Descriptor, ocispec.Descriptor) {
+	t.Helper()
+	ctx := context.Background()
+	layerDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Config: layerDesc,
+		Layers: []ocispec.Descriptor{layerDesc},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifestJSON)
+	if err := s.Push(ctx, layerDesc, bytes.NewReader(layer)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+	if err := s.Push(ctx, manifestDesc, bytes.NewReader(manifestJSON)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+	if err := s.Tag(ctx, manifestDesc, ref); err != nil {
+		t.Fatal("Store.Tag() error =", err)
+	}
+	return manifestDesc, layerDesc
+}
+
+func TestStore_Prune(t *testing.T) {
+	ctx := context.Background()
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	s.TrackAccessTimes = true
+	oldManifest, oldLayer := pushTestImage(t, s, "example.com/foo:old", []byte("old"))
+	newManifest, newLayer := pushTestImage(t, s, "example.com/foo:new", []byte("new"))
+	s.accessTimes["example.com/foo:old"] = time.Now().Add(-48 * time.Hour)
+
+	// the orphan blob is deleted as well
+	orphan := []byte("orphan")
+	orphanDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, orphan)
+	if err := s.Push(ctx, orphanDesc, bytes.NewReader(orphan)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+
+	result, err := s.Prune(ctx, PruneOptions{MaxAge: 24 * time.Hour})
+	if err != nil {
+		t.Fatal("Store.Prune() error =", err)
+	}
+	if want := []string{"example.com/foo:old"}; !reflect.DeepEqual(result.Untagged, want) {
+		t.Errorf("Store.Prune() untagged = %v, want %v", result.Untagged, want)
+	}
+	var deleted []string
+	for _, desc := range result.Deleted {
+		deleted = append(deleted, desc.Digest.String())
+	}
+	want := []string{oldManifest.Digest.String(), oldLayer.Digest.String(), orphanDesc.Digest.String()}
+	if !reflect.DeepEqual(deleted, want) {
+		t.Errorf("Store.Prune() deleted = %v, want %v", deleted, want)
+	}
+	for _, desc := range []ocispec.Descriptor{oldManifest, oldLayer, orphanDesc} {
+		if exists, err := s.Exists(ctx, desc); err != nil || exists {
+			t.Errorf("Store.Exists(%s) = %v, %v, want false", desc.Digest, exists, err)
+		}
+	}
+	for _, desc := range []ocispec.Descriptor{newManifest, newLayer} {
+		if exists, err := s.Exists(ctx, desc); err != nil || !exists {
+			t.Errorf("Store.Exists(%s) = %v, %v, want true", desc.Digest, exists, err)
+		}
+	}
+	if _, err := s.Resolve(ctx, "example.com/foo:old"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Store.Resolve() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+
+	// the pruned store can be reloaded
+	reloaded, err := New(s.root)
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	if _, err := reloaded.Resolve(ctx, "example.com/foo:new"); err != nil {
+		t.Error("Store.Resolve() error =", err)
+	}
+}
+
+func TestStore_Prune_MaxTags(t *testing.T) {
+	ctx := context.Background()
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	s.TrackAccessTimes = true
+	now := time.Now()
+	for i, ref := range []string{"foo:v1", "foo:v2", "foo:v3", "bar:v1", "localhost:5000/foo:v1"} {
+		pushTestImage(t, s, ref, []byte(ref))
+		s.accessTimes[ref] = now.Add(time.Duration(i-10) * time.Minute)
+	}
+	// accessing a tag makes it the most recent
+	if _, err := s.Resolve(ctx, "foo:v1"); err != nil {
+		t.Fatal("Store.Resolve() error =", err)
+	}
+
+	result, err := s.Prune(ctx, PruneOptions{MaxTags: 2, DryRun: true})
+	if err != nil {
+		t.Fatal("Store.Prune() error =", err)
+	}
+	if want := []string{"foo:v2"}; !reflect.DeepEqual(result.Untagged, want) {
+		t.Errorf("Store.Prune() untagged = %v, want %v", result.Untagged, want)
+	}
+	if len(result.Deleted) != 2 {
+		t.Errorf("len(Store.Prune() deleted) = %d, want 2", len(result.Deleted))
+	}
+	// nothing is removed in the dry-run mode
+	if _, err := s.Resolve(ctx, "foo:v2"); err != nil {
+		t.Error("Store.Resolve() error =", err)
+	}
+	for _, desc := range result.Deleted {
+		if exists, err := s.Exists(ctx, desc); err != nil || !exists {
+			t.Errorf("Store.Exists(%s) = %v, %v, want true", desc.Digest, exists, err)
+		}
+	}
+}
+
+func TestStore_Prune_SharedContent(t *testing.T) {
+	ctx := context.Background()
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	s.TrackAccessTimes = true
+	manifestDesc, layerDesc := pushTestImage(t, s, "old", []byte("shared"))
+	if err := s.Tag(ctx, manifestDesc, "new"); err != nil {
+		t.Fatal("Store.Tag() error =", err)
+	}
+	s.accessTimes["old"] = time.Now().Add(-time.Hour)
+
+	result, err := s.Prune(ctx, PruneOptions{MaxAge: time.Minute})
+	if err != nil {
+		t.Fatal("Store.Prune() error =", err)
+	}
+	if want := []string{"old"}; !reflect.DeepEqual(result.Untagged, want) {
+		t.Errorf("Store.Prune() untagged = %v, want %v", result.Untagged, want)
+	}
+	if len(result.Deleted) != 0 {
+		t.Errorf("Store.Prune() deleted = %v, want none", result.Deleted)
+	}
+	for _, desc := range []ocispec.Descriptor{manifestDesc, layerDesc} {
+		if exists, err := s.Exists(ctx, desc); err != nil || !exists {
+			t.Errorf("Store.Exists(%s) = %v, %v, want true", desc.Digest, exists, err)
+		}
+	}
+}
+
+func TestStore_AccessTimes(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	s, err := New(root)
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	s.TrackAccessTimes = true
+	pushTestImage(t, s, "foo", []byte("foo"))
+	accessed := time.Now().Add(-time.Hour).Round(0)
+	s.accessTimes["foo"] = accessed
+	if err := s.SaveIndex(); err != nil {
+		t.Fatal("Store.SaveIndex() error =", err)
+	}
+
+	reloaded, err := New(root)
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	reloaded.TrackAccessTimes = true
+	if got := reloaded.accessTimes["foo"]; !got.Equal(accessed) {
+		t.Errorf("access time = %v, want %v", got, accessed)
+	}
+	if _, err := reloaded.Resolve(ctx, "foo"); err != nil {
+		t.Fatal("Store.Resolve() error =", err)
+	}
+	if got := reloaded.accessTimes["foo"]; !got.After(accessed) {
+		t.Errorf("access time = %v, want after %v", got, accessed)
+	}
+}
+
+func TestStore_AccessTimes_Disabled(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	s, err := New(root)
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	pushTestImage(t, s, "foo", []byte("foo"))
+	if _, err := s.Resolve(ctx, "foo"); err != nil {
+		t.Fatal("Store.Resolve() error =", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, accessTimesFile)); !os.IsNotExist(err) {
+		t.Errorf("os.Stat(%s) error = %v, want not exist", accessTimesFile, err)
+	}
+}
+
+func TestStore_AccessTimes_Corrupt(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, accessTimesFile), []byte("{corrupt"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(root)
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	if len(s.accessTimes) != 0 {
+		t.Errorf("access times = %v, want empty", s.accessTimes)
+	}
+}
+
+func Test_defaultRepositoryPrefix(t *testing.T) {
+	tests := map[string]string{
+		"latest":                 "",
+		"foo:v1":                 "foo",
+		"example.com/foo/bar:v1": "example.com/foo/bar",
+		"localhost:5000/foo":     "",
+		"localhost:5000/foo:v1":  "localhost:5000/foo",
+	}
+	for reference, want := range tests {
+		if got := defaultRepositoryPrefix(reference); got != want {
+			t.Errorf("defaultRepositoryPrefix(%s) = %q, want %q", reference, got, want)
+		}
+	}
+}
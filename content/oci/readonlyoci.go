@@ -78,6 +78,12 @@ func (s *ReadOnlyStore) Fetch(ctx context.Context, target ocispec.Descriptor) (i
 	return s.storage.Fetch(ctx, target)
 }
 
+// FetchRange fetches length bytes of the content identified by the
+// descriptor, starting at offset.
+func (s *ReadOnlyStore) FetchRange(ctx context.Context, target ocispec.Descriptor, offset, length int64) (io.ReadCloser, error) {
+	return content.FetchRange(ctx, s.storage, target, offset, length)
+}
+
 // Exists returns true if the described content exists.
 func (s *ReadOnlyStore) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
 	return s.storage.Exists(ctx, target)
@@ -25,7 +25,9 @@ import (
 
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
 	"oras.land/oras-go/v2/internal/fs/tarfs"
 )
 
@@ -34,6 +36,9 @@ import (
 // Reference: https://github.com/opencontainers/image-spec/blob/v1.1.0-rc2/image-layout.md
 type ReadOnlyStorage struct {
 	fsys fs.FS
+	// keyWrapper unwraps the keys of the encrypted blob files.
+	// If nil, the blob files are not encrypted.
+	keyWrapper KeyWrapper
 }
 
 // NewStorageFromFS creates a new read-only CAS from fsys.
@@ -54,7 +59,7 @@ func NewStorageFromTar(path string) (*ReadOnlyStorage, error) {
 }
 
 // Fetch fetches the content identified by the descriptor.
-func (s *ReadOnlyStorage) Fetch(_ context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+func (s *ReadOnlyStorage) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
 	path, err := blobPath(target.Digest)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, errdef.ErrInvalidDigest)
@@ -68,9 +73,66 @@ func (s *ReadOnlyStorage) Fetch(_ context.Context, target ocispec.Descriptor) (i
 		return nil, err
 	}
 
+	if s.keyWrapper != nil {
+		rc, err := newDecryptingReader(ctx, fp, s.keyWrapper, target)
+		if err != nil {
+			fp.Close()
+			return nil, fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, err)
+		}
+		return rc, nil
+	}
 	return fp, nil
 }
 
+// FetchRange fetches length bytes of the content identified by the
+// descriptor, starting at offset.
+// If the blob file is seekable, only the range is read. For encrypted blob
+// files, the segments from the one containing offset are read and decrypted.
+// Otherwise, the content before offset is read and discarded.
+func (s *ReadOnlyStorage) FetchRange(ctx context.Context, target ocispec.Descriptor, offset, length int64) (io.ReadCloser, error) {
+	if offset < 0 || length < 0 || offset+length > target.Size {
+		return nil, fmt.Errorf("%s: %s: invalid range [%d, %d) of size %d", target.Digest, target.MediaType, offset, offset+length, target.Size)
+	}
+	path, err := blobPath(target.Digest)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, errdef.ErrInvalidDigest)
+	}
+
+	fp, err := s.fsys.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, errdef.ErrNotFound)
+		}
+		return nil, err
+	}
+	rs, ok := fp.(io.ReadSeeker)
+	if !ok {
+		fp.Close()
+		// hide FetchRange to fall back to reading from the beginning
+		return content.FetchRange(ctx, struct{ content.Fetcher }{s}, target, offset, length)
+	}
+
+	var r io.Reader = rs
+	if s.keyWrapper != nil {
+		dr, err := newDecryptingRangeReader(ctx, rs, fp, s.keyWrapper, target, offset)
+		if err != nil {
+			fp.Close()
+			return nil, fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, err)
+		}
+		r = dr
+	} else if _, err := rs.Seek(offset, io.SeekStart); err != nil {
+		fp.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.LimitReader(r, length),
+		Closer: fp,
+	}, nil
+}
+
 // Exists returns true if the described content Exists.
 func (s *ReadOnlyStorage) Exists(_ context.Context, target ocispec.Descriptor) (bool, error) {
 	path, err := blobPath(target.Digest)
@@ -89,9 +151,82 @@ func (s *ReadOnlyStorage) Exists(_ context.Context, target ocispec.Descriptor) (
 	return true, nil
 }
 
+// Blobs calls fn with the descriptors of the blobs in the storage, in the
+// lexical order of the digests. Since the media types of the blobs are not
+// recorded in the layout, the blobs are described as
+// application/octet-stream. Files not named after valid digests are skipped.
+// The sizes of the encrypted blobs are read from the headers of the files.
+func (s *ReadOnlyStorage) Blobs(ctx context.Context, fn func(desc ocispec.Descriptor) error) error {
+	algs, err := fs.ReadDir(s.fsys, "blobs")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	for _, alg := range algs {
+		if !alg.IsDir() {
+			continue
+		}
+		entries, err := fs.ReadDir(s.fsys, path.Join("blobs", alg.Name()))
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !entry.Type().IsRegular() {
+				continue
+			}
+			dgst := digest.NewDigestFromEncoded(digest.Algorithm(alg.Name()), entry.Name())
+			if content.ValidateDigest(dgst) != nil {
+				continue
+			}
+			size, err := s.blobSize(path.Join("blobs", alg.Name(), entry.Name()), entry)
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					// the blob is deleted in the meantime
+					continue
+				}
+				return err
+			}
+			if err := fn(ocispec.Descriptor{
+				MediaType: descriptor.DefaultMediaType,
+				Digest:    dgst,
+				Size:      size,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// blobSize returns the size of the content of the blob file.
+func (s *ReadOnlyStorage) blobSize(name string, entry fs.DirEntry) (int64, error) {
+	if s.keyWrapper == nil {
+		info, err := entry.Info()
+		if err != nil {
+			return 0, err
+		}
+		return info.Size(), nil
+	}
+	fp, err := s.fsys.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer fp.Close()
+	size, err := encryptedBlobSize(fp)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	return size, nil
+}
+
 // blobPath calculates blob path from the given digest.
 func blobPath(dgst digest.Digest) (string, error) {
-	if err := dgst.Validate(); err != nil {
+	if err := content.ValidateDigest(dgst); err != nil {
 		return "", fmt.Errorf("cannot calculate blob path from invalid digest %s: %w: %v",
 			dgst.String(), errdef.ErrInvalidDigest, err)
 	}
@@ -22,6 +22,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"testing/fstest"
@@ -215,3 +216,141 @@ func TestReadOnlyStorage_TarFS(t *testing.T) {
 		t.Errorf("ReadOnlyStorage.Fetch() error = %v, wantErr %v", err, want)
 	}
 }
+
+func TestReadOnlyStorage_Blobs(t *testing.T) {
+	foo := []byte("foo")
+	bar := []byte("bar")
+	fooDigest := digest.FromBytes(foo)
+	barDigest := digest.FromBytes(bar)
+	fsys := fstest.MapFS{
+		"blobs/sha256/" + fooDigest.Encoded():                 {Data: foo},
+		"blobs/sha256/" + barDigest.Encoded():                 {Data: bar},
+		"blobs/sha256/invalid":                                {Data: []byte("invalid")},
+		"blobs/sha256/" + strings.Repeat("0", 64) + "/nested": {Data: []byte("nested")},
+		"blobs/unknown":                                       {Data: []byte("unknown")},
+		ocispec.ImageLayoutFile:                               {Data: []byte(`{"imageLayoutVersion":"1.0.0"}`)},
+	}
+	s := NewStorageFromFS(fsys)
+	ctx := context.Background()
+
+	got := make(map[digest.Digest]int64)
+	if err := s.Blobs(ctx, func(desc ocispec.Descriptor) error {
+		if desc.MediaType != "application/octet-stream" {
+			t.Errorf("ReadOnlyStorage.Blobs() mediaType = %v, want %v", desc.MediaType, "application/octet-stream")
+		}
+		got[desc.Digest] = desc.Size
+		return nil
+	}); err != nil {
+		t.Fatal("ReadOnlyStorage.Blobs() error =", err)
+	}
+	want := map[digest.Digest]int64{
+		fooDigest: int64(len(foo)),
+		barDigest: int64(len(bar)),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadOnlyStorage.Blobs() = %v, want %v", got, want)
+	}
+
+	// test stopping by error
+	errStop := errors.New("stop")
+	count := 0
+	err := s.Blobs(ctx, func(desc ocispec.Descriptor) error {
+		count++
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("ReadOnlyStorage.Blobs() error = %v, want %v", err, errStop)
+	}
+	if count != 1 {
+		t.Errorf("ReadOnlyStorage.Blobs() calls = %d, want %d", count, 1)
+	}
+
+	// test empty storage
+	s = NewStorageFromFS(fstest.MapFS{})
+	if err := s.Blobs(ctx, func(desc ocispec.Descriptor) error {
+		t.Errorf("ReadOnlyStorage.Blobs() unexpected blob %v", desc)
+		return nil
+	}); err != nil {
+		t.Error("ReadOnlyStorage.Blobs() error =", err)
+	}
+}
+
+func TestReadOnlyStorage_FetchRange(t *testing.T) {
+	s, err := NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatal("NewStorage() error =", err)
+	}
+	ctx := context.Background()
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Storage.Push() error =", err)
+	}
+
+	tests := []struct {
+		offset int64
+		length int64
+		want   string
+	}{
+		{0, 5, "hello"},
+		{6, 5, "world"},
+		{4, 3, "o w"},
+		{11, 0, ""},
+	}
+	for _, tt := range tests {
+		rc, err := s.FetchRange(ctx, desc, tt.offset, tt.length)
+		if err != nil {
+			t.Fatalf("ReadOnlyStorage.FetchRange(%d, %d) error = %v", tt.offset, tt.length, err)
+		}
+		got, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal("ReadOnlyStorage.FetchRange().Read() error =", err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("ReadOnlyStorage.FetchRange(%d, %d) = %q, want %q", tt.offset, tt.length, got, tt.want)
+		}
+	}
+
+	// test invalid range
+	if _, err := s.FetchRange(ctx, desc, 6, 6); err == nil {
+		t.Error("ReadOnlyStorage.FetchRange() error = nil, wantErr true")
+	}
+
+	// test non-existing content
+	missing := content.NewDescriptorFromBytes("test", []byte("whatever"))
+	if _, err := s.FetchRange(ctx, missing, 0, 1); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("ReadOnlyStorage.FetchRange() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+}
+
+func TestReadOnlyStorage_FetchRange_TarFS(t *testing.T) {
+	s, err := NewStorageFromTar("testdata/hello-world.tar")
+	if err != nil {
+		t.Fatal("NewStorageFromTar() error =", err)
+	}
+	ctx := context.Background()
+
+	// test data in testdata/hello-world.tar, which is not seekable
+	desc := ocispec.Descriptor{
+		MediaType: docker.MediaTypeManifest,
+		Digest:    "sha256:f54a58bc1aac5ea1a25d796ae155dc228b3f0e11d046ae276b39c4bf2f13d8c4",
+		Size:      525,
+	}
+	full, err := content.FetchAll(ctx, s, desc)
+	if err != nil {
+		t.Fatal("ReadOnlyStorage.Fetch() error =", err)
+	}
+	rc, err := s.FetchRange(ctx, desc, 100, 50)
+	if err != nil {
+		t.Fatal("ReadOnlyStorage.FetchRange() error =", err)
+	}
+	defer rc.Close()
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal("ReadOnlyStorage.FetchRange().Read() error =", err)
+	}
+	if want := full[100:150]; !bytes.Equal(got, want) {
+		t.Errorf("ReadOnlyStorage.FetchRange() = %q, want %q", got, want)
+	}
+}
@@ -20,27 +20,16 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
-	"sync"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/internal/ioutil"
+	"oras.land/oras-go/v2/internal/pool"
 )
 
-// bufPool is a pool of byte buffers that can be reused for copying content
-// between files.
-var bufPool = sync.Pool{
-	New: func() interface{} {
-		// the buffer size should be larger than or equal to 128 KiB
-		// for performance considerations.
-		// we choose 1 MiB here so there will be less disk I/O.
-		buffer := make([]byte, 1<<20) // buffer size = 1 MiB
-		return &buffer
-	},
-}
-
 // Storage is a CAS based on file system with the OCI-Image layout.
 // Reference: https://github.com/opencontainers/image-spec/blob/v1.1.0-rc2/image-layout.md
 type Storage struct {
@@ -65,8 +54,26 @@ func NewStorage(root string) (*Storage, error) {
 	}, nil
 }
 
+// NewEncryptedStorage creates a new CAS based on file system with the
+// OCI-Image layout, where the blob files are encrypted at rest with AES-GCM.
+// Each blob is encrypted with a random data key wrapped by wrapper, while
+// still being addressed by the digest of its plaintext.
+// The encrypted blob files are not readable by other OCI layout
+// implementations.
+func NewEncryptedStorage(root string, wrapper KeyWrapper) (*Storage, error) {
+	if wrapper == nil {
+		return nil, errors.New("nil key wrapper")
+	}
+	s, err := NewStorage(root)
+	if err != nil {
+		return nil, err
+	}
+	s.keyWrapper = wrapper
+	return s, nil
+}
+
 // Push pushes the content, matching the expected descriptor.
-func (s *Storage) Push(_ context.Context, expected ocispec.Descriptor, content io.Reader) error {
+func (s *Storage) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
 	path, err := blobPath(expected.Digest)
 	if err != nil {
 		return fmt.Errorf("%s: %s: %w", expected.Digest, expected.MediaType, errdef.ErrInvalidDigest)
@@ -85,7 +92,7 @@ func (s *Storage) Push(_ context.Context, expected ocispec.Descriptor, content i
 	}
 
 	// write the content to a temporary ingest file.
-	ingest, err := s.ingest(expected, content)
+	ingest, err := s.ingest(ctx, expected, content)
 	if err != nil {
 		return err
 	}
@@ -106,8 +113,25 @@ func (s *Storage) Push(_ context.Context, expected ocispec.Descriptor, content i
 	return nil
 }
 
+// Delete removes the content identified by the descriptor.
+// Returns ErrNotFound if the content does not exist.
+func (s *Storage) Delete(_ context.Context, target ocispec.Descriptor) error {
+	path, err := blobPath(target.Digest)
+	if err != nil {
+		return fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, errdef.ErrInvalidDigest)
+	}
+	targetPath := filepath.Join(s.root, path)
+	if err := os.Remove(targetPath); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, errdef.ErrNotFound)
+		}
+		return err
+	}
+	return nil
+}
+
 // ingest write the content into a temporary ingest file.
-func (s *Storage) ingest(expected ocispec.Descriptor, content io.Reader) (path string, ingestErr error) {
+func (s *Storage) ingest(ctx context.Context, expected ocispec.Descriptor, content io.Reader) (path string, ingestErr error) {
 	if err := ensureDir(s.ingestRoot); err != nil {
 		return "", fmt.Errorf("failed to ensure ingest dir: %w", err)
 	}
@@ -131,11 +155,26 @@ func (s *Storage) ingest(expected ocispec.Descriptor, content io.Reader) (path s
 	}()
 	defer fp.Close()
 
-	buf := bufPool.Get().(*[]byte)
-	defer bufPool.Put(buf)
-	if err := ioutil.CopyBuffer(fp, content, *buf, expected); err != nil {
+	var w io.Writer = fp
+	var ew io.WriteCloser
+	if s.keyWrapper != nil {
+		if ew, err = newEncryptingWriter(ctx, fp, s.keyWrapper, expected); err != nil {
+			return "", fmt.Errorf("failed to encrypt: %w", err)
+		}
+		w = ew
+	}
+
+	buf := pool.File.Get()
+	defer pool.File.Put(buf)
+	if err := ioutil.CopyBuffer(w, content, *buf, expected); err != nil {
 		return "", fmt.Errorf("failed to ingest: %w", err)
 	}
+	if ew != nil {
+		// seal the last segment
+		if err := ew.Close(); err != nil {
+			return "", fmt.Errorf("failed to encrypt: %w", err)
+		}
+	}
 
 	// change to readonly
 	if err := os.Chmod(path, 0444); err != nil {
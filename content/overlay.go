@@ -0,0 +1,127 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+)
+
+// OverlayStorage represents a CAS reading from an ordered set of storages and
+// writing to the upper one, such as a per-tenant storage layered over a
+// shared pool of base layers.
+type OverlayStorage struct {
+	// Upper is the storage receiving the pushed content. It is read before
+	// the lower storages.
+	Upper Storage
+	// Lower are the read-only storages, read in order after Upper.
+	Lower []ReadOnlyStorage
+}
+
+// NewOverlayStorage returns a storage reading from upper and then from lower
+// in order, and writing to upper.
+func NewOverlayStorage(upper Storage, lower ...ReadOnlyStorage) *OverlayStorage {
+	return &OverlayStorage{
+		Upper: upper,
+		Lower: lower,
+	}
+}
+
+// layers returns the storages in the reading order.
+func (s *OverlayStorage) layers() []ReadOnlyStorage {
+	layers := make([]ReadOnlyStorage, 0, len(s.Lower)+1)
+	layers = append(layers, s.Upper)
+	return append(layers, s.Lower...)
+}
+
+// Fetch fetches the content identified by the descriptor from the first
+// storage holding it.
+func (s *OverlayStorage) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	for _, layer := range s.layers() {
+		rc, err := layer.Fetch(ctx, target)
+		if err == nil {
+			return rc, nil
+		}
+		if !errors.Is(err, errdef.ErrNotFound) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, errdef.ErrNotFound)
+}
+
+// Push pushes the content, matching the expected descriptor, to the upper
+// storage. Content existing only in the lower storages is pushed to the upper
+// storage as well; check Exists beforehand to avoid duplicating it.
+func (s *OverlayStorage) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	return s.Upper.Push(ctx, expected, content)
+}
+
+// Exists returns true if the described content exists in any of the
+// storages.
+func (s *OverlayStorage) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	for _, layer := range s.layers() {
+		exists, err := layer.Exists(ctx, target)
+		if err != nil {
+			return false, err
+		}
+		if exists {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Predecessors returns the nodes directly pointing to the current node in any
+// of the storages supporting predecessor finding, without duplicates.
+func (s *OverlayStorage) Predecessors(ctx context.Context, node ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	var predecessors []ocispec.Descriptor
+	visited := make(map[descriptor.Descriptor]bool)
+	for _, layer := range s.layers() {
+		finder, ok := layer.(PredecessorFinder)
+		if !ok {
+			continue
+		}
+		nodes, err := finder.Predecessors(ctx, node)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range nodes {
+			key := descriptor.FromOCI(n)
+			if !visited[key] {
+				visited[key] = true
+				predecessors = append(predecessors, n)
+			}
+		}
+	}
+	return predecessors, nil
+}
+
+// Delete removes the content identified by the descriptor from the upper
+// storage. The lower storages are never modified.
+// Returns ErrUnsupported if the upper storage does not support deletion.
+func (s *OverlayStorage) Delete(ctx context.Context, target ocispec.Descriptor) error {
+	deleter, ok := s.Upper.(Deleter)
+	if !ok {
+		return fmt.Errorf("%s: %s: delete: %w", target.Digest, target.MediaType, errdef.ErrUnsupported)
+	}
+	return deleter.Delete(ctx, target)
+}
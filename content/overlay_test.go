@@ -0,0 +1,134 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestOverlayStorage(t *testing.T) {
+	ctx := context.Background()
+	push := func(s content.Pusher, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+		if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal("Push() error =", err)
+		}
+		return desc
+	}
+	base := memory.New()
+	shared := memory.New()
+	tenant := memory.New()
+	baseDesc := push(base, []byte("base"))
+	sharedDesc := push(shared, []byte("shared"))
+	push(base, []byte("both"))
+	bothDesc := push(shared, []byte("both"))
+
+	s := content.NewOverlayStorage(tenant, base, shared)
+	for _, desc := range []ocispec.Descriptor{baseDesc, sharedDesc, bothDesc} {
+		if exists, err := s.Exists(ctx, desc); err != nil || !exists {
+			t.Errorf("Exists(%s) = %v, %v, want true", desc.Digest, exists, err)
+		}
+		if _, err := content.FetchAll(ctx, s, desc); err != nil {
+			t.Errorf("FetchAll(%s) error = %v", desc.Digest, err)
+		}
+	}
+
+	// pushes go to the upper storage
+	tenantDesc := push(s, []byte("tenant"))
+	if exists, err := tenant.Exists(ctx, tenantDesc); err != nil || !exists {
+		t.Errorf("upper Exists() = %v, %v, want true", exists, err)
+	}
+	for _, lower := range []*memory.Store{base, shared} {
+		if exists, err := lower.Exists(ctx, tenantDesc); err != nil || exists {
+			t.Errorf("lower Exists() = %v, %v, want false", exists, err)
+		}
+	}
+	if got, err := content.FetchAll(ctx, s, tenantDesc); err != nil || string(got) != "tenant" {
+		t.Errorf("FetchAll() = %q, %v, want %q", got, err, "tenant")
+	}
+
+	missing := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, []byte("missing"))
+	if exists, err := s.Exists(ctx, missing); err != nil || exists {
+		t.Errorf("Exists() = %v, %v, want false", exists, err)
+	}
+	if _, err := s.Fetch(ctx, missing); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Fetch() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+
+	// deletion is applied to the upper storage only
+	if err := s.Delete(ctx, baseDesc); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Delete() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+	if err := s.Delete(ctx, tenantDesc); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if exists, err := s.Exists(ctx, tenantDesc); err != nil || exists {
+		t.Errorf("Exists() = %v, %v, want false", exists, err)
+	}
+}
+
+func TestOverlayStorage_Predecessors(t *testing.T) {
+	ctx := context.Background()
+	layer := []byte("layer")
+	layerDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+	pushManifest := func(s content.Pusher, annotation string) ocispec.Descriptor {
+		manifestJSON, err := json.Marshal(ocispec.Manifest{
+			MediaType:   ocispec.MediaTypeImageManifest,
+			Config:      layerDesc,
+			Layers:      []ocispec.Descriptor{layerDesc},
+			Annotations: map[string]string{"name": annotation},
+		})
+		if err != nil {
+			t.Fatal("json.Marshal() error =", err)
+		}
+		desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifestJSON)
+		if err := s.Push(ctx, desc, bytes.NewReader(manifestJSON)); err != nil {
+			t.Fatal("Push() error =", err)
+		}
+		return desc
+	}
+	lower := memory.New()
+	if err := lower.Push(ctx, layerDesc, bytes.NewReader(layer)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	lowerManifest := pushManifest(lower, "lower")
+	upper := memory.New()
+	s := content.NewOverlayStorage(upper, lower)
+	upperManifest := pushManifest(s, "upper")
+	// the same manifest in both storages is reported once
+	pushManifest(upper, "lower")
+
+	predecessors, err := s.Predecessors(ctx, layerDesc)
+	if err != nil {
+		t.Fatal("Predecessors() error =", err)
+	}
+	got := make(map[string]int)
+	for _, desc := range predecessors {
+		got[desc.Digest.String()]++
+	}
+	if len(predecessors) != 2 || got[lowerManifest.Digest.String()] != 1 || got[upperManifest.Digest.String()] != 1 {
+		t.Errorf("Predecessors() = %v, want [%v %v]", predecessors, upperManifest.Digest, lowerManifest.Digest)
+	}
+}
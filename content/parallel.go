@@ -0,0 +1,197 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// defaultParallelConcurrency is the default number of ranges fetched
+	// concurrently by FetchParallel.
+	defaultParallelConcurrency = 4
+
+	// defaultParallelChunkSize is the default size of the ranges fetched by
+	// FetchParallel.
+	defaultParallelChunkSize int64 = 16 * 1024 * 1024 // 16 MiB
+)
+
+// ParallelFetchOptions contains parameters for FetchParallel.
+type ParallelFetchOptions struct {
+	// Concurrency limits the maximum number of ranges fetched concurrently,
+	// including the fetched ranges not yet read.
+	// If less than or equal to 0, a default (currently 4) is used.
+	Concurrency int
+
+	// ChunkSize is the size of each fetched range. The memory used by
+	// FetchParallel is bounded by Concurrency * ChunkSize.
+	// If less than or equal to 0, a default (currently 16MiB) is used.
+	ChunkSize int64
+}
+
+// FetchParallel fetches the content identified by the descriptor by fetching
+// several ranges of it concurrently, and reassembles the ranges in order.
+// The reassembled content is verified against the size and the digest of the
+// descriptor, and the returned reader fails at the end of the content if the
+// verification fails.
+//
+// FetchParallel improves the throughput against servers capping the speed of
+// each connection, such as CDNs. Closing the returned reader cancels the
+// ongoing fetches.
+func FetchParallel(ctx context.Context, fetcher RangeFetcher, target ocispec.Descriptor, opts ParallelFetchOptions) (io.ReadCloser, error) {
+	if target.Size < 0 {
+		return nil, fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, ErrInvalidDescriptorSize)
+	}
+	if _, err := NewVerifier(target.Digest); err != nil {
+		return nil, err
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultParallelConcurrency
+	}
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = defaultParallelChunkSize
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	pr := &parallelReader{
+		cancel: cancel,
+		tokens: make(chan struct{}, opts.Concurrency),
+	}
+	for offset := int64(0); offset < target.Size; offset += opts.ChunkSize {
+		length := target.Size - offset
+		if length > opts.ChunkSize {
+			length = opts.ChunkSize
+		}
+		pr.chunks = append(pr.chunks, &parallelChunk{
+			offset: offset,
+			length: length,
+			done:   make(chan struct{}),
+		})
+	}
+	go pr.fetchAll(ctx, fetcher, target)
+	pr.verifier = NewVerifyReader(readerFunc(pr.read), target)
+	return pr, nil
+}
+
+// parallelChunk is a range of the content fetched by FetchParallel.
+type parallelChunk struct {
+	offset int64
+	length int64
+	data   []byte
+	err    error
+	done   chan struct{}
+}
+
+// parallelReader reassembles the chunks fetched concurrently.
+type parallelReader struct {
+	cancel   context.CancelFunc
+	tokens   chan struct{}
+	chunks   []*parallelChunk
+	current  *bytes.Reader
+	index    int
+	verifier *VerifyReader
+}
+
+// fetchAll fetches the chunks in order, limiting the number of chunks fetched
+// but not yet read by the size of the tokens channel.
+func (pr *parallelReader) fetchAll(ctx context.Context, fetcher RangeFetcher, target ocispec.Descriptor) {
+	for _, chunk := range pr.chunks {
+		select {
+		case pr.tokens <- struct{}{}:
+		case <-ctx.Done():
+			chunk.err = ctx.Err()
+			close(chunk.done)
+			continue
+		}
+		go func(chunk *parallelChunk) {
+			defer close(chunk.done)
+			chunk.data, chunk.err = fetchChunk(ctx, fetcher, target, chunk.offset, chunk.length)
+		}(chunk)
+	}
+}
+
+// fetchChunk fetches exactly length bytes starting at offset.
+func fetchChunk(ctx context.Context, fetcher RangeFetcher, target ocispec.Descriptor, offset, length int64) ([]byte, error) {
+	rc, err := fetcher.FetchRange(ctx, target, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data := make([]byte, length)
+	if _, err := io.ReadFull(rc, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("%s: %s: failed to fetch range [%d, %d): %w", target.Digest, target.MediaType, offset, offset+length, err)
+	}
+	if err := ensureEOF(rc); err != nil {
+		return nil, fmt.Errorf("%s: %s: failed to fetch range [%d, %d): %w", target.Digest, target.MediaType, offset, offset+length, err)
+	}
+	return data, nil
+}
+
+// Read reads the reassembled content, and verifies it at the end.
+func (pr *parallelReader) Read(p []byte) (int, error) {
+	n, err := pr.verifier.Read(p)
+	if err == io.EOF {
+		if verr := pr.verifier.Verify(); verr != nil {
+			return n, verr
+		}
+	}
+	return n, err
+}
+
+// read reads the chunks in order, waiting for them to be fetched.
+func (pr *parallelReader) read(p []byte) (int, error) {
+	for pr.current == nil || pr.current.Len() == 0 {
+		if pr.current != nil {
+			// release the token of the consumed chunk
+			pr.chunks[pr.index-1].data = nil
+			pr.current = nil
+			<-pr.tokens
+		}
+		if pr.index >= len(pr.chunks) {
+			return 0, io.EOF
+		}
+		chunk := pr.chunks[pr.index]
+		<-chunk.done
+		if chunk.err != nil {
+			return 0, chunk.err
+		}
+		pr.current = bytes.NewReader(chunk.data)
+		pr.index++
+	}
+	return pr.current.Read(p)
+}
+
+// Close cancels the ongoing fetches.
+func (pr *parallelReader) Close() error {
+	pr.cancel()
+	return nil
+}
+
+// readerFunc adapts a function to io.Reader.
+type readerFunc func(p []byte) (int, error)
+
+// Read calls f(p).
+func (f readerFunc) Read(p []byte) (int, error) {
+	return f(p)
+}
@@ -0,0 +1,183 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// rangeStorage serves ranges of blobs, recording the requested ranges.
+type rangeStorage struct {
+	blob    []byte
+	corrupt bool
+	fail    int64
+
+	mu     sync.Mutex
+	ranges [][2]int64
+}
+
+func (s *rangeStorage) FetchRange(_ context.Context, target ocispec.Descriptor, offset, length int64) (io.ReadCloser, error) {
+	s.mu.Lock()
+	s.ranges = append(s.ranges, [2]int64{offset, length})
+	s.mu.Unlock()
+	if s.fail > 0 && offset == s.fail {
+		return nil, fmt.Errorf("range %d: injected failure", offset)
+	}
+	data := append([]byte(nil), s.blob[offset:offset+length]...)
+	if s.corrupt && offset == 0 {
+		data[0] ^= 0xff
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func TestFetchParallel(t *testing.T) {
+	ctx := context.Background()
+	blob := bytes.Repeat([]byte("0123456789"), 100)
+	desc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+	}
+	s := &rangeStorage{blob: blob}
+	rc, err := content.FetchParallel(ctx, s, desc, content.ParallelFetchOptions{
+		Concurrency: 3,
+		ChunkSize:   64,
+	})
+	if err != nil {
+		t.Fatalf("FetchParallel() error = %v", err)
+	}
+	defer rc.Close()
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("FetchParallel() = %q, want %q", got, blob)
+	}
+	if want := 16; len(s.ranges) != want {
+		t.Errorf("FetchParallel() fetched %d ranges, want %d", len(s.ranges), want)
+	}
+	var total int64
+	for _, r := range s.ranges {
+		if r[1] > 64 {
+			t.Errorf("FetchParallel() fetched range %v larger than chunk size", r)
+		}
+		total += r[1]
+	}
+	if total != desc.Size {
+		t.Errorf("FetchParallel() fetched %d bytes, want %d", total, desc.Size)
+	}
+}
+
+func TestFetchParallel_Empty(t *testing.T) {
+	desc := content.NewDescriptorFromBytes("test", nil)
+	rc, err := content.FetchParallel(context.Background(), &rangeStorage{}, desc, content.ParallelFetchOptions{})
+	if err != nil {
+		t.Fatalf("FetchParallel() error = %v", err)
+	}
+	defer rc.Close()
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("FetchParallel() = %q, want empty", got)
+	}
+}
+
+func TestFetchParallel_MismatchedDigest(t *testing.T) {
+	blob := bytes.Repeat([]byte("a"), 100)
+	desc := content.NewDescriptorFromBytes("test", blob)
+	s := &rangeStorage{blob: blob, corrupt: true}
+	rc, err := content.FetchParallel(context.Background(), s, desc, content.ParallelFetchOptions{
+		ChunkSize: 30,
+	})
+	if err != nil {
+		t.Fatalf("FetchParallel() error = %v", err)
+	}
+	defer rc.Close()
+	if _, err := io.ReadAll(rc); !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("ReadAll() error = %v, want %v", err, content.ErrMismatchedDigest)
+	}
+}
+
+func TestFetchParallel_RangeFailure(t *testing.T) {
+	blob := bytes.Repeat([]byte("a"), 100)
+	desc := content.NewDescriptorFromBytes("test", blob)
+	s := &rangeStorage{blob: blob, fail: 60}
+	rc, err := content.FetchParallel(context.Background(), s, desc, content.ParallelFetchOptions{
+		Concurrency: 2,
+		ChunkSize:   30,
+	})
+	if err != nil {
+		t.Fatalf("FetchParallel() error = %v", err)
+	}
+	defer rc.Close()
+	got, err := io.ReadAll(rc)
+	if err == nil {
+		t.Fatal("ReadAll() error = nil, want error")
+	}
+	if !bytes.Equal(got, blob[:60]) {
+		t.Errorf("ReadAll() = %q, want %q", got, blob[:60])
+	}
+}
+
+func TestFetchParallel_Close(t *testing.T) {
+	blob := bytes.Repeat([]byte("a"), 1000)
+	desc := content.NewDescriptorFromBytes("test", blob)
+	s := &rangeStorage{blob: blob}
+	rc, err := content.FetchParallel(context.Background(), s, desc, content.ParallelFetchOptions{
+		Concurrency: 2,
+		ChunkSize:   10,
+	})
+	if err != nil {
+		t.Fatalf("FetchParallel() error = %v", err)
+	}
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(rc, buf); err != nil {
+		t.Fatalf("ReadFull() error = %v", err)
+	}
+	if err := rc.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// at most the ranges in flight and the unread ranges are fetched
+	if n := len(s.ranges); n > 4 {
+		t.Errorf("FetchParallel() fetched %d ranges after Close(), want at most 4", n)
+	}
+}
+
+func TestFetchParallel_InvalidDigest(t *testing.T) {
+	desc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    "invalid",
+		Size:      10,
+	}
+	if _, err := content.FetchParallel(context.Background(), &rangeStorage{}, desc, content.ParallelFetchOptions{}); err == nil {
+		t.Error("FetchParallel() error = nil, want error")
+	}
+}
@@ -99,7 +99,13 @@ func (vr *VerifyReader) Verify() error {
 
 // NewVerifyReader wraps r for reading content with verification against desc.
 func NewVerifyReader(r io.Reader, desc ocispec.Descriptor) *VerifyReader {
-	verifier := desc.Digest.Verifier()
+	verifier, err := NewVerifier(desc.Digest)
+	if err != nil {
+		return &VerifyReader{
+			base: &io.LimitedReader{R: r, N: desc.Size},
+			err:  err,
+		}
+	}
 	lr := &io.LimitedReader{
 		R: io.TeeReader(r, verifier),
 		N: desc.Size,
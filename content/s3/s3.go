@@ -0,0 +1,476 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package s3 provides a content store backed by S3-compatible object storage,
+// so that content can be copied between registries and buckets.
+//
+// Blobs are stored as objects keyed by digest, in the same layout as the
+// blobs of an OCI image layout: <prefix>/blobs/<alg>/<encoded>.
+// Requests are signed with AWS Signature Version 4.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+const (
+	// DefaultRegion is the default region used to sign requests.
+	DefaultRegion = "us-east-1"
+	// DefaultPartSize is the default size of the parts of multipart uploads.
+	DefaultPartSize = 16 * 1024 * 1024
+	// MinPartSize is the minimum size of the parts of multipart uploads
+	// allowed by S3, except for the last part.
+	MinPartSize = 5 * 1024 * 1024
+	// MaxParts is the maximum number of parts of a multipart upload allowed
+	// by S3.
+	MaxParts = 10000
+)
+
+// Store is a content store backed by an S3 bucket.
+// Buckets are accessed in the path style, i.e. <endpoint>/<bucket>/<key>.
+//
+// Blobs not larger than PartSize are buffered in memory and uploaded in a
+// single request. Larger blobs are uploaded in parts using multipart upload,
+// which is completed only if the uploaded content matches the descriptor.
+type Store struct {
+	// Client is the HTTP client used to access the bucket.
+	// If nil, http.DefaultClient is used.
+	Client *http.Client
+	// Credentials are the credentials used to sign requests.
+	Credentials Credentials
+	// Region is the region of the bucket used to sign requests.
+	// If empty, DefaultRegion is used.
+	Region string
+	// Prefix is the key prefix of the stored blobs.
+	Prefix string
+	// PartSize is the size of the parts of multipart uploads.
+	// If less than or equal to 0, DefaultPartSize is used. PartSize is
+	// increased if needed to fit a blob in MaxParts parts.
+	PartSize int64
+
+	// endpoint is the service endpoint.
+	endpoint *url.URL
+	// bucket is the bucket name.
+	bucket string
+}
+
+// New creates a Store accessing the bucket at the endpoint, such as
+// "https://s3.us-east-1.amazonaws.com" or "http://localhost:9000".
+func New(endpoint, bucket string) (*Store, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid endpoint %q: unsupported scheme %q", endpoint, u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q: missing host", endpoint)
+	}
+	if bucket == "" {
+		return nil, errors.New("missing bucket name")
+	}
+	return &Store{
+		endpoint: u,
+		bucket:   bucket,
+	}, nil
+}
+
+// Fetch fetches the content identified by the descriptor.
+func (s *Store) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	key, err := s.blobKey(target)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if resp.ContentLength != -1 && resp.ContentLength != target.Size {
+			resp.Body.Close()
+			return nil, fmt.Errorf("%s: %s: mismatch Content-Length %d: %w", target.Digest, target.MediaType, resp.ContentLength, content.ErrInvalidDescriptorSize)
+		}
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, errdef.ErrNotFound)
+	default:
+		defer resp.Body.Close()
+		return nil, parseError(resp)
+	}
+}
+
+// FetchRange fetches length bytes of the content identified by the
+// descriptor, starting at offset, using a ranged GET request.
+func (s *Store) FetchRange(ctx context.Context, target ocispec.Descriptor, offset, length int64) (io.ReadCloser, error) {
+	if offset < 0 || length < 0 || offset+length > target.Size {
+		return nil, fmt.Errorf("%s: %s: invalid range [%d, %d) of size %d", target.Digest, target.MediaType, offset, offset+length, target.Size)
+	}
+	key, err := s.blobKey(target)
+	if err != nil {
+		return nil, err
+	}
+	if length == 0 {
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+	header := http.Header{
+		"Range": {fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)},
+	}
+	resp, err := s.do(ctx, http.MethodGet, key, nil, header, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		if resp.ContentLength != -1 && resp.ContentLength != length {
+			resp.Body.Close()
+			return nil, fmt.Errorf("%s: %s: mismatch Content-Length %d for range of length %d", target.Digest, target.MediaType, resp.ContentLength, length)
+		}
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, errdef.ErrNotFound)
+	default:
+		defer resp.Body.Close()
+		return nil, parseError(resp)
+	}
+}
+
+// Exists returns true if the described content exists.
+func (s *Store) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	key, err := s.blobKey(target)
+	if err != nil {
+		return false, err
+	}
+	resp, err := s.do(ctx, http.MethodHead, key, nil, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, parseError(resp)
+	}
+}
+
+// Push pushes the content, matching the expected descriptor.
+// The content is verified before the upload is completed so that no
+// mismatched content is stored.
+func (s *Store) Push(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	key, err := s.blobKey(expected)
+	if err != nil {
+		return err
+	}
+	exists, err := s.Exists(ctx, expected)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("%s: %s: %w", expected.Digest, expected.MediaType, errdef.ErrAlreadyExists)
+	}
+
+	partSize := s.partSize(expected.Size)
+	vr := content.NewVerifyReader(r, expected)
+	if expected.Size <= partSize {
+		buf := make([]byte, expected.Size)
+		if _, err := io.ReadFull(vr, buf); err != nil {
+			return fmt.Errorf("%s: %s: read failed: %w", expected.Digest, expected.MediaType, err)
+		}
+		if err := vr.Verify(); err != nil {
+			return fmt.Errorf("%s: %s: %w", expected.Digest, expected.MediaType, err)
+		}
+		resp, err := s.do(ctx, http.MethodPut, key, nil, nil, buf)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return parseError(resp)
+		}
+		return nil
+	}
+	return s.pushMultipart(ctx, key, expected, vr, partSize)
+}
+
+// pushMultipart uploads the content in parts, and completes the upload only
+// if the content is verified. The upload is aborted on failure.
+func (s *Store) pushMultipart(ctx context.Context, key string, expected ocispec.Descriptor, vr *content.VerifyReader, partSize int64) (err error) {
+	uploadID, err := s.createMultipartUpload(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			// best effort to release the uploaded parts
+			s.abortMultipartUpload(context.Background(), key, uploadID)
+		}
+	}()
+
+	var parts []completedPart
+	buf := make([]byte, partSize)
+	for remaining := expected.Size; remaining > 0; {
+		n := partSize
+		if remaining < n {
+			n = remaining
+		}
+		if _, err := io.ReadFull(vr, buf[:n]); err != nil {
+			return fmt.Errorf("%s: %s: read failed: %w", expected.Digest, expected.MediaType, err)
+		}
+		partNumber := len(parts) + 1
+		etag, err := s.uploadPart(ctx, key, uploadID, partNumber, buf[:n])
+		if err != nil {
+			return err
+		}
+		parts = append(parts, completedPart{PartNumber: partNumber, ETag: etag})
+		remaining -= n
+	}
+	if err := vr.Verify(); err != nil {
+		return fmt.Errorf("%s: %s: %w", expected.Digest, expected.MediaType, err)
+	}
+	return s.completeMultipartUpload(ctx, key, uploadID, parts)
+}
+
+// Delete removes the content identified by the descriptor.
+// Returns ErrNotFound if the content does not exist.
+func (s *Store) Delete(ctx context.Context, target ocispec.Descriptor) error {
+	key, err := s.blobKey(target)
+	if err != nil {
+		return err
+	}
+	exists, err := s.Exists(ctx, target)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, errdef.ErrNotFound)
+	}
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return parseError(resp)
+	}
+	return nil
+}
+
+// initiateMultipartUploadResult is the response of CreateMultipartUpload.
+type initiateMultipartUploadResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+// completedPart is an uploaded part of a multipart upload.
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// completeMultipartUpload is the request body of CompleteMultipartUpload.
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+// createMultipartUpload initiates a multipart upload and returns its ID.
+func (s *Store) createMultipartUpload(ctx context.Context, key string) (string, error) {
+	resp, err := s.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", parseError(resp)
+	}
+	var result initiateMultipartUploadResult
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode multipart upload: %w", err)
+	}
+	if result.UploadID == "" {
+		return "", errors.New("missing multipart upload ID")
+	}
+	return result.UploadID, nil
+}
+
+// uploadPart uploads a part of a multipart upload and returns its ETag.
+func (s *Store) uploadPart(ctx context.Context, key, uploadID string, partNumber int, data []byte) (string, error) {
+	query := url.Values{
+		"partNumber": {strconv.Itoa(partNumber)},
+		"uploadId":   {uploadID},
+	}
+	resp, err := s.do(ctx, http.MethodPut, key, query, nil, data)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", parseError(resp)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		return "", fmt.Errorf("missing ETag of part %d", partNumber)
+	}
+	return etag, nil
+}
+
+// completeMultipartUpload completes a multipart upload with the parts.
+func (s *Store) completeMultipartUpload(ctx context.Context, key, uploadID string, parts []completedPart) error {
+	body, err := xml.Marshal(completeMultipartUpload{Parts: parts})
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadID}}, nil, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return parseError(resp)
+	}
+	// CompleteMultipartUpload may fail after responding 200 OK
+	// Reference: https://docs.aws.amazon.com/AmazonS3/latest/API/API_CompleteMultipartUpload.html
+	return parseError(resp)
+}
+
+// abortMultipartUpload aborts a multipart upload.
+func (s *Store) abortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return parseError(resp)
+	}
+	return nil
+}
+
+// do sends a signed request on the object identified by key.
+func (s *Store) do(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	u := *s.endpoint
+	u.RawQuery = ""
+	u.Fragment = ""
+	u.RawPath = strings.TrimSuffix(u.EscapedPath(), "/") + "/" + escape(s.bucket, true) + "/" + escape(key, false)
+	var err error
+	if u.Path, err = url.PathUnescape(u.RawPath); err != nil {
+		return nil, err
+	}
+	if len(query) > 0 {
+		pairs := make([]string, 0, len(query))
+		for k, values := range query {
+			for _, v := range values {
+				pairs = append(pairs, escape(k, true)+"="+escape(v, true))
+			}
+		}
+		u.RawQuery = strings.Join(pairs, "&")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	if len(body) == 0 {
+		req.Body = http.NoBody
+	}
+	for k, values := range header {
+		req.Header[k] = values
+	}
+	payloadHash := emptyPayloadHash
+	if len(body) > 0 {
+		payloadHash = hashHex(body)
+	}
+	region := s.Region
+	if region == "" {
+		region = DefaultRegion
+	}
+	signRequest(req, s.Credentials, region, payloadHash, time.Now())
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+// blobKey returns the object key of the blob.
+func (s *Store) blobKey(desc ocispec.Descriptor) (string, error) {
+	if err := content.ValidateDigest(desc.Digest); err != nil {
+		return "", fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, errdef.ErrInvalidDigest)
+	}
+	key := path.Join(s.Prefix, "blobs", desc.Digest.Algorithm().String(), desc.Digest.Encoded())
+	return strings.TrimPrefix(key, "/"), nil
+}
+
+// partSize returns the part size for a blob of the size.
+func (s *Store) partSize(size int64) int64 {
+	partSize := s.PartSize
+	if partSize <= 0 {
+		partSize = DefaultPartSize
+	}
+	if partSize < MinPartSize && size > partSize {
+		partSize = MinPartSize
+	}
+	if minSize := (size + MaxParts - 1) / MaxParts; partSize < minSize {
+		partSize = minSize
+	}
+	return partSize
+}
+
+// s3Error is an error response of S3.
+type s3Error struct {
+	XMLName xml.Name `xml:"Error"`
+	Code    string   `xml:"Code"`
+	Message string   `xml:"Message"`
+}
+
+// parseError returns the error in the response. It returns nil if the
+// response status code indicates success and the body carries no error.
+func parseError(resp *http.Response) error {
+	const maxErrorBytes = 64 * 1024
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBytes))
+	if err != nil {
+		return fmt.Errorf("%s %q: failed to read response: %w", resp.Request.Method, resp.Request.URL, err)
+	}
+	var e s3Error
+	if xmlErr := xml.Unmarshal(data, &e); xmlErr == nil && e.Code != "" {
+		return fmt.Errorf("%s %q: response status code %d: %s: %s", resp.Request.Method, resp.Request.URL, resp.StatusCode, e.Code, e.Message)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return fmt.Errorf("%s %q: response status code %d: %s", resp.Request.Method, resp.Request.URL, resp.StatusCode, http.StatusText(resp.StatusCode))
+}
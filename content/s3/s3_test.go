@@ -0,0 +1,358 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+var testCredentials = Credentials{
+	AccessKeyID:     "access",
+	SecretAccessKey: "secret",
+	SessionToken:    "token",
+}
+
+// fakeS3 is a minimal S3 server serving a single bucket.
+type fakeS3 struct {
+	t      *testing.T
+	bucket string
+	region string
+
+	lock    sync.Mutex
+	objects map[string][]byte
+	uploads map[string]map[int][]byte
+	nextID  int
+	parts   int
+}
+
+func newFakeS3(t *testing.T, bucket, region string) *fakeS3 {
+	return &fakeS3{
+		t:       t,
+		bucket:  bucket,
+		region:  region,
+		objects: make(map[string][]byte),
+		uploads: make(map[string]map[int][]byte),
+	}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		f.t.Error("failed to read request body:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if !f.verifySignature(r, body) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, "<Error><Code>SignatureDoesNotMatch</Code><Message>bad signature</Message></Error>")
+		return
+	}
+	prefix := "/" + f.bucket + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "<Error><Code>NoSuchBucket</Code></Error>")
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, prefix)
+	query := r.URL.Query()
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		f.nextID++
+		id := strconv.Itoa(f.nextID)
+		f.uploads[id] = make(map[int][]byte)
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><Key>%s</Key><UploadId>%s</UploadId></InitiateMultipartUploadResult>", key, id)
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		parts, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		parts[number] = body
+		f.parts++
+		w.Header().Set("ETag", strconv.Quote(hashHex(body)))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		parts, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var complete completeMultipartUpload
+		if err := xml.Unmarshal(body, &complete); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var data []byte
+		for i, part := range complete.Parts {
+			if part.PartNumber != i+1 || part.ETag != strconv.Quote(hashHex(parts[part.PartNumber])) {
+				fmt.Fprint(w, "<Error><Code>InvalidPart</Code></Error>")
+				return
+			}
+			data = append(data, parts[part.PartNumber]...)
+		}
+		delete(f.uploads, query.Get("uploadId"))
+		f.objects[key] = data
+		fmt.Fprint(w, "<CompleteMultipartUploadResult></CompleteMultipartUploadResult>")
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		f.objects[key] = body
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.ServeContent(w, r, key, time.Time{}, bytes.NewReader(data))
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// verifySignature verifies the request is signed with the test credentials.
+func (f *fakeS3) verifySignature(r *http.Request, body []byte) bool {
+	payloadHash := r.Header.Get(headerContentSHA256)
+	if payloadHash != hashHex(body) {
+		f.t.Errorf("%s %s: payload hash = %s, want %s", r.Method, r.URL, payloadHash, hashHex(body))
+		return false
+	}
+	if got := r.Header.Get(headerSecurityToken); got != testCredentials.SessionToken {
+		f.t.Errorf("%s %s: security token = %s, want %s", r.Method, r.URL, got, testCredentials.SessionToken)
+		return false
+	}
+	now, err := time.Parse(timeFormat, r.Header.Get(headerDate))
+	if err != nil {
+		f.t.Errorf("%s %s: invalid date: %v", r.Method, r.URL, err)
+		return false
+	}
+	got := r.Header.Get("Authorization")
+	req := r.Clone(context.Background())
+	req.URL.Host = r.Host
+	req.Header = http.Header{}
+	for key, values := range r.Header {
+		key = http.CanonicalHeaderKey(key)
+		if key == "Range" || strings.HasPrefix(key, "X-Amz-") {
+			req.Header[key] = values
+		}
+	}
+	signRequest(req, testCredentials, f.region, payloadHash, now)
+	return got == req.Header.Get("Authorization")
+}
+
+func (f *fakeS3) keys() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var keys []string
+	for key := range f.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func newTestStore(t *testing.T, bucket string) (*Store, *fakeS3) {
+	fake := newFakeS3(t, bucket, "eu-west-1")
+	ts := httptest.NewServer(fake)
+	t.Cleanup(ts.Close)
+	s, err := New(ts.URL, bucket)
+	if err != nil {
+		t.Fatal("New() error =", err)
+	}
+	s.Credentials = testCredentials
+	s.Region = "eu-west-1"
+	s.Prefix = "oci layouts/foo"
+	return s, fake
+}
+
+func TestStore(t *testing.T) {
+	s, fake := newTestStore(t, "bucket")
+	ctx := context.Background()
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+
+	if exists, err := s.Exists(ctx, desc); err != nil || exists {
+		t.Fatalf("Store.Exists() = %v, %v, want false", exists, err)
+	}
+	if _, err := s.Fetch(ctx, desc); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Store.Fetch() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+	if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+	wantKey := "oci layouts/foo/blobs/sha256/" + desc.Digest.Encoded()
+	if keys := fake.keys(); len(keys) != 1 || keys[0] != wantKey {
+		t.Errorf("stored keys = %v, want [%s]", keys, wantKey)
+	}
+	if err := s.Push(ctx, desc, bytes.NewReader(blob)); !errors.Is(err, errdef.ErrAlreadyExists) {
+		t.Errorf("Store.Push() error = %v, wantErr %v", err, errdef.ErrAlreadyExists)
+	}
+	if exists, err := s.Exists(ctx, desc); err != nil || !exists {
+		t.Errorf("Store.Exists() = %v, %v, want true", exists, err)
+	}
+
+	got, err := content.FetchAll(ctx, s, desc)
+	if err != nil {
+		t.Fatal("Store.Fetch() error =", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("Store.Fetch() = %s, want %s", got, blob)
+	}
+
+	rc, err := s.FetchRange(ctx, desc, 6, 5)
+	if err != nil {
+		t.Fatal("Store.FetchRange() error =", err)
+	}
+	got, err = io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		t.Fatal("Store.FetchRange().Read() error =", err)
+	}
+	if want := "world"; string(got) != want {
+		t.Errorf("Store.FetchRange() = %s, want %s", got, want)
+	}
+	if _, err := s.FetchRange(ctx, desc, 6, 6); err == nil {
+		t.Error("Store.FetchRange() error = nil, wantErr true")
+	}
+
+	if err := s.Delete(ctx, desc); err != nil {
+		t.Fatal("Store.Delete() error =", err)
+	}
+	if exists, err := s.Exists(ctx, desc); err != nil || exists {
+		t.Errorf("Store.Exists() = %v, %v, want false", exists, err)
+	}
+	if err := s.Delete(ctx, desc); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Store.Delete() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+
+	// test mismatched content
+	if err := s.Push(ctx, desc, strings.NewReader("hello WORLD")); !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("Store.Push() error = %v, wantErr %v", err, content.ErrMismatchedDigest)
+	}
+	if keys := fake.keys(); len(keys) != 0 {
+		t.Errorf("stored keys = %v, want none", keys)
+	}
+
+	// test invalid digest
+	invalid := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayer, Digest: "invalid", Size: 1}
+	if _, err := s.Exists(ctx, invalid); !errors.Is(err, errdef.ErrInvalidDigest) {
+		t.Errorf("Store.Exists() error = %v, wantErr %v", err, errdef.ErrInvalidDigest)
+	}
+}
+
+func TestStore_Multipart(t *testing.T) {
+	s, fake := newTestStore(t, "bucket")
+	s.PartSize = 1 // clamped to MinPartSize
+	ctx := context.Background()
+	blob := bytes.Repeat([]byte("0123456789abcdef"), (MinPartSize*2+1024)/16)
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+
+	if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+	if want := 3; fake.parts != want {
+		t.Errorf("uploaded parts = %d, want %d", fake.parts, want)
+	}
+	got, err := content.FetchAll(ctx, s, desc)
+	if err != nil {
+		t.Fatal("Store.Fetch() error =", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Error("Store.Fetch() content mismatch")
+	}
+
+	// test mismatched content aborting the upload
+	corrupted := append([]byte(nil), blob...)
+	corrupted[len(corrupted)-1] ^= 0xff
+	corruptedDesc := desc
+	corruptedDesc.Digest = content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, bytes.ToUpper(blob)).Digest
+	if err := s.Push(ctx, corruptedDesc, bytes.NewReader(corrupted)); !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("Store.Push() error = %v, wantErr %v", err, content.ErrMismatchedDigest)
+	}
+	if exists, err := s.Exists(ctx, corruptedDesc); err != nil || exists {
+		t.Errorf("Store.Exists() = %v, %v, want false", exists, err)
+	}
+	if n := len(fake.uploads); n != 0 {
+		t.Errorf("pending uploads = %d, want 0", n)
+	}
+}
+
+func TestStore_Error(t *testing.T) {
+	s, _ := newTestStore(t, "bucket")
+	s.Credentials.SecretAccessKey = "wrong"
+	ctx := context.Background()
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, []byte("foo"))
+	_, err := s.Exists(ctx, desc)
+	if err == nil {
+		t.Fatal("Store.Exists() error = nil, wantErr true")
+	}
+	if !strings.Contains(err.Error(), "403") {
+		t.Errorf("Store.Exists() error = %v, want status code 403", err)
+	}
+	_, err = s.Fetch(ctx, desc)
+	if err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("Store.Fetch() error = %v, want SignatureDoesNotMatch", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, tt := range []struct {
+		endpoint string
+		bucket   string
+	}{
+		{"ftp://localhost", "bucket"},
+		{"http://", "bucket"},
+		{"http://localhost", ""},
+	} {
+		if _, err := New(tt.endpoint, tt.bucket); err == nil {
+			t.Errorf("New(%q, %q) error = nil, wantErr true", tt.endpoint, tt.bucket)
+		}
+	}
+}
+
+func TestStore_partSize(t *testing.T) {
+	s := &Store{}
+	if got := s.partSize(1); got != DefaultPartSize {
+		t.Errorf("Store.partSize() = %d, want %d", got, DefaultPartSize)
+	}
+	size := int64(DefaultPartSize)*MaxParts + 1
+	if got := s.partSize(size); got*MaxParts < size {
+		t.Errorf("Store.partSize() = %d, too small for %d bytes", got, size)
+	}
+}
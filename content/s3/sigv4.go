@@ -0,0 +1,193 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// signingAlgorithm is the AWS Signature Version 4 algorithm identifier.
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	// signingService is the service name in the credential scope.
+	signingService = "s3"
+	// unsignedPayload is the payload hash indicating the payload is not
+	// signed.
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	// emptyPayloadHash is the SHA-256 hash of an empty payload.
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	// headerContentSHA256 is the header carrying the payload hash.
+	headerContentSHA256 = "X-Amz-Content-Sha256"
+	// headerDate is the header carrying the signing time.
+	headerDate = "X-Amz-Date"
+	// headerSecurityToken is the header carrying the session token.
+	headerSecurityToken = "X-Amz-Security-Token"
+
+	// timeFormat is the format of the signing time.
+	timeFormat = "20060102T150405Z"
+	// dateFormat is the format of the signing date in the credential scope.
+	dateFormat = "20060102"
+)
+
+// Credentials are the AWS credentials used to sign requests.
+type Credentials struct {
+	// AccessKeyID is the access key ID.
+	AccessKeyID string
+	// SecretAccessKey is the secret access key.
+	SecretAccessKey string
+	// SessionToken is the optional session token for temporary credentials.
+	SessionToken string
+}
+
+// signRequest signs req in place with AWS Signature Version 4.
+// The host header, the range header and all the x-amz-* headers are signed.
+// req.URL must be built from escaped paths as the escaped path is used as the
+// canonical URI.
+// Reference: https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func signRequest(req *http.Request, cred Credentials, region, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(timeFormat)
+	req.Header.Set(headerDate, amzDate)
+	req.Header.Set(headerContentSHA256, payloadHash)
+	if cred.SessionToken != "" {
+		req.Header.Set(headerSecurityToken, cred.SessionToken)
+	}
+
+	// canonical headers
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{
+		"host": host,
+	}
+	for key, values := range req.Header {
+		key = strings.ToLower(key)
+		if key == "range" || strings.HasPrefix(key, "x-amz-") {
+			trimmed := make([]string, len(values))
+			for i, value := range values {
+				trimmed[i] = strings.Join(strings.Fields(value), " ")
+			}
+			headers[key] = strings.Join(trimmed, ",")
+		}
+	}
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var canonicalHeaders strings.Builder
+	for _, key := range keys {
+		canonicalHeaders.WriteString(key)
+		canonicalHeaders.WriteByte(':')
+		canonicalHeaders.WriteString(headers[key])
+		canonicalHeaders.WriteByte('\n')
+	}
+	signedHeaders := strings.Join(keys, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req),
+		canonicalQuery(req),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{now.Format(dateFormat), region, signingService, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		signingAlgorithm,
+		amzDate,
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+cred.SecretAccessKey), now.Format(dateFormat))
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, signingService)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", signingAlgorithm+
+		" Credential="+cred.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+
+		", Signature="+signature)
+}
+
+// canonicalURI returns the canonical URI of req.
+func canonicalURI(req *http.Request) string {
+	path := req.URL.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// canonicalQuery returns the canonical query string of req.
+func canonicalQuery(req *http.Request) string {
+	query := req.URL.Query()
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, escape(key, true)+"="+escape(value, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// escape URI-encodes s as required by AWS Signature Version 4, where only the
+// unreserved characters are not encoded. If encodeSlash is false, '/' is not
+// encoded.
+func escape(s string, encodeSlash bool) string {
+	const hexDigits = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hexDigits[c>>4])
+			b.WriteByte(hexDigits[c&0xf])
+		}
+	}
+	return b.String()
+}
+
+// hashHex returns the hex-encoded SHA-256 hash of data.
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,209 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package scrub re-verifies the blobs in long-lived storages to detect
+// corruption such as bit rot.
+package scrub
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/pool"
+	"oras.land/oras-go/v2/internal/syncutil"
+)
+
+// Lister lists the blobs in a storage.
+type Lister interface {
+	// Blobs calls fn with the descriptors of all the blobs in the storage.
+	Blobs(ctx context.Context, fn func(desc ocispec.Descriptor) error) error
+}
+
+// Storage is a storage whose blobs can be scrubbed, such as oci.Store.
+type Storage interface {
+	content.Fetcher
+	Lister
+}
+
+// Corruption describes a corrupted blob.
+type Corruption struct {
+	// Descriptor describes the corrupted blob.
+	Descriptor ocispec.Descriptor
+	// Err is the error encountered when verifying the blob.
+	Err error
+}
+
+// Report is the result of scrubbing.
+type Report struct {
+	// Checked is the number of the checked blobs.
+	Checked int
+	// Bytes is the number of the bytes read.
+	Bytes int64
+	// Corrupted lists the corrupted blobs.
+	Corrupted []Corruption
+}
+
+// Options contains parameters for Scrub and Verify.
+type Options struct {
+	// Concurrency limits the number of blobs verified concurrently.
+	// If less than or equal to 0, blobs are verified one at a time.
+	Concurrency int
+
+	// BytesPerSecond limits the overall rate of reading the blobs, so that
+	// scrubbing can run in the background without starving other I/O.
+	// Bursts of up to one second of the rate are allowed.
+	// If less than or equal to 0, the rate is unlimited.
+	BytesPerSecond int64
+
+	// OnCorrupted is called for each corrupted blob as it is found.
+	// If it returns an error, scrubbing stops with the error.
+	// OnCorrupted is not called concurrently.
+	OnCorrupted func(ctx context.Context, corruption Corruption) error
+}
+
+// Scrub reads all the blobs in the storage, and verifies them against their
+// digests and sizes.
+// Scrubbing stops when ctx is done, and the partial report is returned along
+// with the context error.
+func Scrub(ctx context.Context, storage Storage, opts Options) (*Report, error) {
+	return verifyAll(ctx, storage, storage.Blobs, opts)
+}
+
+// Verify reads the blobs described by descs from the fetcher, and verifies
+// them against their digests and sizes, hashing up to opts.Concurrency blobs
+// concurrently.
+// Verification stops when ctx is done, and the partial report is returned
+// along with the context error.
+func Verify(ctx context.Context, fetcher content.Fetcher, descs []ocispec.Descriptor, opts Options) (*Report, error) {
+	list := func(ctx context.Context, fn func(desc ocispec.Descriptor) error) error {
+		for _, desc := range descs {
+			if err := fn(desc); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return verifyAll(ctx, fetcher, list, opts)
+}
+
+// verifyAll verifies the blobs listed by list with a pool of
+// opts.Concurrency workers.
+func verifyAll(ctx context.Context, fetcher content.Fetcher, list func(ctx context.Context, fn func(desc ocispec.Descriptor) error) error, opts Options) (*Report, error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	report := &Report{}
+	var mu sync.Mutex // protects report and OnCorrupted
+	limiter := syncutil.NewRateLimiter(opts.BytesPerSecond)
+	eg, egCtx := syncutil.LimitGroup(ctx, concurrency)
+	listErr := list(egCtx, func(desc ocispec.Descriptor) error {
+		eg.Go(func() error {
+			n, err := verify(egCtx, fetcher, desc, limiter)
+			mu.Lock()
+			defer mu.Unlock()
+			report.Bytes += n
+			if err != nil {
+				if ctxErr := egCtx.Err(); ctxErr != nil {
+					return ctxErr
+				}
+				if errors.Is(err, errdef.ErrNotFound) {
+					// the blob is deleted in the meantime
+					return nil
+				}
+				corruption := Corruption{
+					Descriptor: desc,
+					Err:        err,
+				}
+				report.Corrupted = append(report.Corrupted, corruption)
+				if opts.OnCorrupted != nil {
+					if err := opts.OnCorrupted(egCtx, corruption); err != nil {
+						return err
+					}
+				}
+			}
+			report.Checked++
+			return nil
+		})
+		return egCtx.Err()
+	})
+	// errors of the workers take precedence over the cancellation of the
+	// listing caused by them
+	if err := eg.Wait(); err != nil {
+		return report, err
+	}
+	if listErr != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return report, ctxErr
+		}
+	}
+	return report, listErr
+}
+
+// verify reads the blob, and verifies it against the descriptor.
+// Returns the number of bytes read.
+func verify(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor, limiter *syncutil.RateLimiter) (int64, error) {
+	rc, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+	r := &countingReader{
+		r: &limitedReader{ctx: ctx, r: rc, limiter: limiter},
+	}
+	vr := content.NewVerifyReader(r, desc)
+	if _, err := pool.Stream.Discard(vr); err != nil {
+		return r.n, err
+	}
+	return r.n, vr.Verify()
+}
+
+// countingReader counts the bytes read.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read reads from the underlying reader.
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// limitedReader is a reader limited by a rate limiter.
+type limitedReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter *syncutil.RateLimiter
+}
+
+// Read reads from the underlying reader at the limited rate.
+// Reads are capped to the rate per second to avoid bursts.
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if rate := r.limiter.Rate(); rate > 0 && int64(len(p)) > rate {
+		p = p[:rate]
+	}
+	n, err := r.r.Read(p)
+	if waitErr := r.limiter.Wait(r.ctx, n); waitErr != nil {
+		return n, waitErr
+	}
+	return n, err
+}
@@ -0,0 +1,260 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scrub
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// setupStore creates an OCI store with the given blobs.
+func setupStore(t *testing.T, blobs ...[]byte) (string, *oci.Store, []ocispec.Descriptor) {
+	root := t.TempDir()
+	s, err := oci.New(root)
+	if err != nil {
+		t.Fatal("oci.New() error =", err)
+	}
+	ctx := context.Background()
+	var descs []ocispec.Descriptor
+	for _, blob := range blobs {
+		desc := content.NewDescriptorFromBytes("test", blob)
+		if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal("Store.Push() error =", err)
+		}
+		descs = append(descs, desc)
+	}
+	return root, s, descs
+}
+
+// corrupt overwrites the blob in the store rooted at root.
+func corrupt(t *testing.T, root string, desc ocispec.Descriptor, data []byte) {
+	path := filepath.Join(root, "blobs", desc.Digest.Algorithm().String(), desc.Digest.Encoded())
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatal("os.Chmod() error =", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal("os.WriteFile() error =", err)
+	}
+}
+
+func TestScrub(t *testing.T) {
+	root, s, descs := setupStore(t, []byte("foo"), []byte("bar"), []byte("hello world"))
+	corrupt(t, root, descs[1], []byte("baz"))          // bit rot
+	corrupt(t, root, descs[2], []byte("hello world!")) // size changed
+
+	ctx := context.Background()
+	var found []Corruption
+	report, err := Scrub(ctx, s, Options{
+		OnCorrupted: func(ctx context.Context, corruption Corruption) error {
+			found = append(found, corruption)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal("Scrub() error =", err)
+	}
+	if report.Checked != 3 {
+		t.Errorf("Report.Checked = %d, want %d", report.Checked, 3)
+	}
+	if len(report.Corrupted) != 2 {
+		t.Fatalf("len(Report.Corrupted) = %d, want %d", len(report.Corrupted), 2)
+	}
+	if len(found) != 2 {
+		t.Errorf("OnCorrupted calls = %d, want %d", len(found), 2)
+	}
+	corrupted := make(map[string]error)
+	for _, c := range report.Corrupted {
+		corrupted[c.Descriptor.Digest.String()] = c.Err
+	}
+	if err := corrupted[descs[1].Digest.String()]; !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("corruption of %s: error = %v, want %v", descs[1].Digest, err, content.ErrMismatchedDigest)
+	}
+	if err := corrupted[descs[2].Digest.String()]; err == nil {
+		t.Errorf("corruption of %s not reported", descs[2].Digest)
+	}
+	if _, ok := corrupted[descs[0].Digest.String()]; ok {
+		t.Errorf("intact blob %s reported as corrupted", descs[0].Digest)
+	}
+}
+
+func TestScrub_OnCorruptedError(t *testing.T) {
+	root, s, descs := setupStore(t, []byte("foo"))
+	corrupt(t, root, descs[0], []byte("bar"))
+
+	errStop := errors.New("stop")
+	report, err := Scrub(context.Background(), s, Options{
+		OnCorrupted: func(ctx context.Context, corruption Corruption) error {
+			return errStop
+		},
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("Scrub() error = %v, want %v", err, errStop)
+	}
+	if len(report.Corrupted) != 1 {
+		t.Errorf("len(Report.Corrupted) = %d, want %d", len(report.Corrupted), 1)
+	}
+}
+
+func TestScrub_RateLimit(t *testing.T) {
+	blob := bytes.Repeat([]byte("a"), 1000)
+	_, s, _ := setupStore(t, blob, append(blob, 'b'))
+
+	start := time.Now()
+	report, err := Scrub(context.Background(), s, Options{
+		BytesPerSecond: 1500,
+	})
+	if err != nil {
+		t.Fatal("Scrub() error =", err)
+	}
+	if want := int64(2001); report.Bytes != want {
+		t.Errorf("Report.Bytes = %d, want %d", report.Bytes, want)
+	}
+	if elapsed, want := time.Since(start), 200*time.Millisecond; elapsed < want {
+		t.Errorf("Scrub() took %v, want at least %v", elapsed, want)
+	}
+}
+
+func TestScrub_ContextCanceled(t *testing.T) {
+	blob := bytes.Repeat([]byte("a"), 1000)
+	_, s, _ := setupStore(t, blob)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	report, err := Scrub(ctx, s, Options{
+		BytesPerSecond: 100,
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Scrub() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if len(report.Corrupted) != 0 {
+		t.Errorf("Report.Corrupted = %v, want empty", report.Corrupted)
+	}
+}
+
+// missingStorage lists a blob which cannot be fetched.
+type missingStorage struct{}
+
+func (missingStorage) Fetch(ctx context.Context, desc ocispec.Descriptor) (rc io.ReadCloser, err error) {
+	return nil, errdef.ErrNotFound
+}
+
+func (missingStorage) Blobs(ctx context.Context, fn func(desc ocispec.Descriptor) error) error {
+	return fn(content.NewDescriptorFromBytes("test", []byte("foo")))
+}
+
+func TestScrub_NotFound(t *testing.T) {
+	report, err := Scrub(context.Background(), missingStorage{}, Options{})
+	if err != nil {
+		t.Fatal("Scrub() error =", err)
+	}
+	if report.Checked != 0 || len(report.Corrupted) != 0 {
+		t.Errorf("Scrub() = %+v, want empty report", report)
+	}
+}
+
+// concurrentFetcher fetches from a storage after all the expected concurrent
+// fetches have started.
+type concurrentFetcher struct {
+	content.Fetcher
+	barrier chan struct{}
+	wait    chan struct{}
+}
+
+func (f *concurrentFetcher) Fetch(ctx context.Context, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	select {
+	case f.barrier <- struct{}{}:
+	default:
+		close(f.wait)
+	}
+	select {
+	case <-f.wait:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return f.Fetcher.Fetch(ctx, desc)
+}
+
+func TestVerify(t *testing.T) {
+	root, s, descs := setupStore(t, []byte("foo"), []byte("bar"), []byte("hello world"), []byte("hello"))
+	corrupt(t, root, descs[1], []byte("baz"))
+
+	// the fetches only proceed if all the blobs are fetched concurrently
+	fetcher := &concurrentFetcher{
+		Fetcher: s,
+		barrier: make(chan struct{}, len(descs)-1),
+		wait:    make(chan struct{}),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var found []Corruption
+	report, err := Verify(ctx, fetcher, descs, Options{
+		Concurrency: len(descs),
+		OnCorrupted: func(ctx context.Context, corruption Corruption) error {
+			found = append(found, corruption)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal("Verify() error =", err)
+	}
+	if report.Checked != len(descs) {
+		t.Errorf("Report.Checked = %d, want %d", report.Checked, len(descs))
+	}
+	if want := int64(3 + 3 + 11 + 5); report.Bytes != want {
+		t.Errorf("Report.Bytes = %d, want %d", report.Bytes, want)
+	}
+	if len(report.Corrupted) != 1 || report.Corrupted[0].Descriptor.Digest != descs[1].Digest {
+		t.Fatalf("Report.Corrupted = %v, want %s", report.Corrupted, descs[1].Digest)
+	}
+	if !errors.Is(report.Corrupted[0].Err, content.ErrMismatchedDigest) {
+		t.Errorf("corruption error = %v, want %v", report.Corrupted[0].Err, content.ErrMismatchedDigest)
+	}
+	if len(found) != 1 {
+		t.Errorf("OnCorrupted calls = %d, want %d", len(found), 1)
+	}
+}
+
+func TestScrub_ConcurrentRateLimit(t *testing.T) {
+	blob := bytes.Repeat([]byte("a"), 1000)
+	_, s, _ := setupStore(t, blob, append(blob, 'b'), append(blob, 'c'), append(blob, 'd'))
+
+	// the rate limit is shared by all the workers
+	start := time.Now()
+	report, err := Scrub(context.Background(), s, Options{
+		Concurrency:    4,
+		BytesPerSecond: 3000,
+	})
+	if err != nil {
+		t.Fatal("Scrub() error =", err)
+	}
+	if want := int64(4003); report.Bytes != want {
+		t.Errorf("Report.Bytes = %d, want %d", report.Bytes, want)
+	}
+	if elapsed, want := time.Since(start), 200*time.Millisecond; elapsed < want {
+		t.Errorf("Scrub() took %v, want at least %v", elapsed, want)
+	}
+}
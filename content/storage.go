@@ -17,9 +17,11 @@ package content
 
 import (
 	"context"
+	"fmt"
 	"io"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
 )
 
 // Fetcher fetches content.
@@ -60,6 +62,63 @@ type Deleter interface {
 	Delete(ctx context.Context, target ocispec.Descriptor) error
 }
 
+// Delete removes the content identified by the descriptor from the storage.
+// Returns ErrUnsupported if the storage does not implement Deleter.
+func Delete(ctx context.Context, storage ReadOnlyStorage, target ocispec.Descriptor) error {
+	deleter, ok := storage.(Deleter)
+	if !ok {
+		return fmt.Errorf("%s: %s: delete: %w", target.Digest, target.MediaType, errdef.ErrUnsupported)
+	}
+	return deleter.Delete(ctx, target)
+}
+
+// RangeFetcher fetches a range of content.
+// RangeFetcher is an extension of Fetcher.
+type RangeFetcher interface {
+	// FetchRange fetches length bytes of the content identified by the
+	// descriptor, starting at offset.
+	FetchRange(ctx context.Context, target ocispec.Descriptor, offset, length int64) (io.ReadCloser, error)
+}
+
+// FetchRange fetches length bytes of the content identified by the
+// descriptor, starting at offset.
+//   - If the fetcher implements RangeFetcher, only the range is fetched.
+//   - If the fetched content implements io.Seeker, it is seeked to offset.
+//   - Otherwise the content before offset is read and discarded.
+//
+// The fetched range cannot be verified against the digest of the content.
+func FetchRange(ctx context.Context, fetcher Fetcher, target ocispec.Descriptor, offset, length int64) (io.ReadCloser, error) {
+	if offset < 0 || length < 0 || offset+length > target.Size {
+		return nil, fmt.Errorf("%s: %s: invalid range [%d, %d) of size %d", target.Digest, target.MediaType, offset, offset+length, target.Size)
+	}
+	if rangeFetcher, ok := fetcher.(RangeFetcher); ok {
+		return rangeFetcher.FetchRange(ctx, target, offset, length)
+	}
+
+	rc, err := fetcher.Fetch(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		if seeker, ok := rc.(io.Seeker); ok {
+			_, err = seeker.Seek(offset, io.SeekStart)
+		} else {
+			_, err = io.CopyN(io.Discard, rc, offset)
+		}
+		if err != nil {
+			rc.Close()
+			return nil, err
+		}
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.LimitReader(rc, length),
+		Closer: rc,
+	}, nil
+}
+
 // FetchAll safely fetches the content described by the descriptor.
 // The fetched content is verified against the size and the digest.
 func FetchAll(ctx context.Context, fetcher Fetcher, desc ocispec.Descriptor) ([]byte, error) {
@@ -0,0 +1,145 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package content_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// deletableStorage is a storage recording the deleted descriptors.
+type deletableStorage struct {
+	content.Storage
+	deleted []ocispec.Descriptor
+}
+
+func (s *deletableStorage) Delete(_ context.Context, target ocispec.Descriptor) error {
+	s.deleted = append(s.deleted, target)
+	return nil
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+	desc := content.NewDescriptorFromBytes("test", []byte("hello world"))
+
+	// storage without delete capability
+	readOnly := struct{ content.ReadOnlyStorage }{memory.New()}
+	if err := content.Delete(ctx, readOnly, desc); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Delete() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+
+	// storage with delete capability
+	s := &deletableStorage{Storage: memory.New()}
+	if err := content.Delete(ctx, s, desc); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if len(s.deleted) != 1 || !content.Equal(s.deleted[0], desc) {
+		t.Errorf("Delete() deleted = %v, want %v", s.deleted, []ocispec.Descriptor{desc})
+	}
+}
+
+// rangeFetcher is a fetcher recording the fetched ranges.
+type rangeFetcher struct {
+	content.Fetcher
+	ranges [][2]int64
+}
+
+func (f *rangeFetcher) FetchRange(ctx context.Context, target ocispec.Descriptor, offset, length int64) (io.ReadCloser, error) {
+	f.ranges = append(f.ranges, [2]int64{offset, length})
+	rc, err := f.Fetch(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data[offset : offset+length])), nil
+}
+
+// seekableFetcher is a fetcher returning seekable content.
+type seekableFetcher struct {
+	data []byte
+}
+
+func (f *seekableFetcher) Fetch(_ context.Context, _ ocispec.Descriptor) (io.ReadCloser, error) {
+	return struct {
+		io.ReadSeeker
+		io.Closer
+	}{bytes.NewReader(f.data), io.NopCloser(nil)}, nil
+}
+
+func TestFetchRange(t *testing.T) {
+	ctx := context.Background()
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	store := memory.New()
+	if err := store.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+
+	readRange := func(fetcher content.Fetcher, offset, length int64) string {
+		rc, err := content.FetchRange(ctx, fetcher, desc, offset, length)
+		if err != nil {
+			t.Fatalf("FetchRange() error = %v", err)
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatalf("failed to read range: %v", err)
+		}
+		return string(data)
+	}
+
+	// plain fetcher
+	if got, want := readRange(store, 6, 5), "world"; got != want {
+		t.Errorf("FetchRange() = %q, want %q", got, want)
+	}
+	if got, want := readRange(store, 0, 5), "hello"; got != want {
+		t.Errorf("FetchRange() = %q, want %q", got, want)
+	}
+
+	// seekable content
+	if got, want := readRange(&seekableFetcher{data: blob}, 4, 3), "o w"; got != want {
+		t.Errorf("FetchRange() = %q, want %q", got, want)
+	}
+
+	// range fetcher
+	rf := &rangeFetcher{Fetcher: store}
+	if got, want := readRange(rf, 2, 3), "llo"; got != want {
+		t.Errorf("FetchRange() = %q, want %q", got, want)
+	}
+	if want := [][2]int64{{2, 3}}; !reflect.DeepEqual(rf.ranges, want) {
+		t.Errorf("FetchRange() ranges = %v, want %v", rf.ranges, want)
+	}
+
+	// invalid ranges
+	for _, r := range [][2]int64{{-1, 2}, {0, -1}, {6, 6}} {
+		if _, err := content.FetchRange(ctx, store, desc, r[0], r[1]); err == nil {
+			t.Errorf("FetchRange(%d, %d) error = nil, want error", r[0], r[1])
+		}
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package stream provides ways to consume the layers of images while they are
+// being pulled, without staging the blobs on disk, for "pull and pipe"
+// scenarios with limited disk space.
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/docker"
+	"oras.land/oras-go/v2/internal/pool"
+)
+
+// BlobHandler handles the content of a blob read from r.
+// The content read from r is verified against desc on reaching EOF, where
+// the verification failure is returned by r instead of io.EOF. The remaining
+// content not read by the handler is discarded.
+type BlobHandler func(ctx context.Context, desc ocispec.Descriptor, r io.Reader) error
+
+// Target is a write-only Target streaming the pushed blobs through a handler
+// instead of storing them.
+//
+// Manifests are kept in memory so that they can be tagged and resolved. All
+// other blobs, including configs, are passed to the handler, which may filter
+// them by media type. The handler calls are serialized, but blobs are handled
+// in the order they are pushed, which is arbitrary when copied by
+// oras.Copy with concurrency. Use Extract to handle the layers of an image in
+// order.
+type Target struct {
+	handler   BlobHandler
+	manifests *memory.Store
+
+	// lock serializes the handler calls.
+	lock sync.Mutex
+	// handled records the handled blobs.
+	handled sync.Map // map[descriptor.Descriptor]struct{}
+}
+
+// NewTarget creates a Target streaming the pushed blobs through handler.
+func NewTarget(handler BlobHandler) *Target {
+	return &Target{
+		handler:   handler,
+		manifests: memory.New(),
+	}
+}
+
+// Push stores the manifest, or streams the blob through the handler.
+func (t *Target) Push(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	if descriptor.IsManifest(expected) {
+		return t.manifests.Push(ctx, expected, r)
+	}
+	key := descriptor.FromOCI(expected)
+	if _, ok := t.handled.Load(key); ok {
+		return fmt.Errorf("%s: %s: %w", expected.Digest, expected.MediaType, errdef.ErrAlreadyExists)
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if err := handle(ctx, t.handler, expected, r); err != nil {
+		return err
+	}
+	t.handled.Store(key, struct{}{})
+	return nil
+}
+
+// Exists returns true if the manifest is stored or the blob is handled.
+func (t *Target) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	if _, ok := t.handled.Load(descriptor.FromOCI(target)); ok {
+		return true, nil
+	}
+	return t.manifests.Exists(ctx, target)
+}
+
+// Fetch fetches the stored manifest.
+// Returns ErrUnsupported for the streamed blobs.
+func (t *Target) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	if !descriptor.IsManifest(target) {
+		return nil, fmt.Errorf("%s: %s: streamed content cannot be fetched: %w", target.Digest, target.MediaType, errdef.ErrUnsupported)
+	}
+	return t.manifests.Fetch(ctx, target)
+}
+
+// Resolve resolves a reference to a stored manifest.
+func (t *Target) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+	return t.manifests.Resolve(ctx, reference)
+}
+
+// Tag tags a stored manifest with a reference string.
+func (t *Target) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	return t.manifests.Tag(ctx, desc, reference)
+}
+
+// Extract fetches the layers of the image manifest described by desc from
+// src one by one, and streams them through handler in order.
+// Returns ErrUnsupported if desc is not an image manifest. To extract a
+// multi-platform image, select a platform manifest first.
+func Extract(ctx context.Context, src content.Fetcher, desc ocispec.Descriptor, handler BlobHandler) error {
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageManifest, docker.MediaTypeManifest:
+	default:
+		return fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, errdef.ErrUnsupported)
+	}
+	manifestJSON, err := content.FetchAll(ctx, src, desc)
+	if err != nil {
+		return err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", desc.Digest, err)
+	}
+	for _, layer := range manifest.Layers {
+		if err := extractLayer(ctx, src, layer, handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractLayer fetches the layer and streams it through handler.
+func extractLayer(ctx context.Context, src content.Fetcher, desc ocispec.Descriptor, handler BlobHandler) error {
+	rc, err := src.Fetch(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return handle(ctx, handler, desc, rc)
+}
+
+// handle streams the content through handler, and verifies it.
+func handle(ctx context.Context, handler BlobHandler, desc ocispec.Descriptor, r io.Reader) error {
+	vr := content.NewVerifyReader(r, desc)
+	if err := handler(ctx, desc, &verifyReader{vr: vr}); err != nil {
+		return err
+	}
+	// discard the remaining content so that it can be verified
+	if _, err := pool.Stream.Discard(vr); err != nil {
+		return fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, err)
+	}
+	if err := vr.Verify(); err != nil {
+		return fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, err)
+	}
+	return nil
+}
+
+// verifyReader verifies the content on reaching EOF.
+type verifyReader struct {
+	vr *content.VerifyReader
+}
+
+// Read reads from the underlying reader, and verifies the content on EOF.
+func (r *verifyReader) Read(p []byte) (int, error) {
+	n, err := r.vr.Read(p)
+	if err == io.EOF {
+		if verr := r.vr.Verify(); verr != nil {
+			return n, verr
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,217 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stream
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// tarGzip returns a gzipped tar archive of the files.
+func tarGzip(t *testing.T, files ...string) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	for _, name := range files {
+		data := []byte("content of " + name)
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data))}); err != nil {
+			t.Fatal("tar.Writer.WriteHeader() error =", err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal("tar.Writer.Write() error =", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal("tar.Writer.Close() error =", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal("gzip.Writer.Close() error =", err)
+	}
+	return buf.Bytes()
+}
+
+// pushTestImage pushes an image with the layers into a new memory store, and
+// tags it as "latest".
+func pushTestImage(t *testing.T, layers ...[]byte) (*memory.Store, ocispec.Descriptor, []ocispec.Descriptor) {
+	ctx := context.Background()
+	s := memory.New()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal("Store.Push() error =", err)
+		}
+		return desc
+	}
+	config := push(ocispec.MediaTypeImageConfig, []byte("{}"))
+	var layerDescs []ocispec.Descriptor
+	for _, layer := range layers {
+		layerDescs = append(layerDescs, push(ocispec.MediaTypeImageLayerGzip, layer))
+	}
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    layerDescs,
+	})
+	if err != nil {
+		t.Fatal("json.Marshal() error =", err)
+	}
+	manifest := push(ocispec.MediaTypeImageManifest, manifestJSON)
+	if err := s.Tag(ctx, manifest, "latest"); err != nil {
+		t.Fatal("Store.Tag() error =", err)
+	}
+	return s, manifest, append([]ocispec.Descriptor{config}, layerDescs...)
+}
+
+// tarNames returns the entry names of the tar archive.
+func tarNames(t *testing.T, data []byte) []string {
+	var names []string
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return names
+		}
+		if err != nil {
+			t.Fatal("tar.Reader.Next() error =", err)
+		}
+		names = append(names, header.Name)
+	}
+}
+
+func TestTarget(t *testing.T) {
+	src, manifest, blobs := pushTestImage(t, tarGzip(t, "a"), tarGzip(t, "b"))
+	ctx := context.Background()
+
+	handled := make(map[string][]byte)
+	target := NewTarget(func(ctx context.Context, desc ocispec.Descriptor, r io.Reader) error {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		handled[desc.Digest.String()] = data
+		return nil
+	})
+	if _, err := oras.Copy(ctx, src, "latest", target, "v1", oras.CopyOptions{}); err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+	if len(handled) != len(blobs) {
+		t.Errorf("handled %d blobs, want %d", len(handled), len(blobs))
+	}
+	for _, desc := range blobs {
+		want, err := content.FetchAll(ctx, src, desc)
+		if err != nil {
+			t.Fatal("Store.Fetch() error =", err)
+		}
+		if !bytes.Equal(handled[desc.Digest.String()], want) {
+			t.Errorf("handled content of %s mismatch", desc.Digest)
+		}
+		if exists, err := target.Exists(ctx, desc); err != nil || !exists {
+			t.Errorf("Target.Exists() = %v, %v, want true", exists, err)
+		}
+		if _, err := target.Fetch(ctx, desc); !errors.Is(err, errdef.ErrUnsupported) {
+			t.Errorf("Target.Fetch() error = %v, wantErr %v", err, errdef.ErrUnsupported)
+		}
+		if err := target.Push(ctx, desc, bytes.NewReader(want)); !errors.Is(err, errdef.ErrAlreadyExists) {
+			t.Errorf("Target.Push() error = %v, wantErr %v", err, errdef.ErrAlreadyExists)
+		}
+	}
+	got, err := target.Resolve(ctx, "v1")
+	if err != nil || !content.Equal(got, manifest) {
+		t.Errorf("Target.Resolve() = %v, %v, want %v", got, err, manifest)
+	}
+	if _, err := content.FetchAll(ctx, target, manifest); err != nil {
+		t.Errorf("Target.Fetch() error = %v", err)
+	}
+}
+
+func TestTarget_Mismatch(t *testing.T) {
+	blob := []byte("foo")
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+	target := NewTarget(func(ctx context.Context, desc ocispec.Descriptor, r io.Reader) error {
+		_, err := io.ReadAll(r)
+		return err
+	})
+	ctx := context.Background()
+	if err := target.Push(ctx, desc, bytes.NewReader([]byte("bar"))); !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("Target.Push() error = %v, wantErr %v", err, content.ErrMismatchedDigest)
+	}
+	if exists, err := target.Exists(ctx, desc); err != nil || exists {
+		t.Errorf("Target.Exists() = %v, %v, want false", exists, err)
+	}
+
+	// test the content not read by the handler being verified
+	lazy := NewTarget(func(ctx context.Context, desc ocispec.Descriptor, r io.Reader) error {
+		return nil
+	})
+	if err := lazy.Push(ctx, desc, bytes.NewReader([]byte("bar"))); !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("Target.Push() error = %v, wantErr %v", err, content.ErrMismatchedDigest)
+	}
+	if err := lazy.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Errorf("Target.Push() error = %v", err)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	src, manifest, _ := pushTestImage(t, tarGzip(t, "a", "b"), tarGzip(t, "c"), tarGzip(t, "a"))
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	tw := NewTarWriter(&buf)
+	if err := Extract(ctx, src, manifest, tw.Handle); err != nil {
+		t.Fatal("Extract() error =", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal("TarWriter.Close() error =", err)
+	}
+	if got, want := tarNames(t, buf.Bytes()), []string{"a", "b", "c", "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Extract() entries = %v, want %v", got, want)
+	}
+
+	// test non-manifest
+	index := content.NewDescriptorFromBytes(ocispec.MediaTypeImageIndex, []byte("{}"))
+	if err := Extract(ctx, src, index, tw.Handle); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Extract() error = %v, wantErr %v", err, errdef.ErrUnsupported)
+	}
+}
+
+func TestTarWriter_Corrupted(t *testing.T) {
+	layer := tarGzip(t, "a")
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayerGzip, layer)
+	corrupted := tarGzip(t, "b")
+	desc.Size = int64(len(corrupted))
+
+	var buf bytes.Buffer
+	tw := NewTarWriter(&buf)
+	target := NewTarget(tw.Handle)
+	if err := target.Push(context.Background(), desc, bytes.NewReader(corrupted)); !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("Target.Push() error = %v, wantErr %v", err, content.ErrMismatchedDigest)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stream
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content/compression"
+)
+
+// TarWriter writes the entries of the handled layers into a single tar
+// stream, in the order the layers are handled.
+//
+// The layers are decompressed with the registered decompressors. Blobs which
+// are not layers, such as configs, are skipped. Whiteout entries are written
+// as is, and therefore the stream is not a flattened filesystem if later
+// layers delete or replace files of earlier ones.
+type TarWriter struct {
+	lock sync.Mutex
+	tw   *tar.Writer
+}
+
+// NewTarWriter creates a TarWriter writing the tar stream to w.
+func NewTarWriter(w io.Writer) *TarWriter {
+	return &TarWriter{
+		tw: tar.NewWriter(w),
+	}
+}
+
+// Handle writes the entries of the layer read from r into the tar stream.
+// Handle can be used as a BlobHandler.
+func (w *TarWriter) Handle(_ context.Context, desc ocispec.Descriptor, r io.Reader) error {
+	if _, ok := compression.FromMediaType(desc.MediaType); !ok {
+		return nil
+	}
+	zr, _, err := compression.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, err)
+	}
+	defer zr.Close()
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	tr := tar.NewReader(zr)
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			if err == io.EOF {
+				// read to the end so that the layer is verified
+				if _, err := io.Copy(io.Discard, zr); err != nil {
+					return fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, err)
+				}
+				return nil
+			}
+			return fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, err)
+		}
+		if err := w.tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("tar: %w", err)
+		}
+		if _, err := io.Copy(w.tw, tr); err != nil {
+			return fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, err)
+		}
+	}
+}
+
+// Close writes the tar footer. The underlying writer is not closed.
+func (w *TarWriter) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.tw.Close()
+}
@@ -31,6 +31,7 @@ import (
 	"testing"
 
 	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2"
 	"oras.land/oras-go/v2/content/memory"
@@ -647,6 +648,114 @@ func TestResolve_Memory(t *testing.T) {
 	}
 }
 
+func TestExistsReference_Memory(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	desc, err := oras.PushBytes(ctx, s, ocispec.MediaTypeImageManifest, []byte(`{"layers":[]}`))
+	if err != nil {
+		t.Fatal("PushBytes() error =", err)
+	}
+	if err := s.Tag(ctx, desc, "latest"); err != nil {
+		t.Fatal("Tag() error =", err)
+	}
+
+	exists, err := oras.ExistsReference(ctx, s, "latest")
+	if err != nil {
+		t.Fatal("ExistsReference() error =", err)
+	}
+	if !exists {
+		t.Errorf("ExistsReference() = %v, want %v", exists, true)
+	}
+	exists, err = oras.ExistsReference(ctx, s, "missing")
+	if err != nil {
+		t.Fatal("ExistsReference() error =", err)
+	}
+	if exists {
+		t.Errorf("ExistsReference() = %v, want %v", exists, false)
+	}
+}
+
+func TestResolve_NestedIndex(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	pushImage := func(config string, layer string) ocispec.Descriptor {
+		configDesc, err := oras.PushBytes(ctx, s, ocispec.MediaTypeImageConfig, []byte(config))
+		if err != nil {
+			t.Fatal("PushBytes() error =", err)
+		}
+		layerDesc, err := oras.PushBytes(ctx, s, ocispec.MediaTypeImageLayer, []byte(layer))
+		if err != nil {
+			t.Fatal("PushBytes() error =", err)
+		}
+		desc, err := oras.Pack(ctx, s, "", []ocispec.Descriptor{layerDesc}, oras.PackOptions{
+			PackImageManifest: true,
+			ConfigDescriptor:  &configDesc,
+		})
+		if err != nil {
+			t.Fatal("Pack() error =", err)
+		}
+		return desc
+	}
+	pushIndex := func(manifests ...ocispec.Descriptor) ocispec.Descriptor {
+		indexJSON, err := json.Marshal(ocispec.Index{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			MediaType: ocispec.MediaTypeImageIndex,
+			Manifests: manifests,
+		})
+		if err != nil {
+			t.Fatal("json.Marshal() error =", err)
+		}
+		desc, err := oras.PushBytes(ctx, s, ocispec.MediaTypeImageIndex, indexJSON)
+		if err != nil {
+			t.Fatal("PushBytes() error =", err)
+		}
+		return desc
+	}
+	amd64 := ocispec.Platform{Architecture: "amd64", OS: "linux"}
+	arm64 := ocispec.Platform{Architecture: "arm64", OS: "linux"}
+	windows := ocispec.Platform{Architecture: "amd64", OS: "windows"}
+	amd64Manifest := pushImage(`{"architecture":"amd64","os":"linux"}`, "amd64")
+	amd64Manifest.Platform = &amd64
+	arm64Manifest := pushImage(`{"architecture":"arm64","os":"linux"}`, "arm64")
+	arm64Manifest.Platform = &arm64
+	windowsManifest := pushImage(`{"architecture":"amd64","os":"windows"}`, "windows")
+	windowsManifest.Platform = &windows
+	inner := pushIndex(amd64Manifest, arm64Manifest)
+	root := pushIndex(windowsManifest, inner)
+	if err := s.Tag(ctx, root, "latest"); err != nil {
+		t.Fatal("Tag() error =", err)
+	}
+
+	// the nested index is descended into
+	got, err := oras.Resolve(ctx, s, "latest", oras.ResolveOptions{TargetPlatform: &arm64})
+	if err != nil {
+		t.Fatal("Resolve() error =", err)
+	}
+	if got.Digest != arm64Manifest.Digest {
+		t.Errorf("Resolve() = %v, want %v", got, arm64Manifest)
+	}
+
+	// the error lists the available platforms
+	riscv := ocispec.Platform{Architecture: "riscv64", OS: "linux"}
+	_, err = oras.Resolve(ctx, s, "latest", oras.ResolveOptions{TargetPlatform: &riscv})
+	if !errors.Is(err, errdef.ErrNotFound) {
+		t.Fatalf("Resolve() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+	var nfErr *oras.PlatformNotFoundError
+	if !errors.As(err, &nfErr) {
+		t.Fatalf("Resolve() error = %v, want %T", err, nfErr)
+	}
+	if nfErr.Descriptor.Digest != root.Digest {
+		t.Errorf("PlatformNotFoundError.Descriptor = %v, want %v", nfErr.Descriptor, root)
+	}
+	if !reflect.DeepEqual(nfErr.Platform, &riscv) {
+		t.Errorf("PlatformNotFoundError.Platform = %v, want %v", nfErr.Platform, riscv)
+	}
+	if want := []ocispec.Platform{windows, amd64, arm64}; !reflect.DeepEqual(nfErr.Available, want) {
+		t.Errorf("PlatformNotFoundError.Available = %v, want %v", nfErr.Available, want)
+	}
+}
+
 func TestResolve_Repository(t *testing.T) {
 	arc_1 := "test-arc-1"
 	arc_2 := "test-arc-2"
@@ -2111,3 +2220,41 @@ func TestTagBytes(t *testing.T) {
 		t.Errorf("Memory.Fetch() = %v, want %v", got, content)
 	}
 }
+
+func TestParsePlatform(t *testing.T) {
+	tests := []struct {
+		specifier string
+		want      *ocispec.Platform
+		wantErr   bool
+	}{
+		{
+			specifier: "linux/amd64",
+			want:      &ocispec.Platform{OS: "linux", Architecture: "amd64"},
+		},
+		{
+			specifier: "linux/arm64/v8",
+			want:      &ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"},
+		},
+		{
+			specifier: "windows/amd64:10.0.20348.1726",
+			want:      &ocispec.Platform{OS: "windows", Architecture: "amd64", OSVersion: "10.0.20348.1726"},
+		},
+		{specifier: "linux", wantErr: true},
+		{specifier: "linux/", wantErr: true},
+		{specifier: "/amd64", wantErr: true},
+		{specifier: "linux/arm/", wantErr: true},
+		{specifier: "linux/arm/v7/extra", wantErr: true},
+		{specifier: "windows/amd64:", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.specifier, func(t *testing.T) {
+			got, err := oras.ParsePlatform(tt.specifier)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePlatform() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePlatform() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -21,17 +21,21 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
-	"golang.org/x/sync/semaphore"
 	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/internal/cas"
 	"oras.land/oras-go/v2/internal/descriptor"
-	"oras.land/oras-go/v2/internal/platform"
+	"oras.land/oras-go/v2/internal/pool"
 	"oras.land/oras-go/v2/internal/registryutil"
 	"oras.land/oras-go/v2/internal/status"
 	"oras.land/oras-go/v2/internal/syncutil"
+	"oras.land/oras-go/v2/logging"
+	"oras.land/oras-go/v2/metrics"
+	"oras.land/oras-go/v2/override"
 	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/tracing"
 )
 
 // defaultConcurrency is the default value of CopyGraphOptions.Concurrency.
@@ -53,6 +57,38 @@ type CopyOptions struct {
 	// reference will be passed to MapRoot, and the mapped descriptor will be
 	// used as the root node for copy.
 	MapRoot func(ctx context.Context, src content.ReadOnlyStorage, root ocispec.Descriptor) (ocispec.Descriptor, error)
+	// VerifyRoot verifies the root node after the graph is copied to the
+	// destination and before the root node is tagged.
+	// The fetcher fetches content from the destination.
+	// If VerifyRoot returns an error, the copy is aborted and the root node
+	// is not tagged.
+	// When VerifyRoot is provided, the root node is copied and tagged in
+	// separate requests even if the destination is a ReferencePusher.
+	VerifyRoot func(ctx context.Context, fetcher content.Fetcher, root ocispec.Descriptor) error
+	// ImmutableTag, if true, refuses to re-tag an existing destination
+	// reference to a different digest with an *ImmutableTagError.
+	// See also NewImmutableTarget.
+	ImmutableTag bool
+	// InjectAnnotations, if not nil, is called for each manifest and index
+	// in the graph, and returns the annotations to be added to it,
+	// overriding the existing values of the same keys.
+	// The changed manifests and indexes are copied with new digests, and
+	// the indexes are re-linked to them, while the source is not modified.
+	// The destination reference tags the new root node, which is returned.
+	// Annotations are not injected to docker manifests and manifest lists.
+	// The annotations are injected as a mapping of MapManifest, applied
+	// before CopyGraphOptions.MapManifest if both are set.
+	// See also WithInjectedAnnotations.
+	InjectAnnotations func(ctx context.Context, desc ocispec.Descriptor) (map[string]string, error)
+	// OnManifestPushed, if not nil, is called after each manifest or index is
+	// pushed to the destination, with a pusher to the destination, so that
+	// signatures or other artifacts can be attached as part of the copy.
+	// Manifests skipped as they already exist in the destination are not
+	// passed to it.
+	// If OnManifestPushed returns an error, the copy is aborted and the root
+	// node is not tagged. When OnManifestPushed is provided, the root node is
+	// tagged in a separate step after the graph is copied.
+	OnManifestPushed func(ctx context.Context, desc ocispec.Descriptor, pusher content.Pusher) error
 }
 
 // WithTargetPlatform configures opts.MapRoot to select the manifest whose
@@ -64,6 +100,9 @@ type CopyOptions struct {
 //   - If the root node is a manifest list, it will be mapped to the first
 //     matching manifest if exists, otherwise ErrNotFound will be returned.
 //   - Otherwise ErrUnsupported will be returned.
+//
+// The platform can be parsed from a specifier such as `linux/arm64/v8` by
+// ParsePlatform.
 func (opts *CopyOptions) WithTargetPlatform(p *ocispec.Platform) {
 	if p == nil {
 		return
@@ -75,7 +114,7 @@ func (opts *CopyOptions) WithTargetPlatform(p *ocispec.Platform) {
 				return ocispec.Descriptor{}, err
 			}
 		}
-		return platform.SelectManifest(ctx, src, root, p)
+		return selectManifest(ctx, src, root, p)
 	}
 }
 
@@ -109,6 +148,104 @@ type CopyGraphOptions struct {
 	// source storage to fetch large blobs.
 	// If FindSuccessors is nil, content.Successors will be used.
 	FindSuccessors func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error)
+	// ExistenceCache caches the results of the existence checks of the
+	// content in the destination, and can be shared across the copies to the
+	// same destination.
+	// If nil, the existence of each node is checked against the destination.
+	ExistenceCache *ExistenceCache
+	// PreflightConcurrency, if greater than 0, enables a pre-flight phase
+	// before copying, which walks the graph level by level and checks the
+	// existence of the nodes in the destination in concurrent batches of up
+	// to PreflightConcurrency checks. It reduces the latency of discovering
+	// huge graphs against destinations with slow existence checks, such as
+	// remote registries.
+	// The sub-DAGs rooted by the nodes existing in the destination are not
+	// walked.
+	PreflightConcurrency int
+	// Scheduler, if not nil, controls the order and the concurrency of the
+	// nodes being copied, and Concurrency is ignored.
+	// If nil, the nodes are copied in order with at most Concurrency nodes
+	// copied at the same time.
+	Scheduler Scheduler
+	// AllowIncompleteGraph, if true, tolerates the non-root nodes missing in
+	// the source, such as blobs absent from sparse mirrors or partially
+	// garbage-collected registries. Instead of aborting the copy, the missing
+	// nodes and the sub-DAGs rooted by them are skipped and passed to
+	// OnMissingContent, and the rest of the graph is copied.
+	// Setting AllowIncompleteGraph acknowledges that the copied graph may be
+	// incomplete in the destination.
+	// See also WithMissingContentReport.
+	AllowIncompleteGraph bool
+	// OnMissingContent, if not nil, is called for each node skipped as it is
+	// missing in the source when AllowIncompleteGraph is true.
+	// PreCopy may have been called for the node, but PostCopy is not.
+	OnMissingContent func(ctx context.Context, desc ocispec.Descriptor) error
+	// StateStore, if not nil, records the nodes committed to the
+	// destination, so that the copy can be resumed after an interruption by
+	// a subsequent copy with the same StateStore, without pushing the nodes
+	// committed by the previous copy again.
+	StateStore CopyStateStore
+	// OnCopyProgress, if not nil, is called as the content of each node is
+	// copied to the destination, with the number of bytes copied so far and
+	// the total size of the content. It is called with 0 bytes copied when
+	// the transfer of the node starts, and then every time a chunk of the
+	// content is read by the destination, so it should return quickly.
+	// Root nodes pushed with a reference are not reported.
+	OnCopyProgress func(ctx context.Context, desc ocispec.Descriptor, copied, total int64)
+	// MaxBytesPerSecond, if greater than 0, throttles the aggregate rate of
+	// the content copied by all the concurrent copy tasks of the operation,
+	// allowing bursts of up to one second of the rate.
+	// Manifests fetched for walking the graph are not throttled.
+	// To share a limit across operations, see RateLimiter and
+	// TransferManager.
+	MaxBytesPerSecond int64
+	// NodeFilter, if not nil, is consulted for each successor node found in
+	// the graph before walking it. If NodeFilter returns false, the node and
+	// its sub-DAG are excluded from the copy, unless the nodes of the sub-DAG
+	// are reachable through other nodes which are not excluded.
+	// The root node is always copied. The nodes are copied without their
+	// excluded successors, which may be rejected by destinations verifying
+	// the completeness of graphs, such as some registries.
+	// See also ExcludeMediaTypes and ExcludeArtifactTypes.
+	NodeFilter func(desc ocispec.Descriptor) bool
+	// MapManifest, if not nil, is called for each manifest and index in the
+	// graph with its content, and returns the content to be copied instead,
+	// such as the content with edited annotations or stripped layers.
+	// The successors are mapped before their predecessors, and the content
+	// passed to MapManifest already references the mapped successors.
+	// The changed manifests and indexes are copied with new digests and
+	// sizes, and the references of their predecessors are re-linked to
+	// them, while the source is not modified. The successors no longer
+	// referenced by the mapped content are not copied.
+	// Copy tags and returns the mapped root node. For CopyGraph, the mapped
+	// root node is the last node passed to PostCopy or OnCopySkipped.
+	MapManifest func(ctx context.Context, desc ocispec.Descriptor, content []byte) ([]byte, error)
+	// MaxDepth, if not 0, limits the depth of the copied graph, where the
+	// root node is at depth 0 and its successors are at depth 1, and so on.
+	// A positive MaxDepth copies the nodes up to that depth only, and a
+	// negative MaxDepth copies the root node only. For instance, a negative
+	// MaxDepth copies a manifest without its config and layers, and MaxDepth
+	// of 1 copies an index with its manifests but without their configs and
+	// layers.
+	// The nodes are copied without their successors beyond MaxDepth, which
+	// may be rejected by destinations verifying the completeness of graphs,
+	// such as some registries.
+	// If MaxDepth is 0, the whole graph is copied.
+	MaxDepth int
+	// VerifyPushed, if true, verifies each node after it is pushed to the
+	// destination, for end-to-end assurance against destinations corrupting
+	// the uploads. Manifests are fetched back to verify their sizes and
+	// digests, while only the existence and the sizes of blobs are verified,
+	// where the sizes are checked by HEAD requests on remote repositories.
+	// A node failing the verification aborts the copy with a
+	// *PushVerificationError identifying the node.
+	VerifyPushed bool
+	// ForeignLayerPolicy controls how the foreign layers and the
+	// non-distributable layers are handled. By default, they are skipped.
+	ForeignLayerPolicy ForeignLayerPolicy
+	// Summary, if not nil, is populated with the statistics of the copy,
+	// such as the copied and the skipped nodes and the elapsed time.
+	Summary *CopySummary
 }
 
 // Copy copies a rooted directed acyclic graph (DAG) with the tagged root node
@@ -116,24 +253,48 @@ type CopyGraphOptions struct {
 // The destination reference will be the same as the source reference if the
 // destination reference is left blank.
 //
+// References retaining both a tag and a digest, such as `v1@sha256:...` or
+// `localhost:5000/hello:v1@sha256:...`, are resolved by the digest, and their
+// tags are used as the destination reference if it is left blank. If the
+// destination reference retains a digest, it must match the copied root node.
+//
 // Returns the descriptor of the root node on successful copy.
-func Copy(ctx context.Context, src ReadOnlyTarget, srcRef string, dst Target, dstRef string, opts CopyOptions) (ocispec.Descriptor, error) {
+func Copy(ctx context.Context, src ReadOnlyTarget, srcRef string, dst Target, dstRef string, opts CopyOptions) (_ ocispec.Descriptor, err error) {
 	if src == nil {
 		return ocispec.Descriptor{}, errors.New("nil source target")
 	}
 	if dst == nil {
 		return ocispec.Descriptor{}, errors.New("nil destination target")
 	}
+	resolveRef, srcTagRef, _ := splitTagDigest(srcRef)
+	var dstDigest digest.Digest
+	if _, dstTagRef, dgst := splitTagDigest(dstRef); dstTagRef != "" {
+		dstRef, dstDigest = dstTagRef, dgst
+	}
+	if dstRef == "" {
+		dstRef = srcTagRef
+	}
 	if dstRef == "" {
 		dstRef = srcRef
 	}
+	if opts.ImmutableTag {
+		dst = NewImmutableTarget(dst)
+	}
+	ctx, span := tracing.Start(ctx, "oras.Copy",
+		tracing.String("oras.src_reference", srcRef),
+		tracing.String("oras.dst_reference", dstRef))
+	defer func() { span.End(err) }()
+	if opts.Summary != nil {
+		opts.Summary.begin()
+		defer opts.Summary.end()
+	}
 
 	// use caching proxy on non-leaf nodes
 	if opts.MaxMetadataBytes <= 0 {
 		opts.MaxMetadataBytes = defaultCopyMaxMetadataBytes
 	}
 	proxy := cas.NewProxyWithLimit(src, cas.NewMemory(), opts.MaxMetadataBytes)
-	root, err := resolveRoot(ctx, src, srcRef, proxy)
+	root, err := resolveRoot(ctx, src, resolveRef, proxy)
 	if err != nil {
 		return ocispec.Descriptor{}, fmt.Errorf("failed to resolve %s: %w", srcRef, err)
 	}
@@ -146,15 +307,86 @@ func Copy(ctx context.Context, src ReadOnlyTarget, srcRef string, dst Target, ds
 		}
 		proxy.StopCaching = false
 	}
+	if opts.InjectAnnotations != nil {
+		// annotations are injected before the other mappings
+		mapManifest := opts.MapManifest
+		injectAnnotations := annotationMapper(opts.InjectAnnotations)
+		opts.MapManifest = injectAnnotations
+		if mapManifest != nil {
+			opts.MapManifest = func(ctx context.Context, desc ocispec.Descriptor, content []byte) ([]byte, error) {
+				content, err := injectAnnotations(ctx, desc, content)
+				if err != nil {
+					return nil, err
+				}
+				return mapManifest(ctx, desc, content)
+			}
+		}
+	}
+	if opts.MapManifest != nil {
+		// stage the mapped nodes in the cache, from which they are copied
+		mapped, err := mapManifests(ctx, proxy, proxy.Cache, root, opts.CopyGraphOptions)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		if opts.InjectAnnotations != nil && mapped.Digest != root.Digest {
+			if mapped.Annotations, err = mappedRootAnnotations(ctx, proxy.Cache, mapped); err != nil {
+				return ocispec.Descriptor{}, err
+			}
+		}
+		root = mapped
+		// the graph is mapped already
+		opts.MapManifest = nil
+	}
+	if dstDigest != "" && root.Digest != dstDigest {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: %s: %w", root.Digest, root.MediaType, content.ErrMismatchedDigest)
+	}
 
-	if err := prepareCopy(ctx, dst, dstRef, proxy, root, &opts); err != nil {
-		return ocispec.Descriptor{}, err
+	if opts.ImmutableTag {
+		// fail fast before copying the graph
+		if err := checkImmutableTag(ctx, dst, root, dstRef); err != nil {
+			return ocispec.Descriptor{}, err
+		}
 	}
 
-	if err := copyGraph(ctx, src, dst, root, proxy, nil, nil, opts.CopyGraphOptions); err != nil {
-		return ocispec.Descriptor{}, err
+	if opts.OnManifestPushed != nil {
+		postCopy := opts.PostCopy
+		opts.PostCopy = func(ctx context.Context, desc ocispec.Descriptor) error {
+			if postCopy != nil {
+				if err := postCopy(ctx, desc); err != nil {
+					return err
+				}
+			}
+			if !descriptor.IsManifest(desc) {
+				return nil
+			}
+			return opts.OnManifestPushed(ctx, desc, dst)
+		}
 	}
 
+	if opts.VerifyRoot != nil || opts.OnManifestPushed != nil {
+		// copy the graph without tagging, and tag the root node only after
+		// it is verified and the hooks succeed
+		if err := copyGraph(ctx, src, dst, root, proxy, nil, nil, opts.CopyGraphOptions); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		if opts.VerifyRoot != nil {
+			if err := opts.VerifyRoot(ctx, dst, root); err != nil {
+				return ocispec.Descriptor{}, err
+			}
+		}
+		if err := dst.Tag(ctx, root, dstRef); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	} else {
+		if err := prepareCopy(ctx, dst, dstRef, proxy, root, &opts); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		if err := copyGraph(ctx, src, dst, root, proxy, nil, nil, opts.CopyGraphOptions); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+
+	logging.FromContext(ctx).Info("copied", "src", srcRef, "dst", dstRef, "digest", root.Digest)
 	return root, nil
 }
 
@@ -165,9 +397,16 @@ func CopyGraph(ctx context.Context, src content.ReadOnlyStorage, dst content.Sto
 }
 
 // copyGraph copies a rooted directed acyclic graph (DAG) from the source CAS to
-// the destination CAS with specified caching, concurrency limiter and tracker.
+// the destination CAS with specified caching, scheduler and tracker.
 func copyGraph(ctx context.Context, src content.ReadOnlyStorage, dst content.Storage, root ocispec.Descriptor,
-	proxy *cas.Proxy, limiter *semaphore.Weighted, tracker *status.Tracker, opts CopyGraphOptions) error {
+	proxy *cas.Proxy, scheduler Scheduler, tracker *status.Tracker, opts CopyGraphOptions) (err error) {
+	ctx, span := tracing.Start(ctx, "oras.CopyGraph", tracing.DescriptorAttributes(root)...)
+	defer func() { span.End(err) }()
+	if opts.Summary != nil {
+		opts.Summary.begin()
+		defer opts.Summary.end()
+	}
+
 	if proxy == nil {
 		// use caching proxy on non-leaf nodes
 		if opts.MaxMetadataBytes <= 0 {
@@ -175,13 +414,10 @@ func copyGraph(ctx context.Context, src content.ReadOnlyStorage, dst content.Sto
 		}
 		proxy = cas.NewProxyWithLimit(src, cas.NewMemory(), opts.MaxMetadataBytes)
 	}
-	if limiter == nil {
-		// if Concurrency is not set or invalid, use the default concurrency
-		if opts.Concurrency <= 0 {
-			opts.Concurrency = defaultConcurrency
-		}
-		limiter = semaphore.NewWeighted(int64(opts.Concurrency))
+	if scheduler == nil {
+		scheduler = newScheduler(ctx, opts)
 	}
+	src = throttleSource(src, &opts)
 	if tracker == nil {
 		// track content status
 		tracker = status.NewTracker()
@@ -190,6 +426,49 @@ func copyGraph(ctx context.Context, src content.ReadOnlyStorage, dst content.Sto
 	if opts.FindSuccessors == nil {
 		opts.FindSuccessors = content.Successors
 	}
+	if opts.NodeFilter != nil {
+		opts.FindSuccessors = filterSuccessors(opts.FindSuccessors, opts.NodeFilter)
+	}
+	if opts.MapManifest != nil {
+		// stage the mapped nodes in the cache, from which they are copied
+		root, err = mapManifests(ctx, proxy, proxy.Cache, root, opts)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.MaxDepth != 0 {
+		findSuccessors, err := depthLimitedSuccessors(ctx, proxy, root, opts)
+		if err != nil {
+			return err
+		}
+		opts.FindSuccessors = findSuccessors
+	}
+	if opts.VerifyPushed {
+		// verify before the content is recorded as committed
+		dst = &verifyingStorage{Storage: dst}
+	}
+	if opts.StateStore != nil {
+		dst = &stateRecordingStorage{
+			Storage: dst,
+			state:   opts.StateStore,
+		}
+	}
+	if opts.ExistenceCache != nil {
+		dst = &existenceCachedStorage{
+			Storage: dst,
+			cache:   opts.ExistenceCache,
+		}
+	}
+	if opts.PreflightConcurrency > 0 {
+		results, err := preflightExistence(ctx, proxy, dst, root, opts)
+		if err != nil {
+			return err
+		}
+		dst = &preflightStorage{
+			Storage: dst,
+			results: results,
+		}
+	}
 
 	// traverse the graph
 	var fn syncutil.GoFunc[ocispec.Descriptor]
@@ -212,6 +491,11 @@ func copyGraph(ctx context.Context, src content.ReadOnlyStorage, dst content.Sto
 			return err
 		}
 		if exists {
+			logging.FromContext(ctx).Debug("skipped existing content", "digest", desc.Digest, "mediaType", desc.MediaType)
+			metrics.AddCounter(ctx, metrics.CacheHits, 1, metrics.Label{Name: "cache", Value: "destination"})
+			if opts.Summary != nil {
+				opts.Summary.addSkipped(desc)
+			}
 			if opts.OnCopySkipped != nil {
 				if err := opts.OnCopySkipped(ctx, desc); err != nil {
 					return err
@@ -222,15 +506,22 @@ func copyGraph(ctx context.Context, src content.ReadOnlyStorage, dst content.Sto
 
 		// find successors while non-leaf nodes will be fetched and cached
 		successors, err := opts.FindSuccessors(ctx, proxy, desc)
+		if err != nil {
+			if isMissingContent(err, desc, root, opts) {
+				return reportMissingContent(ctx, desc, opts)
+			}
+			return err
+		}
+		successors, err = applyForeignLayerPolicy(ctx, successors, opts.ForeignLayerPolicy)
 		if err != nil {
 			return err
 		}
-		successors = removeForeignLayers(successors)
 
 		if len(successors) != 0 {
 			// for non-leaf nodes, process successors and wait for them to complete
 			region.End()
-			if err := syncutil.Go(ctx, limiter, fn, successors...); err != nil {
+			successors = scheduler.Submit(ctx, successors)
+			if err := syncutil.GoLimited[ocispec.Descriptor](ctx, scheduler, fn, successors...); err != nil {
 				return err
 			}
 			for _, node := range successors {
@@ -256,26 +547,80 @@ func copyGraph(ctx context.Context, src content.ReadOnlyStorage, dst content.Sto
 		if exists {
 			return copyNode(ctx, proxy.Cache, dst, desc, opts)
 		}
-		return copyNode(ctx, src, dst, desc, opts)
+		if err := copyNode(ctx, src, dst, desc, opts); err != nil {
+			var notFoundErr *sourceNotFoundError
+			if errors.As(err, &notFoundErr) && isMissingContent(err, desc, root, opts) {
+				return reportMissingContent(ctx, desc, opts)
+			}
+			return err
+		}
+		return nil
 	}
 
-	return syncutil.Go(ctx, limiter, fn, root)
+	return syncutil.GoLimited[ocispec.Descriptor](ctx, scheduler, fn, scheduler.Submit(ctx, []ocispec.Descriptor{root})...)
+}
+
+// newScheduler returns opts.Scheduler if set, or the default scheduler
+// limited by the concurrency.
+func newScheduler(ctx context.Context, opts CopyGraphOptions) Scheduler {
+	if opts.Scheduler != nil {
+		return opts.Scheduler
+	}
+	concurrency := opts.Concurrency
+	if c := override.FromContext(ctx).Concurrency; c > 0 {
+		concurrency = c
+	}
+	return NewSemaphoreScheduler(concurrency)
 }
 
 // doCopyNode copies a single content from the source CAS to the destination CAS.
-func doCopyNode(ctx context.Context, src content.ReadOnlyStorage, dst content.Storage, desc ocispec.Descriptor) error {
+func doCopyNode(ctx context.Context, src content.ReadOnlyStorage, dst content.Storage, desc ocispec.Descriptor) (err error) {
+	ctx, span := tracing.Start(ctx, "oras.CopyNode", tracing.DescriptorAttributes(desc)...)
+	defer func() { span.End(err) }()
+	logging.FromContext(ctx).Debug("copying content", "digest", desc.Digest, "mediaType", desc.MediaType, "size", desc.Size)
+	ctx, cancel := override.WithBlobTimeout(ctx)
+	defer cancel()
+
 	rc, err := src.Fetch(ctx, desc)
 	if err != nil {
+		if errors.Is(err, errdef.ErrNotFound) {
+			return &sourceNotFoundError{err: err}
+		}
 		return err
 	}
 	defer rc.Close()
-	err = dst.Push(ctx, desc, rc)
+	err = dst.Push(ctx, desc, newPooledReader(rc))
 	if err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
 		return err
 	}
+	metrics.AddCounter(ctx, metrics.BlobsCopied, 1)
+	metrics.AddCounter(ctx, metrics.BytesTransferred, float64(desc.Size))
 	return nil
 }
 
+// pooledReader is a reader implementing io.WriterTo with pooled buffers, so
+// that pushers copying the content with io.Copy, such as net/http, do not
+// allocate a buffer per blob.
+type pooledReader struct {
+	io.Reader
+}
+
+// newPooledReader wraps r as a pooledReader unless r is seekable or already
+// implements io.WriterTo, in which case pushers may optimize for r itself.
+func newPooledReader(r io.Reader) io.Reader {
+	switch r.(type) {
+	case io.Seeker, io.WriterTo:
+		return r
+	}
+	return pooledReader{Reader: r}
+}
+
+// WriteTo writes the content to w through a pooled buffer.
+func (r pooledReader) WriteTo(w io.Writer) (int64, error) {
+	// hide the io.WriterTo of r to prevent io.CopyBuffer from recursing
+	return pool.Stream.Copy(w, struct{ io.Reader }{r.Reader})
+}
+
 // copyNode copies a single content from the source CAS to the destination CAS,
 // and apply the given options.
 func copyNode(ctx context.Context, src content.ReadOnlyStorage, dst content.Storage, desc ocispec.Descriptor, opts CopyGraphOptions) error {
@@ -288,9 +633,18 @@ func copyNode(ctx context.Context, src content.ReadOnlyStorage, dst content.Stor
 		}
 	}
 
+	if opts.OnCopyProgress != nil {
+		src = &progressStorage{
+			ReadOnlyStorage: src,
+			onProgress:      opts.OnCopyProgress,
+		}
+	}
 	if err := doCopyNode(ctx, src, dst, desc); err != nil {
 		return err
 	}
+	if opts.Summary != nil {
+		opts.Summary.addCopied(desc)
+	}
 
 	if opts.PostCopy != nil {
 		return opts.PostCopy(ctx, desc)
@@ -314,6 +668,22 @@ func copyCachedNodeWithReference(ctx context.Context, src *cas.Proxy, dst regist
 	return nil
 }
 
+// splitTagDigest splits a reference retaining both a tag and a digest into
+// the reference to resolve by the digest, the tag reference and the digest.
+// Other references are returned as is, with an empty tag reference.
+func splitTagDigest(reference string) (digestRef string, tagRef string, dgst digest.Digest) {
+	if ref, err := registry.ParseTagDigestReference(reference); err == nil {
+		if ref.Tag == "" || ref.Tag == ref.Reference.Reference {
+			return reference, "", ""
+		}
+		return ref.Reference.String(), ref.TagReference().String(), digest.Digest(ref.Reference.Reference)
+	}
+	if tag, d, err := registry.SplitTagDigest(reference); err == nil && tag != "" && d != "" {
+		return d, tag, digest.Digest(d)
+	}
+	return reference, "", ""
+}
+
 // resolveRoot resolves the source reference to the root node.
 func resolveRoot(ctx context.Context, src ReadOnlyTarget, srcRef string, proxy *cas.Proxy) (ocispec.Descriptor, error) {
 	refFetcher, ok := src.(registry.ReferenceFetcher)
@@ -367,6 +737,14 @@ func prepareCopy(ctx context.Context, dst Target, dstRef string, proxy *cas.Prox
 			if err := copyCachedNodeWithReference(ctx, proxy, refPusher, desc, dstRef); err != nil {
 				return err
 			}
+			if opts.VerifyPushed {
+				if err := verifyPushed(ctx, dst, desc); err != nil {
+					return err
+				}
+			}
+			if opts.Summary != nil {
+				opts.Summary.addCopied(desc)
+			}
 			if opts.PostCopy != nil {
 				if err := opts.PostCopy(ctx, desc); err != nil {
 					return err
@@ -412,10 +790,12 @@ func prepareCopy(ctx context.Context, dst Target, dstRef string, proxy *cas.Prox
 }
 
 // removeForeignLayers in-place removes all foreign layers in the given slice.
-func removeForeignLayers(descs []ocispec.Descriptor) []ocispec.Descriptor {
+func removeForeignLayers(ctx context.Context, descs []ocispec.Descriptor) []ocispec.Descriptor {
 	var j int
 	for i, desc := range descs {
-		if !descriptor.IsForeignLayer(desc) {
+		if descriptor.IsForeignLayer(desc) {
+			logging.FromContext(ctx).Debug("skipped foreign layer", "digest", desc.Digest, "mediaType", desc.MediaType)
+		} else {
 			if i != j {
 				descs[j] = desc
 			}
@@ -0,0 +1,45 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func Test_newPooledReader(t *testing.T) {
+	// seekable readers and io.WriterTo are kept for pusher optimizations
+	br := bytes.NewReader([]byte("hello"))
+	if got := newPooledReader(br); got != io.Reader(br) {
+		t.Errorf("newPooledReader() = %T, want %T", got, br)
+	}
+
+	content := strings.Repeat("hello world", 10000)
+	r := newPooledReader(struct{ io.Reader }{strings.NewReader(content)})
+	if _, ok := r.(pooledReader); !ok {
+		t.Fatalf("newPooledReader() = %T, want pooledReader", r)
+	}
+	var buf bytes.Buffer
+	n, err := io.Copy(struct{ io.Writer }{&buf}, r)
+	if err != nil {
+		t.Fatal("io.Copy() error =", err)
+	}
+	if n != int64(len(content)) || buf.String() != content {
+		t.Errorf("io.Copy() = %d, want %d", n, len(content))
+	}
+}
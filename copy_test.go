@@ -24,8 +24,10 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -37,6 +39,9 @@ import (
 	"oras.land/oras-go/v2/internal/cas"
 	"oras.land/oras-go/v2/internal/docker"
 	"oras.land/oras-go/v2/internal/spec"
+	"oras.land/oras-go/v2/logging"
+	"oras.land/oras-go/v2/override"
+	"oras.land/oras-go/v2/tracing"
 )
 
 // storageTracker tracks storage API counts.
@@ -1727,3 +1732,341 @@ func TestCopyGraph_ForeignLayers_Mixed(t *testing.T) {
 		t.Errorf("count(dst.Exists()) = %v, want %v", got, want)
 	}
 }
+
+// spanNameRecorder records the names of the started spans.
+type spanNameRecorder struct {
+	lock  sync.Mutex
+	names map[string]int
+}
+
+func (r *spanNameRecorder) Start(ctx context.Context, name string, _ ...tracing.Attribute) (context.Context, tracing.Span) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.names == nil {
+		r.names = make(map[string]int)
+	}
+	r.names[name]++
+	return ctx, r
+}
+
+func (r *spanNameRecorder) SetAttributes(...tracing.Attribute) {}
+
+func (r *spanNameRecorder) End(error) {}
+
+func TestCopy_Tracing(t *testing.T) {
+	src := memory.New()
+	dst := memory.New()
+	ctx := context.Background()
+
+	blobs := [][]byte{
+		[]byte("config"),
+		[]byte("foo"),
+		[]byte("bar"),
+	}
+	var descs []ocispec.Descriptor
+	for _, blob := range blobs {
+		desc, err := oras.PushBytes(ctx, src, "test", blob)
+		if err != nil {
+			t.Fatal("PushBytes() error =", err)
+		}
+		descs = append(descs, desc)
+	}
+	manifest, err := oras.Pack(ctx, src, "", descs[1:], oras.PackOptions{
+		PackImageManifest: true,
+		ConfigDescriptor:  &descs[0],
+	})
+	if err != nil {
+		t.Fatal("Pack() error =", err)
+	}
+	ref := "foobar"
+	if err := src.Tag(ctx, manifest, ref); err != nil {
+		t.Fatal("Tag() error =", err)
+	}
+
+	recorder := &spanNameRecorder{}
+	ctx = tracing.WithTracer(ctx, recorder)
+	if _, err := oras.Copy(ctx, src, ref, dst, "", oras.DefaultCopyOptions); err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+	want := map[string]int{
+		"oras.Copy":            1,
+		"oras.CopyGraph":       1,
+		"oras.CopyNode":        4,
+		"memory.Store.Resolve": 1,
+		"memory.Store.Exists":  4,
+		"memory.Store.Fetch":   4,
+		"memory.Store.Push":    4,
+		"memory.Store.Tag":     1,
+	}
+	if !reflect.DeepEqual(recorder.names, want) {
+		t.Errorf("span names = %v, want %v", recorder.names, want)
+	}
+}
+
+type messageRecorder struct {
+	lock     sync.Mutex
+	messages map[string]int
+}
+
+func (r *messageRecorder) record(msg string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.messages == nil {
+		r.messages = make(map[string]int)
+	}
+	r.messages[msg]++
+}
+
+func (r *messageRecorder) Debug(msg string, keysAndValues ...interface{}) {
+	r.record(msg)
+}
+
+func (r *messageRecorder) Info(msg string, keysAndValues ...interface{}) {
+	r.record(msg)
+}
+
+func TestCopy_Logging(t *testing.T) {
+	src := memory.New()
+	dst := memory.New()
+	ctx := context.Background()
+
+	blobs := [][]byte{
+		[]byte("config"),
+		[]byte("foo"),
+	}
+	var descs []ocispec.Descriptor
+	for _, blob := range blobs {
+		desc, err := oras.PushBytes(ctx, src, "test", blob)
+		if err != nil {
+			t.Fatal("PushBytes() error =", err)
+		}
+		descs = append(descs, desc)
+	}
+	manifest, err := oras.Pack(ctx, src, "", descs[1:], oras.PackOptions{
+		PackImageManifest: true,
+		ConfigDescriptor:  &descs[0],
+	})
+	if err != nil {
+		t.Fatal("Pack() error =", err)
+	}
+	ref := "foobar"
+	if err := src.Tag(ctx, manifest, ref); err != nil {
+		t.Fatal("Tag() error =", err)
+	}
+	// pre-populate the config to be skipped
+	if err := dst.Push(ctx, descs[0], bytes.NewReader(blobs[0])); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+
+	recorder := &messageRecorder{}
+	ctx = logging.WithLogger(ctx, recorder)
+	if _, err := oras.Copy(ctx, src, ref, dst, "", oras.DefaultCopyOptions); err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+	want := map[string]int{
+		"copied":                   1,
+		"copying content":          2,
+		"skipped existing content": 1,
+	}
+	if !reflect.DeepEqual(recorder.messages, want) {
+		t.Errorf("messages = %v, want %v", recorder.messages, want)
+	}
+}
+
+func TestCopy_VerifyRoot(t *testing.T) {
+	src := memory.New()
+	ctx := context.Background()
+
+	blobs := [][]byte{
+		[]byte("config"),
+		[]byte("foo"),
+		[]byte("bar"),
+	}
+	var descs []ocispec.Descriptor
+	for _, blob := range blobs {
+		desc, err := oras.PushBytes(ctx, src, "test", blob)
+		if err != nil {
+			t.Fatal("PushBytes() error =", err)
+		}
+		descs = append(descs, desc)
+	}
+	manifest, err := oras.Pack(ctx, src, "", descs[1:], oras.PackOptions{
+		PackImageManifest: true,
+		ConfigDescriptor:  &descs[0],
+	})
+	if err != nil {
+		t.Fatal("Pack() error =", err)
+	}
+	descs = append(descs, manifest)
+	ref := "foobar"
+	if err := src.Tag(ctx, manifest, ref); err != nil {
+		t.Fatal("Tag() error =", err)
+	}
+
+	t.Run("verified", func(t *testing.T) {
+		dst := memory.New()
+		opts := oras.CopyOptions{
+			VerifyRoot: func(ctx context.Context, fetcher content.Fetcher, root ocispec.Descriptor) error {
+				if !content.Equal(root, manifest) {
+					t.Errorf("VerifyRoot() root = %v, want %v", root, manifest)
+				}
+				// the graph should be fully copied before verification
+				for i, desc := range descs {
+					exists, err := dst.Exists(ctx, desc)
+					if err != nil {
+						t.Fatalf("dst.Exists(%d) error = %v", i, err)
+					}
+					if !exists {
+						t.Errorf("dst.Exists(%d) = %v, want %v", i, exists, true)
+					}
+				}
+				if _, err := dst.Resolve(ctx, ref); !errors.Is(err, errdef.ErrNotFound) {
+					t.Errorf("dst.Resolve() error = %v, wantErr %v", err, errdef.ErrNotFound)
+				}
+				if _, err := content.FetchAll(ctx, fetcher, root); err != nil {
+					t.Errorf("FetchAll() error = %v", err)
+				}
+				return nil
+			},
+		}
+		if _, err := oras.Copy(ctx, src, ref, dst, "", opts); err != nil {
+			t.Fatal("Copy() error =", err)
+		}
+		got, err := dst.Resolve(ctx, ref)
+		if err != nil {
+			t.Fatal("dst.Resolve() error =", err)
+		}
+		if !reflect.DeepEqual(got, manifest) {
+			t.Errorf("dst.Resolve() = %v, want %v", got, manifest)
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		dst := memory.New()
+		errRejected := errors.New("rejected")
+		opts := oras.CopyOptions{
+			VerifyRoot: func(ctx context.Context, fetcher content.Fetcher, root ocispec.Descriptor) error {
+				return errRejected
+			},
+		}
+		if _, err := oras.Copy(ctx, src, ref, dst, "", opts); !errors.Is(err, errRejected) {
+			t.Fatalf("Copy() error = %v, wantErr %v", err, errRejected)
+		}
+		if _, err := dst.Resolve(ctx, ref); !errors.Is(err, errdef.ErrNotFound) {
+			t.Errorf("dst.Resolve() error = %v, wantErr %v", err, errdef.ErrNotFound)
+		}
+	})
+}
+
+func TestCopy_TagDigestReference(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	layer := []byte("layer")
+	layerDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+	if err := src.Push(ctx, layerDesc, bytes.NewReader(layer)); err != nil {
+		t.Fatalf("failed to push layer: %v", err)
+	}
+	manifestDesc, err := oras.Pack(ctx, src, "", []ocispec.Descriptor{layerDesc}, oras.PackOptions{
+		PackImageManifest: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to pack manifest: %v", err)
+	}
+	// memory stores resolve digests only if tagged by the digests
+	for _, ref := range []string{"v1", manifestDesc.Digest.String()} {
+		if err := src.Tag(ctx, manifestDesc, ref); err != nil {
+			t.Fatalf("failed to tag manifest: %v", err)
+		}
+	}
+	srcRef := "v2@" + manifestDesc.Digest.String()
+
+	// the tag of the source reference names the destination
+	dst := memory.New()
+	got, err := oras.Copy(ctx, src, srcRef, dst, "", oras.CopyOptions{})
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if !content.Equal(got, manifestDesc) {
+		t.Errorf("Copy() = %v, want %v", got, manifestDesc)
+	}
+	desc, err := dst.Resolve(ctx, "v2")
+	if err != nil {
+		t.Fatalf("dst.Resolve() error = %v", err)
+	}
+	if !content.Equal(desc, manifestDesc) {
+		t.Errorf("dst.Resolve() = %v, want %v", desc, manifestDesc)
+	}
+
+	// the destination reference retaining a matching digest
+	if _, err := oras.Copy(ctx, src, "v1", dst, "v3@"+manifestDesc.Digest.String(), oras.CopyOptions{}); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if _, err := dst.Resolve(ctx, "v3"); err != nil {
+		t.Errorf("dst.Resolve() error = %v", err)
+	}
+
+	// the destination reference retaining a mismatched digest
+	wrongRef := "v4@" + digest.FromString("foo").String()
+	if _, err := oras.Copy(ctx, src, "v1", dst, wrongRef, oras.CopyOptions{}); !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("Copy() error = %v, want %v", err, content.ErrMismatchedDigest)
+	}
+	if _, err := dst.Resolve(ctx, "v4"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("dst.Resolve() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+}
+
+// slowPushStorage is a storage blocking pushes of layers until the context
+// is done, while recording the maximum number of concurrent pushes.
+type slowPushStorage struct {
+	content.Storage
+	active    int64
+	maxActive int64
+}
+
+func (s *slowPushStorage) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	active := atomic.AddInt64(&s.active, 1)
+	defer atomic.AddInt64(&s.active, -1)
+	for {
+		max := atomic.LoadInt64(&s.maxActive)
+		if active <= max || atomic.CompareAndSwapInt64(&s.maxActive, max, active) {
+			break
+		}
+	}
+	if expected.MediaType == ocispec.MediaTypeImageLayer {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return s.Storage.Push(ctx, expected, content)
+}
+
+func TestCopy_OverrideOptions(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	var layers []ocispec.Descriptor
+	for i := 0; i < 4; i++ {
+		desc, err := oras.PushBytes(ctx, src, ocispec.MediaTypeImageLayer, []byte(fmt.Sprintf("layer %d", i)))
+		if err != nil {
+			t.Fatal("PushBytes() error =", err)
+		}
+		layers = append(layers, desc)
+	}
+	root, err := oras.Pack(ctx, src, "", layers, oras.PackOptions{})
+	if err != nil {
+		t.Fatal("Pack() error =", err)
+	}
+
+	dst := &slowPushStorage{Storage: memory.New()}
+	opts := oras.CopyGraphOptions{
+		Concurrency: 1,
+	}
+	ctx = override.WithOptions(ctx, override.Options{
+		BlobTimeout: 100 * time.Millisecond,
+		Concurrency: 4,
+	})
+	if err := oras.CopyGraph(ctx, src, dst, root, opts); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("CopyGraph() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if got := atomic.LoadInt64(&dst.maxActive); got != 4 {
+		t.Errorf("max concurrent pushes = %d, want %d", got, 4)
+	}
+}
@@ -0,0 +1,205 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// CopyEventType is the type of a CopyEvent.
+type CopyEventType int
+
+// Types of copy events.
+const (
+	// CopyEventNodeDiscovered is emitted when a node of the graph is
+	// discovered, including the root node.
+	CopyEventNodeDiscovered CopyEventType = iota
+	// CopyEventTransferStarted is emitted before a node is copied.
+	CopyEventTransferStarted
+	// CopyEventProgress is emitted as the content of a node is pushed to the
+	// destination, with the number of bytes pushed so far.
+	CopyEventProgress
+	// CopyEventSkipped is emitted when the sub-DAG rooted by a node is
+	// skipped since the node exists in the destination.
+	CopyEventSkipped
+	// CopyEventCompleted is emitted after a node is copied.
+	CopyEventCompleted
+	// CopyEventError is emitted as the last event if the copy fails.
+	CopyEventError
+)
+
+// String returns the name of the event type.
+func (t CopyEventType) String() string {
+	switch t {
+	case CopyEventNodeDiscovered:
+		return "NodeDiscovered"
+	case CopyEventTransferStarted:
+		return "TransferStarted"
+	case CopyEventProgress:
+		return "Progress"
+	case CopyEventSkipped:
+		return "Skipped"
+	case CopyEventCompleted:
+		return "Completed"
+	case CopyEventError:
+		return "Error"
+	default:
+		return "Unknown"
+	}
+}
+
+// CopyEvent is an event of a copy operation.
+type CopyEvent struct {
+	// Type is the type of the event.
+	Type CopyEventType
+	// Descriptor describes the node of the event. For CopyEventError, it is
+	// the root node.
+	Descriptor ocispec.Descriptor
+	// BytesTransferred is the number of bytes of the node pushed so far.
+	// Set for CopyEventProgress and CopyEventCompleted.
+	BytesTransferred int64
+	// Err is the error failing the copy. Set for CopyEventError only.
+	Err error
+}
+
+const (
+	// copyEventBufferSize is the size of the buffer of the event channel.
+	copyEventBufferSize = 64
+	// copyEventProgressStep is the minimum number of bytes pushed between two
+	// progress events of a node.
+	copyEventProgressStep int64 = 1024 * 1024 // 1 MiB
+)
+
+// CopyGraphEvents copies a rooted directed acyclic graph (DAG) like
+// oras.CopyGraph in a separate goroutine, and returns a channel of the events
+// of the copy. The channel is closed when the copy ends. If the copy fails,
+// the last event is a CopyEventError.
+//
+// The copy is paused while the channel is full, so the caller must drain the
+// channel, or cancel ctx to abort the copy.
+// The callbacks of opts, such as PreCopy, are called before the corresponding
+// events are emitted.
+func CopyGraphEvents(ctx context.Context, src content.ReadOnlyStorage, dst content.Storage, root ocispec.Descriptor, opts CopyGraphOptions) <-chan CopyEvent {
+	events := make(chan CopyEvent, copyEventBufferSize)
+	emit := func(ctx context.Context, event CopyEvent) error {
+		select {
+		case events <- event:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	findSuccessors := opts.FindSuccessors
+	if findSuccessors == nil {
+		findSuccessors = content.Successors
+	}
+	opts.FindSuccessors = func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		successors, err := findSuccessors(ctx, fetcher, desc)
+		if err != nil {
+			return nil, err
+		}
+		for _, successor := range successors {
+			if err := emit(ctx, CopyEvent{Type: CopyEventNodeDiscovered, Descriptor: successor}); err != nil {
+				return nil, err
+			}
+		}
+		return successors, nil
+	}
+	preCopy := opts.PreCopy
+	opts.PreCopy = func(ctx context.Context, desc ocispec.Descriptor) error {
+		if preCopy != nil {
+			if err := preCopy(ctx, desc); err != nil {
+				return err
+			}
+		}
+		return emit(ctx, CopyEvent{Type: CopyEventTransferStarted, Descriptor: desc})
+	}
+	postCopy := opts.PostCopy
+	opts.PostCopy = func(ctx context.Context, desc ocispec.Descriptor) error {
+		if postCopy != nil {
+			if err := postCopy(ctx, desc); err != nil {
+				return err
+			}
+		}
+		return emit(ctx, CopyEvent{Type: CopyEventCompleted, Descriptor: desc, BytesTransferred: desc.Size})
+	}
+	onCopySkipped := opts.OnCopySkipped
+	opts.OnCopySkipped = func(ctx context.Context, desc ocispec.Descriptor) error {
+		if onCopySkipped != nil {
+			if err := onCopySkipped(ctx, desc); err != nil {
+				return err
+			}
+		}
+		return emit(ctx, CopyEvent{Type: CopyEventSkipped, Descriptor: desc})
+	}
+	dst = content.WithPusherMiddleware(dst, func(next content.Pusher) content.Pusher {
+		return content.PusherFunc(func(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+			return next.Push(ctx, expected, &progressEventReader{
+				ctx:        ctx,
+				r:          r,
+				desc:       expected,
+				emit:       emit,
+				reportNext: copyEventProgressStep,
+			})
+		})
+	})
+
+	go func() {
+		defer close(events)
+		if err := emit(ctx, CopyEvent{Type: CopyEventNodeDiscovered, Descriptor: root}); err != nil {
+			return
+		}
+		if err := CopyGraph(ctx, src, dst, root, opts); err != nil {
+			emit(ctx, CopyEvent{Type: CopyEventError, Descriptor: root, Err: err})
+		}
+	}()
+	return events
+}
+
+// progressEventReader emits progress events as the content is read.
+type progressEventReader struct {
+	ctx        context.Context
+	r          io.Reader
+	desc       ocispec.Descriptor
+	emit       func(ctx context.Context, event CopyEvent) error
+	read       int64
+	reported   int64
+	reportNext int64
+}
+
+// Read reads from the underlying reader, and emits a progress event every
+// copyEventProgressStep bytes and at the end of the content.
+func (r *progressEventReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.read += int64(n)
+	if r.read >= r.reportNext || (err == io.EOF && r.read > r.reported) {
+		r.reported = r.read
+		r.reportNext = r.read + copyEventProgressStep
+		if emitErr := r.emit(r.ctx, CopyEvent{
+			Type:             CopyEventProgress,
+			Descriptor:       r.desc,
+			BytesTransferred: r.read,
+		}); emitErr != nil {
+			return n, emitErr
+		}
+	}
+	return n, err
+}
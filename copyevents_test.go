@@ -0,0 +1,123 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestCopyGraphEvents(t *testing.T) {
+	ctx := context.Background()
+	src, root := pushImage(t, []byte(`{}`), []byte("hello world"), "latest")
+	dst := memory.New()
+
+	counts := make(map[CopyEventType]int)
+	var progress int64
+	for event := range CopyGraphEvents(ctx, src, dst, root, CopyGraphOptions{}) {
+		counts[event.Type]++
+		switch event.Type {
+		case CopyEventError:
+			t.Fatalf("unexpected error event: %v", event.Err)
+		case CopyEventProgress:
+			progress += event.BytesTransferred
+		case CopyEventCompleted:
+			if event.BytesTransferred != event.Descriptor.Size {
+				t.Errorf("Completed BytesTransferred = %d, want %d", event.BytesTransferred, event.Descriptor.Size)
+			}
+		}
+	}
+	want := map[CopyEventType]int{
+		CopyEventNodeDiscovered:  3,
+		CopyEventTransferStarted: 3,
+		CopyEventCompleted:       3,
+	}
+	for typ, n := range want {
+		if got := counts[typ]; got != n {
+			t.Errorf("count of %v events = %d, want %d", typ, got, n)
+		}
+	}
+	if counts[CopyEventProgress] == 0 {
+		t.Error("no progress events emitted")
+	}
+	if exists, err := dst.Exists(ctx, root); err != nil || !exists {
+		t.Errorf("dst.Exists() = %v, %v, want true", exists, err)
+	}
+
+	// copy again to get skipped events
+	counts = make(map[CopyEventType]int)
+	for event := range CopyGraphEvents(ctx, src, dst, root, CopyGraphOptions{}) {
+		counts[event.Type]++
+	}
+	if got := counts[CopyEventSkipped]; got != 1 {
+		t.Errorf("count of Skipped events = %d, want 1", got)
+	}
+	if got := counts[CopyEventTransferStarted]; got != 0 {
+		t.Errorf("count of TransferStarted events = %d, want 0", got)
+	}
+}
+
+func TestCopyGraphEvents_Error(t *testing.T) {
+	ctx := context.Background()
+	src, root := pushImage(t, []byte(`{}`), []byte("hello world"), "latest")
+	dst := memory.New()
+
+	errTest := errors.New("test error")
+	var preCopyCalled atomic.Bool
+	opts := CopyGraphOptions{
+		PreCopy: func(ctx context.Context, desc ocispec.Descriptor) error {
+			preCopyCalled.Store(true)
+			return errTest
+		},
+	}
+	var last CopyEvent
+	for event := range CopyGraphEvents(ctx, src, dst, root, opts) {
+		if event.Type == CopyEventTransferStarted {
+			t.Error("TransferStarted emitted when PreCopy fails")
+		}
+		last = event
+	}
+	if !preCopyCalled.Load() {
+		t.Error("PreCopy not called")
+	}
+	if last.Type != CopyEventError {
+		t.Fatalf("last event type = %v, want %v", last.Type, CopyEventError)
+	}
+	if !errors.Is(last.Err, errTest) {
+		t.Errorf("last event error = %v, want %v", last.Err, errTest)
+	}
+	if last.Descriptor.Digest != root.Digest {
+		t.Errorf("last event descriptor = %v, want %v", last.Descriptor, root)
+	}
+}
+
+func TestCopyGraphEvents_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	src, root := pushImage(t, []byte(`{}`), []byte("hello world"), "latest")
+	dst := memory.New()
+
+	events := CopyGraphEvents(ctx, src, dst, root, CopyGraphOptions{})
+	<-events
+	cancel()
+	// the channel must be closed eventually after cancellation
+	for range events {
+	}
+}
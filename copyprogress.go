@@ -0,0 +1,67 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// progressStorage is a storage reporting the progress of reading the fetched
+// content.
+type progressStorage struct {
+	content.ReadOnlyStorage
+	onProgress func(ctx context.Context, desc ocispec.Descriptor, copied, total int64)
+}
+
+// Fetch fetches the content identified by the descriptor, and reports the
+// progress of reading it.
+func (s *progressStorage) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	rc, err := s.ReadOnlyStorage.Fetch(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	s.onProgress(ctx, target, 0, target.Size)
+	return &progressReadCloser{
+		ReadCloser: rc,
+		ctx:        ctx,
+		desc:       target,
+		onProgress: s.onProgress,
+	}, nil
+}
+
+// progressReadCloser reports the number of bytes read.
+type progressReadCloser struct {
+	io.ReadCloser
+	ctx        context.Context
+	desc       ocispec.Descriptor
+	onProgress func(ctx context.Context, desc ocispec.Descriptor, copied, total int64)
+	read       int64
+}
+
+// Read reads from the underlying reader, and reports the progress if any
+// byte is read.
+func (r *progressReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.read += int64(n)
+		r.onProgress(r.ctx, r.desc, r.read, r.desc.Size)
+	}
+	return n, err
+}
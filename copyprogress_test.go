@@ -0,0 +1,70 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestCopyGraph_OnCopyProgress(t *testing.T) {
+	ctx := context.Background()
+	layer := bytes.Repeat([]byte("a"), 256*1024)
+	src, manifest := pushImage(t, []byte("{}"), layer, "latest")
+	layerDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+
+	var lock sync.Mutex
+	progress := make(map[string][]int64)
+	opts := CopyGraphOptions{
+		OnCopyProgress: func(ctx context.Context, desc ocispec.Descriptor, copied, total int64) {
+			if total != desc.Size {
+				t.Errorf("OnCopyProgress() total = %d, want %d", total, desc.Size)
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			progress[desc.Digest.String()] = append(progress[desc.Digest.String()], copied)
+		},
+	}
+	if err := CopyGraph(ctx, src, memory.New(), manifest, opts); err != nil {
+		t.Fatal("CopyGraph() error =", err)
+	}
+
+	if len(progress) != 3 {
+		t.Errorf("OnCopyProgress() reported %d nodes, want 3", len(progress))
+	}
+	reports := progress[layerDesc.Digest.String()]
+	if len(reports) < 2 {
+		t.Fatalf("OnCopyProgress() reports of layer = %v, want at least 2", reports)
+	}
+	if reports[0] != 0 {
+		t.Errorf("first report = %d, want 0", reports[0])
+	}
+	for i := 1; i < len(reports); i++ {
+		if reports[i] <= reports[i-1] {
+			t.Errorf("reports = %v, want increasing", reports)
+			break
+		}
+	}
+	if last := reports[len(reports)-1]; last != layerDesc.Size {
+		t.Errorf("last report = %d, want %d", last, layerDesc.Size)
+	}
+}
@@ -0,0 +1,211 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/internal/cas"
+	"oras.land/oras-go/v2/internal/container/set"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/status"
+	"oras.land/oras-go/v2/internal/syncutil"
+	"oras.land/oras-go/v2/registry"
+)
+
+// DefaultCopyWithReferrersOptions provides the default
+// CopyWithReferrersOptions.
+var DefaultCopyWithReferrersOptions CopyWithReferrersOptions = CopyWithReferrersOptions{
+	CopyGraphWithReferrersOptions: DefaultCopyGraphWithReferrersOptions,
+}
+
+// CopyWithReferrersOptions contains parameters for [oras.CopyWithReferrers].
+type CopyWithReferrersOptions struct {
+	CopyGraphWithReferrersOptions
+}
+
+// DefaultCopyGraphWithReferrersOptions provides the default
+// CopyGraphWithReferrersOptions.
+var DefaultCopyGraphWithReferrersOptions CopyGraphWithReferrersOptions = CopyGraphWithReferrersOptions{
+	CopyGraphOptions: DefaultCopyGraphOptions,
+}
+
+// CopyGraphWithReferrersOptions contains parameters for
+// [oras.CopyGraphWithReferrers].
+type CopyGraphWithReferrersOptions struct {
+	CopyGraphOptions
+	// ArtifactType, if not empty, only copies the referrers of the artifact
+	// type.
+	ArtifactType string
+	// Depth limits the nesting level of the referrers to be copied, where
+	// the referrers of the manifests in the graph are at level 1, the
+	// referrers of those referrers are at level 2, and so on.
+	// If less than or equal to 0, the referrers are copied at any level.
+	Depth int
+}
+
+// CopyWithReferrers copies the graph rooted at the node tagged by srcRef in
+// the source Target, together with the referrers of the manifests in the
+// graph, such as signatures, SBOMs and attestations, to the destination
+// Target.
+// The destination reference will be the same as the source reference if the
+// destination reference is left blank.
+//
+// Returns the descriptor of the tagged node on successful copy.
+func CopyWithReferrers(ctx context.Context, src ReadOnlyTarget, srcRef string, dst Target, dstRef string, opts CopyWithReferrersOptions) (ocispec.Descriptor, error) {
+	if src == nil {
+		return ocispec.Descriptor{}, errors.New("nil source target")
+	}
+	if dst == nil {
+		return ocispec.Descriptor{}, errors.New("nil destination target")
+	}
+	if dstRef == "" {
+		dstRef = srcRef
+	}
+
+	root, err := src.Resolve(ctx, srcRef)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := CopyGraphWithReferrers(ctx, src, dst, root, opts.CopyGraphWithReferrersOptions); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := dst.Tag(ctx, root, dstRef); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return root, nil
+}
+
+// CopyGraphWithReferrers copies the graph rooted at root from the source
+// storage to the destination storage, together with the referrers of the
+// manifests in the graph, and recursively the graphs of the referrers.
+//
+// The referrers are discovered by registry.Referrers, and therefore the
+// source must implement either registry.ReferrerLister or
+// content.PredecessorFinder. Otherwise errdef.ErrUnsupported is returned.
+// The graphs are copied with shared caching, deduplication and concurrency
+// control, so that the nodes shared by the graphs are copied once.
+func CopyGraphWithReferrers(ctx context.Context, src content.ReadOnlyStorage, dst content.Storage, root ocispec.Descriptor, opts CopyGraphWithReferrersOptions) error {
+	// use caching proxy on non-leaf nodes
+	if opts.MaxMetadataBytes <= 0 {
+		opts.MaxMetadataBytes = defaultCopyMaxMetadataBytes
+	}
+	proxy := cas.NewProxyWithLimit(src, cas.NewMemory(), opts.MaxMetadataBytes)
+	roots, err := findReferrerRoots(ctx, src, proxy, root, opts)
+	if err != nil {
+		return err
+	}
+
+	scheduler := newScheduler(ctx, opts.CopyGraphOptions)
+	// throttle the copies of all the graphs together
+	throttled := throttleSource(src, &opts.CopyGraphOptions)
+	// track content status
+	tracker := status.NewTracker()
+
+	// copy the graphs of the root and the referrers
+	return syncutil.GoLimited[ocispec.Descriptor](ctx, scheduler, func(ctx context.Context, region *syncutil.LimitedRegion, root ocispec.Descriptor) error {
+		// As a referrer graph contains its subject, release the limit here
+		// for dispatching, to avoid dead locks where referrers are handled
+		// first and are waiting for their subjects to complete.
+		region.End()
+		if err := copyGraph(ctx, throttled, dst, root, proxy, scheduler, tracker, opts.CopyGraphOptions); err != nil {
+			return err
+		}
+		return region.Start()
+	}, scheduler.Submit(ctx, roots)...)
+}
+
+// findReferrerRoots walks the graph rooted at root level by level, and
+// returns root followed by the referrers of the manifests in the graph and,
+// up to opts.Depth, in the graphs of the referrers.
+// The manifests are fetched through the caching proxy, so that the copy
+// phase does not fetch them again.
+func findReferrerRoots(ctx context.Context, src content.ReadOnlyStorage, proxy *cas.Proxy, root ocispec.Descriptor, opts CopyGraphWithReferrersOptions) ([]ocispec.Descriptor, error) {
+	findSuccessors := opts.FindSuccessors
+	if findSuccessors == nil {
+		findSuccessors = content.Successors
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	// referrerNode is a node in the graph of a referrer at the level.
+	type referrerNode struct {
+		desc  ocispec.Descriptor
+		level int
+	}
+	roots := []ocispec.Descriptor{root}
+	visited := set.New[descriptor.Descriptor]()
+	visited.Add(descriptor.FromOCI(root))
+	current := []referrerNode{{desc: root}}
+	for len(current) > 0 {
+		var mu sync.Mutex // protects roots, visited and next
+		var next []referrerNode
+		visit := func(desc ocispec.Descriptor, level int, isReferrer bool) {
+			key := descriptor.FromOCI(desc)
+			if visited.Contains(key) {
+				return
+			}
+			visited.Add(key)
+			if isReferrer {
+				roots = append(roots, desc)
+			}
+			next = append(next, referrerNode{desc: desc, level: level})
+		}
+
+		eg, egCtx := syncutil.LimitGroup(ctx, concurrency)
+		for _, node := range current {
+			if !descriptor.IsManifest(node.desc) {
+				continue
+			}
+			node := node
+			eg.Go(func() error {
+				successors, err := findSuccessors(egCtx, proxy, node.desc)
+				if err != nil {
+					return err
+				}
+				successors = removeForeignLayers(egCtx, successors)
+				var referrers []ocispec.Descriptor
+				if opts.Depth <= 0 || node.level < opts.Depth {
+					referrers, err = registry.Referrers(egCtx, src, node.desc, opts.ArtifactType)
+					if err != nil {
+						return err
+					}
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				for _, successor := range successors {
+					visit(successor, node.level, false)
+				}
+				for _, referrer := range referrers {
+					visit(referrer, node.level+1, true)
+				}
+				return nil
+			})
+		}
+		if err := eg.Wait(); err != nil {
+			return nil, err
+		}
+		current = next
+	}
+	return roots, nil
+}
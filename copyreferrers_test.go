@@ -0,0 +1,152 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestCopyGraphWithReferrers(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+			t.Fatal("Store.Push() error =", err)
+		}
+		return desc
+	}
+	// the artifact type of an image manifest is its config media type
+	pushManifest := func(artifactType, layer string, subject *ocispec.Descriptor) ocispec.Descriptor {
+		if artifactType == "" {
+			artifactType = ocispec.MediaTypeImageConfig
+		}
+		manifestJSON, err := json.Marshal(ocispec.Manifest{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			MediaType: ocispec.MediaTypeImageManifest,
+			Config:    push(artifactType, []byte("{}")),
+			Layers:    []ocispec.Descriptor{push(ocispec.MediaTypeImageLayer, []byte(layer))},
+			Subject:   subject,
+		})
+		if err != nil {
+			t.Fatal("json.Marshal() error =", err)
+		}
+		return push(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+	image := pushManifest("", "image", nil)
+	index := pushIndex(t, src, image)
+	signature := pushManifest("application/vnd.test.signature", "signature", &image)
+	sbom := pushManifest("application/vnd.test.sbom", "sbom", &index)
+	countersignature := pushManifest("application/vnd.test.signature", "countersignature", &signature)
+	unrelated := pushManifest("application/vnd.test.signature", "unrelated", nil)
+
+	tests := []struct {
+		name     string
+		opts     CopyGraphWithReferrersOptions
+		copied   []ocispec.Descriptor
+		uncopied []ocispec.Descriptor
+	}{
+		{
+			name:     "all referrers",
+			opts:     DefaultCopyGraphWithReferrersOptions,
+			copied:   []ocispec.Descriptor{index, image, signature, sbom, countersignature},
+			uncopied: []ocispec.Descriptor{unrelated},
+		},
+		{
+			name:     "depth",
+			opts:     CopyGraphWithReferrersOptions{Depth: 1},
+			copied:   []ocispec.Descriptor{index, image, signature, sbom},
+			uncopied: []ocispec.Descriptor{countersignature, unrelated},
+		},
+		{
+			name:     "artifact type",
+			opts:     CopyGraphWithReferrersOptions{ArtifactType: "application/vnd.test.signature"},
+			copied:   []ocispec.Descriptor{index, image, signature, countersignature},
+			uncopied: []ocispec.Descriptor{sbom, unrelated},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := memory.New()
+			if err := CopyGraphWithReferrers(ctx, src, dst, index, tt.opts); err != nil {
+				t.Fatal("CopyGraphWithReferrers() error =", err)
+			}
+			for _, desc := range tt.copied {
+				if exists, err := dst.Exists(ctx, desc); err != nil || !exists {
+					t.Errorf("Store.Exists(%s) = %v, %v, want true, nil", desc.Digest, exists, err)
+				}
+			}
+			for _, desc := range tt.uncopied {
+				if exists, err := dst.Exists(ctx, desc); err != nil || exists {
+					t.Errorf("Store.Exists(%s) = %v, %v, want false, nil", desc.Digest, exists, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCopyWithReferrers(t *testing.T) {
+	ctx := context.Background()
+	src, manifest := pushImage(t, []byte("{}"), []byte("foo"), "latest")
+	signatureJSON, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, []byte("{}")),
+		Subject:   &manifest,
+	})
+	if err != nil {
+		t.Fatal("json.Marshal() error =", err)
+	}
+	signature := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, signatureJSON)
+	if err := src.Push(ctx, signature, bytes.NewReader(signatureJSON)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+
+	dst := memory.New()
+	root, err := CopyWithReferrers(ctx, src, "latest", dst, "copied", DefaultCopyWithReferrersOptions)
+	if err != nil {
+		t.Fatal("CopyWithReferrers() error =", err)
+	}
+	if !content.Equal(root, manifest) {
+		t.Errorf("CopyWithReferrers() = %v, want %v", root, manifest)
+	}
+	if desc, err := dst.Resolve(ctx, "copied"); err != nil || !content.Equal(desc, manifest) {
+		t.Errorf("Store.Resolve() = %v, %v, want %v", desc, err, manifest)
+	}
+	if exists, err := dst.Exists(ctx, signature); err != nil || !exists {
+		t.Errorf("Store.Exists(signature) = %v, %v, want true, nil", exists, err)
+	}
+}
+
+func TestCopyGraphWithReferrers_Unsupported(t *testing.T) {
+	ctx := context.Background()
+	s, manifest := pushImage(t, []byte("{}"), []byte("foo"), "latest")
+	src := struct{ content.ReadOnlyStorage }{s}
+	err := CopyGraphWithReferrers(ctx, src, memory.New(), manifest, DefaultCopyGraphWithReferrersOptions)
+	if !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("CopyGraphWithReferrers() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+)
+
+// CopyStateStore records the nodes committed to the destination of a copy,
+// so that an interrupted copy can be resumed by a subsequent copy with the
+// same store without pushing the committed nodes again.
+//
+// If a CopyStateStore also implements registry.UploadTracker, the blobs pushed
+// to remote repositories are uploaded resumably, and their upload sessions
+// are recorded in the store as well, so that partially uploaded blobs are
+// resumed from where they left off.
+//
+// checkpoint.Session implements both interfaces, persisting the state to a
+// pluggable checkpoint.Store.
+type CopyStateStore interface {
+	// Committed returns true if the node described by desc has been
+	// committed to the destination.
+	Committed(ctx context.Context, desc ocispec.Descriptor) (bool, error)
+	// Commit records that the node described by desc has been committed to
+	// the destination.
+	Commit(ctx context.Context, desc ocispec.Descriptor) error
+}
+
+// stateRecordingStorage is a storage recording the pushed content in a
+// CopyStateStore.
+type stateRecordingStorage struct {
+	content.Storage
+	state CopyStateStore
+}
+
+// Exists returns true if the described content is committed in the state
+// store or exists in the storage.
+func (s *stateRecordingStorage) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	committed, err := s.state.Committed(ctx, target)
+	if err != nil {
+		return false, err
+	}
+	if committed {
+		return true, nil
+	}
+	return s.Storage.Exists(ctx, target)
+}
+
+// Push pushes the content to the storage, and commits it in the state store
+// on success.
+func (s *stateRecordingStorage) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	pushCtx := ctx
+	if tracker, ok := s.state.(registry.UploadTracker); ok {
+		pushCtx = registry.WithUploadTracker(ctx, tracker)
+	}
+	err := s.Storage.Push(pushCtx, expected, content)
+	if err == nil || errors.Is(err, errdef.ErrAlreadyExists) {
+		if err := s.state.Commit(ctx, expected); err != nil {
+			return err
+		}
+	}
+	return err
+}
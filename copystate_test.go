@@ -0,0 +1,122 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/internal/descriptor"
+)
+
+// memoryStateStore is a CopyStateStore in memory.
+type memoryStateStore struct {
+	lock      sync.Mutex
+	committed map[descriptor.Descriptor]bool
+}
+
+func (s *memoryStateStore) Committed(_ context.Context, desc ocispec.Descriptor) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.committed[descriptor.FromOCI(desc)], nil
+}
+
+func (s *memoryStateStore) Commit(_ context.Context, desc ocispec.Descriptor) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.committed == nil {
+		s.committed = make(map[descriptor.Descriptor]bool)
+	}
+	s.committed[descriptor.FromOCI(desc)] = true
+	return nil
+}
+
+// flakyStorage fails pushing the content in fail, and records the pushes.
+type flakyStorage struct {
+	content.Storage
+	fail   []ocispec.Descriptor
+	lock   sync.Mutex
+	pushed []ocispec.Descriptor
+}
+
+func (s *flakyStorage) Push(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	for _, desc := range s.fail {
+		if content.Equal(desc, expected) {
+			return errors.New("connection reset")
+		}
+	}
+	s.lock.Lock()
+	s.pushed = append(s.pushed, expected)
+	s.lock.Unlock()
+	return s.Storage.Push(ctx, expected, r)
+}
+
+func (s *flakyStorage) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	// never report existence, so that only the state store prevents
+	// pushing again
+	return false, nil
+}
+
+func TestCopyGraph_StateStore(t *testing.T) {
+	ctx := context.Background()
+	config := []byte("{}")
+	layer := []byte("hello world")
+	src, manifest := pushImage(t, config, layer, "latest")
+	configDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, config)
+	layerDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+
+	state := &memoryStateStore{}
+	dst := &flakyStorage{Storage: memory.New(), fail: []ocispec.Descriptor{layerDesc}}
+	opts := CopyGraphOptions{
+		Concurrency: 1,
+		StateStore:  state,
+	}
+	if err := CopyGraph(ctx, src, dst, manifest, opts); err == nil {
+		t.Fatal("CopyGraph() error = nil, wantErr true")
+	}
+	if committed, _ := state.Committed(ctx, layerDesc); committed {
+		t.Error("Committed(layer) = true after failed push, want false")
+	}
+
+	// resume the copy
+	dst.fail = nil
+	var pushedBefore []ocispec.Descriptor
+	pushedBefore, dst.pushed = dst.pushed, nil
+	if err := CopyGraph(ctx, src, dst, manifest, opts); err != nil {
+		t.Fatal("CopyGraph() error =", err)
+	}
+	for _, desc := range pushedBefore {
+		if committed, _ := state.Committed(ctx, desc); !committed {
+			t.Errorf("Committed(%s) = false, want true", desc.Digest)
+		}
+		for _, pushed := range dst.pushed {
+			if content.Equal(desc, pushed) {
+				t.Errorf("committed node %s is pushed again", desc.Digest)
+			}
+		}
+	}
+	for _, desc := range []ocispec.Descriptor{configDesc, layerDesc, manifest} {
+		if committed, _ := state.Committed(ctx, desc); !committed {
+			t.Errorf("Committed(%s) = false, want true", desc.Digest)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"sync"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// CopySummary is the summary of a copy operation, populated by the copy when
+// set as CopyGraphOptions.Summary.
+// A CopySummary accumulates over all the operations it is set for, so a new
+// CopySummary should be used per operation for per-operation statistics.
+// The fields are safe to read once the operation returns.
+type CopySummary struct {
+	// Copied are the nodes copied to the destination.
+	Copied []ocispec.Descriptor
+	// Skipped are the nodes skipped since they exist in the destination.
+	// The nodes of the skipped sub-DAGs are not walked and not listed.
+	Skipped []ocispec.Descriptor
+	// BytesCopied is the total size of the copied nodes.
+	BytesCopied int64
+	// Elapsed is the time elapsed from the start of the first operation to
+	// the end of the last operation populating the summary.
+	Elapsed time.Duration
+
+	lock  sync.Mutex
+	start time.Time
+}
+
+// begin records the start of an operation.
+func (s *CopySummary) begin() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.start.IsZero() {
+		s.start = time.Now()
+	}
+}
+
+// end records the end of an operation.
+func (s *CopySummary) end() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.Elapsed = time.Since(s.start)
+}
+
+// addCopied records a copied node.
+func (s *CopySummary) addCopied(desc ocispec.Descriptor) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.Copied = append(s.Copied, desc)
+	s.BytesCopied += desc.Size
+}
+
+// addSkipped records a skipped node.
+func (s *CopySummary) addSkipped(desc ocispec.Descriptor) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.Skipped = append(s.Skipped, desc)
+}
@@ -0,0 +1,100 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestCopy_Summary(t *testing.T) {
+	ctx := context.Background()
+	config := []byte("{}")
+	layer := []byte("hello world")
+	src, manifest := pushImage(t, config, layer, "latest")
+	configDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, config)
+	layerDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+
+	// the config exists in the destination
+	dst := memory.New()
+	if err := dst.Push(ctx, configDesc, bytes.NewReader(config)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+
+	summary := &CopySummary{}
+	opts := DefaultCopyOptions
+	opts.Summary = summary
+	if _, err := Copy(ctx, src, "latest", dst, "latest", opts); err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+
+	copied := make(map[string]bool)
+	for _, desc := range summary.Copied {
+		copied[desc.Digest.String()] = true
+	}
+	if len(summary.Copied) != 2 || !copied[manifest.Digest.String()] || !copied[layerDesc.Digest.String()] {
+		t.Errorf("CopySummary.Copied = %v, want %v", summary.Copied, []ocispec.Descriptor{layerDesc, manifest})
+	}
+	if len(summary.Skipped) != 1 || summary.Skipped[0].Digest != configDesc.Digest {
+		t.Errorf("CopySummary.Skipped = %v, want %v", summary.Skipped, []ocispec.Descriptor{configDesc})
+	}
+	if want := manifest.Size + layerDesc.Size; summary.BytesCopied != want {
+		t.Errorf("CopySummary.BytesCopied = %d, want %d", summary.BytesCopied, want)
+	}
+	if summary.Elapsed <= 0 {
+		t.Errorf("CopySummary.Elapsed = %v, want > 0", summary.Elapsed)
+	}
+
+	// copy again: the whole graph is skipped
+	summary = &CopySummary{}
+	opts.Summary = summary
+	if _, err := Copy(ctx, src, "latest", dst, "latest", opts); err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+	if len(summary.Copied) != 0 || summary.BytesCopied != 0 {
+		t.Errorf("CopySummary.Copied = %v, want none", summary.Copied)
+	}
+	if len(summary.Skipped) != 1 || summary.Skipped[0].Digest != manifest.Digest {
+		t.Errorf("CopySummary.Skipped = %v, want %v", summary.Skipped, []ocispec.Descriptor{manifest})
+	}
+}
+
+func TestCopyGraph_Summary(t *testing.T) {
+	ctx := context.Background()
+	src, manifest := pushImage(t, []byte("{}"), []byte("hello world"), "latest")
+	dst := memory.New()
+
+	summary := &CopySummary{}
+	opts := DefaultCopyGraphOptions
+	opts.Summary = summary
+	if err := CopyGraph(ctx, src, dst, manifest, opts); err != nil {
+		t.Fatal("CopyGraph() error =", err)
+	}
+	if len(summary.Copied) != 3 {
+		t.Errorf("len(CopySummary.Copied) = %d, want 3", len(summary.Copied))
+	}
+	if len(summary.Skipped) != 0 {
+		t.Errorf("CopySummary.Skipped = %v, want none", summary.Skipped)
+	}
+	if summary.Elapsed <= 0 {
+		t.Errorf("CopySummary.Elapsed = %v, want > 0", summary.Elapsed)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/internal/container/set"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/syncutil"
+)
+
+// depthLimitedSuccessors returns a FindSuccessors function, which finds the
+// successors of the nodes of the graph rooted at root shallower than
+// opts.MaxDepth only, so that the walk stops at opts.MaxDepth.
+// The depth of a node is the length of the shortest path from the root to
+// the node, resolved by walking the graph breadth-first.
+func depthLimitedSuccessors(ctx context.Context, fetcher content.Fetcher, root ocispec.Descriptor, opts CopyGraphOptions) (func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error), error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	findSuccessors := opts.FindSuccessors
+	if findSuccessors == nil {
+		findSuccessors = content.Successors
+	}
+
+	// expand holds the nodes shallower than opts.MaxDepth
+	expand := set.New[descriptor.Descriptor]()
+	level := []ocispec.Descriptor{root}
+	for depth := 0; depth < opts.MaxDepth && len(level) > 0; depth++ {
+		for _, node := range level {
+			expand.Add(descriptor.FromOCI(node))
+		}
+		if depth == opts.MaxDepth-1 {
+			// the successors of the deepest level are not expanded
+			break
+		}
+
+		var mu sync.Mutex // protects next
+		var next []ocispec.Descriptor
+		eg, egCtx := syncutil.LimitGroup(ctx, concurrency)
+		for _, node := range level {
+			node := node
+			eg.Go(func() error {
+				successors, err := findSuccessors(egCtx, fetcher, node)
+				if err != nil {
+					if isMissingContent(err, node, root, opts) {
+						// leave it to the walk to report
+						return nil
+					}
+					return err
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				next = append(next, successors...)
+				return nil
+			})
+		}
+		if err := eg.Wait(); err != nil {
+			return nil, err
+		}
+
+		level = level[:0]
+		seen := set.New[descriptor.Descriptor]()
+		for _, node := range next {
+			key := descriptor.FromOCI(node)
+			if expand.Contains(key) || seen.Contains(key) {
+				continue
+			}
+			seen.Add(key)
+			level = append(level, node)
+		}
+	}
+
+	return func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		if !expand.Contains(descriptor.FromOCI(desc)) {
+			return nil, nil
+		}
+		return findSuccessors(ctx, fetcher, desc)
+	}, nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestCopyGraph_MaxDepth(t *testing.T) {
+	ctx := context.Background()
+	config := []byte("{}")
+	layer := []byte("hello world")
+	src, manifest := pushImage(t, config, layer, "latest")
+	configDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, config)
+	layerDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+	index := pushIndex(t, src, manifest)
+	// the manifest is at depth 1 via the root, and at depth 2 via the index
+	root := pushIndex(t, src, index, manifest)
+
+	tests := []struct {
+		name     string
+		maxDepth int
+		want     []ocispec.Descriptor
+		notWant  []ocispec.Descriptor
+	}{
+		{
+			name:     "root only",
+			maxDepth: -1,
+			want:     []ocispec.Descriptor{root},
+			notWant:  []ocispec.Descriptor{index, manifest, configDesc, layerDesc},
+		},
+		{
+			name:     "direct successors",
+			maxDepth: 1,
+			want:     []ocispec.Descriptor{root, index, manifest},
+			notWant:  []ocispec.Descriptor{configDesc, layerDesc},
+		},
+		{
+			name:     "shortest path",
+			maxDepth: 2,
+			want:     []ocispec.Descriptor{root, index, manifest, configDesc, layerDesc},
+		},
+		{
+			name:     "unlimited",
+			maxDepth: 0,
+			want:     []ocispec.Descriptor{root, index, manifest, configDesc, layerDesc},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := memory.New()
+			opts := DefaultCopyGraphOptions
+			opts.MaxDepth = tt.maxDepth
+			if err := CopyGraph(ctx, src, dst, root, opts); err != nil {
+				t.Fatal("CopyGraph() error =", err)
+			}
+			for _, desc := range tt.want {
+				if exists, err := dst.Exists(ctx, desc); err != nil || !exists {
+					t.Errorf("Store.Exists(%s) = %v, %v, want true", desc.Digest, exists, err)
+				}
+			}
+			for _, desc := range tt.notWant {
+				if exists, err := dst.Exists(ctx, desc); err != nil || exists {
+					t.Errorf("Store.Exists(%s) = %v, %v, want false", desc.Digest, exists, err)
+				}
+			}
+		})
+	}
+}
+
+func TestPlan_MaxDepth(t *testing.T) {
+	ctx := context.Background()
+	src, manifest := pushImage(t, []byte("{}"), []byte("hello world"), "latest")
+	index := pushIndex(t, src, manifest)
+
+	opts := DefaultCopyGraphOptions
+	opts.MaxDepth = 1
+	plan, err := Plan(ctx, src, memory.New(), index, opts)
+	if err != nil {
+		t.Fatal("Plan() error =", err)
+	}
+	if len(plan.Copy) != 2 || !content.Equal(plan.Copy[0], index) || !content.Equal(plan.Copy[1], manifest) {
+		t.Errorf("CopyPlan.Copy = %v, want %v", plan.Copy, []ocispec.Descriptor{index, manifest})
+	}
+	if want := index.Size + manifest.Size; plan.CopySize != want {
+		t.Errorf("CopyPlan.CopySize = %d, want %d", plan.CopySize, want)
+	}
+}
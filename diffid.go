@@ -0,0 +1,190 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/compression"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/docker"
+	"oras.land/oras-go/v2/internal/pool"
+)
+
+// DiffIDMismatchError is returned by VerifyDiffIDs when the digest of the
+// uncompressed content of a layer does not match the corresponding diff ID
+// in the image config.
+type DiffIDMismatchError struct {
+	// Manifest describes the image manifest of the layer.
+	Manifest ocispec.Descriptor
+	// Layer describes the layer.
+	Layer ocispec.Descriptor
+	// Expected is the diff ID in the image config.
+	Expected digest.Digest
+	// Actual is the digest of the uncompressed content of the layer.
+	Actual digest.Digest
+}
+
+// Error returns the error message.
+func (e *DiffIDMismatchError) Error() string {
+	return fmt.Sprintf("%s: %s: diff ID mismatch: expected %s, got %s", e.Layer.Digest, e.Layer.MediaType, e.Expected, e.Actual)
+}
+
+// VerifyDiffIDs decompresses the layers of the image manifest described by
+// root, and verifies the digests of their uncompressed contents against the
+// diff IDs in rootfs.diff_ids of the image config. If root is an index, the
+// image manifests referenced by it are verified recursively.
+// Foreign layers and manifests other than image manifests, such as artifact
+// manifests, are not verified.
+//
+// A *DiffIDMismatchError is returned on the first mismatched layer. An error
+// wrapping errdef.ErrUnsupported is returned if a layer is compressed by an
+// algorithm without a registered decompressor. See the compression package
+// for registering decompressors.
+func VerifyDiffIDs(ctx context.Context, fetcher content.Fetcher, root ocispec.Descriptor) error {
+	switch root.MediaType {
+	case ocispec.MediaTypeImageIndex, docker.MediaTypeManifestList:
+		manifests, err := content.Successors(ctx, fetcher, root)
+		if err != nil {
+			return err
+		}
+		for _, m := range manifests {
+			if err := VerifyDiffIDs(ctx, fetcher, m); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ocispec.MediaTypeImageManifest, docker.MediaTypeManifest:
+		return verifyManifestDiffIDs(ctx, fetcher, root)
+	default:
+		return nil
+	}
+}
+
+// verifyManifestDiffIDs verifies the layers of the image manifest against the
+// diff IDs in its config.
+func verifyManifestDiffIDs(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) error {
+	manifestJSON, err := content.FetchAll(ctx, fetcher, desc)
+	if err != nil {
+		return err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return fmt.Errorf("failed to decode manifest %s: %w", desc.Digest, err)
+	}
+	switch manifest.Config.MediaType {
+	case ocispec.MediaTypeImageConfig, docker.MediaTypeConfig:
+	default:
+		// not an image
+		return nil
+	}
+	configJSON, err := content.FetchAll(ctx, fetcher, manifest.Config)
+	if err != nil {
+		return err
+	}
+	var config ocispec.Image
+	if err := json.Unmarshal(configJSON, &config); err != nil {
+		return fmt.Errorf("failed to decode config %s: %w", manifest.Config.Digest, err)
+	}
+	diffIDs := config.RootFS.DiffIDs
+	if len(diffIDs) != len(manifest.Layers) {
+		return fmt.Errorf("%s: %s: %d layers but %d diff IDs: %w", desc.Digest, desc.MediaType, len(manifest.Layers), len(diffIDs), errdef.ErrNonConformant)
+	}
+
+	for i, layer := range manifest.Layers {
+		if descriptor.IsForeignLayer(layer) {
+			continue
+		}
+		actual, err := diffID(ctx, fetcher, layer, diffIDs[i].Algorithm())
+		if err != nil {
+			return err
+		}
+		if actual != diffIDs[i] {
+			return &DiffIDMismatchError{
+				Manifest: desc,
+				Layer:    layer,
+				Expected: diffIDs[i],
+				Actual:   actual,
+			}
+		}
+	}
+	return nil
+}
+
+// diffID returns the digest of the uncompressed content of the layer, while
+// verifying the compressed content against the layer descriptor.
+func diffID(ctx context.Context, fetcher content.Fetcher, layer ocispec.Descriptor, alg digest.Algorithm) (digest.Digest, error) {
+	if !alg.Available() {
+		return "", fmt.Errorf("%s: diff ID algorithm %s: %w", layer.Digest, alg, errdef.ErrUnsupported)
+	}
+	rc, err := fetcher.Fetch(ctx, layer)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	vr := content.NewVerifyReader(rc, layer)
+	var dr io.ReadCloser
+	if compressionAlg, ok := compression.FromMediaType(layer.MediaType); ok {
+		dr, err = compression.Decompress(compressionAlg, vr)
+	} else {
+		dr, _, err = compression.NewReader(vr)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %s: %w", layer.Digest, layer.MediaType, err)
+	}
+	defer dr.Close()
+
+	digester := alg.Digester()
+	if _, err := pool.Stream.Copy(digester.Hash(), dr); err != nil {
+		return "", fmt.Errorf("%s: %s: failed to decompress: %w", layer.Digest, layer.MediaType, err)
+	}
+	// drain the compressed content left, such as gzip trailers
+	if _, err := pool.Stream.Discard(vr); err != nil {
+		return "", err
+	}
+	if err := vr.Verify(); err != nil {
+		return "", err
+	}
+	return digester.Digest(), nil
+}
+
+// WithDiffIDVerification configures opts.VerifyRoot to verify the layers
+// copied to the destination against the diff IDs in their image configs by
+// VerifyDiffIDs before the root node is tagged. An existing VerifyRoot is
+// called after the layers are verified.
+//
+// Since all the layers are read back from the destination and decompressed,
+// the verification is expensive for large images and remote destinations.
+func (opts *CopyOptions) WithDiffIDVerification() {
+	verifyRoot := opts.VerifyRoot
+	opts.VerifyRoot = func(ctx context.Context, fetcher content.Fetcher, root ocispec.Descriptor) error {
+		if err := VerifyDiffIDs(ctx, fetcher, root); err != nil {
+			return err
+		}
+		if verifyRoot != nil {
+			return verifyRoot(ctx, fetcher, root)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// pushGzipImage pushes an image with a gzip layer of the uncompressed
+// content, whose config records diffID.
+func pushGzipImage(t *testing.T, uncompressed []byte, diffID digest.Digest) (*memory.Store, ocispec.Descriptor) {
+	t.Helper()
+	ctx := context.Background()
+	s := memory.New()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal("Store.Push() error =", err)
+		}
+		return desc
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(uncompressed); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	layerDesc := push(ocispec.MediaTypeImageLayerGzip, buf.Bytes())
+	configJSON, err := json.Marshal(ocispec.Image{
+		Platform: ocispec.Platform{Architecture: "amd64", OS: "linux"},
+		RootFS: ocispec.RootFS{
+			Type:    "layers",
+			DiffIDs: []digest.Digest{diffID},
+		},
+	})
+	if err != nil {
+		t.Fatal("json.Marshal() error =", err)
+	}
+	configDesc := push(ocispec.MediaTypeImageConfig, configJSON)
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    configDesc,
+		Layers:    []ocispec.Descriptor{layerDesc},
+	})
+	if err != nil {
+		t.Fatal("json.Marshal() error =", err)
+	}
+	manifestDesc := push(ocispec.MediaTypeImageManifest, manifestJSON)
+	if err := s.Tag(ctx, manifestDesc, "latest"); err != nil {
+		t.Fatal("Store.Tag() error =", err)
+	}
+	return s, manifestDesc
+}
+
+func TestVerifyDiffIDs(t *testing.T) {
+	ctx := context.Background()
+	layer := []byte("hello world")
+	s, root := pushGzipImage(t, layer, digest.FromBytes(layer))
+	if err := VerifyDiffIDs(ctx, s, root); err != nil {
+		t.Errorf("VerifyDiffIDs() error = %v", err)
+	}
+
+	// verify through an index
+	index := pushIndex(t, s, root)
+	if err := VerifyDiffIDs(ctx, s, index); err != nil {
+		t.Errorf("VerifyDiffIDs() error = %v", err)
+	}
+
+	// images without diff IDs in the config are non-conformant
+	noDiffIDStore, noDiffID := pushImage(t, []byte("{}"), []byte("foo"), "foo")
+	if err := VerifyDiffIDs(ctx, noDiffIDStore, noDiffID); !errors.Is(err, errdef.ErrNonConformant) {
+		t.Errorf("VerifyDiffIDs() error = %v, want %v", err, errdef.ErrNonConformant)
+	}
+}
+
+func TestVerifyDiffIDs_Mismatch(t *testing.T) {
+	ctx := context.Background()
+	layer := []byte("hello world")
+	wrong := digest.FromString("corrupted")
+	s, root := pushGzipImage(t, layer, wrong)
+
+	err := VerifyDiffIDs(ctx, s, root)
+	var mismatchErr *DiffIDMismatchError
+	if !errors.As(err, &mismatchErr) {
+		t.Fatalf("VerifyDiffIDs() error = %v, want %T", err, mismatchErr)
+	}
+	if mismatchErr.Expected != wrong {
+		t.Errorf("DiffIDMismatchError.Expected = %v, want %v", mismatchErr.Expected, wrong)
+	}
+	if want := digest.FromBytes(layer); mismatchErr.Actual != want {
+		t.Errorf("DiffIDMismatchError.Actual = %v, want %v", mismatchErr.Actual, want)
+	}
+}
+
+func TestCopy_WithDiffIDVerification(t *testing.T) {
+	ctx := context.Background()
+	layer := []byte("hello world")
+
+	src, _ := pushGzipImage(t, layer, digest.FromBytes(layer))
+	dst := memory.New()
+	opts := CopyOptions{}
+	opts.WithDiffIDVerification()
+	if _, err := Copy(ctx, src, "latest", dst, "latest", opts); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if _, err := dst.Resolve(ctx, "latest"); err != nil {
+		t.Errorf("dst.Resolve() error = %v", err)
+	}
+
+	src, _ = pushGzipImage(t, layer, digest.FromString("corrupted"))
+	dst = memory.New()
+	_, err := Copy(ctx, src, "latest", dst, "latest", opts)
+	var mismatchErr *DiffIDMismatchError
+	if !errors.As(err, &mismatchErr) {
+		t.Fatalf("Copy() error = %v, want %T", err, mismatchErr)
+	}
+	if _, err := dst.Resolve(ctx, "latest"); err == nil {
+		t.Error("dst.Resolve() error = nil, want not tagged")
+	}
+}
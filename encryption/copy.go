@@ -0,0 +1,282 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/docker"
+)
+
+// CopyOptions contains parameters for Encrypt and Decrypt.
+type CopyOptions struct {
+	// CopyGraphOptions is used for copying the content not transformed.
+	oras.CopyGraphOptions
+	// KeyWrappers wrap the keys for the recipients when encrypting, or unwrap
+	// the keys when decrypting.
+	KeyWrappers []KeyWrapper
+	// LayerFilter selects the layers to be encrypted or decrypted.
+	// If nil, all the applicable layers are selected.
+	LayerFilter func(desc ocispec.Descriptor) bool
+	// TempDir is the directory for staging the transformed layers.
+	// If empty, the default directory for temporary files is used.
+	TempDir string
+}
+
+// Encrypt copies the image, or the index of images, tagged by srcRef in src
+// to dst like oras.Copy, while encrypting the layers for the recipients of
+// opts.KeyWrappers. The manifests referencing the encrypted layers are
+// rewritten accordingly, and the new root is tagged by dstRef in dst.
+// If dstRef is empty, srcRef is used.
+// Layers already encrypted are copied as is.
+// Returns the descriptor of the new root.
+func Encrypt(ctx context.Context, src oras.ReadOnlyTarget, srcRef string, dst oras.Target, dstRef string, opts CopyOptions) (ocispec.Descriptor, error) {
+	if len(opts.KeyWrappers) == 0 {
+		return ocispec.Descriptor{}, errors.New("no key wrappers")
+	}
+	t := &transformer{
+		src:  src,
+		dst:  dst,
+		opts: opts,
+		selectLayer: func(desc ocispec.Descriptor) bool {
+			if IsEncrypted(desc) {
+				return false
+			}
+			_, err := encryptedMediaType(desc.MediaType)
+			return err == nil
+		},
+		transformLayer: func(r io.Reader, w io.Writer, desc ocispec.Descriptor) (ocispec.Descriptor, error) {
+			vr := content.NewVerifyReader(r, desc)
+			encrypted, err := EncryptLayer(vr, w, desc, opts.KeyWrappers)
+			if err != nil {
+				return ocispec.Descriptor{}, err
+			}
+			if err := vr.Verify(); err != nil {
+				return ocispec.Descriptor{}, err
+			}
+			return encrypted, nil
+		},
+	}
+	return t.copy(ctx, srcRef, dstRef)
+}
+
+// Decrypt copies the image, or the index of images, tagged by srcRef in src
+// to dst like oras.Copy, while decrypting the encrypted layers with
+// opts.KeyWrappers. The manifests referencing the decrypted layers are
+// rewritten accordingly, and the new root is tagged by dstRef in dst.
+// If dstRef is empty, srcRef is used.
+// Returns ErrNoKey if any selected layer cannot be decrypted by the key
+// wrappers.
+// Returns the descriptor of the new root.
+func Decrypt(ctx context.Context, src oras.ReadOnlyTarget, srcRef string, dst oras.Target, dstRef string, opts CopyOptions) (ocispec.Descriptor, error) {
+	if len(opts.KeyWrappers) == 0 {
+		return ocispec.Descriptor{}, errors.New("no key wrappers")
+	}
+	t := &transformer{
+		src:         src,
+		dst:         dst,
+		opts:        opts,
+		selectLayer: IsEncrypted,
+		transformLayer: func(r io.Reader, w io.Writer, desc ocispec.Descriptor) (ocispec.Descriptor, error) {
+			vr := content.NewVerifyReader(r, desc)
+			decrypted, err := DecryptLayer(vr, w, desc, opts.KeyWrappers)
+			if err != nil {
+				return ocispec.Descriptor{}, err
+			}
+			if err := vr.Verify(); err != nil {
+				return ocispec.Descriptor{}, err
+			}
+			return decrypted, nil
+		},
+	}
+	return t.copy(ctx, srcRef, dstRef)
+}
+
+// transformer copies graphs while transforming the selected layers.
+type transformer struct {
+	src            oras.ReadOnlyTarget
+	dst            oras.Target
+	opts           CopyOptions
+	selectLayer    func(desc ocispec.Descriptor) bool
+	transformLayer func(r io.Reader, w io.Writer, desc ocispec.Descriptor) (ocispec.Descriptor, error)
+	// transformed maps the digests of the transformed layers to the
+	// descriptors of the results, so that layers shared by multiple
+	// manifests are transformed once.
+	transformed map[string]ocispec.Descriptor
+}
+
+// copy resolves srcRef, transforms the graph, and tags the new root.
+func (t *transformer) copy(ctx context.Context, srcRef, dstRef string) (ocispec.Descriptor, error) {
+	if dstRef == "" {
+		dstRef = srcRef
+	}
+	root, err := t.src.Resolve(ctx, srcRef)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to resolve %s: %w", srcRef, err)
+	}
+	t.transformed = make(map[string]ocispec.Descriptor)
+	newRoot, err := t.transform(ctx, root)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := t.dst.Tag(ctx, newRoot, dstRef); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return newRoot, nil
+}
+
+// transform copies the graph rooted by desc, and returns the descriptor of
+// the transformed root.
+func (t *transformer) transform(ctx context.Context, desc ocispec.Descriptor) (ocispec.Descriptor, error) {
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageIndex, docker.MediaTypeManifestList:
+		return t.transformNode(ctx, desc, "manifests", t.transform)
+	case ocispec.MediaTypeImageManifest, docker.MediaTypeManifest:
+		return t.transformNode(ctx, desc, "layers", t.transformLeaf)
+	default:
+		return desc, t.copyGraph(ctx, desc)
+	}
+}
+
+// transformNode transforms the descriptors in the field of the manifest or
+// the index described by desc, and pushes the rewritten manifest or index.
+// The other fields are kept as is.
+func (t *transformer) transformNode(ctx context.Context, desc ocispec.Descriptor, field string, fn func(context.Context, ocispec.Descriptor) (ocispec.Descriptor, error)) (ocispec.Descriptor, error) {
+	manifestJSON, err := content.FetchAll(ctx, t.src, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	var manifest map[string]json.RawMessage
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to decode %s: %s: %w", desc.Digest, desc.MediaType, err)
+	}
+	var children []ocispec.Descriptor
+	if raw, ok := manifest[field]; ok {
+		if err := json.Unmarshal(raw, &children); err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("failed to decode %s: %s: %w", desc.Digest, desc.MediaType, err)
+		}
+	}
+
+	changed := false
+	for i, child := range children {
+		newChild, err := fn(ctx, child)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		if !content.Equal(newChild, child) {
+			children[i] = newChild
+			changed = true
+		}
+	}
+	if !changed {
+		return desc, t.copyGraph(ctx, desc)
+	}
+
+	// copy the other successors, such as the config and the subject
+	for _, key := range []string{"config", "subject"} {
+		raw, ok := manifest[key]
+		if !ok {
+			continue
+		}
+		var successor ocispec.Descriptor
+		if err := json.Unmarshal(raw, &successor); err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("failed to decode %s: %s: %w", desc.Digest, desc.MediaType, err)
+		}
+		if err := t.copyGraph(ctx, successor); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+
+	if manifest[field], err = json.Marshal(children); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if manifestJSON, err = json.Marshal(manifest); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	newDesc := content.NewDescriptorFromBytes(desc.MediaType, manifestJSON)
+	newDesc.ArtifactType = desc.ArtifactType
+	newDesc.Annotations = desc.Annotations
+	newDesc.Platform = desc.Platform
+	if err := t.push(ctx, newDesc, bytes.NewReader(manifestJSON)); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return newDesc, nil
+}
+
+// transformLeaf transforms the layer if selected, or copies it otherwise.
+func (t *transformer) transformLeaf(ctx context.Context, desc ocispec.Descriptor) (ocispec.Descriptor, error) {
+	if !t.selectLayer(desc) || (t.opts.LayerFilter != nil && !t.opts.LayerFilter(desc)) {
+		return desc, t.copyGraph(ctx, desc)
+	}
+	if newDesc, ok := t.transformed[desc.Digest.String()]; ok {
+		return newDesc, nil
+	}
+
+	fp, err := os.CreateTemp(t.opts.TempDir, "oras_encryption_*")
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	defer func() {
+		fp.Close()
+		os.Remove(fp.Name())
+	}()
+	rc, err := t.src.Fetch(ctx, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	newDesc, err := t.transformLayer(rc, fp, desc)
+	rc.Close()
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if _, err := fp.Seek(0, io.SeekStart); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := t.push(ctx, newDesc, fp); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	t.transformed[desc.Digest.String()] = newDesc
+	return newDesc, nil
+}
+
+// push pushes the content to the destination, unless the content already
+// exists.
+func (t *transformer) push(ctx context.Context, desc ocispec.Descriptor, r io.Reader) error {
+	if exists, err := t.dst.Exists(ctx, desc); err != nil {
+		return err
+	} else if exists {
+		return nil
+	}
+	if err := t.dst.Push(ctx, desc, r); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return err
+	}
+	return nil
+}
+
+// copyGraph copies the graph rooted by desc as is.
+func (t *transformer) copyGraph(ctx context.Context, desc ocispec.Descriptor) error {
+	return oras.CopyGraph(ctx, t.src, t.dst, desc, t.opts.CopyGraphOptions)
+}
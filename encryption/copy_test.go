@@ -0,0 +1,226 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+// pushBlob pushes the blob into the storage, and returns its descriptor.
+func pushBlob(t *testing.T, s content.Pusher, mediaType string, blob []byte) ocispec.Descriptor {
+	desc := content.NewDescriptorFromBytes(mediaType, blob)
+	if err := s.Push(context.Background(), desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	return desc
+}
+
+// pushJSON pushes the JSON encoding of v into the storage, and returns its
+// descriptor.
+func pushJSON(t *testing.T, s content.Pusher, mediaType string, v interface{}) ocispec.Descriptor {
+	blob, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal("json.Marshal() error =", err)
+	}
+	return pushBlob(t, s, mediaType, blob)
+}
+
+// fetchManifest fetches and decodes the manifest.
+func fetchManifest(t *testing.T, s content.Fetcher, desc ocispec.Descriptor, v interface{}) {
+	manifestJSON, err := content.FetchAll(context.Background(), s, desc)
+	if err != nil {
+		t.Fatal("FetchAll() error =", err)
+	}
+	if err := json.Unmarshal(manifestJSON, v); err != nil {
+		t.Fatal("json.Unmarshal() error =", err)
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	ctx := context.Background()
+	key := generateKey(t)
+	src := memory.New()
+
+	// prepare an index of two images sharing a layer
+	config := pushBlob(t, src, ocispec.MediaTypeImageConfig, []byte("{}"))
+	shared := pushBlob(t, src, ocispec.MediaTypeImageLayerGzip, []byte("shared layer"))
+	foo := pushBlob(t, src, ocispec.MediaTypeImageLayerGzip, []byte("foo layer"))
+	bar := pushBlob(t, src, ocispec.MediaTypeImageLayer, []byte("bar layer"))
+	var manifests []ocispec.Descriptor
+	for i, layer := range []ocispec.Descriptor{foo, bar} {
+		manifest := pushJSON(t, src, ocispec.MediaTypeImageManifest, ocispec.Manifest{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			MediaType: ocispec.MediaTypeImageManifest,
+			Config:    config,
+			Layers:    []ocispec.Descriptor{shared, layer},
+		})
+		manifest.Platform = &ocispec.Platform{OS: "linux", Architecture: []string{"amd64", "arm64"}[i]}
+		manifests = append(manifests, manifest)
+	}
+	index := pushJSON(t, src, ocispec.MediaTypeImageIndex, ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: manifests,
+	})
+	if err := src.Tag(ctx, index, "v1"); err != nil {
+		t.Fatal("Tag() error =", err)
+	}
+
+	// test Encrypt
+	encrypted := memory.New()
+	encRoot, err := Encrypt(ctx, src, "v1", encrypted, "", CopyOptions{
+		KeyWrappers: []KeyWrapper{&JWE{Recipients: []*rsa.PublicKey{&key.PublicKey}}},
+		LayerFilter: func(desc ocispec.Descriptor) bool {
+			return desc.Digest != bar.Digest
+		},
+	})
+	if err != nil {
+		t.Fatal("Encrypt() error =", err)
+	}
+	if got, err := encrypted.Resolve(ctx, "v1"); err != nil || !content.Equal(got, encRoot) {
+		t.Errorf("Resolve() = %v, %v, want %v", got, err, encRoot)
+	}
+	var encIndex ocispec.Index
+	fetchManifest(t, encrypted, encRoot, &encIndex)
+	if len(encIndex.Manifests) != 2 {
+		t.Fatalf("len(Index.Manifests) = %d, want %d", len(encIndex.Manifests), 2)
+	}
+	var encShared []ocispec.Descriptor
+	for i, desc := range encIndex.Manifests {
+		if desc.Platform == nil || desc.Platform.Architecture != manifests[i].Platform.Architecture {
+			t.Errorf("Index.Manifests[%d].Platform = %v, want %v", i, desc.Platform, manifests[i].Platform)
+		}
+		var manifest ocispec.Manifest
+		fetchManifest(t, encrypted, desc, &manifest)
+		if !content.Equal(manifest.Config, config) {
+			t.Errorf("Manifest.Config = %v, want %v", manifest.Config, config)
+		}
+		if exists, err := encrypted.Exists(ctx, config); err != nil || !exists {
+			t.Errorf("Exists(config) = %v, %v, want %v", exists, err, true)
+		}
+		encShared = append(encShared, manifest.Layers[0])
+		for j, layer := range manifest.Layers {
+			wantEncrypted := layer.Digest != bar.Digest
+			if IsEncrypted(layer) != wantEncrypted {
+				t.Errorf("Manifest[%d].Layers[%d] encrypted = %v, want %v", i, j, !wantEncrypted, wantEncrypted)
+			}
+			if exists, err := encrypted.Exists(ctx, layer); err != nil || !exists {
+				t.Errorf("Exists(layer) = %v, %v, want %v", exists, err, true)
+			}
+		}
+	}
+	if !content.Equal(encShared[0], encShared[1]) {
+		t.Errorf("shared layer encrypted twice: %v, %v", encShared[0], encShared[1])
+	}
+
+	// test Decrypt
+	decrypted := memory.New()
+	decRoot, err := Decrypt(ctx, encrypted, "v1", decrypted, "v1-decrypted", CopyOptions{
+		KeyWrappers: []KeyWrapper{&JWE{PrivateKeys: []*rsa.PrivateKey{key}}},
+	})
+	if err != nil {
+		t.Fatal("Decrypt() error =", err)
+	}
+	if got, err := decrypted.Resolve(ctx, "v1-decrypted"); err != nil || !content.Equal(got, decRoot) {
+		t.Errorf("Resolve() = %v, %v, want %v", got, err, decRoot)
+	}
+	var decIndex ocispec.Index
+	fetchManifest(t, decrypted, decRoot, &decIndex)
+	for i, desc := range decIndex.Manifests {
+		var manifest ocispec.Manifest
+		fetchManifest(t, decrypted, desc, &manifest)
+		want := []ocispec.Descriptor{shared, foo}
+		if i == 1 {
+			want[1] = bar
+		}
+		for j, layer := range manifest.Layers {
+			if !content.Equal(layer, want[j]) {
+				t.Errorf("Manifest[%d].Layers[%d] = %v, want %v", i, j, layer, want[j])
+			}
+			got, err := content.FetchAll(ctx, decrypted, layer)
+			if err != nil {
+				t.Fatal("FetchAll() error =", err)
+			}
+			wantBlob, err := content.FetchAll(ctx, src, want[j])
+			if err != nil {
+				t.Fatal("FetchAll() error =", err)
+			}
+			if !bytes.Equal(got, wantBlob) {
+				t.Errorf("Manifest[%d].Layers[%d] content = %q, want %q", i, j, got, wantBlob)
+			}
+		}
+	}
+
+	// test decrypting without the key
+	_, err = Decrypt(ctx, encrypted, "v1", memory.New(), "", CopyOptions{
+		KeyWrappers: []KeyWrapper{&JWE{PrivateKeys: []*rsa.PrivateKey{generateKey(t)}}},
+	})
+	if !errors.Is(err, ErrNoKey) {
+		t.Errorf("Decrypt() error = %v, wantErr %v", err, ErrNoKey)
+	}
+
+	// test no key wrappers
+	if _, err := Encrypt(ctx, src, "v1", memory.New(), "", CopyOptions{}); err == nil {
+		t.Error("Encrypt() error = nil, wantErr true")
+	}
+	if _, err := Decrypt(ctx, src, "v1", memory.New(), "", CopyOptions{}); err == nil {
+		t.Error("Decrypt() error = nil, wantErr true")
+	}
+}
+
+func TestEncrypt_Unchanged(t *testing.T) {
+	ctx := context.Background()
+	key := generateKey(t)
+	src := memory.New()
+	config := pushBlob(t, src, ocispec.MediaTypeImageConfig, []byte("{}"))
+	layer := pushBlob(t, src, ocispec.MediaTypeImageLayer, []byte("layer"))
+	manifest := pushJSON(t, src, ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    []ocispec.Descriptor{layer},
+	})
+	if err := src.Tag(ctx, manifest, "v1"); err != nil {
+		t.Fatal("Tag() error =", err)
+	}
+
+	// test decrypting an image without encrypted layers
+	dst := memory.New()
+	root, err := Decrypt(ctx, src, "v1", dst, "", CopyOptions{
+		KeyWrappers: []KeyWrapper{&JWE{PrivateKeys: []*rsa.PrivateKey{key}}},
+	})
+	if err != nil {
+		t.Fatal("Decrypt() error =", err)
+	}
+	if !content.Equal(root, manifest) {
+		t.Errorf("Decrypt() = %v, want %v", root, manifest)
+	}
+	for _, desc := range []ocispec.Descriptor{config, layer, manifest} {
+		if exists, err := dst.Exists(ctx, desc); err != nil || !exists {
+			t.Errorf("Exists(%v) = %v, %v, want %v", desc, exists, err, true)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package encryption encrypts and decrypts image layers in the format of
+// ocicrypt, as an optional transformation when copying images.
+//
+// Each layer is encrypted with a random symmetric key using the
+// AES_256_CTR_HMAC_SHA256 cipher. The key is wrapped for each recipient by
+// KeyWrappers, and stored in the layer annotations along with the public
+// options of the cipher. The JWE key wrapper for RSA keys is built in; the
+// other protocols of ocicrypt, such as pgp, pkcs7, pkcs11 and key providers,
+// can be plugged in by implementing KeyWrapper.
+//
+// Reference: https://github.com/containers/ocicrypt/blob/main/docs/spec.md
+package encryption
+
+import (
+	"errors"
+)
+
+const (
+	// AnnotationKeysPrefix is the prefix of the layer annotations holding the
+	// wrapped keys, followed by the protocol of the key wrapper.
+	AnnotationKeysPrefix = "org.opencontainers.image.enc.keys."
+	// AnnotationPublicOptions is the layer annotation holding the public
+	// options of the layer cipher.
+	AnnotationPublicOptions = "org.opencontainers.image.enc.pubopts"
+
+	// mediaTypeSuffix is the suffix of the media types of encrypted layers.
+	mediaTypeSuffix = "+encrypted"
+)
+
+var (
+	// ErrNoKey is returned when none of the key wrappers is able to unwrap
+	// the key of an encrypted layer.
+	ErrNoKey = errors.New("no decryption key")
+	// ErrInvalidEncryptedLayer is returned when an encrypted layer is
+	// malformed or fails authentication.
+	ErrInvalidEncryptedLayer = errors.New("invalid encrypted layer")
+	// ErrUnsupportedMediaType is returned when a layer of an unsupported media
+	// type is requested to be encrypted or decrypted.
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+)
+
+// KeyWrapper wraps the private options of the layer cipher, which contain the
+// symmetric key, for a set of recipients, and unwraps them with private keys.
+type KeyWrapper interface {
+	// Protocol returns the name of the key wrapping protocol, such as "jwe",
+	// which is the suffix of the annotation holding the wrapped keys.
+	Protocol() string
+	// WrapKeys wraps optsData for all the recipients.
+	WrapKeys(optsData []byte) ([]byte, error)
+	// UnwrapKey unwraps the data wrapped by WrapKeys.
+	// Returns ErrNoKey if none of the private keys is able to unwrap it.
+	UnwrapKey(wrapped []byte) ([]byte, error)
+}
@@ -0,0 +1,218 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// JWE key management and content encryption algorithms.
+const (
+	jweAlgRSAOAEP    = "RSA-OAEP"
+	jweAlgRSAOAEP256 = "RSA-OAEP-256"
+	jweEncA256GCM    = "A256GCM"
+)
+
+// JWE is a KeyWrapper of the "jwe" protocol, wrapping the keys in JSON Web
+// Encryption (RFC 7516) objects in the general JSON serialization, with
+// RSA-OAEP key encryption and A256GCM content encryption, as ocicrypt does for
+// RSA keys.
+type JWE struct {
+	// Recipients are the public keys of the recipients, used for wrapping.
+	Recipients []*rsa.PublicKey
+	// PrivateKeys are the private keys used for unwrapping.
+	PrivateKeys []*rsa.PrivateKey
+}
+
+// jweHeader is the JOSE header of a JWE object.
+type jweHeader struct {
+	Algorithm  string `json:"alg,omitempty"`
+	Encryption string `json:"enc,omitempty"`
+}
+
+// jweRecipient is a recipient of a JWE object.
+type jweRecipient struct {
+	Header       *jweHeader `json:"header,omitempty"`
+	EncryptedKey string     `json:"encrypted_key,omitempty"`
+}
+
+// jweObject is a JWE object in the general or the flattened JSON
+// serialization.
+type jweObject struct {
+	Protected    string         `json:"protected,omitempty"`
+	Unprotected  *jweHeader     `json:"unprotected,omitempty"`
+	Recipients   []jweRecipient `json:"recipients,omitempty"`
+	Header       *jweHeader     `json:"header,omitempty"`
+	EncryptedKey string         `json:"encrypted_key,omitempty"`
+	AAD          string         `json:"aad,omitempty"`
+	IV           string         `json:"iv"`
+	Ciphertext   string         `json:"ciphertext"`
+	Tag          string         `json:"tag"`
+}
+
+// Protocol returns "jwe".
+func (j *JWE) Protocol() string {
+	return "jwe"
+}
+
+// WrapKeys wraps optsData for all the recipients.
+func (j *JWE) WrapKeys(optsData []byte) ([]byte, error) {
+	if len(j.Recipients) == 0 {
+		return nil, errors.New("no recipients")
+	}
+	cek := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, cek); err != nil {
+		return nil, err
+	}
+	protectedJSON, err := json.Marshal(jweHeader{Encryption: jweEncA256GCM})
+	if err != nil {
+		return nil, err
+	}
+	obj := jweObject{
+		Protected: base64.RawURLEncoding.EncodeToString(protectedJSON),
+	}
+	for _, pub := range j.Recipients {
+		encryptedKey, err := rsa.EncryptOAEP(sha1.New(), rand.Reader, pub, cek, nil)
+		if err != nil {
+			return nil, err
+		}
+		obj.Recipients = append(obj.Recipients, jweRecipient{
+			Header:       &jweHeader{Algorithm: jweAlgRSAOAEP},
+			EncryptedKey: base64.RawURLEncoding.EncodeToString(encryptedKey),
+		})
+	}
+
+	aead, err := newGCM(cek)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(nil, iv, optsData, []byte(obj.Protected))
+	tagStart := len(sealed) - aead.Overhead()
+	obj.IV = base64.RawURLEncoding.EncodeToString(iv)
+	obj.Ciphertext = base64.RawURLEncoding.EncodeToString(sealed[:tagStart])
+	obj.Tag = base64.RawURLEncoding.EncodeToString(sealed[tagStart:])
+	return json.Marshal(obj)
+}
+
+// UnwrapKey unwraps the data wrapped by WrapKeys, or by ocicrypt for RSA
+// keys.
+// Returns ErrNoKey if none of the private keys is able to unwrap it.
+func (j *JWE) UnwrapKey(wrapped []byte) ([]byte, error) {
+	var obj jweObject
+	if err := json.Unmarshal(wrapped, &obj); err != nil {
+		return nil, fmt.Errorf("invalid JWE: %w", err)
+	}
+	var protected jweHeader
+	if obj.Protected != "" {
+		protectedJSON, err := base64.RawURLEncoding.DecodeString(obj.Protected)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWE protected header: %w", err)
+		}
+		if err := json.Unmarshal(protectedJSON, &protected); err != nil {
+			return nil, fmt.Errorf("invalid JWE protected header: %w", err)
+		}
+	}
+	enc := protected.Encryption
+	if enc == "" && obj.Unprotected != nil {
+		enc = obj.Unprotected.Encryption
+	}
+	if enc != jweEncA256GCM {
+		return nil, fmt.Errorf("unsupported JWE content encryption %q", enc)
+	}
+	recipients := obj.Recipients
+	if len(recipients) == 0 {
+		// flattened JSON serialization
+		recipients = []jweRecipient{{Header: obj.Header, EncryptedKey: obj.EncryptedKey}}
+	}
+
+	iv, err := base64.RawURLEncoding.DecodeString(obj.IV)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWE iv: %w", err)
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(obj.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWE ciphertext: %w", err)
+	}
+	tag, err := base64.RawURLEncoding.DecodeString(obj.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWE tag: %w", err)
+	}
+	aad := obj.Protected
+	if obj.AAD != "" {
+		aad += "." + obj.AAD
+	}
+
+	for _, recipient := range recipients {
+		alg := protected.Algorithm
+		if recipient.Header != nil && recipient.Header.Algorithm != "" {
+			alg = recipient.Header.Algorithm
+		} else if alg == "" && obj.Unprotected != nil {
+			alg = obj.Unprotected.Algorithm
+		}
+		var newHash func() hash.Hash
+		switch alg {
+		case jweAlgRSAOAEP:
+			newHash = sha1.New
+		case jweAlgRSAOAEP256:
+			newHash = sha256.New
+		default:
+			continue
+		}
+		encryptedKey, err := base64.RawURLEncoding.DecodeString(recipient.EncryptedKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWE encrypted key: %w", err)
+		}
+		for _, priv := range j.PrivateKeys {
+			cek, err := rsa.DecryptOAEP(newHash(), nil, priv, encryptedKey, nil)
+			if err != nil {
+				continue
+			}
+			aead, err := newGCM(cek)
+			if err != nil {
+				continue
+			}
+			sealed := append(ciphertext[:len(ciphertext):len(ciphertext)], tag...)
+			if plaintext, err := aead.Open(nil, iv, sealed, []byte(aad)); err == nil {
+				return plaintext, nil
+			}
+		}
+	}
+	return nil, ErrNoKey
+}
+
+// newGCM creates an AES-GCM AEAD.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
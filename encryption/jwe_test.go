@@ -0,0 +1,122 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal("rsa.GenerateKey() error =", err)
+	}
+	return key
+}
+
+func TestJWE(t *testing.T) {
+	alice := generateKey(t)
+	bob := generateKey(t)
+	eve := generateKey(t)
+	data := []byte(`{"symkey":"secret"}`)
+
+	wrapper := &JWE{
+		Recipients: []*rsa.PublicKey{&alice.PublicKey, &bob.PublicKey},
+	}
+	if got := wrapper.Protocol(); got != "jwe" {
+		t.Errorf("JWE.Protocol() = %v, want %v", got, "jwe")
+	}
+	wrapped, err := wrapper.WrapKeys(data)
+	if err != nil {
+		t.Fatal("JWE.WrapKeys() error =", err)
+	}
+	if bytes.Contains(wrapped, data) {
+		t.Errorf("JWE.WrapKeys() = %s, contains the plaintext", wrapped)
+	}
+
+	for _, key := range []*rsa.PrivateKey{alice, bob} {
+		got, err := (&JWE{PrivateKeys: []*rsa.PrivateKey{eve, key}}).UnwrapKey(wrapped)
+		if err != nil {
+			t.Fatal("JWE.UnwrapKey() error =", err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("JWE.UnwrapKey() = %s, want %s", got, data)
+		}
+	}
+
+	// test non-recipient
+	if _, err := (&JWE{PrivateKeys: []*rsa.PrivateKey{eve}}).UnwrapKey(wrapped); !errors.Is(err, ErrNoKey) {
+		t.Errorf("JWE.UnwrapKey() error = %v, wantErr %v", err, ErrNoKey)
+	}
+
+	// test no recipients
+	if _, err := (&JWE{}).WrapKeys(data); err == nil {
+		t.Error("JWE.WrapKeys() error = nil, wantErr true")
+	}
+}
+
+func TestJWE_UnwrapKey_Flattened(t *testing.T) {
+	key := generateKey(t)
+	data := []byte(`{"symkey":"secret"}`)
+
+	// build a JWE object in the flattened JSON serialization with the
+	// algorithm in the protected header, using RSA-OAEP-256
+	cek := bytes.Repeat([]byte{1}, 32)
+	encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, &key.PublicKey, cek, nil)
+	if err != nil {
+		t.Fatal("rsa.EncryptOAEP() error =", err)
+	}
+	protected := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RSA-OAEP-256","enc":"A256GCM"}`))
+	aead, err := newGCM(cek)
+	if err != nil {
+		t.Fatal("newGCM() error =", err)
+	}
+	iv := make([]byte, aead.NonceSize())
+	sealed := aead.Seal(nil, iv, data, []byte(protected))
+	tagStart := len(sealed) - aead.Overhead()
+	wrapped, err := json.Marshal(map[string]string{
+		"protected":     protected,
+		"encrypted_key": base64.RawURLEncoding.EncodeToString(encryptedKey),
+		"iv":            base64.RawURLEncoding.EncodeToString(iv),
+		"ciphertext":    base64.RawURLEncoding.EncodeToString(sealed[:tagStart]),
+		"tag":           base64.RawURLEncoding.EncodeToString(sealed[tagStart:]),
+	})
+	if err != nil {
+		t.Fatal("json.Marshal() error =", err)
+	}
+
+	got, err := (&JWE{PrivateKeys: []*rsa.PrivateKey{key}}).UnwrapKey(wrapped)
+	if err != nil {
+		t.Fatal("JWE.UnwrapKey() error =", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("JWE.UnwrapKey() = %s, want %s", got, data)
+	}
+
+	// test unsupported content encryption
+	badProtected := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RSA-OAEP-256","enc":"A128CBC-HS256"}`))
+	bad := bytes.Replace(wrapped, []byte(protected), []byte(badProtected), 1)
+	if _, err := (&JWE{PrivateKeys: []*rsa.PrivateKey{key}}).UnwrapKey(bad); err == nil || errors.Is(err, ErrNoKey) {
+		t.Errorf("JWE.UnwrapKey() error = %v, want unsupported encryption", err)
+	}
+}
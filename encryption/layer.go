@@ -0,0 +1,300 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/internal/docker"
+)
+
+// CipherAES256CTRHMACSHA256 is the layer cipher of ocicrypt, which encrypts
+// the layers with AES-256 in the CTR mode and authenticates the ciphertext
+// with HMAC-SHA256 using the same key.
+const CipherAES256CTRHMACSHA256 = "AES_256_CTR_HMAC_SHA256"
+
+// publicOptions is the public layer block cipher options, stored in the
+// AnnotationPublicOptions annotation.
+type publicOptions struct {
+	CipherType    string            `json:"cipher"`
+	Hmac          []byte            `json:"hmac"`
+	CipherOptions map[string][]byte `json:"cipheroptions"`
+}
+
+// privateOptions is the private layer block cipher options, which are
+// wrapped by the KeyWrappers for the recipients.
+type privateOptions struct {
+	SymmetricKey  []byte            `json:"symkey"`
+	Digest        digest.Digest     `json:"digest"`
+	CipherOptions map[string][]byte `json:"cipheroptions"`
+}
+
+// IsEncrypted returns true if the descriptor describes an encrypted layer.
+func IsEncrypted(desc ocispec.Descriptor) bool {
+	return strings.HasSuffix(desc.MediaType, mediaTypeSuffix)
+}
+
+// encryptedMediaType returns the media type of the encrypted layer.
+// Docker layers are converted to OCI layers, as ocicrypt does.
+func encryptedMediaType(mediaType string) (string, error) {
+	switch mediaType {
+	case docker.MediaTypeLayer:
+		return ocispec.MediaTypeImageLayerGzip + mediaTypeSuffix, nil
+	case ocispec.MediaTypeImageLayer,
+		ocispec.MediaTypeImageLayerGzip,
+		ocispec.MediaTypeImageLayerZstd,
+		ocispec.MediaTypeImageLayerNonDistributable,
+		ocispec.MediaTypeImageLayerNonDistributableGzip,
+		ocispec.MediaTypeImageLayerNonDistributableZstd:
+		return mediaType + mediaTypeSuffix, nil
+	}
+	return "", fmt.Errorf("%s: %w", mediaType, ErrUnsupportedMediaType)
+}
+
+// EncryptLayer encrypts the layer described by desc read from r into w, and
+// wraps the key for the recipients of each of the key wrappers.
+// Returns the descriptor of the encrypted layer, whose annotations carry the
+// wrapped keys and the public options.
+// The content read from r is not verified against desc.
+func EncryptLayer(r io.Reader, w io.Writer, desc ocispec.Descriptor, wrappers []KeyWrapper) (ocispec.Descriptor, error) {
+	if len(wrappers) == 0 {
+		return ocispec.Descriptor{}, errors.New("no key wrappers")
+	}
+	mediaType, err := encryptedMediaType(desc.MediaType)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	key := make([]byte, 32)
+	nonce := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	stream, err := newCTR(key, nonce)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	digester := digest.Canonical.Digester()
+	cw := &cipherWriter{
+		w:      io.MultiWriter(w, mac, digester.Hash()),
+		stream: stream,
+	}
+	n, err := io.Copy(cw, r)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	priv := privateOptions{
+		SymmetricKey:  key,
+		Digest:        desc.Digest,
+		CipherOptions: map[string][]byte{"nonce": nonce},
+	}
+	pub := publicOptions{
+		CipherType:    CipherAES256CTRHMACSHA256,
+		Hmac:          mac.Sum(nil),
+		CipherOptions: map[string][]byte{},
+	}
+	privJSON, err := json.Marshal(priv)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	pubJSON, err := json.Marshal(pub)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	annotations := make(map[string]string, len(desc.Annotations)+len(wrappers)+1)
+	for k, v := range desc.Annotations {
+		annotations[k] = v
+	}
+	for _, wrapper := range wrappers {
+		wrapped, err := wrapper.WrapKeys(privJSON)
+		if err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("failed to wrap keys with %s: %w", wrapper.Protocol(), err)
+		}
+		annotations[AnnotationKeysPrefix+wrapper.Protocol()] = base64.StdEncoding.EncodeToString(wrapped)
+	}
+	annotations[AnnotationPublicOptions] = base64.StdEncoding.EncodeToString(pubJSON)
+
+	return ocispec.Descriptor{
+		MediaType:   mediaType,
+		Digest:      digester.Digest(),
+		Size:        n,
+		URLs:        desc.URLs,
+		Annotations: annotations,
+		Platform:    desc.Platform,
+	}, nil
+}
+
+// DecryptLayer decrypts the encrypted layer described by desc read from r
+// into w, using the first key wrapper able to unwrap the key.
+// The ciphertext is authenticated, and the plaintext is verified against the
+// digest recorded in the private options. Since the verification completes
+// only after the whole layer is read, the content written to w must be
+// discarded if an error is returned.
+// Returns the descriptor of the decrypted layer.
+// Returns ErrNoKey if none of the key wrappers is able to unwrap the key.
+func DecryptLayer(r io.Reader, w io.Writer, desc ocispec.Descriptor, wrappers []KeyWrapper) (ocispec.Descriptor, error) {
+	if !IsEncrypted(desc) {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, ErrUnsupportedMediaType)
+	}
+	pub, err := parsePublicOptions(desc)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	priv, err := unwrapPrivateOptions(desc, wrappers)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if pub.CipherType != CipherAES256CTRHMACSHA256 {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: %s: unsupported cipher %q: %w", desc.Digest, desc.MediaType, pub.CipherType, ErrInvalidEncryptedLayer)
+	}
+	if err := priv.Digest.Validate(); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: %s: invalid plaintext digest: %w", desc.Digest, desc.MediaType, ErrInvalidEncryptedLayer)
+	}
+	stream, err := newCTR(priv.SymmetricKey, priv.CipherOptions["nonce"])
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: %s: %v: %w", desc.Digest, desc.MediaType, err, ErrInvalidEncryptedLayer)
+	}
+
+	mac := hmac.New(sha256.New, priv.SymmetricKey)
+	verifier := priv.Digest.Verifier()
+	cw := &cipherWriter{
+		w:      io.MultiWriter(w, verifier),
+		stream: stream,
+	}
+	n, err := io.Copy(cw, io.TeeReader(r, mac))
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if !hmac.Equal(mac.Sum(nil), pub.Hmac) {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: %s: HMAC mismatch: %w", desc.Digest, desc.MediaType, ErrInvalidEncryptedLayer)
+	}
+	if !verifier.Verified() {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: %s: plaintext digest mismatch: %w", desc.Digest, desc.MediaType, ErrInvalidEncryptedLayer)
+	}
+
+	annotations := make(map[string]string, len(desc.Annotations))
+	for k, v := range desc.Annotations {
+		if k == AnnotationPublicOptions || strings.HasPrefix(k, AnnotationKeysPrefix) {
+			continue
+		}
+		annotations[k] = v
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	return ocispec.Descriptor{
+		MediaType:   strings.TrimSuffix(desc.MediaType, mediaTypeSuffix),
+		Digest:      priv.Digest,
+		Size:        n,
+		URLs:        desc.URLs,
+		Annotations: annotations,
+		Platform:    desc.Platform,
+	}, nil
+}
+
+// parsePublicOptions parses the public options of the encrypted layer.
+func parsePublicOptions(desc ocispec.Descriptor) (publicOptions, error) {
+	var pub publicOptions
+	pubJSON, err := base64.StdEncoding.DecodeString(desc.Annotations[AnnotationPublicOptions])
+	if err != nil {
+		return pub, fmt.Errorf("%s: %s: invalid public options: %w", desc.Digest, desc.MediaType, ErrInvalidEncryptedLayer)
+	}
+	if len(pubJSON) == 0 {
+		// layers encrypted by early versions of ocicrypt have no public
+		// options, and are not authenticated
+		return pub, fmt.Errorf("%s: %s: missing public options: %w", desc.Digest, desc.MediaType, ErrInvalidEncryptedLayer)
+	}
+	if err := json.Unmarshal(pubJSON, &pub); err != nil {
+		return pub, fmt.Errorf("%s: %s: invalid public options: %w", desc.Digest, desc.MediaType, ErrInvalidEncryptedLayer)
+	}
+	return pub, nil
+}
+
+// unwrapPrivateOptions unwraps the private options of the encrypted layer
+// with the first key wrapper able to unwrap them.
+func unwrapPrivateOptions(desc ocispec.Descriptor, wrappers []KeyWrapper) (privateOptions, error) {
+	var priv privateOptions
+	for _, wrapper := range wrappers {
+		value, ok := desc.Annotations[AnnotationKeysPrefix+wrapper.Protocol()]
+		if !ok {
+			continue
+		}
+		wrapped, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return priv, fmt.Errorf("%s: %s: invalid wrapped keys: %w", desc.Digest, desc.MediaType, ErrInvalidEncryptedLayer)
+		}
+		privJSON, err := wrapper.UnwrapKey(wrapped)
+		if err != nil {
+			if errors.Is(err, ErrNoKey) {
+				continue
+			}
+			return priv, fmt.Errorf("%s: %s: failed to unwrap keys with %s: %w", desc.Digest, desc.MediaType, wrapper.Protocol(), err)
+		}
+		if err := json.Unmarshal(privJSON, &priv); err != nil {
+			return priv, fmt.Errorf("%s: %s: invalid private options: %w", desc.Digest, desc.MediaType, ErrInvalidEncryptedLayer)
+		}
+		return priv, nil
+	}
+	return priv, fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, ErrNoKey)
+}
+
+// newCTR creates an AES-CTR stream.
+func newCTR(key, nonce []byte) (cipher.Stream, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != block.BlockSize() {
+		return nil, fmt.Errorf("invalid nonce size %d", len(nonce))
+	}
+	return cipher.NewCTR(block, nonce), nil
+}
+
+// cipherWriter XORs the written content with the key stream.
+type cipherWriter struct {
+	w      io.Writer
+	stream cipher.Stream
+	buf    []byte
+}
+
+// Write writes the transformed p to the underlying writer.
+func (cw *cipherWriter) Write(p []byte) (int, error) {
+	if cap(cw.buf) < len(p) {
+		cw.buf = make([]byte, len(p))
+	}
+	buf := cw.buf[:len(p)]
+	cw.stream.XORKeyStream(buf, p)
+	return cw.w.Write(buf)
+}
@@ -0,0 +1,162 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/internal/docker"
+)
+
+func TestEncryptLayer(t *testing.T) {
+	key := generateKey(t)
+	encrypter := []KeyWrapper{&JWE{Recipients: []*rsa.PublicKey{&key.PublicKey}}}
+	decrypter := []KeyWrapper{&JWE{PrivateKeys: []*rsa.PrivateKey{key}}}
+
+	layer := bytes.Repeat([]byte("hello world"), 1000)
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayerGzip, layer)
+	desc.Annotations = map[string]string{
+		ocispec.AnnotationTitle: "hello.txt",
+	}
+
+	var encrypted bytes.Buffer
+	encDesc, err := EncryptLayer(bytes.NewReader(layer), &encrypted, desc, encrypter)
+	if err != nil {
+		t.Fatal("EncryptLayer() error =", err)
+	}
+	if want := ocispec.MediaTypeImageLayerGzip + "+encrypted"; encDesc.MediaType != want {
+		t.Errorf("EncryptLayer() mediaType = %v, want %v", encDesc.MediaType, want)
+	}
+	if !IsEncrypted(encDesc) {
+		t.Errorf("IsEncrypted() = %v, want %v", false, true)
+	}
+	if want := content.NewDescriptorFromBytes(encDesc.MediaType, encrypted.Bytes()); encDesc.Digest != want.Digest || encDesc.Size != want.Size {
+		t.Errorf("EncryptLayer() = %v, want digest %v and size %v", encDesc, want.Digest, want.Size)
+	}
+	if bytes.Contains(encrypted.Bytes(), []byte("hello world")) {
+		t.Error("EncryptLayer() output contains the plaintext")
+	}
+	for _, name := range []string{AnnotationKeysPrefix + "jwe", AnnotationPublicOptions, ocispec.AnnotationTitle} {
+		if _, ok := encDesc.Annotations[name]; !ok {
+			t.Errorf("EncryptLayer() annotation %s missing", name)
+		}
+	}
+
+	var decrypted bytes.Buffer
+	decDesc, err := DecryptLayer(bytes.NewReader(encrypted.Bytes()), &decrypted, encDesc, decrypter)
+	if err != nil {
+		t.Fatal("DecryptLayer() error =", err)
+	}
+	if !bytes.Equal(decrypted.Bytes(), layer) {
+		t.Error("DecryptLayer() output mismatches the plaintext")
+	}
+	if !content.Equal(decDesc, desc) {
+		t.Errorf("DecryptLayer() = %v, want %v", decDesc, desc)
+	}
+	if got := decDesc.Annotations; len(got) != 1 || got[ocispec.AnnotationTitle] != "hello.txt" {
+		t.Errorf("DecryptLayer() annotations = %v, want %v", got, desc.Annotations)
+	}
+
+	// test tampered ciphertext
+	tampered := append([]byte(nil), encrypted.Bytes()...)
+	tampered[len(tampered)/2] ^= 1
+	if _, err := DecryptLayer(bytes.NewReader(tampered), &bytes.Buffer{}, encDesc, decrypter); !errors.Is(err, ErrInvalidEncryptedLayer) {
+		t.Errorf("DecryptLayer() error = %v, wantErr %v", err, ErrInvalidEncryptedLayer)
+	}
+
+	// test missing public options
+	noPubOpts := encDesc
+	noPubOpts.Annotations = map[string]string{
+		AnnotationKeysPrefix + "jwe": encDesc.Annotations[AnnotationKeysPrefix+"jwe"],
+	}
+	if _, err := DecryptLayer(bytes.NewReader(encrypted.Bytes()), &bytes.Buffer{}, noPubOpts, decrypter); !errors.Is(err, ErrInvalidEncryptedLayer) {
+		t.Errorf("DecryptLayer() error = %v, wantErr %v", err, ErrInvalidEncryptedLayer)
+	}
+
+	// test forged public options
+	forged := encDesc
+	forged.Annotations = map[string]string{
+		AnnotationKeysPrefix + "jwe": encDesc.Annotations[AnnotationKeysPrefix+"jwe"],
+		AnnotationPublicOptions:      base64.StdEncoding.EncodeToString([]byte(`{"cipher":"AES_256_CTR_HMAC_SHA256","hmac":"AAAA"}`)),
+	}
+	if _, err := DecryptLayer(bytes.NewReader(encrypted.Bytes()), &bytes.Buffer{}, forged, decrypter); !errors.Is(err, ErrInvalidEncryptedLayer) {
+		t.Errorf("DecryptLayer() error = %v, wantErr %v", err, ErrInvalidEncryptedLayer)
+	}
+
+	// test no key
+	other := []KeyWrapper{&JWE{PrivateKeys: []*rsa.PrivateKey{generateKey(t)}}}
+	if _, err := DecryptLayer(bytes.NewReader(encrypted.Bytes()), &bytes.Buffer{}, encDesc, other); !errors.Is(err, ErrNoKey) {
+		t.Errorf("DecryptLayer() error = %v, wantErr %v", err, ErrNoKey)
+	}
+
+	// test decrypting plaintext layer
+	if _, err := DecryptLayer(bytes.NewReader(layer), &bytes.Buffer{}, desc, decrypter); !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Errorf("DecryptLayer() error = %v, wantErr %v", err, ErrUnsupportedMediaType)
+	}
+}
+
+func TestEncryptLayer_MediaType(t *testing.T) {
+	key := generateKey(t)
+	encrypter := []KeyWrapper{&JWE{Recipients: []*rsa.PublicKey{&key.PublicKey}}}
+	layer := []byte("hello world")
+
+	tests := []struct {
+		mediaType string
+		want      string
+		wantErr   error
+	}{
+		{
+			mediaType: ocispec.MediaTypeImageLayer,
+			want:      "application/vnd.oci.image.layer.v1.tar+encrypted",
+		},
+		{
+			mediaType: ocispec.MediaTypeImageLayerZstd,
+			want:      "application/vnd.oci.image.layer.v1.tar+zstd+encrypted",
+		},
+		{
+			mediaType: docker.MediaTypeLayer,
+			want:      "application/vnd.oci.image.layer.v1.tar+gzip+encrypted",
+		},
+		{
+			mediaType: ocispec.MediaTypeImageConfig,
+			wantErr:   ErrUnsupportedMediaType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mediaType, func(t *testing.T) {
+			desc := content.NewDescriptorFromBytes(tt.mediaType, layer)
+			got, err := EncryptLayer(bytes.NewReader(layer), &bytes.Buffer{}, desc, encrypter)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("EncryptLayer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.MediaType != tt.want {
+				t.Errorf("EncryptLayer() mediaType = %v, want %v", got.MediaType, tt.want)
+			}
+		})
+	}
+
+	// test no key wrappers
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+	if _, err := EncryptLayer(bytes.NewReader(layer), &bytes.Buffer{}, desc, nil); err == nil {
+		t.Error("EncryptLayer() error = nil, wantErr true")
+	}
+}
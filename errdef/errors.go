@@ -23,6 +23,7 @@ var (
 	ErrInvalidDigest      = errors.New("invalid digest")
 	ErrInvalidReference   = errors.New("invalid reference")
 	ErrMissingReference   = errors.New("missing reference")
+	ErrNonConformant      = errors.New("non-conformant")
 	ErrNotFound           = errors.New("not found")
 	ErrSizeExceedsLimit   = errors.New("size exceeds limit")
 	ErrUnsupported        = errors.New("unsupported")
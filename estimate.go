@@ -0,0 +1,138 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/internal/container/set"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/syncutil"
+)
+
+// defaultEstimateConcurrency is the default value of
+// EstimateOptions.Concurrency.
+const defaultEstimateConcurrency int = 3
+
+// EstimateOptions contains parameters for [oras.Estimate].
+type EstimateOptions struct {
+	// Concurrency limits the maximum number of manifests fetched
+	// concurrently.
+	// If less than or equal to 0, a default (currently 3) is used.
+	Concurrency int
+	// FindSuccessors finds the successors of the current manifest.
+	// It should be consistent with CopyGraphOptions.FindSuccessors of the
+	// copy being estimated.
+	// If FindSuccessors is nil, content.Successors will be used.
+	FindSuccessors func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error)
+}
+
+// MediaTypeEstimate is the share of a media type in a GraphEstimate.
+type MediaTypeEstimate struct {
+	// Count is the number of nodes of the media type.
+	Count int
+	// Size is the total size of the nodes of the media type.
+	Size int64
+}
+
+// GraphEstimate is the estimated size of a graph.
+type GraphEstimate struct {
+	// Root is the root node of the graph.
+	Root ocispec.Descriptor
+	// Size is the total size of the nodes in the graph, including the
+	// manifests.
+	Size int64
+	// ManifestCount is the number of manifests in the graph.
+	ManifestCount int
+	// BlobCount is the number of blobs, such as configs and layers, in the
+	// graph.
+	BlobCount int
+	// MediaTypes breaks down the nodes in the graph by media type.
+	MediaTypes map[string]MediaTypeEstimate
+}
+
+// Estimate walks the graph rooted at root in src and estimates the amount of
+// content transferred by copying the graph, before any blob is transferred.
+// Only the manifests are fetched; the sizes of the blobs are taken from their
+// descriptors. Nodes shared in the graph are counted once, and foreign layers
+// are excluded as they are not copied.
+// The estimate does not account for the content already existing in the
+// destination.
+func Estimate(ctx context.Context, src content.ReadOnlyStorage, root ocispec.Descriptor, opts EstimateOptions) (*GraphEstimate, error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultEstimateConcurrency
+	}
+	findSuccessors := opts.FindSuccessors
+	if findSuccessors == nil {
+		findSuccessors = content.Successors
+	}
+
+	estimate := &GraphEstimate{
+		Root:       root,
+		MediaTypes: make(map[string]MediaTypeEstimate),
+	}
+	visited := set.New[descriptor.Descriptor]()
+	visited.Add(descriptor.FromOCI(root))
+	level := []ocispec.Descriptor{root}
+	for len(level) > 0 {
+		var mu sync.Mutex // protects next
+		var next []ocispec.Descriptor
+		eg, egCtx := syncutil.LimitGroup(ctx, concurrency)
+		for _, node := range level {
+			share := estimate.MediaTypes[node.MediaType]
+			share.Count++
+			share.Size += node.Size
+			estimate.MediaTypes[node.MediaType] = share
+			estimate.Size += node.Size
+			if !descriptor.IsManifest(node) {
+				estimate.BlobCount++
+				continue
+			}
+			estimate.ManifestCount++
+
+			node := node
+			eg.Go(func() error {
+				successors, err := findSuccessors(egCtx, src, node)
+				if err != nil {
+					return err
+				}
+				successors = removeForeignLayers(egCtx, successors)
+				mu.Lock()
+				defer mu.Unlock()
+				next = append(next, successors...)
+				return nil
+			})
+		}
+		if err := eg.Wait(); err != nil {
+			return nil, err
+		}
+
+		level = level[:0]
+		for _, node := range next {
+			key := descriptor.FromOCI(node)
+			if visited.Contains(key) {
+				continue
+			}
+			visited.Add(key)
+			level = append(level, node)
+		}
+	}
+	return estimate, nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/internal/descriptor"
+)
+
+// fetchCountingStorage counts the fetches of manifests and blobs.
+type fetchCountingStorage struct {
+	content.ReadOnlyStorage
+	manifests atomic.Int64
+	blobs     atomic.Int64
+}
+
+func (s *fetchCountingStorage) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	if descriptor.IsManifest(target) {
+		s.manifests.Add(1)
+	} else {
+		s.blobs.Add(1)
+	}
+	return s.ReadOnlyStorage.Fetch(ctx, target)
+}
+
+func TestEstimate(t *testing.T) {
+	ctx := context.Background()
+	config := []byte(`{"architecture":"amd64","os":"linux"}`)
+	layer := []byte("hello world")
+	s, manifest := pushImage(t, config, layer, "latest")
+	// the manifest is listed twice but counted once
+	index := pushIndex(t, s, manifest, manifest)
+
+	src := &fetchCountingStorage{ReadOnlyStorage: s}
+	got, err := Estimate(ctx, src, index, EstimateOptions{})
+	if err != nil {
+		t.Fatal("Estimate() error =", err)
+	}
+	want := &GraphEstimate{
+		Root:          index,
+		Size:          index.Size + manifest.Size + int64(len(config)) + int64(len(layer)),
+		ManifestCount: 2,
+		BlobCount:     2,
+		MediaTypes: map[string]MediaTypeEstimate{
+			ocispec.MediaTypeImageIndex:    {Count: 1, Size: index.Size},
+			ocispec.MediaTypeImageManifest: {Count: 1, Size: manifest.Size},
+			ocispec.MediaTypeImageConfig:   {Count: 1, Size: int64(len(config))},
+			ocispec.MediaTypeImageLayer:    {Count: 1, Size: int64(len(layer))},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Estimate() = %+v, want %+v", got, want)
+	}
+	if n := src.manifests.Load(); n != 2 {
+		t.Errorf("manifest fetches = %d, want 2", n)
+	}
+	if n := src.blobs.Load(); n != 0 {
+		t.Errorf("blob fetches = %d, want 0", n)
+	}
+}
+
+func TestEstimate_foreignLayer(t *testing.T) {
+	ctx := context.Background()
+	_, manifest := pushImage(t, []byte("{}"), []byte("foo"), "latest")
+	foreign := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageLayerNonDistributable,
+		Digest:    "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		Size:      1024,
+	}
+	findSuccessors := func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		return []ocispec.Descriptor{foreign}, nil
+	}
+	got, err := Estimate(ctx, nil, manifest, EstimateOptions{FindSuccessors: findSuccessors})
+	if err != nil {
+		t.Fatal("Estimate() error =", err)
+	}
+	if got.Size != manifest.Size || got.BlobCount != 0 {
+		t.Errorf("Estimate() = %+v, want foreign layer excluded", got)
+	}
+}
+
+func TestEstimate_error(t *testing.T) {
+	ctx := context.Background()
+	_, manifest := pushImage(t, []byte("{}"), []byte("foo"), "latest")
+	errFind := errors.New("find failed")
+	findSuccessors := func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		return nil, errFind
+	}
+	if _, err := Estimate(ctx, nil, manifest, EstimateOptions{FindSuccessors: findSuccessors}); !errors.Is(err, errFind) {
+		t.Errorf("Estimate() error = %v, want %v", err, errFind)
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+)
+
+const (
+	// defaultExistencePositiveTTL is the default value of
+	// ExistenceCache.PositiveTTL.
+	defaultExistencePositiveTTL = time.Minute
+
+	// defaultExistenceNegativeTTL is the default value of
+	// ExistenceCache.NegativeTTL.
+	defaultExistenceNegativeTTL = 5 * time.Second
+)
+
+// ExistenceCache caches the recent results of the existence checks of the
+// content in a destination, so that copying many overlapping graphs to the
+// same destination in quick succession skips the redundant checks, such as
+// HEAD requests to a remote registry.
+//
+// Content successfully copied to the destination is cached as existing.
+// Since the content is keyed by its descriptor, an ExistenceCache must be
+// shared only by the copies to the same destination.
+//
+// An ExistenceCache is safe for concurrent use.
+type ExistenceCache struct {
+	// PositiveTTL is the duration the existing content is cached for.
+	// If less than or equal to zero, a default (currently 1 minute) is used.
+	PositiveTTL time.Duration
+
+	// NegativeTTL is the duration the non-existing content is cached for.
+	// It should be short, since the content may be pushed to the destination
+	// by others at any time.
+	// If less than or equal to zero, a default (currently 5 seconds) is used.
+	NegativeTTL time.Duration
+
+	lock    sync.Mutex
+	entries map[descriptor.Descriptor]existenceEntry
+}
+
+// existenceEntry is a cached existence check result.
+type existenceEntry struct {
+	exists  bool
+	expires time.Time
+}
+
+// NewExistenceCache creates a new ExistenceCache with the default TTLs.
+func NewExistenceCache() *ExistenceCache {
+	return &ExistenceCache{
+		entries: make(map[descriptor.Descriptor]existenceEntry),
+	}
+}
+
+// Purge removes all the cached results.
+func (c *ExistenceCache) Purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.entries = make(map[descriptor.Descriptor]existenceEntry)
+}
+
+// load returns the cached result of the content if not expired.
+func (c *ExistenceCache) load(desc ocispec.Descriptor) (exists bool, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	key := descriptor.FromOCI(desc)
+	entry, ok := c.entries[key]
+	if !ok {
+		return false, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return false, false
+	}
+	return entry.exists, true
+}
+
+// store caches the result of the content.
+func (c *ExistenceCache) store(desc ocispec.Descriptor, exists bool) {
+	ttl := c.NegativeTTL
+	if ttl <= 0 {
+		ttl = defaultExistenceNegativeTTL
+	}
+	if exists {
+		ttl = c.PositiveTTL
+		if ttl <= 0 {
+			ttl = defaultExistencePositiveTTL
+		}
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[descriptor.Descriptor]existenceEntry)
+	}
+	c.entries[descriptor.FromOCI(desc)] = existenceEntry{
+		exists:  exists,
+		expires: time.Now().Add(ttl),
+	}
+}
+
+// existenceCachedStorage is a storage whose existence checks are cached by
+// an ExistenceCache.
+type existenceCachedStorage struct {
+	content.Storage
+	cache *ExistenceCache
+}
+
+// Exists returns the cached result if any, and checks the existence in the
+// storage otherwise.
+func (s *existenceCachedStorage) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	if exists, ok := s.cache.load(target); ok {
+		return exists, nil
+	}
+	exists, err := s.Storage.Exists(ctx, target)
+	if err != nil {
+		return false, err
+	}
+	s.cache.store(target, exists)
+	return exists, nil
+}
+
+// Push pushes the content to the storage, and caches it as existing on
+// success.
+func (s *existenceCachedStorage) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	err := s.Storage.Push(ctx, expected, content)
+	if err == nil || errors.Is(err, errdef.ErrAlreadyExists) {
+		s.cache.store(expected, true)
+	}
+	return err
+}
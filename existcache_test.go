@@ -0,0 +1,134 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/orastest"
+)
+
+func TestCopyGraph_ExistenceCache(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatalf("failed to push %s: %v", mediaType, err)
+		}
+		return desc
+	}
+	shared := push(ocispec.MediaTypeImageLayer, []byte("shared layer"))
+	layerA := push(ocispec.MediaTypeImageLayer, []byte("layer A"))
+	layerB := push(ocispec.MediaTypeImageLayer, []byte("layer B"))
+	manifestA, err := oras.Pack(ctx, src, "", []ocispec.Descriptor{shared, layerA}, oras.PackOptions{PackImageManifest: true})
+	if err != nil {
+		t.Fatalf("failed to pack manifest: %v", err)
+	}
+	manifestB, err := oras.Pack(ctx, src, "", []ocispec.Descriptor{shared, layerB}, oras.PackOptions{PackImageManifest: true})
+	if err != nil {
+		t.Fatalf("failed to pack manifest: %v", err)
+	}
+
+	dst := orastest.NewTarget()
+	cache := oras.NewExistenceCache()
+	opts := oras.CopyGraphOptions{ExistenceCache: cache}
+	countExists := func() int {
+		n := len(dst.CallsOf(orastest.MethodExists))
+		dst.Reset()
+		return n
+	}
+
+	// manifest, config, shared layer and layer A are checked
+	if err := oras.CopyGraph(ctx, src, dst, manifestA, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v", err)
+	}
+	if got, want := countExists(), 4; got != want {
+		t.Errorf("Exists() calls = %d, want %d", got, want)
+	}
+
+	// the config and the shared layer are cached as existing after the copy
+	if err := oras.CopyGraph(ctx, src, dst, manifestB, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v", err)
+	}
+	if got, want := countExists(), 2; got != want {
+		t.Errorf("Exists() calls = %d, want %d", got, want)
+	}
+
+	// copying again costs no existence check
+	if err := oras.CopyGraph(ctx, src, dst, manifestA, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v", err)
+	}
+	if got := countExists(); got != 0 {
+		t.Errorf("Exists() calls = %d, want 0", got)
+	}
+	if got := len(dst.CallsOf(orastest.MethodPush)); got != 0 {
+		t.Errorf("Push() calls = %d, want 0", got)
+	}
+
+	// purged results are checked again
+	cache.Purge()
+	if err := oras.CopyGraph(ctx, src, dst, manifestA, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v", err)
+	}
+	if got, want := countExists(), 1; got != want {
+		t.Errorf("Exists() calls = %d, want %d", got, want)
+	}
+}
+
+func TestCopyGraph_ExistenceCacheExpiry(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	blob := []byte("hello")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatalf("failed to push: %v", err)
+	}
+
+	dst := orastest.NewTarget()
+	cache := &oras.ExistenceCache{
+		PositiveTTL: 50 * time.Millisecond,
+	}
+	opts := oras.CopyGraphOptions{ExistenceCache: cache}
+	for i := 0; i < 2; i++ {
+		if err := oras.CopyGraph(ctx, src, dst, desc, opts); err != nil {
+			t.Fatalf("CopyGraph() error = %v", err)
+		}
+	}
+	if got := len(dst.CallsOf(orastest.MethodExists)); got != 1 {
+		t.Errorf("Exists() calls = %d, want 1", got)
+	}
+
+	// the expired result is checked again
+	time.Sleep(60 * time.Millisecond)
+	dst.Reset()
+	if err := oras.CopyGraph(ctx, src, dst, desc, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v", err)
+	}
+	if got := len(dst.CallsOf(orastest.MethodExists)); got != 1 {
+		t.Errorf("Exists() calls = %d, want 1", got)
+	}
+	if got := len(dst.CallsOf(orastest.MethodPush)); got != 0 {
+		t.Errorf("Push() calls = %d, want 0", got)
+	}
+}
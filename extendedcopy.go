@@ -22,7 +22,6 @@ import (
 	"regexp"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
-	"golang.org/x/sync/semaphore"
 	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/internal/cas"
 	"oras.land/oras-go/v2/internal/container/set"
@@ -104,11 +103,9 @@ func ExtendedCopyGraph(ctx context.Context, src content.ReadOnlyGraphStorage, ds
 		return err
 	}
 
-	// if Concurrency is not set or invalid, use the default concurrency
-	if opts.Concurrency <= 0 {
-		opts.Concurrency = defaultConcurrency
-	}
-	limiter := semaphore.NewWeighted(int64(opts.Concurrency))
+	scheduler := newScheduler(ctx, opts.CopyGraphOptions)
+	// throttle the copies of all the roots together
+	throttled := throttleSource(src, &opts.CopyGraphOptions)
 	// use caching proxy on non-leaf nodes
 	if opts.MaxMetadataBytes <= 0 {
 		opts.MaxMetadataBytes = defaultCopyMaxMetadataBytes
@@ -118,16 +115,16 @@ func ExtendedCopyGraph(ctx context.Context, src content.ReadOnlyGraphStorage, ds
 	tracker := status.NewTracker()
 
 	// copy the sub-DAGs rooted by the root nodes
-	return syncutil.Go(ctx, limiter, func(ctx context.Context, region *syncutil.LimitedRegion, root ocispec.Descriptor) error {
+	return syncutil.GoLimited[ocispec.Descriptor](ctx, scheduler, func(ctx context.Context, region *syncutil.LimitedRegion, root ocispec.Descriptor) error {
 		// As a root can be a predecessor of other roots, release the limit here
 		// for dispatching, to avoid dead locks where predecessor roots are
 		// handled first and are waiting for its successors to complete.
 		region.End()
-		if err := copyGraph(ctx, src, dst, root, proxy, limiter, tracker, opts.CopyGraphOptions); err != nil {
+		if err := copyGraph(ctx, throttled, dst, root, proxy, scheduler, tracker, opts.CopyGraphOptions); err != nil {
 			return err
 		}
 		return region.Start()
-	}, roots...)
+	}, scheduler.Submit(ctx, roots)...)
 }
 
 // findRoots finds the root nodes reachable from the given node through a
@@ -0,0 +1,72 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// ExcludeMediaTypes returns a NodeFilter of CopyGraphOptions excluding the
+// nodes of the given media types, such as the media types of provenance or
+// cache layers, with their sub-DAGs.
+func ExcludeMediaTypes(mediaTypes ...string) func(desc ocispec.Descriptor) bool {
+	excluded := make(map[string]struct{}, len(mediaTypes))
+	for _, mediaType := range mediaTypes {
+		excluded[mediaType] = struct{}{}
+	}
+	return func(desc ocispec.Descriptor) bool {
+		_, ok := excluded[desc.MediaType]
+		return !ok
+	}
+}
+
+// ExcludeArtifactTypes returns a NodeFilter of CopyGraphOptions excluding the
+// nodes of the given artifact types, such as signatures or SBOMs, with their
+// sub-DAGs. Only the artifact types present in the descriptors are matched.
+func ExcludeArtifactTypes(artifactTypes ...string) func(desc ocispec.Descriptor) bool {
+	excluded := make(map[string]struct{}, len(artifactTypes))
+	for _, artifactType := range artifactTypes {
+		excluded[artifactType] = struct{}{}
+	}
+	return func(desc ocispec.Descriptor) bool {
+		if desc.ArtifactType == "" {
+			return true
+		}
+		_, ok := excluded[desc.ArtifactType]
+		return !ok
+	}
+}
+
+// filterSuccessors returns a FindSuccessors function returning only the
+// successors accepted by filter.
+func filterSuccessors(findSuccessors func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error), filter func(desc ocispec.Descriptor) bool) func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	return func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		successors, err := findSuccessors(ctx, fetcher, desc)
+		if err != nil {
+			return nil, err
+		}
+		var filtered []ocispec.Descriptor
+		for _, node := range successors {
+			if filter(node) {
+				filtered = append(filtered, node)
+			}
+		}
+		return filtered, nil
+	}
+}
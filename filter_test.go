@@ -0,0 +1,113 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestCopyGraph_NodeFilter(t *testing.T) {
+	ctx := context.Background()
+	config := []byte("{}")
+	layer := []byte("hello world")
+	src, manifest := pushImage(t, config, layer, "latest")
+	configDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, config)
+	layerDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+	index := pushIndex(t, src, manifest)
+
+	tests := []struct {
+		name       string
+		root       ocispec.Descriptor
+		nodeFilter func(desc ocispec.Descriptor) bool
+		want       []ocispec.Descriptor
+		notWant    []ocispec.Descriptor
+	}{
+		{
+			name:       "exclude layers",
+			root:       index,
+			nodeFilter: ExcludeMediaTypes(ocispec.MediaTypeImageLayer),
+			want:       []ocispec.Descriptor{index, manifest, configDesc},
+			notWant:    []ocispec.Descriptor{layerDesc},
+		},
+		{
+			name: "exclude sub-DAG",
+			root: index,
+			nodeFilter: func(desc ocispec.Descriptor) bool {
+				return desc.Digest != manifest.Digest
+			},
+			want:    []ocispec.Descriptor{index},
+			notWant: []ocispec.Descriptor{manifest, configDesc, layerDesc},
+		},
+		{
+			name:       "root always copied",
+			root:       manifest,
+			nodeFilter: ExcludeMediaTypes(ocispec.MediaTypeImageManifest, ocispec.MediaTypeImageConfig),
+			want:       []ocispec.Descriptor{manifest, layerDesc},
+			notWant:    []ocispec.Descriptor{configDesc},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := memory.New()
+			opts := DefaultCopyGraphOptions
+			opts.NodeFilter = tt.nodeFilter
+			if err := CopyGraph(ctx, src, dst, tt.root, opts); err != nil {
+				t.Fatal("CopyGraph() error =", err)
+			}
+			for _, desc := range tt.want {
+				if exists, err := dst.Exists(ctx, desc); err != nil || !exists {
+					t.Errorf("Store.Exists(%s) = %v, %v, want true", desc.Digest, exists, err)
+				}
+			}
+			for _, desc := range tt.notWant {
+				if exists, err := dst.Exists(ctx, desc); err != nil || exists {
+					t.Errorf("Store.Exists(%s) = %v, %v, want false", desc.Digest, exists, err)
+				}
+			}
+
+			// Plan agrees with the copy
+			plan, err := Plan(ctx, src, memory.New(), tt.root, opts)
+			if err != nil {
+				t.Fatal("Plan() error =", err)
+			}
+			if len(plan.Copy) != len(tt.want) {
+				t.Errorf("CopyPlan.Copy = %v, want %v", plan.Copy, tt.want)
+			}
+		})
+	}
+}
+
+func TestExcludeArtifactTypes(t *testing.T) {
+	filter := ExcludeArtifactTypes("application/vnd.example.sbom")
+	tests := []struct {
+		desc ocispec.Descriptor
+		want bool
+	}{
+		{ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest}, true},
+		{ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: "application/vnd.example.signature"}, true},
+		{ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: "application/vnd.example.sbom"}, false},
+	}
+	for _, tt := range tests {
+		if got := filter(tt.desc); got != tt.want {
+			t.Errorf("ExcludeArtifactTypes()(%q) = %v, want %v", tt.desc.ArtifactType, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fips restricts ORAS to FIPS-approved algorithms, for deployments in
+// FIPS 140 regulated environments.
+//
+// Enable restricts the accepted digest algorithms, and the TLS helpers build
+// and validate HTTP clients negotiating FIPS-approved protocol versions,
+// cipher suites and curves only.
+// Note that this package restricts algorithm choices but does not make the
+// underlying cryptographic module FIPS validated; a FIPS validated Go
+// toolchain is still required for compliance.
+package fips
+
+import (
+	"fmt"
+	"net/http"
+
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/retry"
+)
+
+// Enable enables the FIPS mode, rejecting digests of algorithms other than
+// sha256, sha384 and sha512 with a *content.UnapprovedAlgorithmError.
+// Enable is expected to be called during initialization.
+func Enable() {
+	content.SetFIPSMode(true)
+}
+
+// Enabled returns true if the FIPS mode is enabled.
+func Enabled() bool {
+	return content.FIPSMode()
+}
+
+// ConfigError is returned when an HTTP client or a TLS configuration is not
+// compliant with the FIPS mode.
+// It matches errdef.ErrUnsupported by errors.Is.
+type ConfigError struct {
+	// Setting is the non-compliant setting.
+	Setting string
+	// Reason describes why the setting is not compliant.
+	Reason string
+}
+
+// Error returns the error message.
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%s: %s: not compliant with FIPS mode", e.Setting, e.Reason)
+}
+
+// Unwrap returns errdef.ErrUnsupported so that the error can be checked with
+// errors.Is.
+func (e *ConfigError) Unwrap() error {
+	return errdef.ErrUnsupported
+}
+
+// NewClient returns an auth-decorated client with the default retry policy,
+// whose transport only negotiates FIPS-approved TLS parameters.
+func NewClient() *auth.Client {
+	return &auth.Client{
+		Client: &http.Client{
+			Transport: retry.NewTransport(NewTransport()),
+		},
+		Header: http.Header{
+			"User-Agent": {"oras-go"},
+		},
+		Cache: auth.NewCache(),
+	}
+}
+
+// NewTransport returns a clone of http.DefaultTransport configured with
+// TLSConfig.
+func NewTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = TLSConfig()
+	return transport
+}
+
+// ValidateClient checks that the HTTP client only negotiates FIPS-approved
+// TLS parameters, returning a *ConfigError otherwise.
+// The clients and transports of this module and the standard library are
+// inspected through their layers; any other implementation cannot be
+// inspected and is reported as non-compliant.
+func ValidateClient(client remote.Client) error {
+	switch c := client.(type) {
+	case nil:
+		return ValidateTransport(nil)
+	case *auth.Client:
+		if c.Client == nil {
+			return ValidateClient(http.DefaultClient)
+		}
+		return ValidateClient(c.Client)
+	case *http.Client:
+		return ValidateTransport(c.Transport)
+	default:
+		return &ConfigError{
+			Setting: fmt.Sprintf("client %T", client),
+			Reason:  "unknown client cannot be inspected",
+		}
+	}
+}
+
+// ValidateTransport checks that the HTTP transport only negotiates
+// FIPS-approved TLS parameters, returning a *ConfigError otherwise.
+// A nil transport stands for http.DefaultTransport.
+func ValidateTransport(rt http.RoundTripper) error {
+	switch t := rt.(type) {
+	case nil:
+		return ValidateTransport(http.DefaultTransport)
+	case *retry.Transport:
+		return ValidateTransport(t.Base)
+	case *http.Transport:
+		if t.DialTLSContext != nil || t.DialTLS != nil {
+			return &ConfigError{
+				Setting: "Transport.DialTLSContext",
+				Reason:  "custom TLS dialer cannot be inspected",
+			}
+		}
+		return ValidateTLSConfig(t.TLSClientConfig)
+	default:
+		return &ConfigError{
+			Setting: fmt.Sprintf("transport %T", rt),
+			Reason:  "unknown transport cannot be inspected",
+		}
+	}
+}
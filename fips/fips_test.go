@@ -0,0 +1,144 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fips
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/retry"
+)
+
+func TestEnable(t *testing.T) {
+	Enable()
+	t.Cleanup(func() {
+		content.SetFIPSMode(false)
+	})
+	if !Enabled() {
+		t.Fatal("Enabled() = false, want true")
+	}
+	if err := content.ValidateDigest(digest.FromString("foo")); err != nil {
+		t.Errorf("content.ValidateDigest() error = %v, want nil", err)
+	}
+	if !content.AlgorithmAvailable(digest.SHA256) {
+		t.Error("content.AlgorithmAvailable(sha256) = false, want true")
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	client := NewClient()
+	if err := ValidateClient(client); err != nil {
+		t.Errorf("ValidateClient(NewClient()) error = %v", err)
+	}
+	if err := ValidateTransport(NewTransport()); err != nil {
+		t.Errorf("ValidateTransport(NewTransport()) error = %v", err)
+	}
+}
+
+type customClient struct{}
+
+func (customClient) Do(*http.Request) (*http.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+type customTransport struct{}
+
+func (customTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestValidateClient(t *testing.T) {
+	dialer := NewTransport()
+	dialer.DialTLSContext = func(context.Context, string, string) (net.Conn, error) {
+		return nil, errors.New("not implemented")
+	}
+	tests := []struct {
+		name    string
+		client  remote.Client
+		wantErr bool
+	}{
+		{
+			name:    "nil client",
+			wantErr: true,
+		},
+		{
+			name:    "default auth client",
+			client:  auth.DefaultClient,
+			wantErr: true,
+		},
+		{
+			name:    "zero auth client",
+			client:  &auth.Client{},
+			wantErr: true,
+		},
+		{
+			name:    "retry client",
+			client:  retry.NewClient(),
+			wantErr: true,
+		},
+		{
+			name:   "compliant http client",
+			client: &http.Client{Transport: NewTransport()},
+		},
+		{
+			name: "compliant auth client with retry",
+			client: &auth.Client{
+				Client: &http.Client{Transport: retry.NewTransport(NewTransport())},
+			},
+		},
+		{
+			name:    "custom TLS dialer",
+			client:  &http.Client{Transport: dialer},
+			wantErr: true,
+		},
+		{
+			name:    "custom transport",
+			client:  &http.Client{Transport: customTransport{}},
+			wantErr: true,
+		},
+		{
+			name:    "custom client",
+			client:  customClient{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateClient(tt.client)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			var configErr *ConfigError
+			if !errors.As(err, &configErr) {
+				t.Errorf("ValidateClient() error = %v, want %T", err, configErr)
+			}
+			if !errors.Is(err, errdef.ErrUnsupported) {
+				t.Errorf("ValidateClient() error = %v, want %v", err, errdef.ErrUnsupported)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fips
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+// approvedCipherSuites lists the FIPS-approved TLS 1.2 cipher suites, in
+// order of preference.
+var approvedCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+}
+
+// approvedCurves lists the FIPS-approved elliptic curves, in order of
+// preference.
+var approvedCurves = []tls.CurveID{
+	tls.CurveP256,
+	tls.CurveP384,
+	tls.CurveP521,
+}
+
+// TLSConfig returns a TLS client configuration restricted to FIPS-approved
+// cipher suites and curves.
+// The maximum version is TLS 1.2 since the TLS 1.3 cipher suites, which
+// include ChaCha20-Poly1305, are not configurable in crypto/tls.
+func TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:       tls.VersionTLS12,
+		MaxVersion:       tls.VersionTLS12,
+		CipherSuites:     append([]uint16(nil), approvedCipherSuites...),
+		CurvePreferences: append([]tls.CurveID(nil), approvedCurves...),
+	}
+}
+
+// ValidateTLSConfig checks that the TLS client configuration only negotiates
+// FIPS-approved parameters, returning a *ConfigError otherwise.
+// A nil configuration stands for the crypto/tls defaults, which are not
+// compliant.
+func ValidateTLSConfig(cfg *tls.Config) error {
+	if cfg == nil {
+		return &ConfigError{
+			Setting: "TLSClientConfig",
+			Reason:  "default configuration allows unapproved cipher suites",
+		}
+	}
+	if cfg.InsecureSkipVerify {
+		return &ConfigError{
+			Setting: "InsecureSkipVerify",
+			Reason:  "server certificate verification is disabled",
+		}
+	}
+	if cfg.MinVersion < tls.VersionTLS12 {
+		return &ConfigError{
+			Setting: "MinVersion",
+			Reason:  "versions prior to TLS 1.2 are allowed",
+		}
+	}
+	if cfg.MaxVersion == 0 || cfg.MaxVersion > tls.VersionTLS12 {
+		return &ConfigError{
+			Setting: "MaxVersion",
+			Reason:  "TLS 1.3 cipher suites cannot be restricted",
+		}
+	}
+	if len(cfg.CipherSuites) == 0 {
+		return &ConfigError{
+			Setting: "CipherSuites",
+			Reason:  "default cipher suites include unapproved ones",
+		}
+	}
+	for _, id := range cfg.CipherSuites {
+		if !containsCipherSuite(id) {
+			return &ConfigError{
+				Setting: "CipherSuites",
+				Reason:  fmt.Sprintf("cipher suite %s is not approved", tls.CipherSuiteName(id)),
+			}
+		}
+	}
+	if len(cfg.CurvePreferences) == 0 {
+		return &ConfigError{
+			Setting: "CurvePreferences",
+			Reason:  "default curves include unapproved ones",
+		}
+	}
+	for _, id := range cfg.CurvePreferences {
+		if !containsCurve(id) {
+			return &ConfigError{
+				Setting: "CurvePreferences",
+				Reason:  fmt.Sprintf("curve %s is not approved", id),
+			}
+		}
+	}
+	return nil
+}
+
+// containsCipherSuite returns true if the cipher suite is approved.
+func containsCipherSuite(id uint16) bool {
+	for _, approved := range approvedCipherSuites {
+		if id == approved {
+			return true
+		}
+	}
+	return false
+}
+
+// containsCurve returns true if the curve is approved.
+func containsCurve(id tls.CurveID) bool {
+	for _, approved := range approvedCurves {
+		if id == approved {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fips
+
+import (
+	"crypto/tls"
+	"errors"
+	"testing"
+)
+
+func TestTLSConfig(t *testing.T) {
+	cfg := TLSConfig()
+	if err := ValidateTLSConfig(cfg); err != nil {
+		t.Fatalf("ValidateTLSConfig(TLSConfig()) error = %v", err)
+	}
+
+	// returned configurations are independent
+	cfg.CipherSuites[0] = tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305
+	if err := ValidateTLSConfig(TLSConfig()); err != nil {
+		t.Errorf("ValidateTLSConfig(TLSConfig()) error = %v", err)
+	}
+}
+
+func TestValidateTLSConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(cfg *tls.Config) *tls.Config
+		wantSetting string
+	}{
+		{
+			name: "nil config",
+			modify: func(cfg *tls.Config) *tls.Config {
+				return nil
+			},
+			wantSetting: "TLSClientConfig",
+		},
+		{
+			name: "insecure skip verify",
+			modify: func(cfg *tls.Config) *tls.Config {
+				cfg.InsecureSkipVerify = true
+				return cfg
+			},
+			wantSetting: "InsecureSkipVerify",
+		},
+		{
+			name: "default min version",
+			modify: func(cfg *tls.Config) *tls.Config {
+				cfg.MinVersion = 0
+				return cfg
+			},
+			wantSetting: "MinVersion",
+		},
+		{
+			name: "TLS 1.1",
+			modify: func(cfg *tls.Config) *tls.Config {
+				cfg.MinVersion = tls.VersionTLS11
+				return cfg
+			},
+			wantSetting: "MinVersion",
+		},
+		{
+			name: "TLS 1.3",
+			modify: func(cfg *tls.Config) *tls.Config {
+				cfg.MaxVersion = tls.VersionTLS13
+				return cfg
+			},
+			wantSetting: "MaxVersion",
+		},
+		{
+			name: "default cipher suites",
+			modify: func(cfg *tls.Config) *tls.Config {
+				cfg.CipherSuites = nil
+				return cfg
+			},
+			wantSetting: "CipherSuites",
+		},
+		{
+			name: "unapproved cipher suite",
+			modify: func(cfg *tls.Config) *tls.Config {
+				cfg.CipherSuites = append(cfg.CipherSuites, tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305)
+				return cfg
+			},
+			wantSetting: "CipherSuites",
+		},
+		{
+			name: "default curves",
+			modify: func(cfg *tls.Config) *tls.Config {
+				cfg.CurvePreferences = nil
+				return cfg
+			},
+			wantSetting: "CurvePreferences",
+		},
+		{
+			name: "unapproved curve",
+			modify: func(cfg *tls.Config) *tls.Config {
+				cfg.CurvePreferences = append(cfg.CurvePreferences, tls.X25519)
+				return cfg
+			},
+			wantSetting: "CurvePreferences",
+		},
+		{
+			name: "subset of approved parameters",
+			modify: func(cfg *tls.Config) *tls.Config {
+				cfg.CipherSuites = cfg.CipherSuites[:1]
+				cfg.CurvePreferences = cfg.CurvePreferences[1:2]
+				return cfg
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTLSConfig(tt.modify(TLSConfig()))
+			if tt.wantSetting == "" {
+				if err != nil {
+					t.Errorf("ValidateTLSConfig() error = %v, want nil", err)
+				}
+				return
+			}
+			var configErr *ConfigError
+			if !errors.As(err, &configErr) {
+				t.Fatalf("ValidateTLSConfig() error = %v, want %T", err, configErr)
+			}
+			if configErr.Setting != tt.wantSetting {
+				t.Errorf("ConfigError.Setting = %v, want %v", configErr.Setting, tt.wantSetting)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+)
+
+// ForeignLayerPolicy controls how the foreign layers, such as
+// "application/vnd.docker.image.rootfs.foreign.diff.tar.gzip", and the
+// non-distributable OCI layers are handled by a copy.
+type ForeignLayerPolicy int
+
+// Foreign layer policies.
+const (
+	// ForeignLayerPolicySkip skips the foreign layers, which are left to be
+	// fetched from their original locations by the consumers.
+	ForeignLayerPolicySkip ForeignLayerPolicy = iota
+	// ForeignLayerPolicyCopy copies the foreign layers like the other
+	// layers. The foreign layers are fetched from the source, instead of
+	// the URLs of their descriptors, and the copy fails if they are not
+	// available in the source.
+	ForeignLayerPolicyCopy
+	// ForeignLayerPolicyError fails the copy with a *ForeignLayerError when
+	// a foreign layer is found in the graph.
+	ForeignLayerPolicyError
+)
+
+// String returns the name of the policy.
+func (p ForeignLayerPolicy) String() string {
+	switch p {
+	case ForeignLayerPolicySkip:
+		return "Skip"
+	case ForeignLayerPolicyCopy:
+		return "Copy"
+	case ForeignLayerPolicyError:
+		return "Error"
+	default:
+		return "Unknown"
+	}
+}
+
+// ForeignLayerError is returned when a foreign layer is found in the graph
+// being copied with ForeignLayerPolicyError.
+type ForeignLayerError struct {
+	// Descriptor describes the foreign layer.
+	Descriptor ocispec.Descriptor
+}
+
+// Error returns the error message.
+func (e *ForeignLayerError) Error() string {
+	return fmt.Sprintf("%s: %s: foreign layer refused by policy", e.Descriptor.Digest, e.Descriptor.MediaType)
+}
+
+// Unwrap returns errdef.ErrUnsupported so that the error can be checked with
+// errors.Is.
+func (e *ForeignLayerError) Unwrap() error {
+	return errdef.ErrUnsupported
+}
+
+// applyForeignLayerPolicy handles the foreign layers in the given successors
+// according to policy, and returns the successors to be copied.
+func applyForeignLayerPolicy(ctx context.Context, successors []ocispec.Descriptor, policy ForeignLayerPolicy) ([]ocispec.Descriptor, error) {
+	switch policy {
+	case ForeignLayerPolicyCopy:
+		return successors, nil
+	case ForeignLayerPolicyError:
+		for _, desc := range successors {
+			if descriptor.IsForeignLayer(desc) {
+				return nil, &ForeignLayerError{Descriptor: desc}
+			}
+		}
+		return successors, nil
+	default:
+		return removeForeignLayers(ctx, successors), nil
+	}
+}
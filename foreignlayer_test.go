@@ -0,0 +1,124 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestCopyGraph_ForeignLayerPolicy(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal("Store.Push() error =", err)
+		}
+		return desc
+	}
+	configDesc := push(ocispec.MediaTypeImageConfig, []byte("{}"))
+	foreignDesc := push(ocispec.MediaTypeImageLayerNonDistributableGzip, []byte("foreign"))
+	layerDesc := push(ocispec.MediaTypeImageLayer, []byte("layer"))
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    configDesc,
+		Layers:    []ocispec.Descriptor{foreignDesc, layerDesc},
+	})
+	if err != nil {
+		t.Fatal("json.Marshal() error =", err)
+	}
+	manifestDesc := push(ocispec.MediaTypeImageManifest, manifestJSON)
+
+	tests := []struct {
+		name        string
+		policy      ForeignLayerPolicy
+		wantForeign bool
+		wantErr     bool
+	}{
+		{
+			name:   "skip",
+			policy: ForeignLayerPolicySkip,
+		},
+		{
+			name:        "copy",
+			policy:      ForeignLayerPolicyCopy,
+			wantForeign: true,
+		},
+		{
+			name:    "error",
+			policy:  ForeignLayerPolicyError,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := memory.New()
+			opts := CopyGraphOptions{
+				ForeignLayerPolicy: tt.policy,
+			}
+			err := CopyGraph(ctx, src, dst, manifestDesc, opts)
+			if tt.wantErr {
+				var foreignErr *ForeignLayerError
+				if !errors.As(err, &foreignErr) {
+					t.Fatalf("CopyGraph() error = %v, want ForeignLayerError", err)
+				}
+				if foreignErr.Descriptor.Digest != foreignDesc.Digest {
+					t.Errorf("ForeignLayerError.Descriptor = %v, want %v", foreignErr.Descriptor.Digest, foreignDesc.Digest)
+				}
+				if !errors.Is(err, errdef.ErrUnsupported) {
+					t.Errorf("CopyGraph() error = %v, want %v", err, errdef.ErrUnsupported)
+				}
+				if exists, err := dst.Exists(ctx, manifestDesc); err != nil || exists {
+					t.Errorf("Store.Exists(manifest) = %v, %v, want false", exists, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("CopyGraph() error =", err)
+			}
+			if exists, err := dst.Exists(ctx, layerDesc); err != nil || !exists {
+				t.Errorf("Store.Exists(layer) = %v, %v, want true", exists, err)
+			}
+			if exists, err := dst.Exists(ctx, foreignDesc); err != nil || exists != tt.wantForeign {
+				t.Errorf("Store.Exists(foreign layer) = %v, %v, want %v", exists, err, tt.wantForeign)
+			}
+
+			// Plan agrees with the copy
+			plan, err := Plan(ctx, src, memory.New(), manifestDesc, opts)
+			if err != nil {
+				t.Fatal("Plan() error =", err)
+			}
+			wantCopy := 3
+			if tt.wantForeign {
+				wantCopy++
+			}
+			if len(plan.Copy) != wantCopy {
+				t.Errorf("len(CopyPlan.Copy) = %d, want %d", len(plan.Copy), wantCopy)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/registry"
+)
+
+// DefaultGarbageCollectOptions provides the default GarbageCollectOptions.
+var DefaultGarbageCollectOptions GarbageCollectOptions
+
+// GarbageCollectOptions contains parameters for [oras.GarbageCollect].
+type GarbageCollectOptions struct {
+	// DryRun, if true, only reports the unreachable content which would be
+	// deleted without modifying the target. PreDelete and PostDelete are not
+	// called in the dry-run mode.
+	DryRun bool
+	// PreDelete handles the current descriptor before deleting it.
+	// If PreDelete returns an error, the garbage collection is aborted.
+	PreDelete func(ctx context.Context, desc ocispec.Descriptor) error
+	// PostDelete handles the current descriptor after deleting it.
+	PostDelete func(ctx context.Context, desc ocispec.Descriptor) error
+}
+
+// GarbageCollect deletes the content in the target which is not reachable
+// from the given root references, and returns the deleted descriptors.
+//
+// The content reachable from a root reference includes the root node, and
+// recursively its successors and its referrers.
+// The content in the target is discovered by walking the graph, through both
+// successors and predecessors, from all the tags listed by the target.
+// Content not connected to any tagged node is not discovered, and therefore
+// not deleted.
+//
+// Predecessors are deleted before their successors so that the target does
+// not hold dangling references if the garbage collection is interrupted.
+//
+// If opts.DryRun is true, the descriptors which would be deleted are returned
+// in the deletion order without modifying the target, so that they can be
+// reviewed before the destructive cleanup. The number of bytes to be freed is
+// given by content.TotalSize.
+// Returns ErrUnsupported if the target does not support tag listing, or
+// deletion unless in the dry-run mode.
+func GarbageCollect(ctx context.Context, target ReadOnlyGraphTarget, roots []string, opts GarbageCollectOptions) ([]ocispec.Descriptor, error) {
+	if target == nil {
+		return nil, errors.New("nil target")
+	}
+	deleter, ok := target.(content.Deleter)
+	if !ok && !opts.DryRun {
+		return nil, fmt.Errorf("garbage collection: delete: %w", errdef.ErrUnsupported)
+	}
+
+	// mark the content reachable from the roots
+	var rootDescs []ocispec.Descriptor
+	for _, ref := range roots {
+		desc, err := target.Resolve(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", ref, err)
+		}
+		rootDescs = append(rootDescs, desc)
+	}
+	reachable, err := walkGraph(ctx, target, rootDescs, func(ctx context.Context, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		if !descriptor.IsManifest(desc) {
+			return nil, nil
+		}
+		return registry.Referrers(ctx, target, desc, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// discover the content in the target from the tagged nodes
+	var tagged []ocispec.Descriptor
+	if err := registry.ListTags(ctx, target, "", func(tags []string) error {
+		for _, tag := range tags {
+			desc, err := target.Resolve(ctx, tag)
+			if err != nil {
+				if errors.Is(err, errdef.ErrNotFound) {
+					continue
+				}
+				return fmt.Errorf("failed to resolve %s: %w", tag, err)
+			}
+			tagged = append(tagged, desc)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	discovered, err := walkGraph(ctx, target, tagged, target.Predecessors)
+	if err != nil {
+		return nil, err
+	}
+
+	// delete the unreachable content, predecessors first
+	unreachable := discovered.deletionOrder(reachable)
+	if opts.DryRun {
+		return unreachable, nil
+	}
+	var deleted []ocispec.Descriptor
+	for _, desc := range unreachable {
+		if opts.PreDelete != nil {
+			if err := opts.PreDelete(ctx, desc); err != nil {
+				return deleted, err
+			}
+		}
+		if err := deleter.Delete(ctx, desc); err != nil {
+			if errors.Is(err, errdef.ErrNotFound) {
+				// the content is already deleted, possibly along with its
+				// predecessor
+				continue
+			}
+			return deleted, err
+		}
+		deleted = append(deleted, desc)
+		if opts.PostDelete != nil {
+			if err := opts.PostDelete(ctx, desc); err != nil {
+				return deleted, err
+			}
+		}
+	}
+	return deleted, nil
+}
+
+// graphWalk records the nodes visited by walkGraph.
+type graphWalk struct {
+	// nodes are the visited nodes in the visiting order.
+	nodes []ocispec.Descriptor
+	// successors maps the visited nodes to their successors.
+	successors map[descriptor.Descriptor][]ocispec.Descriptor
+}
+
+// contains returns true if the node is visited.
+func (w *graphWalk) contains(node ocispec.Descriptor) bool {
+	_, ok := w.successors[descriptor.FromOCI(node)]
+	return ok
+}
+
+// walkGraph visits the nodes reachable from the given nodes through their
+// successors and the nodes returned by extra.
+// Nodes not found in the storage are skipped.
+func walkGraph(ctx context.Context, storage content.ReadOnlyStorage, nodes []ocispec.Descriptor, extra func(context.Context, ocispec.Descriptor) ([]ocispec.Descriptor, error)) (*graphWalk, error) {
+	walk := &graphWalk{
+		successors: make(map[descriptor.Descriptor][]ocispec.Descriptor),
+	}
+	queue := nodes
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		key := descriptor.FromOCI(node)
+		if _, visited := walk.successors[key]; visited {
+			continue
+		}
+
+		successors, err := content.Successors(ctx, storage, node)
+		if err != nil {
+			if errors.Is(err, errdef.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		walk.successors[key] = successors
+		walk.nodes = append(walk.nodes, node)
+		queue = append(queue, successors...)
+
+		others, err := extra(ctx, node)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, others...)
+	}
+	return walk, nil
+}
+
+// deletionOrder returns the visited nodes not visited by the excluded walk,
+// ordered such that predecessors come before their successors.
+func (w *graphWalk) deletionOrder(excluded *graphWalk) []ocispec.Descriptor {
+	var postOrder []ocispec.Descriptor
+	done := make(map[descriptor.Descriptor]bool)
+	var visit func(node ocispec.Descriptor)
+	visit = func(node ocispec.Descriptor) {
+		key := descriptor.FromOCI(node)
+		if done[key] || excluded.contains(node) {
+			return
+		}
+		done[key] = true
+		for _, successor := range w.successors[key] {
+			if w.contains(successor) {
+				visit(successor)
+			}
+		}
+		postOrder = append(postOrder, node)
+	}
+	for _, node := range w.nodes {
+		visit(node)
+	}
+
+	// reverse the post order so that predecessors come first
+	for i, j := 0, len(postOrder)-1; i < j; i, j = i+1, j-1 {
+		postOrder[i], postOrder[j] = postOrder[j], postOrder[i]
+	}
+	return postOrder
+}
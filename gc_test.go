@@ -0,0 +1,254 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+)
+
+// gcTestGraph is the graph pushed by pushGCTestGraph.
+type gcTestGraph struct {
+	config, layerA, layerB, manifestA, manifestB, sigA, sigB, sigLayer ocispec.Descriptor
+}
+
+// pushGCTestGraph pushes two images sharing a config, each with a signature,
+// and tags the images as "a" and "b".
+func pushGCTestGraph(t *testing.T, target oras.Target) gcTestGraph {
+	ctx := context.Background()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := target.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal("Push() error =", err)
+		}
+		return desc
+	}
+	pushManifest := func(manifest ocispec.Manifest) ocispec.Descriptor {
+		manifest.SchemaVersion = 2
+		manifest.MediaType = ocispec.MediaTypeImageManifest
+		manifestJSON, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return push(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+
+	var g gcTestGraph
+	g.config = push(ocispec.MediaTypeImageConfig, []byte("{}"))
+	g.layerA = push(ocispec.MediaTypeImageLayer, []byte("a"))
+	g.layerB = push(ocispec.MediaTypeImageLayer, []byte("b"))
+	g.sigLayer = push(ocispec.MediaTypeImageLayer, []byte("signature"))
+	g.manifestA = pushManifest(ocispec.Manifest{Config: g.config, Layers: []ocispec.Descriptor{g.layerA}})
+	g.manifestB = pushManifest(ocispec.Manifest{Config: g.config, Layers: []ocispec.Descriptor{g.layerB}})
+	g.sigA = pushManifest(ocispec.Manifest{Config: g.config, Layers: []ocispec.Descriptor{g.sigLayer}, Subject: &g.manifestA})
+	g.sigB = pushManifest(ocispec.Manifest{Config: g.config, Layers: []ocispec.Descriptor{g.sigLayer}, Subject: &g.manifestB})
+	if err := target.Tag(ctx, g.manifestA, "a"); err != nil {
+		t.Fatal("Tag() error =", err)
+	}
+	if err := target.Tag(ctx, g.manifestB, "b"); err != nil {
+		t.Fatal("Tag() error =", err)
+	}
+	return g
+}
+
+func TestGarbageCollect(t *testing.T) {
+	ctx := context.Background()
+	ociStore, err := oci.New(t.TempDir())
+	if err != nil {
+		t.Fatal("oci.New() error =", err)
+	}
+	targets := map[string]oras.GraphTarget{
+		"memory": memory.New(),
+		"oci":    ociStore,
+	}
+	for name, target := range targets {
+		t.Run(name, func(t *testing.T) {
+			g := pushGCTestGraph(t, target)
+
+			var preDeleted []ocispec.Descriptor
+			opts := oras.GarbageCollectOptions{
+				PreDelete: func(ctx context.Context, desc ocispec.Descriptor) error {
+					preDeleted = append(preDeleted, desc)
+					return nil
+				},
+			}
+			deleted, err := oras.GarbageCollect(ctx, target, []string{"a"}, opts)
+			if err != nil {
+				t.Fatal("GarbageCollect() error =", err)
+			}
+
+			// verify deleted content
+			wantDeleted := []ocispec.Descriptor{g.sigB, g.manifestB, g.layerB}
+			if len(deleted) != len(wantDeleted) {
+				t.Fatalf("GarbageCollect() = %v, want %v", deleted, wantDeleted)
+			}
+			for i := range wantDeleted {
+				if !content.Equal(deleted[i], wantDeleted[i]) {
+					t.Errorf("GarbageCollect()[%d] = %v, want %v", i, deleted[i], wantDeleted[i])
+				}
+			}
+			if len(preDeleted) != len(deleted) {
+				t.Errorf("PreDelete() called %d times, want %d", len(preDeleted), len(deleted))
+			}
+			for _, desc := range wantDeleted {
+				exists, err := target.Exists(ctx, desc)
+				if err != nil {
+					t.Fatal("Exists() error =", err)
+				}
+				if exists {
+					t.Errorf("Exists(%v) = %v, want %v", desc, exists, false)
+				}
+			}
+			if _, err := target.Resolve(ctx, "b"); !errors.Is(err, errdef.ErrNotFound) {
+				t.Errorf("Resolve(b) error = %v, want %v", err, errdef.ErrNotFound)
+			}
+
+			// verify kept content
+			for _, desc := range []ocispec.Descriptor{g.config, g.layerA, g.manifestA, g.sigA, g.sigLayer} {
+				exists, err := target.Exists(ctx, desc)
+				if err != nil {
+					t.Fatal("Exists() error =", err)
+				}
+				if !exists {
+					t.Errorf("Exists(%v) = %v, want %v", desc, exists, true)
+				}
+			}
+			if _, err := target.Resolve(ctx, "a"); err != nil {
+				t.Errorf("Resolve(a) error = %v", err)
+			}
+		})
+	}
+}
+
+func TestGarbageCollect_DryRun(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	g := pushGCTestGraph(t, store)
+
+	// the dry run does not require delete capability
+	type graphTagLister interface {
+		oras.ReadOnlyGraphTarget
+		registry.TagLister
+	}
+	target := struct{ graphTagLister }{store}
+	opts := oras.GarbageCollectOptions{
+		DryRun: true,
+		PreDelete: func(ctx context.Context, desc ocispec.Descriptor) error {
+			t.Errorf("PreDelete() called on %v in dry run", desc)
+			return nil
+		},
+	}
+	got, err := oras.GarbageCollect(ctx, target, []string{"a"}, opts)
+	if err != nil {
+		t.Fatal("GarbageCollect() error =", err)
+	}
+	want := []ocispec.Descriptor{g.sigB, g.manifestB, g.layerB}
+	if len(got) != len(want) {
+		t.Fatalf("GarbageCollect() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !content.Equal(got[i], want[i]) {
+			t.Errorf("GarbageCollect()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if size, wantSize := content.TotalSize(got), g.sigB.Size+g.manifestB.Size+g.layerB.Size; size != wantSize {
+		t.Errorf("TotalSize() = %d, want %d", size, wantSize)
+	}
+
+	// verify nothing is deleted
+	for _, desc := range want {
+		exists, err := store.Exists(ctx, desc)
+		if err != nil {
+			t.Fatal("Exists() error =", err)
+		}
+		if !exists {
+			t.Errorf("Exists(%v) = %v, want %v", desc, exists, true)
+		}
+	}
+	if _, err := store.Resolve(ctx, "b"); err != nil {
+		t.Errorf("Resolve(b) error = %v", err)
+	}
+
+	// the dry run reports what the actual run deletes
+	deleted, err := oras.GarbageCollect(ctx, store, []string{"a"}, oras.DefaultGarbageCollectOptions)
+	if err != nil {
+		t.Fatal("GarbageCollect() error =", err)
+	}
+	if len(deleted) != len(got) {
+		t.Fatalf("GarbageCollect() = %v, want %v", deleted, got)
+	}
+	for i := range got {
+		if !content.Equal(deleted[i], got[i]) {
+			t.Errorf("GarbageCollect()[%d] = %v, want %v", i, deleted[i], got[i])
+		}
+	}
+}
+
+func TestGarbageCollect_PreDeleteError(t *testing.T) {
+	ctx := context.Background()
+	target := memory.New()
+	g := pushGCTestGraph(t, target)
+
+	errAbort := errors.New("abort")
+	opts := oras.GarbageCollectOptions{
+		PreDelete: func(ctx context.Context, desc ocispec.Descriptor) error {
+			return errAbort
+		},
+	}
+	if _, err := oras.GarbageCollect(ctx, target, []string{"a"}, opts); !errors.Is(err, errAbort) {
+		t.Fatalf("GarbageCollect() error = %v, want %v", err, errAbort)
+	}
+	exists, err := target.Exists(ctx, g.manifestB)
+	if err != nil {
+		t.Fatal("Exists() error =", err)
+	}
+	if !exists {
+		t.Errorf("Exists() = %v, want %v", exists, true)
+	}
+}
+
+func TestGarbageCollect_Unsupported(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	pushGCTestGraph(t, store)
+
+	// target without delete capability
+	target := struct{ oras.ReadOnlyGraphTarget }{store}
+	if _, err := oras.GarbageCollect(ctx, target, []string{"a"}, oras.DefaultGarbageCollectOptions); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("GarbageCollect() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+
+	// target without tag listing capability
+	type graphDeleter interface {
+		oras.ReadOnlyGraphTarget
+		content.Deleter
+	}
+	target2 := struct{ graphDeleter }{store}
+	if _, err := oras.GarbageCollect(ctx, target2, []string{"a"}, oras.DefaultGarbageCollectOptions); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("GarbageCollect() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+)
+
+// ImmutableTagError is returned when an existing reference is about to be
+// re-tagged to a different descriptor on an immutable target.
+type ImmutableTagError struct {
+	// Reference is the reference being tagged.
+	Reference string
+	// Existing is the descriptor the reference currently resolves to.
+	Existing ocispec.Descriptor
+	// Desired is the descriptor the reference was requested to be tagged to.
+	Desired ocispec.Descriptor
+}
+
+// Error returns the error message.
+func (e *ImmutableTagError) Error() string {
+	return fmt.Sprintf("%s: immutable tag already points to %s, refusing to re-tag to %s",
+		e.Reference, e.Existing.Digest, e.Desired.Digest)
+}
+
+// Unwrap returns errdef.ErrAlreadyExists so that the error can be checked
+// with errors.Is.
+func (e *ImmutableTagError) Unwrap() error {
+	return errdef.ErrAlreadyExists
+}
+
+// immutableTarget is a Target refusing to move existing tags.
+type immutableTarget struct {
+	Target
+}
+
+// NewImmutableTarget wraps t to refuse re-tagging an existing reference to a
+// different digest with an *ImmutableTagError. Re-tagging a reference to the
+// digest it already points to is allowed.
+//
+// If t is a registry.Repository, such as a remote repository, the returned
+// Target is also a registry.Repository, whose manifest store refuses
+// re-tagging as well. If t is also a registry.Mounter, so is the returned
+// Target.
+//
+// The check and the tagging are not atomic. Concurrent writers to the same
+// reference may still overwrite each other.
+func NewImmutableTarget(t Target) Target {
+	switch t.(type) {
+	case *immutableTarget, *immutableRepository, *immutableMountableRepository:
+		return t
+	}
+	if repo, ok := t.(registry.Repository); ok {
+		immutableRepo := immutableRepository{
+			immutableTarget: immutableTarget{Target: t},
+			repo:            repo,
+		}
+		if mounter, ok := t.(registry.Mounter); ok {
+			return &immutableMountableRepository{
+				immutableRepository: immutableRepo,
+				mounter:             mounter,
+			}
+		}
+		return &immutableRepo
+	}
+	return &immutableTarget{Target: t}
+}
+
+// Tag tags a descriptor with a reference string if the reference does not
+// exist or already points to the same digest.
+func (t *immutableTarget) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	if err := checkImmutableTag(ctx, t.Target, desc, reference); err != nil {
+		return err
+	}
+	return t.Target.Tag(ctx, desc, reference)
+}
+
+// PushReference pushes the manifest with a reference tag if the reference
+// does not exist or already points to the same digest.
+func (t *immutableTarget) PushReference(ctx context.Context, expected ocispec.Descriptor, r io.Reader, reference string) error {
+	if err := checkImmutableTag(ctx, t.Target, expected, reference); err != nil {
+		return err
+	}
+	if refPusher, ok := t.Target.(registry.ReferencePusher); ok {
+		return refPusher.PushReference(ctx, expected, r, reference)
+	}
+	if err := t.Target.Push(ctx, expected, r); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return err
+	}
+	return t.Target.Tag(ctx, expected, reference)
+}
+
+// immutableRepository is a registry.Repository refusing to move existing
+// tags.
+type immutableRepository struct {
+	immutableTarget
+	repo registry.Repository
+}
+
+// Delete removes the content identified by the descriptor.
+func (r *immutableRepository) Delete(ctx context.Context, target ocispec.Descriptor) error {
+	return r.repo.Delete(ctx, target)
+}
+
+// FetchReference fetches the content identified by the reference.
+func (r *immutableRepository) FetchReference(ctx context.Context, reference string) (ocispec.Descriptor, io.ReadCloser, error) {
+	return r.repo.FetchReference(ctx, reference)
+}
+
+// Referrers lists the descriptors of the manifests directly referencing desc.
+func (r *immutableRepository) Referrers(ctx context.Context, desc ocispec.Descriptor, artifactType string, fn func(referrers []ocispec.Descriptor) error) error {
+	return r.repo.Referrers(ctx, desc, artifactType, fn)
+}
+
+// Tags lists the tags available in the repository.
+func (r *immutableRepository) Tags(ctx context.Context, last string, fn func(tags []string) error) error {
+	return r.repo.Tags(ctx, last, fn)
+}
+
+// Blobs provides access to the blob CAS only.
+func (r *immutableRepository) Blobs() registry.BlobStore {
+	return r.repo.Blobs()
+}
+
+// Manifests provides access to the manifest CAS only, refusing to move
+// existing tags.
+func (r *immutableRepository) Manifests() registry.ManifestStore {
+	manifests := r.repo.Manifests()
+	return &immutableManifestStore{
+		ManifestStore: manifests,
+		resolver:      r.repo,
+	}
+}
+
+// immutableMountableRepository is an immutableRepository forwarding
+// registry.Mounter.
+type immutableMountableRepository struct {
+	immutableRepository
+	mounter registry.Mounter
+}
+
+// Mount makes the blob with the given descriptor in fromRepo available in the
+// repository.
+func (r *immutableMountableRepository) Mount(ctx context.Context, desc ocispec.Descriptor, fromRepo string, getContent func() (io.ReadCloser, error)) error {
+	return r.mounter.Mount(ctx, desc, fromRepo, getContent)
+}
+
+// immutableManifestStore is a registry.ManifestStore refusing to move
+// existing tags.
+type immutableManifestStore struct {
+	registry.ManifestStore
+	resolver content.Resolver
+}
+
+// Tag tags a descriptor with a reference string if the reference does not
+// exist or already points to the same digest.
+func (s *immutableManifestStore) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	if err := checkImmutableTag(ctx, s.resolver, desc, reference); err != nil {
+		return err
+	}
+	return s.ManifestStore.Tag(ctx, desc, reference)
+}
+
+// PushReference pushes the manifest with a reference tag if the reference
+// does not exist or already points to the same digest.
+func (s *immutableManifestStore) PushReference(ctx context.Context, expected ocispec.Descriptor, r io.Reader, reference string) error {
+	if err := checkImmutableTag(ctx, s.resolver, expected, reference); err != nil {
+		return err
+	}
+	return s.ManifestStore.PushReference(ctx, expected, r, reference)
+}
+
+// checkImmutableTag returns an *ImmutableTagError if reference exists and
+// resolves to a digest different from desc.
+func checkImmutableTag(ctx context.Context, resolver content.Resolver, desc ocispec.Descriptor, reference string) error {
+	existing, err := resolver.Resolve(ctx, reference)
+	if err != nil {
+		if errors.Is(err, errdef.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.Digest != desc.Digest {
+		return &ImmutableTagError{
+			Reference: reference,
+			Existing:  existing,
+			Desired:   desc,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+)
+
+func TestImmutableTarget(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	target := NewImmutableTarget(s)
+	if got := NewImmutableTarget(target); got != target {
+		t.Errorf("NewImmutableTarget() = %v, want %v", got, target)
+	}
+
+	foo := []byte("foo")
+	fooDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, foo)
+	bar := []byte("bar")
+	barDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, bar)
+	for _, blob := range [][]byte{foo, bar} {
+		desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+		if err := target.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal("Target.Push() error =", err)
+		}
+	}
+
+	ref := "v1"
+	if err := target.Tag(ctx, fooDesc, ref); err != nil {
+		t.Fatal("Target.Tag() error =", err)
+	}
+	// test re-tagging to the same digest
+	if err := target.Tag(ctx, fooDesc, ref); err != nil {
+		t.Fatal("Target.Tag() error =", err)
+	}
+	// test re-tagging to a different digest
+	err := target.Tag(ctx, barDesc, ref)
+	var tagErr *ImmutableTagError
+	if !errors.As(err, &tagErr) {
+		t.Fatalf("Target.Tag() error = %v, want *ImmutableTagError", err)
+	}
+	if !errors.Is(err, errdef.ErrAlreadyExists) {
+		t.Errorf("Target.Tag() error = %v, wantErr %v", err, errdef.ErrAlreadyExists)
+	}
+	if tagErr.Reference != ref || tagErr.Existing.Digest != fooDesc.Digest || tagErr.Desired.Digest != barDesc.Digest {
+		t.Errorf("ImmutableTagError = %+v, want reference %s from %s to %s", tagErr, ref, fooDesc.Digest, barDesc.Digest)
+	}
+	if got, err := s.Resolve(ctx, ref); err != nil || got.Digest != fooDesc.Digest {
+		t.Errorf("Store.Resolve() = %v, %v, want %v", got.Digest, err, fooDesc.Digest)
+	}
+
+	// test PushReference
+	refPusher := target.(registry.ReferencePusher)
+	baz := []byte("baz")
+	bazDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, baz)
+	err = refPusher.PushReference(ctx, bazDesc, bytes.NewReader(baz), ref)
+	if !errors.As(err, &tagErr) {
+		t.Fatalf("Target.PushReference() error = %v, want *ImmutableTagError", err)
+	}
+	if exists, err := s.Exists(ctx, bazDesc); err != nil || exists {
+		t.Errorf("Store.Exists() = %v, %v, want false", exists, err)
+	}
+	if err := refPusher.PushReference(ctx, bazDesc, bytes.NewReader(baz), "v2"); err != nil {
+		t.Fatal("Target.PushReference() error =", err)
+	}
+	if got, err := s.Resolve(ctx, "v2"); err != nil || got.Digest != bazDesc.Digest {
+		t.Errorf("Store.Resolve() = %v, %v, want %v", got.Digest, err, bazDesc.Digest)
+	}
+}
+
+func TestCopy_ImmutableTag(t *testing.T) {
+	ctx := context.Background()
+	v1, v1Manifest := pushImage(t, []byte(`{"architecture":"amd64","os":"linux"}`), []byte("v1"), "latest")
+	v2, v2Manifest := pushImage(t, []byte(`{"architecture":"amd64","os":"linux"}`), []byte("v2"), "latest")
+
+	dst := memory.New()
+	opts := CopyOptions{ImmutableTag: true}
+	if _, err := Copy(ctx, v1, "latest", dst, "release", opts); err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+	// test copying the same content again
+	if _, err := Copy(ctx, v1, "latest", dst, "release", opts); err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+	// test copying different content
+	_, err := Copy(ctx, v2, "latest", dst, "release", opts)
+	var tagErr *ImmutableTagError
+	if !errors.As(err, &tagErr) {
+		t.Fatalf("Copy() error = %v, want *ImmutableTagError", err)
+	}
+	if exists, err := dst.Exists(ctx, v2Manifest); err != nil || exists {
+		t.Errorf("Store.Exists() = %v, %v, want false", exists, err)
+	}
+	if got, err := dst.Resolve(ctx, "release"); err != nil || got.Digest != v1Manifest.Digest {
+		t.Errorf("Store.Resolve() = %v, %v, want %v", got.Digest, err, v1Manifest.Digest)
+	}
+
+	// test without the option
+	if _, err := Copy(ctx, v2, "latest", dst, "release", CopyOptions{}); err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+}
+
+func TestImmutableTarget_Repository(t *testing.T) {
+	ctx := context.Background()
+	target := NewImmutableTarget(&testRepository{Store: memory.New()})
+	repo, ok := target.(registry.Repository)
+	if !ok {
+		t.Fatalf("NewImmutableTarget() = %T, want registry.Repository", target)
+	}
+	if _, ok := target.(blobStoreProvider); !ok {
+		t.Errorf("NewImmutableTarget() = %T, want blobStoreProvider", target)
+	}
+	if _, ok := target.(registry.Mounter); ok {
+		t.Errorf("NewImmutableTarget() = %T, want no registry.Mounter", target)
+	}
+	if got := NewImmutableTarget(target); got != target {
+		t.Errorf("NewImmutableTarget() = %v, want %v", got, target)
+	}
+
+	foo := []byte(`{"schemaVersion":2,"annotations":{"name":"foo"}}`)
+	fooDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, foo)
+	bar := []byte(`{"schemaVersion":2,"annotations":{"name":"bar"}}`)
+	barDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, bar)
+	ref := "v1"
+	if err := repo.PushReference(ctx, fooDesc, bytes.NewReader(foo), ref); err != nil {
+		t.Fatal("Repository.PushReference() error =", err)
+	}
+
+	// the manifest store refuses re-tagging as well
+	manifests := repo.Manifests()
+	if err := manifests.Push(ctx, barDesc, bytes.NewReader(bar)); err != nil {
+		t.Fatal("ManifestStore.Push() error =", err)
+	}
+	if err := manifests.Tag(ctx, fooDesc, ref); err != nil {
+		t.Fatal("ManifestStore.Tag() error =", err)
+	}
+	err := manifests.Tag(ctx, barDesc, ref)
+	if !errors.Is(err, errdef.ErrAlreadyExists) {
+		t.Errorf("ManifestStore.Tag() error = %v, want %v", err, errdef.ErrAlreadyExists)
+	}
+	err = manifests.PushReference(ctx, barDesc, bytes.NewReader(bar), ref)
+	if !errors.Is(err, errdef.ErrAlreadyExists) {
+		t.Errorf("ManifestStore.PushReference() error = %v, want %v", err, errdef.ErrAlreadyExists)
+	}
+	desc, rc, err := repo.FetchReference(ctx, ref)
+	if err != nil {
+		t.Fatal("Repository.FetchReference() error =", err)
+	}
+	rc.Close()
+	if !content.Equal(desc, fooDesc) {
+		t.Errorf("Repository.FetchReference() = %v, want %v", desc, fooDesc)
+	}
+
+	// mounting is not supported by the wrapped repository
+	blob := []byte("blob")
+	blobDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+	getContent := func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(blob)), nil
+	}
+	err = registry.Mount(ctx, target, blobDesc, "source", getContent)
+	if !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Mount() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+}
+
+// testMountableRepository is a testRepository mounting blobs by pushing the
+// content.
+type testMountableRepository struct {
+	testRepository
+	mounted []string
+}
+
+func (r *testMountableRepository) Mount(ctx context.Context, desc ocispec.Descriptor, fromRepo string, getContent func() (io.ReadCloser, error)) error {
+	r.mounted = append(r.mounted, fromRepo)
+	return nil
+}
+
+func TestImmutableTarget_MountableRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := &testMountableRepository{testRepository: testRepository{Store: memory.New()}}
+	target := NewImmutableTarget(repo)
+	if _, ok := target.(registry.Repository); !ok {
+		t.Fatalf("NewImmutableTarget() = %T, want registry.Repository", target)
+	}
+	if got := NewImmutableTarget(target); got != target {
+		t.Errorf("NewImmutableTarget() = %v, want %v", got, target)
+	}
+
+	blobDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, []byte("blob"))
+	if err := registry.Mount(ctx, target, blobDesc, "source", nil); err != nil {
+		t.Fatal("Mount() error =", err)
+	}
+	if want := []string{"source"}; !reflect.DeepEqual(repo.mounted, want) {
+		t.Errorf("mounted = %v, want %v", repo.mounted, want)
+	}
+}
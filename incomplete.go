@@ -0,0 +1,102 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/logging"
+)
+
+// MissingContentReport lists the nodes missing in the source of a copy
+// tolerating incomplete graphs.
+// See also CopyGraphOptions.WithMissingContentReport.
+type MissingContentReport struct {
+	// Missing are the nodes missing in the source, which are not copied.
+	// The sub-DAGs rooted by missing manifests are not walked, and their
+	// nodes are not listed.
+	// Missing is safe to read once the copy returns.
+	Missing []ocispec.Descriptor
+
+	lock sync.Mutex
+}
+
+// Complete reports whether no content is missing, that is, whether the graph
+// is completely copied to the destination.
+func (r *MissingContentReport) Complete() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return len(r.Missing) == 0
+}
+
+// add adds a missing node to the report.
+func (r *MissingContentReport) add(desc ocispec.Descriptor) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.Missing = append(r.Missing, desc)
+}
+
+// WithMissingContentReport configures opts to tolerate the nodes missing in
+// the source by setting AllowIncompleteGraph, and records the missing nodes
+// into report. Check report.Complete after the copy to find out if the graph
+// in the destination is incomplete.
+func (opts *CopyGraphOptions) WithMissingContentReport(report *MissingContentReport) {
+	opts.AllowIncompleteGraph = true
+	onMissingContent := opts.OnMissingContent
+	opts.OnMissingContent = func(ctx context.Context, desc ocispec.Descriptor) error {
+		report.add(desc)
+		if onMissingContent != nil {
+			return onMissingContent(ctx, desc)
+		}
+		return nil
+	}
+}
+
+// sourceNotFoundError is returned when the content being copied is not found
+// in the source.
+type sourceNotFoundError struct {
+	err error
+}
+
+// Error returns the error message.
+func (e *sourceNotFoundError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *sourceNotFoundError) Unwrap() error {
+	return e.err
+}
+
+// isMissingContent returns true if err indicates that the non-root node desc
+// is missing in the source, and missing content is tolerated by opts.
+func isMissingContent(err error, desc, root ocispec.Descriptor, opts CopyGraphOptions) bool {
+	return opts.AllowIncompleteGraph && errors.Is(err, errdef.ErrNotFound) && !content.Equal(desc, root)
+}
+
+// reportMissingContent reports the node missing in the source.
+func reportMissingContent(ctx context.Context, desc ocispec.Descriptor, opts CopyGraphOptions) error {
+	logging.FromContext(ctx).Info("skipped content missing in source", "digest", desc.Digest, "mediaType", desc.MediaType)
+	if opts.OnMissingContent != nil {
+		return opts.OnMissingContent(ctx, desc)
+	}
+	return nil
+}
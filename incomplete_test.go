@@ -0,0 +1,143 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// sparseTarget is a target missing some of its content.
+type sparseTarget struct {
+	*memory.Store
+	missing []ocispec.Descriptor
+}
+
+func (t *sparseTarget) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	for _, desc := range t.missing {
+		if content.Equal(desc, target) {
+			return nil, fmt.Errorf("%s: %s: %w", target.Digest, target.MediaType, errdef.ErrNotFound)
+		}
+	}
+	return t.Store.Fetch(ctx, target)
+}
+
+func TestCopy_missingContent(t *testing.T) {
+	ctx := context.Background()
+	layer := []byte("hello world")
+	s, manifest := pushImage(t, []byte("{}"), layer, "latest")
+	layerDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+	src := &sparseTarget{Store: s, missing: []ocispec.Descriptor{layerDesc}}
+
+	// missing content aborts the copy by default
+	if _, err := Copy(ctx, src, "latest", memory.New(), "", DefaultCopyOptions); !errors.Is(err, errdef.ErrNotFound) {
+		t.Fatalf("Copy() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+
+	dst := memory.New()
+	var report MissingContentReport
+	opts := CopyOptions{}
+	opts.WithMissingContentReport(&report)
+	var postCopied []ocispec.Descriptor
+	opts.PostCopy = func(ctx context.Context, desc ocispec.Descriptor) error {
+		postCopied = append(postCopied, desc)
+		return nil
+	}
+	root, err := Copy(ctx, src, "latest", dst, "", opts)
+	if err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+	if !content.Equal(root, manifest) {
+		t.Errorf("Copy() = %v, want %v", root, manifest)
+	}
+	if report.Complete() {
+		t.Error("MissingContentReport.Complete() = true, want false")
+	}
+	if len(report.Missing) != 1 || !content.Equal(report.Missing[0], layerDesc) {
+		t.Errorf("MissingContentReport.Missing = %v, want %v", report.Missing, []ocispec.Descriptor{layerDesc})
+	}
+	for _, desc := range postCopied {
+		if content.Equal(desc, layerDesc) {
+			t.Error("PostCopy() called for missing content")
+		}
+	}
+	if exists, err := dst.Exists(ctx, layerDesc); err != nil || exists {
+		t.Errorf("Store.Exists(layer) = %v, %v, want false, nil", exists, err)
+	}
+	if desc, err := dst.Resolve(ctx, "latest"); err != nil || !content.Equal(desc, manifest) {
+		t.Errorf("Store.Resolve() = %v, %v, want %v", desc, err, manifest)
+	}
+}
+
+func TestCopyGraph_missingManifest(t *testing.T) {
+	ctx := context.Background()
+	s, amd64 := pushImage(t, []byte(`{"architecture":"amd64"}`), []byte("amd64"), "amd64")
+	arm64Store, arm64 := pushImage(t, []byte(`{"architecture":"arm64"}`), []byte("arm64"), "arm64")
+	copyInto(t, arm64Store, s, arm64)
+	index := pushIndex(t, s, amd64, arm64)
+	src := &sparseTarget{Store: s, missing: []ocispec.Descriptor{arm64}}
+
+	dst := memory.New()
+	var report MissingContentReport
+	var opts CopyGraphOptions
+	var onMissing []ocispec.Descriptor
+	opts.OnMissingContent = func(ctx context.Context, desc ocispec.Descriptor) error {
+		onMissing = append(onMissing, desc)
+		return nil
+	}
+	opts.WithMissingContentReport(&report)
+	if err := CopyGraph(ctx, src, dst, index, opts); err != nil {
+		t.Fatal("CopyGraph() error =", err)
+	}
+	if len(report.Missing) != 1 || !content.Equal(report.Missing[0], arm64) {
+		t.Errorf("MissingContentReport.Missing = %v, want %v", report.Missing, []ocispec.Descriptor{arm64})
+	}
+	if len(onMissing) != 1 {
+		t.Errorf("OnMissingContent() called %d times, want 1", len(onMissing))
+	}
+	for _, desc := range []ocispec.Descriptor{index, amd64} {
+		if exists, err := dst.Exists(ctx, desc); err != nil || !exists {
+			t.Errorf("Store.Exists(%s) = %v, %v, want true, nil", desc.Digest, exists, err)
+		}
+	}
+	if exists, err := dst.Exists(ctx, arm64); err != nil || exists {
+		t.Errorf("Store.Exists(arm64) = %v, %v, want false, nil", exists, err)
+	}
+}
+
+func TestCopyGraph_missingRoot(t *testing.T) {
+	ctx := context.Background()
+	s, manifest := pushImage(t, []byte("{}"), []byte("foo"), "latest")
+	src := &sparseTarget{Store: s, missing: []ocispec.Descriptor{manifest}}
+
+	var report MissingContentReport
+	var opts CopyGraphOptions
+	opts.WithMissingContentReport(&report)
+	if err := CopyGraph(ctx, src, memory.New(), manifest, opts); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("CopyGraph() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+	if !report.Complete() {
+		t.Errorf("MissingContentReport.Missing = %v, want empty", report.Missing)
+	}
+}
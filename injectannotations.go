@@ -0,0 +1,103 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/internal/spec"
+)
+
+// WithInjectedAnnotations configures opts.InjectAnnotations to add the
+// annotations to every manifest and index copied, overriding the existing
+// values of the same keys.
+func (opts *CopyOptions) WithInjectedAnnotations(annotations map[string]string) {
+	opts.InjectAnnotations = func(_ context.Context, _ ocispec.Descriptor) (map[string]string, error) {
+		return annotations, nil
+	}
+}
+
+// annotationMapper returns a MapManifest function adding the annotations
+// returned by inject to the manifests and the indexes, overriding the
+// existing values of the same keys. The content is returned as is if the
+// annotations are not changed.
+func annotationMapper(inject func(ctx context.Context, desc ocispec.Descriptor) (map[string]string, error)) func(ctx context.Context, desc ocispec.Descriptor, content []byte) ([]byte, error) {
+	return func(ctx context.Context, desc ocispec.Descriptor, content []byte) ([]byte, error) {
+		switch desc.MediaType {
+		case ocispec.MediaTypeImageManifest, ocispec.MediaTypeImageIndex, spec.MediaTypeArtifactManifest:
+		default:
+			// annotations are not supported
+			return content, nil
+		}
+		injected, err := inject(ctx, desc)
+		if err != nil {
+			return nil, err
+		}
+		var manifest map[string]json.RawMessage
+		if err := json.Unmarshal(content, &manifest); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %s: %w", desc.Digest, desc.MediaType, err)
+		}
+		annotations, err := decodeAnnotations(desc, manifest)
+		if err != nil {
+			return nil, err
+		}
+		changed := false
+		for k, v := range injected {
+			if value, ok := annotations[k]; !ok || value != v {
+				changed = true
+				break
+			}
+		}
+		if !changed {
+			return content, nil
+		}
+		annotations = applyAnnotationChanges(annotations, AnnotateOptions{Set: injected})
+		if manifest["annotations"], err = json.Marshal(annotations); err != nil {
+			return nil, err
+		}
+		return json.Marshal(manifest)
+	}
+}
+
+// decodeAnnotations decodes the annotations of the decoded manifest or
+// index.
+func decodeAnnotations(desc ocispec.Descriptor, manifest map[string]json.RawMessage) (map[string]string, error) {
+	var annotations map[string]string
+	if raw, ok := manifest["annotations"]; ok {
+		if err := json.Unmarshal(raw, &annotations); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %s: %w", desc.Digest, desc.MediaType, err)
+		}
+	}
+	return annotations, nil
+}
+
+// mappedRootAnnotations returns the annotations of the mapped root node
+// staged in the fetcher, which are set on the descriptor returned by Copy.
+func mappedRootAnnotations(ctx context.Context, fetcher content.Fetcher, root ocispec.Descriptor) (map[string]string, error) {
+	rootJSON, err := content.FetchAll(ctx, fetcher, root)
+	if err != nil {
+		return nil, err
+	}
+	var manifest map[string]json.RawMessage
+	if err := json.Unmarshal(rootJSON, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %s: %w", root.Digest, root.MediaType, err)
+	}
+	return decodeAnnotations(root, manifest)
+}
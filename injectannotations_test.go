@@ -0,0 +1,132 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestCopy_InjectAnnotations(t *testing.T) {
+	ctx := context.Background()
+	src, manifestDesc := pushImage(t, []byte(`{"architecture":"amd64","os":"linux"}`), []byte("layer"), "image")
+	child := manifestDesc
+	child.Platform = &ocispec.Platform{OS: "linux", Architecture: "amd64"}
+	indexDesc := pushIndex(t, src, child)
+	if err := src.Tag(ctx, indexDesc, "index"); err != nil {
+		t.Fatal("Store.Tag() error =", err)
+	}
+
+	dst := memory.New()
+	opts := CopyOptions{}
+	opts.WithInjectedAnnotations(map[string]string{"mirror.source": "example"})
+	root, err := Copy(ctx, src, "index", dst, "mirrored", opts)
+	if err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+	if root.Digest == indexDesc.Digest {
+		t.Fatal("Copy() root digest is not changed")
+	}
+	if got := root.Annotations["mirror.source"]; got != "example" {
+		t.Errorf("root annotation = %q, want %q", got, "example")
+	}
+	tagged, err := dst.Resolve(ctx, "mirrored")
+	if err != nil {
+		t.Fatal("Store.Resolve() error =", err)
+	}
+	if tagged.Digest != root.Digest {
+		t.Errorf("tagged digest = %v, want %v", tagged.Digest, root.Digest)
+	}
+
+	var index ocispec.Index
+	indexJSON, err := content.FetchAll(ctx, dst, root)
+	if err != nil {
+		t.Fatal("FetchAll() error =", err)
+	}
+	if err := json.Unmarshal(indexJSON, &index); err != nil {
+		t.Fatal("json.Unmarshal() error =", err)
+	}
+	if got := index.Annotations["mirror.source"]; got != "example" {
+		t.Errorf("index annotation = %q, want %q", got, "example")
+	}
+	if len(index.Manifests) != 1 {
+		t.Fatalf("len(index.Manifests) = %d, want 1", len(index.Manifests))
+	}
+	newChild := index.Manifests[0]
+	if newChild.Digest == manifestDesc.Digest {
+		t.Error("child digest is not changed")
+	}
+	if newChild.Platform == nil || newChild.Platform.Architecture != "amd64" {
+		t.Errorf("child platform = %v, want amd64", newChild.Platform)
+	}
+	var manifest ocispec.Manifest
+	manifestJSON, err := content.FetchAll(ctx, dst, newChild)
+	if err != nil {
+		t.Fatal("FetchAll() error =", err)
+	}
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		t.Fatal("json.Unmarshal() error =", err)
+	}
+	if got := manifest.Annotations["mirror.source"]; got != "example" {
+		t.Errorf("manifest annotation = %q, want %q", got, "example")
+	}
+	if exists, err := dst.Exists(ctx, manifest.Layers[0]); err != nil || !exists {
+		t.Errorf("layer exists = %v, %v, want true", exists, err)
+	}
+
+	// the source is not modified
+	if exists, err := dst.Exists(ctx, indexDesc); err != nil || exists {
+		t.Errorf("original index exists in dst = %v, %v, want false", exists, err)
+	}
+	if got, err := src.Resolve(ctx, "index"); err != nil || got.Digest != indexDesc.Digest {
+		t.Errorf("src.Resolve() = %v, %v, want %v", got.Digest, err, indexDesc.Digest)
+	}
+}
+
+func TestCopy_InjectAnnotations_Unchanged(t *testing.T) {
+	ctx := context.Background()
+	src, manifestDesc := pushImage(t, []byte("{}"), []byte("layer"), "image")
+	dst := memory.New()
+	opts := CopyOptions{}
+	opts.WithInjectedAnnotations(nil)
+	root, err := Copy(ctx, src, "image", dst, "image", opts)
+	if err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+	if root.Digest != manifestDesc.Digest {
+		t.Errorf("Copy() digest = %v, want %v", root.Digest, manifestDesc.Digest)
+	}
+}
+
+func TestCopy_InjectAnnotations_Error(t *testing.T) {
+	ctx := context.Background()
+	src, _ := pushImage(t, []byte("{}"), []byte("layer"), "image")
+	errInject := errors.New("inject failed")
+	opts := CopyOptions{
+		InjectAnnotations: func(ctx context.Context, desc ocispec.Descriptor) (map[string]string, error) {
+			return nil, errInject
+		},
+	}
+	if _, err := Copy(ctx, src, "image", memory.New(), "image", opts); !errors.Is(err, errInject) {
+		t.Errorf("Copy() error = %v, want %v", err, errInject)
+	}
+}
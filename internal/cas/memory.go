@@ -75,6 +75,15 @@ func (m *Memory) Exists(_ context.Context, target ocispec.Descriptor) (bool, err
 	return exists, nil
 }
 
+// Delete removes the content identified by the descriptor.
+func (m *Memory) Delete(_ context.Context, target ocispec.Descriptor) error {
+	key := descriptor.FromOCI(target)
+	if _, exists := m.content.LoadAndDelete(key); !exists {
+		return fmt.Errorf("%s: %s: %w", key.Digest, key.MediaType, errdef.ErrNotFound)
+	}
+	return nil
+}
+
 // Map dumps the memory into a built-in map structure.
 // Like other operations, calling Map() is go-routine safe. However, it does not
 // necessarily correspond to any consistent snapshot of the storage contents.
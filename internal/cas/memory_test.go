@@ -136,3 +136,32 @@ func TestMemoryBadPush(t *testing.T) {
 		t.Errorf("Memory.Push() error = %v, wantErr %v", err, true)
 	}
 }
+
+func TestMemoryDelete(t *testing.T) {
+	content := []byte("hello world")
+	desc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(content),
+		Size:      int64(len(content)),
+	}
+
+	s := NewMemory()
+	ctx := context.Background()
+
+	if err := s.Push(ctx, desc, bytes.NewReader(content)); err != nil {
+		t.Fatal("Memory.Push() error =", err)
+	}
+	if err := s.Delete(ctx, desc); err != nil {
+		t.Fatal("Memory.Delete() error =", err)
+	}
+	exists, err := s.Exists(ctx, desc)
+	if err != nil {
+		t.Fatal("Memory.Exists() error =", err)
+	}
+	if exists {
+		t.Errorf("Memory.Exists() = %v, want %v", exists, false)
+	}
+	if err := s.Delete(ctx, desc); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Memory.Delete() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+}
@@ -31,6 +31,7 @@ import (
 // Memory is a memory based PredecessorFinder.
 type Memory struct {
 	predecessors sync.Map // map[descriptor.Descriptor]map[descriptor.Descriptor]ocispec.Descriptor
+	successors   sync.Map // map[descriptor.Descriptor][]ocispec.Descriptor
 	indexed      sync.Map // map[descriptor.Descriptor]any
 }
 
@@ -116,6 +117,24 @@ func (m *Memory) Predecessors(_ context.Context, node ocispec.Descriptor) ([]oci
 	return res, nil
 }
 
+// Remove removes the node from the predecessors of its direct successors.
+// The predecessors of the node itself are kept so that they remain
+// discoverable if the node is added back.
+func (m *Memory) Remove(_ context.Context, node ocispec.Descriptor) {
+	predecessorKey := descriptor.FromOCI(node)
+	m.indexed.Delete(predecessorKey)
+	value, exists := m.successors.LoadAndDelete(predecessorKey)
+	if !exists {
+		return
+	}
+	for _, successor := range value.([]ocispec.Descriptor) {
+		successorKey := descriptor.FromOCI(successor)
+		if value, exists := m.predecessors.Load(successorKey); exists {
+			value.(*sync.Map).Delete(predecessorKey)
+		}
+	}
+}
+
 // index indexes predecessors for each direct successor of the given node.
 // There is no data consistency issue as long as deletion is not implemented
 // for the underlying storage.
@@ -125,6 +144,7 @@ func (m *Memory) index(ctx context.Context, node ocispec.Descriptor, successors
 	}
 
 	predecessorKey := descriptor.FromOCI(node)
+	m.successors.Store(predecessorKey, successors)
 	for _, successor := range successors {
 		successorKey := descriptor.FromOCI(successor)
 		value, _ := m.predecessors.LoadOrStore(successorKey, &sync.Map{})
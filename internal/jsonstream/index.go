@@ -0,0 +1,122 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package jsonstream decodes large JSON documents incrementally.
+package jsonstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// manifestsField is the name of the manifests field of image indexes.
+const manifestsField = "manifests"
+
+// DecodeIndex decodes the image index read from r incrementally. Instead of
+// being collected into index.Manifests, which is left untouched, the entries
+// of the manifests field are decoded one at a time and fed to fn in order,
+// so that the memory used for decoding is bounded by the largest entry
+// rather than the whole index. The other fields are decoded into index.
+//
+// Decoding stops at the first error returned by fn, which is returned as is.
+func DecodeIndex(r io.Reader, index *ocispec.Index, fn func(desc ocispec.Descriptor) error) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	fields := make(map[string]json.RawMessage)
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("invalid object key %v", token)
+		}
+		// match field names case-insensitively like encoding/json
+		if !strings.EqualFold(key, manifestsField) {
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return err
+			}
+			fields[key] = value
+			continue
+		}
+		if err := decodeDescriptors(dec, fn); err != nil {
+			return err
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return err
+	}
+
+	// the remaining fields are small enough to be decoded at once
+	fieldsJSON, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	manifests := index.Manifests
+	if err := json.Unmarshal(fieldsJSON, index); err != nil {
+		return err
+	}
+	index.Manifests = manifests
+	return nil
+}
+
+// decodeDescriptors decodes a JSON array of descriptors, or null, one element
+// at a time.
+func decodeDescriptors(dec *json.Decoder, fn func(desc ocispec.Descriptor) error) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		// null
+		return nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("invalid %s field: expect array, got %v", manifestsField, token)
+	}
+	for dec.More() {
+		var desc ocispec.Descriptor
+		if err := dec.Decode(&desc); err != nil {
+			return err
+		}
+		if err := fn(desc); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+// expectDelim reads the next token and checks if it is the delimiter.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if got, ok := token.(json.Delim); !ok || got != delim {
+		return fmt.Errorf("invalid JSON: expect %v, got %v", delim, token)
+	}
+	return nil
+}
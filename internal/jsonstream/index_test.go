@@ -0,0 +1,124 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jsonstream
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/internal/spec"
+)
+
+func TestDecodeIndex(t *testing.T) {
+	want := ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{
+			{
+				MediaType:    ocispec.MediaTypeImageManifest,
+				Digest:       digest.FromString("foo"),
+				Size:         3,
+				ArtifactType: "application/vnd.test",
+			},
+			{
+				MediaType: spec.MediaTypeArtifactManifest,
+				Digest:    digest.FromString("bar"),
+				Size:      3,
+			},
+		},
+		Annotations: map[string]string{
+			"key": "value",
+		},
+	}
+	want.SchemaVersion = 2
+	indexJSON, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("failed to marshal index: %v", err)
+	}
+
+	var got ocispec.Index
+	var manifests []ocispec.Descriptor
+	if err := DecodeIndex(strings.NewReader(string(indexJSON)), &got, func(desc ocispec.Descriptor) error {
+		manifests = append(manifests, desc)
+		return nil
+	}); err != nil {
+		t.Fatalf("DecodeIndex() error = %v", err)
+	}
+	if got.Manifests != nil {
+		t.Errorf("DecodeIndex() index.Manifests = %v, want nil", got.Manifests)
+	}
+	got.Manifests = manifests
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeIndex() = %v, want %v", got, want)
+	}
+}
+
+func TestDecodeIndex_NullManifests(t *testing.T) {
+	var got ocispec.Index
+	if err := DecodeIndex(strings.NewReader(`{"schemaVersion":2,"manifests":null}`), &got, func(desc ocispec.Descriptor) error {
+		t.Errorf("unexpected descriptor: %v", desc)
+		return nil
+	}); err != nil {
+		t.Fatalf("DecodeIndex() error = %v", err)
+	}
+	if got.SchemaVersion != 2 {
+		t.Errorf("DecodeIndex() schemaVersion = %v, want 2", got.SchemaVersion)
+	}
+}
+
+func TestDecodeIndex_CallbackError(t *testing.T) {
+	errStop := errors.New("stop")
+	indexJSON := `{"manifests":[{"size":1},{"size":2},{"size":3}]}`
+	var count int
+	err := DecodeIndex(strings.NewReader(indexJSON), &ocispec.Index{}, func(desc ocispec.Descriptor) error {
+		count++
+		if desc.Size == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("DecodeIndex() error = %v, want %v", err, errStop)
+	}
+	if count != 2 {
+		t.Errorf("DecodeIndex() fed %d descriptors, want 2", count)
+	}
+}
+
+func TestDecodeIndex_Invalid(t *testing.T) {
+	tests := []string{
+		``,
+		`[]`,
+		`{"manifests":{}}`,
+		`{"manifests":[{"size":"1"}]}`,
+		`{"manifests":[{"size":1}`,
+		`{"schemaVersion":"2"}`,
+		`{"manifests":[]`,
+	}
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			if err := DecodeIndex(strings.NewReader(tt), &ocispec.Index{}, func(ocispec.Descriptor) error {
+				return nil
+			}); err == nil {
+				t.Error("DecodeIndex() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package manifestutil provides utilities for walking manifests and indexes.
+package manifestutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/docker"
+)
+
+// DefaultMaxIndexDepth is the default number of nested index levels below
+// the root walked by WalkIndex.
+const DefaultMaxIndexDepth = 8
+
+var (
+	// ErrIndexCycle is returned by WalkIndex when an index references itself
+	// directly or through nested indexes.
+	ErrIndexCycle = errors.New("index cycle detected")
+	// ErrIndexTooDeep is returned by WalkIndex when the nesting of indexes
+	// exceeds the limit.
+	ErrIndexTooDeep = errors.New("index nesting too deep")
+
+	// SkipIndex is returned by the function passed to WalkIndex to skip the
+	// nested index being visited.
+	SkipIndex = errors.New("skip this index")
+	// SkipAll is returned by the function passed to WalkIndex to stop the
+	// walk, which then returns nil.
+	SkipAll = errors.New("skip everything and stop the walk")
+)
+
+// IsIndex returns true if desc describes an image index or a manifest list.
+func IsIndex(desc ocispec.Descriptor) bool {
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageIndex, docker.MediaTypeManifestList:
+		return true
+	default:
+		return false
+	}
+}
+
+// WalkIndex walks the index described by root and its nested indexes in
+// depth-first order, and calls fn on each descriptor listed by the indexes.
+// The nested indexes are walked after fn is called on them, unless fn
+// returns SkipIndex. If fn returns SkipAll, the walk stops and WalkIndex
+// returns nil. Other errors returned by fn stop the walk and are returned.
+//
+// The nested indexes are walked up to maxDepth levels below root, and an
+// error wrapping ErrIndexTooDeep is returned beyond. If maxDepth is less than
+// or equal to 0, DefaultMaxIndexDepth is used. An error wrapping
+// ErrIndexCycle is returned if an index references itself.
+func WalkIndex(ctx context.Context, fetcher content.Fetcher, root ocispec.Descriptor, maxDepth int, fn func(desc ocispec.Descriptor) error) error {
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxIndexDepth
+	}
+
+	// path holds the indexes being walked
+	path := make(map[descriptor.Descriptor]bool)
+	var walk func(index ocispec.Descriptor, depth int) error
+	walk = func(index ocispec.Descriptor, depth int) error {
+		key := descriptor.FromOCI(index)
+		if path[key] {
+			return fmt.Errorf("%s: %s: %w", index.Digest, index.MediaType, ErrIndexCycle)
+		}
+		if depth > maxDepth {
+			return fmt.Errorf("%s: %s: depth %d exceeds %d: %w", index.Digest, index.MediaType, depth, maxDepth, ErrIndexTooDeep)
+		}
+		path[key] = true
+		defer delete(path, key)
+
+		manifests, err := content.Successors(ctx, fetcher, index)
+		if err != nil {
+			return err
+		}
+		for _, m := range manifests {
+			if !IsIndex(m) && !descriptor.IsManifest(m) {
+				// skip the blobs, if any
+				continue
+			}
+			if err := fn(m); err != nil {
+				if err == SkipIndex && IsIndex(m) {
+					continue
+				}
+				return err
+			}
+			if IsIndex(m) {
+				if err := walk(m, depth+1); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(root, 0); err != nil && err != SkipAll {
+		return err
+	}
+	return nil
+}
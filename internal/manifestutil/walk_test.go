@@ -0,0 +1,95 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifestutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/cas"
+)
+
+func TestWalkIndex(t *testing.T) {
+	ctx := context.Background()
+	storage := cas.NewMemory()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		}
+		if err := storage.Push(ctx, desc, bytes.NewReader(blob)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	pushIndex := func(manifests ...ocispec.Descriptor) ocispec.Descriptor {
+		indexJSON, err := json.Marshal(ocispec.Index{Manifests: manifests})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return push(ocispec.MediaTypeImageIndex, indexJSON)
+	}
+	foo := push(ocispec.MediaTypeImageManifest, []byte(`{"layers":[],"foo":1}`))
+	bar := push(ocispec.MediaTypeImageManifest, []byte(`{"layers":[],"bar":1}`))
+	nested := pushIndex(bar)
+	root := pushIndex(nested, foo)
+
+	var visited []ocispec.Descriptor
+	if err := WalkIndex(ctx, storage, root, 0, func(desc ocispec.Descriptor) error {
+		visited = append(visited, desc)
+		return nil
+	}); err != nil {
+		t.Fatal("WalkIndex() error =", err)
+	}
+	if want := []ocispec.Descriptor{nested, bar, foo}; !reflect.DeepEqual(visited, want) {
+		t.Errorf("WalkIndex() visited = %v, want %v", visited, want)
+	}
+
+	visited = nil
+	if err := WalkIndex(ctx, storage, root, 0, func(desc ocispec.Descriptor) error {
+		visited = append(visited, desc)
+		if IsIndex(desc) {
+			return SkipIndex
+		}
+		return SkipAll
+	}); err != nil {
+		t.Fatal("WalkIndex() error =", err)
+	}
+	if want := []ocispec.Descriptor{nested, foo}; !reflect.DeepEqual(visited, want) {
+		t.Errorf("WalkIndex() visited = %v, want %v", visited, want)
+	}
+
+	err := WalkIndex(ctx, storage, root, 1, func(desc ocispec.Descriptor) error {
+		return nil
+	})
+	if err != nil {
+		t.Errorf("WalkIndex() error = %v, want nil", err)
+	}
+	err = WalkIndex(ctx, storage, pushIndex(root), 1, func(desc ocispec.Descriptor) error {
+		return nil
+	})
+	if !errors.Is(err, ErrIndexTooDeep) {
+		t.Errorf("WalkIndex() error = %v, want %v", err, ErrIndexTooDeep)
+	}
+}
@@ -20,11 +20,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/internal/docker"
+	"oras.land/oras-go/v2/internal/manifestutil"
 )
 
 // Match checks whether the current platform matches the target platform.
@@ -56,6 +58,32 @@ func Match(got *ocispec.Platform, want *ocispec.Platform) bool {
 	return true
 }
 
+// Parse parses the platform specifier in the format of
+// `os/arch[/variant][:os_version]`, such as `linux/arm64/v8` or
+// `windows/amd64:10.0.20348.1726`.
+func Parse(specifier string) (*ocispec.Platform, error) {
+	var p ocispec.Platform
+	parts, osVersion, _ := strings.Cut(specifier, ":")
+	p.OSVersion = osVersion
+	fields := strings.Split(parts, "/")
+	switch len(fields) {
+	case 3:
+		p.Variant = fields[2]
+		fallthrough
+	case 2:
+		p.OS, p.Architecture = fields[0], fields[1]
+	default:
+		return nil, fmt.Errorf("%q: invalid platform: expected os/arch[/variant][:os_version]", specifier)
+	}
+	if p.OS == "" || p.Architecture == "" || (len(fields) == 3 && p.Variant == "") {
+		return nil, fmt.Errorf("%q: invalid platform: empty field", specifier)
+	}
+	if strings.Contains(specifier, ":") && p.OSVersion == "" {
+		return nil, fmt.Errorf("%q: invalid platform: empty os version", specifier)
+	}
+	return &p, nil
+}
+
 // isSubset returns true if all items in slice A are present in slice B.
 func isSubset(a, b []string) bool {
 	set := make(map[string]bool, len(b))
@@ -71,24 +99,70 @@ func isSubset(a, b []string) bool {
 	return true
 }
 
+// NotFoundError is returned by SelectManifest when no manifest matches the
+// target platform.
+type NotFoundError struct {
+	// Descriptor describes the manifest or the manifest list searched.
+	Descriptor ocispec.Descriptor
+	// Platform is the target platform.
+	Platform *ocispec.Platform
+	// Available lists the platforms found.
+	Available []ocispec.Platform
+
+	message string
+}
+
+// Error returns the error message.
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s: %v: %s", e.Descriptor.Digest, errdef.ErrNotFound, e.message)
+}
+
+// Unwrap returns errdef.ErrNotFound.
+func (e *NotFoundError) Unwrap() error {
+	return errdef.ErrNotFound
+}
+
 // SelectManifest implements platform filter and returns the descriptor of the
 // first matched manifest if the root is a manifest list. If the root is a
 // manifest, then return the root descriptor if platform matches.
+// Nested manifest lists are searched in depth-first order, up to
+// manifestutil.DefaultMaxIndexDepth levels below the root.
+// A *NotFoundError is returned if no manifest matches.
 func SelectManifest(ctx context.Context, src content.ReadOnlyStorage, root ocispec.Descriptor, p *ocispec.Platform) (ocispec.Descriptor, error) {
 	switch root.MediaType {
 	case docker.MediaTypeManifestList, ocispec.MediaTypeImageIndex:
-		manifests, err := content.Successors(ctx, src, root)
-		if err != nil {
-			return ocispec.Descriptor{}, err
-		}
-
 		// platform filter
-		for _, m := range manifests {
+		var selected ocispec.Descriptor
+		var available []ocispec.Platform
+		if err := manifestutil.WalkIndex(ctx, src, root, 0, func(m ocispec.Descriptor) error {
+			if manifestutil.IsIndex(m) {
+				if m.Platform != nil && !Match(m.Platform, p) {
+					available = append(available, *m.Platform)
+					return manifestutil.SkipIndex
+				}
+				return nil
+			}
+			if m.Platform == nil {
+				return nil
+			}
 			if Match(m.Platform, p) {
-				return m, nil
+				selected = m
+				return manifestutil.SkipAll
 			}
+			available = append(available, *m.Platform)
+			return nil
+		}); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		if selected.Digest != "" {
+			return selected, nil
+		}
+		return ocispec.Descriptor{}, &NotFoundError{
+			Descriptor: root,
+			Platform:   p,
+			Available:  available,
+			message:    "no matching manifest was found in the manifest list",
 		}
-		return ocispec.Descriptor{}, fmt.Errorf("%s: %w: no matching manifest was found in the manifest list", root.Digest, errdef.ErrNotFound)
 	case docker.MediaTypeManifest, ocispec.MediaTypeImageManifest:
 		descs, err := content.Successors(ctx, src, root)
 		if err != nil {
@@ -108,7 +182,12 @@ func SelectManifest(ctx context.Context, src content.ReadOnlyStorage, root ocisp
 		if Match(cfgPlatform, p) {
 			return root, nil
 		}
-		return ocispec.Descriptor{}, fmt.Errorf("%s: %w: platform in manifest does not match target platform", root.Digest, errdef.ErrNotFound)
+		return ocispec.Descriptor{}, &NotFoundError{
+			Descriptor: root,
+			Platform:   p,
+			Available:  []ocispec.Platform{*cfgPlatform},
+			message:    "platform in manifest does not match target platform",
+		}
 	default:
 		return ocispec.Descriptor{}, fmt.Errorf("%s: %s: %w", root.Digest, root.MediaType, errdef.ErrUnsupported)
 	}
@@ -134,3 +213,26 @@ func getPlatformFromConfig(ctx context.Context, src content.ReadOnlyStorage, des
 
 	return &platform, nil
 }
+
+// FromManifest returns the platform recorded in the config of the image
+// manifest described by desc.
+func FromManifest(ctx context.Context, src content.ReadOnlyStorage, desc ocispec.Descriptor) (*ocispec.Platform, error) {
+	var configMediaType string
+	switch desc.MediaType {
+	case docker.MediaTypeManifest:
+		configMediaType = docker.MediaTypeConfig
+	case ocispec.MediaTypeImageManifest:
+		configMediaType = ocispec.MediaTypeImageConfig
+	default:
+		return nil, fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, errdef.ErrUnsupported)
+	}
+	manifestJSON, err := content.FetchAll(ctx, src, desc)
+	if err != nil {
+		return nil, err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest %s: %w", desc.Digest, err)
+	}
+	return getPlatformFromConfig(ctx, src, manifest.Config, configMediaType)
+}
@@ -30,6 +30,7 @@ import (
 	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/internal/cas"
 	"oras.land/oras-go/v2/internal/docker"
+	"oras.land/oras-go/v2/internal/manifestutil"
 )
 
 func TestMatch(t *testing.T) {
@@ -354,3 +355,93 @@ func TestSelectManifest(t *testing.T) {
 		t.Fatalf("SelectManifest() error = %v, wantErr %v", err, expected)
 	}
 }
+
+func TestFromManifest(t *testing.T) {
+	storage := cas.NewMemory()
+	ctx := context.Background()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		}
+		if err := storage.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal("Push() error =", err)
+		}
+		return desc
+	}
+	subject := push(ocispec.MediaTypeImageLayer, []byte("subject"))
+	config := push(ocispec.MediaTypeImageConfig, []byte(`{"architecture":"arm64","os":"linux","variant":"v8"}`))
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Subject:   &subject,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := push(ocispec.MediaTypeImageManifest, manifestJSON)
+
+	got, err := FromManifest(ctx, storage, manifest)
+	if err != nil {
+		t.Fatal("FromManifest() error =", err)
+	}
+	want := &ocispec.Platform{
+		Architecture: "arm64",
+		OS:           "linux",
+		Variant:      "v8",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromManifest() = %v, want %v", got, want)
+	}
+
+	// test unsupported media type
+	if _, err := FromManifest(ctx, storage, config); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("FromManifest() error = %v, wantErr %v", err, errdef.ErrUnsupported)
+	}
+}
+
+func TestSelectManifest_NestedIndex(t *testing.T) {
+	ctx := context.Background()
+	storage := cas.NewMemory()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(blob),
+			Size:      int64(len(blob)),
+		}
+		if err := storage.Push(ctx, desc, bytes.NewReader(blob)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+			t.Fatal(err)
+		}
+		return desc
+	}
+	pushIndex := func(manifests ...ocispec.Descriptor) ocispec.Descriptor {
+		indexJSON, err := json.Marshal(ocispec.Index{Manifests: manifests})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return push(ocispec.MediaTypeImageIndex, indexJSON)
+	}
+	target := ocispec.Platform{Architecture: "amd64", OS: "linux"}
+	manifest := push(ocispec.MediaTypeImageManifest, []byte(`{"layers":[]}`))
+	manifest.Platform = &target
+
+	// the manifest is found in a nested index
+	root := pushIndex(pushIndex(manifest))
+	got, err := SelectManifest(ctx, storage, root, &target)
+	if err != nil {
+		t.Fatal("SelectManifest() error =", err)
+	}
+	if got.Digest != manifest.Digest {
+		t.Errorf("SelectManifest() = %v, want %v", got.Digest, manifest.Digest)
+	}
+
+	// the nesting is limited
+	root = manifest
+	for i := 0; i <= manifestutil.DefaultMaxIndexDepth+1; i++ {
+		root = pushIndex(root)
+	}
+	if _, err := SelectManifest(ctx, storage, root, &target); !errors.Is(err, manifestutil.ErrIndexTooDeep) {
+		t.Errorf("SelectManifest() error = %v, want %v", err, manifestutil.ErrIndexTooDeep)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pool provides pools of byte buffers shared across the copy paths
+// to reduce allocations.
+package pool
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// DefaultFileBufferSize is the default size of the buffers in File.
+	// The buffer size should be larger than or equal to 128 KiB for
+	// performance considerations; 1 MiB is chosen so that there will be less
+	// disk I/O.
+	DefaultFileBufferSize = 1 << 20
+	// DefaultStreamBufferSize is the default size of the buffers in Stream,
+	// the same as the one allocated by io.Copy.
+	DefaultStreamBufferSize = 32 << 10
+)
+
+var (
+	// File is the pool of the buffers for copying content between files.
+	File = New(DefaultFileBufferSize)
+	// Stream is the pool of the buffers for copying content between
+	// streams, such as network connections and hashes.
+	Stream = New(DefaultStreamBufferSize)
+)
+
+// Buffers is a pool of byte buffers of a configurable size.
+// Buffers is safe for concurrent use.
+type Buffers struct {
+	defaultSize int
+	size        atomic.Int64
+	pool        sync.Pool
+}
+
+// New creates a pool of buffers of the given default size.
+func New(defaultSize int) *Buffers {
+	b := &Buffers{defaultSize: defaultSize}
+	b.size.Store(int64(defaultSize))
+	return b
+}
+
+// Size returns the size of the buffers.
+func (b *Buffers) Size() int {
+	return int(b.size.Load())
+}
+
+// SetSize sets the size of the buffers got afterwards. Buffers of the
+// previous size are dropped when put back.
+// If size is less than or equal to 0, the default size is used.
+func (b *Buffers) SetSize(size int) {
+	if size <= 0 {
+		size = b.defaultSize
+	}
+	b.size.Store(int64(size))
+}
+
+// Get returns a buffer of the current size.
+func (b *Buffers) Get() *[]byte {
+	size := b.Size()
+	if v := b.pool.Get(); v != nil {
+		if buf := v.(*[]byte); len(*buf) == size {
+			return buf
+		}
+	}
+	buf := make([]byte, size)
+	return &buf
+}
+
+// Put puts the buffer back to the pool.
+func (b *Buffers) Put(buf *[]byte) {
+	if len(*buf) == b.Size() {
+		b.pool.Put(buf)
+	}
+}
+
+// Copy copies from src to dst like io.Copy with a pooled buffer, which is
+// used unless src implements io.WriterTo or dst implements io.ReaderFrom.
+func (b *Buffers) Copy(dst io.Writer, src io.Reader) (int64, error) {
+	buf := b.Get()
+	defer b.Put(buf)
+	return io.CopyBuffer(dst, src, *buf)
+}
+
+// Discard reads src until EOF with a pooled buffer, and returns the number
+// of bytes read.
+func (b *Buffers) Discard(src io.Reader) (int64, error) {
+	// hide io.Discard's ReaderFrom, which reads with small buffers
+	return b.Copy(struct{ io.Writer }{io.Discard}, src)
+}
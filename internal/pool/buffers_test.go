@@ -0,0 +1,69 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pool
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBuffers(t *testing.T) {
+	b := New(16)
+	if got := b.Size(); got != 16 {
+		t.Fatalf("Size() = %d, want 16", got)
+	}
+	buf := b.Get()
+	if len(*buf) != 16 {
+		t.Fatalf("len(Get()) = %d, want 16", len(*buf))
+	}
+	b.Put(buf)
+
+	// buffers of the previous size are not reused
+	b.SetSize(32)
+	old := make([]byte, 16)
+	b.Put(&old)
+	if buf := b.Get(); len(*buf) != 32 {
+		t.Errorf("len(Get()) = %d, want 32", len(*buf))
+	}
+
+	// non-positive sizes reset to the default
+	b.SetSize(0)
+	if got := b.Size(); got != 16 {
+		t.Errorf("Size() = %d, want 16", got)
+	}
+}
+
+func TestBuffers_Copy(t *testing.T) {
+	b := New(4)
+	src := strings.Repeat("hello world", 10)
+	var dst bytes.Buffer
+	n, err := b.Copy(struct{ *bytes.Buffer }{&dst}, strings.NewReader(src))
+	if err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+	if n != int64(len(src)) || dst.String() != src {
+		t.Errorf("Copy() = %d, %q, want %d, %q", n, dst.String(), len(src), src)
+	}
+
+	n, err = b.Discard(struct{ *strings.Reader }{strings.NewReader(src)})
+	if err != nil {
+		t.Fatal("Discard() error =", err)
+	}
+	if n != int64(len(src)) {
+		t.Errorf("Discard() = %d, want %d", n, len(src))
+	}
+}
@@ -48,6 +48,25 @@ func (m *Memory) Tag(_ context.Context, desc ocispec.Descriptor, reference strin
 	return nil
 }
 
+// Untag removes the reference from the index.
+func (m *Memory) Untag(reference string) {
+	m.index.Delete(reference)
+}
+
+// UntagAll removes all the references tagging the given descriptor, and
+// returns the removed references.
+func (m *Memory) UntagAll(target ocispec.Descriptor) []string {
+	var refs []string
+	m.index.Range(func(key, value interface{}) bool {
+		if value.(ocispec.Descriptor).Digest == target.Digest {
+			refs = append(refs, key.(string))
+			m.index.Delete(key)
+		}
+		return true
+	})
+	return refs
+}
+
 // Map dumps the memory into a built-in map structure.
 // Like other operations, calling Map() is go-routine safe. However, it does not
 // necessarily correspond to any consistent snapshot of the storage contents.
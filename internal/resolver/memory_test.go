@@ -20,6 +20,7 @@ import (
 	_ "crypto/sha256"
 	"errors"
 	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/opencontainers/go-digest"
@@ -67,3 +68,46 @@ func TestMemoryNotFound(t *testing.T) {
 		t.Errorf("Memory.Resolve() error = %v, want %v", err, errdef.ErrNotFound)
 	}
 }
+
+func TestMemoryUntag(t *testing.T) {
+	content := []byte("hello world")
+	desc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(content),
+		Size:      int64(len(content)),
+	}
+	other := []byte("foo")
+	otherDesc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(other),
+		Size:      int64(len(other)),
+	}
+
+	s := NewMemory()
+	ctx := context.Background()
+	for _, ref := range []string{"foo", "bar", "baz"} {
+		if err := s.Tag(ctx, desc, ref); err != nil {
+			t.Fatal("Memory.Tag() error =", err)
+		}
+	}
+	if err := s.Tag(ctx, otherDesc, "other"); err != nil {
+		t.Fatal("Memory.Tag() error =", err)
+	}
+
+	s.Untag("foo")
+	if _, err := s.Resolve(ctx, "foo"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Memory.Resolve() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+
+	refs := s.UntagAll(desc)
+	sort.Strings(refs)
+	if want := []string{"bar", "baz"}; !reflect.DeepEqual(refs, want) {
+		t.Errorf("Memory.UntagAll() = %v, want %v", refs, want)
+	}
+	want := map[string]ocispec.Descriptor{
+		"other": otherDesc,
+	}
+	if got := s.Map(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Memory.Map() = %v, want %v", got, want)
+	}
+}
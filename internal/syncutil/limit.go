@@ -25,7 +25,8 @@ import (
 // LimitedRegion provides a way to bound concurrent access to a code block.
 type LimitedRegion struct {
 	ctx     context.Context
-	limiter *semaphore.Weighted
+	acquire func(ctx context.Context) error
+	release func()
 	ended   bool
 }
 
@@ -34,9 +35,20 @@ func LimitRegion(ctx context.Context, limiter *semaphore.Weighted) *LimitedRegio
 	if limiter == nil {
 		return nil
 	}
+	return LimitRegionFunc(ctx, func(ctx context.Context) error {
+		return limiter.Acquire(ctx, 1)
+	}, func() {
+		limiter.Release(1)
+	})
+}
+
+// LimitRegionFunc creates a new LimitedRegion, which calls acquire on start
+// and release on end.
+func LimitRegionFunc(ctx context.Context, acquire func(ctx context.Context) error, release func()) *LimitedRegion {
 	return &LimitedRegion{
 		ctx:     ctx,
-		limiter: limiter,
+		acquire: acquire,
+		release: release,
 		ended:   true,
 	}
 }
@@ -46,7 +58,7 @@ func (lr *LimitedRegion) Start() error {
 	if lr == nil || !lr.ended {
 		return nil
 	}
-	if err := lr.limiter.Acquire(lr.ctx, 1); err != nil {
+	if err := lr.acquire(lr.ctx); err != nil {
 		return err
 	}
 	lr.ended = false
@@ -58,18 +70,46 @@ func (lr *LimitedRegion) End() {
 	if lr == nil || lr.ended {
 		return
 	}
-	lr.limiter.Release(1)
+	lr.release()
 	lr.ended = true
 }
 
+// Limiter limits concurrent invocations on items.
+type Limiter[T any] interface {
+	// Acquire blocks until a slot for the item is available.
+	Acquire(ctx context.Context, t T) error
+	// Release releases the slot acquired for the item.
+	Release(t T)
+}
+
 // GoFunc represents a function that can be invoked by Go.
 type GoFunc[T any] func(ctx context.Context, region *LimitedRegion, t T) error
 
 // Go concurrently invokes fn on items.
 func Go[T any](ctx context.Context, limiter *semaphore.Weighted, fn GoFunc[T], items ...T) error {
+	return goRegions(ctx, func(T) *LimitedRegion {
+		return LimitRegion(ctx, limiter)
+	}, fn, items...)
+}
+
+// GoLimited concurrently invokes fn on items, with the concurrency limited
+// by limiter.
+func GoLimited[T any](ctx context.Context, limiter Limiter[T], fn GoFunc[T], items ...T) error {
+	return goRegions(ctx, func(t T) *LimitedRegion {
+		return LimitRegionFunc(ctx, func(ctx context.Context) error {
+			return limiter.Acquire(ctx, t)
+		}, func() {
+			limiter.Release(t)
+		})
+	}, fn, items...)
+}
+
+// goRegions concurrently invokes fn on items, each in the region created by
+// newRegion.
+func goRegions[T any](ctx context.Context, newRegion func(t T) *LimitedRegion, fn GoFunc[T], items ...T) error {
 	eg, egCtx := errgroup.WithContext(ctx)
 	for _, item := range items {
-		region := LimitRegion(ctx, limiter)
+		region := newRegion(item)
 		if err := region.Start(); err != nil {
 			return err
 		}
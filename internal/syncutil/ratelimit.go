@@ -0,0 +1,75 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncutil
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter limits the rate of units, such as bytes or requests,
+// transferred by scheduling the transfers virtually, allowing bursts of up to
+// one second of the rate. It is safe for concurrent use.
+type RateLimiter struct {
+	rate int64
+	mu   sync.Mutex
+	next time.Time
+}
+
+// NewRateLimiter creates a RateLimiter with the given rate in units per
+// second. Returns nil if the rate is not limited.
+func NewRateLimiter(rate int64) *RateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &RateLimiter{rate: rate}
+}
+
+// Rate returns the rate in units per second, or 0 if l is nil.
+func (l *RateLimiter) Rate() int64 {
+	if l == nil {
+		return 0
+	}
+	return l.rate
+}
+
+// Wait records n units transferred, and waits until they are within the
+// rate. A nil limiter never waits.
+func (l *RateLimiter) Wait(ctx context.Context, n int) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if earliest := now.Add(-time.Second); l.next.Before(earliest) {
+		l.next = earliest
+	}
+	l.next = l.next.Add(time.Duration(float64(n) / float64(l.rate) * float64(time.Second)))
+	delay := l.next.Sub(now)
+	l.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
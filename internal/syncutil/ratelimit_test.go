@@ -0,0 +1,58 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncutil
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Wait(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewRateLimiter(10000)
+
+	// a burst of one second of the rate does not wait
+	start := time.Now()
+	if err := limiter.Wait(ctx, 10000); err != nil {
+		t.Fatalf("RateLimiter.Wait() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("RateLimiter.Wait() took %v, want no wait", elapsed)
+	}
+
+	// exceeding the burst waits
+	start = time.Now()
+	if err := limiter.Wait(ctx, 1000); err != nil {
+		t.Fatalf("RateLimiter.Wait() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("RateLimiter.Wait() took %v, want about 100ms", elapsed)
+	}
+
+	// waiting is cancelled with the context
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := limiter.Wait(cancelCtx, 10000); err != context.Canceled {
+		t.Errorf("RateLimiter.Wait() error = %v, want %v", err, context.Canceled)
+	}
+
+	// nil limiter never waits
+	var nilLimiter *RateLimiter
+	if err := nilLimiter.Wait(ctx, 1<<30); err != nil {
+		t.Errorf("RateLimiter.Wait() error = %v", err)
+	}
+}
@@ -0,0 +1,278 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package interop provides adapters for passing artifacts between ORAS and
+// github.com/google/go-containerregistry (ggcr) without round-tripping through
+// a registry.
+//
+// ggcr is not a dependency of this module. Instead, Image, Index and Layer
+// expose the method sets of the ggcr v1.Image, v1.ImageIndex and v1.Layer
+// interfaces with OCI types in place of the ggcr ones, and Source describes
+// the ggcr side consumed by Copy. Bridging the two only takes converting
+// digest.Digest values to and from v1.Hash in the caller.
+package interop
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/docker"
+)
+
+// Image is a read-only view of an image manifest stored in an ORAS storage,
+// such as a remote.Repository or a local store, shaped after the ggcr
+// v1.Image interface.
+// Since the ggcr methods take no context, the context given to NewImage is
+// used for all fetches.
+type Image struct {
+	ctx      context.Context
+	fetcher  content.Fetcher
+	desc     ocispec.Descriptor
+	raw      []byte
+	manifest ocispec.Manifest
+}
+
+// NewImage fetches the image manifest described by desc from fetcher and
+// returns an Image view of it.
+func NewImage(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) (*Image, error) {
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageManifest, docker.MediaTypeManifest:
+	default:
+		return nil, fmt.Errorf("%s: %s: not an image manifest: %w", desc.Digest, desc.MediaType, errdef.ErrUnsupported)
+	}
+	raw, err := content.FetchAll(ctx, fetcher, desc)
+	if err != nil {
+		return nil, err
+	}
+	img := &Image{
+		ctx:     ctx,
+		fetcher: fetcher,
+		desc:    desc,
+		raw:     raw,
+	}
+	// OCI manifest schema can be used to unmarshal docker manifest
+	if err := json.Unmarshal(raw, &img.manifest); err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, err)
+	}
+	return img, nil
+}
+
+// Descriptor returns the descriptor of the image manifest.
+func (img *Image) Descriptor() ocispec.Descriptor {
+	return img.desc
+}
+
+// MediaType returns the media type of the image manifest.
+func (img *Image) MediaType() (string, error) {
+	return img.desc.MediaType, nil
+}
+
+// Size returns the size of the image manifest.
+func (img *Image) Size() (int64, error) {
+	return img.desc.Size, nil
+}
+
+// Digest returns the digest of the image manifest.
+func (img *Image) Digest() (digest.Digest, error) {
+	return img.desc.Digest, nil
+}
+
+// RawManifest returns the serialized bytes of the image manifest.
+func (img *Image) RawManifest() ([]byte, error) {
+	return img.raw, nil
+}
+
+// Manifest returns the parsed image manifest.
+func (img *Image) Manifest() (*ocispec.Manifest, error) {
+	manifest := img.manifest
+	return &manifest, nil
+}
+
+// ConfigName returns the digest of the config blob.
+func (img *Image) ConfigName() (digest.Digest, error) {
+	return img.manifest.Config.Digest, nil
+}
+
+// RawConfigFile returns the serialized bytes of the config blob.
+func (img *Image) RawConfigFile() ([]byte, error) {
+	return content.FetchAll(img.ctx, img.fetcher, img.manifest.Config)
+}
+
+// Layers returns the layers of the image, ordered from the base layer.
+func (img *Image) Layers() ([]*Layer, error) {
+	layers := make([]*Layer, 0, len(img.manifest.Layers))
+	for _, desc := range img.manifest.Layers {
+		layers = append(layers, img.layer(desc))
+	}
+	return layers, nil
+}
+
+// LayerByDigest returns the layer or the config blob with the given digest.
+func (img *Image) LayerByDigest(dgst digest.Digest) (*Layer, error) {
+	if img.manifest.Config.Digest == dgst {
+		return img.layer(img.manifest.Config), nil
+	}
+	for _, desc := range img.manifest.Layers {
+		if desc.Digest == dgst {
+			return img.layer(desc), nil
+		}
+	}
+	return nil, fmt.Errorf("%s: layer of image %s: %w", dgst, img.desc.Digest, errdef.ErrNotFound)
+}
+
+// layer returns a Layer view of the blob.
+func (img *Image) layer(desc ocispec.Descriptor) *Layer {
+	return &Layer{
+		ctx:     img.ctx,
+		fetcher: img.fetcher,
+		desc:    desc,
+	}
+}
+
+// Layer is a read-only view of a blob stored in an ORAS storage, shaped after
+// the ggcr v1.Layer interface.
+type Layer struct {
+	ctx     context.Context
+	fetcher content.Fetcher
+	desc    ocispec.Descriptor
+}
+
+// Descriptor returns the descriptor of the layer.
+func (l *Layer) Descriptor() ocispec.Descriptor {
+	return l.desc
+}
+
+// Digest returns the digest of the compressed layer.
+func (l *Layer) Digest() (digest.Digest, error) {
+	return l.desc.Digest, nil
+}
+
+// Size returns the size of the compressed layer.
+func (l *Layer) Size() (int64, error) {
+	return l.desc.Size, nil
+}
+
+// MediaType returns the media type of the layer.
+func (l *Layer) MediaType() (string, error) {
+	return l.desc.MediaType, nil
+}
+
+// Compressed returns the content of the layer as stored, verified against
+// its descriptor while being read.
+func (l *Layer) Compressed() (io.ReadCloser, error) {
+	return content.VerifyFetcher(l.fetcher).Fetch(l.ctx, l.desc)
+}
+
+// Index is a read-only view of an image index stored in an ORAS storage,
+// shaped after the ggcr v1.ImageIndex interface.
+// Since the ggcr methods take no context, the context given to NewIndex is
+// used for all fetches.
+type Index struct {
+	ctx     context.Context
+	fetcher content.Fetcher
+	desc    ocispec.Descriptor
+	raw     []byte
+	index   ocispec.Index
+}
+
+// NewIndex fetches the image index described by desc from fetcher and returns
+// an Index view of it.
+func NewIndex(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) (*Index, error) {
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageIndex, docker.MediaTypeManifestList:
+	default:
+		return nil, fmt.Errorf("%s: %s: not an image index: %w", desc.Digest, desc.MediaType, errdef.ErrUnsupported)
+	}
+	raw, err := content.FetchAll(ctx, fetcher, desc)
+	if err != nil {
+		return nil, err
+	}
+	idx := &Index{
+		ctx:     ctx,
+		fetcher: fetcher,
+		desc:    desc,
+		raw:     raw,
+	}
+	// OCI index schema can be used to unmarshal docker manifest list
+	if err := json.Unmarshal(raw, &idx.index); err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, err)
+	}
+	return idx, nil
+}
+
+// Descriptor returns the descriptor of the image index.
+func (idx *Index) Descriptor() ocispec.Descriptor {
+	return idx.desc
+}
+
+// MediaType returns the media type of the image index.
+func (idx *Index) MediaType() (string, error) {
+	return idx.desc.MediaType, nil
+}
+
+// Size returns the size of the image index.
+func (idx *Index) Size() (int64, error) {
+	return idx.desc.Size, nil
+}
+
+// Digest returns the digest of the image index.
+func (idx *Index) Digest() (digest.Digest, error) {
+	return idx.desc.Digest, nil
+}
+
+// RawManifest returns the serialized bytes of the image index.
+func (idx *Index) RawManifest() ([]byte, error) {
+	return idx.raw, nil
+}
+
+// IndexManifest returns the parsed image index.
+func (idx *Index) IndexManifest() (*ocispec.Index, error) {
+	index := idx.index
+	return &index, nil
+}
+
+// Image returns the child image manifest with the given digest.
+func (idx *Index) Image(dgst digest.Digest) (*Image, error) {
+	desc, err := idx.child(dgst)
+	if err != nil {
+		return nil, err
+	}
+	return NewImage(idx.ctx, idx.fetcher, desc)
+}
+
+// ImageIndex returns the child image index with the given digest.
+func (idx *Index) ImageIndex(dgst digest.Digest) (*Index, error) {
+	desc, err := idx.child(dgst)
+	if err != nil {
+		return nil, err
+	}
+	return NewIndex(idx.ctx, idx.fetcher, desc)
+}
+
+// child returns the descriptor of the child manifest with the given digest.
+func (idx *Index) child(dgst digest.Digest) (ocispec.Descriptor, error) {
+	for _, desc := range idx.index.Manifests {
+		if desc.Digest == dgst {
+			return desc, nil
+		}
+	}
+	return ocispec.Descriptor{}, fmt.Errorf("%s: child of index %s: %w", dgst, idx.desc.Digest, errdef.ErrNotFound)
+}
@@ -0,0 +1,195 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interop
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// testGraph is an index of a single image pushed into a memory store.
+type testGraph struct {
+	store    *memory.Store
+	config   []byte
+	layers   [][]byte
+	manifest ocispec.Descriptor
+	index    ocispec.Descriptor
+}
+
+func push(t *testing.T, s *memory.Store, mediaType string, blob []byte) ocispec.Descriptor {
+	t.Helper()
+	desc := content.NewDescriptorFromBytes(mediaType, blob)
+	if err := s.Push(context.Background(), desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+	return desc
+}
+
+func newTestGraph(t *testing.T) *testGraph {
+	g := &testGraph{
+		store:  memory.New(),
+		config: []byte(`{"architecture":"amd64","os":"linux"}`),
+		layers: [][]byte{[]byte("foo"), []byte("bar")},
+	}
+	manifest := ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    push(t, g.store, ocispec.MediaTypeImageConfig, g.config),
+	}
+	for _, layer := range g.layers {
+		manifest.Layers = append(manifest.Layers, push(t, g.store, ocispec.MediaTypeImageLayer, layer))
+	}
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.manifest = push(t, g.store, ocispec.MediaTypeImageManifest, manifestJSON)
+	index := ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{g.manifest},
+	}
+	indexJSON, err := json.Marshal(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.index = push(t, g.store, ocispec.MediaTypeImageIndex, indexJSON)
+	return g
+}
+
+func TestImage(t *testing.T) {
+	ctx := context.Background()
+	g := newTestGraph(t)
+
+	img, err := NewImage(ctx, g.store, g.manifest)
+	if err != nil {
+		t.Fatal("NewImage() error =", err)
+	}
+	if dgst, _ := img.Digest(); dgst != g.manifest.Digest {
+		t.Errorf("Image.Digest() = %v, want %v", dgst, g.manifest.Digest)
+	}
+	if mediaType, _ := img.MediaType(); mediaType != ocispec.MediaTypeImageManifest {
+		t.Errorf("Image.MediaType() = %v, want %v", mediaType, ocispec.MediaTypeImageManifest)
+	}
+	raw, _ := img.RawManifest()
+	if got := digest.FromBytes(raw); got != g.manifest.Digest {
+		t.Errorf("Image.RawManifest() digest = %v, want %v", got, g.manifest.Digest)
+	}
+	config, err := img.RawConfigFile()
+	if err != nil {
+		t.Fatal("Image.RawConfigFile() error =", err)
+	}
+	if !bytes.Equal(config, g.config) {
+		t.Errorf("Image.RawConfigFile() = %s, want %s", config, g.config)
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatal("Image.Layers() error =", err)
+	}
+	if len(layers) != len(g.layers) {
+		t.Fatalf("len(Image.Layers()) = %d, want %d", len(layers), len(g.layers))
+	}
+	for i, layer := range layers {
+		rc, err := layer.Compressed()
+		if err != nil {
+			t.Fatal("Layer.Compressed() error =", err)
+		}
+		got, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal("io.ReadAll() error =", err)
+		}
+		if !bytes.Equal(got, g.layers[i]) {
+			t.Errorf("Layer.Compressed() = %s, want %s", got, g.layers[i])
+		}
+	}
+
+	dgst, _ := layers[1].Digest()
+	layer, err := img.LayerByDigest(dgst)
+	if err != nil {
+		t.Fatal("Image.LayerByDigest() error =", err)
+	}
+	if !content.Equal(layer.Descriptor(), layers[1].Descriptor()) {
+		t.Errorf("Image.LayerByDigest() = %v, want %v", layer.Descriptor(), layers[1].Descriptor())
+	}
+	if _, err := img.LayerByDigest(digest.FromString("baz")); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Image.LayerByDigest() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+
+	if _, err := NewImage(ctx, g.store, g.index); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("NewImage(index) error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+}
+
+func TestLayer_Compressed_mismatch(t *testing.T) {
+	ctx := context.Background()
+	blob := []byte("foo")
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+	fetcher := content.FetcherFunc(func(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader([]byte("bar"))), nil
+	})
+	layer := &Layer{ctx: ctx, fetcher: fetcher, desc: desc}
+	rc, err := layer.Compressed()
+	if err != nil {
+		t.Fatal("Layer.Compressed() error =", err)
+	}
+	defer rc.Close()
+	if _, err := io.ReadAll(rc); !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("io.ReadAll() error = %v, want %v", err, content.ErrMismatchedDigest)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	ctx := context.Background()
+	g := newTestGraph(t)
+
+	idx, err := NewIndex(ctx, g.store, g.index)
+	if err != nil {
+		t.Fatal("NewIndex() error =", err)
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		t.Fatal("Index.IndexManifest() error =", err)
+	}
+	if len(manifest.Manifests) != 1 || !content.Equal(manifest.Manifests[0], g.manifest) {
+		t.Errorf("Index.IndexManifest().Manifests = %v, want %v", manifest.Manifests, []ocispec.Descriptor{g.manifest})
+	}
+	img, err := idx.Image(g.manifest.Digest)
+	if err != nil {
+		t.Fatal("Index.Image() error =", err)
+	}
+	if !content.Equal(img.Descriptor(), g.manifest) {
+		t.Errorf("Index.Image() = %v, want %v", img.Descriptor(), g.manifest)
+	}
+	if _, err := idx.ImageIndex(g.manifest.Digest); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Index.ImageIndex() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+	if _, err := idx.Image(digest.FromString("baz")); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Index.Image() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+}
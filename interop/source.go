@@ -0,0 +1,122 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interop
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// Source is the ggcr side of an image or an image index to be passed to ORAS,
+// usually implemented by a few lines of glue over a ggcr v1.Image or
+// v1.ImageIndex.
+type Source interface {
+	// RawManifest returns the serialized bytes of the root manifest.
+	RawManifest() ([]byte, error)
+
+	// Blob returns the content with the given digest referenced by the root
+	// manifest or its descendants, including config blobs, layers and child
+	// manifests of indexes.
+	// An error matching errdef.ErrNotFound is expected if the content is
+	// missing.
+	Blob(ctx context.Context, dgst digest.Digest) (io.ReadCloser, error)
+}
+
+// NewStorage returns a read-only storage serving the graph of src, and the
+// descriptor of its root manifest.
+// The media type of the root manifest is read from its mediaType field, or
+// inferred from its content if the field is absent.
+func NewStorage(src Source) (content.ReadOnlyStorage, ocispec.Descriptor, error) {
+	raw, err := src.RawManifest()
+	if err != nil {
+		return nil, ocispec.Descriptor{}, err
+	}
+	var manifest struct {
+		MediaType string            `json:"mediaType"`
+		Manifests []json.RawMessage `json:"manifests"`
+	}
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, ocispec.Descriptor{}, fmt.Errorf("failed to parse root manifest: %w", err)
+	}
+	mediaType := manifest.MediaType
+	if mediaType == "" {
+		mediaType = ocispec.MediaTypeImageManifest
+		if manifest.Manifests != nil {
+			mediaType = ocispec.MediaTypeImageIndex
+		}
+	}
+	root := content.NewDescriptorFromBytes(mediaType, raw)
+	return &sourceStorage{
+		src:  src,
+		root: root,
+		raw:  raw,
+	}, root, nil
+}
+
+// Copy copies the graph of src into dst and returns the descriptor of its root
+// manifest. If ref is not empty, the root manifest is tagged with ref in dst.
+func Copy(ctx context.Context, src Source, dst oras.Target, ref string, opts oras.CopyGraphOptions) (ocispec.Descriptor, error) {
+	storage, root, err := NewStorage(src)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := oras.CopyGraph(ctx, storage, dst, root, opts); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if ref != "" {
+		if err := dst.Tag(ctx, root, ref); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+	return root, nil
+}
+
+// sourceStorage is a read-only storage serving the graph of a Source.
+type sourceStorage struct {
+	src  Source
+	root ocispec.Descriptor
+	raw  []byte
+}
+
+// Fetch fetches the content identified by the descriptor.
+func (s *sourceStorage) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	if target.Digest == s.root.Digest {
+		return io.NopCloser(bytes.NewReader(s.raw)), nil
+	}
+	return s.src.Blob(ctx, target.Digest)
+}
+
+// Exists returns true if the described content exists.
+func (s *sourceStorage) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	rc, err := s.Fetch(ctx, target)
+	if err != nil {
+		if errors.Is(err, errdef.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, rc.Close()
+}
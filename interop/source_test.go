@@ -0,0 +1,171 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interop
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// indexSource is a Source over an Index view, resembling the glue over a ggcr
+// v1.ImageIndex.
+type indexSource struct {
+	idx *Index
+}
+
+func (s *indexSource) RawManifest() ([]byte, error) {
+	return s.idx.RawManifest()
+}
+
+func (s *indexSource) Blob(_ context.Context, dgst digest.Digest) (io.ReadCloser, error) {
+	manifest, err := s.idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	for _, desc := range manifest.Manifests {
+		img, err := s.idx.Image(desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		if desc.Digest == dgst {
+			raw, err := img.RawManifest()
+			if err != nil {
+				return nil, err
+			}
+			return io.NopCloser(bytes.NewReader(raw)), nil
+		}
+		if layer, err := img.LayerByDigest(dgst); err == nil {
+			return layer.Compressed()
+		}
+	}
+	return nil, errdef.ErrNotFound
+}
+
+// rawSource is a Source of a single manifest without blobs.
+type rawSource []byte
+
+func (s rawSource) RawManifest() ([]byte, error) {
+	return s, nil
+}
+
+func (s rawSource) Blob(_ context.Context, dgst digest.Digest) (io.ReadCloser, error) {
+	return nil, errdef.ErrNotFound
+}
+
+func TestCopy(t *testing.T) {
+	ctx := context.Background()
+	g := newTestGraph(t)
+	idx, err := NewIndex(ctx, g.store, g.index)
+	if err != nil {
+		t.Fatal("NewIndex() error =", err)
+	}
+
+	dst := memory.New()
+	root, err := Copy(ctx, &indexSource{idx: idx}, dst, "latest", oras.DefaultCopyGraphOptions)
+	if err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+	if !content.Equal(root, g.index) {
+		t.Errorf("Copy() = %v, want %v", root, g.index)
+	}
+	desc, err := dst.Resolve(ctx, "latest")
+	if err != nil {
+		t.Fatal("Store.Resolve() error =", err)
+	}
+	if !content.Equal(desc, g.index) {
+		t.Errorf("Store.Resolve() = %v, want %v", desc, g.index)
+	}
+
+	// the round-tripped graph is readable through the views again
+	copied, err := NewIndex(ctx, dst, root)
+	if err != nil {
+		t.Fatal("NewIndex() error =", err)
+	}
+	img, err := copied.Image(g.manifest.Digest)
+	if err != nil {
+		t.Fatal("Index.Image() error =", err)
+	}
+	config, err := img.RawConfigFile()
+	if err != nil {
+		t.Fatal("Image.RawConfigFile() error =", err)
+	}
+	if !bytes.Equal(config, g.config) {
+		t.Errorf("Image.RawConfigFile() = %s, want %s", config, g.config)
+	}
+}
+
+func TestNewStorage(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name          string
+		manifest      string
+		wantMediaType string
+	}{
+		{
+			name:          "explicit media type",
+			manifest:      `{"mediaType":"application/vnd.docker.distribution.manifest.v2+json","layers":[]}`,
+			wantMediaType: "application/vnd.docker.distribution.manifest.v2+json",
+		},
+		{
+			name:          "inferred manifest",
+			manifest:      `{"schemaVersion":2,"layers":[]}`,
+			wantMediaType: ocispec.MediaTypeImageManifest,
+		},
+		{
+			name:          "inferred index",
+			manifest:      `{"schemaVersion":2,"manifests":[]}`,
+			wantMediaType: ocispec.MediaTypeImageIndex,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage, root, err := NewStorage(rawSource(tt.manifest))
+			if err != nil {
+				t.Fatal("NewStorage() error =", err)
+			}
+			if root.MediaType != tt.wantMediaType {
+				t.Errorf("NewStorage() media type = %v, want %v", root.MediaType, tt.wantMediaType)
+			}
+			if root.Digest != digest.FromString(tt.manifest) {
+				t.Errorf("NewStorage() digest = %v, want %v", root.Digest, digest.FromString(tt.manifest))
+			}
+			got, err := content.FetchAll(ctx, storage, root)
+			if err != nil {
+				t.Fatal("content.FetchAll() error =", err)
+			}
+			if string(got) != tt.manifest {
+				t.Errorf("content.FetchAll() = %s, want %s", got, tt.manifest)
+			}
+			exists, err := storage.Exists(ctx, content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, []byte("foo")))
+			if err != nil || exists {
+				t.Errorf("Storage.Exists() = %v, %v, want false, nil", exists, err)
+			}
+		})
+	}
+
+	if _, _, err := NewStorage(rawSource("not json")); err == nil {
+		t.Error("NewStorage() error = nil, wantErr true")
+	}
+}
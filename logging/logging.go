@@ -0,0 +1,61 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logging provides a minimal structured logger interface used by the
+// ORAS library to report events such as retries, fallbacks, and skips.
+//
+// Logging is disabled by default. It is enabled for an operation by attaching
+// a Logger to the context using WithLogger.
+package logging
+
+import "context"
+
+// Logger is a minimal structured logger.
+// keysAndValues are alternating keys and values, where keys are strings.
+type Logger interface {
+	// Debug logs a message for diagnosing.
+	Debug(msg string, keysAndValues ...interface{})
+	// Info logs a message for notable events.
+	Info(msg string, keysAndValues ...interface{})
+}
+
+// Discard is a logger discarding all the messages.
+var Discard Logger = discard{}
+
+// discard is a logger discarding all the messages.
+type discard struct{}
+
+// Debug discards the message.
+func (discard) Debug(string, ...interface{}) {}
+
+// Info discards the message.
+func (discard) Info(string, ...interface{}) {}
+
+// loggerContextKey is the context key for the logger.
+type loggerContextKey struct{}
+
+// WithLogger returns a context with the logger attached.
+func WithLogger(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+// FromContext returns the logger attached to the context.
+// If no logger is attached, Discard is returned.
+func FromContext(ctx context.Context) Logger {
+	if logger, ok := ctx.Value(loggerContextKey{}).(Logger); ok && logger != nil {
+		return logger
+	}
+	return Discard
+}
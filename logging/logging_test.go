@@ -0,0 +1,59 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"context"
+	"testing"
+)
+
+type recordLogger struct {
+	messages []string
+}
+
+func (l *recordLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.messages = append(l.messages, "debug: "+msg)
+}
+
+func (l *recordLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.messages = append(l.messages, "info: "+msg)
+}
+
+func TestFromContext(t *testing.T) {
+	ctx := context.Background()
+	if got := FromContext(ctx); got != Discard {
+		t.Errorf("FromContext() = %v, want %v", got, Discard)
+	}
+
+	logger := &recordLogger{}
+	ctx = WithLogger(ctx, logger)
+	FromContext(ctx).Debug("foo", "key", "value")
+	FromContext(ctx).Info("bar")
+	want := []string{"debug: foo", "info: bar"}
+	if len(logger.messages) != len(want) {
+		t.Fatalf("messages = %v, want %v", logger.messages, want)
+	}
+	for i := range want {
+		if logger.messages[i] != want[i] {
+			t.Errorf("messages[%d] = %v, want %v", i, logger.messages[i], want[i])
+		}
+	}
+
+	ctx = WithLogger(ctx, nil)
+	if got := FromContext(ctx); got != Discard {
+		t.Errorf("FromContext() with nil logger = %v, want %v", got, Discard)
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+)
+
+// manifestMapper rewrites manifests and indexes with
+// CopyGraphOptions.MapManifest.
+type manifestMapper struct {
+	fetcher content.Fetcher
+	// cache stores the rewritten manifests and indexes.
+	cache          content.Pusher
+	findSuccessors func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error)
+	mapManifest    func(ctx context.Context, desc ocispec.Descriptor, content []byte) ([]byte, error)
+	// mapped maps the visited nodes to the rewritten descriptors.
+	mapped map[descriptor.Descriptor]ocispec.Descriptor
+}
+
+// mapManifests rewrites the manifests and the indexes in the graph rooted by
+// root with opts.MapManifest, and pushes the rewritten ones to cache. The
+// successors are rewritten before their predecessors, which are re-linked to
+// the rewritten successors before being passed to opts.MapManifest.
+// Returns the descriptor of the rewritten root.
+func mapManifests(ctx context.Context, fetcher content.Fetcher, cache content.Pusher, root ocispec.Descriptor, opts CopyGraphOptions) (ocispec.Descriptor, error) {
+	findSuccessors := opts.FindSuccessors
+	if findSuccessors == nil {
+		findSuccessors = content.Successors
+	}
+	if opts.NodeFilter != nil {
+		findSuccessors = filterSuccessors(findSuccessors, opts.NodeFilter)
+	}
+	mapper := &manifestMapper{
+		fetcher:        fetcher,
+		cache:          cache,
+		findSuccessors: findSuccessors,
+		mapManifest:    opts.MapManifest,
+		mapped:         make(map[descriptor.Descriptor]ocispec.Descriptor),
+	}
+	return mapper.rewrite(ctx, root)
+}
+
+// rewrite rewrites the node described by desc, and returns the descriptor of
+// the result.
+func (mm *manifestMapper) rewrite(ctx context.Context, desc ocispec.Descriptor) (ocispec.Descriptor, error) {
+	if !descriptor.IsManifest(desc) {
+		return desc, nil
+	}
+	key := descriptor.FromOCI(desc)
+	if newDesc, ok := mm.mapped[key]; ok {
+		return newDesc, nil
+	}
+
+	successors, err := mm.findSuccessors(ctx, mm.fetcher, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	relinked := make(map[digest.Digest]ocispec.Descriptor)
+	for _, node := range successors {
+		newNode, err := mm.rewrite(ctx, node)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		if newNode.Digest != node.Digest {
+			relinked[node.Digest] = newNode
+		}
+	}
+
+	manifestJSON, err := content.FetchAll(ctx, mm.fetcher, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if len(relinked) != 0 {
+		if manifestJSON, err = relinkManifest(desc, manifestJSON, relinked); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+	manifestJSON, err = mm.mapManifest(ctx, desc, manifestJSON)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if !json.Valid(manifestJSON) {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: %s: mapped manifest is not valid JSON", desc.Digest, desc.MediaType)
+	}
+
+	newDesc := content.NewDescriptorFromBytes(desc.MediaType, manifestJSON)
+	if newDesc.Digest == desc.Digest {
+		mm.mapped[key] = desc
+		return desc, nil
+	}
+	newDesc.ArtifactType = desc.ArtifactType
+	newDesc.Annotations = desc.Annotations
+	newDesc.Platform = desc.Platform
+	if err := mm.cache.Push(ctx, newDesc, bytes.NewReader(manifestJSON)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to stage %s: %w", newDesc.Digest, err)
+	}
+	mm.mapped[key] = newDesc
+	return newDesc, nil
+}
+
+// relinkManifest replaces the digests and the sizes of the children and the
+// subject referenced by the manifest or the index with the ones in relinked,
+// keeping the other fields of the descriptors.
+func relinkManifest(desc ocispec.Descriptor, manifestJSON []byte, relinked map[digest.Digest]ocispec.Descriptor) ([]byte, error) {
+	var manifest map[string]json.RawMessage
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %s: %w", desc.Digest, desc.MediaType, err)
+	}
+	if raw, ok := manifest["manifests"]; ok {
+		var manifests []ocispec.Descriptor
+		if err := json.Unmarshal(raw, &manifests); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %s: %w", desc.Digest, desc.MediaType, err)
+		}
+		for i, child := range manifests {
+			if newChild, ok := relinked[child.Digest]; ok {
+				manifests[i].Digest = newChild.Digest
+				manifests[i].Size = newChild.Size
+			}
+		}
+		var err error
+		if manifest["manifests"], err = json.Marshal(manifests); err != nil {
+			return nil, err
+		}
+	}
+	if raw, ok := manifest["subject"]; ok && string(raw) != "null" {
+		var subject ocispec.Descriptor
+		if err := json.Unmarshal(raw, &subject); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %s: %w", desc.Digest, desc.MediaType, err)
+		}
+		if newSubject, ok := relinked[subject.Digest]; ok {
+			subject.Digest = newSubject.Digest
+			subject.Size = newSubject.Size
+			var err error
+			if manifest["subject"], err = json.Marshal(subject); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return json.Marshal(manifest)
+}
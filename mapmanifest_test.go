@@ -0,0 +1,152 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestCopy_MapManifest(t *testing.T) {
+	ctx := context.Background()
+	layer := []byte("foreign")
+	src, manifestDesc := pushImage(t, []byte(`{"architecture":"amd64","os":"linux"}`), layer, "image")
+	layerDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+	child := manifestDesc
+	child.Platform = &ocispec.Platform{OS: "linux", Architecture: "amd64"}
+	indexDesc := pushIndex(t, src, child)
+	if err := src.Tag(ctx, indexDesc, "index"); err != nil {
+		t.Fatal("Store.Tag() error =", err)
+	}
+
+	dst := memory.New()
+	opts := CopyOptions{}
+	opts.MapManifest = func(ctx context.Context, desc ocispec.Descriptor, content []byte) ([]byte, error) {
+		if desc.MediaType != ocispec.MediaTypeImageManifest {
+			return content, nil
+		}
+		// strip the layers
+		var manifest ocispec.Manifest
+		if err := json.Unmarshal(content, &manifest); err != nil {
+			return nil, err
+		}
+		manifest.Layers = []ocispec.Descriptor{}
+		return json.Marshal(manifest)
+	}
+	root, err := Copy(ctx, src, "index", dst, "mapped", opts)
+	if err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+	if root.Digest == indexDesc.Digest {
+		t.Fatal("Copy() root digest is not changed")
+	}
+	tagged, err := dst.Resolve(ctx, "mapped")
+	if err != nil {
+		t.Fatal("Store.Resolve() error =", err)
+	}
+	if tagged.Digest != root.Digest {
+		t.Errorf("tagged digest = %v, want %v", tagged.Digest, root.Digest)
+	}
+
+	indexJSON, err := content.FetchAll(ctx, dst, root)
+	if err != nil {
+		t.Fatal("FetchAll() error =", err)
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(indexJSON, &index); err != nil {
+		t.Fatal("json.Unmarshal() error =", err)
+	}
+	if len(index.Manifests) != 1 {
+		t.Fatalf("len(index.Manifests) = %d, want 1", len(index.Manifests))
+	}
+	got := index.Manifests[0]
+	if got.Digest == manifestDesc.Digest {
+		t.Error("index is not re-linked to the mapped manifest")
+	}
+	if got.Platform == nil || got.Platform.Architecture != "amd64" {
+		t.Errorf("index.Manifests[0].Platform = %v, want amd64", got.Platform)
+	}
+	manifestJSON, err := content.FetchAll(ctx, dst, got)
+	if err != nil {
+		t.Fatal("FetchAll() error =", err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		t.Fatal("json.Unmarshal() error =", err)
+	}
+	if len(manifest.Layers) != 0 {
+		t.Errorf("manifest.Layers = %v, want empty", manifest.Layers)
+	}
+	for _, desc := range []ocispec.Descriptor{manifestDesc, layerDesc} {
+		if exists, err := dst.Exists(ctx, desc); err != nil || exists {
+			t.Errorf("Store.Exists(%s) = %v, %v, want false", desc.Digest, exists, err)
+		}
+	}
+
+	// source is not modified
+	if exists, err := src.Exists(ctx, root); err != nil || exists {
+		t.Errorf("source Store.Exists(%s) = %v, %v, want false", root.Digest, exists, err)
+	}
+}
+
+func TestCopyGraph_MapManifest(t *testing.T) {
+	ctx := context.Background()
+	src, manifestDesc := pushImage(t, []byte("{}"), []byte("layer"), "image")
+
+	t.Run("unchanged", func(t *testing.T) {
+		dst := memory.New()
+		opts := CopyGraphOptions{
+			MapManifest: func(ctx context.Context, desc ocispec.Descriptor, content []byte) ([]byte, error) {
+				return content, nil
+			},
+		}
+		if err := CopyGraph(ctx, src, dst, manifestDesc, opts); err != nil {
+			t.Fatal("CopyGraph() error =", err)
+		}
+		if exists, err := dst.Exists(ctx, manifestDesc); err != nil || !exists {
+			t.Errorf("Store.Exists() = %v, %v, want true", exists, err)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		wantErr := errors.New("map failed")
+		opts := CopyGraphOptions{
+			MapManifest: func(ctx context.Context, desc ocispec.Descriptor, content []byte) ([]byte, error) {
+				return nil, wantErr
+			},
+		}
+		if err := CopyGraph(ctx, src, memory.New(), manifestDesc, opts); !errors.Is(err, wantErr) {
+			t.Errorf("CopyGraph() error = %v, want %v", err, wantErr)
+		}
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		opts := CopyGraphOptions{
+			MapManifest: func(ctx context.Context, desc ocispec.Descriptor, content []byte) ([]byte, error) {
+				return []byte("{"), nil
+			},
+		}
+		if err := CopyGraph(ctx, src, memory.New(), manifestDesc, opts); err == nil {
+			t.Error("CopyGraph() error = nil, want error")
+		}
+	})
+}
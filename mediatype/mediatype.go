@@ -0,0 +1,279 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mediatype provides a registry of the well-known artifact types and
+// their config media types, such as Helm charts, SBOMs, signatures and WASM
+// modules, so that packers and discovery filters can reference the types
+// symbolically and validate the config payloads.
+package mediatype
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// Media types of the well-known artifacts.
+const (
+	// HelmChartConfig is the config media type of Helm charts.
+	HelmChartConfig = "application/vnd.cncf.helm.config.v1+json"
+	// HelmChartContent is the layer media type of Helm chart archives.
+	HelmChartContent = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+	// HelmChartProvenance is the layer media type of Helm chart provenance
+	// files.
+	HelmChartProvenance = "application/vnd.cncf.helm.chart.provenance.v1.prov"
+
+	// SPDX is the media type of SPDX SBOMs in JSON.
+	SPDX = "application/spdx+json"
+	// CycloneDX is the media type of CycloneDX SBOMs in JSON.
+	CycloneDX = "application/vnd.cyclonedx+json"
+	// InTotoStatement is the media type of in-toto attestations.
+	InTotoStatement = "application/vnd.in-toto+json"
+
+	// NotarySignature is the artifact type of Notary Project signatures.
+	NotarySignature = "application/vnd.cncf.notary.signature"
+	// CosignSignature is the artifact type of cosign signatures.
+	CosignSignature = "application/vnd.dev.cosign.artifact.sig.v1+json"
+
+	// WASMConfig is the config media type of WebAssembly modules.
+	WASMConfig = "application/vnd.wasm.config.v0+json"
+	// WASMContent is the layer media type of WebAssembly modules.
+	WASMContent = "application/wasm"
+)
+
+// Category classifies the artifact types.
+type Category string
+
+// Categories of the well-known artifact types.
+const (
+	CategoryHelm        Category = "helm"
+	CategorySBOM        Category = "sbom"
+	CategoryAttestation Category = "attestation"
+	CategorySignature   Category = "signature"
+	CategoryWASM        Category = "wasm"
+)
+
+// ErrInvalidConfig is returned when a config payload does not conform to the
+// shape required by its artifact type.
+var ErrInvalidConfig = errors.New("invalid config")
+
+// Type describes an artifact type.
+type Type struct {
+	// Name is the human-readable name of the artifact type, such as
+	// "Helm chart".
+	Name string
+	// Category classifies the artifact type.
+	Category Category
+	// ArtifactType is the artifact type, set as the artifactType of the
+	// manifests or as the media type of their config.
+	ArtifactType string
+	// ConfigMediaType is the media type of the config of the artifacts.
+	// If empty, the artifacts are expected to have an empty config.
+	ConfigMediaType string
+	// LayerMediaTypes are the media types of the layers of the artifacts.
+	LayerMediaTypes []string
+	// Validate validates the config payload of the artifacts, returning an
+	// error wrapping ErrInvalidConfig if the payload is malformed.
+	// If nil, any payload is accepted.
+	Validate func(config []byte) error
+}
+
+// ValidateConfig validates the config payload of an artifact of the type.
+func (t Type) ValidateConfig(config []byte) error {
+	if t.Validate == nil {
+		return nil
+	}
+	return t.Validate(config)
+}
+
+// NewConfig marshals v as the config payload of an artifact of the type,
+// validates it, and returns the payload with its descriptor.
+// Returns errdef.ErrUnsupported if the type has no config media type.
+func (t Type) NewConfig(v any) (ocispec.Descriptor, []byte, error) {
+	if t.ConfigMediaType == "" {
+		return ocispec.Descriptor{}, nil, fmt.Errorf("%s: config: %w", t.ArtifactType, errdef.ErrUnsupported)
+	}
+	config, err := json.Marshal(v)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	if err := t.ValidateConfig(config); err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	return content.NewDescriptorFromBytes(t.ConfigMediaType, config), config, nil
+}
+
+var (
+	registryLock sync.RWMutex
+	registry     = make(map[string]Type)
+)
+
+func init() {
+	for _, t := range []Type{
+		{
+			Name:            "Helm chart",
+			Category:        CategoryHelm,
+			ArtifactType:    HelmChartConfig,
+			ConfigMediaType: HelmChartConfig,
+			LayerMediaTypes: []string{HelmChartContent, HelmChartProvenance},
+			Validate:        validateHelmChartConfig,
+		},
+		{
+			Name:            "SPDX SBOM",
+			Category:        CategorySBOM,
+			ArtifactType:    SPDX,
+			LayerMediaTypes: []string{SPDX},
+		},
+		{
+			Name:            "CycloneDX SBOM",
+			Category:        CategorySBOM,
+			ArtifactType:    CycloneDX,
+			LayerMediaTypes: []string{CycloneDX},
+		},
+		{
+			Name:            "in-toto attestation",
+			Category:        CategoryAttestation,
+			ArtifactType:    InTotoStatement,
+			LayerMediaTypes: []string{InTotoStatement},
+		},
+		{
+			Name:         "Notary Project signature",
+			Category:     CategorySignature,
+			ArtifactType: NotarySignature,
+			LayerMediaTypes: []string{
+				"application/jose+json",
+				"application/cose",
+			},
+		},
+		{
+			Name:            "cosign signature",
+			Category:        CategorySignature,
+			ArtifactType:    CosignSignature,
+			LayerMediaTypes: []string{"application/vnd.dev.cosign.simplesigning.v1+json"},
+		},
+		{
+			Name:            "WebAssembly module",
+			Category:        CategoryWASM,
+			ArtifactType:    WASMConfig,
+			ConfigMediaType: WASMConfig,
+			LayerMediaTypes: []string{WASMContent},
+			Validate:        validateWASMConfig,
+		},
+	} {
+		registry[t.ArtifactType] = t
+	}
+}
+
+// Register registers an artifact type, so that it can be looked up by
+// Lookup.
+// Returns errdef.ErrAlreadyExists if the artifact type is registered.
+func Register(t Type) error {
+	if t.ArtifactType == "" {
+		return errors.New("missing artifact type")
+	}
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	if _, ok := registry[t.ArtifactType]; ok {
+		return fmt.Errorf("%s: %w", t.ArtifactType, errdef.ErrAlreadyExists)
+	}
+	registry[t.ArtifactType] = t
+	return nil
+}
+
+// Lookup returns the registered artifact type identified by mediaType, which
+// is either the artifact type or the config media type of the artifacts.
+func Lookup(mediaType string) (Type, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	if t, ok := registry[mediaType]; ok {
+		return t, true
+	}
+	for _, t := range registry {
+		if t.ConfigMediaType != "" && t.ConfigMediaType == mediaType {
+			return t, true
+		}
+	}
+	return Type{}, false
+}
+
+// ListByCategory returns the registered artifact types of the category,
+// sorted by artifact type.
+func ListByCategory(category Category) []Type {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	var types []Type
+	for _, t := range registry {
+		if t.Category == category {
+			types = append(types, t)
+		}
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].ArtifactType < types[j].ArtifactType
+	})
+	return types
+}
+
+// ArtifactTypes returns the artifact types of the category, which can be
+// used to filter referrers.
+func ArtifactTypes(category Category) []string {
+	types := ListByCategory(category)
+	artifactTypes := make([]string, 0, len(types))
+	for _, t := range types {
+		artifactTypes = append(artifactTypes, t.ArtifactType)
+	}
+	return artifactTypes
+}
+
+// validateHelmChartConfig validates that the config of a Helm chart carries
+// the chart name and version.
+// Reference: https://helm.sh/docs/topics/charts/#the-chartyaml-file
+func validateHelmChartConfig(config []byte) error {
+	var chart struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(config, &chart); err != nil {
+		return fmt.Errorf("%s: %v: %w", HelmChartConfig, err, ErrInvalidConfig)
+	}
+	if chart.Name == "" {
+		return fmt.Errorf("%s: missing chart name: %w", HelmChartConfig, ErrInvalidConfig)
+	}
+	if chart.Version == "" {
+		return fmt.Errorf("%s: missing chart version: %w", HelmChartConfig, ErrInvalidConfig)
+	}
+	return nil
+}
+
+// validateWASMConfig validates that the config of a WebAssembly module
+// declares the wasm architecture.
+// Reference: https://tag-runtime.cncf.io/wgs/wasm/deliverables/wasm-oci-artifact/
+func validateWASMConfig(config []byte) error {
+	var wasm struct {
+		Architecture string `json:"architecture"`
+	}
+	if err := json.Unmarshal(config, &wasm); err != nil {
+		return fmt.Errorf("%s: %v: %w", WASMConfig, err, ErrInvalidConfig)
+	}
+	if wasm.Architecture != "wasm" {
+		return fmt.Errorf("%s: architecture %q is not wasm: %w", WASMConfig, wasm.Architecture, ErrInvalidConfig)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mediatype
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		mediaType string
+		wantName  string
+		wantOK    bool
+	}{
+		{HelmChartConfig, "Helm chart", true},
+		{SPDX, "SPDX SBOM", true},
+		{NotarySignature, "Notary Project signature", true},
+		{WASMConfig, "WebAssembly module", true},
+		{"application/vnd.unknown", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mediaType, func(t *testing.T) {
+			got, ok := Lookup(tt.mediaType)
+			if ok != tt.wantOK {
+				t.Fatalf("Lookup() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if got.Name != tt.wantName {
+				t.Errorf("Lookup() name = %v, want %v", got.Name, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	typ := Type{
+		Name:            "test",
+		Category:        "test",
+		ArtifactType:    "application/vnd.test.artifact",
+		ConfigMediaType: "application/vnd.test.config+json",
+	}
+	if err := Register(typ); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := Register(typ); !errors.Is(err, errdef.ErrAlreadyExists) {
+		t.Errorf("Register() error = %v, want %v", err, errdef.ErrAlreadyExists)
+	}
+	if err := Register(Type{}); err == nil {
+		t.Error("Register() error = nil, want error")
+	}
+
+	// lookup by config media type
+	got, ok := Lookup("application/vnd.test.config+json")
+	if !ok || got.Name != "test" {
+		t.Errorf("Lookup() = %v, %v, want test type", got, ok)
+	}
+	if got, want := ArtifactTypes("test"), []string{"application/vnd.test.artifact"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ArtifactTypes() = %v, want %v", got, want)
+	}
+}
+
+func TestArtifactTypes(t *testing.T) {
+	if got, want := ArtifactTypes(CategorySBOM), []string{SPDX, CycloneDX}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ArtifactTypes() = %v, want %v", got, want)
+	}
+	if got, want := ArtifactTypes(CategorySignature), []string{NotarySignature, CosignSignature}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ArtifactTypes() = %v, want %v", got, want)
+	}
+}
+
+func TestType_ValidateConfig(t *testing.T) {
+	helm, _ := Lookup(HelmChartConfig)
+	wasm, _ := Lookup(WASMConfig)
+	spdx, _ := Lookup(SPDX)
+	tests := []struct {
+		name    string
+		typ     Type
+		config  string
+		wantErr bool
+	}{
+		{"helm", helm, `{"name":"chart","version":"1.0.0","apiVersion":"v2"}`, false},
+		{"helm missing version", helm, `{"name":"chart"}`, true},
+		{"helm missing name", helm, `{"version":"1.0.0"}`, true},
+		{"helm invalid json", helm, `{`, true},
+		{"wasm", wasm, `{"architecture":"wasm","os":"wasip1"}`, false},
+		{"wasm wrong architecture", wasm, `{"architecture":"amd64"}`, true},
+		{"spdx any", spdx, `anything`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.typ.ValidateConfig([]byte(tt.config))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Type.ValidateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("Type.ValidateConfig() error = %v, want %v", err, ErrInvalidConfig)
+			}
+		})
+	}
+}
+
+func TestType_NewConfig(t *testing.T) {
+	helm, _ := Lookup(HelmChartConfig)
+	desc, config, err := helm.NewConfig(map[string]string{"name": "chart", "version": "1.0.0"})
+	if err != nil {
+		t.Fatalf("Type.NewConfig() error = %v", err)
+	}
+	if desc.MediaType != HelmChartConfig || desc.Size != int64(len(config)) {
+		t.Errorf("Type.NewConfig() descriptor = %v", desc)
+	}
+	if _, _, err := helm.NewConfig(map[string]string{"name": "chart"}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Type.NewConfig() error = %v, want %v", err, ErrInvalidConfig)
+	}
+
+	spdx, _ := Lookup(SPDX)
+	if _, _, err := spdx.NewConfig(struct{}{}); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Type.NewConfig() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+}
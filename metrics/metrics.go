@@ -0,0 +1,91 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics provides a minimal metrics abstraction used to instrument
+// the ORAS library.
+//
+// Metrics are disabled by default. They are enabled for an operation by
+// attaching a Recorder to the context using WithRecorder. A Registry is
+// provided as a default Recorder which exposes the collected metrics in the
+// Prometheus text exposition format.
+package metrics
+
+import "context"
+
+// Names of the metrics recorded by the ORAS library.
+const (
+	// BlobsCopied counts the nodes copied to the destination.
+	BlobsCopied = "oras_blobs_copied_total"
+	// BytesTransferred counts the bytes copied to the destination.
+	BytesTransferred = "oras_bytes_transferred_total"
+	// CacheHits counts the transfers and the round trips saved by caches,
+	// labeled by the cache kind.
+	CacheHits = "oras_cache_hits_total"
+	// AuthRoundTrips counts the requests made to fetch auth tokens.
+	AuthRoundTrips = "oras_auth_round_trips_total"
+	// RequestDuration observes the latencies of HTTP requests in seconds.
+	RequestDuration = "oras_http_request_duration_seconds"
+)
+
+// Label is a name-value pair identifying a metric series.
+type Label struct {
+	// Name is the label name.
+	Name string
+	// Value is the label value.
+	Value string
+}
+
+// Recorder records metrics.
+// Implementations must be safe for concurrent use.
+type Recorder interface {
+	// AddCounter adds delta to the counter identified by name and labels.
+	AddCounter(name string, delta float64, labels ...Label)
+	// ObserveHistogram records value in the histogram identified by name and
+	// labels.
+	ObserveHistogram(name string, value float64, labels ...Label)
+}
+
+// recorderContextKey is the context key for the recorder.
+type recorderContextKey struct{}
+
+// WithRecorder returns a context with the recorder attached.
+// All the instrumented operations using the returned context will record
+// metrics to the recorder.
+func WithRecorder(ctx context.Context, recorder Recorder) context.Context {
+	return context.WithValue(ctx, recorderContextKey{}, recorder)
+}
+
+// RecorderFromContext returns the recorder attached to the context, or nil if
+// no recorder is attached.
+func RecorderFromContext(ctx context.Context) Recorder {
+	recorder, _ := ctx.Value(recorderContextKey{}).(Recorder)
+	return recorder
+}
+
+// AddCounter adds delta to a counter using the recorder attached to the
+// context. It does nothing if no recorder is attached.
+func AddCounter(ctx context.Context, name string, delta float64, labels ...Label) {
+	if recorder := RecorderFromContext(ctx); recorder != nil {
+		recorder.AddCounter(name, delta, labels...)
+	}
+}
+
+// ObserveHistogram records value in a histogram using the recorder attached to
+// the context. It does nothing if no recorder is attached.
+func ObserveHistogram(ctx context.Context, name string, value float64, labels ...Label) {
+	if recorder := RecorderFromContext(ctx); recorder != nil {
+		recorder.ObserveHistogram(name, value, labels...)
+	}
+}
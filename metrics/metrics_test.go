@@ -0,0 +1,134 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRecorderFromContext(t *testing.T) {
+	ctx := context.Background()
+	if got := RecorderFromContext(ctx); got != nil {
+		t.Errorf("RecorderFromContext() = %v, want nil", got)
+	}
+	// no-op without recorder
+	AddCounter(ctx, BlobsCopied, 1)
+	ObserveHistogram(ctx, RequestDuration, 1)
+
+	registry := NewRegistry()
+	ctx = WithRecorder(ctx, registry)
+	if got := RecorderFromContext(ctx); got != registry {
+		t.Errorf("RecorderFromContext() = %v, want %v", got, registry)
+	}
+	AddCounter(ctx, BlobsCopied, 2)
+	var buf bytes.Buffer
+	if _, err := registry.WriteTo(&buf); err != nil {
+		t.Fatalf("Registry.WriteTo() error = %v", err)
+	}
+	want := "# TYPE oras_blobs_copied_total counter\noras_blobs_copied_total 2\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Registry.WriteTo() = %q, want %q", got, want)
+	}
+}
+
+func TestRegistry_WriteTo(t *testing.T) {
+	registry := &Registry{
+		Buckets: []float64{0.1, 1},
+	}
+	registry.AddCounter(CacheHits, 1, Label{Name: "cache", Value: "destination"})
+	registry.AddCounter(CacheHits, 2, Label{Name: "cache", Value: "auth"})
+	registry.AddCounter(CacheHits, 1, Label{Name: "cache", Value: "auth"})
+	registry.AddCounter(AuthRoundTrips, 1, Label{Name: "registry", Value: "a\"b\\c\nd"})
+	registry.ObserveHistogram(RequestDuration, 0.05, Label{Name: "method", Value: "GET"}, Label{Name: "code", Value: "200"})
+	registry.ObserveHistogram(RequestDuration, 0.5, Label{Name: "code", Value: "200"}, Label{Name: "method", Value: "GET"})
+	registry.ObserveHistogram(RequestDuration, 2, Label{Name: "method", Value: "GET"}, Label{Name: "code", Value: "200"})
+
+	var buf bytes.Buffer
+	n, err := registry.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("Registry.WriteTo() error = %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("Registry.WriteTo() = %d, want %d", n, buf.Len())
+	}
+	want := strings.Join([]string{
+		`# TYPE oras_auth_round_trips_total counter`,
+		`oras_auth_round_trips_total{registry="a\"b\\c\nd"} 1`,
+		`# TYPE oras_cache_hits_total counter`,
+		`oras_cache_hits_total{cache="auth"} 3`,
+		`oras_cache_hits_total{cache="destination"} 1`,
+		`# TYPE oras_http_request_duration_seconds histogram`,
+		`oras_http_request_duration_seconds_bucket{code="200",method="GET",le="0.1"} 1`,
+		`oras_http_request_duration_seconds_bucket{code="200",method="GET",le="1"} 2`,
+		`oras_http_request_duration_seconds_bucket{code="200",method="GET",le="+Inf"} 3`,
+		`oras_http_request_duration_seconds_sum{code="200",method="GET"} 2.55`,
+		`oras_http_request_duration_seconds_count{code="200",method="GET"} 3`,
+	}, "\n") + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Registry.WriteTo() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRegistry_Concurrent(t *testing.T) {
+	registry := NewRegistry()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				registry.AddCounter(BytesTransferred, 1)
+				registry.ObserveHistogram(RequestDuration, 0.01)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var buf bytes.Buffer
+	if _, err := registry.WriteTo(&buf); err != nil {
+		t.Fatalf("Registry.WriteTo() error = %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"oras_bytes_transferred_total 1000\n",
+		"oras_http_request_duration_seconds_count 1000\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Registry.WriteTo() = %q, want containing %q", got, want)
+		}
+	}
+}
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	registry := NewRegistry()
+	registry.AddCounter(BlobsCopied, 1)
+
+	rec := httptest.NewRecorder()
+	registry.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if got, want := rec.Header().Get("Content-Type"), "text/plain; version=0.0.4; charset=utf-8"; got != want {
+		t.Errorf("Content-Type = %v, want %v", got, want)
+	}
+	want := "# TYPE oras_blobs_copied_total counter\noras_blobs_copied_total 1\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
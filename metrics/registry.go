@@ -0,0 +1,238 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the default histogram buckets, tailored to measure
+// request latencies in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Registry is a Recorder keeping the metrics in memory and exposing them in
+// the Prometheus text exposition format.
+// Registry implements http.Handler and can be served as a scrape endpoint.
+type Registry struct {
+	// Buckets are the upper bounds of the histogram buckets in increasing
+	// order. If nil, DefaultBuckets is used.
+	// Buckets must not be changed after the first observation.
+	Buckets []float64
+
+	lock   sync.Mutex
+	series map[string]*series
+}
+
+// metricKind is the kind of a metric.
+type metricKind int
+
+const (
+	kindCounter metricKind = iota
+	kindHistogram
+)
+
+// series is a single metric series identified by a name and a set of labels.
+type series struct {
+	name   string
+	labels []Label
+	kind   metricKind
+
+	// value is the counter value.
+	value float64
+
+	// fields for histograms
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+// NewRegistry creates a new Registry with the default buckets.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// AddCounter adds delta to the counter identified by name and labels.
+func (r *Registry) AddCounter(name string, delta float64, labels ...Label) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	s := r.load(name, kindCounter, labels)
+	s.value += delta
+}
+
+// ObserveHistogram records value in the histogram identified by name and
+// labels.
+func (r *Registry) ObserveHistogram(name string, value float64, labels ...Label) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	s := r.load(name, kindHistogram, labels)
+	for i, bound := range s.buckets {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+	s.sum += value
+	s.count++
+}
+
+// load returns the series identified by name and labels, creating it if
+// needed. The caller must hold the lock.
+func (r *Registry) load(name string, kind metricKind, labels []Label) *series {
+	labels = sortLabels(labels)
+	key := seriesKey(name, labels)
+	if s, ok := r.series[key]; ok {
+		return s
+	}
+	s := &series{
+		name:   name,
+		labels: labels,
+		kind:   kind,
+	}
+	if kind == kindHistogram {
+		s.buckets = r.Buckets
+		if s.buckets == nil {
+			s.buckets = DefaultBuckets
+		}
+		s.counts = make([]uint64, len(s.buckets))
+	}
+	if r.series == nil {
+		r.series = make(map[string]*series)
+	}
+	r.series[key] = s
+	return s
+}
+
+// WriteTo writes the metrics to w in the Prometheus text exposition format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	keys := make([]string, 0, len(r.series))
+	for key := range r.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	cw := &countWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	var lastName string
+	for _, key := range keys {
+		s := r.series[key]
+		if s.name != lastName {
+			typ := "counter"
+			if s.kind == kindHistogram {
+				typ = "histogram"
+			}
+			fmt.Fprintf(bw, "# TYPE %s %s\n", s.name, typ)
+			lastName = s.name
+		}
+		switch s.kind {
+		case kindCounter:
+			fmt.Fprintf(bw, "%s%s %s\n", s.name, formatLabels(s.labels), formatFloat(s.value))
+		case kindHistogram:
+			for i, bound := range s.buckets {
+				labels := append(s.labels[:len(s.labels):len(s.labels)], Label{Name: "le", Value: formatFloat(bound)})
+				fmt.Fprintf(bw, "%s_bucket%s %d\n", s.name, formatLabels(labels), s.counts[i])
+			}
+			labels := append(s.labels[:len(s.labels):len(s.labels)], Label{Name: "le", Value: "+Inf"})
+			fmt.Fprintf(bw, "%s_bucket%s %d\n", s.name, formatLabels(labels), s.count)
+			fmt.Fprintf(bw, "%s_sum%s %s\n", s.name, formatLabels(s.labels), formatFloat(s.sum))
+			fmt.Fprintf(bw, "%s_count%s %d\n", s.name, formatLabels(s.labels), s.count)
+		}
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.WriteTo(w)
+}
+
+// countWriter counts the bytes written to the underlying writer.
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+// Write writes p to the underlying writer.
+func (cw *countWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// sortLabels returns a copy of labels sorted by name.
+func sortLabels(labels []Label) []Label {
+	sorted := make([]Label, len(labels))
+	copy(sorted, labels)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// seriesKey returns the key identifying a series. Sorting the keys groups the
+// series by name.
+func seriesKey(name string, labels []Label) string {
+	return name + "\x00" + formatLabels(labels)
+}
+
+// formatLabels formats labels in the Prometheus text exposition format.
+func formatLabels(labels []Label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, label := range labels {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(label.Name)
+		sb.WriteString(`="`)
+		sb.WriteString(labelValueEscaper.Replace(label.Value))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// labelValueEscaper escapes label values as required by the Prometheus text
+// exposition format.
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatFloat formats a float in the Prometheus text exposition format.
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
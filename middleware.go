@@ -0,0 +1,95 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// WithFetcherMiddleware returns a Target whose Fetch calls go through the
+// middlewares before reaching t. Other methods are served by t directly.
+//
+// Optional interfaces implemented by t, such as registry.ReferenceFetcher, are
+// not exposed by the returned Target so that all content is fetched through
+// the middlewares.
+func WithFetcherMiddleware(t Target, middlewares ...content.FetcherMiddleware) Target {
+	return &fetcherMiddlewareTarget{
+		Target:  t,
+		fetcher: content.ChainFetcher(middlewares...)(t),
+	}
+}
+
+// fetcherMiddlewareTarget is a Target with a decorated Fetcher.
+type fetcherMiddlewareTarget struct {
+	Target
+	fetcher content.Fetcher
+}
+
+// Fetch fetches the content identified by the descriptor through the
+// middlewares.
+func (t *fetcherMiddlewareTarget) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	return t.fetcher.Fetch(ctx, target)
+}
+
+// WithReadOnlyFetcherMiddleware is the ReadOnlyTarget version of
+// WithFetcherMiddleware.
+func WithReadOnlyFetcherMiddleware(t ReadOnlyTarget, middlewares ...content.FetcherMiddleware) ReadOnlyTarget {
+	return &fetcherMiddlewareReadOnlyTarget{
+		ReadOnlyTarget: t,
+		fetcher:        content.ChainFetcher(middlewares...)(t),
+	}
+}
+
+// fetcherMiddlewareReadOnlyTarget is a ReadOnlyTarget with a decorated
+// Fetcher.
+type fetcherMiddlewareReadOnlyTarget struct {
+	ReadOnlyTarget
+	fetcher content.Fetcher
+}
+
+// Fetch fetches the content identified by the descriptor through the
+// middlewares.
+func (t *fetcherMiddlewareReadOnlyTarget) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	return t.fetcher.Fetch(ctx, target)
+}
+
+// WithPusherMiddleware returns a Target whose Push calls go through the
+// middlewares before reaching t. Other methods are served by t directly.
+//
+// Optional interfaces implemented by t, such as registry.ReferencePusher, are
+// not exposed by the returned Target so that all content is pushed through
+// the middlewares.
+func WithPusherMiddleware(t Target, middlewares ...content.PusherMiddleware) Target {
+	return &pusherMiddlewareTarget{
+		Target: t,
+		pusher: content.ChainPusher(middlewares...)(t),
+	}
+}
+
+// pusherMiddlewareTarget is a Target with a decorated Pusher.
+type pusherMiddlewareTarget struct {
+	Target
+	pusher content.Pusher
+}
+
+// Push pushes the content through the middlewares.
+func (t *pusherMiddlewareTarget) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	return t.pusher.Push(ctx, expected, content)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestWithFetcherMiddleware(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	blob := []byte("hello world")
+	layer := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+	if err := src.Push(ctx, layer, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	root, err := Pack(ctx, src, "", []ocispec.Descriptor{layer}, PackOptions{})
+	if err != nil {
+		t.Fatal("Pack() error =", err)
+	}
+	ref := "foobar"
+	if err := src.Tag(ctx, root, ref); err != nil {
+		t.Fatal("Tag() error =", err)
+	}
+
+	var mu sync.Mutex
+	fetched := make(map[string]bool)
+	record := func(next content.Fetcher) content.Fetcher {
+		return content.FetcherFunc(func(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+			mu.Lock()
+			fetched[target.Digest.String()] = true
+			mu.Unlock()
+			return next.Fetch(ctx, target)
+		})
+	}
+
+	for _, wrapped := range []ReadOnlyTarget{
+		WithFetcherMiddleware(src, record, content.VerifyFetcher),
+		WithReadOnlyFetcherMiddleware(src, record, content.VerifyFetcher),
+	} {
+		fetched = make(map[string]bool)
+		dst := memory.New()
+		if _, err := Copy(ctx, wrapped, ref, dst, "", DefaultCopyOptions); err != nil {
+			t.Fatal("Copy() error =", err)
+		}
+		for _, desc := range []ocispec.Descriptor{root, layer} {
+			if !fetched[desc.Digest.String()] {
+				t.Errorf("%s is not fetched through the middleware", desc.Digest)
+			}
+			exists, err := dst.Exists(ctx, desc)
+			if err != nil {
+				t.Fatal("Exists() error =", err)
+			}
+			if !exists {
+				t.Errorf("%s is not copied", desc.Digest)
+			}
+		}
+	}
+}
+
+func TestWithPusherMiddleware(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	blob := []byte("hello world")
+	layer := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+	if err := src.Push(ctx, layer, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	root, err := Pack(ctx, src, "", []ocispec.Descriptor{layer}, PackOptions{})
+	if err != nil {
+		t.Fatal("Pack() error =", err)
+	}
+	ref := "foobar"
+	if err := src.Tag(ctx, root, ref); err != nil {
+		t.Fatal("Tag() error =", err)
+	}
+
+	var mu sync.Mutex
+	pushed := make(map[string]bool)
+	record := func(next content.Pusher) content.Pusher {
+		return content.PusherFunc(func(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+			mu.Lock()
+			pushed[expected.Digest.String()] = true
+			mu.Unlock()
+			return next.Push(ctx, expected, r)
+		})
+	}
+	dst := memory.New()
+	if _, err := Copy(ctx, src, ref, WithPusherMiddleware(dst, record, content.VerifyPusher), ref, DefaultCopyOptions); err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+	for _, desc := range []ocispec.Descriptor{root, layer} {
+		if !pushed[desc.Digest.String()] {
+			t.Errorf("%s is not pushed through the middleware", desc.Digest)
+		}
+	}
+	if _, err := dst.Resolve(ctx, ref); err != nil {
+		t.Errorf("Resolve() error = %v", err)
+	}
+
+	// the size limit rejects the layer
+	dst = memory.New()
+	if _, err := Copy(ctx, src, ref, WithPusherMiddleware(dst, content.LimitPusher(layer.Size-1)), ref, DefaultCopyOptions); !errors.Is(err, errdef.ErrSizeExceedsLimit) {
+		t.Errorf("Copy() error = %v, want %v", err, errdef.ErrSizeExceedsLimit)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/manifestutil"
+	"oras.land/oras-go/v2/internal/platform"
+)
+
+var (
+	// ErrIndexCycle is returned by FindManifest and FindManifests when an
+	// index references itself directly or through nested indexes.
+	// As contents are verified against their digests, cycles can only be
+	// formed by digest collisions.
+	ErrIndexCycle = manifestutil.ErrIndexCycle
+	// ErrIndexTooDeep is returned by FindManifest and FindManifests when
+	// the nesting of indexes exceeds FindManifestOptions.MaxDepth.
+	ErrIndexTooDeep = manifestutil.ErrIndexTooDeep
+)
+
+// FindManifestOptions contains parameters for [oras.FindManifest] and
+// [oras.FindManifests].
+// A manifest is matched if it satisfies all the conditions set.
+type FindManifestOptions struct {
+	// Platform, if not nil, matches manifests by the platforms in their
+	// descriptors. See WithTargetPlatform for the matching rules.
+	// If the root is a manifest without platform in its descriptor, the
+	// platform in its config is used.
+	// Nested indexes with platforms not matching Platform are not searched.
+	Platform *ocispec.Platform
+	// Annotations, if not empty, matches manifests whose descriptors carry
+	// all the annotations with the same values.
+	Annotations map[string]string
+	// Match, if not nil, matches manifests by their descriptors.
+	Match func(desc ocispec.Descriptor) bool
+	// MaxDepth limits the number of nested index levels below the root to
+	// be searched. ErrIndexTooDeep is returned if the limit is exceeded.
+	// If less than or equal to 0, a default (currently 8) is used.
+	MaxDepth int
+}
+
+// FindManifest searches the graph rooted by root through nested indexes in
+// depth-first order, and returns the descriptor of the first manifest
+// matching opts.
+// If root is a manifest, it is returned if it matches.
+// An error wrapping errdef.ErrNotFound is returned if no manifest matches.
+func FindManifest(ctx context.Context, src content.ReadOnlyStorage, root ocispec.Descriptor, opts FindManifestOptions) (ocispec.Descriptor, error) {
+	var found ocispec.Descriptor
+	var ok bool
+	if err := findManifests(ctx, src, root, opts, func(desc ocispec.Descriptor) bool {
+		found, ok = desc, true
+		return false
+	}); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if !ok {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: %s: no matching manifest: %w", root.Digest, root.MediaType, errdef.ErrNotFound)
+	}
+	return found, nil
+}
+
+// FindManifests searches the graph rooted by root through nested indexes in
+// depth-first order, and returns the descriptors of all the manifests
+// matching opts. Manifests referenced more than once are returned once.
+// If no manifest matches, an empty list is returned.
+func FindManifests(ctx context.Context, src content.ReadOnlyStorage, root ocispec.Descriptor, opts FindManifestOptions) ([]ocispec.Descriptor, error) {
+	var found []ocispec.Descriptor
+	seen := make(map[descriptor.Descriptor]bool)
+	if err := findManifests(ctx, src, root, opts, func(desc ocispec.Descriptor) bool {
+		key := descriptor.FromOCI(desc)
+		if !seen[key] {
+			seen[key] = true
+			found = append(found, desc)
+		}
+		return true
+	}); err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// findManifests walks the graph rooted by root through nested indexes in
+// depth-first order, and calls fn on each manifest matching opts until fn
+// returns false.
+func findManifests(ctx context.Context, src content.ReadOnlyStorage, root ocispec.Descriptor, opts FindManifestOptions, fn func(desc ocispec.Descriptor) bool) error {
+	if !isIndex(root) {
+		if !descriptor.IsManifest(root) {
+			return fmt.Errorf("%s: %s: %w", root.Digest, root.MediaType, errdef.ErrUnsupported)
+		}
+		if opts.Platform != nil && root.Platform == nil {
+			p, err := platform.FromManifest(ctx, src, root)
+			if err != nil {
+				return err
+			}
+			withPlatform := root
+			withPlatform.Platform = p
+			if !matchManifest(withPlatform, opts) {
+				return nil
+			}
+		} else if !matchManifest(root, opts) {
+			return nil
+		}
+		fn(root)
+		return nil
+	}
+
+	return manifestutil.WalkIndex(ctx, src, root, opts.MaxDepth, func(m ocispec.Descriptor) error {
+		if isIndex(m) {
+			if opts.Platform != nil && m.Platform != nil && !platform.Match(m.Platform, opts.Platform) {
+				return manifestutil.SkipIndex
+			}
+			return nil
+		}
+		if matchManifest(m, opts) && !fn(m) {
+			return manifestutil.SkipAll
+		}
+		return nil
+	})
+}
+
+// isIndex returns true if desc describes an image index or a manifest list.
+func isIndex(desc ocispec.Descriptor) bool {
+	return manifestutil.IsIndex(desc)
+}
+
+// matchManifest returns true if the manifest described by desc matches opts.
+func matchManifest(desc ocispec.Descriptor, opts FindManifestOptions) bool {
+	if opts.Platform != nil && (desc.Platform == nil || !platform.Match(desc.Platform, opts.Platform)) {
+		return false
+	}
+	for k, v := range opts.Annotations {
+		if value, ok := desc.Annotations[k]; !ok || value != v {
+			return false
+		}
+	}
+	return opts.Match == nil || opts.Match(desc)
+}
@@ -0,0 +1,141 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// pushIndex pushes an index of the manifests to s.
+func pushIndex(t *testing.T, s content.Pusher, manifests ...ocispec.Descriptor) ocispec.Descriptor {
+	t.Helper()
+	indexJSON, err := json.Marshal(ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: manifests,
+	})
+	if err != nil {
+		t.Fatal("json.Marshal() error =", err)
+	}
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageIndex, indexJSON)
+	if err := s.Push(context.Background(), desc, bytes.NewReader(indexJSON)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	return desc
+}
+
+// copyInto copies the graph rooted by root from src to dst.
+func copyInto(t *testing.T, src content.ReadOnlyStorage, dst content.Storage, root ocispec.Descriptor) {
+	t.Helper()
+	if err := CopyGraph(context.Background(), src, dst, root, CopyGraphOptions{}); err != nil {
+		t.Fatal("CopyGraph() error =", err)
+	}
+}
+
+func TestFindManifest_Nested(t *testing.T) {
+	ctx := context.Background()
+	amd64Store, amd64 := pushImage(t, []byte(`{"architecture":"amd64","os":"linux"}`), []byte("amd64"), "amd64")
+	arm64Store, arm64 := pushImage(t, []byte(`{"architecture":"arm64","os":"linux"}`), []byte("arm64"), "arm64")
+	s := memory.New()
+	copyInto(t, amd64Store, s, amd64)
+	copyInto(t, arm64Store, s, arm64)
+
+	amd64.Platform = &ocispec.Platform{Architecture: "amd64", OS: "linux"}
+	arm64.Platform = &ocispec.Platform{Architecture: "arm64", OS: "linux"}
+	arm64.Annotations = map[string]string{"purpose": "test"}
+	inner := pushIndex(t, s, arm64)
+	middle := pushIndex(t, s, inner)
+	root := pushIndex(t, s, amd64, middle)
+
+	got, err := FindManifest(ctx, s, root, FindManifestOptions{
+		Platform: &ocispec.Platform{Architecture: "arm64", OS: "linux"},
+	})
+	if err != nil {
+		t.Fatalf("FindManifest() error = %v", err)
+	}
+	if got.Digest != arm64.Digest {
+		t.Errorf("FindManifest() = %v, want %v", got.Digest, arm64.Digest)
+	}
+
+	got, err = FindManifest(ctx, s, root, FindManifestOptions{
+		Annotations: map[string]string{"purpose": "test"},
+	})
+	if err != nil {
+		t.Fatalf("FindManifest() error = %v", err)
+	}
+	if got.Digest != arm64.Digest {
+		t.Errorf("FindManifest() = %v, want %v", got.Digest, arm64.Digest)
+	}
+
+	_, err = FindManifest(ctx, s, root, FindManifestOptions{
+		Platform: &ocispec.Platform{Architecture: "s390x", OS: "linux"},
+	})
+	if !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("FindManifest() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+
+	_, err = FindManifest(ctx, s, root, FindManifestOptions{
+		Platform: &ocispec.Platform{Architecture: "arm64", OS: "linux"},
+		MaxDepth: 1,
+	})
+	if !errors.Is(err, ErrIndexTooDeep) {
+		t.Errorf("FindManifest() error = %v, want %v", err, ErrIndexTooDeep)
+	}
+
+	all, err := FindManifests(ctx, s, root, FindManifestOptions{
+		Match: func(desc ocispec.Descriptor) bool {
+			return desc.Platform != nil && desc.Platform.OS == "linux"
+		},
+	})
+	if err != nil {
+		t.Fatalf("FindManifests() error = %v", err)
+	}
+	if len(all) != 2 || all[0].Digest != amd64.Digest || all[1].Digest != arm64.Digest {
+		t.Errorf("FindManifests() = %v, want [%v %v]", all, amd64.Digest, arm64.Digest)
+	}
+}
+
+func TestFindManifest_RootManifest(t *testing.T) {
+	ctx := context.Background()
+	s, root := pushImage(t, []byte(`{"architecture":"amd64","os":"linux"}`), []byte("amd64"), "amd64")
+
+	got, err := FindManifest(ctx, s, root, FindManifestOptions{
+		Platform: &ocispec.Platform{Architecture: "amd64", OS: "linux"},
+	})
+	if err != nil {
+		t.Fatalf("FindManifest() error = %v", err)
+	}
+	if got.Digest != root.Digest {
+		t.Errorf("FindManifest() = %v, want %v", got.Digest, root.Digest)
+	}
+
+	_, err = FindManifest(ctx, s, root, FindManifestOptions{
+		Platform: &ocispec.Platform{Architecture: "arm64", OS: "linux"},
+	})
+	if !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("FindManifest() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+}
@@ -0,0 +1,287 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package orastest provides utilities for testing applications built on
+// oras-go, such as an in-memory fake Target recording calls and injecting
+// failures and latency.
+package orastest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/registry"
+)
+
+// Methods of Target recorded in Call.Method.
+const (
+	MethodFetch        = "Fetch"
+	MethodPush         = "Push"
+	MethodExists       = "Exists"
+	MethodResolve      = "Resolve"
+	MethodTag          = "Tag"
+	MethodDelete       = "Delete"
+	MethodPredecessors = "Predecessors"
+	MethodTags         = "Tags"
+	MethodReferrers    = "Referrers"
+)
+
+// ErrInjected is a generic error to be returned by Rule.Err.
+var ErrInjected = errors.New("injected failure")
+
+// Call is a recorded method call on Target.
+type Call struct {
+	// Method is the name of the called method, such as MethodPush.
+	Method string
+	// Descriptor is the descriptor argument, if any.
+	Descriptor ocispec.Descriptor
+	// Reference is the reference argument, if any.
+	Reference string
+	// Err is the error returned by the call.
+	Err error
+}
+
+// Rule scripts the behavior of the calls matching it.
+// Empty fields match any call.
+type Rule struct {
+	// Method matches the name of the called method.
+	Method string
+	// Digest matches the digest of the descriptor argument.
+	Digest digest.Digest
+	// Reference matches the reference argument.
+	Reference string
+
+	// Latency delays the matched calls. The delay is interrupted if the
+	// context is done, in which case the context error is returned.
+	Latency time.Duration
+	// Err, if not nil, is returned by the matched calls without calling
+	// the underlying store.
+	Err error
+	// Times limits the number of calls the rule applies to.
+	// If Times is 0, the rule applies to all the matched calls.
+	Times int
+}
+
+// Target is an in-memory fake Target for testing.
+// It records all the calls, and applies the rules to script failures and
+// latency. Target implements content.Deleter, content.PredecessorFinder,
+// registry.TagLister and registry.ReferrerLister.
+// Target is safe for concurrent use.
+type Target struct {
+	store *memory.Store
+
+	lock  sync.Mutex
+	calls []Call
+	rules []*Rule
+}
+
+// NewTarget creates a new empty Target.
+func NewTarget() *Target {
+	return &Target{
+		store: memory.New(),
+	}
+}
+
+// AddRule adds a rule scripting the matched calls.
+// Rules are evaluated in the order they are added. The latency of all the
+// matched rules is accumulated, and the error of the first matched rule with
+// an error is returned.
+func (t *Target) AddRule(rule Rule) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.rules = append(t.rules, &rule)
+}
+
+// Calls returns the recorded calls in order.
+func (t *Target) Calls() []Call {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return append([]Call(nil), t.calls...)
+}
+
+// CallsOf returns the recorded calls of the method in order.
+func (t *Target) CallsOf(method string) []Call {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	var calls []Call
+	for _, call := range t.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset clears the recorded calls and the rules.
+// The stored content is kept.
+func (t *Target) Reset() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.calls = nil
+	t.rules = nil
+}
+
+// Fetch fetches the content identified by the descriptor.
+func (t *Target) Fetch(ctx context.Context, target ocispec.Descriptor) (rc io.ReadCloser, err error) {
+	call := Call{Method: MethodFetch, Descriptor: target}
+	defer func() { t.record(call, err) }()
+	if err := t.apply(ctx, call); err != nil {
+		return nil, err
+	}
+	return t.store.Fetch(ctx, target)
+}
+
+// Push pushes the content, matching the expected descriptor.
+func (t *Target) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) (err error) {
+	call := Call{Method: MethodPush, Descriptor: expected}
+	defer func() { t.record(call, err) }()
+	if err := t.apply(ctx, call); err != nil {
+		return err
+	}
+	return t.store.Push(ctx, expected, content)
+}
+
+// Exists returns true if the described content exists.
+func (t *Target) Exists(ctx context.Context, target ocispec.Descriptor) (exists bool, err error) {
+	call := Call{Method: MethodExists, Descriptor: target}
+	defer func() { t.record(call, err) }()
+	if err := t.apply(ctx, call); err != nil {
+		return false, err
+	}
+	return t.store.Exists(ctx, target)
+}
+
+// Resolve resolves a reference to a descriptor.
+func (t *Target) Resolve(ctx context.Context, reference string) (desc ocispec.Descriptor, err error) {
+	call := Call{Method: MethodResolve, Reference: reference}
+	defer func() { t.record(call, err) }()
+	if err := t.apply(ctx, call); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return t.store.Resolve(ctx, reference)
+}
+
+// Tag tags a descriptor with a reference string.
+func (t *Target) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) (err error) {
+	call := Call{Method: MethodTag, Descriptor: desc, Reference: reference}
+	defer func() { t.record(call, err) }()
+	if err := t.apply(ctx, call); err != nil {
+		return err
+	}
+	return t.store.Tag(ctx, desc, reference)
+}
+
+// Delete removes the content identified by the descriptor, and removes all
+// the references tagging it.
+func (t *Target) Delete(ctx context.Context, target ocispec.Descriptor) (err error) {
+	call := Call{Method: MethodDelete, Descriptor: target}
+	defer func() { t.record(call, err) }()
+	if err := t.apply(ctx, call); err != nil {
+		return err
+	}
+	return t.store.Delete(ctx, target)
+}
+
+// Predecessors returns the nodes directly pointing to the current node.
+func (t *Target) Predecessors(ctx context.Context, node ocispec.Descriptor) (nodes []ocispec.Descriptor, err error) {
+	call := Call{Method: MethodPredecessors, Descriptor: node}
+	defer func() { t.record(call, err) }()
+	if err := t.apply(ctx, call); err != nil {
+		return nil, err
+	}
+	return t.store.Predecessors(ctx, node)
+}
+
+// Tags lists the tags in ascending order.
+func (t *Target) Tags(ctx context.Context, last string, fn func(tags []string) error) (err error) {
+	call := Call{Method: MethodTags, Reference: last}
+	defer func() { t.record(call, err) }()
+	if err := t.apply(ctx, call); err != nil {
+		return err
+	}
+	return t.store.Tags(ctx, last, fn)
+}
+
+// Referrers lists the manifests having the descriptor as their subject,
+// filtered by artifactType if not empty.
+func (t *Target) Referrers(ctx context.Context, desc ocispec.Descriptor, artifactType string, fn func(referrers []ocispec.Descriptor) error) (err error) {
+	call := Call{Method: MethodReferrers, Descriptor: desc}
+	defer func() { t.record(call, err) }()
+	if err := t.apply(ctx, call); err != nil {
+		return err
+	}
+	referrers, err := registry.Referrers(ctx, t.store, desc, artifactType)
+	if err != nil {
+		return err
+	}
+	return fn(referrers)
+}
+
+// apply applies the rules matching the call.
+func (t *Target) apply(ctx context.Context, call Call) error {
+	var latency time.Duration
+	var ruleErr error
+	t.lock.Lock()
+	for _, rule := range t.rules {
+		if !rule.matches(call) {
+			continue
+		}
+		if rule.Times > 0 {
+			rule.Times--
+			if rule.Times == 0 {
+				// exhausted after this call
+				rule.Times = -1
+			}
+		}
+		latency += rule.Latency
+		if ruleErr == nil {
+			ruleErr = rule.Err
+		}
+	}
+	t.lock.Unlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return ruleErr
+}
+
+// record records the call with its result.
+func (t *Target) record(call Call, err error) {
+	call.Err = err
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.calls = append(t.calls, call)
+}
+
+// matches returns true if the rule applies to the call.
+func (r *Rule) matches(call Call) bool {
+	return r.Times >= 0 &&
+		(r.Method == "" || r.Method == call.Method) &&
+		(r.Digest == "" || r.Digest == call.Descriptor.Digest) &&
+		(r.Reference == "" || r.Reference == call.Reference)
+}
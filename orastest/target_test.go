@@ -0,0 +1,187 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orastest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+)
+
+var (
+	_ oras.GraphTarget        = (*Target)(nil)
+	_ content.Deleter         = (*Target)(nil)
+	_ registry.TagLister      = (*Target)(nil)
+	_ registry.ReferrerLister = (*Target)(nil)
+)
+
+// pushTestImage pushes an image manifest with the subject into target.
+func pushTestImage(t *testing.T, target *Target, layer string, subject *ocispec.Descriptor) (ocispec.Descriptor, []ocispec.Descriptor) {
+	ctx := context.Background()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if exists, _ := target.store.Exists(ctx, desc); !exists {
+			if err := target.store.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+				t.Fatal("Store.Push() error =", err)
+			}
+		}
+		return desc
+	}
+	config := push(ocispec.MediaTypeImageConfig, []byte("{}"))
+	layerDesc := push(ocispec.MediaTypeImageLayer, []byte(layer))
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    []ocispec.Descriptor{layerDesc},
+		Subject:   subject,
+	})
+	if err != nil {
+		t.Fatal("json.Marshal() error =", err)
+	}
+	manifest := push(ocispec.MediaTypeImageManifest, manifestJSON)
+	return manifest, []ocispec.Descriptor{config, layerDesc, manifest}
+}
+
+func TestTarget_Copy(t *testing.T) {
+	ctx := context.Background()
+	src := NewTarget()
+	root, nodes := pushTestImage(t, src, "foo", nil)
+	if err := src.Tag(ctx, root, "latest"); err != nil {
+		t.Fatal("Target.Tag() error =", err)
+	}
+	src.Reset()
+
+	// test scripted failure
+	dst := NewTarget()
+	layer := nodes[1]
+	dst.AddRule(Rule{Method: MethodPush, Digest: layer.Digest, Err: ErrInjected, Times: 1})
+	if _, err := oras.Copy(ctx, src, "latest", dst, "v1", oras.CopyOptions{}); !errors.Is(err, ErrInjected) {
+		t.Fatalf("Copy() error = %v, wantErr %v", err, ErrInjected)
+	}
+	failed := dst.CallsOf(MethodPush)
+	var found bool
+	for _, call := range failed {
+		if call.Descriptor.Digest == layer.Digest {
+			found = errors.Is(call.Err, ErrInjected)
+		}
+	}
+	if !found {
+		t.Errorf("Target.Calls() = %v, want failed push of %s", failed, layer.Digest)
+	}
+	if calls := dst.CallsOf(MethodTag); len(calls) != 0 {
+		t.Errorf("Target.CallsOf(Tag) = %v, want none", calls)
+	}
+
+	// test the rule being exhausted
+	if _, err := oras.Copy(ctx, src, "latest", dst, "v1", oras.CopyOptions{}); err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+	if got, err := dst.Resolve(ctx, "v1"); err != nil || got.Digest != root.Digest {
+		t.Errorf("Target.Resolve() = %v, %v, want %v", got, err, root)
+	}
+	tags := dst.CallsOf(MethodTag)
+	want := []Call{{Method: MethodTag, Descriptor: root, Reference: "v1"}}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("Target.CallsOf(Tag) = %v, want %v", tags, want)
+	}
+	if calls := src.CallsOf(MethodResolve); len(calls) != 2 || calls[0].Reference != "latest" {
+		t.Errorf("Target.CallsOf(Resolve) = %v, want 2 calls on latest", calls)
+	}
+}
+
+func TestTarget_Latency(t *testing.T) {
+	target := NewTarget()
+	target.AddRule(Rule{Method: MethodResolve, Latency: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := target.Resolve(ctx, "latest"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Target.Resolve() error = %v, wantErr %v", err, context.DeadlineExceeded)
+	}
+
+	target.Reset()
+	target.AddRule(Rule{Reference: "latest", Latency: 10 * time.Millisecond})
+	start := time.Now()
+	target.Resolve(context.Background(), "latest")
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("Target.Resolve() took %v, want at least 10ms", elapsed)
+	}
+	start = time.Now()
+	target.Resolve(context.Background(), "other")
+	if elapsed := time.Since(start); elapsed >= 10*time.Millisecond {
+		t.Errorf("Target.Resolve() took %v, want no latency", elapsed)
+	}
+}
+
+func TestTarget_Optional(t *testing.T) {
+	ctx := context.Background()
+	target := NewTarget()
+	subject, _ := pushTestImage(t, target, "foo", nil)
+	referrer, _ := pushTestImage(t, target, "bar", &subject)
+	if err := target.Tag(ctx, subject, "v1"); err != nil {
+		t.Fatal("Target.Tag() error =", err)
+	}
+
+	var referrers []ocispec.Descriptor
+	if err := target.Referrers(ctx, subject, "", func(got []ocispec.Descriptor) error {
+		referrers = append(referrers, got...)
+		return nil
+	}); err != nil {
+		t.Fatal("Target.Referrers() error =", err)
+	}
+	if len(referrers) != 1 || referrers[0].Digest != referrer.Digest {
+		t.Errorf("Target.Referrers() = %v, want %v", referrers, referrer)
+	}
+
+	got, err := registry.Tags(ctx, target)
+	if err != nil {
+		t.Fatal("registry.Tags() error =", err)
+	}
+	if want := []string{"v1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("registry.Tags() = %v, want %v", got, want)
+	}
+
+	if err := target.Delete(ctx, referrer); err != nil {
+		t.Fatal("Target.Delete() error =", err)
+	}
+	if exists, err := target.Exists(ctx, referrer); err != nil || exists {
+		t.Errorf("Target.Exists() = %v, %v, want false", exists, err)
+	}
+
+	target.AddRule(Rule{Method: MethodTags, Err: ErrInjected})
+	if _, err := registry.Tags(ctx, target); !errors.Is(err, ErrInjected) {
+		t.Errorf("registry.Tags() error = %v, wantErr %v", err, ErrInjected)
+	}
+	methods := make(map[string]bool)
+	for _, call := range target.Calls() {
+		methods[call.Method] = true
+	}
+	for _, method := range []string{MethodTag, MethodReferrers, MethodTags, MethodDelete, MethodExists} {
+		if !methods[method] {
+			t.Errorf("Target.Calls() missing %s", method)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package override provides per-operation option overrides attached to a
+// context.
+//
+// A long-lived client, such as a remote.Repository, is configured once and
+// shared across operations. Operations with differing requirements attach
+// overrides to the context using WithOptions instead of reconstructing the
+// client. The overrides are consulted by oras.Copy and its variants, by
+// remote.Repository and by auth.Client.
+package override
+
+import (
+	"context"
+	"time"
+)
+
+// Options holds the option overrides for an operation.
+// Zero values leave the configured options unchanged.
+type Options struct {
+	// BlobTimeout limits the time to transfer a single blob. It applies to
+	// each node copied by oras.Copy and its variants, covering both fetching
+	// from the source and pushing to the destination, and to blob pushes of
+	// remote.Repository.
+	BlobTimeout time.Duration
+
+	// Concurrency overrides the maximum number of concurrent copy tasks of
+	// oras.CopyGraphOptions and the number of concurrent chunk transfers of
+	// remote.Repository. Parallel chunk transfers are not enabled by the
+	// override, and the override applies only to the repositories with
+	// UploadConcurrency or DownloadConcurrency greater than 1.
+	Concurrency int
+
+	// UserAgent overrides the User-Agent header of requests sent by
+	// auth.Client.
+	UserAgent string
+
+	// Scopes are added to the scope hints used by auth.Client for fetching
+	// bearer tokens. See auth.WithScopes.
+	Scopes []string
+}
+
+// optionsContextKey is the context key for the option overrides.
+type optionsContextKey struct{}
+
+// WithOptions returns a context with the option overrides attached.
+// The overrides are merged with the ones already attached to ctx, where the
+// non-zero fields of opts take precedence and the scopes are accumulated.
+func WithOptions(ctx context.Context, opts Options) context.Context {
+	merged := FromContext(ctx)
+	if opts.BlobTimeout > 0 {
+		merged.BlobTimeout = opts.BlobTimeout
+	}
+	if opts.Concurrency > 0 {
+		merged.Concurrency = opts.Concurrency
+	}
+	if opts.UserAgent != "" {
+		merged.UserAgent = opts.UserAgent
+	}
+	if len(opts.Scopes) > 0 {
+		merged.Scopes = append(merged.Scopes[:len(merged.Scopes):len(merged.Scopes)], opts.Scopes...)
+	}
+	return context.WithValue(ctx, optionsContextKey{}, merged)
+}
+
+// FromContext returns the option overrides attached to the context.
+// If no overrides are attached, zero Options is returned.
+func FromContext(ctx context.Context) Options {
+	opts, _ := ctx.Value(optionsContextKey{}).(Options)
+	return opts
+}
+
+// WithBlobTimeout returns a context bounded by the BlobTimeout override
+// attached to ctx, if any. The returned cancel function must be called once
+// the blob is transferred.
+func WithBlobTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if timeout := FromContext(ctx).BlobTimeout; timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return ctx, func() {}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package override
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWithOptions(t *testing.T) {
+	ctx := context.Background()
+	if got := FromContext(ctx); !reflect.DeepEqual(got, Options{}) {
+		t.Errorf("FromContext() = %v, want %v", got, Options{})
+	}
+
+	ctx = WithOptions(ctx, Options{
+		BlobTimeout: time.Minute,
+		Concurrency: 2,
+		UserAgent:   "foo",
+		Scopes:      []string{"repository:foo:pull"},
+	})
+	child := WithOptions(ctx, Options{
+		Concurrency: 5,
+		Scopes:      []string{"repository:bar:pull"},
+	})
+	want := Options{
+		BlobTimeout: time.Minute,
+		Concurrency: 5,
+		UserAgent:   "foo",
+		Scopes:      []string{"repository:foo:pull", "repository:bar:pull"},
+	}
+	if got := FromContext(child); !reflect.DeepEqual(got, want) {
+		t.Errorf("FromContext() = %v, want %v", got, want)
+	}
+
+	// the parent context is not affected
+	want = Options{
+		BlobTimeout: time.Minute,
+		Concurrency: 2,
+		UserAgent:   "foo",
+		Scopes:      []string{"repository:foo:pull"},
+	}
+	if got := FromContext(ctx); !reflect.DeepEqual(got, want) {
+		t.Errorf("FromContext() = %v, want %v", got, want)
+	}
+}
+
+func TestWithBlobTimeout(t *testing.T) {
+	ctx := context.Background()
+	got, cancel := WithBlobTimeout(ctx)
+	defer cancel()
+	if got != ctx {
+		t.Errorf("WithBlobTimeout() = %v, want %v", got, ctx)
+	}
+
+	ctx = WithOptions(ctx, Options{BlobTimeout: time.Minute})
+	got, cancel = WithBlobTimeout(ctx)
+	defer cancel()
+	deadline, ok := got.Deadline()
+	if !ok {
+		t.Fatal("WithBlobTimeout() has no deadline")
+	}
+	if remaining := time.Until(deadline); remaining <= 0 || remaining > time.Minute {
+		t.Errorf("WithBlobTimeout() deadline in %v, want within %v", remaining, time.Minute)
+	}
+}
@@ -23,8 +23,10 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/opencontainers/go-digest"
 	specs "github.com/opencontainers/image-spec/specs-go"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/annotation"
 	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/internal/spec"
@@ -43,12 +45,21 @@ const (
 // AnnotationArtifactCreated or AnnotationCreated is provided, but its value
 // is not in RFC 3339 format.
 // Reference: https://www.rfc-editor.org/rfc/rfc3339#section-5.6
-var ErrInvalidDateTimeFormat = errors.New("invalid date and time format")
+var ErrInvalidDateTimeFormat = annotation.ErrInvalidDateTimeFormat
 
 // PackOptions contains parameters for [oras.Pack].
 type PackOptions struct {
 	// Subject is the subject of the manifest.
 	Subject *ocispec.Descriptor
+	// VerifySubject, if true, verifies that Subject exists in the pusher
+	// before pushing the manifest, copying it from SubjectSource if it is
+	// missing, so that the manifest does not dangle. The pusher must be a
+	// content.Storage. See also EnsureSubject.
+	VerifySubject bool
+	// SubjectSource is the storage from which a missing Subject is copied
+	// when VerifySubject is true.
+	// If nil, a missing Subject is an error.
+	SubjectSource content.ReadOnlyStorage
 	// ManifestAnnotations is the annotation map of the manifest.
 	ManifestAnnotations map[string]string
 
@@ -67,6 +78,11 @@ type PackOptions struct {
 	// This option is valid only when PackImageManifest is true
 	// and ConfigDescriptor is nil.
 	ConfigAnnotations map[string]string
+	// DigestAlgorithm is the algorithm for digesting the generated manifest
+	// and config, which can be any algorithm registered by
+	// content.RegisterAlgorithm.
+	// Default: sha256.
+	DigestAlgorithm digest.Algorithm
 }
 
 // Pack packs the given blobs, generates a manifest for the pack,
@@ -76,6 +92,15 @@ type PackOptions struct {
 // the config descriptor mediaType of the image manifest.
 // If succeeded, returns a descriptor of the manifest.
 func Pack(ctx context.Context, pusher content.Pusher, artifactType string, blobs []ocispec.Descriptor, opts PackOptions) (ocispec.Descriptor, error) {
+	if opts.VerifySubject && opts.Subject != nil {
+		storage, ok := pusher.(content.Storage)
+		if !ok {
+			return ocispec.Descriptor{}, fmt.Errorf("subject verification on pusher: %w", errdef.ErrUnsupported)
+		}
+		if err := EnsureSubject(ctx, storage, *opts.Subject, EnsureSubjectOptions{Source: opts.SubjectSource}); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
 	if opts.PackImageManifest {
 		return packImage(ctx, pusher, artifactType, blobs, opts)
 	}
@@ -90,7 +115,7 @@ func packArtifact(ctx context.Context, pusher content.Pusher, artifactType strin
 		artifactType = MediaTypeUnknownArtifact
 	}
 
-	annotations, err := ensureAnnotationCreated(opts.ManifestAnnotations, ocispec.AnnotationArtifactCreated)
+	annotations, err := ensureAnnotationCreated(opts.ManifestAnnotations, annotation.ArtifactCreated, annotation.WithArtifactCreated)
 	if err != nil {
 		return ocispec.Descriptor{}, err
 	}
@@ -105,7 +130,10 @@ func packArtifact(ctx context.Context, pusher content.Pusher, artifactType strin
 	if err != nil {
 		return ocispec.Descriptor{}, fmt.Errorf("failed to marshal manifest: %w", err)
 	}
-	manifestDesc := content.NewDescriptorFromBytes(spec.MediaTypeArtifactManifest, manifestJSON)
+	manifestDesc, err := newDescriptorFromBytes(spec.MediaTypeArtifactManifest, manifestJSON, opts)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
 	// populate ArtifactType and Annotations of the manifest into manifestDesc
 	manifestDesc.ArtifactType = manifest.ArtifactType
 	manifestDesc.Annotations = manifest.Annotations
@@ -136,7 +164,11 @@ func packImage(ctx context.Context, pusher content.Pusher, configMediaType strin
 		// As of September 2022, GAR is known to return 400 on empty blob upload.
 		// See https://github.com/oras-project/oras-go/issues/294 for details.
 		configBytes := []byte("{}")
-		configDesc = content.NewDescriptorFromBytes(configMediaType, configBytes)
+		var err error
+		configDesc, err = newDescriptorFromBytes(configMediaType, configBytes, opts)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
 		configDesc.Annotations = opts.ConfigAnnotations
 		// push config
 		if err := pusher.Push(ctx, configDesc, bytes.NewReader(configBytes)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
@@ -144,7 +176,7 @@ func packImage(ctx context.Context, pusher content.Pusher, configMediaType strin
 		}
 	}
 
-	annotations, err := ensureAnnotationCreated(opts.ManifestAnnotations, ocispec.AnnotationCreated)
+	annotations, err := ensureAnnotationCreated(opts.ManifestAnnotations, annotation.Created, annotation.WithCreated)
 	if err != nil {
 		return ocispec.Descriptor{}, err
 	}
@@ -165,7 +197,10 @@ func packImage(ctx context.Context, pusher content.Pusher, configMediaType strin
 	if err != nil {
 		return ocispec.Descriptor{}, fmt.Errorf("failed to marshal manifest: %w", err)
 	}
-	manifestDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifestJSON)
+	manifestDesc, err := newDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifestJSON, opts)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
 	// populate ArtifactType and Annotations of the manifest into manifestDesc
 	manifestDesc.ArtifactType = manifest.Config.MediaType
 	manifestDesc.Annotations = manifest.Annotations
@@ -178,26 +213,34 @@ func packImage(ctx context.Context, pusher content.Pusher, configMediaType strin
 	return manifestDesc, nil
 }
 
-// ensureAnnotationCreated ensures that annotationCreatedKey is in annotations,
-// and that its value conforms to RFC 3339. Otherwise returns a new annotation
-// map with annotationCreatedKey created.
-func ensureAnnotationCreated(annotations map[string]string, annotationCreatedKey string) (map[string]string, error) {
-	if createdTime, ok := annotations[annotationCreatedKey]; ok {
-		// if annotationCreatedKey is provided, validate its format
-		if _, err := time.Parse(time.RFC3339, createdTime); err != nil {
-			return nil, fmt.Errorf("%w: %v", ErrInvalidDateTimeFormat, err)
-		}
+// ensureAnnotationCreated ensures that the creation time annotation read by
+// created is in annotations, and that its value conforms to RFC 3339.
+// Otherwise returns a new annotation map with the creation time set by
+// withCreated.
+// Reference: https://github.com/opencontainers/image-spec/blob/v1.1.0-rc2/annotations.md#pre-defined-annotation-keys
+func ensureAnnotationCreated(annotations map[string]string, created func(map[string]string) (time.Time, bool, error), withCreated func(map[string]string, time.Time) map[string]string) (map[string]string, error) {
+	// if the creation time is provided, validate its format
+	_, ok, err := created(annotations)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
 		return annotations, nil
 	}
+	return withCreated(annotations, time.Now()), nil
+}
 
-	// copy the original annotation map
-	copied := make(map[string]string, len(annotations)+1)
-	for k, v := range annotations {
-		copied[k] = v
+// newDescriptorFromBytes returns a descriptor of the content digested by the
+// algorithm specified in opts.
+func newDescriptorFromBytes(mediaType string, data []byte, opts PackOptions) (ocispec.Descriptor, error) {
+	desc := content.NewDescriptorFromBytes(mediaType, data)
+	if opts.DigestAlgorithm == "" || opts.DigestAlgorithm == desc.Digest.Algorithm() {
+		return desc, nil
+	}
+	dgst, err := content.ComputeDigest(opts.DigestAlgorithm, data)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to digest %s: %w", mediaType, err)
 	}
-	// set creation time in RFC 3339 format
-	// reference: https://github.com/opencontainers/image-spec/blob/v1.1.0-rc2/annotations.md#pre-defined-annotation-keys
-	now := time.Now().UTC()
-	copied[annotationCreatedKey] = now.Format(time.RFC3339)
-	return copied, nil
+	desc.Digest = dgst
+	return desc, nil
 }
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"hash/fnv"
 	"io"
 	"reflect"
 	"testing"
@@ -475,3 +476,59 @@ func Test_Pack_Image_InvalidDateTimeFormat(t *testing.T) {
 		t.Errorf("Oras.Pack() error = %v, wantErr = %v", err, ErrInvalidDateTimeFormat)
 	}
 }
+
+func Test_Pack_DigestAlgorithm(t *testing.T) {
+	alg := digest.Algorithm("fnv128a")
+	if !content.AlgorithmAvailable(alg) {
+		if err := content.RegisterAlgorithm(alg, fnv.New128a); err != nil {
+			t.Fatal("content.RegisterAlgorithm() error =", err)
+		}
+	}
+	s := memory.New()
+
+	// prepare test content
+	blobs := []ocispec.Descriptor{
+		content.NewDescriptorFromBytes("test", []byte("hello world")),
+	}
+	artifactType := "application/vnd.test"
+
+	// test Pack
+	ctx := context.Background()
+	opts := PackOptions{
+		PackImageManifest: true,
+		DigestAlgorithm:   alg,
+	}
+	manifestDesc, err := Pack(ctx, s, artifactType, blobs, opts)
+	if err != nil {
+		t.Fatal("Oras.Pack() error =", err)
+	}
+	if got := manifestDesc.Digest.Algorithm(); got != alg {
+		t.Errorf("manifest digest algorithm = %v, want %v", got, alg)
+	}
+
+	// test config and manifest are digested by the algorithm
+	manifestJSON, err := content.FetchAll(ctx, s, manifestDesc)
+	if err != nil {
+		t.Fatal("Store.Fetch() error =", err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		t.Fatal("error decoding manifest, error =", err)
+	}
+	if got := manifest.Config.Digest.Algorithm(); got != alg {
+		t.Errorf("config digest algorithm = %v, want %v", got, alg)
+	}
+	if _, err := content.FetchAll(ctx, s, manifest.Config); err != nil {
+		t.Error("Store.Fetch() error =", err)
+	}
+
+	// test unsupported algorithm
+	opts.DigestAlgorithm = "fnv64"
+	if _, err := Pack(ctx, s, artifactType, blobs, opts); !errors.Is(err, digest.ErrDigestUnsupported) {
+		t.Errorf("Oras.Pack() error = %v, wantErr %v", err, digest.ErrDigestUnsupported)
+	}
+	opts.PackImageManifest = false
+	if _, err := Pack(ctx, s, artifactType, blobs, opts); !errors.Is(err, digest.ErrDigestUnsupported) {
+		t.Errorf("Oras.Pack() error = %v, wantErr %v", err, digest.ErrDigestUnsupported)
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/internal/cas"
+	"oras.land/oras-go/v2/internal/container/set"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/syncutil"
+)
+
+// CopyPlan describes what a copy of a graph would do.
+type CopyPlan struct {
+	// Root is the root node of the graph.
+	Root ocispec.Descriptor
+	// Copy are the nodes missing in the destination, which would be copied.
+	// The nodes are listed level by level from the root, and therefore in
+	// the reverse order of the copy.
+	Copy []ocispec.Descriptor
+	// Skip are the nodes existing in the destination, whose sub-DAGs would
+	// be skipped. The nodes of the skipped sub-DAGs are not walked and not
+	// listed.
+	Skip []ocispec.Descriptor
+	// CopySize is the total size of the nodes to be copied.
+	CopySize int64
+}
+
+// Plan walks the graph rooted at root like CopyGraph, and reports the nodes
+// which would be copied or skipped, without transferring any content to the
+// destination. It is useful to review the changes or to estimate the size of
+// the transfer before copying.
+//
+// The manifests missing in the destination are fetched from the source to
+// find their successors. The existence of the nodes is checked level by
+// level with up to opts.Concurrency concurrent checks, and
+// opts.ExistenceCache is consulted if set. opts.FindSuccessors,
+// opts.NodeFilter, opts.ForeignLayerPolicy and opts.MaxDepth are respected,
+// while the callbacks of opts, such as PreCopy, are not called.
+func Plan(ctx context.Context, src content.ReadOnlyStorage, dst content.ReadOnlyStorage, root ocispec.Descriptor, opts CopyGraphOptions) (*CopyPlan, error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	findSuccessors := opts.FindSuccessors
+	if findSuccessors == nil {
+		findSuccessors = content.Successors
+	}
+	if opts.NodeFilter != nil {
+		findSuccessors = filterSuccessors(findSuccessors, opts.NodeFilter)
+	}
+	if opts.MaxMetadataBytes <= 0 {
+		opts.MaxMetadataBytes = defaultCopyMaxMetadataBytes
+	}
+	proxy := cas.NewProxyWithLimit(src, cas.NewMemory(), opts.MaxMetadataBytes)
+	exists := dst.Exists
+	if opts.ExistenceCache != nil {
+		exists = (&existenceCachedStorage{
+			Storage: readOnlyStorage{ReadOnlyStorage: dst},
+			cache:   opts.ExistenceCache,
+		}).Exists
+	}
+
+	plan := &CopyPlan{Root: root}
+	visited := set.New[descriptor.Descriptor]()
+	visited.Add(descriptor.FromOCI(root))
+	level := []ocispec.Descriptor{root}
+	for depth := 0; len(level) > 0; depth++ {
+		expand := opts.MaxDepth == 0 || depth < opts.MaxDepth
+		var mu sync.Mutex // protects plan and next
+		var next []ocispec.Descriptor
+		eg, egCtx := syncutil.LimitGroup(ctx, concurrency)
+		for _, node := range level {
+			node := node
+			eg.Go(func() error {
+				existing, err := exists(egCtx, node)
+				if err != nil {
+					return err
+				}
+				if existing {
+					mu.Lock()
+					defer mu.Unlock()
+					plan.Skip = append(plan.Skip, node)
+					return nil
+				}
+				var successors []ocispec.Descriptor
+				if expand {
+					successors, err = findSuccessors(egCtx, proxy, node)
+					if err != nil {
+						return err
+					}
+					successors, err = applyForeignLayerPolicy(egCtx, successors, opts.ForeignLayerPolicy)
+					if err != nil {
+						return err
+					}
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				plan.Copy = append(plan.Copy, node)
+				plan.CopySize += node.Size
+				next = append(next, successors...)
+				return nil
+			})
+		}
+		if err := eg.Wait(); err != nil {
+			return nil, err
+		}
+
+		level = level[:0]
+		for _, node := range next {
+			key := descriptor.FromOCI(node)
+			if visited.Contains(key) {
+				continue
+			}
+			visited.Add(key)
+			level = append(level, node)
+		}
+	}
+	return plan, nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestPlan(t *testing.T) {
+	ctx := context.Background()
+	config := []byte("{}")
+	layer := []byte("hello world")
+	src, manifest := pushImage(t, config, layer, "latest")
+	configDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, config)
+	layerDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+	index := pushIndex(t, src, manifest)
+
+	// the config exists in the destination
+	dst := memory.New()
+	if err := dst.Push(ctx, configDesc, bytes.NewReader(config)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+
+	for _, opts := range []CopyGraphOptions{
+		DefaultCopyGraphOptions,
+		{ExistenceCache: NewExistenceCache()},
+	} {
+		plan, err := Plan(ctx, src, dst, index, opts)
+		if err != nil {
+			t.Fatal("Plan() error =", err)
+		}
+		wantCopy := []ocispec.Descriptor{index, manifest, layerDesc}
+		if len(plan.Copy) != len(wantCopy) {
+			t.Fatalf("CopyPlan.Copy = %v, want %v", plan.Copy, wantCopy)
+		}
+		for i, desc := range wantCopy {
+			if !content.Equal(plan.Copy[i], desc) {
+				t.Errorf("CopyPlan.Copy[%d] = %v, want %v", i, plan.Copy[i], desc)
+			}
+		}
+		if len(plan.Skip) != 1 || !content.Equal(plan.Skip[0], configDesc) {
+			t.Errorf("CopyPlan.Skip = %v, want %v", plan.Skip, []ocispec.Descriptor{configDesc})
+		}
+		if want := index.Size + manifest.Size + layerDesc.Size; plan.CopySize != want {
+			t.Errorf("CopyPlan.CopySize = %d, want %d", plan.CopySize, want)
+		}
+	}
+
+	// nothing is transferred
+	for _, desc := range []ocispec.Descriptor{index, manifest, layerDesc} {
+		if exists, err := dst.Exists(ctx, desc); err != nil || exists {
+			t.Errorf("Store.Exists(%s) = %v, %v, want false, nil", desc.Digest, exists, err)
+		}
+	}
+
+	// existing root skips the whole graph
+	if err := CopyGraph(ctx, src, dst, index, DefaultCopyGraphOptions); err != nil {
+		t.Fatal("CopyGraph() error =", err)
+	}
+	plan, err := Plan(ctx, src, dst, index, DefaultCopyGraphOptions)
+	if err != nil {
+		t.Fatal("Plan() error =", err)
+	}
+	if len(plan.Copy) != 0 || len(plan.Skip) != 1 || plan.CopySize != 0 {
+		t.Errorf("Plan() = %+v, want root skipped only", plan)
+	}
+}
@@ -0,0 +1,214 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package policy provides rules restricting the content allowed to flow
+// through ORAS operations.
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"sync/atomic"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/registry"
+)
+
+// ErrDenied is returned when content is denied by a policy.
+var ErrDenied = errors.New("denied by policy")
+
+// SizeLimitError is returned when the size of a node or a graph exceeds the
+// limit of a policy.
+// It matches both ErrDenied and errdef.ErrSizeExceedsLimit by errors.Is.
+type SizeLimitError struct {
+	// Descriptor is the node exceeding the limit, or the root node of the
+	// graph exceeding the limit.
+	Descriptor ocispec.Descriptor
+	// Size is the size of the node, or the total size of the graph.
+	Size int64
+	// Limit is the exceeded limit.
+	Limit int64
+	// Graph indicates that the size is the total size of the graph.
+	Graph bool
+}
+
+// Error returns the error message.
+func (e *SizeLimitError) Error() string {
+	kind := "size"
+	if e.Graph {
+		kind = "graph size"
+	}
+	return fmt.Sprintf("%s: %s: %s %d exceeds limit %d: %v", e.Descriptor.Digest, e.Descriptor.MediaType, kind, e.Size, e.Limit, ErrDenied)
+}
+
+// Is returns true if target is ErrDenied or errdef.ErrSizeExceedsLimit.
+func (e *SizeLimitError) Is(target error) bool {
+	return target == ErrDenied || target == errdef.ErrSizeExceedsLimit
+}
+
+// Policy describes the content allowed to flow through ORAS operations.
+// The zero value allows any content.
+type Policy struct {
+	// AllowedMediaTypes lists the allowed media types.
+	// Each entry is either a media type or a pattern in the syntax of
+	// path.Match, such as "application/vnd.oci.image.layer.*".
+	// If empty, all media types are allowed.
+	AllowedMediaTypes []string
+	// MaxBlobSize limits the maximum size of a single node.
+	// If less than or equal to 0, the size is not limited.
+	MaxBlobSize int64
+	// MaxGraphSize limits the maximum total size of the nodes in a single
+	// graph, computed from the descriptors by a pre-flight walk of the graph
+	// before any content is copied.
+	// If less than or equal to 0, the total size is not limited.
+	MaxGraphSize int64
+	// MaxNodes limits the maximum number of nodes copied in a single graph.
+	// If less than or equal to 0, the number of nodes is not limited.
+	MaxNodes int
+	// AllowedRegistries lists the registries allowed as the source of
+	// content, such as "registry.example.com" or "localhost:5000".
+	// If empty, all registries are allowed.
+	AllowedRegistries []string
+}
+
+// CheckDescriptor checks if the media type and the size of desc are allowed
+// by the policy.
+func (p *Policy) CheckDescriptor(desc ocispec.Descriptor) error {
+	if p.MaxBlobSize > 0 && desc.Size > p.MaxBlobSize {
+		return &SizeLimitError{
+			Descriptor: desc,
+			Size:       desc.Size,
+			Limit:      p.MaxBlobSize,
+		}
+	}
+	if len(p.AllowedMediaTypes) == 0 {
+		return nil
+	}
+	for _, pattern := range p.AllowedMediaTypes {
+		if matched, _ := path.Match(pattern, desc.MediaType); matched {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: %s: media type not allowed: %w", desc.Digest, desc.MediaType, ErrDenied)
+}
+
+// CheckRegistry checks if the registry is allowed as a source by the policy.
+func (p *Policy) CheckRegistry(registry string) error {
+	if len(p.AllowedRegistries) == 0 {
+		return nil
+	}
+	for _, allowed := range p.AllowedRegistries {
+		if registry == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("registry %q not allowed: %w", registry, ErrDenied)
+}
+
+// CheckReference checks if the registry of the reference is allowed as a
+// source by the policy.
+// The reference must be fully qualified, such as
+// "registry.example.com/hello-world:v1".
+func (p *Policy) CheckReference(reference string) error {
+	ref, err := registry.ParseReference(reference)
+	if err != nil {
+		return err
+	}
+	return p.CheckRegistry(ref.Registry)
+}
+
+// CheckGraph walks the graph rooted at root, fetching the manifests from
+// fetcher, and checks if the nodes, the number of the nodes and the total size
+// of the graph are allowed by the policy, before any content of the graph is
+// transferred.
+// Nodes shared in the graph are counted once.
+func (p *Policy) CheckGraph(ctx context.Context, fetcher content.Fetcher, root ocispec.Descriptor) error {
+	visited := make(map[descriptor.Descriptor]bool)
+	var total int64
+	queue := []ocispec.Descriptor{root}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		key := descriptor.FromOCI(node)
+		if visited[key] {
+			continue
+		}
+		visited[key] = true
+		if p.MaxNodes > 0 && len(visited) > p.MaxNodes {
+			return fmt.Errorf("%s: %s: number of nodes exceeds limit %d: %w", root.Digest, root.MediaType, p.MaxNodes, ErrDenied)
+		}
+		if err := p.CheckDescriptor(node); err != nil {
+			return err
+		}
+		total += node.Size
+		if p.MaxGraphSize > 0 && total > p.MaxGraphSize {
+			return &SizeLimitError{
+				Descriptor: root,
+				Size:       total,
+				Limit:      p.MaxGraphSize,
+				Graph:      true,
+			}
+		}
+		successors, err := content.Successors(ctx, fetcher, node)
+		if err != nil {
+			return err
+		}
+		queue = append(queue, successors...)
+	}
+	return nil
+}
+
+// Enforce configures opts to deny the nodes violating the policy.
+// Nodes are checked before they are copied, and the copy is aborted with
+// ErrDenied on the first violation.
+// If MaxGraphSize is set, the graph is checked by CheckGraph on visiting the
+// root node, so that no content is transferred if the graph is too large.
+//
+// The number of nodes is counted across all the copies using opts. Therefore,
+// opts should be used for a single copy when MaxNodes is set.
+func (p *Policy) Enforce(opts *oras.CopyGraphOptions) {
+	var count int64
+	findSuccessors := opts.FindSuccessors
+	if findSuccessors == nil {
+		findSuccessors = content.Successors
+	}
+	var checkGraph sync.Once
+	var checkGraphErr error
+	opts.FindSuccessors = func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		if p.MaxGraphSize > 0 {
+			// the root node is the first node to visit
+			checkGraph.Do(func() {
+				checkGraphErr = p.CheckGraph(ctx, fetcher, desc)
+			})
+			if checkGraphErr != nil {
+				return nil, checkGraphErr
+			}
+		}
+		if p.MaxNodes > 0 && atomic.AddInt64(&count, 1) > int64(p.MaxNodes) {
+			return nil, fmt.Errorf("%s: %s: number of nodes exceeds limit %d: %w", desc.Digest, desc.MediaType, p.MaxNodes, ErrDenied)
+		}
+		if err := p.CheckDescriptor(desc); err != nil {
+			return nil, err
+		}
+		return findSuccessors(ctx, fetcher, desc)
+	}
+}
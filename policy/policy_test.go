@@ -0,0 +1,299 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestPolicy_CheckDescriptor(t *testing.T) {
+	blob := []byte("hello world")
+	layer := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayerGzip, blob)
+	config := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, blob)
+	tests := []struct {
+		name    string
+		policy  Policy
+		desc    ocispec.Descriptor
+		wantErr bool
+	}{
+		{
+			name:   "zero policy",
+			policy: Policy{},
+			desc:   layer,
+		},
+		{
+			name: "allowed media type",
+			policy: Policy{
+				AllowedMediaTypes: []string{ocispec.MediaTypeImageLayerGzip},
+			},
+			desc: layer,
+		},
+		{
+			name: "allowed media type pattern",
+			policy: Policy{
+				AllowedMediaTypes: []string{"application/vnd.oci.image.layer.*"},
+			},
+			desc: layer,
+		},
+		{
+			name: "denied media type",
+			policy: Policy{
+				AllowedMediaTypes: []string{"application/vnd.oci.image.layer.*"},
+			},
+			desc:    config,
+			wantErr: true,
+		},
+		{
+			name: "size within limit",
+			policy: Policy{
+				MaxBlobSize: int64(len(blob)),
+			},
+			desc: layer,
+		},
+		{
+			name: "size exceeds limit",
+			policy: Policy{
+				MaxBlobSize: int64(len(blob)) - 1,
+			},
+			desc:    layer,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.CheckDescriptor(tt.desc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Policy.CheckDescriptor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrDenied) {
+				t.Errorf("Policy.CheckDescriptor() error = %v, want %v", err, ErrDenied)
+			}
+		})
+	}
+}
+
+func TestPolicy_CheckReference(t *testing.T) {
+	p := Policy{
+		AllowedRegistries: []string{"registry.example.com", "localhost:5000"},
+	}
+	for _, ref := range []string{
+		"registry.example.com/hello-world:v1",
+		"localhost:5000/hello-world@sha256:b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9",
+	} {
+		if err := p.CheckReference(ref); err != nil {
+			t.Errorf("Policy.CheckReference(%q) error = %v", ref, err)
+		}
+	}
+	if err := p.CheckReference("evil.example.com/hello-world:v1"); !errors.Is(err, ErrDenied) {
+		t.Errorf("Policy.CheckReference() error = %v, want %v", err, ErrDenied)
+	}
+	if err := p.CheckReference("invalid"); err == nil || errors.Is(err, ErrDenied) {
+		t.Errorf("Policy.CheckReference() error = %v, want invalid reference error", err)
+	}
+	if err := (&Policy{}).CheckRegistry("evil.example.com"); err != nil {
+		t.Errorf("Policy.CheckRegistry() error = %v, want nil", err)
+	}
+}
+
+// pushGraph pushes an image with the given layers to the storage, and returns
+// the descriptor of the manifest.
+func pushGraph(t *testing.T, storage *memory.Store, layers ...[]byte) ocispec.Descriptor {
+	ctx := context.Background()
+	config, err := oras.PushBytes(ctx, storage, ocispec.MediaTypeImageConfig, []byte("{}"))
+	if err != nil {
+		t.Fatal("PushBytes() error =", err)
+	}
+	var layerDescs []ocispec.Descriptor
+	for _, layer := range layers {
+		desc, err := oras.PushBytes(ctx, storage, ocispec.MediaTypeImageLayer, layer)
+		if err != nil {
+			t.Fatal("PushBytes() error =", err)
+		}
+		layerDescs = append(layerDescs, desc)
+	}
+	manifest, err := oras.Pack(ctx, storage, "", layerDescs, oras.PackOptions{
+		PackImageManifest: true,
+		ConfigDescriptor:  &config,
+	})
+	if err != nil {
+		t.Fatal("Pack() error =", err)
+	}
+	return manifest
+}
+
+func TestPolicy_Enforce(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	root := pushGraph(t, src, []byte("foo"), []byte("bar"))
+
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr bool
+	}{
+		{
+			name: "allowed",
+			policy: Policy{
+				AllowedMediaTypes: []string{
+					ocispec.MediaTypeImageManifest,
+					ocispec.MediaTypeImageConfig,
+					ocispec.MediaTypeImageLayer,
+				},
+				MaxNodes: 4,
+			},
+		},
+		{
+			name: "denied media type",
+			policy: Policy{
+				AllowedMediaTypes: []string{
+					ocispec.MediaTypeImageManifest,
+					ocispec.MediaTypeImageConfig,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "too many nodes",
+			policy: Policy{
+				MaxNodes: 3,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := memory.New()
+			opts := oras.CopyGraphOptions{}
+			tt.policy.Enforce(&opts)
+			err := oras.CopyGraph(ctx, src, dst, root, opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CopyGraph() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrDenied) {
+					t.Errorf("CopyGraph() error = %v, want %v", err, ErrDenied)
+				}
+				return
+			}
+			exists, err := dst.Exists(ctx, root)
+			if err != nil {
+				t.Fatal("dst.Exists() error =", err)
+			}
+			if !exists {
+				t.Errorf("dst.Exists() = %v, want %v", exists, true)
+			}
+		})
+	}
+}
+
+func TestPolicy_Enforce_FindSuccessors(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	root := pushGraph(t, src, bytes.Repeat([]byte("a"), 10))
+
+	var visited []digest.Digest
+	opts := oras.CopyGraphOptions{
+		Concurrency: 1,
+		FindSuccessors: func(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+			visited = append(visited, desc.Digest)
+			return content.Successors(ctx, fetcher, desc)
+		},
+	}
+	p := Policy{
+		MaxBlobSize: 5,
+	}
+	p.Enforce(&opts)
+	if err := oras.CopyGraph(ctx, src, memory.New(), root, opts); !errors.Is(err, ErrDenied) {
+		t.Fatalf("CopyGraph() error = %v, want %v", err, ErrDenied)
+	}
+	// the oversized manifest is denied before finding its successors
+	if len(visited) != 0 {
+		t.Errorf("visited = %v, want none", visited)
+	}
+}
+
+func TestPolicy_CheckGraph(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	root := pushGraph(t, src, bytes.Repeat([]byte("a"), 1000), []byte("b"))
+	graphSize := root.Size + 2 + 1000 + 1
+
+	if err := (&Policy{MaxGraphSize: graphSize}).CheckGraph(ctx, src, root); err != nil {
+		t.Errorf("CheckGraph() error = %v", err)
+	}
+
+	err := (&Policy{MaxGraphSize: graphSize - 1}).CheckGraph(ctx, src, root)
+	var sizeErr *SizeLimitError
+	if !errors.As(err, &sizeErr) {
+		t.Fatalf("CheckGraph() error = %v, want %T", err, sizeErr)
+	}
+	if !sizeErr.Graph || sizeErr.Limit != graphSize-1 || !content.Equal(sizeErr.Descriptor, root) {
+		t.Errorf("CheckGraph() error = %+v", sizeErr)
+	}
+	if !errors.Is(err, ErrDenied) || !errors.Is(err, errdef.ErrSizeExceedsLimit) {
+		t.Errorf("CheckGraph() error = %v, want %v and %v", err, ErrDenied, errdef.ErrSizeExceedsLimit)
+	}
+
+	err = (&Policy{MaxBlobSize: 999}).CheckGraph(ctx, src, root)
+	if !errors.As(err, &sizeErr) || sizeErr.Graph || sizeErr.Size != 1000 {
+		t.Errorf("CheckGraph() error = %v, want blob size limit error", err)
+	}
+
+	if err := (&Policy{MaxNodes: 3}).CheckGraph(ctx, src, root); !errors.Is(err, ErrDenied) {
+		t.Errorf("CheckGraph() error = %v, want %v", err, ErrDenied)
+	}
+}
+
+func TestPolicy_Enforce_MaxGraphSize(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	root := pushGraph(t, src, bytes.Repeat([]byte("a"), 100))
+
+	var copied []ocispec.Descriptor
+	opts := oras.CopyGraphOptions{
+		PreCopy: func(ctx context.Context, desc ocispec.Descriptor) error {
+			copied = append(copied, desc)
+			return nil
+		},
+	}
+	p := Policy{MaxGraphSize: 100}
+	p.Enforce(&opts)
+	dst := memory.New()
+	if err := oras.CopyGraph(ctx, src, dst, root, opts); !errors.Is(err, errdef.ErrSizeExceedsLimit) {
+		t.Fatalf("CopyGraph() error = %v, want %v", err, errdef.ErrSizeExceedsLimit)
+	}
+	// no content is transferred
+	if len(copied) != 0 {
+		t.Errorf("copied = %v, want none", copied)
+	}
+
+	opts = oras.CopyGraphOptions{}
+	p = Policy{MaxGraphSize: 1 << 20}
+	p.Enforce(&opts)
+	if err := oras.CopyGraph(ctx, src, dst, root, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v", err)
+	}
+}
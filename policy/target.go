@@ -0,0 +1,59 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+)
+
+// target is a Target enforcing a policy on the pushed and tagged content.
+type target struct {
+	oras.Target
+	policy *Policy
+}
+
+// NewTarget wraps t to deny pushing or tagging content violating the policy
+// with ErrDenied.
+// MaxNodes and AllowedRegistries are not applicable to a single target, and
+// therefore are not enforced.
+func NewTarget(t oras.Target, p *Policy) oras.Target {
+	return &target{
+		Target: t,
+		policy: p,
+	}
+}
+
+// Push pushes the content, matching the expected descriptor, if it is allowed
+// by the policy.
+func (t *target) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	if err := t.policy.CheckDescriptor(expected); err != nil {
+		return err
+	}
+	return t.Target.Push(ctx, expected, content)
+}
+
+// Tag tags a descriptor with a reference string if it is allowed by the
+// policy.
+func (t *target) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	if err := t.policy.CheckDescriptor(desc); err != nil {
+		return err
+	}
+	return t.Target.Tag(ctx, desc, reference)
+}
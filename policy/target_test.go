@@ -0,0 +1,77 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestTarget(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	target := NewTarget(store, &Policy{
+		AllowedMediaTypes: []string{ocispec.MediaTypeImageLayer},
+		MaxBlobSize:       5,
+	})
+
+	allowed := []byte("foo")
+	allowedDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, allowed)
+	if err := target.Push(ctx, allowedDesc, bytes.NewReader(allowed)); err != nil {
+		t.Fatal("Target.Push() error =", err)
+	}
+	if err := target.Tag(ctx, allowedDesc, "foo"); err != nil {
+		t.Fatal("Target.Tag() error =", err)
+	}
+
+	oversized := []byte("foobar")
+	oversizedDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, oversized)
+	if err := target.Push(ctx, oversizedDesc, bytes.NewReader(oversized)); !errors.Is(err, ErrDenied) {
+		t.Errorf("Target.Push() error = %v, want %v", err, ErrDenied)
+	}
+	if exists, _ := store.Exists(ctx, oversizedDesc); exists {
+		t.Errorf("denied content %v is pushed", oversizedDesc)
+	}
+
+	config := []byte("{}")
+	configDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, config)
+	if err := store.Push(ctx, configDesc, bytes.NewReader(config)); err != nil {
+		t.Fatal("Store.Push() error =", err)
+	}
+	if err := target.Tag(ctx, configDesc, "config"); !errors.Is(err, ErrDenied) {
+		t.Errorf("Target.Tag() error = %v, want %v", err, ErrDenied)
+	}
+	if _, err := store.Resolve(ctx, "config"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Store.Resolve() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+
+	// copy to the policy target
+	src := memory.New()
+	if _, err := oras.TagBytes(ctx, src, ocispec.MediaTypeImageLayer, oversized, "latest"); err != nil {
+		t.Fatal("TagBytes() error =", err)
+	}
+	if _, err := oras.Copy(ctx, src, "latest", target, "", oras.DefaultCopyOptions); !errors.Is(err, ErrDenied) {
+		t.Errorf("Copy() error = %v, want %v", err, ErrDenied)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/internal/container/set"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/syncutil"
+)
+
+// preflightExistence checks the existence of the nodes of the graph rooted
+// by root in the destination, level by level, with up to
+// opts.PreflightConcurrency concurrent checks.
+// The successors of the nodes missing in the destination are found via the
+// caching fetcher, so that the copy phase does not fetch them again.
+// The sub-DAGs rooted by existing nodes are not walked.
+func preflightExistence(ctx context.Context, fetcher content.Fetcher, dst content.Storage, root ocispec.Descriptor, opts CopyGraphOptions) (map[descriptor.Descriptor]bool, error) {
+	results := make(map[descriptor.Descriptor]bool)
+	visited := set.New[descriptor.Descriptor]()
+	visited.Add(descriptor.FromOCI(root))
+	level := []ocispec.Descriptor{root}
+	for len(level) > 0 {
+		// check the existence of the nodes in the level
+		exists := make([]bool, len(level))
+		eg, egCtx := syncutil.LimitGroup(ctx, opts.PreflightConcurrency)
+		for i, desc := range level {
+			i, desc := i, desc
+			eg.Go(func() error {
+				var err error
+				exists[i], err = dst.Exists(egCtx, desc)
+				return err
+			})
+		}
+		if err := eg.Wait(); err != nil {
+			return nil, err
+		}
+
+		// find the successors of the missing nodes
+		var lock sync.Mutex
+		var next []ocispec.Descriptor
+		eg, egCtx = syncutil.LimitGroup(ctx, opts.PreflightConcurrency)
+		for i, desc := range level {
+			results[descriptor.FromOCI(desc)] = exists[i]
+			if exists[i] {
+				continue
+			}
+			desc := desc
+			eg.Go(func() error {
+				successors, err := opts.FindSuccessors(egCtx, fetcher, desc)
+				if err != nil {
+					return err
+				}
+				successors, err = applyForeignLayerPolicy(egCtx, successors, opts.ForeignLayerPolicy)
+				if err != nil {
+					return err
+				}
+				lock.Lock()
+				defer lock.Unlock()
+				for _, successor := range successors {
+					key := descriptor.FromOCI(successor)
+					if !visited.Contains(key) {
+						visited.Add(key)
+						next = append(next, successor)
+					}
+				}
+				return nil
+			})
+		}
+		if err := eg.Wait(); err != nil {
+			return nil, err
+		}
+		level = next
+	}
+	return results, nil
+}
+
+// preflightStorage is a storage serving the existence checks from the
+// results of the pre-flight phase.
+type preflightStorage struct {
+	content.Storage
+	results map[descriptor.Descriptor]bool
+}
+
+// Exists returns the existence of the described content found in the
+// pre-flight phase, or checks the underlying storage if not found.
+func (s *preflightStorage) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	if exists, ok := s.results[descriptor.FromOCI(target)]; ok {
+		return exists, nil
+	}
+	return s.Storage.Exists(ctx, target)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/orastest"
+)
+
+func TestCopyGraph_PreflightConcurrency(t *testing.T) {
+	ctx := context.Background()
+	src := memory.New()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatalf("failed to push %s: %v", mediaType, err)
+		}
+		return desc
+	}
+	layerA := push(ocispec.MediaTypeImageLayer, []byte("layer A"))
+	layerB := push(ocispec.MediaTypeImageLayer, []byte("layer B"))
+	layerC := push(ocispec.MediaTypeImageLayer, []byte("layer C"))
+	manifestA, err := oras.Pack(ctx, src, "", []ocispec.Descriptor{layerA}, oras.PackOptions{PackImageManifest: true})
+	if err != nil {
+		t.Fatalf("failed to pack manifest: %v", err)
+	}
+	manifestB, err := oras.Pack(ctx, src, "", []ocispec.Descriptor{layerB, layerC}, oras.PackOptions{PackImageManifest: true})
+	if err != nil {
+		t.Fatalf("failed to pack manifest: %v", err)
+	}
+	indexJSON, err := json.Marshal(ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{manifestA, manifestB},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal index: %v", err)
+	}
+	index := push(ocispec.MediaTypeImageIndex, indexJSON)
+
+	// manifest A exists in the destination
+	dst := orastest.NewTarget()
+	if err := oras.CopyGraph(ctx, src, dst, manifestA, oras.CopyGraphOptions{}); err != nil {
+		t.Fatalf("CopyGraph() error = %v", err)
+	}
+	dst.Reset()
+
+	opts := oras.CopyGraphOptions{
+		PreflightConcurrency: 10,
+	}
+	if err := oras.CopyGraph(ctx, src, dst, index, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v", err)
+	}
+
+	// the index, both manifests and the config and layers of manifest B are
+	// checked exactly once, all before the first push
+	checked := make(map[string]int)
+	pushed := false
+	for _, call := range dst.Calls() {
+		switch call.Method {
+		case orastest.MethodExists:
+			if pushed {
+				t.Errorf("Exists(%s) called after push", call.Descriptor.Digest)
+			}
+			checked[call.Descriptor.Digest.String()]++
+		case orastest.MethodPush:
+			pushed = true
+		}
+	}
+	if got, want := len(checked), 6; got != want {
+		t.Errorf("checked nodes = %d, want %d", got, want)
+	}
+	for dgst, n := range checked {
+		if n != 1 {
+			t.Errorf("Exists(%s) calls = %d, want 1", dgst, n)
+		}
+	}
+	if _, ok := checked[layerA.Digest.String()]; ok {
+		t.Errorf("layer A of the existing manifest is checked")
+	}
+	for _, desc := range []ocispec.Descriptor{index, manifestB, layerB, layerC} {
+		exists, err := dst.Exists(ctx, desc)
+		if err != nil {
+			t.Fatalf("Exists() error = %v", err)
+		}
+		if !exists {
+			t.Errorf("%s is not copied", desc.Digest)
+		}
+	}
+
+	// pre-flight failures abort the copy before any push
+	dst = orastest.NewTarget()
+	dst.AddRule(orastest.Rule{
+		Method: orastest.MethodExists,
+		Digest: layerC.Digest,
+		Err:    orastest.ErrInjected,
+	})
+	if err := oras.CopyGraph(ctx, src, dst, index, opts); !errors.Is(err, orastest.ErrInjected) {
+		t.Errorf("CopyGraph() error = %v, want %v", err, orastest.ErrInjected)
+	}
+	if calls := dst.CallsOf(orastest.MethodPush); len(calls) != 0 {
+		t.Errorf("Push() calls = %d, want 0", len(calls))
+	}
+}
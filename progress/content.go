@@ -0,0 +1,73 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package progress
+
+import (
+	"context"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// TrackFetcher returns a fetcher whose fetched contents are tracked by m.
+// The tracker of a content is marked done when the fetched reader is closed.
+func TrackFetcher(fetcher content.Fetcher, m *Manager) content.Fetcher {
+	return content.FetcherFunc(func(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+		tracker := m.Track(target)
+		rc, err := fetcher.Fetch(ctx, target)
+		if err != nil {
+			tracker.Done(err)
+			return nil, err
+		}
+		return NewReadCloser(rc, tracker), nil
+	})
+}
+
+// FetcherMiddleware returns a content.FetcherMiddleware tracking the fetched
+// contents by m. See TrackFetcher.
+func FetcherMiddleware(m *Manager) content.FetcherMiddleware {
+	return func(next content.Fetcher) content.Fetcher {
+		return TrackFetcher(next, m)
+	}
+}
+
+// PusherFunc is the basic Push method defined in content.Pusher.
+type PusherFunc func(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error
+
+// Push performs Push operation by the PusherFunc.
+func (fn PusherFunc) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	return fn(ctx, expected, content)
+}
+
+// TrackPusher returns a pusher whose pushed contents are tracked by m.
+// The tracker of a content is marked done when the push returns.
+func TrackPusher(pusher content.Pusher, m *Manager) content.Pusher {
+	return PusherFunc(func(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+		tracker := m.Track(expected)
+		err := pusher.Push(ctx, expected, NewReader(r, tracker))
+		tracker.Done(err)
+		return err
+	})
+}
+
+// PusherMiddleware returns a content.PusherMiddleware tracking the pushed
+// contents by m. See TrackPusher.
+func PusherMiddleware(m *Manager) content.PusherMiddleware {
+	return func(next content.Pusher) content.Pusher {
+		return TrackPusher(next, m)
+	}
+}
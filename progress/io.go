@@ -0,0 +1,94 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package progress
+
+import "io"
+
+// reader tracks the bytes read from the underlying reader.
+type reader struct {
+	r       io.Reader
+	tracker *Tracker
+}
+
+// NewReader wraps r so that the bytes read are recorded by t.
+func NewReader(r io.Reader, t *Tracker) io.Reader {
+	return &reader{
+		r:       r,
+		tracker: t,
+	}
+}
+
+// Read reads up to len(p) bytes into p and records the number of bytes read.
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.tracker.Add(int64(n))
+	return n, err
+}
+
+// readCloser tracks the bytes read from the underlying reader, and marks the
+// tracker done on close.
+type readCloser struct {
+	reader
+	closer io.Closer
+}
+
+// NewReadCloser wraps rc so that the bytes read are recorded by t.
+// On close, t is marked done. The transfer is considered failed if rc is
+// closed before reaching io.EOF.
+func NewReadCloser(rc io.ReadCloser, t *Tracker) io.ReadCloser {
+	return &readCloser{
+		reader: reader{
+			r:       rc,
+			tracker: t,
+		},
+		closer: rc,
+	}
+}
+
+// Close closes the underlying reader and marks the tracker done.
+func (rc *readCloser) Close() error {
+	err := rc.closer.Close()
+	status := rc.tracker.Status()
+	if !status.Done {
+		if status.Offset < status.Descriptor.Size {
+			rc.tracker.Done(io.ErrUnexpectedEOF)
+		} else {
+			rc.tracker.Done(err)
+		}
+	}
+	return err
+}
+
+// writer tracks the bytes written to the underlying writer.
+type writer struct {
+	w       io.Writer
+	tracker *Tracker
+}
+
+// NewWriter wraps w so that the bytes written are recorded by t.
+func NewWriter(w io.Writer, t *Tracker) io.Writer {
+	return &writer{
+		w:       w,
+		tracker: t,
+	}
+}
+
+// Write writes len(p) bytes from p and records the number of bytes written.
+func (w *writer) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.tracker.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,224 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package progress provides progress tracking of content transfers.
+package progress
+
+import (
+	"sync"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/internal/descriptor"
+)
+
+// Status is a snapshot of the transfer progress of a single content.
+type Status struct {
+	// Descriptor describes the content being transferred.
+	Descriptor ocispec.Descriptor
+	// Offset is the number of bytes transferred.
+	Offset int64
+	// StartTime is the time when the first update is received.
+	StartTime time.Time
+	// Done is true if the transfer is completed or failed.
+	Done bool
+	// Err is the error causing the transfer to fail, if any.
+	Err error
+}
+
+// Summary is a snapshot of the aggregated progress of all the tracked
+// contents.
+type Summary struct {
+	// Items contains the status of each tracked content, in the order they
+	// are tracked.
+	Items []Status
+	// Total is the total number of bytes of the tracked contents.
+	Total int64
+	// Transferred is the number of bytes transferred.
+	Transferred int64
+	// Elapsed is the duration since the first content is tracked.
+	Elapsed time.Duration
+	// Rate is the average transfer rate in bytes per second.
+	Rate float64
+	// ETA is the estimated time to complete the remaining transfers.
+	// ETA is zero if the rate is unknown.
+	ETA time.Duration
+}
+
+// Renderer renders the progress.
+type Renderer interface {
+	// Render renders a snapshot of the progress.
+	Render(summary Summary)
+}
+
+// RendererFunc is the basic Render method defined in Renderer.
+type RendererFunc func(summary Summary)
+
+// Render performs Render operation by the RendererFunc.
+func (fn RendererFunc) Render(summary Summary) {
+	fn(summary)
+}
+
+// Manager aggregates the progress of multiple contents, and renders it with
+// the configured renderer.
+// Manager is go-routine safe.
+type Manager struct {
+	// Renderer renders the progress on each update.
+	// If nil, the progress is not rendered but can still be queried by
+	// Summary().
+	Renderer Renderer
+	// MinInterval limits how often the Renderer is invoked on updates.
+	// Status changes such as starting or finishing a content are always
+	// rendered.
+	// If zero, the Renderer is invoked on every update.
+	MinInterval time.Duration
+
+	lock       sync.Mutex
+	trackers   map[descriptor.Descriptor]*Tracker
+	order      []*Tracker
+	startTime  time.Time
+	lastRender time.Time
+	now        func() time.Time
+}
+
+// NewManager creates a new progress manager with the given renderer.
+func NewManager(renderer Renderer) *Manager {
+	return &Manager{
+		Renderer: renderer,
+	}
+}
+
+// Track returns the tracker for the content described by desc.
+// The same tracker is returned if desc is tracked more than once.
+func (m *Manager) Track(desc ocispec.Descriptor) *Tracker {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key := descriptor.FromOCI(desc)
+	if t, ok := m.trackers[key]; ok {
+		return t
+	}
+	if m.trackers == nil {
+		m.trackers = make(map[descriptor.Descriptor]*Tracker)
+	}
+	if m.startTime.IsZero() {
+		m.startTime = m.clock()
+	}
+	t := &Tracker{
+		manager: m,
+		status: Status{
+			Descriptor: desc,
+		},
+	}
+	m.trackers[key] = t
+	m.order = append(m.order, t)
+	return t
+}
+
+// Summary returns a snapshot of the aggregated progress.
+func (m *Manager) Summary() Summary {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.summary()
+}
+
+// summary returns a snapshot of the aggregated progress.
+// The caller must hold m.lock.
+func (m *Manager) summary() Summary {
+	summary := Summary{
+		Items: make([]Status, 0, len(m.order)),
+	}
+	for _, t := range m.order {
+		summary.Items = append(summary.Items, t.status)
+		summary.Total += t.status.Descriptor.Size
+		summary.Transferred += t.status.Offset
+	}
+	if m.startTime.IsZero() {
+		return summary
+	}
+	summary.Elapsed = m.clock().Sub(m.startTime)
+	if seconds := summary.Elapsed.Seconds(); seconds > 0 {
+		summary.Rate = float64(summary.Transferred) / seconds
+	}
+	if summary.Rate > 0 {
+		remaining := summary.Total - summary.Transferred
+		if remaining > 0 {
+			summary.ETA = time.Duration(float64(remaining) / summary.Rate * float64(time.Second))
+		}
+	}
+	return summary
+}
+
+// update applies fn on the status of t and renders the progress.
+func (m *Manager) update(t *Tracker, force bool, fn func(status *Status)) {
+	m.lock.Lock()
+	now := m.clock()
+	if t.status.StartTime.IsZero() {
+		t.status.StartTime = now
+		force = true
+	}
+	fn(&t.status)
+	if m.Renderer == nil || (!force && now.Sub(m.lastRender) < m.MinInterval) {
+		m.lock.Unlock()
+		return
+	}
+	m.lastRender = now
+	summary := m.summary()
+	m.lock.Unlock()
+
+	m.Renderer.Render(summary)
+}
+
+// clock returns the current time.
+func (m *Manager) clock() time.Time {
+	if m.now == nil {
+		return time.Now()
+	}
+	return m.now()
+}
+
+// Tracker tracks the progress of a single content.
+type Tracker struct {
+	manager *Manager
+	status  Status
+}
+
+// Add records n more bytes transferred.
+func (t *Tracker) Add(n int64) {
+	if n <= 0 {
+		return
+	}
+	t.manager.update(t, false, func(status *Status) {
+		status.Offset += n
+	})
+}
+
+// Done marks the transfer as completed if err is nil, or failed otherwise.
+func (t *Tracker) Done(err error) {
+	t.manager.update(t, true, func(status *Status) {
+		status.Done = true
+		status.Err = err
+		if err == nil {
+			status.Offset = status.Descriptor.Size
+		}
+	})
+}
+
+// Status returns a snapshot of the tracked status.
+func (t *Tracker) Status() Status {
+	t.manager.lock.Lock()
+	defer t.manager.lock.Unlock()
+	return t.status
+}
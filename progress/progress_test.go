@@ -0,0 +1,263 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package progress
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// fakeClock is a manually advanced clock.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestManager_Summary(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	var rendered []Summary
+	m := NewManager(RendererFunc(func(s Summary) {
+		rendered = append(rendered, s)
+	}))
+	m.now = clock.Now
+
+	foo := content.NewDescriptorFromBytes("test", bytes.Repeat([]byte("a"), 100))
+	bar := content.NewDescriptorFromBytes("test", bytes.Repeat([]byte("b"), 300))
+	fooTracker := m.Track(foo)
+	barTracker := m.Track(bar)
+	if got := m.Track(foo); got != fooTracker {
+		t.Error("Track() returns a different tracker for the same descriptor")
+	}
+
+	clock.Advance(time.Second)
+	fooTracker.Add(50)
+	barTracker.Add(50)
+	clock.Advance(time.Second)
+	fooTracker.Done(nil)
+
+	summary := m.Summary()
+	if summary.Total != 400 {
+		t.Errorf("Summary().Total = %d, want %d", summary.Total, 400)
+	}
+	if summary.Transferred != 150 {
+		t.Errorf("Summary().Transferred = %d, want %d", summary.Transferred, 150)
+	}
+	if summary.Elapsed != 2*time.Second {
+		t.Errorf("Summary().Elapsed = %v, want %v", summary.Elapsed, 2*time.Second)
+	}
+	if summary.Rate != 75 {
+		t.Errorf("Summary().Rate = %v, want %v", summary.Rate, 75)
+	}
+	if want := 250 * time.Second / 75; summary.ETA != want {
+		t.Errorf("Summary().ETA = %v, want %v", summary.ETA, want)
+	}
+	if len(summary.Items) != 2 || !summary.Items[0].Done || summary.Items[1].Done {
+		t.Errorf("Summary().Items = %v, want foo done and bar in progress", summary.Items)
+	}
+	if len(rendered) != 3 {
+		t.Errorf("rendered %d times, want %d", len(rendered), 3)
+	}
+
+	errFail := errors.New("fail")
+	barTracker.Done(errFail)
+	if status := barTracker.Status(); !status.Done || status.Err != errFail || status.Offset != 50 {
+		t.Errorf("Tracker.Status() = %v, want failed at offset 50", status)
+	}
+}
+
+func TestManager_MinInterval(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	var count int
+	m := NewManager(RendererFunc(func(Summary) {
+		count++
+	}))
+	m.MinInterval = time.Second
+	m.now = clock.Now
+
+	tracker := m.Track(content.NewDescriptorFromBytes("test", []byte("hello world")))
+	tracker.Add(1) // rendered as the transfer starts
+	tracker.Add(1) // throttled
+	clock.Advance(time.Second)
+	tracker.Add(1) // rendered
+	tracker.Add(1) // throttled
+	tracker.Done(nil)
+	if want := 3; count != want {
+		t.Errorf("rendered %d times, want %d", count, want)
+	}
+}
+
+func TestReadCloser(t *testing.T) {
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+
+	m := NewManager(nil)
+	tracker := m.Track(desc)
+	rc := NewReadCloser(io.NopCloser(bytes.NewReader(blob)), tracker)
+	got, err := content.ReadAll(rc, desc)
+	if err != nil {
+		t.Fatal("ReadAll() error =", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("ReadAll() = %v, want %v", got, blob)
+	}
+	if err := rc.Close(); err != nil {
+		t.Fatal("Close() error =", err)
+	}
+	if status := tracker.Status(); !status.Done || status.Err != nil || status.Offset != desc.Size {
+		t.Errorf("Tracker.Status() = %v, want done", status)
+	}
+
+	// close early
+	tracker = m.Track(ocispec.Descriptor{MediaType: "test", Digest: "sha256:0", Size: 100})
+	rc = NewReadCloser(io.NopCloser(bytes.NewReader(blob)), tracker)
+	if _, err := rc.Read(make([]byte, 5)); err != nil {
+		t.Fatal("Read() error =", err)
+	}
+	rc.Close()
+	if status := tracker.Status(); !status.Done || status.Err != io.ErrUnexpectedEOF || status.Offset != 5 {
+		t.Errorf("Tracker.Status() = %v, want failed at offset 5", status)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	blob := []byte("hello world")
+	m := NewManager(nil)
+	tracker := m.Track(content.NewDescriptorFromBytes("test", blob))
+	var buf bytes.Buffer
+	if _, err := io.Copy(NewWriter(&buf, tracker), NewReader(bytes.NewReader(blob), m.Track(ocispec.Descriptor{}))); err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+	if got := tracker.Status().Offset; got != int64(len(blob)) {
+		t.Errorf("Tracker.Status().Offset = %d, want %d", got, len(blob))
+	}
+}
+
+func TestTextRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewTextRenderer(&buf)
+	r.Render(Summary{
+		Items:       []Status{{Done: true}, {}},
+		Total:       4 << 20,
+		Transferred: 3 << 19,
+		Rate:        512 << 10,
+		ETA:         5 * time.Second,
+	})
+	want := "1/2 done, 1.50 MiB / 4.00 MiB (37.5%), 512.00 KiB/s, ETA 5s\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.00 KiB"},
+		{3 << 29, "1.50 GiB"},
+	}
+	for _, tt := range tests {
+		if got := FormatBytes(tt.n); !strings.EqualFold(got, tt.want) {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestTrackFetcherAndPusher(t *testing.T) {
+	ctx := context.Background()
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	src := memory.New()
+	if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+
+	fetchManager := NewManager(nil)
+	rc, err := TrackFetcher(src, fetchManager).Fetch(ctx, desc)
+	if err != nil {
+		t.Fatal("Fetch() error =", err)
+	}
+	pushManager := NewManager(nil)
+	dst := memory.New()
+	if err := TrackPusher(dst, pushManager).Push(ctx, desc, rc); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	if err := rc.Close(); err != nil {
+		t.Fatal("Close() error =", err)
+	}
+	for _, m := range []*Manager{fetchManager, pushManager} {
+		summary := m.Summary()
+		if len(summary.Items) != 1 || !summary.Items[0].Done || summary.Items[0].Err != nil {
+			t.Errorf("Summary().Items = %v, want 1 done item", summary.Items)
+		}
+		if summary.Transferred != desc.Size {
+			t.Errorf("Summary().Transferred = %d, want %d", summary.Transferred, desc.Size)
+		}
+	}
+
+	// fetch failure
+	missing := content.NewDescriptorFromBytes("test", []byte("missing"))
+	if _, err := TrackFetcher(src, fetchManager).Fetch(ctx, missing); !errors.Is(err, errdef.ErrNotFound) {
+		t.Fatalf("Fetch() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+	if status := fetchManager.Track(missing).Status(); !status.Done || status.Err == nil {
+		t.Errorf("Tracker.Status() = %v, want failed", status)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	ctx := context.Background()
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	src := memory.New()
+	if err := src.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+
+	fetchManager := NewManager(nil)
+	if _, err := content.FetchAll(ctx, FetcherMiddleware(fetchManager)(src), desc); err != nil {
+		t.Fatal("FetchAll() error =", err)
+	}
+	pushManager := NewManager(nil)
+	dst := content.WithPusherMiddleware(memory.New(), PusherMiddleware(pushManager))
+	if err := dst.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	for _, m := range []*Manager{fetchManager, pushManager} {
+		if summary := m.Summary(); summary.Transferred != desc.Size {
+			t.Errorf("Summary().Transferred = %d, want %d", summary.Transferred, desc.Size)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package progress
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// textRenderer renders the progress as lines of text.
+type textRenderer struct {
+	w io.Writer
+}
+
+// NewTextRenderer returns a renderer writing a line of text to w for each
+// rendered snapshot. The line looks like
+//
+//	2/3 done, 1.50 MiB / 4.00 MiB (37.5%), 512.00 KiB/s, ETA 5s
+func NewTextRenderer(w io.Writer) Renderer {
+	return &textRenderer{w: w}
+}
+
+// Render writes a line of text describing the summary.
+func (r *textRenderer) Render(summary Summary) {
+	fmt.Fprintln(r.w, FormatSummary(summary))
+}
+
+// FormatSummary formats the summary into a single line of text.
+func FormatSummary(summary Summary) string {
+	var done int
+	for _, item := range summary.Items {
+		if item.Done {
+			done++
+		}
+	}
+	var percent float64
+	if summary.Total > 0 {
+		percent = float64(summary.Transferred) / float64(summary.Total) * 100
+	}
+	line := fmt.Sprintf("%d/%d done, %s / %s (%.1f%%), %s/s",
+		done, len(summary.Items),
+		FormatBytes(summary.Transferred), FormatBytes(summary.Total),
+		percent, FormatBytes(int64(summary.Rate)))
+	if summary.ETA > 0 {
+		line += ", ETA " + summary.ETA.Round(time.Second).String()
+	}
+	return line
+}
+
+// FormatBytes formats the size in bytes into a human readable string using
+// binary units.
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value := float64(n)
+	var i int
+	for value >= unit && i < len(byteUnits) {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.2f %s", value, byteUnits[i-1])
+}
+
+// byteUnits lists the binary units used by FormatBytes.
+var byteUnits = []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
@@ -0,0 +1,333 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/registry"
+)
+
+// DefaultPruneReferrersOptions provides the default PruneReferrersOptions.
+var DefaultPruneReferrersOptions PruneReferrersOptions
+
+// PruneReferrersOptions contains parameters for [oras.PruneReferrers].
+type PruneReferrersOptions struct {
+	// DryRun, if true, only reports the dangling referrers and the stale
+	// referrers tag indexes without modifying the target.
+	DryRun bool
+	// PreDelete handles the current descriptor before deleting it.
+	// If PreDelete returns an error, the pruning is aborted.
+	PreDelete func(ctx context.Context, desc ocispec.Descriptor) error
+	// PostDelete handles the current descriptor after deleting it.
+	PostDelete func(ctx context.Context, desc ocispec.Descriptor) error
+}
+
+// PruneReferrers deletes the dangling referrers in the target, and returns
+// the deleted descriptors.
+//
+// A referrer is dangling if its subject does not exist in the target, or if
+// its subject is a dangling referrer itself. Referrers are discovered by
+// walking the graph, through both successors and predecessors, from all the
+// tags listed by the target, and therefore referrers not connected to any
+// tagged node are not discovered. Dangling referrers of referrers are deleted
+// before the referrers they refer to. The successors of the deleted
+// referrers, such as their configs and layers, are not deleted and can be
+// cleaned up by [oras.GarbageCollect].
+//
+// Afterwards, the indexes tagged with the referrers tag schema
+// <alg>-<ref> are updated to drop the entries no longer existing in the
+// target. A referrers tag index without any remaining entry is deleted, and a
+// rewritten referrers tag index replaces the old one, which is deleted.
+// Reference: https://github.com/opencontainers/distribution-spec/blob/v1.1.0-rc1/spec.md#unavailable-referrers-api
+//
+// If opts.DryRun is true, the descriptors which would be deleted are returned
+// without modifying the target.
+// Returns ErrUnsupported if the target does not support tag listing or
+// deletion.
+func PruneReferrers(ctx context.Context, target GraphTarget, opts PruneReferrersOptions) ([]ocispec.Descriptor, error) {
+	if target == nil {
+		return nil, errors.New("nil target")
+	}
+	deleter, ok := target.(content.Deleter)
+	if !ok {
+		return nil, fmt.Errorf("referrers pruning: delete: %w", errdef.ErrUnsupported)
+	}
+
+	// discover the content in the target from the tagged nodes
+	var tagged []ocispec.Descriptor
+	referrersTags := make(map[string]ocispec.Descriptor)
+	if err := registry.ListTags(ctx, target, "", func(tags []string) error {
+		for _, tag := range tags {
+			desc, err := target.Resolve(ctx, tag)
+			if err != nil {
+				if errors.Is(err, errdef.ErrNotFound) {
+					continue
+				}
+				return fmt.Errorf("failed to resolve %s: %w", tag, err)
+			}
+			tagged = append(tagged, desc)
+			if isReferrersTag(tag) && desc.MediaType == ocispec.MediaTypeImageIndex {
+				referrersTags[tag] = desc
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	referrers, err := discoverReferrers(ctx, target, tagged)
+	if err != nil {
+		return nil, err
+	}
+
+	// find the dangling referrers
+	var dangling []ocispec.Descriptor
+	depths := make(map[descriptor.Descriptor]int)
+	for key, referrer := range referrers {
+		depth, err := danglingDepth(ctx, target, referrers, depths, key)
+		if err != nil {
+			return nil, err
+		}
+		if depth >= 0 {
+			dangling = append(dangling, referrer.desc)
+		}
+	}
+	// delete the referrers of referrers first
+	sort.Slice(dangling, func(i, j int) bool {
+		di := depths[descriptor.FromOCI(dangling[i])]
+		dj := depths[descriptor.FromOCI(dangling[j])]
+		if di != dj {
+			return di > dj
+		}
+		return dangling[i].Digest < dangling[j].Digest
+	})
+
+	var deleted []ocispec.Descriptor
+	removed := make(map[descriptor.Descriptor]bool)
+	deleteNode := func(desc ocispec.Descriptor) error {
+		removed[descriptor.FromOCI(desc)] = true
+		if opts.DryRun {
+			deleted = append(deleted, desc)
+			return nil
+		}
+		if opts.PreDelete != nil {
+			if err := opts.PreDelete(ctx, desc); err != nil {
+				return err
+			}
+		}
+		if err := deleter.Delete(ctx, desc); err != nil {
+			if errors.Is(err, errdef.ErrNotFound) {
+				// the content is already deleted
+				return nil
+			}
+			return err
+		}
+		deleted = append(deleted, desc)
+		if opts.PostDelete != nil {
+			return opts.PostDelete(ctx, desc)
+		}
+		return nil
+	}
+	for _, desc := range dangling {
+		if err := deleteNode(desc); err != nil {
+			return deleted, err
+		}
+	}
+
+	// update the referrers tag indexes
+	tags := make([]string, 0, len(referrersTags))
+	for tag := range referrersTags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		indexDesc := referrersTags[tag]
+		if !opts.DryRun {
+			// the index may have been updated on deleting the referrers
+			indexDesc, err = target.Resolve(ctx, tag)
+			if err != nil {
+				if errors.Is(err, errdef.ErrNotFound) {
+					continue
+				}
+				return deleted, fmt.Errorf("failed to resolve %s: %w", tag, err)
+			}
+		}
+		if err := pruneReferrersIndex(ctx, target, tag, indexDesc, removed, deleteNode, opts); err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+// referrerNode is a manifest with a subject.
+type referrerNode struct {
+	desc    ocispec.Descriptor
+	subject ocispec.Descriptor
+}
+
+// discoverReferrers walks the graph from the given nodes through both
+// successors and predecessors, and returns the visited manifests having a
+// subject.
+// The predecessors of the nodes not found in the target are also visited so
+// that the referrers of deleted subjects are discovered.
+func discoverReferrers(ctx context.Context, target GraphTarget, nodes []ocispec.Descriptor) (map[descriptor.Descriptor]referrerNode, error) {
+	referrers := make(map[descriptor.Descriptor]referrerNode)
+	visited := make(map[descriptor.Descriptor]bool)
+	queue := nodes
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		key := descriptor.FromOCI(node)
+		if visited[key] {
+			continue
+		}
+		visited[key] = true
+
+		predecessors, err := target.Predecessors(ctx, node)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, predecessors...)
+		if !descriptor.IsManifest(node) {
+			continue
+		}
+
+		manifestJSON, err := content.FetchAll(ctx, target, node)
+		if err != nil {
+			if errors.Is(err, errdef.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		var manifest struct {
+			Subject *ocispec.Descriptor `json:"subject,omitempty"`
+		}
+		if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", node.Digest, err)
+		}
+		if manifest.Subject != nil {
+			referrers[key] = referrerNode{desc: node, subject: *manifest.Subject}
+		}
+		successors, err := content.Successors(ctx, target, node)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, successors...)
+	}
+	return referrers, nil
+}
+
+// danglingDepth returns the length of the subject chain from the referrer
+// identified by key to the first missing subject, or -1 if the referrer is
+// not dangling.
+func danglingDepth(ctx context.Context, target content.ReadOnlyStorage, referrers map[descriptor.Descriptor]referrerNode, depths map[descriptor.Descriptor]int, key descriptor.Descriptor) (int, error) {
+	if depth, ok := depths[key]; ok {
+		return depth, nil
+	}
+	// guard against cycles
+	depths[key] = -1
+
+	subject := referrers[key].subject
+	exists, err := target.Exists(ctx, subject)
+	if err != nil {
+		return -1, err
+	}
+	depth := -1
+	if !exists {
+		depth = 0
+	} else {
+		subjectKey := descriptor.FromOCI(subject)
+		if _, ok := referrers[subjectKey]; ok {
+			subjectDepth, err := danglingDepth(ctx, target, referrers, depths, subjectKey)
+			if err != nil {
+				return -1, err
+			}
+			if subjectDepth >= 0 {
+				depth = subjectDepth + 1
+			}
+		}
+	}
+	depths[key] = depth
+	return depth, nil
+}
+
+// pruneReferrersIndex drops the entries not existing in the target or
+// removed from the referrers tag index, and replaces the index if changed.
+func pruneReferrersIndex(ctx context.Context, target Target, tag string, indexDesc ocispec.Descriptor, removed map[descriptor.Descriptor]bool, deleteNode func(ocispec.Descriptor) error, opts PruneReferrersOptions) error {
+	indexJSON, err := content.FetchAll(ctx, target, indexDesc)
+	if err != nil {
+		return err
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(indexJSON, &index); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", indexDesc.Digest, err)
+	}
+	manifests := make([]ocispec.Descriptor, 0, len(index.Manifests))
+	for _, desc := range index.Manifests {
+		if removed[descriptor.FromOCI(desc)] {
+			continue
+		}
+		exists, err := target.Exists(ctx, desc)
+		if err != nil {
+			return err
+		}
+		if exists {
+			manifests = append(manifests, desc)
+		}
+	}
+	if len(manifests) == len(index.Manifests) {
+		return nil
+	}
+	if len(manifests) == 0 || opts.DryRun {
+		return deleteNode(indexDesc)
+	}
+
+	// replace the stale index
+	index.Manifests = manifests
+	newIndexJSON, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to encode referrers index: %w", err)
+	}
+	newIndexDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageIndex, newIndexJSON)
+	if err := target.Push(ctx, newIndexDesc, bytes.NewReader(newIndexJSON)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return err
+	}
+	if err := target.Tag(ctx, newIndexDesc, tag); err != nil {
+		return err
+	}
+	return deleteNode(indexDesc)
+}
+
+// isReferrersTag returns true if the tag follows the referrers tag schema
+// <alg>-<ref>.
+func isReferrersTag(tag string) bool {
+	alg, encoded, ok := strings.Cut(tag, "-")
+	if !ok {
+		return false
+	}
+	return content.ValidateDigest(digest.Digest(alg+":"+encoded)) == nil
+}
@@ -0,0 +1,178 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestPruneReferrers(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+			t.Fatal("Store.Push() error =", err)
+		}
+		return desc
+	}
+	tag := func(desc ocispec.Descriptor, ref string) {
+		if err := s.Tag(ctx, desc, ref); err != nil {
+			t.Fatal("Store.Tag() error =", err)
+		}
+	}
+	config := push(ocispec.MediaTypeImageConfig, []byte("{}"))
+	pushManifest := func(layer string, subject *ocispec.Descriptor) ocispec.Descriptor {
+		manifestJSON, err := json.Marshal(ocispec.Manifest{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			MediaType: ocispec.MediaTypeImageManifest,
+			Config:    config,
+			Layers:    []ocispec.Descriptor{push(ocispec.MediaTypeImageLayer, []byte(layer))},
+			Subject:   subject,
+		})
+		if err != nil {
+			t.Fatal("json.Marshal() error =", err)
+		}
+		return push(ocispec.MediaTypeImageManifest, manifestJSON)
+	}
+	pushIndex := func(manifests ...ocispec.Descriptor) ocispec.Descriptor {
+		indexJSON, err := json.Marshal(ocispec.Index{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			MediaType: ocispec.MediaTypeImageIndex,
+			Manifests: manifests,
+		})
+		if err != nil {
+			t.Fatal("json.Marshal() error =", err)
+		}
+		return push(ocispec.MediaTypeImageIndex, indexJSON)
+	}
+	referrersTag := func(desc ocispec.Descriptor) string {
+		return desc.Digest.Algorithm().String() + "-" + desc.Digest.Encoded()
+	}
+
+	imageA := pushManifest("a", nil)
+	tag(imageA, "a")
+	imageB := pushManifest("b", nil)
+	tag(imageB, "b")
+	sbomA := pushManifest("sbom a", &imageA)
+	signatureA := pushManifest("signature a", &sbomA)
+	sbomB := pushManifest("sbom b", &imageB)
+	missing := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, []byte("missing"))
+	indexA := pushIndex(sbomA)
+	tag(indexA, referrersTag(imageA))
+	indexB := pushIndex(sbomB, missing)
+	tag(indexB, referrersTag(imageB))
+
+	if err := s.Delete(ctx, imageA); err != nil {
+		t.Fatal("Store.Delete() error =", err)
+	}
+
+	// test dry run
+	want := []ocispec.Descriptor{signatureA, sbomA, indexA, indexB}
+	got, err := PruneReferrers(ctx, s, PruneReferrersOptions{DryRun: true})
+	if err != nil {
+		t.Fatal("PruneReferrers() error =", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PruneReferrers() = %v, want %v", got, want)
+	}
+	for _, desc := range want {
+		if exists, err := s.Exists(ctx, desc); err != nil || !exists {
+			t.Errorf("Store.Exists(%s) = %v, %v, want true", desc.Digest, exists, err)
+		}
+	}
+
+	// test pruning
+	var preDeleted, postDeleted []ocispec.Descriptor
+	got, err = PruneReferrers(ctx, s, PruneReferrersOptions{
+		PreDelete: func(ctx context.Context, desc ocispec.Descriptor) error {
+			preDeleted = append(preDeleted, desc)
+			return nil
+		},
+		PostDelete: func(ctx context.Context, desc ocispec.Descriptor) error {
+			postDeleted = append(postDeleted, desc)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal("PruneReferrers() error =", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PruneReferrers() = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(preDeleted, want) {
+		t.Errorf("PreDelete() = %v, want %v", preDeleted, want)
+	}
+	if !reflect.DeepEqual(postDeleted, want) {
+		t.Errorf("PostDelete() = %v, want %v", postDeleted, want)
+	}
+	for _, desc := range want {
+		if exists, err := s.Exists(ctx, desc); err != nil || exists {
+			t.Errorf("Store.Exists(%s) = %v, %v, want false", desc.Digest, exists, err)
+		}
+	}
+	for _, desc := range []ocispec.Descriptor{imageB, sbomB} {
+		if exists, err := s.Exists(ctx, desc); err != nil || !exists {
+			t.Errorf("Store.Exists(%s) = %v, %v, want true", desc.Digest, exists, err)
+		}
+	}
+	if _, err := s.Resolve(ctx, referrersTag(imageA)); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Store.Resolve() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+	newIndexB, err := s.Resolve(ctx, referrersTag(imageB))
+	if err != nil {
+		t.Fatal("Store.Resolve() error =", err)
+	}
+	indexJSON, err := content.FetchAll(ctx, s, newIndexB)
+	if err != nil {
+		t.Fatal("Store.Fetch() error =", err)
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(indexJSON, &index); err != nil {
+		t.Fatal("json.Unmarshal() error =", err)
+	}
+	if want := []ocispec.Descriptor{sbomB}; !reflect.DeepEqual(index.Manifests, want) {
+		t.Errorf("Index.Manifests = %v, want %v", index.Manifests, want)
+	}
+
+	// test pruning a consistent target
+	got, err = PruneReferrers(ctx, s, DefaultPruneReferrersOptions)
+	if err != nil {
+		t.Fatal("PruneReferrers() error =", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("PruneReferrers() = %v, want none", got)
+	}
+}
+
+func TestPruneReferrers_Unsupported(t *testing.T) {
+	ctx := context.Background()
+	target := struct{ GraphTarget }{memory.New()}
+	if _, err := PruneReferrers(ctx, target, DefaultPruneReferrersOptions); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("PruneReferrers() error = %v, wantErr %v", err, errdef.ErrUnsupported)
+	}
+}
@@ -0,0 +1,356 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/internal/syncutil"
+	"oras.land/oras-go/v2/registry"
+)
+
+// RateLimiterOptions contains parameters for [oras.NewRateLimiter].
+type RateLimiterOptions struct {
+	// RequestsPerSecond limits the rate of the requests, i.e. the calls to
+	// Fetch, Push, Exists, Resolve and Tag, across all the wrappers of the
+	// limiter. Bursts of up to one second of the rate are allowed.
+	// If less than or equal to 0, the rate is not limited.
+	RequestsPerSecond int64
+	// FetchBytesPerSecond limits the total rate of the content read from the
+	// fetched streams across all the wrappers of the limiter.
+	// If less than or equal to 0, the rate is not limited.
+	FetchBytesPerSecond int64
+	// PushBytesPerSecond limits the total rate of the content pushed across
+	// all the wrappers of the limiter.
+	// If less than or equal to 0, the rate is not limited.
+	PushBytesPerSecond int64
+}
+
+// RateLimiter limits the request rate and the byte rate of the operations on
+// the targets and the storages it wraps. The budget is shared by all the
+// wrappers of a RateLimiter, so that multiple components in a process wrapping
+// the same upstream with one RateLimiter collectively respect it.
+//
+// Unlike TransferManager, which bounds the transfers of copy operations,
+// RateLimiter limits every operation on the wrapped targets.
+//
+// A RateLimiter is safe for concurrent use.
+type RateLimiter struct {
+	requests   *syncutil.RateLimiter
+	fetchBytes *syncutil.RateLimiter
+	pushBytes  *syncutil.RateLimiter
+}
+
+// NewRateLimiter creates a RateLimiter.
+func NewRateLimiter(opts RateLimiterOptions) *RateLimiter {
+	return &RateLimiter{
+		requests:   syncutil.NewRateLimiter(opts.RequestsPerSecond),
+		fetchBytes: syncutil.NewRateLimiter(opts.FetchBytesPerSecond),
+		pushBytes:  syncutil.NewRateLimiter(opts.PushBytesPerSecond),
+	}
+}
+
+// Target wraps t so that the operations on t are subject to the limits.
+// If t is a registry.Repository, such as a remote repository, the returned
+// Target is also a registry.Repository subject to the limits, including its
+// blob and manifest stores. Other optional interfaces implemented by t are
+// not preserved by the returned Target.
+func (l *RateLimiter) Target(t Target) Target {
+	target := rateLimitedTarget{
+		rateLimitedStorage: rateLimitedStorage{Storage: t, limiter: l},
+		resolver:           t,
+	}
+	if repo, ok := t.(registry.Repository); ok {
+		return &rateLimitedRepository{
+			rateLimitedTarget: target,
+			repo:              repo,
+		}
+	}
+	return &target
+}
+
+// ReadOnlyTarget wraps t so that the operations on t are subject to the
+// limits.
+// Optional interfaces implemented by t are not preserved by the returned
+// ReadOnlyTarget.
+func (l *RateLimiter) ReadOnlyTarget(t ReadOnlyTarget) ReadOnlyTarget {
+	return &rateLimitedReadOnlyTarget{
+		ReadOnlyTarget: t,
+		limiter:        l,
+	}
+}
+
+// Storage wraps s so that the operations on s are subject to the limits.
+// Optional interfaces implemented by s are not preserved by the returned
+// Storage.
+func (l *RateLimiter) Storage(s content.Storage) content.Storage {
+	return &rateLimitedStorage{
+		Storage: s,
+		limiter: l,
+	}
+}
+
+// waitRequest waits until a request is within the request rate.
+func (l *RateLimiter) waitRequest(ctx context.Context) error {
+	return l.requests.Wait(ctx, 1)
+}
+
+// fetch fetches the content through fetcher, subject to the limits.
+func (l *RateLimiter) fetch(ctx context.Context, fetcher content.Fetcher, target ocispec.Descriptor) (io.ReadCloser, error) {
+	if err := l.waitRequest(ctx); err != nil {
+		return nil, err
+	}
+	rc, err := fetcher.Fetch(ctx, target)
+	if err != nil || l.fetchBytes == nil {
+		return rc, err
+	}
+	return &managedReadCloser{
+		ctx:     ctx,
+		rc:      rc,
+		limiter: l.fetchBytes,
+	}, nil
+}
+
+// push pushes the content through pusher, subject to the limits.
+func (l *RateLimiter) push(ctx context.Context, pusher content.Pusher, expected ocispec.Descriptor, r io.Reader) error {
+	if err := l.waitRequest(ctx); err != nil {
+		return err
+	}
+	if l.pushBytes != nil {
+		r = &managedReadCloser{
+			ctx:     ctx,
+			rc:      io.NopCloser(r),
+			limiter: l.pushBytes,
+		}
+	}
+	return pusher.Push(ctx, expected, r)
+}
+
+// fetchReference fetches the content identified by the reference through
+// fetcher, subject to the limits.
+func (l *RateLimiter) fetchReference(ctx context.Context, fetcher registry.ReferenceFetcher, reference string) (ocispec.Descriptor, io.ReadCloser, error) {
+	if err := l.waitRequest(ctx); err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	desc, rc, err := fetcher.FetchReference(ctx, reference)
+	if err != nil || l.fetchBytes == nil {
+		return desc, rc, err
+	}
+	return desc, &managedReadCloser{
+		ctx:     ctx,
+		rc:      rc,
+		limiter: l.fetchBytes,
+	}, nil
+}
+
+// pushReference pushes the manifest with a reference tag through pusher,
+// subject to the limits.
+func (l *RateLimiter) pushReference(ctx context.Context, pusher registry.ReferencePusher, expected ocispec.Descriptor, r io.Reader, reference string) error {
+	if err := l.waitRequest(ctx); err != nil {
+		return err
+	}
+	if l.pushBytes != nil {
+		r = &managedReadCloser{
+			ctx:     ctx,
+			rc:      io.NopCloser(r),
+			limiter: l.pushBytes,
+		}
+	}
+	return pusher.PushReference(ctx, expected, r, reference)
+}
+
+// rateLimitedStorage is a content.Storage subject to a RateLimiter.
+type rateLimitedStorage struct {
+	content.Storage
+	limiter *RateLimiter
+}
+
+// Fetch fetches the content identified by the descriptor.
+func (s *rateLimitedStorage) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	return s.limiter.fetch(ctx, s.Storage, target)
+}
+
+// Push pushes the content, matching the expected descriptor.
+func (s *rateLimitedStorage) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	return s.limiter.push(ctx, s.Storage, expected, content)
+}
+
+// Exists returns true if the described content exists.
+func (s *rateLimitedStorage) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	if err := s.limiter.waitRequest(ctx); err != nil {
+		return false, err
+	}
+	return s.Storage.Exists(ctx, target)
+}
+
+// rateLimitedTarget is a Target subject to a RateLimiter.
+type rateLimitedTarget struct {
+	rateLimitedStorage
+	resolver content.TagResolver
+}
+
+// Resolve resolves a reference to a descriptor.
+func (t *rateLimitedTarget) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+	if err := t.limiter.waitRequest(ctx); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return t.resolver.Resolve(ctx, reference)
+}
+
+// Tag tags a descriptor with a reference string.
+func (t *rateLimitedTarget) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	if err := t.limiter.waitRequest(ctx); err != nil {
+		return err
+	}
+	return t.resolver.Tag(ctx, desc, reference)
+}
+
+// rateLimitedReadOnlyTarget is a ReadOnlyTarget subject to a RateLimiter.
+type rateLimitedReadOnlyTarget struct {
+	ReadOnlyTarget
+	limiter *RateLimiter
+}
+
+// Fetch fetches the content identified by the descriptor.
+func (t *rateLimitedReadOnlyTarget) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	return t.limiter.fetch(ctx, t.ReadOnlyTarget, target)
+}
+
+// Exists returns true if the described content exists.
+func (t *rateLimitedReadOnlyTarget) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	if err := t.limiter.waitRequest(ctx); err != nil {
+		return false, err
+	}
+	return t.ReadOnlyTarget.Exists(ctx, target)
+}
+
+// Resolve resolves a reference to a descriptor.
+func (t *rateLimitedReadOnlyTarget) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+	if err := t.limiter.waitRequest(ctx); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return t.ReadOnlyTarget.Resolve(ctx, reference)
+}
+
+// rateLimitedRepository is a registry.Repository subject to a RateLimiter.
+type rateLimitedRepository struct {
+	rateLimitedTarget
+	repo registry.Repository
+}
+
+// Delete removes the content identified by the descriptor.
+func (r *rateLimitedRepository) Delete(ctx context.Context, target ocispec.Descriptor) error {
+	if err := r.limiter.waitRequest(ctx); err != nil {
+		return err
+	}
+	return r.repo.Delete(ctx, target)
+}
+
+// FetchReference fetches the content identified by the reference.
+func (r *rateLimitedRepository) FetchReference(ctx context.Context, reference string) (ocispec.Descriptor, io.ReadCloser, error) {
+	return r.limiter.fetchReference(ctx, r.repo, reference)
+}
+
+// PushReference pushes the manifest with a reference tag.
+func (r *rateLimitedRepository) PushReference(ctx context.Context, expected ocispec.Descriptor, content io.Reader, reference string) error {
+	return r.limiter.pushReference(ctx, r.repo, expected, content, reference)
+}
+
+// Referrers lists the descriptors of the manifests directly referencing desc.
+func (r *rateLimitedRepository) Referrers(ctx context.Context, desc ocispec.Descriptor, artifactType string, fn func(referrers []ocispec.Descriptor) error) error {
+	if err := r.limiter.waitRequest(ctx); err != nil {
+		return err
+	}
+	return r.repo.Referrers(ctx, desc, artifactType, fn)
+}
+
+// Tags lists the tags available in the repository.
+func (r *rateLimitedRepository) Tags(ctx context.Context, last string, fn func(tags []string) error) error {
+	if err := r.limiter.waitRequest(ctx); err != nil {
+		return err
+	}
+	return r.repo.Tags(ctx, last, fn)
+}
+
+// Blobs provides access to the blob CAS only, subject to the limits.
+func (r *rateLimitedRepository) Blobs() registry.BlobStore {
+	return &rateLimitedBlobStore{
+		rateLimitedStorage: rateLimitedStorage{Storage: r.repo.Blobs(), limiter: r.limiter},
+		store:              r.repo.Blobs(),
+	}
+}
+
+// Manifests provides access to the manifest CAS only, subject to the limits.
+func (r *rateLimitedRepository) Manifests() registry.ManifestStore {
+	manifests := r.repo.Manifests()
+	return &rateLimitedManifestStore{
+		rateLimitedBlobStore: rateLimitedBlobStore{
+			rateLimitedStorage: rateLimitedStorage{Storage: manifests, limiter: r.limiter},
+			store:              manifests,
+		},
+		manifests: manifests,
+	}
+}
+
+// rateLimitedBlobStore is a registry.BlobStore subject to a RateLimiter.
+type rateLimitedBlobStore struct {
+	rateLimitedStorage
+	store registry.BlobStore
+}
+
+// Delete removes the content identified by the descriptor.
+func (s *rateLimitedBlobStore) Delete(ctx context.Context, target ocispec.Descriptor) error {
+	if err := s.limiter.waitRequest(ctx); err != nil {
+		return err
+	}
+	return s.store.Delete(ctx, target)
+}
+
+// Resolve resolves a reference to a descriptor.
+func (s *rateLimitedBlobStore) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+	if err := s.limiter.waitRequest(ctx); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return s.store.Resolve(ctx, reference)
+}
+
+// FetchReference fetches the content identified by the reference.
+func (s *rateLimitedBlobStore) FetchReference(ctx context.Context, reference string) (ocispec.Descriptor, io.ReadCloser, error) {
+	return s.limiter.fetchReference(ctx, s.store, reference)
+}
+
+// rateLimitedManifestStore is a registry.ManifestStore subject to a
+// RateLimiter.
+type rateLimitedManifestStore struct {
+	rateLimitedBlobStore
+	manifests registry.ManifestStore
+}
+
+// Tag tags a descriptor with a reference string.
+func (s *rateLimitedManifestStore) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	if err := s.limiter.waitRequest(ctx); err != nil {
+		return err
+	}
+	return s.manifests.Tag(ctx, desc, reference)
+}
+
+// PushReference pushes the manifest with a reference tag.
+func (s *rateLimitedManifestStore) PushReference(ctx context.Context, expected ocispec.Descriptor, content io.Reader, reference string) error {
+	return s.limiter.pushReference(ctx, s.manifests, expected, content, reference)
+}
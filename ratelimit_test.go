@@ -0,0 +1,192 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/registry"
+)
+
+func TestRateLimiter_Requests(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewRateLimiter(RateLimiterOptions{RequestsPerSecond: 10})
+	blob := []byte("hello")
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+	store := memory.New()
+	if err := store.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	if err := store.Tag(ctx, desc, "latest"); err != nil {
+		t.Fatal("Tag() error =", err)
+	}
+
+	// the wrappers share the budget
+	target := limiter.Target(store)
+	readOnly := limiter.ReadOnlyTarget(store)
+	storage := limiter.Storage(store)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if _, err := target.Resolve(ctx, "latest"); err != nil {
+			t.Fatal("Resolve() error =", err)
+		}
+		if _, err := readOnly.Exists(ctx, desc); err != nil {
+			t.Fatal("Exists() error =", err)
+		}
+		if _, err := content.FetchAll(ctx, storage, desc); err != nil {
+			t.Fatal("FetchAll() error =", err)
+		}
+	}
+	// 12 requests exceed the burst of 10 by 2
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("requests took %v, want about 200ms", elapsed)
+	}
+
+	// waiting is cancelled with the context
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := target.Tag(cancelCtx, desc, "v1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Tag() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestRateLimiter_Bytes(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewRateLimiter(RateLimiterOptions{
+		FetchBytesPerSecond: 1000,
+		PushBytesPerSecond:  1000,
+	})
+	blob := bytes.Repeat([]byte("a"), 1200)
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+	target := limiter.Target(memory.New())
+
+	start := time.Now()
+	if err := target.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Push() took %v, want about 200ms", elapsed)
+	}
+
+	start = time.Now()
+	got, err := content.FetchAll(ctx, target, desc)
+	if err != nil {
+		t.Fatal("FetchAll() error =", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Error("FetchAll() content mismatch")
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("FetchAll() took %v, want about 200ms", elapsed)
+	}
+}
+
+func TestRateLimiter_Unlimited(t *testing.T) {
+	ctx := context.Background()
+	src, root := pushImage(t, []byte("{}"), []byte("layer"), "latest")
+	limiter := NewRateLimiter(RateLimiterOptions{})
+	dst := memory.New()
+	if _, err := Copy(ctx, limiter.ReadOnlyTarget(src), "latest", limiter.Target(dst), "latest", DefaultCopyOptions); err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+	if got, err := dst.Resolve(ctx, "latest"); err != nil || got.Digest != root.Digest {
+		t.Errorf("Resolve() = %v, %v, want %v", got.Digest, err, root.Digest)
+	}
+}
+
+func TestRateLimiter_Repository(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewRateLimiter(RateLimiterOptions{RequestsPerSecond: 10})
+	target := limiter.Target(&testRepository{Store: memory.New()})
+	repo, ok := target.(registry.Repository)
+	if !ok {
+		t.Fatalf("RateLimiter.Target() = %T, want registry.Repository", target)
+	}
+	if _, ok := target.(blobStoreProvider); !ok {
+		t.Errorf("RateLimiter.Target() = %T, want blobStoreProvider", target)
+	}
+
+	manifest := []byte(`{"schemaVersion":2}`)
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifest)
+	if err := repo.PushReference(ctx, desc, bytes.NewReader(manifest), "latest"); err != nil {
+		t.Fatal("Repository.PushReference() error =", err)
+	}
+
+	// the blob and manifest stores share the budget
+	blobs := repo.Blobs()
+	manifests := repo.Manifests()
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if _, err := blobs.Exists(ctx, desc); err != nil {
+			t.Fatal("BlobStore.Exists() error =", err)
+		}
+		if _, err := manifests.Resolve(ctx, "latest"); err != nil {
+			t.Fatal("ManifestStore.Resolve() error =", err)
+		}
+		if err := repo.Tags(ctx, "", func([]string) error { return nil }); err != nil {
+			t.Fatal("Repository.Tags() error =", err)
+		}
+	}
+	// 13 requests exceed the burst of 10 by 3
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("requests took %v, want about 200ms", elapsed)
+	}
+}
+
+// testRepository is a registry.Repository backed by a memory store, whose
+// blob and manifest stores share the same storage.
+type testRepository struct {
+	*memory.Store
+}
+
+func (r *testRepository) FetchReference(ctx context.Context, reference string) (ocispec.Descriptor, io.ReadCloser, error) {
+	desc, err := r.Resolve(ctx, reference)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	rc, err := r.Fetch(ctx, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, nil, err
+	}
+	return desc, rc, nil
+}
+
+func (r *testRepository) PushReference(ctx context.Context, expected ocispec.Descriptor, content io.Reader, reference string) error {
+	if err := r.Push(ctx, expected, content); err != nil {
+		return err
+	}
+	return r.Tag(ctx, expected, reference)
+}
+
+func (r *testRepository) Referrers(ctx context.Context, desc ocispec.Descriptor, artifactType string, fn func(referrers []ocispec.Descriptor) error) error {
+	return fn(nil)
+}
+
+func (r *testRepository) Blobs() registry.BlobStore {
+	return r
+}
+
+func (r *testRepository) Manifests() registry.ManifestStore {
+	return r
+}
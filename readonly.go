@@ -0,0 +1,84 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// readOnlyTarget is a Target refusing all the write operations.
+type readOnlyTarget struct {
+	ReadOnlyTarget
+}
+
+// readOnlyGraphTarget is a GraphTarget refusing all the write operations.
+type readOnlyGraphTarget struct {
+	readOnlyTarget
+	finder content.PredecessorFinder
+}
+
+// NewReadOnlyTarget wraps t as a Target whose Push, PushReference, Tag and
+// Delete return errdef.ErrUnsupported without reaching t, so that t can be
+// passed to code paths requiring a Target while guaranteed not to be
+// modified. The returned Target is a GraphTarget if t is a
+// ReadOnlyGraphTarget.
+//
+// Where the code path is under control, accepting a ReadOnlyTarget enforces
+// the read-only access at compile time instead.
+func NewReadOnlyTarget(t ReadOnlyTarget) Target {
+	switch t := t.(type) {
+	case *readOnlyTarget, *readOnlyGraphTarget:
+		return t.(Target)
+	case ReadOnlyGraphTarget:
+		return &readOnlyGraphTarget{
+			readOnlyTarget: readOnlyTarget{ReadOnlyTarget: t},
+			finder:         t,
+		}
+	default:
+		return &readOnlyTarget{ReadOnlyTarget: t}
+	}
+}
+
+// Push returns errdef.ErrUnsupported.
+func (t *readOnlyTarget) Push(_ context.Context, expected ocispec.Descriptor, _ io.Reader) error {
+	return fmt.Errorf("%s: %s: push to read-only target: %w", expected.Digest, expected.MediaType, errdef.ErrUnsupported)
+}
+
+// PushReference returns errdef.ErrUnsupported.
+func (t *readOnlyTarget) PushReference(_ context.Context, expected ocispec.Descriptor, _ io.Reader, reference string) error {
+	return fmt.Errorf("%s: push to read-only target: %w", reference, errdef.ErrUnsupported)
+}
+
+// Tag returns errdef.ErrUnsupported.
+func (t *readOnlyTarget) Tag(_ context.Context, _ ocispec.Descriptor, reference string) error {
+	return fmt.Errorf("%s: tag on read-only target: %w", reference, errdef.ErrUnsupported)
+}
+
+// Delete returns errdef.ErrUnsupported.
+func (t *readOnlyTarget) Delete(_ context.Context, target ocispec.Descriptor) error {
+	return fmt.Errorf("%s: %s: delete from read-only target: %w", target.Digest, target.MediaType, errdef.ErrUnsupported)
+}
+
+// Predecessors returns the nodes directly pointing to the current node.
+func (t *readOnlyGraphTarget) Predecessors(ctx context.Context, node ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	return t.finder.Predecessors(ctx, node)
+}
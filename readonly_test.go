@@ -0,0 +1,100 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+)
+
+func TestNewReadOnlyTarget(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	blob := []byte("hello")
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+	if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	if err := s.Tag(ctx, desc, "latest"); err != nil {
+		t.Fatal("Tag() error =", err)
+	}
+
+	target := NewReadOnlyTarget(s)
+	if _, ok := target.(GraphTarget); !ok {
+		t.Error("NewReadOnlyTarget() is not a GraphTarget")
+	}
+	if NewReadOnlyTarget(target) != target {
+		t.Error("NewReadOnlyTarget() wraps a read-only target again")
+	}
+
+	// reads are forwarded
+	got, err := content.FetchAll(ctx, target, desc)
+	if err != nil || !bytes.Equal(got, blob) {
+		t.Errorf("FetchAll() = %q, %v, want %q", got, err, blob)
+	}
+	if resolved, err := target.Resolve(ctx, "latest"); err != nil || resolved.Digest != desc.Digest {
+		t.Errorf("Resolve() = %v, %v, want %v", resolved.Digest, err, desc.Digest)
+	}
+
+	// writes are refused
+	other := []byte("world")
+	otherDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, other)
+	if err := target.Push(ctx, otherDesc, bytes.NewReader(other)); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Push() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+	if err := target.Tag(ctx, desc, "v1"); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Tag() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+	if err := target.(registry.ReferencePusher).PushReference(ctx, otherDesc, bytes.NewReader(other), "v1"); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("PushReference() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+	if err := target.(content.Deleter).Delete(ctx, desc); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Delete() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+	if exists, err := s.Exists(ctx, otherDesc); err != nil || exists {
+		t.Errorf("Exists() = %v, %v, want false", exists, err)
+	}
+	if exists, err := s.Exists(ctx, desc); err != nil || !exists {
+		t.Errorf("Exists() = %v, %v, want true", exists, err)
+	}
+	if _, err := s.Resolve(ctx, "v1"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Resolve() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+
+	// copying into a read-only target fails
+	src := memory.New()
+	if err := src.Push(ctx, otherDesc, bytes.NewReader(other)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	if err := CopyGraph(ctx, src, target, otherDesc, DefaultCopyGraphOptions); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("CopyGraph() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+}
+
+func TestNewReadOnlyTarget_NonGraph(t *testing.T) {
+	target := NewReadOnlyTarget(struct{ ReadOnlyTarget }{memory.New()})
+	if _, ok := target.(GraphTarget); ok {
+		t.Error("NewReadOnlyTarget() is a GraphTarget, want not")
+	}
+}
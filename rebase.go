@@ -0,0 +1,243 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/docker"
+)
+
+// RebaseOptions contains parameters for [oras.Rebase].
+type RebaseOptions struct {
+	// BaseLayerCount, if greater than 0, is the number of the base layers
+	// of the image to be replaced, when the old base image is not provided.
+	BaseLayerCount int
+	// NewBaseName, if not empty, is recorded in the
+	// "org.opencontainers.image.base.name" annotation of the new manifest.
+	NewBaseName string
+}
+
+// Rebase replaces the base layers of the image manifest described by image
+// in the storage with the layers of the image manifest described by newBase,
+// and returns the descriptor of the new manifest. The layers of newBase must
+// exist in the storage.
+//
+// The base layers to be replaced are determined by the first available of:
+//   - the layers of the image manifest described by oldBase, if oldBase is
+//     not a zero descriptor, which must match the first layers of the image
+//     by digests;
+//   - the first opts.BaseLayerCount layers of the image;
+//   - the layers of the base image recorded by the
+//     "org.opencontainers.image.base.name" or
+//     "org.opencontainers.image.base.digest" annotation of the image,
+//     resolved by the storage if it is a content.Resolver.
+//
+// In the new config, the diff IDs and the history entries of the old base
+// are replaced by the ones of the new base, while the rest of the config is
+// kept. The application layers and their history entries are not changed.
+// The "org.opencontainers.image.base.digest" annotation of the new manifest
+// is set to the digest of newBase. The new manifest is not tagged.
+func Rebase(ctx context.Context, storage content.Storage, image, oldBase, newBase ocispec.Descriptor, opts RebaseOptions) (ocispec.Descriptor, error) {
+	imageManifest, imageConfig, err := fetchImage(ctx, storage, image)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	newManifest, newConfig, err := fetchImage(ctx, storage, newBase)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	for _, layer := range newManifest.Layers {
+		exists, err := storage.Exists(ctx, layer)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		if !exists {
+			return ocispec.Descriptor{}, fmt.Errorf("%s: %s: layer of the new base: %w", layer.Digest, layer.MediaType, errdef.ErrNotFound)
+		}
+	}
+
+	// find the old base layers and history entries
+	var baseLayers, baseHistory int
+	switch {
+	case oldBase.Digest != "":
+		baseLayers, baseHistory, err = matchBase(ctx, storage, imageManifest, oldBase)
+	case opts.BaseLayerCount > 0:
+		baseLayers = opts.BaseLayerCount
+		baseHistory, err = countHistory(imageConfig.History, baseLayers)
+	default:
+		oldBase, err = resolveBase(ctx, storage, imageManifest)
+		if err == nil {
+			baseLayers, baseHistory, err = matchBase(ctx, storage, imageManifest, oldBase)
+		}
+	}
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if baseLayers > len(imageManifest.Layers) || baseLayers > len(imageConfig.RootFS.DiffIDs) || baseHistory > len(imageConfig.History) {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: %s: %d base layers exceed the image: %w", image.Digest, image.MediaType, baseLayers, errdef.ErrNonConformant)
+	}
+
+	// rewrite the config, keeping unknown fields
+	configJSON, err := content.FetchAll(ctx, storage, imageManifest.Config)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	var config map[string]json.RawMessage
+	if err := json.Unmarshal(configJSON, &config); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to decode config %s: %w", imageManifest.Config.Digest, err)
+	}
+	rootFS := imageConfig.RootFS
+	rootFS.DiffIDs = append(append([]digest.Digest{}, newConfig.RootFS.DiffIDs...), imageConfig.RootFS.DiffIDs[baseLayers:]...)
+	if config["rootfs"], err = json.Marshal(rootFS); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	history := append(append([]ocispec.History{}, newConfig.History...), imageConfig.History[baseHistory:]...)
+	if len(history) > 0 {
+		if config["history"], err = json.Marshal(history); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+	if configJSON, err = json.Marshal(config); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	configDesc := content.NewDescriptorFromBytes(imageManifest.Config.MediaType, configJSON)
+	if err := storage.Push(ctx, configDesc, bytes.NewReader(configJSON)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return ocispec.Descriptor{}, err
+	}
+
+	// rewrite the manifest
+	manifest := imageManifest
+	manifest.Config = configDesc
+	manifest.Layers = append(append([]ocispec.Descriptor{}, newManifest.Layers...), imageManifest.Layers[baseLayers:]...)
+	annotations := make(map[string]string, len(imageManifest.Annotations)+2)
+	for k, v := range imageManifest.Annotations {
+		annotations[k] = v
+	}
+	annotations[ocispec.AnnotationBaseImageDigest] = newBase.Digest.String()
+	if opts.NewBaseName != "" {
+		annotations[ocispec.AnnotationBaseImageName] = opts.NewBaseName
+	}
+	manifest.Annotations = annotations
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	desc := content.NewDescriptorFromBytes(image.MediaType, manifestJSON)
+	if err := storage.Push(ctx, desc, bytes.NewReader(manifestJSON)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return ocispec.Descriptor{}, err
+	}
+	return desc, nil
+}
+
+// fetchImage fetches the image manifest and its config.
+func fetchImage(ctx context.Context, fetcher content.Fetcher, desc ocispec.Descriptor) (ocispec.Manifest, ocispec.Image, error) {
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageManifest, docker.MediaTypeManifest:
+	default:
+		return ocispec.Manifest{}, ocispec.Image{}, fmt.Errorf("%s: %s: not an image manifest: %w", desc.Digest, desc.MediaType, errdef.ErrUnsupported)
+	}
+	manifestJSON, err := content.FetchAll(ctx, fetcher, desc)
+	if err != nil {
+		return ocispec.Manifest{}, ocispec.Image{}, err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return ocispec.Manifest{}, ocispec.Image{}, fmt.Errorf("failed to decode manifest %s: %w", desc.Digest, err)
+	}
+	configJSON, err := content.FetchAll(ctx, fetcher, manifest.Config)
+	if err != nil {
+		return ocispec.Manifest{}, ocispec.Image{}, err
+	}
+	var config ocispec.Image
+	if err := json.Unmarshal(configJSON, &config); err != nil {
+		return ocispec.Manifest{}, ocispec.Image{}, fmt.Errorf("failed to decode config %s: %w", manifest.Config.Digest, err)
+	}
+	return manifest, config, nil
+}
+
+// matchBase matches the layers of the base image against the first layers of
+// the image by digests, and returns the numbers of the base layers and the
+// base history entries.
+func matchBase(ctx context.Context, fetcher content.Fetcher, image ocispec.Manifest, base ocispec.Descriptor) (int, int, error) {
+	baseManifest, baseConfig, err := fetchImage(ctx, fetcher, base)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(baseManifest.Layers) > len(image.Layers) {
+		return 0, 0, fmt.Errorf("%s: %s: base has more layers than the image: %w", base.Digest, base.MediaType, errdef.ErrNonConformant)
+	}
+	for i, layer := range baseManifest.Layers {
+		if image.Layers[i].Digest != layer.Digest {
+			return 0, 0, fmt.Errorf("%s: %s: layer %d mismatches the base layer %s: %w", base.Digest, base.MediaType, i, layer.Digest, errdef.ErrNonConformant)
+		}
+	}
+	return len(baseManifest.Layers), len(baseConfig.History), nil
+}
+
+// countHistory returns the number of the history entries of the first n
+// non-empty layers.
+func countHistory(history []ocispec.History, n int) (int, error) {
+	if len(history) == 0 {
+		return 0, nil
+	}
+	for i, entry := range history {
+		if n == 0 {
+			return i, nil
+		}
+		if !entry.EmptyLayer {
+			n--
+		}
+	}
+	if n == 0 {
+		return len(history), nil
+	}
+	return 0, fmt.Errorf("history has fewer layers than the base layers: %w", errdef.ErrNonConformant)
+}
+
+// resolveBase resolves the base image recorded by the annotations of the
+// image manifest.
+func resolveBase(ctx context.Context, storage content.Storage, image ocispec.Manifest) (ocispec.Descriptor, error) {
+	name := image.Annotations[ocispec.AnnotationBaseImageName]
+	dgst := image.Annotations[ocispec.AnnotationBaseImageDigest]
+	reference := name
+	if reference == "" {
+		reference = dgst
+	}
+	if reference == "" {
+		return ocispec.Descriptor{}, errors.New("unable to determine the base layers: no old base, base layer count, or base image annotations")
+	}
+	resolver, ok := storage.(content.Resolver)
+	if !ok {
+		return ocispec.Descriptor{}, fmt.Errorf("unable to resolve the base image %s: storage is not a resolver: %w", reference, errdef.ErrUnsupported)
+	}
+	base, err := resolver.Resolve(ctx, reference)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to resolve the base image %s: %w", reference, err)
+	}
+	if dgst != "" && base.Digest.String() != dgst {
+		return ocispec.Descriptor{}, fmt.Errorf("base image %s resolved to %s instead of %s: %w", reference, base.Digest, dgst, errdef.ErrNotFound)
+	}
+	return base, nil
+}
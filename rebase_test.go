@@ -0,0 +1,169 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// rebaseTestImage pushes an image of the layers, with a history entry per
+// layer, to s.
+func rebaseTestImage(t *testing.T, s *memory.Store, annotations map[string]string, layers ...string) ocispec.Descriptor {
+	t.Helper()
+	ctx := context.Background()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+			t.Fatal("Store.Push() error =", err)
+		}
+		return desc
+	}
+	config := ocispec.Image{
+		Platform: ocispec.Platform{Architecture: "amd64", OS: "linux"},
+		RootFS:   ocispec.RootFS{Type: "layers"},
+	}
+	var layerDescs []ocispec.Descriptor
+	for _, layer := range layers {
+		desc := push(ocispec.MediaTypeImageLayer, []byte(layer))
+		layerDescs = append(layerDescs, desc)
+		config.RootFS.DiffIDs = append(config.RootFS.DiffIDs, desc.Digest)
+		config.History = append(config.History, ocispec.History{CreatedBy: layer})
+	}
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Versioned:   specs.Versioned{SchemaVersion: 2},
+		MediaType:   ocispec.MediaTypeImageManifest,
+		Config:      push(ocispec.MediaTypeImageConfig, configJSON),
+		Layers:      layerDescs,
+		Annotations: annotations,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return push(ocispec.MediaTypeImageManifest, manifestJSON)
+}
+
+// assertRebased checks the layers and the history of the rebased image.
+func assertRebased(t *testing.T, s *memory.Store, desc ocispec.Descriptor, newBase ocispec.Descriptor, want ...string) {
+	t.Helper()
+	ctx := context.Background()
+	manifest, config, err := fetchImage(ctx, s, desc)
+	if err != nil {
+		t.Fatalf("fetchImage() error = %v", err)
+	}
+	if len(manifest.Layers) != len(want) || len(config.RootFS.DiffIDs) != len(want) || len(config.History) != len(want) {
+		t.Fatalf("rebased image has %d layers, %d diff IDs, %d history entries, want %d", len(manifest.Layers), len(config.RootFS.DiffIDs), len(config.History), len(want))
+	}
+	for i, layer := range want {
+		wantDigest := digest.FromString(layer)
+		if manifest.Layers[i].Digest != wantDigest || config.RootFS.DiffIDs[i] != wantDigest {
+			t.Errorf("layer %d = %v, want %v", i, manifest.Layers[i].Digest, wantDigest)
+		}
+		if config.History[i].CreatedBy != layer {
+			t.Errorf("history %d = %v, want %v", i, config.History[i].CreatedBy, layer)
+		}
+	}
+	if got := manifest.Annotations[ocispec.AnnotationBaseImageDigest]; got != newBase.Digest.String() {
+		t.Errorf("base digest annotation = %v, want %v", got, newBase.Digest)
+	}
+	if config.Architecture != "amd64" {
+		t.Errorf("config platform = %v, want kept", config.Platform)
+	}
+}
+
+func TestRebase(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	oldBase := rebaseTestImage(t, s, nil, "base1", "base2")
+	newBase := rebaseTestImage(t, s, nil, "newbase")
+	image := rebaseTestImage(t, s, nil, "base1", "base2", "app")
+
+	// by the old base
+	rebased, err := Rebase(ctx, s, image, oldBase, newBase, RebaseOptions{NewBaseName: "base:v2"})
+	if err != nil {
+		t.Fatalf("Rebase() error = %v", err)
+	}
+	assertRebased(t, s, rebased, newBase, "newbase", "app")
+
+	// by the layer count
+	byCount, err := Rebase(ctx, s, image, ocispec.Descriptor{}, newBase, RebaseOptions{BaseLayerCount: 2, NewBaseName: "base:v2"})
+	if err != nil {
+		t.Fatalf("Rebase() error = %v", err)
+	}
+	if byCount.Digest != rebased.Digest {
+		t.Errorf("Rebase() = %v, want %v", byCount.Digest, rebased.Digest)
+	}
+
+	// mismatched old base
+	other := rebaseTestImage(t, s, nil, "other")
+	if _, err := Rebase(ctx, s, image, other, newBase, RebaseOptions{}); !errors.Is(err, errdef.ErrNonConformant) {
+		t.Errorf("Rebase() error = %v, want %v", err, errdef.ErrNonConformant)
+	}
+
+	// missing layers of the new base
+	missing := rebaseTestImage(t, memory.New(), nil, "missing")
+	if _, err := Rebase(ctx, s, image, oldBase, missing, RebaseOptions{}); err == nil {
+		t.Error("Rebase() error = nil, want error")
+	}
+}
+
+func TestRebase_Annotations(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	oldBase := rebaseTestImage(t, s, nil, "base1", "base2")
+	if err := s.Tag(ctx, oldBase, "base:v1"); err != nil {
+		t.Fatal(err)
+	}
+	newBase := rebaseTestImage(t, s, nil, "newbase")
+	image := rebaseTestImage(t, s, map[string]string{
+		ocispec.AnnotationBaseImageName:   "base:v1",
+		ocispec.AnnotationBaseImageDigest: oldBase.Digest.String(),
+	}, "base1", "base2", "app")
+
+	rebased, err := Rebase(ctx, s, image, ocispec.Descriptor{}, newBase, RebaseOptions{})
+	if err != nil {
+		t.Fatalf("Rebase() error = %v", err)
+	}
+	assertRebased(t, s, rebased, newBase, "newbase", "app")
+
+	// the base name moved to another image
+	if err := s.Tag(ctx, newBase, "base:v1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Rebase(ctx, s, image, ocispec.Descriptor{}, newBase, RebaseOptions{}); err == nil {
+		t.Error("Rebase() error = nil, want error")
+	}
+
+	// no way to find the base layers
+	plain := rebaseTestImage(t, s, nil, "base1", "app")
+	if _, err := Rebase(ctx, s, plain, ocispec.Descriptor{}, newBase, RebaseOptions{}); err == nil {
+		t.Error("Rebase() error = nil, want error")
+	}
+}
@@ -0,0 +1,446 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package recompress copies images while converting the compression of their
+// layers, such as from gzip to zstd.
+package recompress
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/compression"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/docker"
+	"oras.land/oras-go/v2/internal/pool"
+	"oras.land/oras-go/v2/internal/syncutil"
+)
+
+// defaultConcurrency is the default number of layers recompressed
+// concurrently.
+const defaultConcurrency = 3
+
+// CopyOptions contains parameters for [recompress.Copy].
+type CopyOptions struct {
+	// CopyGraphOptions is used for copying the content not recompressed.
+	// Its Concurrency also limits the number of layers recompressed
+	// concurrently.
+	oras.CopyGraphOptions
+	// Algorithm is the compression algorithm of the layers to be converted
+	// to. A compressor of the algorithm must be registered in the
+	// compression package.
+	Algorithm compression.Algorithm
+	// LayerFilter selects the layers to be recompressed.
+	// If nil, all the applicable layers are selected.
+	LayerFilter func(desc ocispec.Descriptor) bool
+	// TempDir is the directory for staging the recompressed layers.
+	// If empty, the default directory for temporary files is used.
+	TempDir string
+}
+
+// Copy copies the image, or the index of images, tagged by srcRef in src to
+// dst like oras.Copy, while recompressing the layers by opts.Algorithm.
+// The manifests referencing the recompressed layers are rewritten with the
+// new media types, digests and sizes, and the new root is tagged by dstRef
+// in dst. If dstRef is empty, srcRef is used.
+//
+// Layers are applicable if they are in known compression formats other than
+// opts.Algorithm, and their media types can be converted, such as
+// "application/vnd.oci.image.layer.v1.tar+gzip" to
+// "application/vnd.oci.image.layer.v1.tar+zstd". Docker layers can only be
+// kept in gzip. Foreign layers are not recompressed. Since diff IDs are the
+// digests of the uncompressed layers, image configs are not changed.
+// Returns the descriptor of the new root.
+func Copy(ctx context.Context, src oras.ReadOnlyTarget, srcRef string, dst oras.Target, dstRef string, opts CopyOptions) (ocispec.Descriptor, error) {
+	if _, err := compression.Compress(opts.Algorithm, io.Discard); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if dstRef == "" {
+		dstRef = srcRef
+	}
+	root, err := src.Resolve(ctx, srcRef)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to resolve %s: %w", srcRef, err)
+	}
+
+	r := &recompressor{
+		src:          src,
+		dst:          dst,
+		opts:         opts,
+		manifests:    make(map[digest.Digest][]byte),
+		recompressed: make(map[digest.Digest]ocispec.Descriptor),
+	}
+	var layers []ocispec.Descriptor
+	if err := r.collect(ctx, root, &layers); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := r.recompressAll(ctx, layers); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	newRoot, err := r.rewrite(ctx, root)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := dst.Tag(ctx, newRoot, dstRef); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return newRoot, nil
+}
+
+// MediaType returns the media type of the layer of the given media type
+// recompressed by alg. Returns false if the media type cannot be converted.
+func MediaType(mediaType string, alg compression.Algorithm) (string, bool) {
+	switch mediaType {
+	case ocispec.MediaTypeImageLayer, ocispec.MediaTypeImageLayerGzip, ocispec.MediaTypeImageLayerZstd:
+		if newMediaType := compression.LayerMediaType(alg); newMediaType != "" {
+			return newMediaType, true
+		}
+		return "", false
+	case docker.MediaTypeLayer:
+		// docker image manifests only support gzip layers
+		return mediaType, alg == compression.Gzip
+	}
+	var base string
+	switch {
+	case strings.HasSuffix(mediaType, "+gzip"):
+		base = strings.TrimSuffix(mediaType, "+gzip")
+	case strings.HasSuffix(mediaType, "+zstd"):
+		base = strings.TrimSuffix(mediaType, "+zstd")
+	default:
+		return "", false
+	}
+	switch alg {
+	case compression.Uncompressed:
+		return base, true
+	case compression.Gzip, compression.Zstd:
+		return base + "+" + string(alg), true
+	default:
+		return "", false
+	}
+}
+
+// recompressor copies graphs while recompressing the selected layers.
+type recompressor struct {
+	src  oras.ReadOnlyTarget
+	dst  oras.Target
+	opts CopyOptions
+	// manifests caches the manifests and the indexes fetched.
+	manifests map[digest.Digest][]byte
+	// recompressed maps the digests of the recompressed layers to the
+	// descriptors of the results.
+	recompressed map[digest.Digest]ocispec.Descriptor
+	lock         sync.Mutex
+}
+
+// selectLayer returns true if the layer is to be recompressed.
+func (r *recompressor) selectLayer(desc ocispec.Descriptor) bool {
+	if descriptor.IsForeignLayer(desc) {
+		return false
+	}
+	alg, ok := compression.FromMediaType(desc.MediaType)
+	if !ok || alg == r.opts.Algorithm {
+		return false
+	}
+	if newMediaType, ok := MediaType(desc.MediaType, r.opts.Algorithm); !ok || newMediaType == desc.MediaType {
+		return false
+	}
+	return r.opts.LayerFilter == nil || r.opts.LayerFilter(desc)
+}
+
+// fetchManifest fetches the manifest or the index, with caching.
+func (r *recompressor) fetchManifest(ctx context.Context, desc ocispec.Descriptor) (map[string]json.RawMessage, error) {
+	manifestJSON, ok := r.manifests[desc.Digest]
+	if !ok {
+		var err error
+		manifestJSON, err = content.FetchAll(ctx, r.src, desc)
+		if err != nil {
+			return nil, err
+		}
+		r.manifests[desc.Digest] = manifestJSON
+	}
+	var manifest map[string]json.RawMessage
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %s: %w", desc.Digest, desc.MediaType, err)
+	}
+	return manifest, nil
+}
+
+// children decodes the descriptors in the field of the manifest.
+func children(manifest map[string]json.RawMessage, field string, desc ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+	var descs []ocispec.Descriptor
+	if raw, ok := manifest[field]; ok {
+		if err := json.Unmarshal(raw, &descs); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %s: %w", desc.Digest, desc.MediaType, err)
+		}
+	}
+	return descs, nil
+}
+
+// collect collects the layers to be recompressed in the graph rooted by desc.
+func (r *recompressor) collect(ctx context.Context, desc ocispec.Descriptor, layers *[]ocispec.Descriptor) error {
+	var field string
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageIndex, docker.MediaTypeManifestList:
+		field = "manifests"
+	case ocispec.MediaTypeImageManifest, docker.MediaTypeManifest:
+		field = "layers"
+	default:
+		return nil
+	}
+	manifest, err := r.fetchManifest(ctx, desc)
+	if err != nil {
+		return err
+	}
+	descs, err := children(manifest, field, desc)
+	if err != nil {
+		return err
+	}
+	for _, child := range descs {
+		if field == "manifests" {
+			if err := r.collect(ctx, child, layers); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, ok := r.recompressed[child.Digest]; ok || !r.selectLayer(child) {
+			continue
+		}
+		// mark the layer as collected
+		r.recompressed[child.Digest] = ocispec.Descriptor{}
+		*layers = append(*layers, child)
+	}
+	return nil
+}
+
+// recompressAll recompresses the layers concurrently.
+func (r *recompressor) recompressAll(ctx context.Context, layers []ocispec.Descriptor) error {
+	concurrency := r.opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	eg, egCtx := syncutil.LimitGroup(ctx, concurrency)
+	for _, layer := range layers {
+		layer := layer
+		eg.Go(func() error {
+			newDesc, err := r.recompress(egCtx, layer)
+			if err != nil {
+				return fmt.Errorf("failed to recompress %s: %w", layer.Digest, err)
+			}
+			r.lock.Lock()
+			r.recompressed[layer.Digest] = newDesc
+			r.lock.Unlock()
+			return nil
+		})
+	}
+	return eg.Wait()
+}
+
+// recompress recompresses the layer, and pushes the result to the
+// destination.
+func (r *recompressor) recompress(ctx context.Context, desc ocispec.Descriptor) (ocispec.Descriptor, error) {
+	alg, _ := compression.FromMediaType(desc.MediaType)
+	mediaType, _ := MediaType(desc.MediaType, r.opts.Algorithm)
+
+	fp, err := os.CreateTemp(r.opts.TempDir, "oras_recompress_*")
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	defer func() {
+		fp.Close()
+		os.Remove(fp.Name())
+	}()
+
+	rc, err := r.src.Fetch(ctx, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	defer rc.Close()
+	vr := content.NewVerifyReader(rc, desc)
+	zr, err := compression.Decompress(alg, vr)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	defer zr.Close()
+
+	digester := digest.Canonical.Digester()
+	zw, err := compression.Compress(r.opts.Algorithm, io.MultiWriter(fp, digester.Hash()))
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if _, err := pool.Stream.Copy(zw, zr); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := zw.Close(); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	// drain the content left, such as gzip trailers
+	if _, err := pool.Stream.Discard(vr); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := vr.Verify(); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	size, err := fp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if _, err := fp.Seek(0, io.SeekStart); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	newDesc := ocispec.Descriptor{
+		MediaType:   mediaType,
+		Digest:      digester.Digest(),
+		Size:        size,
+		Annotations: layerAnnotations(desc),
+	}
+	if err := r.push(ctx, newDesc, fp); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return newDesc, nil
+}
+
+// layerAnnotations returns the annotations of the layer to be kept, which
+// exclude the zstd:chunked metadata no longer valid after recompression.
+func layerAnnotations(desc ocispec.Descriptor) map[string]string {
+	chunked := compression.ZstdChunkedAnnotations(desc)
+	var annotations map[string]string
+	for k, v := range desc.Annotations {
+		if _, ok := chunked[k]; ok {
+			continue
+		}
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[k] = v
+	}
+	return annotations
+}
+
+// rewrite copies the graph rooted by desc with the recompressed layers, and
+// returns the descriptor of the new root.
+func (r *recompressor) rewrite(ctx context.Context, desc ocispec.Descriptor) (ocispec.Descriptor, error) {
+	var field string
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageIndex, docker.MediaTypeManifestList:
+		field = "manifests"
+	case ocispec.MediaTypeImageManifest, docker.MediaTypeManifest:
+		field = "layers"
+	default:
+		return desc, r.copyGraph(ctx, desc)
+	}
+	manifest, err := r.fetchManifest(ctx, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	descs, err := children(manifest, field, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	changed := false
+	for i, child := range descs {
+		var newChild ocispec.Descriptor
+		if field == "manifests" {
+			if newChild, err = r.rewrite(ctx, child); err != nil {
+				return ocispec.Descriptor{}, err
+			}
+		} else if recompressed, ok := r.recompressed[child.Digest]; ok {
+			newChild = recompressed
+		} else {
+			newChild = child
+		}
+		if !content.Equal(newChild, child) {
+			descs[i] = newChild
+			changed = true
+		}
+	}
+	if !changed {
+		return desc, r.copyGraph(ctx, desc)
+	}
+
+	// copy the other successors, such as the config, the subject, and the
+	// layers not recompressed
+	for _, key := range []string{"config", "subject"} {
+		raw, ok := manifest[key]
+		if !ok {
+			continue
+		}
+		var successor ocispec.Descriptor
+		if err := json.Unmarshal(raw, &successor); err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("failed to decode %s: %s: %w", desc.Digest, desc.MediaType, err)
+		}
+		if err := r.copyGraph(ctx, successor); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+	}
+	if field == "layers" {
+		for _, layer := range descs {
+			if _, ok := r.recompressed[layer.Digest]; ok || descriptor.IsForeignLayer(layer) {
+				continue
+			}
+			if err := r.copyGraph(ctx, layer); err != nil {
+				return ocispec.Descriptor{}, err
+			}
+		}
+	}
+
+	if manifest[field], err = json.Marshal(descs); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	newDesc := content.NewDescriptorFromBytes(desc.MediaType, manifestJSON)
+	newDesc.ArtifactType = desc.ArtifactType
+	newDesc.Annotations = desc.Annotations
+	newDesc.Platform = desc.Platform
+	if err := r.push(ctx, newDesc, bytes.NewReader(manifestJSON)); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return newDesc, nil
+}
+
+// push pushes the content to the destination, unless the content already
+// exists.
+func (r *recompressor) push(ctx context.Context, desc ocispec.Descriptor, rd io.Reader) error {
+	if exists, err := r.dst.Exists(ctx, desc); err != nil {
+		return err
+	} else if exists {
+		return nil
+	}
+	if err := r.dst.Push(ctx, desc, rd); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return err
+	}
+	return nil
+}
+
+// copyGraph copies the graph rooted by desc as is.
+func (r *recompressor) copyGraph(ctx context.Context, desc ocispec.Descriptor) error {
+	return oras.CopyGraph(ctx, r.src, r.dst, desc, r.opts.CopyGraphOptions)
+}
@@ -0,0 +1,214 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recompress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/compression"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/docker"
+)
+
+// testStore is a memory store with helpers to push test images.
+type testStore struct {
+	*memory.Store
+	t *testing.T
+}
+
+func (s *testStore) push(mediaType string, blob []byte) ocispec.Descriptor {
+	s.t.Helper()
+	desc := content.NewDescriptorFromBytes(mediaType, blob)
+	if err := s.Push(context.Background(), desc, bytes.NewReader(blob)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		s.t.Fatal("Store.Push() error =", err)
+	}
+	return desc
+}
+
+func (s *testStore) pushJSON(mediaType string, v any) ocispec.Descriptor {
+	s.t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return s.push(mediaType, b)
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// pushImage pushes an image of the layers.
+func (s *testStore) pushImage(layers ...ocispec.Descriptor) ocispec.Descriptor {
+	return s.pushJSON(ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    s.push(ocispec.MediaTypeImageConfig, []byte("{}")),
+		Layers:    layers,
+	})
+}
+
+func fetchManifest(t *testing.T, fetcher content.Fetcher, desc ocispec.Descriptor) ocispec.Manifest {
+	t.Helper()
+	b, err := content.FetchAll(context.Background(), fetcher, desc)
+	if err != nil {
+		t.Fatalf("FetchAll() error = %v", err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	return manifest
+}
+
+func TestCopy(t *testing.T) {
+	ctx := context.Background()
+	src := &testStore{Store: memory.New(), t: t}
+	shared := []byte("shared layer")
+	sharedLayer := src.push(ocispec.MediaTypeImageLayerGzip, gzipBytes(t, shared))
+	sharedLayer.Annotations = map[string]string{"foo": "bar"}
+	plainLayer := src.push(ocispec.MediaTypeImageLayer, []byte("plain layer"))
+	amd64 := src.pushImage(sharedLayer, plainLayer)
+	amd64.Platform = &ocispec.Platform{Architecture: "amd64", OS: "linux"}
+	arm64 := src.pushImage(sharedLayer)
+	arm64.Platform = &ocispec.Platform{Architecture: "arm64", OS: "linux"}
+	index := src.pushJSON(ocispec.MediaTypeImageIndex, ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{amd64, arm64},
+	})
+	if err := src.Tag(ctx, index, "latest"); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := memory.New()
+	root, err := Copy(ctx, src, "latest", dst, "", CopyOptions{
+		Algorithm: compression.Uncompressed,
+	})
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if tagged, err := dst.Resolve(ctx, "latest"); err != nil || tagged.Digest != root.Digest {
+		t.Errorf("dst.Resolve() = %v, %v, want %v", tagged.Digest, err, root.Digest)
+	}
+
+	indexJSON, err := content.FetchAll(ctx, dst, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var newIndex ocispec.Index
+	if err := json.Unmarshal(indexJSON, &newIndex); err != nil {
+		t.Fatal(err)
+	}
+	if len(newIndex.Manifests) != 2 {
+		t.Fatalf("manifests = %d, want 2", len(newIndex.Manifests))
+	}
+	if newIndex.Manifests[0].Platform == nil || newIndex.Manifests[0].Platform.Architecture != "amd64" {
+		t.Errorf("platform = %v, want kept", newIndex.Manifests[0].Platform)
+	}
+	wantLayer := ocispec.Descriptor{
+		MediaType:   ocispec.MediaTypeImageLayer,
+		Digest:      digest.FromBytes(shared),
+		Size:        int64(len(shared)),
+		Annotations: map[string]string{"foo": "bar"},
+	}
+	for _, m := range newIndex.Manifests {
+		manifest := fetchManifest(t, dst, m)
+		if got := manifest.Layers[0]; !content.Equal(got, wantLayer) || got.Annotations["foo"] != "bar" {
+			t.Errorf("layer = %v, want %v", got, wantLayer)
+		}
+		for _, layer := range manifest.Layers {
+			if exists, err := dst.Exists(ctx, layer); err != nil || !exists {
+				t.Errorf("dst.Exists(%v) = %v, %v, want true", layer.Digest, exists, err)
+			}
+		}
+		if exists, err := dst.Exists(ctx, manifest.Config); err != nil || !exists {
+			t.Errorf("dst.Exists(config) = %v, %v, want true", exists, err)
+		}
+	}
+	// the uncompressed layer is kept as is
+	if got := fetchManifest(t, dst, newIndex.Manifests[0]).Layers[1]; !content.Equal(got, plainLayer) {
+		t.Errorf("layer = %v, want %v", got, plainLayer)
+	}
+}
+
+func TestCopy_Unchanged(t *testing.T) {
+	ctx := context.Background()
+	src := &testStore{Store: memory.New(), t: t}
+	image := src.pushImage(src.push(ocispec.MediaTypeImageLayerGzip, gzipBytes(t, []byte("layer"))))
+	if err := src.Tag(ctx, image, "latest"); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := memory.New()
+	root, err := Copy(ctx, src, "latest", dst, "v1", CopyOptions{
+		Algorithm: compression.Gzip,
+	})
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if root.Digest != image.Digest {
+		t.Errorf("Copy() = %v, want %v", root.Digest, image.Digest)
+	}
+
+	if _, err := Copy(ctx, src, "latest", dst, "v1", CopyOptions{
+		Algorithm: compression.Zstd,
+	}); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Copy() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+}
+
+func TestMediaType(t *testing.T) {
+	tests := []struct {
+		mediaType string
+		alg       compression.Algorithm
+		want      string
+		wantOK    bool
+	}{
+		{ocispec.MediaTypeImageLayerGzip, compression.Zstd, ocispec.MediaTypeImageLayerZstd, true},
+		{ocispec.MediaTypeImageLayerZstd, compression.Gzip, ocispec.MediaTypeImageLayerGzip, true},
+		{ocispec.MediaTypeImageLayerGzip, compression.Uncompressed, ocispec.MediaTypeImageLayer, true},
+		{docker.MediaTypeLayer, compression.Zstd, "", false},
+		{"application/vnd.example.v1.tar+gzip", compression.Zstd, "application/vnd.example.v1.tar+zstd", true},
+		{"application/vnd.example.v1.tar+zstd", compression.Uncompressed, "application/vnd.example.v1.tar", true},
+		{"application/vnd.example.v1.tar", compression.Gzip, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := MediaType(tt.mediaType, tt.alg)
+		if ok != tt.wantOK || (ok && got != tt.want) {
+			t.Errorf("MediaType(%q, %v) = %q, %v, want %q, %v", tt.mediaType, tt.alg, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
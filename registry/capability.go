@@ -0,0 +1,130 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/spec"
+)
+
+// ListTags lists the tags available in the target.
+// fn is called for each page of the tag list. If `last` is NOT empty, the
+// entries in the response start after the tag specified by `last`.
+// Returns ErrUnsupported if the target does not implement TagLister.
+//
+// See also `Tags()` in this package.
+func ListTags(ctx context.Context, target content.Resolver, last string, fn func(tags []string) error) error {
+	lister, ok := target.(TagLister)
+	if !ok {
+		return fmt.Errorf("tag listing: %w", errdef.ErrUnsupported)
+	}
+	return lister.Tags(ctx, last, fn)
+}
+
+// Mount makes the blob with the given descriptor in fromRepo available in the
+// target.
+// Returns ErrUnsupported if the target does not implement Mounter.
+//
+// See also `Mounter` in this package.
+func Mount(ctx context.Context, target content.Pusher, desc ocispec.Descriptor, fromRepo string, getContent func() (io.ReadCloser, error)) error {
+	mounter, ok := target.(Mounter)
+	if !ok {
+		return fmt.Errorf("%s: %s: mount: %w", desc.Digest, desc.MediaType, errdef.ErrUnsupported)
+	}
+	return mounter.Mount(ctx, desc, fromRepo, getContent)
+}
+
+// Referrers lists the descriptors of image or artifact manifests directly
+// referencing the given manifest descriptor.
+// If artifactType is not empty, only referrers of the same artifact type are
+// returned.
+//
+//   - If the store implements ReferrerLister, the Referrers API is used.
+//   - If the store implements content.PredecessorFinder, the predecessors
+//     whose subject is desc are fetched and returned.
+//   - Otherwise ErrUnsupported is returned.
+func Referrers(ctx context.Context, store content.ReadOnlyStorage, desc ocispec.Descriptor, artifactType string) ([]ocispec.Descriptor, error) {
+	if lister, ok := store.(ReferrerLister); ok {
+		var results []ocispec.Descriptor
+		if err := lister.Referrers(ctx, desc, artifactType, func(referrers []ocispec.Descriptor) error {
+			results = append(results, referrers...)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+
+	finder, ok := store.(content.PredecessorFinder)
+	if !ok {
+		return nil, fmt.Errorf("%s: %s: referrers: %w", desc.Digest, desc.MediaType, errdef.ErrUnsupported)
+	}
+	predecessors, err := finder.Predecessors(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	var results []ocispec.Descriptor
+	for _, node := range predecessors {
+		switch node.MediaType {
+		case ocispec.MediaTypeImageManifest, spec.MediaTypeArtifactManifest:
+			referrer, ok, err := referrerOf(ctx, store, node, desc)
+			if err != nil {
+				return nil, err
+			}
+			if ok && (artifactType == "" || referrer.ArtifactType == artifactType) {
+				results = append(results, referrer)
+			}
+		}
+	}
+	return results, nil
+}
+
+// referrerOf fetches the manifest described by node and checks whether its
+// subject is the given subject. If so, returns the node populated with the
+// artifact type and the annotations of the manifest.
+func referrerOf(ctx context.Context, fetcher content.Fetcher, node, subject ocispec.Descriptor) (ocispec.Descriptor, bool, error) {
+	manifestJSON, err := content.FetchAll(ctx, fetcher, node)
+	if err != nil {
+		return ocispec.Descriptor{}, false, err
+	}
+	var manifest struct {
+		ArtifactType string              `json:"artifactType"`
+		Config       ocispec.Descriptor  `json:"config"`
+		Subject      *ocispec.Descriptor `json:"subject"`
+		Annotations  map[string]string   `json:"annotations"`
+	}
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return ocispec.Descriptor{}, false, fmt.Errorf("failed to decode manifest: %s: %s: %w", node.Digest, node.MediaType, err)
+	}
+	if manifest.Subject == nil || !content.Equal(*manifest.Subject, subject) {
+		return ocispec.Descriptor{}, false, nil
+	}
+	referrer := descriptor.Plain(node)
+	referrer.ArtifactType = manifest.ArtifactType
+	if referrer.ArtifactType == "" && node.MediaType == ocispec.MediaTypeImageManifest {
+		referrer.ArtifactType = manifest.Config.MediaType
+	}
+	referrer.Annotations = manifest.Annotations
+	return referrer, true, nil
+}
@@ -0,0 +1,188 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+)
+
+// plainStorage is a storage without any optional capability.
+type plainStorage struct {
+	content.Storage
+}
+
+func (s *plainStorage) Resolve(context.Context, string) (ocispec.Descriptor, error) {
+	return ocispec.Descriptor{}, errdef.ErrNotFound
+}
+
+func TestListTags(t *testing.T) {
+	ctx := context.Background()
+	err := registry.ListTags(ctx, &plainStorage{memory.New()}, "", func([]string) error { return nil })
+	if !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("ListTags() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+
+	s, err := oci.New(t.TempDir())
+	if err != nil {
+		t.Fatal("oci.New() error =", err)
+	}
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, []byte(`{"layers":[]}`))
+	if err := s.Push(ctx, desc, bytes.NewReader([]byte(`{"layers":[]}`))); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	for _, tag := range []string{"v1", "v2"} {
+		if err := s.Tag(ctx, desc, tag); err != nil {
+			t.Fatal("Tag() error =", err)
+		}
+	}
+	var got []string
+	if err := registry.ListTags(ctx, s, "", func(tags []string) error {
+		got = append(got, tags...)
+		return nil
+	}); err != nil {
+		t.Fatal("ListTags() error =", err)
+	}
+	if want := []string{"v1", "v2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListTags() = %v, want %v", got, want)
+	}
+}
+
+func TestMount(t *testing.T) {
+	ctx := context.Background()
+	desc := content.NewDescriptorFromBytes("test", []byte("hello world"))
+	err := registry.Mount(ctx, memory.New(), desc, "foo", func() (io.ReadCloser, error) {
+		return nil, errors.New("should not be called")
+	})
+	if !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Mount() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+}
+
+func TestReferrers(t *testing.T) {
+	ctx := context.Background()
+
+	// no referrers capability
+	subject := content.NewDescriptorFromBytes("test", []byte("hello world"))
+	if _, err := registry.Referrers(ctx, &plainStorage{memory.New()}, subject, ""); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Referrers() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+
+	// referrers through predecessors
+	s := memory.New()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal("Push() error =", err)
+		}
+		return desc
+	}
+	config := push("test/config", []byte("{}"))
+	layer := push("test/layer", []byte("foo"))
+	subject = push(ocispec.MediaTypeImageManifest, mustMarshal(t, ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    []ocispec.Descriptor{layer},
+	}))
+	sig := push(ocispec.MediaTypeImageManifest, mustMarshal(t, ocispec.Manifest{
+		MediaType:   ocispec.MediaTypeImageManifest,
+		Config:      content.NewDescriptorFromBytes("application/vnd.test.signature", []byte("{}")),
+		Subject:     &subject,
+		Annotations: map[string]string{"foo": "bar"},
+	}))
+	sbom := push(ocispec.MediaTypeImageManifest, mustMarshal(t, ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    content.NewDescriptorFromBytes("application/vnd.test.sbom", []byte("{}")),
+		Subject:   &subject,
+	}))
+	// artifactType takes precedence over the config media type
+	attestation := push(ocispec.MediaTypeImageManifest, mustMarshal(t, ocispec.Manifest{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: "application/vnd.test.attestation",
+		Config:       content.NewDescriptorFromBytes("application/vnd.test.config", []byte("{}")),
+		Subject:      &subject,
+	}))
+	// an index pointing to the subject is a predecessor but not a referrer
+	push(ocispec.MediaTypeImageIndex, mustMarshal(t, ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{subject},
+	}))
+
+	got, err := registry.Referrers(ctx, s, subject, "")
+	if err != nil {
+		t.Fatal("Referrers() error =", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("Referrers() = %v, want 3 referrers", got)
+	}
+
+	got, err = registry.Referrers(ctx, s, subject, "application/vnd.test.sbom")
+	if err != nil {
+		t.Fatal("Referrers() error =", err)
+	}
+	sbom.ArtifactType = "application/vnd.test.sbom"
+	if want := []ocispec.Descriptor{sbom}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Referrers() = %v, want %v", got, want)
+	}
+
+	got, err = registry.Referrers(ctx, s, subject, "application/vnd.test.signature")
+	if err != nil {
+		t.Fatal("Referrers() error =", err)
+	}
+	sig.ArtifactType = "application/vnd.test.signature"
+	sig.Annotations = map[string]string{"foo": "bar"}
+	if want := []ocispec.Descriptor{sig}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Referrers() = %v, want %v", got, want)
+	}
+
+	got, err = registry.Referrers(ctx, s, subject, "application/vnd.test.attestation")
+	if err != nil {
+		t.Fatal("Referrers() error =", err)
+	}
+	attestation.ArtifactType = "application/vnd.test.attestation"
+	if want := []ocispec.Descriptor{attestation}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Referrers() = %v, want %v", got, want)
+	}
+
+	got, err = registry.Referrers(ctx, s, layer, "")
+	if err != nil {
+		t.Fatal("Referrers() error =", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Referrers() = %v, want no referrers", got)
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal("json.Marshal() error =", err)
+	}
+	return b
+}
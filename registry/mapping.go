@@ -0,0 +1,143 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"fmt"
+	"strings"
+
+	"oras.land/oras-go/v2/errdef"
+)
+
+// Mapping rewrites the repositories of references, such as mapping
+// `docker.io/library/*` to `registry.corp/mirror/*`, for mirroring content
+// between registries.
+//
+// A pattern is either a full repository name like `docker.io/library/alpine`,
+// or a repository prefix followed by `/*`, which matches all the repositories
+// under the prefix. If the source pattern ends with a wildcard, the
+// destination pattern ends with a wildcard as well, which is substituted by
+// the matched remainder of the repository name.
+//
+// Rules are matched in the order of being added, and the first matching rule
+// wins.
+type Mapping struct {
+	rules []mappingRule
+}
+
+// mappingRule is a rule of Mapping.
+type mappingRule struct {
+	source      string
+	destination string
+	wildcard    bool
+}
+
+// NewMapping creates a Mapping from rules in the form of
+// `source=destination`, e.g. `docker.io/library/*=registry.corp/mirror/*`.
+func NewMapping(rules ...string) (*Mapping, error) {
+	m := &Mapping{}
+	for _, rule := range rules {
+		source, destination, ok := strings.Cut(rule, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid mapping rule %q: missing destination", rule)
+		}
+		if err := m.Add(strings.TrimSpace(source), strings.TrimSpace(destination)); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Add adds a rule mapping the source pattern to the destination pattern.
+func (m *Mapping) Add(source, destination string) error {
+	srcPrefix, srcWildcard := cutWildcard(source)
+	dstPrefix, dstWildcard := cutWildcard(destination)
+	if srcWildcard != dstWildcard {
+		return fmt.Errorf("invalid mapping rule %q=%q: mismatched wildcards", source, destination)
+	}
+	for _, pattern := range []string{srcPrefix, dstPrefix} {
+		if err := validatePattern(pattern, srcWildcard); err != nil {
+			return fmt.Errorf("invalid mapping rule %q=%q: %w", source, destination, err)
+		}
+	}
+	m.rules = append(m.rules, mappingRule{
+		source:      srcPrefix,
+		destination: dstPrefix,
+		wildcard:    srcWildcard,
+	})
+	return nil
+}
+
+// cutWildcard returns pattern without the trailing wildcard, and reports
+// whether the wildcard is found.
+func cutWildcard(pattern string) (string, bool) {
+	if strings.HasSuffix(pattern, "/*") {
+		return strings.TrimSuffix(pattern, "/*"), true
+	}
+	return pattern, false
+}
+
+// validatePattern validates a pattern without the trailing wildcard.
+// A wildcard pattern may have only the registry part.
+func validatePattern(pattern string, wildcard bool) error {
+	if strings.Contains(pattern, "*") {
+		return fmt.Errorf("%w: wildcards are only allowed at the end", errdef.ErrInvalidReference)
+	}
+	registry, repository, _ := strings.Cut(pattern, "/")
+	ref := Reference{
+		Registry:   registry,
+		Repository: repository,
+	}
+	if err := ref.ValidateRegistry(); err != nil {
+		return err
+	}
+	if repository == "" && wildcard {
+		return nil
+	}
+	return ref.ValidateRepository()
+}
+
+// Map maps the repository of ref by the first matching rule. The tag or the
+// digest of ref is kept.
+// Returns ErrNotFound if no rule matches.
+func (m *Mapping) Map(ref Reference) (Reference, error) {
+	name := ref.Registry + "/" + ref.Repository
+	for _, rule := range m.rules {
+		var mapped string
+		switch {
+		case !rule.wildcard:
+			if name != rule.source {
+				continue
+			}
+			mapped = rule.destination
+		case strings.HasPrefix(name, rule.source+"/"):
+			mapped = rule.destination + strings.TrimPrefix(name, rule.source)
+		default:
+			continue
+		}
+		registry, repository, _ := strings.Cut(mapped, "/")
+		mappedRef := Reference{
+			Registry:   registry,
+			Repository: repository,
+			Reference:  ref.Reference,
+		}
+		if err := mappedRef.ValidateRepository(); err != nil {
+			return Reference{}, fmt.Errorf("failed to map %s to %s: %w", name, mapped, err)
+		}
+		return mappedRef, nil
+	}
+	return Reference{}, fmt.Errorf("%s: no mapping rule: %w", name, errdef.ErrNotFound)
+}
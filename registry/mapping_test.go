@@ -0,0 +1,109 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"errors"
+	"testing"
+
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestMapping_Map(t *testing.T) {
+	m, err := NewMapping(
+		"docker.io/library/alpine=registry.corp/base/alpine",
+		"docker.io/library/* = registry.corp/mirror/*",
+		"ghcr.io/*=registry.corp/ghcr/*",
+	)
+	if err != nil {
+		t.Fatal("NewMapping() error =", err)
+	}
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "exact match",
+			ref:  "docker.io/library/alpine:3.18",
+			want: "registry.corp/base/alpine:3.18",
+		},
+		{
+			name: "prefix match",
+			ref:  "docker.io/library/busybox:latest",
+			want: "registry.corp/mirror/busybox:latest",
+		},
+		{
+			name: "nested prefix match with digest",
+			ref:  "docker.io/library/foo/bar@sha256:b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9",
+			want: "registry.corp/mirror/foo/bar@sha256:b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9",
+		},
+		{
+			name: "registry match",
+			ref:  "ghcr.io/oras-project/oras",
+			want: "registry.corp/ghcr/oras-project/oras",
+		},
+		{
+			name:    "partial name",
+			ref:     "docker.io/library-extra/foo:v1",
+			wantErr: errdef.ErrNotFound,
+		},
+		{
+			name:    "no match",
+			ref:     "quay.io/foo/bar:v1",
+			wantErr: errdef.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := ParseReference(tt.ref)
+			if err != nil {
+				t.Fatal("ParseReference() error =", err)
+			}
+			got, err := m.Map(ref)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Mapping.Map() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got.String() != tt.want {
+				t.Errorf("Mapping.Map() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMapping_Invalid(t *testing.T) {
+	tests := []string{
+		"docker.io/library/*",
+		"docker.io/library/*=registry.corp/mirror",
+		"docker.io/library=registry.corp/mirror/*",
+		"docker.io/*/foo=registry.corp/mirror/foo",
+		"docker.io/Library/*=registry.corp/mirror/*",
+		"docker.io/library/*=registry corp/mirror/*",
+		"docker.io=registry.corp",
+	}
+	for _, rule := range tests {
+		t.Run(rule, func(t *testing.T) {
+			if _, err := NewMapping(rule); err == nil {
+				t.Errorf("NewMapping(%q) error = nil, wantErr true", rule)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package matcher provides matchers of references by glob or regular
+// expression patterns over their registry, repository and tag components,
+// for expressing declaratively which references are mirrored or synced, such
+// as "the tags v* of the repositories under ghcr.io/org".
+package matcher
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"oras.land/oras-go/v2/registry"
+)
+
+// Matcher matches references.
+type Matcher interface {
+	// Match returns true if the reference matches.
+	Match(ref registry.Reference) bool
+}
+
+// MatcherFunc is an adapter to allow the use of ordinary functions as
+// Matchers.
+type MatcherFunc func(ref registry.Reference) bool
+
+// Match returns f(ref).
+func (f MatcherFunc) Match(ref registry.Reference) bool {
+	return f(ref)
+}
+
+// Pattern matches references by the patterns of their registry, repository
+// and tag components. An absent component pattern matches anything.
+//
+// The tag pattern is matched only against tags: references with a digest or
+// without a reference match if the registry and the repository match, since
+// the content addressed by digests cannot be told apart by tags.
+type Pattern struct {
+	registry   *regexp.Regexp
+	repository *regexp.Regexp
+	tag        *regexp.Regexp
+	source     string
+}
+
+// Glob parses a glob pattern in the form of `<registry>[/<repository>][:<tag>]`,
+// such as `ghcr.io/org/*:v*`. In the patterns,
+//   - `*` matches any sequence of characters except `/`.
+//   - `**` matches any sequence of characters, including `/`.
+//   - `?` matches any single character except `/`.
+//
+// For instance, `ghcr.io/org/*` matches `ghcr.io/org/app` but not
+// `ghcr.io/org/team/app`, while `ghcr.io/org/**` matches both.
+// The pattern `ghcr.io` matches all the repositories in ghcr.io.
+func Glob(pattern string) (*Pattern, error) {
+	registryPattern, remainder, _ := strings.Cut(pattern, "/")
+	repositoryPattern, tagPattern := remainder, ""
+	if index := strings.LastIndex(remainder, ":"); index != -1 && !strings.Contains(remainder[index:], "/") {
+		repositoryPattern, tagPattern = remainder[:index], remainder[index+1:]
+		if tagPattern == "" {
+			return nil, fmt.Errorf("invalid pattern %q: empty tag pattern", pattern)
+		}
+	}
+	if registryPattern == "" {
+		return nil, fmt.Errorf("invalid pattern %q: empty registry pattern", pattern)
+	}
+	if strings.Contains(remainder, "@") {
+		return nil, fmt.Errorf("invalid pattern %q: digests are not supported", pattern)
+	}
+	p, err := GlobComponents(registryPattern, repositoryPattern, tagPattern)
+	if err != nil {
+		return nil, err
+	}
+	p.source = pattern
+	return p, nil
+}
+
+// GlobComponents returns a Pattern matching the registry, the repository and
+// the tag by the respective glob patterns in the syntax of Glob. Empty
+// patterns match anything.
+func GlobComponents(registryPattern, repositoryPattern, tagPattern string) (*Pattern, error) {
+	p := &Pattern{}
+	for _, c := range []struct {
+		pattern string
+		re      **regexp.Regexp
+	}{
+		{registryPattern, &p.registry},
+		{repositoryPattern, &p.repository},
+		{tagPattern, &p.tag},
+	} {
+		if c.pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(globToRegexp(c.pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q: %w", c.pattern, err)
+		}
+		*c.re = re
+	}
+	p.source = joinComponents(registryPattern, repositoryPattern, tagPattern)
+	return p, nil
+}
+
+// Regexp returns a Pattern matching the registry, the repository and the tag
+// by the respective regular expressions in the syntax of the regexp package.
+// The expressions are anchored to match the whole components. Empty
+// expressions match anything.
+func Regexp(registryExpr, repositoryExpr, tagExpr string) (*Pattern, error) {
+	p := &Pattern{}
+	for _, c := range []struct {
+		expr string
+		re   **regexp.Regexp
+	}{
+		{registryExpr, &p.registry},
+		{repositoryExpr, &p.repository},
+		{tagExpr, &p.tag},
+	} {
+		if c.expr == "" {
+			continue
+		}
+		re, err := regexp.Compile("^(?:" + c.expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", c.expr, err)
+		}
+		*c.re = re
+	}
+	p.source = joinComponents(registryExpr, repositoryExpr, tagExpr)
+	return p, nil
+}
+
+// Match returns true if the reference matches the pattern.
+func (p *Pattern) Match(ref registry.Reference) bool {
+	if p.registry != nil && !p.registry.MatchString(ref.Registry) {
+		return false
+	}
+	if p.repository != nil && !p.repository.MatchString(ref.Repository) {
+		return false
+	}
+	if p.tag != nil && ref.Reference != "" {
+		if _, err := ref.Digest(); err != nil {
+			return p.tag.MatchString(ref.Reference)
+		}
+	}
+	return true
+}
+
+// String returns the pattern.
+func (p *Pattern) String() string {
+	return p.source
+}
+
+// Any returns a Matcher matching the references matched by any of the
+// matchers. It matches nothing if no matcher is given.
+func Any(matchers ...Matcher) Matcher {
+	return MatcherFunc(func(ref registry.Reference) bool {
+		for _, m := range matchers {
+			if m.Match(ref) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// All returns a Matcher matching the references matched by all the
+// matchers. It matches everything if no matcher is given.
+func All(matchers ...Matcher) Matcher {
+	return MatcherFunc(func(ref registry.Reference) bool {
+		for _, m := range matchers {
+			if !m.Match(ref) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Not returns a Matcher matching the references not matched by m.
+func Not(m Matcher) Matcher {
+	return MatcherFunc(func(ref registry.Reference) bool {
+		return !m.Match(ref)
+	})
+}
+
+// globToRegexp converts a glob pattern to an anchored regular expression.
+func globToRegexp(pattern string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				sb.WriteString(".*")
+				i++
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return sb.String()
+}
+
+// joinComponents joins the component patterns for display.
+func joinComponents(registryPattern, repositoryPattern, tagPattern string) string {
+	s := registryPattern
+	if repositoryPattern != "" {
+		s += "/" + repositoryPattern
+	}
+	if tagPattern != "" {
+		s += ":" + tagPattern
+	}
+	return s
+}
@@ -0,0 +1,132 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package matcher
+
+import (
+	"testing"
+
+	"oras.land/oras-go/v2/registry"
+)
+
+const testDigest = "sha256:b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+
+func mustParse(t *testing.T, s string) registry.Reference {
+	t.Helper()
+	ref, err := registry.ParseReference(s)
+	if err != nil {
+		t.Fatalf("ParseReference(%q) error = %v", s, err)
+	}
+	return ref
+}
+
+func TestGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		ref     string
+		want    bool
+	}{
+		{"ghcr.io/org/*:v*", "ghcr.io/org/app:v1", true},
+		{"ghcr.io/org/*:v*", "ghcr.io/org/app:latest", false},
+		{"ghcr.io/org/*:v*", "ghcr.io/org/app@" + testDigest, true},
+		{"ghcr.io/org/*:v*", "ghcr.io/org/app", true},
+		{"ghcr.io/org/*:v*", "ghcr.io/org/team/app:v1", false},
+		{"ghcr.io/org/**", "ghcr.io/org/team/app:v1", true},
+		{"ghcr.io/org/**", "ghcr.io/other/app:v1", false},
+		{"ghcr.io", "ghcr.io/any/repo:tag", true},
+		{"*.example.com/app", "registry.example.com/app", true},
+		{"*.example.com/app", "example.com/app", false},
+		{"localhost:5000/app?", "localhost:5000/app1", true},
+		{"localhost:5000/app?", "localhost:5000/app12", false},
+		{"localhost:5000/a.p", "localhost:5000/axp", false},
+		{"localhost:*/app:1.*", "localhost:5000/app:1.2", true},
+		{"localhost:*/app:1.*", "localhost:5000/app:12", false},
+	}
+	for _, tt := range tests {
+		p, err := Glob(tt.pattern)
+		if err != nil {
+			t.Fatalf("Glob(%q) error = %v", tt.pattern, err)
+		}
+		if got := p.Match(mustParse(t, tt.ref)); got != tt.want {
+			t.Errorf("Glob(%q).Match(%q) = %v, want %v", tt.pattern, tt.ref, got, tt.want)
+		}
+		if got := p.String(); got != tt.pattern {
+			t.Errorf("Glob(%q).String() = %q", tt.pattern, got)
+		}
+	}
+
+	for _, pattern := range []string{"", "/repo", "ghcr.io/repo:", "ghcr.io/repo@" + testDigest} {
+		if _, err := Glob(pattern); err == nil {
+			t.Errorf("Glob(%q) error = nil, want error", pattern)
+		}
+	}
+}
+
+func TestRegexp(t *testing.T) {
+	p, err := Regexp(`ghcr\.io`, `org/(app|web)`, `v[0-9]+(\.[0-9]+)*`)
+	if err != nil {
+		t.Fatalf("Regexp() error = %v", err)
+	}
+	tests := []struct {
+		ref  string
+		want bool
+	}{
+		{"ghcr.io/org/app:v1.2", true},
+		{"ghcr.io/org/web:v2", true},
+		{"ghcr.io/org/app:v1-rc", false},
+		{"ghcr.io/org/apps:v1", false},
+		{"ghcr.io/org/app@" + testDigest, true},
+		{"ghcrxio/org/app:v1", false},
+	}
+	for _, tt := range tests {
+		if got := p.Match(mustParse(t, tt.ref)); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.ref, got, tt.want)
+		}
+	}
+
+	if _, err := Regexp("(", "", ""); err == nil {
+		t.Error("Regexp() error = nil, want error")
+	}
+}
+
+func TestCombinators(t *testing.T) {
+	org, err := Glob("ghcr.io/org/**")
+	if err != nil {
+		t.Fatalf("Glob() error = %v", err)
+	}
+	release, err := GlobComponents("", "", "v*")
+	if err != nil {
+		t.Fatalf("GlobComponents() error = %v", err)
+	}
+	ref := mustParse(t, "ghcr.io/org/app:v1")
+	dev := mustParse(t, "ghcr.io/org/app:dev")
+	other := mustParse(t, "docker.io/library/alpine:v1")
+
+	if m := All(org, release); !m.Match(ref) || m.Match(dev) || m.Match(other) {
+		t.Error("All() matches unexpectedly")
+	}
+	if m := Any(org, release); !m.Match(ref) || !m.Match(dev) || !m.Match(other) {
+		t.Error("Any() matches unexpectedly")
+	}
+	if m := Not(org); m.Match(ref) || !m.Match(other) {
+		t.Error("Not() matches unexpectedly")
+	}
+	if Any().Match(ref) {
+		t.Error("Any() without matchers matches")
+	}
+	if !All().Match(ref) {
+		t.Error("All() without matchers does not match")
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"oras.land/oras-go/v2/errdef"
+)
+
+const (
+	// dockerHubRegistry is the canonical registry name of Docker Hub.
+	dockerHubRegistry = "docker.io"
+
+	// dockerHubOfficialRepositoryPrefix is the namespace of the official
+	// images on Docker Hub.
+	dockerHubOfficialRepositoryPrefix = "library/"
+
+	// maxRepositoryLength is the maximum length of repository names.
+	// Reference: https://github.com/distribution/distribution/blob/v2.7.1/reference/reference.go#L37
+	maxRepositoryLength = 255
+)
+
+// dockerHubAliases are the alternative names of Docker Hub.
+var dockerHubAliases = map[string]bool{
+	"index.docker.io":      true,
+	"registry-1.docker.io": true,
+}
+
+// domainRegexp matches domain names and IPv4 addresses.
+var domainRegexp = regexp.MustCompile(`^(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*$`)
+
+// ParseOptions contains parameters for ParseReferenceWithOptions.
+type ParseOptions struct {
+	// ExpandDockerHub expands the Docker Hub shorthand references as the
+	// docker CLI does. For instance, `alpine` is expanded to
+	// `docker.io/library/alpine` and `user/app:v1` to `docker.io/user/app:v1`.
+	// A reference is a shorthand if it has no registry, that is, if its first
+	// path component is not `localhost` and contains no `.` or `:`.
+	ExpandDockerHub bool
+
+	// Strict enables the strict validation, in which the following
+	// references accepted by ParseReference are rejected:
+	//   - References with both a tag and a digest (Valid Form B).
+	//   - Registries that are not a valid domain name, IPv4 address or
+	//     bracketed IPv6 address, optionally with a port in 1-65535.
+	//   - Repositories longer than 255 characters.
+	Strict bool
+
+	// Normalize normalizes the parsed reference by Reference.Normalize.
+	Normalize bool
+}
+
+// ParseReferenceWithOptions parses a string (artifact) into an
+// `artifact reference` as ParseReference does, with the behaviors configured
+// by opts.
+func ParseReferenceWithOptions(artifact string, opts ParseOptions) (Reference, error) {
+	if opts.ExpandDockerHub {
+		artifact = expandDockerHub(artifact)
+	}
+	if opts.Strict {
+		if err := validateStrict(artifact); err != nil {
+			return Reference{}, err
+		}
+	}
+	ref, err := ParseReference(artifact)
+	if err != nil {
+		return Reference{}, err
+	}
+	if opts.Normalize {
+		ref = ref.Normalize()
+	}
+	return ref, nil
+}
+
+// Normalize returns the canonical form of the reference, so that the
+// different spellings of the same reference compare equal:
+//   - The registry name is lowercased.
+//   - The default HTTPS port 443 is removed from the registry.
+//   - The aliases of Docker Hub, such as `index.docker.io`, are replaced by
+//     `docker.io`.
+//   - The official images on Docker Hub are prefixed by `library/`.
+func (r Reference) Normalize() Reference {
+	registry := strings.ToLower(r.Registry)
+	if host, port, err := net.SplitHostPort(registry); err == nil && port == "443" {
+		if strings.Contains(host, ":") {
+			// IPv6 addresses must be bracketed
+			registry = "[" + host + "]"
+		} else {
+			registry = host
+		}
+	}
+	if dockerHubAliases[registry] {
+		registry = dockerHubRegistry
+	}
+	repository := r.Repository
+	if registry == dockerHubRegistry && repository != "" && !strings.Contains(repository, "/") {
+		repository = dockerHubOfficialRepositoryPrefix + repository
+	}
+	return Reference{
+		Registry:   registry,
+		Repository: repository,
+		Reference:  r.Reference,
+	}
+}
+
+// Equal returns true if the two references are the same in their normalized
+// forms.
+func (r Reference) Equal(other Reference) bool {
+	return r.Normalize() == other.Normalize()
+}
+
+// expandDockerHub expands the artifact as a Docker Hub reference if it has no
+// registry.
+// Reference: https://github.com/distribution/distribution/blob/v2.7.1/reference/normalize.go#L88-L105
+func expandDockerHub(artifact string) string {
+	first, remainder, ok := strings.Cut(artifact, "/")
+	if ok && (first == "localhost" || strings.ContainsAny(first, ".:") || strings.ToLower(first) != first) {
+		return artifact
+	}
+	if !ok {
+		return dockerHubRegistry + "/" + dockerHubOfficialRepositoryPrefix + artifact
+	}
+	return dockerHubRegistry + "/" + first + "/" + remainder
+}
+
+// validateStrict performs the checks of the strict validation not covered by
+// ParseReference.
+func validateStrict(artifact string) error {
+	registry, path, ok := strings.Cut(artifact, "/")
+	if !ok {
+		return fmt.Errorf("%w: missing repository", errdef.ErrInvalidReference)
+	}
+	if err := validateHost(registry); err != nil {
+		return err
+	}
+	repository := path
+	if index := strings.Index(path, "@"); index != -1 {
+		repository = path[:index]
+		if strings.Contains(repository, ":") {
+			return fmt.Errorf("%w: both tag and digest are specified", errdef.ErrInvalidReference)
+		}
+	} else if index := strings.Index(path, ":"); index != -1 {
+		repository = path[:index]
+	}
+	if len(repository) > maxRepositoryLength {
+		return fmt.Errorf("%w: repository name longer than %d characters", errdef.ErrInvalidReference, maxRepositoryLength)
+	}
+	return nil
+}
+
+// validateHost validates the registry as a domain name, an IPv4 address or a
+// bracketed IPv6 address, optionally with a port.
+func validateHost(registry string) error {
+	host := registry
+	if strings.HasPrefix(registry, "[") || strings.Count(registry, ":") == 1 {
+		var port string
+		var err error
+		host, port, err = net.SplitHostPort(registry)
+		if err != nil {
+			if !strings.HasPrefix(registry, "[") || !strings.HasSuffix(registry, "]") {
+				return fmt.Errorf("%w: invalid registry: %v", errdef.ErrInvalidReference, err)
+			}
+			// bracketed IPv6 address without port
+			host, port = registry[1:len(registry)-1], ""
+		}
+		if port != "" {
+			if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+				return fmt.Errorf("%w: invalid registry port %q", errdef.ErrInvalidReference, port)
+			}
+		}
+		if strings.HasPrefix(registry, "[") {
+			if ip := net.ParseIP(host); ip == nil || ip.To4() != nil {
+				return fmt.Errorf("%w: invalid registry IPv6 address %q", errdef.ErrInvalidReference, host)
+			}
+			return nil
+		}
+	}
+	if !domainRegexp.MatchString(host) {
+		return fmt.Errorf("%w: invalid registry host %q", errdef.ErrInvalidReference, host)
+	}
+	return nil
+}
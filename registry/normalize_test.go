@@ -0,0 +1,208 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestParseReferenceWithOptions_ExpandDockerHub(t *testing.T) {
+	tests := []struct {
+		artifact string
+		want     Reference
+	}{
+		{
+			artifact: "alpine",
+			want:     Reference{Registry: "docker.io", Repository: "library/alpine"},
+		},
+		{
+			artifact: "alpine:3.18",
+			want:     Reference{Registry: "docker.io", Repository: "library/alpine", Reference: "3.18"},
+		},
+		{
+			artifact: "alpine@" + ValidDigest,
+			want:     Reference{Registry: "docker.io", Repository: "library/alpine", Reference: ValidDigest},
+		},
+		{
+			artifact: "user/app:v1",
+			want:     Reference{Registry: "docker.io", Repository: "user/app", Reference: "v1"},
+		},
+		{
+			artifact: "localhost/app",
+			want:     Reference{Registry: "localhost", Repository: "app"},
+		},
+		{
+			artifact: "localhost:5000/app",
+			want:     Reference{Registry: "localhost:5000", Repository: "app"},
+		},
+		{
+			artifact: "ghcr.io/oras-project/oras",
+			want:     Reference{Registry: "ghcr.io", Repository: "oras-project/oras"},
+		},
+		{
+			artifact: "[::1]:5000/app:v1",
+			want:     Reference{Registry: "[::1]:5000", Repository: "app", Reference: "v1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.artifact, func(t *testing.T) {
+			got, err := ParseReferenceWithOptions(tt.artifact, ParseOptions{ExpandDockerHub: true})
+			if err != nil {
+				t.Fatalf("ParseReferenceWithOptions() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseReferenceWithOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// shorthand references are rejected without the option
+	if _, err := ParseReferenceWithOptions("alpine", ParseOptions{}); !errors.Is(err, errdef.ErrInvalidReference) {
+		t.Errorf("ParseReferenceWithOptions() error = %v, want %v", err, errdef.ErrInvalidReference)
+	}
+}
+
+func TestParseReferenceWithOptions_Strict(t *testing.T) {
+	valid := []string{
+		"localhost/hello",
+		"localhost:5000/hello:v1",
+		"registry.example.com/hello@" + ValidDigest,
+		"127.0.0.1:5000/hello",
+		"[::1]/hello",
+		"[::1]:5000/hello:v1",
+		"[2001:db8::1]:443/hello",
+	}
+	for _, artifact := range valid {
+		if _, err := ParseReferenceWithOptions(artifact, ParseOptions{Strict: true}); err != nil {
+			t.Errorf("ParseReferenceWithOptions(%q) error = %v", artifact, err)
+		}
+	}
+
+	invalid := []string{
+		"localhost/hello:v1@" + ValidDigest,
+		"localhost:0/hello",
+		"localhost:70000/hello",
+		"[127.0.0.1]:5000/hello",
+		"[::1/hello",
+		"-registry.example.com/hello",
+		"registry..example.com/hello",
+		"localhost/" + strings.Repeat("a", 256),
+	}
+	for _, artifact := range invalid {
+		if _, err := ParseReferenceWithOptions(artifact, ParseOptions{Strict: true}); !errors.Is(err, errdef.ErrInvalidReference) {
+			t.Errorf("ParseReferenceWithOptions(%q) error = %v, want %v", artifact, err, errdef.ErrInvalidReference)
+		}
+	}
+
+	// the tag of Valid Form B is dropped in non-strict mode
+	got, err := ParseReferenceWithOptions("localhost/hello:v1@"+ValidDigest, ParseOptions{})
+	if err != nil {
+		t.Fatalf("ParseReferenceWithOptions() error = %v", err)
+	}
+	if got.Reference != ValidDigest {
+		t.Errorf("ParseReferenceWithOptions() reference = %v, want %v", got.Reference, ValidDigest)
+	}
+}
+
+func TestReference_Normalize(t *testing.T) {
+	tests := []struct {
+		name string
+		ref  Reference
+		want Reference
+	}{
+		{
+			name: "lowercase registry",
+			ref:  Reference{Registry: "Registry.Example.COM", Repository: "hello", Reference: "V1"},
+			want: Reference{Registry: "registry.example.com", Repository: "hello", Reference: "V1"},
+		},
+		{
+			name: "default port",
+			ref:  Reference{Registry: "registry.example.com:443", Repository: "hello"},
+			want: Reference{Registry: "registry.example.com", Repository: "hello"},
+		},
+		{
+			name: "non-default port",
+			ref:  Reference{Registry: "registry.example.com:5000", Repository: "hello"},
+			want: Reference{Registry: "registry.example.com:5000", Repository: "hello"},
+		},
+		{
+			name: "IPv6 default port",
+			ref:  Reference{Registry: "[::1]:443", Repository: "hello"},
+			want: Reference{Registry: "[::1]", Repository: "hello"},
+		},
+		{
+			name: "Docker Hub alias",
+			ref:  Reference{Registry: "index.docker.io", Repository: "user/app"},
+			want: Reference{Registry: "docker.io", Repository: "user/app"},
+		},
+		{
+			name: "Docker Hub official image",
+			ref:  Reference{Registry: "registry-1.docker.io", Repository: "alpine", Reference: "3.18"},
+			want: Reference{Registry: "docker.io", Repository: "library/alpine", Reference: "3.18"},
+		},
+		{
+			name: "registry only",
+			ref:  Reference{Registry: "docker.io"},
+			want: Reference{Registry: "docker.io"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ref.Normalize(); got != tt.want {
+				t.Errorf("Reference.Normalize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReference_Equal(t *testing.T) {
+	equal := [][2]string{
+		{"docker.io/alpine:3.18", "docker.io/library/alpine:3.18"},
+		{"index.docker.io/library/alpine", "registry-1.docker.io/library/alpine"},
+		{"Registry.Example.com:443/hello", "registry.example.com/hello"},
+	}
+	for _, pair := range equal {
+		a, err := ParseReference(pair[0])
+		if err != nil {
+			t.Fatalf("ParseReference(%q) error = %v", pair[0], err)
+		}
+		b, err := ParseReference(pair[1])
+		if err != nil {
+			t.Fatalf("ParseReference(%q) error = %v", pair[1], err)
+		}
+		if !a.Equal(b) {
+			t.Errorf("Reference.Equal(%q, %q) = false, want true", pair[0], pair[1])
+		}
+	}
+
+	a, _ := ParseReference("registry.example.com/hello:v1")
+	b, _ := ParseReference("registry.example.com/hello:V1")
+	if a.Equal(b) {
+		t.Error("Reference.Equal() = true for different tags, want false")
+	}
+
+	got, err := ParseReferenceWithOptions("alpine:3.18", ParseOptions{ExpandDockerHub: true, Normalize: true})
+	if err != nil {
+		t.Fatalf("ParseReferenceWithOptions() error = %v", err)
+	}
+	if want := "docker.io/library/alpine:3.18"; got.String() != want {
+		t.Errorf("ParseReferenceWithOptions() = %v, want %v", got, want)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	"github.com/opencontainers/go-digest"
+	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/errdef"
 )
 
@@ -248,8 +249,10 @@ func (r Reference) ReferenceOrDefault() string {
 }
 
 // Digest returns the reference as a digest.
+// Digests of the algorithms registered by content.RegisterAlgorithm are
+// accepted.
 func (r Reference) Digest() (digest.Digest, error) {
-	return digest.Parse(r.Reference)
+	return content.ParseDigest(r.Reference)
 }
 
 // String implements `fmt.Stringer` and returns the reference string.
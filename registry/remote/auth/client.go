@@ -25,10 +25,16 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
+	"oras.land/oras-go/v2/logging"
+	"oras.land/oras-go/v2/metrics"
+	"oras.land/oras-go/v2/override"
 	"oras.land/oras-go/v2/registry/remote/internal/errutil"
 	"oras.land/oras-go/v2/registry/remote/retry"
+	"oras.land/oras-go/v2/tracing"
 )
 
 // DefaultClient is the default auth-decorated client.
@@ -121,10 +127,36 @@ func (c *Client) client() *http.Client {
 }
 
 // send adds headers to the request and sends the request to the remote server.
-func (c *Client) send(req *http.Request) (*http.Response, error) {
+func (c *Client) send(req *http.Request) (resp *http.Response, err error) {
 	for key, values := range c.Header {
 		req.Header[key] = append(req.Header[key], values...)
 	}
+	if userAgent := override.FromContext(req.Context()).UserAgent; userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+
+	ctx, span := tracing.Start(req.Context(), "HTTP "+req.Method,
+		tracing.String(tracing.AttributeKeyHTTPMethod, req.Method),
+		tracing.String(tracing.AttributeKeyHTTPURL, req.URL.Redacted()))
+	defer func() {
+		if resp != nil {
+			span.SetAttributes(tracing.Int64(tracing.AttributeKeyHTTPStatusCode, int64(resp.StatusCode)))
+		}
+		span.End(err)
+	}()
+	if ctx != req.Context() {
+		req = req.WithContext(ctx)
+	}
+	start := time.Now()
+	defer func() {
+		code := "error"
+		if resp != nil {
+			code = strconv.Itoa(resp.StatusCode)
+		}
+		metrics.ObserveHistogram(ctx, metrics.RequestDuration, time.Since(start).Seconds(),
+			metrics.Label{Name: "method", Value: req.Method},
+			metrics.Label{Name: "code", Value: code})
+	}()
 	return c.client().Do(req)
 }
 
@@ -145,6 +177,16 @@ func (c *Client) cache() Cache {
 	return c.Cache
 }
 
+// contextScopes returns the scope hints in the context, including the scopes
+// of the option overrides.
+func contextScopes(ctx context.Context) []string {
+	scopes := GetScopes(ctx)
+	if overridden := override.FromContext(ctx).Scopes; len(overridden) > 0 {
+		scopes = CleanScopes(append(scopes, overridden...))
+	}
+	return scopes
+}
+
 // SetUserAgent sets the user agent for all out-going requests.
 func (c *Client) SetUserAgent(userAgent string) {
 	if c.Header == nil {
@@ -159,6 +201,10 @@ func (c *Client) SetUserAgent(userAgent string) {
 // On authentication failure due to bad credential,
 //   - Do returns error if it fails to fetch token for bearer auth.
 //   - Do returns the registry response without error for basic auth.
+//
+// If the registry responds 403 Forbidden with an insufficient_scope bearer
+// challenge, such as pushing with a token obtained for pulling, Do acquires
+// a token with the challenged scopes and retries the request once.
 func (c *Client) Do(originalReq *http.Request) (*http.Response, error) {
 	if auth := originalReq.Header.Get("Authorization"); auth != "" {
 		return c.send(originalReq)
@@ -169,6 +215,7 @@ func (c *Client) Do(originalReq *http.Request) (*http.Response, error) {
 
 	// attempt cached auth token
 	var attemptedKey string
+	var attemptedCache bool
 	cache := c.cache()
 	registry := originalReq.Host
 	scheme, err := cache.GetScheme(ctx, registry)
@@ -178,13 +225,15 @@ func (c *Client) Do(originalReq *http.Request) (*http.Response, error) {
 			token, err := cache.GetToken(ctx, registry, SchemeBasic, "")
 			if err == nil {
 				req.Header.Set("Authorization", "Basic "+token)
+				attemptedCache = true
 			}
 		case SchemeBearer:
-			scopes := GetScopes(ctx)
+			scopes := contextScopes(ctx)
 			attemptedKey = strings.Join(scopes, " ")
 			token, err := cache.GetToken(ctx, registry, SchemeBearer, attemptedKey)
 			if err == nil {
 				req.Header.Set("Authorization", "Bearer "+token)
+				attemptedCache = true
 			}
 		}
 	}
@@ -193,13 +242,17 @@ func (c *Client) Do(originalReq *http.Request) (*http.Response, error) {
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode != http.StatusUnauthorized {
+	if resp.StatusCode != http.StatusUnauthorized && !isInsufficientScope(resp) {
+		if attemptedCache {
+			metrics.AddCounter(ctx, metrics.CacheHits, 1, metrics.Label{Name: "cache", Value: "auth"})
+		}
 		return resp, nil
 	}
 
 	// attempt again with credentials for recognized schemes
 	challenge := resp.Header.Get("Www-Authenticate")
 	scheme, params := parseChallenge(challenge)
+	logging.FromContext(ctx).Debug("received auth challenge", "registry", registry, "scheme", scheme.String())
 	switch scheme {
 	case SchemeBasic:
 		resp.Body.Close()
@@ -217,7 +270,7 @@ func (c *Client) Do(originalReq *http.Request) (*http.Response, error) {
 		resp.Body.Close()
 
 		// merge hinted scopes with challenged scopes
-		scopes := GetScopes(ctx)
+		scopes := contextScopes(ctx)
 		if scope := params["scope"]; scope != "" {
 			scopes = append(scopes, strings.Split(scope, " ")...)
 			scopes = CleanScopes(scopes)
@@ -237,7 +290,8 @@ func (c *Client) Do(originalReq *http.Request) (*http.Response, error) {
 				if err != nil {
 					return nil, err
 				}
-				if resp.StatusCode != http.StatusUnauthorized {
+				if resp.StatusCode != http.StatusUnauthorized && !isInsufficientScope(resp) {
+					metrics.AddCounter(ctx, metrics.CacheHits, 1, metrics.Label{Name: "cache", Value: "auth"})
 					return resp, nil
 				}
 				resp.Body.Close()
@@ -266,6 +320,17 @@ func (c *Client) Do(originalReq *http.Request) (*http.Response, error) {
 	return c.send(req)
 }
 
+// isInsufficientScope reports whether the response rejects the request due to
+// the insufficient scope of the bearer token.
+// Reference: https://datatracker.ietf.org/doc/html/rfc6750#section-3.1
+func isInsufficientScope(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden {
+		return false
+	}
+	scheme, params := parseChallenge(resp.Header.Get("Www-Authenticate"))
+	return scheme == SchemeBearer && params["error"] == "insufficient_scope"
+}
+
 // fetchBasicAuth fetches a basic auth token for the basic challenge.
 func (c *Client) fetchBasicAuth(ctx context.Context, registry string) (string, error) {
 	cred, err := c.credential(ctx, registry)
@@ -283,7 +348,15 @@ func (c *Client) fetchBasicAuth(ctx context.Context, registry string) (string, e
 }
 
 // fetchBearerToken fetches an access token for the bearer challenge.
-func (c *Client) fetchBearerToken(ctx context.Context, registry, realm, service string, scopes []string) (string, error) {
+func (c *Client) fetchBearerToken(ctx context.Context, registry, realm, service string, scopes []string) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "auth.FetchToken",
+		tracing.String("auth.registry", registry),
+		tracing.String("auth.realm", realm),
+		tracing.String("auth.service", service),
+		tracing.String("auth.scopes", strings.Join(scopes, " ")))
+	defer func() { span.End(err) }()
+	logging.FromContext(ctx).Debug("fetching bearer token", "registry", registry, "realm", realm, "service", service, "scopes", scopes)
+
 	cred, err := c.credential(ctx, registry)
 	if err != nil {
 		return "", err
@@ -291,6 +364,7 @@ func (c *Client) fetchBearerToken(ctx context.Context, registry, realm, service
 	if cred.AccessToken != "" {
 		return cred.AccessToken, nil
 	}
+	metrics.AddCounter(ctx, metrics.AuthRoundTrips, 1, metrics.Label{Name: "registry", Value: registry})
 	if cred == EmptyCredential || (cred.RefreshToken == "" && !c.ForceAttemptOAuth2) {
 		return c.fetchDistributionToken(ctx, realm, service, scopes, cred.Username, cred.Password)
 	}
@@ -16,10 +16,12 @@ limitations under the License.
 package auth
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -28,7 +30,10 @@ import (
 	"sync/atomic"
 	"testing"
 
+	"oras.land/oras-go/v2/metrics"
+	"oras.land/oras-go/v2/override"
 	"oras.land/oras-go/v2/registry/remote/errcode"
+	"oras.land/oras-go/v2/tracing"
 )
 
 func TestClient_SetUserAgent(t *testing.T) {
@@ -1482,6 +1487,70 @@ func TestClient_Do_Token_Expire(t *testing.T) {
 	}
 }
 
+func TestClient_Do_OverrideOptions(t *testing.T) {
+	accessToken := "test/access/token"
+	challengedScope := "repository:test:pull"
+	hintedScope := "repository:dst:pull,push"
+	overriddenScope := "repository:src:pull"
+	wantUserAgent := "override agent"
+	var authCount int64
+	as := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wantScope := CleanScopes([]string{challengedScope, hintedScope, overriddenScope})
+		if got := r.URL.Query()["scope"]; !reflect.DeepEqual(got, wantScope) {
+			t.Errorf("unexpected scope: %v, want %v", got, wantScope)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if userAgent := r.UserAgent(); userAgent != wantUserAgent {
+			t.Errorf("unexpected User-Agent: %v, want %v", userAgent, wantUserAgent)
+		}
+		atomic.AddInt64(&authCount, 1)
+		if _, err := fmt.Fprintf(w, `{"access_token":%q}`, accessToken); err != nil {
+			t.Errorf("failed to write %q: %v", r.URL, err)
+		}
+	}))
+	defer as.Close()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if userAgent := r.UserAgent(); userAgent != wantUserAgent {
+			t.Errorf("unexpected User-Agent: %v, want %v", userAgent, wantUserAgent)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer "+accessToken {
+			challenge := fmt.Sprintf("Bearer realm=%q,service=%q,scope=%q", as.URL, "test", challengedScope)
+			w.Header().Set("Www-Authenticate", challenge)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}))
+	defer ts.Close()
+
+	client := &Client{
+		Cache: NewCache(),
+	}
+	client.SetUserAgent("default agent")
+	ctx := WithScopes(context.Background(), hintedScope)
+	ctx = override.WithOptions(ctx, override.Options{
+		UserAgent: wantUserAgent,
+		Scopes:    []string{overriddenScope},
+	})
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+		if err != nil {
+			t.Fatalf("failed to create test request: %v", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Client.Do() error = %v", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Client.Do() = %v, want %v", resp.StatusCode, http.StatusOK)
+		}
+	}
+	// the second request is served by the cached token
+	if authCount != 1 {
+		t.Errorf("unexpected number of auth requests: %d, want %d", authCount, 1)
+	}
+}
+
 func TestClient_Do_Scope_Hint_Mismatch(t *testing.T) {
 	username := "test_user"
 	password := "test_password"
@@ -1633,6 +1702,109 @@ func TestClient_Do_Scope_Hint_Mismatch(t *testing.T) {
 	}
 }
 
+func TestClient_Do_Insufficient_Scope(t *testing.T) {
+	pullScope := "repository:test:pull"
+	pushScope := "repository:test:pull,push"
+	var authCount, wantAuthCount int64
+	var service string
+	as := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/" {
+			t.Error("unexecuted attempt of authorization service")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if got := r.URL.Query().Get("service"); got != service {
+			t.Errorf("unexpected service: %v, want %v", got, service)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		atomic.AddInt64(&authCount, 1)
+		scope := strings.Join(r.URL.Query()["scope"], " ")
+		if _, err := fmt.Fprintf(w, `{"token":%q}`, scope); err != nil {
+			t.Errorf("failed to write %q: %v", r.URL, err)
+		}
+	}))
+	defer as.Close()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		switch {
+		case auth == "":
+			challenge := fmt.Sprintf("Bearer realm=%q,service=%q,scope=%q", as.URL, service, pullScope)
+			w.Header().Set("Www-Authenticate", challenge)
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.Method == http.MethodPut && auth != "Bearer "+pushScope:
+			challenge := fmt.Sprintf("Bearer realm=%q,service=%q,scope=%q,error=%q", as.URL, service, pushScope, "insufficient_scope")
+			w.Header().Set("Www-Authenticate", challenge)
+			w.WriteHeader(http.StatusForbidden)
+		case r.Method == http.MethodPut:
+			if got, err := io.ReadAll(r.Body); err != nil || string(got) != "hello" {
+				t.Errorf("unexpected request body: %q, %v", got, err)
+			}
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+	service = uri.Host
+
+	client := &Client{
+		Cache: NewCache(),
+	}
+	ctx := WithScopes(context.Background(), pullScope)
+
+	// pull with a pull-scoped token
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+	if err != nil {
+		t.Fatalf("failed to create test request: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Client.Do() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Client.Do() = %v, want %v", resp.StatusCode, http.StatusOK)
+	}
+	if wantAuthCount++; authCount != wantAuthCount {
+		t.Errorf("unexpected number of auth requests: %d, want %d", authCount, wantAuthCount)
+	}
+
+	// push with the cached pull-scoped token escalates the scope
+	req, err = http.NewRequestWithContext(ctx, http.MethodPut, ts.URL, strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("failed to create test request: %v", err)
+	}
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("Client.Do() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Client.Do() = %v, want %v", resp.StatusCode, http.StatusCreated)
+	}
+	if wantAuthCount++; authCount != wantAuthCount {
+		t.Errorf("unexpected number of auth requests: %d, want %d", authCount, wantAuthCount)
+	}
+
+	// repeated push with the escalated scope hits the cache
+	ctx = WithScopes(context.Background(), pushScope)
+	req, err = http.NewRequestWithContext(ctx, http.MethodPut, ts.URL, strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("failed to create test request: %v", err)
+	}
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("Client.Do() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Client.Do() = %v, want %v", resp.StatusCode, http.StatusCreated)
+	}
+	if authCount != wantAuthCount {
+		t.Errorf("unexpected number of auth requests: %d, want %d", authCount, wantAuthCount)
+	}
+}
+
 func TestClient_Do_Invalid_Credential_Basic(t *testing.T) {
 	username := "test_user"
 	password := "test_password"
@@ -2288,3 +2460,126 @@ func TestClient_StaticCredential_withRefreshToken(t *testing.T) {
 		t.Errorf("incorrect error: %v, expected %v", err, expectedError)
 	}
 }
+
+// spanRecorder records the names and the HTTP status codes of the spans.
+type spanRecorder struct {
+	names       []string
+	statusCodes []int64
+}
+
+func (r *spanRecorder) Start(ctx context.Context, name string, _ ...tracing.Attribute) (context.Context, tracing.Span) {
+	r.names = append(r.names, name)
+	return ctx, r
+}
+
+func (r *spanRecorder) SetAttributes(attrs ...tracing.Attribute) {
+	for _, attr := range attrs {
+		if attr.Key == tracing.AttributeKeyHTTPStatusCode {
+			r.statusCodes = append(r.statusCodes, attr.Value.(int64))
+		}
+	}
+}
+
+func (r *spanRecorder) End(error) {}
+
+func TestClient_Do_Tracing(t *testing.T) {
+	accessToken := "test/access/token"
+	as := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"access_token":%q}`, accessToken)
+	}))
+	defer as.Close()
+	var service string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer "+accessToken {
+			challenge := fmt.Sprintf("Bearer realm=%q,service=%q,scope=%q", as.URL, service, "repository:test:pull")
+			w.Header().Set("Www-Authenticate", challenge)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+	service = uri.Host
+
+	recorder := &spanRecorder{}
+	ctx := tracing.WithTracer(context.Background(), recorder)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+	if err != nil {
+		t.Fatalf("failed to create test request: %v", err)
+	}
+	client := &Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Client.Do() error = %v", err)
+	}
+	resp.Body.Close()
+
+	wantNames := []string{"HTTP GET", "auth.FetchToken", "HTTP GET", "HTTP GET"}
+	if !reflect.DeepEqual(recorder.names, wantNames) {
+		t.Errorf("span names = %v, want %v", recorder.names, wantNames)
+	}
+	wantStatusCodes := []int64{http.StatusUnauthorized, http.StatusOK, http.StatusOK}
+	if !reflect.DeepEqual(recorder.statusCodes, wantStatusCodes) {
+		t.Errorf("span status codes = %v, want %v", recorder.statusCodes, wantStatusCodes)
+	}
+}
+
+func TestClient_Do_Metrics(t *testing.T) {
+	accessToken := "test/access/token"
+	as := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"access_token":%q}`, accessToken)
+	}))
+	defer as.Close()
+	var service string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer "+accessToken {
+			challenge := fmt.Sprintf("Bearer realm=%q,service=%q,scope=%q", as.URL, service, "repository:test:pull")
+			w.Header().Set("Www-Authenticate", challenge)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+	service = uri.Host
+
+	registry := metrics.NewRegistry()
+	ctx := metrics.WithRecorder(context.Background(), registry)
+	ctx = WithScopes(ctx, "repository:test:pull")
+	client := &Client{
+		Cache: NewCache(),
+	}
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+		if err != nil {
+			t.Fatalf("failed to create test request: %v", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Client.Do() error = %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	var buf bytes.Buffer
+	if _, err := registry.WriteTo(&buf); err != nil {
+		t.Fatalf("Registry.WriteTo() error = %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		fmt.Sprintf("oras_auth_round_trips_total{registry=%q} 1\n", uri.Host),
+		"oras_cache_hits_total{cache=\"auth\"} 1\n",
+		"oras_http_request_duration_seconds_count{code=\"401\",method=\"GET\"} 1\n",
+		"oras_http_request_duration_seconds_count{code=\"200\",method=\"GET\"} 3\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("metrics %q does not contain %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/override"
+)
+
+// defaultDownloadChunkSize is the default range size of parallel blob
+// downloads.
+const defaultDownloadChunkSize int64 = 16 * 1024 * 1024 // 16 MiB
+
+// downloadConcurrency returns the number of concurrent range requests of
+// blob downloads, honoring the concurrency override in the context once
+// parallel downloads are enabled by DownloadConcurrency.
+func (r *Repository) downloadConcurrency(ctx context.Context) int {
+	if r.quirks().NoParallelDownload {
+		return 1
+	}
+	if r.DownloadConcurrency <= 1 {
+		// parallel downloads are opt-in, and not enabled by the override
+		return r.DownloadConcurrency
+	}
+	if concurrency := override.FromContext(ctx).Concurrency; concurrency > 0 {
+		return concurrency
+	}
+	return r.DownloadConcurrency
+}
+
+// downloadChunkSize returns the range size of parallel blob downloads.
+func (r *Repository) downloadChunkSize() int64 {
+	if r.DownloadChunkSize > 0 {
+		return r.DownloadChunkSize
+	}
+	return defaultDownloadChunkSize
+}
+
+// fetchParallel fetches the blob in concurrent range requests.
+// The first range is requested before the others to probe the range request
+// capability of the server. If the server ignores the range request, the
+// whole blob is read from the first response instead.
+func (s *blobStore) fetchParallel(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	chunkSize := s.repo.downloadChunkSize()
+	resp, err := s.requestRange(ctx, target, 0, chunkSize)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		if size := resp.ContentLength; size != -1 && size != target.Size {
+			resp.Body.Close()
+			return nil, fmt.Errorf("%s %q: mismatch Content-Length", resp.Request.Method, resp.Request.URL)
+		}
+		return resp.Body, nil
+	}
+
+	fetcher := &probedRangeFetcher{
+		store:  s,
+		first:  resp.Body,
+		length: chunkSize,
+	}
+	rc, err := content.FetchParallel(ctx, fetcher, target, content.ParallelFetchOptions{
+		Concurrency: s.repo.downloadConcurrency(ctx),
+		ChunkSize:   chunkSize,
+	})
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return rc, nil
+}
+
+// probedRangeFetcher fetches the ranges of a blob, serving the first range
+// from the response of the probing request.
+type probedRangeFetcher struct {
+	store  *blobStore
+	first  io.ReadCloser
+	length int64
+}
+
+// FetchRange fetches length bytes of the blob starting at offset.
+func (f *probedRangeFetcher) FetchRange(ctx context.Context, target ocispec.Descriptor, offset, length int64) (io.ReadCloser, error) {
+	if offset == 0 && length == f.length && f.first != nil {
+		// the first range is fetched only once by content.FetchParallel
+		first := f.first
+		f.first = nil
+		return first, nil
+	}
+	return f.store.FetchRange(ctx, target, offset, length)
+}
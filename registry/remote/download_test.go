@@ -0,0 +1,173 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+func TestRepository_Fetch_Parallel(t *testing.T) {
+	blob := bytes.Repeat([]byte("hello world!"), 10)
+	blobDesc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+	}
+	var mu sync.Mutex
+	var ranges []string
+	ignoreRange := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v2/test/blobs/"+blobDesc.Digest.String() {
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		mu.Unlock()
+		if ignoreRange || r.Header.Get("Range") == "" {
+			w.Write(blob)
+			return
+		}
+		var start, end int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); err != nil {
+			t.Errorf("invalid range header: %q", r.Header.Get("Range"))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(blob)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(blob[start : end+1])
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	repo.DownloadConcurrency = 3
+	repo.DownloadChunkSize = 50
+	ctx := context.Background()
+
+	// parallel download
+	rc, err := repo.Fetch(ctx, blobDesc)
+	if err != nil {
+		t.Fatalf("Repository.Fetch() error = %v", err)
+	}
+	got, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	rc.Close()
+	if !bytes.Equal(got, blob) {
+		t.Errorf("Repository.Fetch() = %q, want %q", got, blob)
+	}
+	sort.Strings(ranges)
+	if want := []string{"bytes=0-49", "bytes=100-119", "bytes=50-99"}; fmt.Sprint(ranges) != fmt.Sprint(want) {
+		t.Errorf("requested ranges = %v, want %v", ranges, want)
+	}
+
+	// server ignoring range requests
+	ranges = nil
+	ignoreRange = true
+	rc, err = repo.Fetch(ctx, blobDesc)
+	if err != nil {
+		t.Fatalf("Repository.Fetch() error = %v", err)
+	}
+	got, err = io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	rc.Close()
+	if !bytes.Equal(got, blob) {
+		t.Errorf("Repository.Fetch() = %q, want %q", got, blob)
+	}
+	if len(ranges) != 1 {
+		t.Errorf("requested ranges = %v, want 1 request", ranges)
+	}
+
+	// small blob fetched in a single request
+	ranges = nil
+	ignoreRange = false
+	repo.DownloadChunkSize = int64(len(blob))
+	rc, err = repo.Fetch(ctx, blobDesc)
+	if err != nil {
+		t.Fatalf("Repository.Fetch() error = %v", err)
+	}
+	rc.Close()
+	if want := []string{""}; fmt.Sprint(ranges) != fmt.Sprint(want) {
+		t.Errorf("requested ranges = %q, want %q", ranges, want)
+	}
+}
+
+func TestRepository_Fetch_ParallelMismatchedDigest(t *testing.T) {
+	blob := bytes.Repeat([]byte("hello world!"), 10)
+	blobDesc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes([]byte("foo")),
+		Size:      int64(len(blob)),
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var start, end int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); err != nil {
+			t.Errorf("invalid range header: %q", r.Header.Get("Range"))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(blob[start : end+1])
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	repo.DownloadConcurrency = 2
+	repo.DownloadChunkSize = 32
+	rc, err := repo.Fetch(context.Background(), blobDesc)
+	if err != nil {
+		t.Fatalf("Repository.Fetch() error = %v", err)
+	}
+	defer rc.Close()
+	if _, err := io.ReadAll(rc); !errors.Is(err, content.ErrMismatchedDigest) {
+		t.Errorf("ReadAll() error = %v, want %v", err, content.ErrMismatchedDigest)
+	}
+}
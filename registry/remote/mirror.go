@@ -0,0 +1,195 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/matcher"
+)
+
+// defaultMirrorFailureCooldown is the default value of
+// MirrorConfig.FailureCooldown.
+const defaultMirrorFailureCooldown = 30 * time.Second
+
+// Mirror is a mirror endpoint of an upstream registry, such as an internal
+// pull-through cache, similar to the mirror hosts configured for containerd.
+type Mirror struct {
+	// Host is the host name of the mirror, optionally with a port.
+	// Example: mirror.corp:5000
+	Host string
+
+	// PlainHTTP signals the transport to access the mirror via HTTP instead
+	// of HTTPS.
+	PlainHTTP bool
+
+	// Match restricts the mirror to the pull requests of the references
+	// matching it, such as matcher.Glob("docker.io/library/**"). The
+	// reference of a request is derived from its URL, where blob requests
+	// are referenced by digests, and catalog requests have no repository.
+	// If nil, the mirror is used for all the pull requests.
+	Match matcher.Matcher
+}
+
+// MirrorConfig configures the mirrors of upstream registries.
+//
+// When a repository of an upstream registry with mirrors is accessed, pull
+// requests, i.e. GET and HEAD requests, are sent to the mirrors in order, and
+// then to the upstream registry, until one of them succeeds. Push requests are
+// always sent to the upstream registry.
+//
+// A mirror failing with a network error or a server error is considered
+// unhealthy, and is skipped until FailureCooldown elapses. A mirror
+// responding 404 Not Found, e.g. a cache without the requested content, is
+// skipped for the request only.
+//
+// A MirrorConfig is safe for concurrent use, and is expected to be shared by
+// the repositories, e.g. via Registry.RepositoryOptions, so that the health
+// of the mirrors is tracked across them.
+type MirrorConfig struct {
+	// FailureCooldown is the duration an unhealthy mirror is skipped for.
+	// If less than or equal to zero, a default (currently 30 seconds) is used.
+	FailureCooldown time.Duration
+
+	lock    sync.RWMutex
+	mirrors map[string][]Mirror
+	// unhealthy maps the hosts of the unhealthy mirrors to the time they
+	// failed.
+	unhealthy map[string]time.Time
+}
+
+// NewMirrorConfig creates a new MirrorConfig.
+func NewMirrorConfig() *MirrorConfig {
+	return &MirrorConfig{
+		mirrors:   make(map[string][]Mirror),
+		unhealthy: make(map[string]time.Time),
+	}
+}
+
+// AddMirrors adds the mirrors of the upstream registry, such as "docker.io".
+// The mirrors are tried in the order of being added.
+func (c *MirrorConfig) AddMirrors(upstream string, mirrors ...Mirror) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.mirrors[upstream] = append(c.mirrors[upstream], mirrors...)
+}
+
+// Mirrors returns the mirrors of the upstream registry.
+func (c *MirrorConfig) Mirrors(upstream string) []Mirror {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return append([]Mirror(nil), c.mirrors[upstream]...)
+}
+
+// healthy returns true if the mirror is not in the cooldown of a failure.
+func (c *MirrorConfig) healthy(m Mirror) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	failedAt, ok := c.unhealthy[m.Host]
+	if !ok {
+		return true
+	}
+	cooldown := c.FailureCooldown
+	if cooldown <= 0 {
+		cooldown = defaultMirrorFailureCooldown
+	}
+	return time.Since(failedAt) >= cooldown
+}
+
+// setHealthy records the health of the mirror.
+func (c *MirrorConfig) setHealthy(m Mirror, healthy bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if healthy {
+		delete(c.unhealthy, m.Host)
+	} else {
+		c.unhealthy[m.Host] = time.Now()
+	}
+}
+
+// mirrorClient sends the pull requests to the upstream host through the
+// mirrors first.
+type mirrorClient struct {
+	Client
+	config   *MirrorConfig
+	registry string
+	upstream string
+	mirrors  []Mirror
+}
+
+// Do sends the request through the healthy mirrors in order, and falls back
+// to the upstream registry.
+func (c *mirrorClient) Do(req *http.Request) (*http.Response, error) {
+	if (req.Method != http.MethodGet && req.Method != http.MethodHead) || req.URL.Host != c.upstream {
+		return c.Client.Do(req)
+	}
+	ref := registry.Reference{Registry: c.registry}
+	ref.Repository, ref.Reference = parseRequestPath(req.URL.Path)
+	for _, m := range c.mirrors {
+		if m.Match != nil && !m.Match.Match(ref) {
+			continue
+		}
+		if !c.config.healthy(m) {
+			continue
+		}
+		mirrorReq := req.Clone(req.Context())
+		mirrorReq.Host = ""
+		mirrorReq.URL.Host = m.Host
+		mirrorReq.URL.Scheme = "https"
+		if m.PlainHTTP {
+			mirrorReq.URL.Scheme = "http"
+		}
+		resp, err := c.Client.Do(mirrorReq)
+		if err != nil {
+			if ctxErr := req.Context().Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			c.config.setHealthy(m, false)
+			continue
+		}
+		switch {
+		case resp.StatusCode >= http.StatusInternalServerError:
+			c.config.setHealthy(m, false)
+		case resp.StatusCode == http.StatusNotFound:
+			// the mirror is healthy but misses the content
+		default:
+			c.config.setHealthy(m, true)
+			return resp, nil
+		}
+		resp.Body.Close()
+	}
+	return c.Client.Do(req)
+}
+
+// parseRequestPath returns the repository and the reference of the request
+// path of the distribution API, such as `/v2/<name>/manifests/<reference>`.
+// Reference: https://github.com/opencontainers/distribution-spec/blob/v1.1.0-rc1/spec.md#endpoints
+func parseRequestPath(path string) (repository string, reference string) {
+	path = strings.TrimPrefix(path, "/v2/")
+	for _, endpoint := range []string{"/manifests/", "/blobs/", "/referrers/"} {
+		if index := strings.LastIndex(path, endpoint); index != -1 {
+			return path[:index], path[index+len(endpoint):]
+		}
+	}
+	if strings.HasSuffix(path, "/tags/list") {
+		return strings.TrimSuffix(path, "/tags/list"), ""
+	}
+	return "", ""
+}
@@ -0,0 +1,273 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/matcher"
+)
+
+// newBlobServer creates a test server serving the blob with the status code,
+// and counts the requests.
+func newBlobServer(t *testing.T, blob []byte, status *int32, count *int32) *httptest.Server {
+	desc := content.NewDescriptorFromBytes("test", blob)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(count, 1)
+		if code := int(atomic.LoadInt32(status)); code != http.StatusOK {
+			w.WriteHeader(code)
+			return
+		}
+		if r.Method != http.MethodGet || r.URL.Path != "/v2/test/blobs/"+desc.Digest.String() {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Docker-Content-Digest", desc.Digest.String())
+		w.Write(blob)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestRepository_Mirrors(t *testing.T) {
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	var upstreamStatus, mirrorStatus int32 = http.StatusOK, http.StatusOK
+	var upstreamCount, mirrorCount int32
+	upstream := newBlobServer(t, blob, &upstreamStatus, &upstreamCount)
+	mirror := newBlobServer(t, blob, &mirrorStatus, &mirrorCount)
+	upstreamURL, err := url.Parse(upstream.URL)
+	if err != nil {
+		t.Fatal("url.Parse() error =", err)
+	}
+	mirrorURL, err := url.Parse(mirror.URL)
+	if err != nil {
+		t.Fatal("url.Parse() error =", err)
+	}
+
+	config := NewMirrorConfig()
+	config.AddMirrors(upstreamURL.Host, Mirror{Host: mirrorURL.Host, PlainHTTP: true})
+	repo := &Repository{
+		Reference: registry.Reference{
+			Registry:   upstreamURL.Host,
+			Repository: "test",
+		},
+		Client:    http.DefaultClient,
+		PlainHTTP: true,
+		Mirrors:   config,
+	}
+	ctx := context.Background()
+	fetch := func() {
+		t.Helper()
+		got, err := content.FetchAll(ctx, repo, desc)
+		if err != nil {
+			t.Fatal("Repository.Fetch() error =", err)
+		}
+		if !bytes.Equal(got, blob) {
+			t.Errorf("Repository.Fetch() = %q, want %q", got, blob)
+		}
+	}
+	checkCount := func(wantMirror, wantUpstream int32) {
+		t.Helper()
+		if got := atomic.SwapInt32(&mirrorCount, 0); got != wantMirror {
+			t.Errorf("mirror requests = %d, want %d", got, wantMirror)
+		}
+		if got := atomic.SwapInt32(&upstreamCount, 0); got != wantUpstream {
+			t.Errorf("upstream requests = %d, want %d", got, wantUpstream)
+		}
+	}
+
+	// test pulling through the mirror
+	fetch()
+	checkCount(1, 0)
+
+	// test falling back on content missing in the mirror
+	atomic.StoreInt32(&mirrorStatus, http.StatusNotFound)
+	fetch()
+	checkCount(1, 1)
+	fetch()
+	checkCount(1, 1)
+
+	// test skipping the unhealthy mirror
+	atomic.StoreInt32(&mirrorStatus, http.StatusServiceUnavailable)
+	fetch()
+	checkCount(1, 1)
+	atomic.StoreInt32(&mirrorStatus, http.StatusOK)
+	fetch()
+	checkCount(0, 1)
+
+	// test retrying the mirror after the cooldown
+	config.FailureCooldown = time.Millisecond
+	time.Sleep(10 * time.Millisecond)
+	fetch()
+	checkCount(1, 0)
+
+	// test push requests not sent to the mirror
+	atomic.StoreInt32(&upstreamStatus, http.StatusForbidden)
+	if err := repo.Push(ctx, desc, bytes.NewReader(blob)); err == nil {
+		t.Error("Repository.Push() error = nil, wantErr true")
+	}
+	checkCount(0, 1)
+
+	// test repositories derived from a registry
+	reg := &Registry{
+		RepositoryOptions: RepositoryOptions{
+			Client:    http.DefaultClient,
+			Reference: registry.Reference{Registry: upstreamURL.Host},
+			PlainHTTP: true,
+			Mirrors:   config,
+		},
+	}
+	derived, err := reg.Repository(ctx, "test")
+	if err != nil {
+		t.Fatal("Registry.Repository() error =", err)
+	}
+	if _, err := content.FetchAll(ctx, derived, desc); err != nil {
+		t.Fatal("Repository.Fetch() error =", err)
+	}
+	checkCount(1, 0)
+}
+
+func TestRepository_Mirrors_Unreachable(t *testing.T) {
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	var status int32 = http.StatusOK
+	var count int32
+	upstream := newBlobServer(t, blob, &status, &count)
+	upstreamURL, err := url.Parse(upstream.URL)
+	if err != nil {
+		t.Fatal("url.Parse() error =", err)
+	}
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closedURL, err := url.Parse(closed.URL)
+	if err != nil {
+		t.Fatal("url.Parse() error =", err)
+	}
+	closed.Close()
+
+	config := NewMirrorConfig()
+	config.AddMirrors(upstreamURL.Host, Mirror{Host: closedURL.Host, PlainHTTP: true})
+	if got := config.Mirrors(upstreamURL.Host); len(got) != 1 {
+		t.Fatalf("MirrorConfig.Mirrors() = %v, want 1 mirror", got)
+	}
+	repo := &Repository{
+		Reference: registry.Reference{
+			Registry:   upstreamURL.Host,
+			Repository: "test",
+		},
+		PlainHTTP: true,
+		Mirrors:   config,
+	}
+	got, err := content.FetchAll(context.Background(), repo, desc)
+	if err != nil {
+		t.Fatal("Repository.Fetch() error =", err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("Repository.Fetch() = %q, want %q", got, blob)
+	}
+	if count != 1 {
+		t.Errorf("upstream requests = %d, want %d", count, 1)
+	}
+	if config.healthy(Mirror{Host: closedURL.Host}) {
+		t.Error("unreachable mirror is healthy")
+	}
+}
+
+func TestRepository_Mirrors_Match(t *testing.T) {
+	blob := []byte("hello world")
+	desc := content.NewDescriptorFromBytes("test", blob)
+	var upstreamStatus, mirrorStatus int32 = http.StatusOK, http.StatusOK
+	var upstreamCount, mirrorCount int32
+	upstream := newBlobServer(t, blob, &upstreamStatus, &upstreamCount)
+	mirror := newBlobServer(t, blob, &mirrorStatus, &mirrorCount)
+	upstreamURL, err := url.Parse(upstream.URL)
+	if err != nil {
+		t.Fatal("url.Parse() error =", err)
+	}
+	mirrorURL, err := url.Parse(mirror.URL)
+	if err != nil {
+		t.Fatal("url.Parse() error =", err)
+	}
+
+	tests := []struct {
+		pattern    string
+		wantMirror int32
+	}{
+		{pattern: upstreamURL.Host + "/test", wantMirror: 1},
+		{pattern: upstreamURL.Host + "/**:v*", wantMirror: 1},
+		{pattern: upstreamURL.Host + "/other", wantMirror: 0},
+		{pattern: "example.com/**", wantMirror: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			match, err := matcher.Glob(tt.pattern)
+			if err != nil {
+				t.Fatal("matcher.Glob() error =", err)
+			}
+			config := NewMirrorConfig()
+			config.AddMirrors(upstreamURL.Host, Mirror{Host: mirrorURL.Host, PlainHTTP: true, Match: match})
+			repo := &Repository{
+				Reference: registry.Reference{
+					Registry:   upstreamURL.Host,
+					Repository: "test",
+				},
+				Client:    http.DefaultClient,
+				PlainHTTP: true,
+				Mirrors:   config,
+			}
+			if _, err := content.FetchAll(context.Background(), repo, desc); err != nil {
+				t.Fatal("Repository.Fetch() error =", err)
+			}
+			if got := atomic.SwapInt32(&mirrorCount, 0); got != tt.wantMirror {
+				t.Errorf("mirror requests = %d, want %d", got, tt.wantMirror)
+			}
+			if got, want := atomic.SwapInt32(&upstreamCount, 0), 1-tt.wantMirror; got != want {
+				t.Errorf("upstream requests = %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func Test_parseRequestPath(t *testing.T) {
+	tests := []struct {
+		path           string
+		wantRepository string
+		wantReference  string
+	}{
+		{"/v2/", "", ""},
+		{"/v2/_catalog", "", ""},
+		{"/v2/org/app/manifests/v1", "org/app", "v1"},
+		{"/v2/org/app/blobs/sha256:abc", "org/app", "sha256:abc"},
+		{"/v2/org/app/referrers/sha256:abc", "org/app", "sha256:abc"},
+		{"/v2/org/app/tags/list", "org/app", ""},
+	}
+	for _, tt := range tests {
+		repository, reference := parseRequestPath(tt.path)
+		if repository != tt.wantRepository || reference != tt.wantReference {
+			t.Errorf("parseRequestPath(%q) = %q, %q, want %q, %q", tt.path, repository, reference, tt.wantRepository, tt.wantReference)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import "strings"
+
+// Quirks describes the known deviations of a registry implementation from
+// the distribution spec, which the clients adapt their behavior to.
+// The zero value describes a conformant registry.
+type Quirks struct {
+	// NoCatalog indicates that the registry does not implement the catalog
+	// API. Registry.Repositories returns errdef.ErrUnsupported without
+	// sending any request.
+	NoCatalog bool
+
+	// MinUploadChunkSize specifies the minimum size of the chunks accepted by
+	// the registry, except for the last chunk of a blob. Chunked uploads
+	// raise UploadChunkSize to this value if it is smaller.
+	MinUploadChunkSize int64
+
+	// NoParallelUpload indicates that the registry mishandles concurrent
+	// chunk uploads. Blobs are uploaded in a single request regardless of
+	// UploadConcurrency.
+	NoParallelUpload bool
+
+	// NoParallelDownload indicates that the registry redirects blob
+	// downloads in a way that range requests are not honored consistently.
+	// Blobs are fetched in a single request regardless of
+	// DownloadConcurrency.
+	NoParallelDownload bool
+
+	// SingleScope indicates that the authorization service of the registry
+	// rejects token requests with scopes of repositories other than the
+	// target one. Scope hints of other repositories, such as the source
+	// repository of a cross-repository mount, are not sent.
+	SingleScope bool
+}
+
+var (
+	// QuirksECR describes Amazon Elastic Container Registry, which does
+	// not implement the catalog API and requires upload chunks of at least
+	// 5 MiB.
+	QuirksECR = Quirks{
+		NoCatalog:          true,
+		MinUploadChunkSize: 5 * 1024 * 1024, // 5 MiB
+	}
+
+	// QuirksGHCR describes GitHub Container Registry, whose authorization
+	// service rejects token requests with scopes of inaccessible
+	// repositories.
+	QuirksGHCR = Quirks{
+		SingleScope: true,
+	}
+
+	// QuirksHarbor describes Harbor, which redirects blob downloads to the
+	// storage backend. Harbor cannot be identified by its host name, so the
+	// quirks must be set explicitly by Repository.Quirks.
+	QuirksHarbor = Quirks{
+		NoParallelDownload: true,
+	}
+)
+
+// LookupQuirks returns the quirks of the registry identified by host, such as
+// "ghcr.io" or "123456789012.dkr.ecr.us-east-1.amazonaws.com".
+// The zero Quirks is returned for registries that are not known to deviate
+// from the distribution spec.
+func LookupQuirks(host string) Quirks {
+	// strip port if any
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	host = strings.ToLower(host)
+	switch {
+	case host == "ghcr.io":
+		return QuirksGHCR
+	case strings.Contains(host, ".dkr.ecr.") &&
+		(strings.HasSuffix(host, ".amazonaws.com") || strings.HasSuffix(host, ".amazonaws.com.cn")):
+		return QuirksECR
+	case host == "public.ecr.aws":
+		return QuirksECR
+	}
+	return Quirks{}
+}
+
+// quirks returns the quirks of the remote registry.
+// If Quirks is not set, the quirks are looked up by the registry host.
+func (r *Repository) quirks() Quirks {
+	if r.Quirks != nil {
+		return *r.Quirks
+	}
+	return LookupQuirks(r.Reference.Registry)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestLookupQuirks(t *testing.T) {
+	tests := []struct {
+		host string
+		want Quirks
+	}{
+		{"ghcr.io", QuirksGHCR},
+		{"GHCR.io:443", QuirksGHCR},
+		{"123456789012.dkr.ecr.us-east-1.amazonaws.com", QuirksECR},
+		{"123456789012.dkr.ecr.cn-north-1.amazonaws.com.cn", QuirksECR},
+		{"public.ecr.aws", QuirksECR},
+		{"s3.amazonaws.com", Quirks{}},
+		{"localhost:5000", Quirks{}},
+		{"registry.example.com", Quirks{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := LookupQuirks(tt.host); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LookupQuirks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_quirks(t *testing.T) {
+	repo, err := NewRepository("ghcr.io/test/repo")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	if got := repo.quirks(); !reflect.DeepEqual(got, QuirksGHCR) {
+		t.Errorf("Repository.quirks() = %v, want %v", got, QuirksGHCR)
+	}
+	repo.Quirks = &QuirksHarbor
+	if got := repo.quirks(); !reflect.DeepEqual(got, QuirksHarbor) {
+		t.Errorf("Repository.quirks() = %v, want %v", got, QuirksHarbor)
+	}
+
+	repo.UploadChunkSize = 1024
+	repo.UploadConcurrency = 4
+	repo.DownloadConcurrency = 4
+	repo.Quirks = &Quirks{
+		MinUploadChunkSize: 4096,
+		NoParallelUpload:   true,
+		NoParallelDownload: true,
+	}
+	ctx := context.Background()
+	if got, want := repo.uploadChunkSize(), int64(4096); got != want {
+		t.Errorf("Repository.uploadChunkSize() = %v, want %v", got, want)
+	}
+	if got, want := repo.uploadConcurrency(ctx), 1; got != want {
+		t.Errorf("Repository.uploadConcurrency() = %v, want %v", got, want)
+	}
+	if got, want := repo.downloadConcurrency(ctx), 1; got != want {
+		t.Errorf("Repository.downloadConcurrency() = %v, want %v", got, want)
+	}
+}
+
+func TestRegistry_Repositories_NoCatalog(t *testing.T) {
+	var requested bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	reg, err := NewRegistry(uri.Host)
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	reg.PlainHTTP = true
+	reg.Quirks = &QuirksECR
+	err = reg.Repositories(context.Background(), "", func(repos []string) error {
+		t.Error("unexpected repositories")
+		return nil
+	})
+	if !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Registry.Repositories() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+	if requested {
+		t.Error("Registry.Repositories() sent a request to a registry without catalog API")
+	}
+
+	// quirks are inherited by derived repositories
+	repo, err := reg.Repository(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("Registry.Repository() error = %v", err)
+	}
+	if got := repo.(*Repository).quirks(); !reflect.DeepEqual(got, QuirksECR) {
+		t.Errorf("Repository.quirks() = %v, want %v", got, QuirksECR)
+	}
+}
@@ -17,12 +17,10 @@ package remote
 
 import (
 	"errors"
-	"strings"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/internal/descriptor"
-	"oras.land/oras-go/v2/internal/spec"
 )
 
 // zeroDigest represents a digest that consists of zeros. zeroDigest is used
@@ -101,6 +99,10 @@ func (e *ReferrersError) IsReferrersIndexDelete() bool {
 	return e.Op == opDeleteReferrersIndex
 }
 
+// referrersBatchSize is the maximum number of referrers fed to the callback
+// of Referrers at a time when decoding a huge referrers list.
+const referrersBatchSize = 1000
+
 // buildReferrersTag builds the referrers tag for the given manifest descriptor.
 // Format: <algorithm>-<digest>
 // Reference: https://github.com/opencontainers/distribution-spec/blob/v1.1.0-rc1/spec.md#unavailable-referrers-api
@@ -110,40 +112,6 @@ func buildReferrersTag(desc ocispec.Descriptor) string {
 	return alg + "-" + encoded
 }
 
-// isReferrersFilterApplied checks annotations to see if requested is in the
-// applied filter list.
-func isReferrersFilterApplied(annotations map[string]string, requested string) bool {
-	applied := annotations[spec.AnnotationReferrersFiltersApplied]
-	if applied == "" || requested == "" {
-		return false
-	}
-	filters := strings.Split(applied, ",")
-	for _, f := range filters {
-		if f == requested {
-			return true
-		}
-	}
-	return false
-}
-
-// filterReferrers filters a slice of referrers by artifactType in place.
-// The returned slice contains matching referrers.
-func filterReferrers(refs []ocispec.Descriptor, artifactType string) []ocispec.Descriptor {
-	if artifactType == "" {
-		return refs
-	}
-	var j int
-	for i, ref := range refs {
-		if ref.ArtifactType == artifactType {
-			if i != j {
-				refs[j] = ref
-			}
-			j++
-		}
-	}
-	return refs[:j]
-}
-
 // applyReferrerChanges applies referrerChanges on referrers and returns the
 // updated referrers.
 // Returns errNoReferrerUpdate if there is no any referrers updates.
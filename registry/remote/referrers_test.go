@@ -19,9 +19,7 @@ import (
 	"reflect"
 	"testing"
 
-	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
-	"oras.land/oras-go/v2/internal/spec"
 )
 
 func Test_buildReferrersTag(t *testing.T) {
@@ -61,151 +59,6 @@ func Test_buildReferrersTag(t *testing.T) {
 	}
 }
 
-func Test_isReferrersFilterApplied(t *testing.T) {
-	tests := []struct {
-		name        string
-		annotations map[string]string
-		requested   string
-		want        bool
-	}{
-		{
-			name:        "single filter applied, specified filter matches",
-			annotations: map[string]string{spec.AnnotationReferrersFiltersApplied: "artifactType"},
-			requested:   "artifactType",
-			want:        true,
-		},
-		{
-			name:        "single filter applied, specified filter does not match",
-			annotations: map[string]string{spec.AnnotationReferrersFiltersApplied: "foo"},
-			requested:   "artifactType",
-			want:        false,
-		},
-		{
-			name:        "multiple filters applied, specified filter matches",
-			annotations: map[string]string{spec.AnnotationReferrersFiltersApplied: "foo,artifactType"},
-			requested:   "artifactType",
-			want:        true,
-		},
-		{
-			name:        "multiple filters applied, specified filter does not match",
-			annotations: map[string]string{spec.AnnotationReferrersFiltersApplied: "foo,bar"},
-			requested:   "artifactType",
-			want:        false,
-		},
-		{
-			name:        "single filter applied, specified filter empty",
-			annotations: map[string]string{spec.AnnotationReferrersFiltersApplied: "foo"},
-			requested:   "",
-			want:        false,
-		},
-		{
-			name:        "no filter applied",
-			annotations: map[string]string{},
-			requested:   "artifactType",
-			want:        false,
-		},
-		{
-			name:        "empty filter applied",
-			annotations: map[string]string{spec.AnnotationReferrersFiltersApplied: ""},
-			requested:   "artifactType",
-			want:        false,
-		},
-		{
-			name:        "no filter applied, specified filter empty",
-			annotations: map[string]string{},
-			requested:   "",
-			want:        false,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := isReferrersFilterApplied(tt.annotations, tt.requested); got != tt.want {
-				t.Errorf("isReferrersFilterApplied() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func Test_filterReferrers(t *testing.T) {
-	refs := []ocispec.Descriptor{
-		{
-			MediaType:    spec.MediaTypeArtifactManifest,
-			Size:         1,
-			Digest:       digest.FromString("1"),
-			ArtifactType: "application/vnd.test",
-		},
-		{
-			MediaType:    spec.MediaTypeArtifactManifest,
-			Size:         2,
-			Digest:       digest.FromString("2"),
-			ArtifactType: "application/vnd.foo",
-		},
-		{
-			MediaType:    spec.MediaTypeArtifactManifest,
-			Size:         3,
-			Digest:       digest.FromString("3"),
-			ArtifactType: "application/vnd.bar",
-		},
-		{
-			MediaType:    spec.MediaTypeArtifactManifest,
-			Size:         4,
-			Digest:       digest.FromString("4"),
-			ArtifactType: "application/vnd.test",
-		},
-		{
-			MediaType:    spec.MediaTypeArtifactManifest,
-			Size:         5,
-			Digest:       digest.FromString("5"),
-			ArtifactType: "application/vnd.baz",
-		},
-	}
-	got := filterReferrers(refs, "application/vnd.test")
-	want := []ocispec.Descriptor{
-		{
-			MediaType:    spec.MediaTypeArtifactManifest,
-			Size:         1,
-			Digest:       digest.FromString("1"),
-			ArtifactType: "application/vnd.test",
-		},
-		{
-			MediaType:    spec.MediaTypeArtifactManifest,
-			Size:         4,
-			Digest:       digest.FromString("4"),
-			ArtifactType: "application/vnd.test",
-		},
-	}
-	if !reflect.DeepEqual(got, want) {
-		t.Errorf("filterReferrers() = %v, want %v", got, want)
-	}
-}
-
-func Test_filterReferrers_allMatch(t *testing.T) {
-	refs := []ocispec.Descriptor{
-		{
-			MediaType:    spec.MediaTypeArtifactManifest,
-			Size:         1,
-			Digest:       digest.FromString("1"),
-			ArtifactType: "application/vnd.test",
-		},
-		{
-			MediaType:    spec.MediaTypeArtifactManifest,
-			Size:         4,
-			Digest:       digest.FromString("2"),
-			ArtifactType: "application/vnd.test",
-		},
-		{
-			MediaType:    spec.MediaTypeArtifactManifest,
-			Size:         5,
-			Digest:       digest.FromString("3"),
-			ArtifactType: "application/vnd.test",
-		},
-	}
-	got := filterReferrers(refs, "application/vnd.test")
-	if !reflect.DeepEqual(got, refs) {
-		t.Errorf("filterReferrers() = %v, want %v", got, refs)
-	}
-}
-
 func Test_applyReferrerChanges(t *testing.T) {
 	descs := []ocispec.Descriptor{
 		{
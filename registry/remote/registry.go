@@ -73,6 +73,11 @@ func (r *Registry) client() Client {
 	return r.Client
 }
 
+// quirks returns the quirks of the remote registry.
+func (r *Registry) quirks() Quirks {
+	return (*Repository)(&r.RepositoryOptions).quirks()
+}
+
 // Ping checks whether or not the registry implement Docker Registry API V2 or
 // OCI Distribution Specification.
 // Ping can be used to check authentication when an auth client is configured.
@@ -105,6 +110,8 @@ func (r *Registry) Ping(ctx context.Context) error {
 
 // Repositories lists the name of repositories available in the registry.
 // See also `RepositoryListPageSize`.
+// Returns errdef.ErrUnsupported if the registry is known not to implement the
+// catalog API. See also `Quirks`.
 //
 // If `last` is NOT empty, the entries in the response start after the
 // repo specified by `last`. Otherwise, the response starts from the top
@@ -112,6 +119,9 @@ func (r *Registry) Ping(ctx context.Context) error {
 //
 // Reference: https://docs.docker.com/registry/spec/api/#catalog
 func (r *Registry) Repositories(ctx context.Context, last string, fn func(repos []string) error) error {
+	if r.quirks().NoCatalog {
+		return fmt.Errorf("%s: catalog API: %w", r.Reference.Registry, errdef.ErrUnsupported)
+	}
 	ctx = auth.AppendScopes(ctx, auth.ScopeRegistryCatalog)
 	url := buildRegistryCatalogURL(r.PlainHTTP, r.Reference)
 	var err error
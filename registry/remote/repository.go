@@ -24,6 +24,7 @@ import (
 	"io"
 	"mime"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -32,19 +33,24 @@ import (
 	"github.com/opencontainers/go-digest"
 	specs "github.com/opencontainers/image-spec/specs-go"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/conformance"
 	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/internal/cas"
 	"oras.land/oras-go/v2/internal/httputil"
 	"oras.land/oras-go/v2/internal/ioutil"
+	"oras.land/oras-go/v2/internal/jsonstream"
 	"oras.land/oras-go/v2/internal/registryutil"
 	"oras.land/oras-go/v2/internal/slices"
 	"oras.land/oras-go/v2/internal/spec"
 	"oras.land/oras-go/v2/internal/syncutil"
+	"oras.land/oras-go/v2/logging"
+	"oras.land/oras-go/v2/override"
 	"oras.land/oras-go/v2/registry"
 	"oras.land/oras-go/v2/registry/remote/auth"
 	"oras.land/oras-go/v2/registry/remote/errcode"
 	"oras.land/oras-go/v2/registry/remote/internal/errutil"
+	"oras.land/oras-go/v2/tracing"
 )
 
 // dockerContentDigestHeader - The Docker-Content-Digest header, if present
@@ -102,6 +108,66 @@ type Repository struct {
 	// If less than or equal to zero, a default (currently 4MiB) is used.
 	MaxMetadataBytes int64
 
+	// UploadChunkSize specifies the size of the chunks of resumable blob
+	// uploads. Blob uploads are resumable only if a registry.UploadTracker is
+	// attached to the context by registry.WithUploadTracker.
+	// If less than or equal to zero, a default (currently 8MiB) is used.
+	UploadChunkSize int64
+
+	// UploadConcurrency specifies the number of chunks uploaded concurrently
+	// when pushing a blob larger than UploadChunkSize, for registries
+	// accepting out-of-order chunks. Otherwise, the chunks are uploaded
+	// sequentially.
+	// If less than or equal to 1, blobs are uploaded in a single request
+	// unless a registry.UploadTracker is attached to the context.
+	UploadConcurrency int
+
+	// DownloadConcurrency specifies the number of range requests sent
+	// concurrently to fetch a blob larger than DownloadChunkSize. The ranges
+	// are reassembled in order and the blob is verified against its digest
+	// at the end.
+	// If less than or equal to 1, blobs are fetched in a single request.
+	DownloadConcurrency int
+
+	// DownloadChunkSize specifies the size of the ranges of parallel blob
+	// downloads. It takes effect only if DownloadConcurrency is greater
+	// than 1.
+	// If less than or equal to zero, a default (currently 16MiB) is used.
+	DownloadChunkSize int64
+
+	// Strict enables the strict OCI conformance mode, in which the following
+	// non-conformant behaviors are rejected with errdef.ErrNonConformant
+	// instead of being tolerated:
+	//   - Manifest responses without the Docker-Content-Digest header.
+	//   - Manifests whose mediaType field mismatches the Content-Type.
+	//   - Fetched or pushed manifests violating the image-spec, as validated
+	//     by conformance.ValidateManifest.
+	Strict bool
+
+	// Mirrors configures the mirrors of the upstream registries. If the
+	// registry of the repository has mirrors, the pull requests are sent
+	// through the mirrors first, falling back to the registry.
+	// If nil, no mirror is used.
+	Mirrors *MirrorConfig
+
+	// ResolveCache caches the descriptors resolved by references, which are
+	// revalidated by conditional requests, so that resolving an unchanged
+	// reference repeatedly, such as polling a tag, costs a single cheap
+	// request answered with 304 Not Modified.
+	// If nil, no cache is used.
+	ResolveCache *ResolveCache
+
+	// Quirks adapts the behavior of the client to the known deviations of
+	// the remote registry from the distribution spec.
+	// If nil, the quirks are looked up by the registry host using
+	// LookupQuirks.
+	Quirks *Quirks
+
+	// TagCache caches the tag lists returned by Tags until they expire or
+	// are invalidated.
+	// If nil, no cache is used.
+	TagCache *TagCache
+
 	// NOTE: Must keep fields in sync with newRepositoryWithOptions function.
 
 	// referrersState represents that if the repository supports Referrers API.
@@ -130,6 +196,30 @@ func NewRepository(reference string) (*Repository, error) {
 	}, nil
 }
 
+// NewMappedRepository creates a client to the remote repository mapped from
+// the source reference by mapping, such as the destination repository when
+// mirroring content.
+// If opts is not nil, the client is configured by opts like the repositories
+// derived from a Registry.
+// Returns ErrNotFound if no mapping rule matches the source reference.
+// Example: docker.io/library/alpine:3.18 is mapped to
+// registry.corp/mirror/alpine:3.18 by the rule
+// `docker.io/library/*=registry.corp/mirror/*`.
+func NewMappedRepository(source string, mapping *registry.Mapping, opts *RepositoryOptions) (*Repository, error) {
+	ref, err := registry.ParseReference(source)
+	if err != nil {
+		return nil, err
+	}
+	ref, err = mapping.Map(ref)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &RepositoryOptions{}
+	}
+	return newRepositoryWithOptions(ref, opts)
+}
+
 // newRepositoryWithOptions returns a Repository with the given Reference and
 // RepositoryOptions.
 //
@@ -149,6 +239,15 @@ func newRepositoryWithOptions(ref registry.Reference, opts *RepositoryOptions) (
 		TagListPageSize:      opts.TagListPageSize,
 		ReferrerListPageSize: opts.ReferrerListPageSize,
 		MaxMetadataBytes:     opts.MaxMetadataBytes,
+		UploadChunkSize:      opts.UploadChunkSize,
+		UploadConcurrency:    opts.UploadConcurrency,
+		DownloadConcurrency:  opts.DownloadConcurrency,
+		DownloadChunkSize:    opts.DownloadChunkSize,
+		Strict:               opts.Strict,
+		Mirrors:              opts.Mirrors,
+		ResolveCache:         opts.ResolveCache,
+		Quirks:               opts.Quirks,
+		TagCache:             opts.TagCache,
 	}, nil
 }
 
@@ -190,10 +289,28 @@ func (r *Repository) loadReferrersState() referrersState {
 // client returns an HTTP client used to access the remote repository.
 // A default HTTP client is return if the client is not configured.
 func (r *Repository) client() Client {
-	if r.Client == nil {
-		return auth.DefaultClient
+	client := r.Client
+	if client == nil {
+		client = auth.DefaultClient
+	}
+	if r.Mirrors != nil {
+		if mirrors := r.Mirrors.Mirrors(r.Reference.Registry); len(mirrors) > 0 {
+			return &mirrorClient{
+				Client:   client,
+				config:   r.Mirrors,
+				registry: r.Reference.Registry,
+				upstream: r.Reference.Host(),
+				mirrors:  mirrors,
+			}
+		}
 	}
-	return r.Client
+	return client
+}
+
+// startSpan starts a tracing span for the repository operation.
+func (r *Repository) startSpan(ctx context.Context, op string, attrs ...tracing.Attribute) (context.Context, tracing.Span) {
+	attrs = append(attrs, tracing.String(tracing.AttributeKeyRepository, r.Reference.String()))
+	return tracing.Start(ctx, "remote.Repository."+op, attrs...)
 }
 
 // blobStore detects the blob store for the given descriptor.
@@ -205,12 +322,25 @@ func (r *Repository) blobStore(desc ocispec.Descriptor) registry.BlobStore {
 }
 
 // Fetch fetches the content identified by the descriptor.
-func (r *Repository) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+func (r *Repository) Fetch(ctx context.Context, target ocispec.Descriptor) (_ io.ReadCloser, err error) {
+	ctx, span := r.startSpan(ctx, "Fetch", tracing.DescriptorAttributes(target)...)
+	defer func() { span.End(err) }()
 	return r.blobStore(target).Fetch(ctx, target)
 }
 
+// FetchRange fetches length bytes of the blob identified by the descriptor,
+// starting at offset, using a range request.
+// Manifests are fetched in full and the range is read from the content.
+func (r *Repository) FetchRange(ctx context.Context, target ocispec.Descriptor, offset, length int64) (_ io.ReadCloser, err error) {
+	ctx, span := r.startSpan(ctx, "FetchRange", tracing.DescriptorAttributes(target)...)
+	defer func() { span.End(err) }()
+	return content.FetchRange(ctx, r.blobStore(target), target, offset, length)
+}
+
 // Push pushes the content, matching the expected descriptor.
-func (r *Repository) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+func (r *Repository) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) (err error) {
+	ctx, span := r.startSpan(ctx, "Push", tracing.DescriptorAttributes(expected)...)
+	defer func() { span.End(err) }()
 	return r.blobStore(expected).Push(ctx, expected, content)
 }
 
@@ -223,17 +353,23 @@ func (r *Repository) Push(ctx context.Context, expected ocispec.Descriptor, cont
 // content to push. If getContent is nil, the content will be pulled from the source
 // repository. If getContent returns an error, it will be wrapped inside the error
 // returned from Mount.
-func (r *Repository) Mount(ctx context.Context, desc ocispec.Descriptor, fromRepo string, getContent func() (io.ReadCloser, error)) error {
+func (r *Repository) Mount(ctx context.Context, desc ocispec.Descriptor, fromRepo string, getContent func() (io.ReadCloser, error)) (err error) {
+	ctx, span := r.startSpan(ctx, "Mount", append(tracing.DescriptorAttributes(desc), tracing.String("oras.from_repository", fromRepo))...)
+	defer func() { span.End(err) }()
 	return r.Blobs().(registry.Mounter).Mount(ctx, desc, fromRepo, getContent)
 }
 
 // Exists returns true if the described content exists.
-func (r *Repository) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+func (r *Repository) Exists(ctx context.Context, target ocispec.Descriptor) (_ bool, err error) {
+	ctx, span := r.startSpan(ctx, "Exists", tracing.DescriptorAttributes(target)...)
+	defer func() { span.End(err) }()
 	return r.blobStore(target).Exists(ctx, target)
 }
 
 // Delete removes the content identified by the descriptor.
-func (r *Repository) Delete(ctx context.Context, target ocispec.Descriptor) error {
+func (r *Repository) Delete(ctx context.Context, target ocispec.Descriptor) (err error) {
+	ctx, span := r.startSpan(ctx, "Delete", tracing.DescriptorAttributes(target)...)
+	defer func() { span.End(err) }()
 	return r.blobStore(target).Delete(ctx, target)
 }
 
@@ -250,23 +386,40 @@ func (r *Repository) Manifests() registry.ManifestStore {
 
 // Resolve resolves a reference to a manifest descriptor.
 // See also `ManifestMediaTypes`.
-func (r *Repository) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+func (r *Repository) Resolve(ctx context.Context, reference string) (_ ocispec.Descriptor, err error) {
+	ctx, span := r.startSpan(ctx, "Resolve", tracing.String(tracing.AttributeKeyReference, reference))
+	defer func() { span.End(err) }()
 	return r.Manifests().Resolve(ctx, reference)
 }
 
+// ExistsReference returns true if the manifest identified by the reference
+// exists, using a HEAD request.
+// The reference can be a tag or digest.
+func (r *Repository) ExistsReference(ctx context.Context, reference string) (_ bool, err error) {
+	ctx, span := r.startSpan(ctx, "ExistsReference", tracing.String(tracing.AttributeKeyReference, reference))
+	defer func() { span.End(err) }()
+	return (&manifestStore{repo: r}).ExistsReference(ctx, reference)
+}
+
 // Tag tags a manifest descriptor with a reference string.
-func (r *Repository) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+func (r *Repository) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) (err error) {
+	ctx, span := r.startSpan(ctx, "Tag", append(tracing.DescriptorAttributes(desc), tracing.String(tracing.AttributeKeyReference, reference))...)
+	defer func() { span.End(err) }()
 	return r.Manifests().Tag(ctx, desc, reference)
 }
 
 // PushReference pushes the manifest with a reference tag.
-func (r *Repository) PushReference(ctx context.Context, expected ocispec.Descriptor, content io.Reader, reference string) error {
+func (r *Repository) PushReference(ctx context.Context, expected ocispec.Descriptor, content io.Reader, reference string) (err error) {
+	ctx, span := r.startSpan(ctx, "PushReference", append(tracing.DescriptorAttributes(expected), tracing.String(tracing.AttributeKeyReference, reference))...)
+	defer func() { span.End(err) }()
 	return r.Manifests().PushReference(ctx, expected, content, reference)
 }
 
 // FetchReference fetches the manifest identified by the reference.
 // The reference can be a tag or digest.
-func (r *Repository) FetchReference(ctx context.Context, reference string) (ocispec.Descriptor, io.ReadCloser, error) {
+func (r *Repository) FetchReference(ctx context.Context, reference string) (_ ocispec.Descriptor, _ io.ReadCloser, err error) {
+	ctx, span := r.startSpan(ctx, "FetchReference", tracing.String(tracing.AttributeKeyReference, reference))
+	defer func() { span.End(err) }()
 	return r.Manifests().FetchReference(ctx, reference)
 }
 
@@ -314,7 +467,7 @@ func (r *Repository) ParseReference(reference string) (registry.Reference, error
 }
 
 // Tags lists the tags available in the repository.
-// See also `TagListPageSize`.
+// See also `TagListPageSize` and `TagCache`.
 // If `last` is NOT empty, the entries in the response start after the
 // tag specified by `last`. Otherwise, the response starts from the top
 // of the Tags list.
@@ -323,6 +476,27 @@ func (r *Repository) ParseReference(reference string) (registry.Reference, error
 //   - https://github.com/opencontainers/distribution-spec/blob/v1.1.0-rc1/spec.md#content-discovery
 //   - https://docs.docker.com/registry/spec/api/#tags
 func (r *Repository) Tags(ctx context.Context, last string, fn func(tags []string) error) error {
+	if r.TagCache == nil {
+		return r.listTags(ctx, last, fn)
+	}
+	if tags, ok := r.TagCache.load(r.Reference); ok {
+		return tagsFromCache(tags, last, r.TagListPageSize, fn)
+	}
+	var tags []string
+	if err := r.listTags(ctx, "", func(page []string) error {
+		tags = append(tags, page...)
+		return nil
+	}); err != nil {
+		return err
+	}
+	sort.Strings(tags)
+	r.TagCache.store(r.Reference, tags)
+	return tagsFromCache(tags, last, r.TagListPageSize, fn)
+}
+
+// listTags lists the tags available in the repository from the remote
+// registry.
+func (r *Repository) listTags(ctx context.Context, last string, fn func(tags []string) error) error {
 	ctx = registryutil.WithScopeHint(ctx, r.Reference, auth.ActionPull)
 	url := buildRepositoryTagListURL(r.PlainHTTP, r.Reference)
 	var err error
@@ -425,6 +599,7 @@ func (r *Repository) Referrers(ctx context.Context, desc ocispec.Descriptor, art
 		}
 		// A 404 returned by Referrers API indicates that Referrers API is
 		// not supported. Fallback to referrers tag schema.
+		logging.FromContext(ctx).Info("referrers API not supported, falling back to referrers tag schema", "repository", r.Reference.String())
 		r.SetReferrersCapability(false)
 		return r.referrersByTagSchema(ctx, desc, artifactType, fn)
 	}
@@ -480,16 +655,31 @@ func (r *Repository) referrersPageByAPI(ctx context.Context, artifactType string
 		return "", errutil.ParseErrorResponse(resp)
 	}
 
-	var index ocispec.Index
+	// the referrers are decoded incrementally and fed to fn in batches to
+	// bound the memory used by huge referrers lists.
+	// The referrers are always filtered on the client side since the
+	// annotations indicating the server side filtering may come after the
+	// manifests, which is a no-op if the filter is applied by the server.
+	var referrers []ocispec.Descriptor
+	var fnErr error
 	lr := limitReader(resp.Body, r.MaxMetadataBytes)
-	if err := json.NewDecoder(lr).Decode(&index); err != nil {
+	if err := jsonstream.DecodeIndex(lr, &ocispec.Index{}, func(desc ocispec.Descriptor) error {
+		if artifactType != "" && desc.ArtifactType != artifactType {
+			return nil
+		}
+		referrers = append(referrers, desc)
+		if len(referrers) < referrersBatchSize {
+			return nil
+		}
+		fnErr = fn(referrers)
+		referrers = nil
+		return fnErr
+	}); err != nil {
+		if fnErr != nil {
+			return "", fnErr
+		}
 		return "", fmt.Errorf("%s %q: failed to decode response: %w", resp.Request.Method, resp.Request.URL, err)
 	}
-	referrers := index.Manifests
-	if artifactType != "" && !isReferrersFilterApplied(index.Annotations, "artifactType") {
-		// perform client side filtering if the filter is not applied on the server side
-		referrers = filterReferrers(referrers, artifactType)
-	}
 	if len(referrers) > 0 {
 		if err := fn(referrers); err != nil {
 			return "", err
@@ -505,7 +695,7 @@ func (r *Repository) referrersPageByAPI(ctx context.Context, artifactType string
 // reference: https://github.com/opencontainers/distribution-spec/blob/v1.1.0-rc1/spec.md#backwards-compatibility
 func (r *Repository) referrersByTagSchema(ctx context.Context, desc ocispec.Descriptor, artifactType string, fn func(referrers []ocispec.Descriptor) error) error {
 	referrersTag := buildReferrersTag(desc)
-	_, referrers, err := r.referrersFromIndex(ctx, referrersTag)
+	indexDesc, rc, err := r.FetchReference(ctx, referrersTag)
 	if err != nil {
 		if errors.Is(err, errdef.ErrNotFound) {
 			// no referrers to the manifest
@@ -513,8 +703,26 @@ func (r *Repository) referrersByTagSchema(ctx context.Context, desc ocispec.Desc
 		}
 		return err
 	}
+	defer rc.Close()
+	if err := limitSize(indexDesc, r.MaxMetadataBytes); err != nil {
+		return fmt.Errorf("failed to read referrers index from referrers tag %s: %w", referrersTag, err)
+	}
 
-	filtered := filterReferrers(referrers, artifactType)
+	// decode the referrers index incrementally, only keeping the referrers
+	// of the requested artifact type.
+	var filtered []ocispec.Descriptor
+	vr := content.NewVerifyReader(rc, indexDesc)
+	if err := jsonstream.DecodeIndex(vr, &ocispec.Index{}, func(referrer ocispec.Descriptor) error {
+		if artifactType == "" || referrer.ArtifactType == artifactType {
+			filtered = append(filtered, referrer)
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to decode referrers index from referrers tag %s: %w", referrersTag, err)
+	}
+	if err := vr.Verify(); err != nil {
+		return fmt.Errorf("failed to read referrers index from referrers tag %s: %w", referrersTag, err)
+	}
 	if len(filtered) == 0 {
 		return nil
 	}
@@ -618,6 +826,10 @@ func (r *Repository) delete(ctx context.Context, target ocispec.Descriptor, isMa
 
 	switch resp.StatusCode {
 	case http.StatusAccepted:
+		if isManifest {
+			// deleting a manifest removes its tags
+			r.TagCache.invalidate(r.Reference)
+		}
 		return verifyContentDigest(resp, target.Digest)
 	case http.StatusNotFound:
 		return fmt.Errorf("%s: %w", target.Digest, errdef.ErrNotFound)
@@ -633,6 +845,9 @@ type blobStore struct {
 
 // Fetch fetches the content identified by the descriptor.
 func (s *blobStore) Fetch(ctx context.Context, target ocispec.Descriptor) (rc io.ReadCloser, err error) {
+	if s.repo.downloadConcurrency(ctx) > 1 && target.Size > s.repo.downloadChunkSize() {
+		return s.fetchParallel(ctx, target)
+	}
 	ref := s.repo.Reference
 	ref.Reference = target.Digest.String()
 	ctx = registryutil.WithScopeHint(ctx, ref, auth.ActionPull)
@@ -673,16 +888,83 @@ func (s *blobStore) Fetch(ctx context.Context, target ocispec.Descriptor) (rc io
 	}
 }
 
+// FetchRange fetches length bytes of the blob identified by the descriptor,
+// starting at offset, using a range request.
+// If the server ignores the range request, the content before offset is read
+// and discarded.
+func (s *blobStore) FetchRange(ctx context.Context, target ocispec.Descriptor, offset, length int64) (io.ReadCloser, error) {
+	if offset < 0 || length < 0 || offset+length > target.Size {
+		return nil, fmt.Errorf("%s: %s: invalid range [%d, %d) of size %d", target.Digest, target.MediaType, offset, offset+length, target.Size)
+	}
+	if length == 0 {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+	resp, err := s.requestRange(ctx, target, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusPartialContent {
+		return resp.Body, nil
+	}
+
+	// server does not support range requests.
+	if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.LimitReader(resp.Body, length),
+		Closer: resp.Body,
+	}, nil
+}
+
+// requestRange sends a range request for length bytes of the blob starting at
+// offset. The status code of the returned response is either
+// http.StatusPartialContent, or http.StatusOK if the server ignores the range
+// request.
+func (s *blobStore) requestRange(ctx context.Context, target ocispec.Descriptor, offset, length int64) (*http.Response, error) {
+	ref := s.repo.Reference
+	ref.Reference = target.Digest.String()
+	ctx = registryutil.WithScopeHint(ctx, ref, auth.ActionPull)
+	url := buildRepositoryBlobURL(s.repo.PlainHTTP, ref)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+
+	resp, err := s.repo.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusPartialContent, http.StatusOK:
+		return resp, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %w", target.Digest, errdef.ErrNotFound)
+	default:
+		defer resp.Body.Close()
+		return nil, errutil.ParseErrorResponse(resp)
+	}
+}
+
 // Mount mounts the given descriptor from fromRepo into s.
 func (s *blobStore) Mount(ctx context.Context, desc ocispec.Descriptor, fromRepo string, getContent func() (io.ReadCloser, error)) error {
 	// pushing usually requires both pull and push actions.
 	// Reference: https://github.com/distribution/distribution/blob/v2.7.1/registry/handlers/app.go#L921-L930
 	ctx = registryutil.WithScopeHint(ctx, s.repo.Reference, auth.ActionPull, auth.ActionPush)
 
-	// We also need pull access to the source repo.
-	fromRef := s.repo.Reference
-	fromRef.Repository = fromRepo
-	ctx = registryutil.WithScopeHint(ctx, fromRef, auth.ActionPull)
+	// We also need pull access to the source repo, unless the registry
+	// rejects scopes of other repositories.
+	if !s.repo.quirks().SingleScope {
+		fromRef := s.repo.Reference
+		fromRef.Repository = fromRepo
+		ctx = registryutil.WithScopeHint(ctx, fromRef, auth.ActionPull)
+	}
 
 	url := buildRepositoryBlobMountURL(s.repo.PlainHTTP, s.repo.Reference, desc.Digest, fromRepo)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
@@ -717,6 +999,7 @@ func (s *blobStore) Mount(ctx context.Context, desc ocispec.Descriptor, fromRepo
 	//
 	// [spec]: https://github.com/opencontainers/distribution-spec/blob/main/spec.md#mounting-a-blob-from-another-repository
 
+	logging.FromContext(ctx).Debug("blob not mounted, falling back to push", "digest", desc.Digest, "from", fromRepo, "repository", s.repo.Reference.String())
 	var r io.ReadCloser
 	if getContent != nil {
 		r, err = getContent()
@@ -751,6 +1034,15 @@ func (s *blobStore) sibling(otherRepoName string) *blobStore {
 // - https://docs.docker.com/registry/spec/api/#initiate-blob-upload
 // - https://github.com/opencontainers/distribution-spec/blob/v1.1.0-rc1/spec.md#pushing-a-blob-monolithically
 func (s *blobStore) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	ctx, cancel := override.WithBlobTimeout(ctx)
+	defer cancel()
+	if tracker := registry.UploadTrackerFromContext(ctx); tracker != nil {
+		return s.pushResumable(ctx, expected, content, tracker)
+	}
+	if s.repo.uploadConcurrency(ctx) > 1 && expected.Size > s.repo.uploadChunkSize() {
+		return s.pushParallel(ctx, expected, content)
+	}
+
 	// start an upload
 	// pushing usually requires both pull and push actions.
 	// Reference: https://github.com/distribution/distribution/blob/v2.7.1/registry/handlers/app.go#L921-L930
@@ -778,24 +1070,11 @@ func (s *blobStore) Push(ctx context.Context, expected ocispec.Descriptor, conte
 // Push or by Mount when the receiving repository does not implement the
 // mount endpoint.
 func (s *blobStore) completePushAfterInitialPost(ctx context.Context, req *http.Request, resp *http.Response, expected ocispec.Descriptor, content io.Reader) error {
-	reqHostname := req.URL.Hostname()
-	reqPort := req.URL.Port()
 	// monolithic upload
-	location, err := resp.Location()
+	location, err := uploadLocation(req, resp)
 	if err != nil {
 		return err
 	}
-	// work-around solution for https://github.com/oras-project/oras-go/issues/177
-	// For some registries, if the port 443 is explicitly set to the hostname
-	// like registry.wabbit-networks.io:443/myrepo, blob push will fail since
-	// the hostname of the Location header in the response is set to
-	// registry.wabbit-networks.io instead of registry.wabbit-networks.io:443.
-	locationHostname := location.Hostname()
-	locationPort := location.Port()
-	// if location port 443 is missing, add it back
-	if reqPort == "443" && locationHostname == reqHostname && locationPort == "" {
-		location.Host = locationHostname + ":" + reqPort
-	}
 	url := location.String()
 	req, err = http.NewRequestWithContext(ctx, http.MethodPut, url, content)
 	if err != nil {
@@ -1004,6 +1283,9 @@ func (s *manifestStore) Fetch(ctx context.Context, target ocispec.Descriptor) (r
 	if err := verifyContentDigest(resp, target.Digest); err != nil {
 		return nil, err
 	}
+	if s.repo.Strict {
+		return s.validateManifest(resp, target)
+	}
 	return resp.Body, nil
 }
 
@@ -1094,6 +1376,12 @@ func (s *manifestStore) Resolve(ctx context.Context, reference string) (ocispec.
 		return ocispec.Descriptor{}, err
 	}
 	req.Header.Set("Accept", manifestAcceptHeader(s.repo.ManifestMediaTypes))
+	cache := s.repo.ResolveCache
+	cacheKey := resolveCacheKey(ref, req.Header.Get("Accept"))
+	cached, cachedOK := cache.load(cacheKey)
+	if cachedOK {
+		req.Header.Set("If-None-Match", cached.etag)
+	}
 
 	resp, err := s.repo.client().Do(req)
 	if err != nil {
@@ -1102,15 +1390,58 @@ func (s *manifestStore) Resolve(ctx context.Context, reference string) (ocispec.
 	defer resp.Body.Close()
 
 	switch resp.StatusCode {
+	case http.StatusNotModified:
+		if cachedOK {
+			return cached.desc, nil
+		}
+		return ocispec.Descriptor{}, errutil.ParseErrorResponse(resp)
 	case http.StatusOK:
-		return s.generateDescriptor(resp, ref, req.Method)
+		desc, err := s.generateDescriptor(resp, ref, req.Method)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		cache.store(cacheKey, desc, resp.Header.Get("ETag"))
+		return desc, nil
 	case http.StatusNotFound:
+		cache.delete(cacheKey)
 		return ocispec.Descriptor{}, fmt.Errorf("%s: %w", ref, errdef.ErrNotFound)
 	default:
 		return ocispec.Descriptor{}, errutil.ParseErrorResponse(resp)
 	}
 }
 
+// ExistsReference returns true if the manifest identified by the reference
+// exists, using a HEAD request.
+// The reference can be a tag or digest.
+func (s *manifestStore) ExistsReference(ctx context.Context, reference string) (bool, error) {
+	ref, err := s.repo.ParseReference(reference)
+	if err != nil {
+		return false, err
+	}
+	ctx = registryutil.WithScopeHint(ctx, ref, auth.ActionPull)
+	url := buildRepositoryManifestURL(s.repo.PlainHTTP, ref)
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", manifestAcceptHeader(s.repo.ManifestMediaTypes))
+
+	resp, err := s.repo.client().Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, errutil.ParseErrorResponse(resp)
+	}
+}
+
 // FetchReference fetches the manifest identified by the reference.
 // The reference can be a tag or digest.
 func (s *manifestStore) FetchReference(ctx context.Context, reference string) (desc ocispec.Descriptor, rc io.ReadCloser, err error) {
@@ -1147,6 +1478,13 @@ func (s *manifestStore) FetchReference(ctx context.Context, reference string) (d
 		if err != nil {
 			return ocispec.Descriptor{}, nil, err
 		}
+		if s.repo.Strict {
+			rc, err = s.validateManifest(resp, desc)
+			if err != nil {
+				return ocispec.Descriptor{}, nil, err
+			}
+			return desc, rc, nil
+		}
 		return desc, resp.Body, nil
 	case http.StatusNotFound:
 		return ocispec.Descriptor{}, nil, fmt.Errorf("%s: %w", ref, errdef.ErrNotFound)
@@ -1234,12 +1572,29 @@ func (s *manifestStore) push(ctx context.Context, expected ocispec.Descriptor, c
 	if resp.StatusCode != http.StatusCreated {
 		return errutil.ParseErrorResponse(resp)
 	}
+	if ref.ValidateReferenceAsDigest() != nil {
+		s.repo.TagCache.invalidate(ref)
+	}
 	return verifyContentDigest(resp, expected.Digest)
 }
 
 // pushWithIndexing pushes the manifest content matching the expected descriptor,
 // and indexes referrers for the manifest when needed.
 func (s *manifestStore) pushWithIndexing(ctx context.Context, expected ocispec.Descriptor, r io.Reader, reference string) error {
+	if s.repo.Strict {
+		if err := limitSize(expected, s.repo.MaxMetadataBytes); err != nil {
+			return err
+		}
+		manifestJSON, err := content.ReadAll(r, expected)
+		if err != nil {
+			return err
+		}
+		if err := conformance.ValidateManifest(expected, manifestJSON); err != nil {
+			return err
+		}
+		r = bytes.NewReader(manifestJSON)
+	}
+
 	switch expected.MediaType {
 	case spec.MediaTypeArtifactManifest, ocispec.MediaTypeImageManifest:
 		if state := s.repo.loadReferrersState(); state == referrersStateSupported {
@@ -1409,7 +1764,7 @@ func (s *manifestStore) generateDescriptor(resp *http.Response, ref registry.Ref
 	// 4. Validate Server Digest (if present)
 	var serverHeaderDigest digest.Digest
 	if serverHeaderDigestStr := resp.Header.Get(dockerContentDigestHeader); serverHeaderDigestStr != "" {
-		if serverHeaderDigest, err = digest.Parse(serverHeaderDigestStr); err != nil {
+		if serverHeaderDigest, err = content.ParseDigest(serverHeaderDigestStr); err != nil {
 			return ocispec.Descriptor{}, fmt.Errorf(
 				"%s %q: invalid response header value: `%s: %s`; %w",
 				resp.Request.Method,
@@ -1421,6 +1776,16 @@ func (s *manifestStore) generateDescriptor(resp *http.Response, ref registry.Ref
 		}
 	}
 
+	if s.repo.Strict && len(serverHeaderDigest) == 0 {
+		return ocispec.Descriptor{}, fmt.Errorf(
+			"%s %q: missing response header %q: %w",
+			resp.Request.Method,
+			resp.Request.URL,
+			dockerContentDigestHeader,
+			errdef.ErrNonConformant,
+		)
+	}
+
 	/* 5. Now, look for specific error conditions; see truth table in method docstring */
 	var contentDigest digest.Digest
 
@@ -1440,7 +1805,7 @@ func (s *manifestStore) generateDescriptor(resp *http.Response, ref registry.Ref
 			// GET without server `Docker-Content-Digest` header forces the
 			// expensive calculation
 			var calculatedDigest digest.Digest
-			if calculatedDigest, err = calculateDigestFromResponse(resp, s.repo.MaxMetadataBytes); err != nil {
+			if calculatedDigest, err = calculateDigestFromResponse(resp, s.repo.MaxMetadataBytes, refDigest); err != nil {
 				return ocispec.Descriptor{}, fmt.Errorf("failed to calculate digest on response body; %w", err)
 			}
 			contentDigest = calculatedDigest
@@ -1466,19 +1831,46 @@ func (s *manifestStore) generateDescriptor(resp *http.Response, ref registry.Ref
 	}, nil
 }
 
+// validateManifest reads and validates the manifest described by desc in the
+// response in the strict mode, and returns the reader of the manifest.
+// The response body is closed.
+func (s *manifestStore) validateManifest(resp *http.Response, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	defer resp.Body.Close()
+	if resp.Header.Get(dockerContentDigestHeader) == "" {
+		return nil, fmt.Errorf("%s %q: missing response header %q: %w", resp.Request.Method, resp.Request.URL, dockerContentDigestHeader, errdef.ErrNonConformant)
+	}
+	if err := limitSize(desc, s.repo.MaxMetadataBytes); err != nil {
+		return nil, err
+	}
+	manifestJSON, err := content.ReadAll(resp.Body, desc)
+	if err != nil {
+		return nil, err
+	}
+	if err := conformance.ValidateManifest(desc, manifestJSON); err != nil {
+		return nil, fmt.Errorf("%s %q: %w", resp.Request.Method, resp.Request.URL, err)
+	}
+	return io.NopCloser(bytes.NewReader(manifestJSON)), nil
+}
+
 // calculateDigestFromResponse calculates the actual digest of the response body
 // taking care not to destroy it in the process.
-func calculateDigestFromResponse(resp *http.Response, maxMetadataBytes int64) (digest.Digest, error) {
+// The digest is calculated using the algorithm of the expected digest if
+// specified, or the canonical algorithm.
+func calculateDigestFromResponse(resp *http.Response, maxMetadataBytes int64, expected digest.Digest) (digest.Digest, error) {
 	defer resp.Body.Close()
 
 	body := limitReader(resp.Body, maxMetadataBytes)
-	content, err := io.ReadAll(body)
+	data, err := io.ReadAll(body)
 	if err != nil {
 		return "", fmt.Errorf("%s %q: failed to read response body: %w", resp.Request.Method, resp.Request.URL, err)
 	}
-	resp.Body = io.NopCloser(bytes.NewReader(content))
+	resp.Body = io.NopCloser(bytes.NewReader(data))
 
-	return digest.FromBytes(content), nil
+	alg := digest.Canonical
+	if expected != "" {
+		alg = expected.Algorithm()
+	}
+	return content.ComputeDigest(alg, data)
 }
 
 // verifyContentDigest verifies "Docker-Content-Digest" header if present.
@@ -1491,7 +1883,7 @@ func verifyContentDigest(resp *http.Response, expected digest.Digest) error {
 		return nil
 	}
 
-	contentDigest, err := digest.Parse(digestStr)
+	contentDigest, err := content.ParseDigest(digestStr)
 	if err != nil {
 		return fmt.Errorf(
 			"%s %q: invalid response header: `%s: %s`",
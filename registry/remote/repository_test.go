@@ -751,6 +751,57 @@ func TestRepository_Delete(t *testing.T) {
 	}
 }
 
+func TestRepository_ExistsReference(t *testing.T) {
+	ref := "foobar"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/test/manifests/" + ref:
+			// no digest header is required
+			w.Header().Set("Content-Type", ocispec.MediaTypeImageIndex)
+		case "/v2/test/manifests/missing":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	repo.Client = http.DefaultClient
+	ctx := context.Background()
+
+	exists, err := repo.ExistsReference(ctx, ref)
+	if err != nil {
+		t.Fatalf("Repository.ExistsReference() error = %v", err)
+	}
+	if !exists {
+		t.Errorf("Repository.ExistsReference() = %v, want %v", exists, true)
+	}
+	exists, err = repo.ExistsReference(ctx, "missing")
+	if err != nil {
+		t.Fatalf("Repository.ExistsReference() error = %v", err)
+	}
+	if exists {
+		t.Errorf("Repository.ExistsReference() = %v, want %v", exists, false)
+	}
+	if _, err := repo.ExistsReference(ctx, "broken"); err == nil {
+		t.Error("Repository.ExistsReference() error = nil, want error")
+	}
+}
+
 func TestRepository_Resolve(t *testing.T) {
 	blob := []byte("hello world")
 	blobDesc := ocispec.Descriptor{
@@ -2110,6 +2161,108 @@ func TestRepository_Referrers_ClientFiltering(t *testing.T) {
 	}
 }
 
+func TestRepository_Referrers_LargeList(t *testing.T) {
+	manifest := []byte(`{"layers":[]}`)
+	manifestDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(manifest),
+		Size:      int64(len(manifest)),
+	}
+	count := 2*referrersBatchSize + 100
+	var referrers []ocispec.Descriptor
+	for i := 0; i < count; i++ {
+		artifactType := "application/vnd.test"
+		if i%2 == 1 {
+			artifactType = "application/vnd.foo"
+		}
+		referrers = append(referrers, ocispec.Descriptor{
+			MediaType:    ocispec.MediaTypeImageManifest,
+			Size:         int64(i),
+			Digest:       digest.FromString(strconv.Itoa(i)),
+			ArtifactType: artifactType,
+		})
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := "/v2/test/referrers/" + manifestDesc.Digest.String()
+		if r.Method != http.MethodGet || r.URL.Path != path {
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		result := ocispec.Index{
+			Versioned: specs.Versioned{
+				SchemaVersion: 2, // historical value. does not pertain to OCI or docker version
+			},
+			MediaType: ocispec.MediaTypeImageIndex,
+			Manifests: referrers,
+		}
+		w.Header().Set("Content-Type", ocispec.MediaTypeImageIndex)
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			t.Errorf("failed to write response: %v", err)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	repo.SetReferrersCapability(true)
+	ctx := context.Background()
+
+	// referrers are fed in batches
+	var got []ocispec.Descriptor
+	var batches []int
+	if err := repo.Referrers(ctx, manifestDesc, "", func(refs []ocispec.Descriptor) error {
+		got = append(got, refs...)
+		batches = append(batches, len(refs))
+		return nil
+	}); err != nil {
+		t.Fatalf("Repository.Referrers() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, referrers) {
+		t.Errorf("Repository.Referrers() got %d referrers, want %d", len(got), len(referrers))
+	}
+	if want := []int{referrersBatchSize, referrersBatchSize, 100}; !reflect.DeepEqual(batches, want) {
+		t.Errorf("Repository.Referrers() batches = %v, want %v", batches, want)
+	}
+
+	// referrers are filtered while decoding
+	got = nil
+	if err := repo.Referrers(ctx, manifestDesc, "application/vnd.test", func(refs []ocispec.Descriptor) error {
+		got = append(got, refs...)
+		return nil
+	}); err != nil {
+		t.Fatalf("Repository.Referrers() error = %v", err)
+	}
+	if want := (count + 1) / 2; len(got) != want {
+		t.Errorf("Repository.Referrers() got %d referrers, want %d", len(got), want)
+	}
+	for _, ref := range got {
+		if ref.ArtifactType != "application/vnd.test" {
+			t.Errorf("Repository.Referrers() got unexpected referrer %v", ref)
+		}
+	}
+
+	// errors returned by fn stop listing
+	errStop := errors.New("stop")
+	var calls int
+	if err := repo.Referrers(ctx, manifestDesc, "", func(refs []ocispec.Descriptor) error {
+		calls++
+		return errStop
+	}); err != errStop {
+		t.Errorf("Repository.Referrers() error = %v, want %v", err, errStop)
+	}
+	if calls != 1 {
+		t.Errorf("Repository.Referrers() called fn %d times, want 1", calls)
+	}
+}
+
 func TestRepository_Referrers_TagSchemaFallback_ClientFiltering(t *testing.T) {
 	manifest := []byte(`{"layers":[]}`)
 	manifestDesc := ocispec.Descriptor{
@@ -5872,3 +6025,222 @@ func TestRepository_pingReferrers_Concurrent(t *testing.T) {
 		t.Errorf("Repository.loadReferrersState() = %v, want %v", state, referrersStateSupported)
 	}
 }
+
+func TestRepository_FetchRange(t *testing.T) {
+	blob := []byte("hello world")
+	blobDesc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+	}
+	index := []byte(`{"manifests":[]}`)
+	indexDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Digest:    digest.FromBytes(index),
+		Size:      int64(len(index)),
+	}
+	ignoreRange := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/test/blobs/" + blobDesc.Digest.String():
+			w.Header().Set("Content-Type", "application/octet-stream")
+			if ignoreRange {
+				w.Write(blob)
+				return
+			}
+			var start, end int
+			if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); err != nil {
+				t.Errorf("invalid range header: %q", r.Header.Get("Range"))
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(blob)))
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(blob[start : end+1])
+		case "/v2/test/manifests/" + indexDesc.Digest.String():
+			w.Header().Set("Content-Type", indexDesc.MediaType)
+			w.Header().Set("Docker-Content-Digest", indexDesc.Digest.String())
+			w.Write(index)
+		default:
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	ctx := context.Background()
+
+	fetchRange := func(desc ocispec.Descriptor, offset, length int64) string {
+		rc, err := repo.FetchRange(ctx, desc, offset, length)
+		if err != nil {
+			t.Fatalf("Repository.FetchRange() error = %v", err)
+		}
+		defer rc.Close()
+		got, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatalf("fail to read: %v", err)
+		}
+		return string(got)
+	}
+	if got, want := fetchRange(blobDesc, 6, 5), "world"; got != want {
+		t.Errorf("Repository.FetchRange() = %q, want %q", got, want)
+	}
+	ignoreRange = true
+	if got, want := fetchRange(blobDesc, 2, 3), "llo"; got != want {
+		t.Errorf("Repository.FetchRange() = %q, want %q", got, want)
+	}
+	if got, want := fetchRange(indexDesc, 1, 9), `"manifest`; got != want {
+		t.Errorf("Repository.FetchRange() = %q, want %q", got, want)
+	}
+	if _, err := repo.FetchRange(ctx, blobDesc, 10, 2); err == nil {
+		t.Error("Repository.FetchRange() error = nil, want invalid range error")
+	}
+}
+
+func TestRepository_Strict(t *testing.T) {
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[]}`)
+	manifestDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Digest:    digest.FromBytes(manifest),
+		Size:      int64(len(manifest)),
+	}
+	badManifest := []byte(`{"manifests":[]}`)
+	badManifestDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Digest:    digest.FromBytes(badManifest),
+		Size:      int64(len(badManifest)),
+	}
+	omitDigest := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var content []byte
+		var desc ocispec.Descriptor
+		switch r.URL.Path {
+		case "/v2/test/manifests/" + manifestDesc.Digest.String(), "/v2/test/manifests/good":
+			content, desc = manifest, manifestDesc
+		case "/v2/test/manifests/" + badManifestDesc.Digest.String(), "/v2/test/manifests/bad":
+			content, desc = badManifest, badManifestDesc
+		default:
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			w.Header().Set("Content-Type", desc.MediaType)
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			if !omitDigest {
+				w.Header().Set("Docker-Content-Digest", desc.Digest.String())
+			}
+			if r.Method == http.MethodGet {
+				w.Write(content)
+			}
+		case http.MethodPut:
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	ctx := context.Background()
+
+	// non-strict mode tolerates non-conformant manifests
+	if _, err := repo.Fetch(ctx, badManifestDesc); err != nil {
+		t.Fatalf("Repository.Fetch() error = %v", err)
+	}
+
+	repo.Strict = true
+	if _, err := content.FetchAll(ctx, repo, manifestDesc); err != nil {
+		t.Errorf("Repository.Fetch() error = %v", err)
+	}
+	if _, err := repo.Fetch(ctx, badManifestDesc); !errors.Is(err, errdef.ErrNonConformant) {
+		t.Errorf("Repository.Fetch() error = %v, want %v", err, errdef.ErrNonConformant)
+	}
+	if _, _, err := repo.FetchReference(ctx, "bad"); !errors.Is(err, errdef.ErrNonConformant) {
+		t.Errorf("Repository.FetchReference() error = %v, want %v", err, errdef.ErrNonConformant)
+	}
+	if err := repo.Push(ctx, badManifestDesc, bytes.NewReader(badManifest)); !errors.Is(err, errdef.ErrNonConformant) {
+		t.Errorf("Repository.Push() error = %v, want %v", err, errdef.ErrNonConformant)
+	}
+	if err := repo.Push(ctx, manifestDesc, bytes.NewReader(manifest)); err != nil {
+		t.Errorf("Repository.Push() error = %v", err)
+	}
+
+	// missing Docker-Content-Digest
+	omitDigest = true
+	if _, err := repo.Resolve(ctx, "good"); !errors.Is(err, errdef.ErrNonConformant) {
+		t.Errorf("Repository.Resolve() error = %v, want %v", err, errdef.ErrNonConformant)
+	}
+	if _, err := repo.Fetch(ctx, manifestDesc); !errors.Is(err, errdef.ErrNonConformant) {
+		t.Errorf("Repository.Fetch() error = %v, want %v", err, errdef.ErrNonConformant)
+	}
+}
+
+func TestNewMappedRepository(t *testing.T) {
+	mapping, err := registry.NewMapping("docker.io/library/*=registry.corp/mirror/*")
+	if err != nil {
+		t.Fatal("registry.NewMapping() error =", err)
+	}
+	opts := &RepositoryOptions{
+		PlainHTTP:       true,
+		TagListPageSize: 10,
+	}
+
+	repo, err := NewMappedRepository("docker.io/library/alpine:3.18", mapping, opts)
+	if err != nil {
+		t.Fatal("NewMappedRepository() error =", err)
+	}
+	want := registry.Reference{
+		Registry:   "registry.corp",
+		Repository: "mirror/alpine",
+		Reference:  "3.18",
+	}
+	if repo.Reference != want {
+		t.Errorf("Repository.Reference = %v, want %v", repo.Reference, want)
+	}
+	if !repo.PlainHTTP || repo.TagListPageSize != 10 {
+		t.Errorf("Repository options = %v, %v, want %v, %v", repo.PlainHTTP, repo.TagListPageSize, true, 10)
+	}
+
+	// test without options
+	repo, err = NewMappedRepository("docker.io/library/alpine", mapping, nil)
+	if err != nil {
+		t.Fatal("NewMappedRepository() error =", err)
+	}
+	if got := repo.Reference.String(); got != "registry.corp/mirror/alpine" {
+		t.Errorf("Repository.Reference = %v, want %v", got, "registry.corp/mirror/alpine")
+	}
+
+	// test no matching rule
+	if _, err := NewMappedRepository("ghcr.io/foo/bar", mapping, nil); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("NewMappedRepository() error = %v, wantErr %v", err, errdef.ErrNotFound)
+	}
+	// test invalid source
+	if _, err := NewMappedRepository("invalid", mapping, nil); !errors.Is(err, errdef.ErrInvalidReference) {
+		t.Errorf("NewMappedRepository() error = %v, wantErr %v", err, errdef.ErrInvalidReference)
+	}
+}
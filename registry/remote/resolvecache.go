@@ -0,0 +1,108 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"strconv"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry"
+)
+
+// ResolveCache caches the descriptors resolved by Repository.Resolve.
+//
+// The cached descriptors are revalidated by conditional HEAD requests with
+// the `If-None-Match` header, carrying the ETag returned by the registry or,
+// if absent, the quoted digest of the manifest, which is the ETag of the
+// manifests served by the distribution implementation. A registry responding
+// 304 Not Modified confirms the cached descriptor, while registries ignoring
+// the header respond the descriptor as usual.
+//
+// A ResolveCache is safe for concurrent use, and can be shared by the
+// repositories, e.g. via Registry.RepositoryOptions.
+type ResolveCache struct {
+	lock    sync.RWMutex
+	entries map[string]resolveCacheEntry
+}
+
+// resolveCacheEntry is a cached descriptor with its ETag.
+type resolveCacheEntry struct {
+	desc ocispec.Descriptor
+	etag string
+}
+
+// NewResolveCache creates a new ResolveCache.
+func NewResolveCache() *ResolveCache {
+	return &ResolveCache{
+		entries: make(map[string]resolveCacheEntry),
+	}
+}
+
+// Purge removes all the cached descriptors.
+func (c *ResolveCache) Purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.entries = make(map[string]resolveCacheEntry)
+}
+
+// load returns the cached entry of the key.
+// A nil cache has no entries.
+func (c *ResolveCache) load(key string) (resolveCacheEntry, bool) {
+	if c == nil {
+		return resolveCacheEntry{}, false
+	}
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	entry, ok := c.entries[key]
+	return entry, ok
+}
+
+// store caches the descriptor of the key with its ETag.
+func (c *ResolveCache) store(key string, desc ocispec.Descriptor, etag string) {
+	if c == nil {
+		return
+	}
+	if etag == "" {
+		etag = strconv.Quote(desc.Digest.String())
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]resolveCacheEntry)
+	}
+	c.entries[key] = resolveCacheEntry{
+		desc: desc,
+		etag: etag,
+	}
+}
+
+// delete removes the cached entry of the key.
+func (c *ResolveCache) delete(key string) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.entries, key)
+}
+
+// resolveCacheKey returns the cache key of the reference resolved with the
+// Accept header, since registries may resolve the same reference to
+// different manifests by the accepted media types.
+func resolveCacheKey(ref registry.Reference, accept string) string {
+	return ref.String() + " " + accept
+}
@@ -0,0 +1,137 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestRepository_Resolve_Cache(t *testing.T) {
+	manifest := []byte(`{"layers":[]}`)
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifest)
+	newManifest := []byte(`{"layers":[],"annotations":{"foo":"bar"}}`)
+	newDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, newManifest)
+
+	current := atomic.Value{}
+	current.Store(desc)
+	var withETag, deleted atomic.Bool
+	var fullCount, notModifiedCount int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead || r.URL.Path != "/v2/test/manifests/latest" {
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if deleted.Load() {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		desc := current.Load().(ocispec.Descriptor)
+		etag := strconv.Quote(desc.Digest.String())
+		if withETag.Load() {
+			etag = `W/"custom-` + desc.Digest.Encoded()[:8] + `"`
+			w.Header().Set("ETag", etag)
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			atomic.AddInt32(&notModifiedCount, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&fullCount, 1)
+		w.Header().Set("Content-Type", desc.MediaType)
+		w.Header().Set("Content-Length", strconv.FormatInt(desc.Size, 10))
+		w.Header().Set("Docker-Content-Digest", desc.Digest.String())
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	repo.Client = http.DefaultClient
+	repo.ResolveCache = NewResolveCache()
+	ctx := context.Background()
+	resolve := func(want ocispec.Descriptor) {
+		t.Helper()
+		got, err := repo.Resolve(ctx, "latest")
+		if err != nil {
+			t.Fatalf("Repository.Resolve() error = %v", err)
+		}
+		if !content.Equal(got, want) {
+			t.Errorf("Repository.Resolve() = %v, want %v", got, want)
+		}
+	}
+	checkCount := func(wantFull, wantNotModified int32) {
+		t.Helper()
+		if got := atomic.SwapInt32(&fullCount, 0); got != wantFull {
+			t.Errorf("full responses = %d, want %d", got, wantFull)
+		}
+		if got := atomic.SwapInt32(&notModifiedCount, 0); got != wantNotModified {
+			t.Errorf("not modified responses = %d, want %d", got, wantNotModified)
+		}
+	}
+
+	// revalidated by the digest
+	resolve(desc)
+	checkCount(1, 0)
+	resolve(desc)
+	resolve(desc)
+	checkCount(0, 2)
+
+	// tag updated
+	current.Store(newDesc)
+	resolve(newDesc)
+	checkCount(1, 0)
+	resolve(newDesc)
+	checkCount(0, 1)
+
+	// revalidated by the ETag
+	withETag.Store(true)
+	resolve(newDesc)
+	checkCount(1, 0)
+	resolve(newDesc)
+	checkCount(0, 1)
+
+	// tag deleted
+	deleted.Store(true)
+	if _, err := repo.Resolve(ctx, "latest"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Fatalf("Repository.Resolve() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+	deleted.Store(false)
+	resolve(newDesc)
+	checkCount(1, 0)
+
+	// purged
+	repo.ResolveCache.Purge()
+	resolve(newDesc)
+	checkCount(1, 0)
+}
@@ -18,6 +18,8 @@ package retry
 import (
 	"net/http"
 	"time"
+
+	"oras.land/oras-go/v2/logging"
 )
 
 // DefaultClient is a client with the default retry policy.
@@ -85,7 +87,10 @@ func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 
 		// close the response body if needed
 		if respErr == nil {
+			logging.FromContext(ctx).Info("retrying request", "method", req.Method, "url", req.URL.Redacted(), "attempt", attempt+1, "backoff", duration, "status", resp.StatusCode)
 			resp.Body.Close()
+		} else {
+			logging.FromContext(ctx).Info("retrying request", "method", req.Method, "url", req.URL.Redacted(), "attempt", attempt+1, "backoff", duration, "error", respErr)
 		}
 
 		timer := time.NewTimer(duration)
@@ -17,9 +17,12 @@ package retry
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"oras.land/oras-go/v2/logging"
 )
 
 func Test_Client(t *testing.T) {
@@ -95,3 +98,43 @@ func Test_Client(t *testing.T) {
 		})
 	}
 }
+
+type retryLogger struct {
+	retries int
+}
+
+func (l *retryLogger) Debug(msg string, keysAndValues ...interface{}) {}
+
+func (l *retryLogger) Info(msg string, keysAndValues ...interface{}) {
+	if msg == "retrying request" {
+		l.retries++
+	}
+}
+
+func Test_Client_Logging(t *testing.T) {
+	count := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		if count < 3 {
+			http.Error(w, "error", http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	logger := &retryLogger{}
+	ctx := logging.WithLogger(context.Background(), logger)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+	if err != nil {
+		t.Fatalf("failed to create test request: %v", err)
+	}
+	resp, err := DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to do test request: %v", err)
+	}
+	resp.Body.Close()
+	if want := 2; logger.retries != want {
+		t.Errorf("logged retries = %d, want %d", logger.retries, want)
+	}
+}
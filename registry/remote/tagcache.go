@@ -0,0 +1,169 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"oras.land/oras-go/v2/registry"
+)
+
+// defaultTagCacheTTL is the default time-to-live of cached tag lists.
+const defaultTagCacheTTL = time.Minute
+
+// TagCache caches the tag lists of repositories listed by Repository.Tags,
+// so that polling many unchanged repositories does not list their tags
+// repeatedly.
+//
+// A cached tag list expires after TTL, and is invalidated when the
+// repository is tagged or a manifest is deleted through a Repository sharing
+// the cache. Changes made by other clients are observed only after the cached
+// list expires, or after an explicit Invalidate.
+//
+// A TagCache is safe for concurrent use, and can be shared by the
+// repositories, e.g. via Registry.RepositoryOptions.
+type TagCache struct {
+	// TTL specifies how long a tag list is cached.
+	// If less than or equal to zero, a default (currently 1 minute) is used.
+	TTL time.Duration
+
+	lock    sync.Mutex
+	entries map[string]tagCacheEntry
+	now     func() time.Time
+}
+
+// tagCacheEntry is a cached tag list with its expiration time.
+type tagCacheEntry struct {
+	tags    []string
+	expires time.Time
+}
+
+// NewTagCache creates a new TagCache with the given TTL.
+func NewTagCache(ttl time.Duration) *TagCache {
+	return &TagCache{
+		TTL:     ttl,
+		entries: make(map[string]tagCacheEntry),
+	}
+}
+
+// Invalidate removes the cached tag list of the repository, such as
+// "localhost:5000/hello-world".
+func (c *TagCache) Invalidate(repository string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.entries, repository)
+}
+
+// Purge removes all the cached tag lists.
+func (c *TagCache) Purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.entries = make(map[string]tagCacheEntry)
+}
+
+// load returns the cached tag list of the repository if not expired.
+// A nil cache has no entries.
+func (c *TagCache) load(ref registry.Reference) ([]string, bool) {
+	if c == nil {
+		return nil, false
+	}
+	key := tagCacheKey(ref)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.timeNow().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.tags, true
+}
+
+// store caches the tag list of the repository, which must be sorted.
+func (c *TagCache) store(ref registry.Reference, tags []string) {
+	if c == nil {
+		return
+	}
+	ttl := c.TTL
+	if ttl <= 0 {
+		ttl = defaultTagCacheTTL
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]tagCacheEntry)
+	}
+	c.entries[tagCacheKey(ref)] = tagCacheEntry{
+		tags:    tags,
+		expires: c.timeNow().Add(ttl),
+	}
+}
+
+// invalidate removes the cached tag list of the repository.
+func (c *TagCache) invalidate(ref registry.Reference) {
+	if c == nil {
+		return
+	}
+	c.Invalidate(tagCacheKey(ref))
+}
+
+// timeNow returns the current time.
+func (c *TagCache) timeNow() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// tagCacheKey returns the cache key of the repository.
+func tagCacheKey(ref registry.Reference) string {
+	ref.Reference = ""
+	return ref.String()
+}
+
+// tagsFromCache serves the tag list in pages of pageSize, starting after
+// the tag last.
+func tagsFromCache(tags []string, last string, pageSize int, fn func(tags []string) error) error {
+	if last != "" {
+		// tags are listed in lexical order as specified by the
+		// distribution spec.
+		i := sort.Search(len(tags), func(i int) bool {
+			return tags[i] > last
+		})
+		tags = tags[i:]
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	if pageSize <= 0 {
+		pageSize = len(tags)
+	}
+	for len(tags) > 0 {
+		n := pageSize
+		if n > len(tags) {
+			n = len(tags)
+		}
+		if err := fn(tags[:n:n]); err != nil {
+			return err
+		}
+		tags = tags[n:]
+	}
+	return nil
+}
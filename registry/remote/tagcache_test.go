@@ -0,0 +1,137 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestRepository_Tags_TagCache(t *testing.T) {
+	manifest := []byte(`{"layers":[]}`)
+	manifestDesc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(manifest),
+		Size:      int64(len(manifest)),
+	}
+	tags := []string{"v3", "v1", "v2"}
+	var listCount int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/test/tags/list":
+			atomic.AddInt64(&listCount, 1)
+			result := struct {
+				Tags []string `json:"tags"`
+			}{
+				Tags: tags,
+			}
+			if err := json.NewEncoder(w).Encode(result); err != nil {
+				t.Errorf("failed to write response: %v", err)
+			}
+		case r.Method == http.MethodPut && r.URL.Path == "/v2/test/manifests/v4":
+			tags = append(tags, "v4")
+			w.Header().Set("Docker-Content-Digest", manifestDesc.Digest.String())
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	now := time.Now()
+	cache := NewTagCache(time.Minute)
+	cache.now = func() time.Time { return now }
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	repo.TagCache = cache
+	ctx := context.Background()
+
+	listTags := func(last string) [][]string {
+		var pages [][]string
+		if err := repo.Tags(ctx, last, func(tags []string) error {
+			pages = append(pages, tags)
+			return nil
+		}); err != nil {
+			t.Fatalf("Repository.Tags() error = %v", err)
+		}
+		return pages
+	}
+	checkCount := func(want int64) {
+		t.Helper()
+		if got := atomic.LoadInt64(&listCount); got != want {
+			t.Errorf("number of tag list requests = %d, want %d", got, want)
+		}
+	}
+
+	// first listing hits the registry
+	if got, want := listTags(""), [][]string{{"v1", "v2", "v3"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Repository.Tags() = %v, want %v", got, want)
+	}
+	checkCount(1)
+
+	// repeated listing is served from the cache with pagination
+	repo.TagListPageSize = 1
+	if got, want := listTags("v1"), [][]string{{"v2"}, {"v3"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Repository.Tags() = %v, want %v", got, want)
+	}
+	checkCount(1)
+	repo.TagListPageSize = 0
+
+	// tagging invalidates the cache
+	if err := repo.PushReference(ctx, manifestDesc, bytes.NewReader(manifest), "v4"); err != nil {
+		t.Fatalf("Repository.PushReference() error = %v", err)
+	}
+	if got, want := listTags(""), [][]string{{"v1", "v2", "v3", "v4"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Repository.Tags() = %v, want %v", got, want)
+	}
+	checkCount(2)
+
+	// explicit invalidation
+	cache.Invalidate(uri.Host + "/test")
+	listTags("")
+	checkCount(3)
+
+	// cached tag lists expire after TTL
+	listTags("")
+	checkCount(3)
+	now = now.Add(time.Minute)
+	listTags("")
+	checkCount(4)
+
+	// purge
+	cache.Purge()
+	listTags("")
+	checkCount(5)
+}
@@ -0,0 +1,402 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/internal/registryutil"
+	"oras.land/oras-go/v2/internal/syncutil"
+	"oras.land/oras-go/v2/logging"
+	"oras.land/oras-go/v2/override"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/errcode"
+	"oras.land/oras-go/v2/registry/remote/internal/errutil"
+)
+
+// defaultUploadChunkSize is the default chunk size of resumable blob uploads.
+const defaultUploadChunkSize int64 = 8 * 1024 * 1024 // 8 MiB
+
+// pushResumable pushes the content in chunks, resuming the saved upload
+// session if any.
+// References:
+//   - https://github.com/opencontainers/distribution-spec/blob/v1.1.0-rc1/spec.md#pushing-a-blob-in-chunks
+//   - https://docs.docker.com/registry/spec/api/#upload-progress
+func (s *blobStore) pushResumable(ctx context.Context, expected ocispec.Descriptor, content io.Reader, tracker registry.UploadTracker) error {
+	ctx = registryutil.WithScopeHint(ctx, s.repo.Reference, auth.ActionPull, auth.ActionPush)
+	session, ok, err := tracker.LoadUpload(ctx, expected)
+	if err != nil {
+		return err
+	}
+	if ok && session.Digest != "" && session.Digest != expected.Digest {
+		logging.FromContext(ctx).Info("ignoring upload session of another blob", "digest", expected.Digest, "session", session.Digest)
+		ok = false
+	}
+	if ok {
+		offset, err := s.uploadStatus(ctx, session.Location)
+		if err != nil {
+			// the session may have expired, so start over
+			logging.FromContext(ctx).Info("failed to resume upload, restarting", "digest", expected.Digest, "error", err)
+			ok = false
+		} else {
+			session.Offset = offset
+		}
+	}
+	if !ok {
+		location, err := s.startUpload(ctx)
+		if err != nil {
+			return err
+		}
+		session = registry.UploadSession{Location: location, Digest: expected.Digest}
+		if err := tracker.SaveUpload(ctx, expected, session); err != nil {
+			return err
+		}
+	}
+
+	// skip the uploaded bytes
+	if session.Offset > 0 {
+		logging.FromContext(ctx).Info("resuming upload", "digest", expected.Digest, "offset", session.Offset)
+		if _, err := io.CopyN(io.Discard, content, session.Offset); err != nil {
+			return err
+		}
+	}
+	chunkSize := s.repo.uploadChunkSize()
+	for session.Offset < expected.Size {
+		n := expected.Size - session.Offset
+		if n > chunkSize {
+			n = chunkSize
+		}
+		location, err := s.uploadChunk(ctx, session.Location, io.LimitReader(content, n), session.Offset, n)
+		if err != nil {
+			return err
+		}
+		session = registry.UploadSession{Location: location, Offset: session.Offset + n, Digest: expected.Digest}
+		if err := tracker.SaveUpload(ctx, expected, session); err != nil {
+			return err
+		}
+	}
+
+	if err := s.completeUpload(ctx, session.Location, expected); err != nil {
+		return err
+	}
+	return tracker.DeleteUpload(ctx, expected)
+}
+
+// pushParallel pushes the content in chunks, uploading the chunks
+// concurrently over PATCH requests to the same upload session.
+// Since the distribution spec requires chunks to be uploaded in order, the
+// support of out-of-order chunks is probed by uploading the third chunk
+// before the second one. If the registry rejects the out-of-order chunk, the
+// remaining chunks are uploaded sequentially.
+func (s *blobStore) pushParallel(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	ctx = registryutil.WithScopeHint(ctx, s.repo.Reference, auth.ActionPull, auth.ActionPush)
+	location, err := s.startUpload(ctx)
+	if err != nil {
+		return err
+	}
+	chunkSize := s.repo.uploadChunkSize()
+	chunks := &chunkReader{r: content, size: expected.Size, chunkSize: chunkSize}
+
+	// upload the first chunk to obtain the location of the upload session
+	first, err := chunks.next()
+	if err != nil {
+		return err
+	}
+	if location, err = s.uploadChunk(ctx, location, bytes.NewReader(first.data), first.offset, first.length()); err != nil {
+		return err
+	}
+	if chunks.offset < expected.Size {
+		if location, err = s.uploadRemainingChunks(ctx, location, chunks); err != nil {
+			return err
+		}
+	}
+	return s.completeUpload(ctx, location, expected)
+}
+
+// uploadRemainingChunks uploads the remaining chunks, concurrently if the
+// registry accepts out-of-order chunks, and returns the location of the
+// upload session for the completing request.
+func (s *blobStore) uploadRemainingChunks(ctx context.Context, location string, chunks *chunkReader) (string, error) {
+	second, err := chunks.next()
+	if err != nil {
+		return "", err
+	}
+	if chunks.offset >= chunks.size {
+		return s.uploadChunk(ctx, location, bytes.NewReader(second.data), second.offset, second.length())
+	}
+	third, err := chunks.next()
+	if err != nil {
+		return "", err
+	}
+
+	// probe the support of out-of-order chunks
+	probed, err := s.uploadChunk(ctx, location, bytes.NewReader(third.data), third.offset, third.length())
+	if err != nil {
+		var errResp *errcode.ErrorResponse
+		if !errors.As(err, &errResp) {
+			return "", err
+		}
+		// the session must be intact to fall back to sequential upload
+		if offset, statusErr := s.uploadStatus(ctx, location); statusErr != nil || offset != second.offset {
+			return "", err
+		}
+		logging.FromContext(ctx).Info("out-of-order chunks not supported, uploading sequentially", "error", err)
+		for _, chunk := range []contentChunk{second, third} {
+			if location, err = s.uploadChunk(ctx, location, bytes.NewReader(chunk.data), chunk.offset, chunk.length()); err != nil {
+				return "", err
+			}
+		}
+		for chunks.offset < chunks.size {
+			chunk, err := chunks.next()
+			if err != nil {
+				return "", err
+			}
+			if location, err = s.uploadChunk(ctx, location, bytes.NewReader(chunk.data), chunk.offset, chunk.length()); err != nil {
+				return "", err
+			}
+		}
+		return location, nil
+	}
+
+	// the registry accepts out-of-order chunks, so upload the remaining
+	// chunks concurrently
+	location = probed
+	eg, egCtx := syncutil.LimitGroup(ctx, s.repo.uploadConcurrency(ctx))
+	upload := func(chunk contentChunk) {
+		eg.Go(func() error {
+			_, err := s.uploadChunk(egCtx, location, bytes.NewReader(chunk.data), chunk.offset, chunk.length())
+			return err
+		})
+	}
+	upload(second)
+	for chunks.offset < chunks.size && egCtx.Err() == nil {
+		chunk, err := chunks.next()
+		if err != nil {
+			eg.Go(func() error {
+				return err
+			})
+			break
+		}
+		upload(chunk)
+	}
+	if err := eg.Wait(); err != nil {
+		return "", err
+	}
+	return location, nil
+}
+
+// contentChunk is a chunk of the content read by chunkReader.
+type contentChunk struct {
+	offset int64
+	data   []byte
+}
+
+// length returns the length of the chunk.
+func (c contentChunk) length() int64 {
+	return int64(len(c.data))
+}
+
+// chunkReader reads the content in chunks.
+type chunkReader struct {
+	r         io.Reader
+	size      int64
+	chunkSize int64
+	offset    int64
+}
+
+// next reads the next chunk of the content.
+func (cr *chunkReader) next() (contentChunk, error) {
+	n := cr.size - cr.offset
+	if n > cr.chunkSize {
+		n = cr.chunkSize
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(cr.r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return contentChunk{}, err
+	}
+	chunk := contentChunk{offset: cr.offset, data: data}
+	cr.offset += n
+	return chunk, nil
+}
+
+// startUpload starts an upload session, and returns its location.
+func (s *blobStore) startUpload(ctx context.Context) (string, error) {
+	url := buildRepositoryBlobUploadURL(s.repo.PlainHTTP, s.repo.Reference)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := s.repo.client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return "", errutil.ParseErrorResponse(resp)
+	}
+	location, err := uploadLocation(req, resp)
+	if err != nil {
+		return "", err
+	}
+	return location.String(), nil
+}
+
+// uploadStatus returns the number of bytes received by the upload session.
+func (s *blobStore) uploadStatus(ctx context.Context, location string) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := s.repo.client().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return 0, errutil.ParseErrorResponse(resp)
+	}
+	return parseUploadRange(resp.Header.Get("Range"))
+}
+
+// uploadChunk uploads a chunk of size n starting at offset, and returns the
+// location of the upload session for the next request.
+func (s *blobStore) uploadChunk(ctx context.Context, location string, chunk io.Reader, offset, n int64) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, location, chunk)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = n
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Range", fmt.Sprintf("%d-%d", offset, offset+n-1))
+	resp, err := s.repo.client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return "", errutil.ParseErrorResponse(resp)
+	}
+	next, err := uploadLocation(req, resp)
+	if err != nil {
+		return "", err
+	}
+	return next.String(), nil
+}
+
+// completeUpload completes the upload session.
+func (s *blobStore) completeUpload(ctx context.Context, location string, expected ocispec.Descriptor) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, location, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	q := req.URL.Query()
+	q.Set("digest", expected.Digest.String())
+	req.URL.RawQuery = q.Encode()
+	resp, err := s.repo.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return errutil.ParseErrorResponse(resp)
+	}
+	return nil
+}
+
+// uploadConcurrency returns the number of chunks uploaded concurrently,
+// honoring the concurrency override in the context once parallel uploads are
+// enabled by UploadConcurrency.
+func (r *Repository) uploadConcurrency(ctx context.Context) int {
+	if r.quirks().NoParallelUpload {
+		return 1
+	}
+	if r.UploadConcurrency <= 1 {
+		// parallel uploads are opt-in, and not enabled by the override
+		return r.UploadConcurrency
+	}
+	if concurrency := override.FromContext(ctx).Concurrency; concurrency > 0 {
+		return concurrency
+	}
+	return r.UploadConcurrency
+}
+
+// uploadChunkSize returns the chunk size of chunked blob uploads.
+func (r *Repository) uploadChunkSize() int64 {
+	size := defaultUploadChunkSize
+	if r.UploadChunkSize > 0 {
+		size = r.UploadChunkSize
+	}
+	if minSize := r.quirks().MinUploadChunkSize; size < minSize {
+		return minSize
+	}
+	return size
+}
+
+// parseUploadRange parses the Range header of upload status responses in the
+// format of "0-<end>", and returns the number of bytes received.
+// Since some registries report "0-0" for empty sessions, "0-0" is treated as
+// no bytes received.
+func parseUploadRange(value string) (int64, error) {
+	if value == "" || value == "0-0" {
+		return 0, nil
+	}
+	start, end, ok := strings.Cut(value, "-")
+	if !ok || start != "0" {
+		return 0, fmt.Errorf("invalid upload range %q", value)
+	}
+	n, err := strconv.ParseInt(end, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid upload range %q", value)
+	}
+	return n + 1, nil
+}
+
+// uploadLocation returns the location of the upload session in the response
+// to the upload request req.
+func uploadLocation(req *http.Request, resp *http.Response) (*url.URL, error) {
+	reqHostname := req.URL.Hostname()
+	reqPort := req.URL.Port()
+	location, err := resp.Location()
+	if err != nil {
+		return nil, err
+	}
+	// work-around solution for https://github.com/oras-project/oras-go/issues/177
+	// For some registries, if the port 443 is explicitly set to the hostname
+	// like registry.wabbit-networks.io:443/myrepo, blob push will fail since
+	// the hostname of the Location header in the response is set to
+	// registry.wabbit-networks.io instead of registry.wabbit-networks.io:443.
+	locationHostname := location.Hostname()
+	locationPort := location.Port()
+	// if location port 443 is missing, add it back
+	if reqPort == "443" && locationHostname == reqHostname && locationPort == "" {
+		location.Host = locationHostname + ":" + reqPort
+	}
+	return location, nil
+}
@@ -0,0 +1,362 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/override"
+	"oras.land/oras-go/v2/registry"
+)
+
+// testUploadTracker is an in-memory registry.UploadTracker.
+type testUploadTracker struct {
+	mu       sync.Mutex
+	sessions map[digest.Digest]registry.UploadSession
+}
+
+func (t *testUploadTracker) LoadUpload(_ context.Context, desc ocispec.Descriptor) (registry.UploadSession, bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	session, ok := t.sessions[desc.Digest]
+	return session, ok, nil
+}
+
+func (t *testUploadTracker) SaveUpload(_ context.Context, desc ocispec.Descriptor, session registry.UploadSession) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sessions[desc.Digest] = session
+	return nil
+}
+
+func (t *testUploadTracker) DeleteUpload(_ context.Context, desc ocispec.Descriptor) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.sessions, desc.Digest)
+	return nil
+}
+
+// failingReader fails after reading n bytes.
+type failingReader struct {
+	r io.Reader
+	n int
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.n <= 0 {
+		return 0, errors.New("connection lost")
+	}
+	if len(p) > r.n {
+		p = p[:r.n]
+	}
+	n, err := r.r.Read(p)
+	r.n -= n
+	return n, err
+}
+
+func TestRepository_Push_Resumable(t *testing.T) {
+	blob := []byte("hello world!")
+	blobDesc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+	}
+	var received []byte
+	var patchOffsets []int64
+	var completed bool
+	uploadPath := "/v2/test/blobs/uploads/uuid"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/test/blobs/uploads/":
+			w.Header().Set("Location", uploadPath)
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodGet && r.URL.Path == uploadPath:
+			if len(received) > 0 {
+				w.Header().Set("Range", fmt.Sprintf("0-%d", len(received)-1))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPatch && r.URL.Path == uploadPath:
+			start, _, _ := strings.Cut(r.Header.Get("Content-Range"), "-")
+			offset, err := strconv.ParseInt(start, 10, 64)
+			if err != nil || offset != int64(len(received)) {
+				w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+				return
+			}
+			data, err := io.ReadAll(r.Body)
+			if err != nil || int64(len(data)) != r.ContentLength {
+				// the chunk is interrupted
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			patchOffsets = append(patchOffsets, offset)
+			received = append(received, data...)
+			w.Header().Set("Location", uploadPath)
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodPut && r.URL.Path == uploadPath:
+			if got := r.URL.Query().Get("digest"); got != blobDesc.Digest.String() || !bytes.Equal(received, blob) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			completed = true
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	repo.UploadChunkSize = 4
+	tracker := &testUploadTracker{sessions: make(map[digest.Digest]registry.UploadSession)}
+	ctx := registry.WithUploadTracker(context.Background(), tracker)
+
+	// interrupted push
+	content := &failingReader{r: bytes.NewReader(blob), n: 5}
+	if err := repo.Push(ctx, blobDesc, content); err == nil {
+		t.Fatal("Repository.Push() error = nil, want error")
+	}
+	want := registry.UploadSession{Location: ts.URL + uploadPath, Offset: 4, Digest: blobDesc.Digest}
+	if got := tracker.sessions[blobDesc.Digest]; got != want {
+		t.Fatalf("saved session = %v, want %v", got, want)
+	}
+
+	// resumed push
+	if err := repo.Push(ctx, blobDesc, bytes.NewReader(blob)); err != nil {
+		t.Fatalf("Repository.Push() error = %v", err)
+	}
+	if !completed {
+		t.Error("upload is not completed")
+	}
+	if want := []int64{0, 4, 8}; !reflect.DeepEqual(patchOffsets, want) {
+		t.Errorf("uploaded offsets = %v, want %v", patchOffsets, want)
+	}
+	if _, ok := tracker.sessions[blobDesc.Digest]; ok {
+		t.Error("upload session is not deleted")
+	}
+}
+
+func TestRepository_Push_Parallel(t *testing.T) {
+	blob := []byte("hello world! hello oras!")
+	blobDesc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+	}
+	tests := []struct {
+		name       string
+		outOfOrder bool
+		wantOrder  []int64
+	}{
+		{
+			name:       "out-of-order chunks accepted",
+			outOfOrder: true,
+		},
+		{
+			name:       "out-of-order chunks rejected",
+			outOfOrder: false,
+			wantOrder:  []int64{0, 5, 10, 15, 20},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			received := make([]byte, len(blob))
+			var written int64
+			var patchOffsets []int64
+			var completed bool
+			uploadPath := "/v2/test/blobs/uploads/uuid"
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case r.Method == http.MethodPost && r.URL.Path == "/v2/test/blobs/uploads/":
+					w.Header().Set("Location", uploadPath)
+					w.WriteHeader(http.StatusAccepted)
+				case r.Method == http.MethodGet && r.URL.Path == uploadPath:
+					if written > 0 {
+						w.Header().Set("Range", fmt.Sprintf("0-%d", written-1))
+					}
+					w.WriteHeader(http.StatusNoContent)
+				case r.Method == http.MethodPatch && r.URL.Path == uploadPath:
+					start, _, _ := strings.Cut(r.Header.Get("Content-Range"), "-")
+					offset, err := strconv.ParseInt(start, 10, 64)
+					if err != nil || (!tt.outOfOrder && offset != written) {
+						w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+						return
+					}
+					data, err := io.ReadAll(r.Body)
+					if err != nil || int64(len(data)) != r.ContentLength {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					patchOffsets = append(patchOffsets, offset)
+					copy(received[offset:], data)
+					written += int64(len(data))
+					w.Header().Set("Location", uploadPath)
+					w.WriteHeader(http.StatusAccepted)
+				case r.Method == http.MethodPut && r.URL.Path == uploadPath:
+					if got := r.URL.Query().Get("digest"); got != blobDesc.Digest.String() || !bytes.Equal(received, blob) {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					completed = true
+					w.WriteHeader(http.StatusCreated)
+				default:
+					t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer ts.Close()
+			uri, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatalf("invalid test http server: %v", err)
+			}
+
+			repo, err := NewRepository(uri.Host + "/test")
+			if err != nil {
+				t.Fatalf("NewRepository() error = %v", err)
+			}
+			repo.Client = http.DefaultClient
+			repo.PlainHTTP = true
+			repo.UploadChunkSize = 5
+			repo.UploadConcurrency = 3
+			if err := repo.Push(context.Background(), blobDesc, bytes.NewReader(blob)); err != nil {
+				t.Fatalf("Repository.Push() error = %v", err)
+			}
+			if !completed {
+				t.Error("upload is not completed")
+			}
+			if tt.wantOrder != nil && !reflect.DeepEqual(patchOffsets, tt.wantOrder) {
+				t.Errorf("uploaded offsets = %v, want %v", patchOffsets, tt.wantOrder)
+			}
+			if tt.outOfOrder && len(patchOffsets) > 1 && patchOffsets[1] != 10 {
+				t.Errorf("probing offset = %d, want %d", patchOffsets[1], 10)
+			}
+			if len(patchOffsets) != 5 {
+				t.Errorf("uploaded offsets = %v, want 5 chunks", patchOffsets)
+			}
+		})
+	}
+}
+
+func TestRepository_Push_ParallelShortContent(t *testing.T) {
+	blob := []byte("hello world! hello oras!")
+	blobDesc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromBytes(blob),
+		Size:      int64(len(blob)),
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost, http.MethodPatch:
+			io.Copy(io.Discard, r.Body)
+			w.Header().Set("Location", "/v2/test/blobs/uploads/uuid")
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			t.Errorf("unexpected access: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("invalid test http server: %v", err)
+	}
+
+	repo, err := NewRepository(uri.Host + "/test")
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	repo.PlainHTTP = true
+	repo.UploadChunkSize = 5
+	repo.UploadConcurrency = 2
+	err = repo.Push(context.Background(), blobDesc, bytes.NewReader(blob[:17]))
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Repository.Push() error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func Test_parseUploadRange(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{value: "", want: 0},
+		{value: "0-0", want: 0},
+		{value: "0-99", want: 100},
+		{value: "1-99", wantErr: true},
+		{value: "0-", wantErr: true},
+		{value: "bytes", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseUploadRange(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseUploadRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseUploadRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepository_concurrencyOverride(t *testing.T) {
+	ctx := override.WithOptions(context.Background(), override.Options{Concurrency: 8})
+	repo := &Repository{}
+
+	// the override does not enable parallel transfers
+	if got, want := repo.uploadConcurrency(ctx), 0; got != want {
+		t.Errorf("Repository.uploadConcurrency() = %v, want %v", got, want)
+	}
+	if got, want := repo.downloadConcurrency(ctx), 0; got != want {
+		t.Errorf("Repository.downloadConcurrency() = %v, want %v", got, want)
+	}
+
+	// the override applies once parallel transfers are enabled
+	repo.UploadConcurrency = 2
+	repo.DownloadConcurrency = 2
+	if got, want := repo.uploadConcurrency(ctx), 8; got != want {
+		t.Errorf("Repository.uploadConcurrency() = %v, want %v", got, want)
+	}
+	if got, want := repo.downloadConcurrency(ctx), 8; got != want {
+		t.Errorf("Repository.downloadConcurrency() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry"
+)
+
+// memoryUploadTracker is a registry.UploadTracker in memory.
+type memoryUploadTracker struct {
+	sessions sync.Map // map[digest.Digest]registry.UploadSession
+}
+
+// NewMemoryUploadTracker returns a registry.UploadTracker keeping the upload
+// sessions in memory, which is useful for resuming uploads within the same
+// process, such as retrying a failed push.
+func NewMemoryUploadTracker() registry.UploadTracker {
+	return &memoryUploadTracker{}
+}
+
+// LoadUpload returns the saved upload session of the blob described by desc.
+func (t *memoryUploadTracker) LoadUpload(_ context.Context, desc ocispec.Descriptor) (registry.UploadSession, bool, error) {
+	value, ok := t.sessions.Load(desc.Digest)
+	if !ok {
+		return registry.UploadSession{}, false, nil
+	}
+	return value.(registry.UploadSession), true, nil
+}
+
+// SaveUpload saves the upload session of the blob described by desc.
+func (t *memoryUploadTracker) SaveUpload(_ context.Context, desc ocispec.Descriptor, session registry.UploadSession) error {
+	t.sessions.Store(desc.Digest, session)
+	return nil
+}
+
+// DeleteUpload deletes the upload session of the blob described by desc.
+func (t *memoryUploadTracker) DeleteUpload(_ context.Context, desc ocispec.Descriptor) error {
+	t.sessions.Delete(desc.Digest)
+	return nil
+}
+
+// FileUploadTracker is a registry.UploadTracker keeping the upload sessions
+// as JSON files in a directory, so that resumable uploads survive process
+// restarts.
+type FileUploadTracker struct {
+	dir string
+}
+
+// NewFileUploadTracker returns a FileUploadTracker keeping the upload
+// sessions in the given directory.
+// The directory is created if it does not exist.
+func NewFileUploadTracker(dir string) (*FileUploadTracker, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileUploadTracker{dir: dir}, nil
+}
+
+// LoadUpload returns the saved upload session of the blob described by desc.
+func (t *FileUploadTracker) LoadUpload(_ context.Context, desc ocispec.Descriptor) (registry.UploadSession, bool, error) {
+	path, err := t.path(desc)
+	if err != nil {
+		return registry.UploadSession{}, false, err
+	}
+	sessionJSON, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return registry.UploadSession{}, false, nil
+		}
+		return registry.UploadSession{}, false, err
+	}
+	var session registry.UploadSession
+	if err := json.Unmarshal(sessionJSON, &session); err != nil {
+		return registry.UploadSession{}, false, fmt.Errorf("failed to decode upload session of %s: %w", desc.Digest, err)
+	}
+	return session, true, nil
+}
+
+// SaveUpload saves the upload session of the blob described by desc.
+// The session file is replaced atomically, so that a crash never leaves a
+// corrupted session behind.
+func (t *FileUploadTracker) SaveUpload(_ context.Context, desc ocispec.Descriptor, session registry.UploadSession) (err error) {
+	path, err := t.path(desc)
+	if err != nil {
+		return err
+	}
+	sessionJSON, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	fp, err := os.CreateTemp(t.dir, "upload_*.json.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(fp.Name())
+		}
+	}()
+	if _, err := fp.Write(sessionJSON); err != nil {
+		fp.Close()
+		return err
+	}
+	if err := fp.Sync(); err != nil {
+		fp.Close()
+		return err
+	}
+	if err := fp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(fp.Name(), path)
+}
+
+// DeleteUpload deletes the upload session of the blob described by desc.
+func (t *FileUploadTracker) DeleteUpload(_ context.Context, desc ocispec.Descriptor) error {
+	path, err := t.path(desc)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path returns the path of the session file of the blob described by desc.
+func (t *FileUploadTracker) path(desc ocispec.Descriptor) (string, error) {
+	if err := desc.Digest.Validate(); err != nil {
+		return "", fmt.Errorf("invalid digest %q: %w", desc.Digest, err)
+	}
+	name := desc.Digest.Algorithm().String() + "-" + desc.Digest.Encoded() + ".json"
+	return filepath.Join(t.dir, name), nil
+}
@@ -0,0 +1,124 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry"
+)
+
+func testUploadTrackerRoundTrip(t *testing.T, tracker registry.UploadTracker) {
+	ctx := context.Background()
+	desc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromString("foo"),
+		Size:      3,
+	}
+	if _, ok, err := tracker.LoadUpload(ctx, desc); err != nil || ok {
+		t.Fatalf("LoadUpload() = %v, %v, want no session", ok, err)
+	}
+	want := registry.UploadSession{Location: "http://localhost/uploads/uuid", Offset: 2, Digest: desc.Digest}
+	if err := tracker.SaveUpload(ctx, desc, want); err != nil {
+		t.Fatalf("SaveUpload() error = %v", err)
+	}
+	got, ok, err := tracker.LoadUpload(ctx, desc)
+	if err != nil || !ok {
+		t.Fatalf("LoadUpload() = %v, %v, want session", ok, err)
+	}
+	if got != want {
+		t.Errorf("LoadUpload() = %v, want %v", got, want)
+	}
+	if err := tracker.DeleteUpload(ctx, desc); err != nil {
+		t.Fatalf("DeleteUpload() error = %v", err)
+	}
+	if _, ok, err := tracker.LoadUpload(ctx, desc); err != nil || ok {
+		t.Errorf("LoadUpload() = %v, %v, want no session", ok, err)
+	}
+	// deleting a missing session is not an error
+	if err := tracker.DeleteUpload(ctx, desc); err != nil {
+		t.Errorf("DeleteUpload() error = %v", err)
+	}
+}
+
+func TestMemoryUploadTracker(t *testing.T) {
+	testUploadTrackerRoundTrip(t, NewMemoryUploadTracker())
+}
+
+func TestFileUploadTracker(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "uploads")
+	tracker, err := NewFileUploadTracker(dir)
+	if err != nil {
+		t.Fatalf("NewFileUploadTracker() error = %v", err)
+	}
+	testUploadTrackerRoundTrip(t, tracker)
+}
+
+func TestFileUploadTracker_Restart(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	desc := ocispec.Descriptor{
+		MediaType: "test",
+		Digest:    digest.FromString("foo"),
+		Size:      3,
+	}
+	tracker, err := NewFileUploadTracker(dir)
+	if err != nil {
+		t.Fatalf("NewFileUploadTracker() error = %v", err)
+	}
+	want := registry.UploadSession{Location: "http://localhost/uploads/uuid", Offset: 2, Digest: desc.Digest}
+	if err := tracker.SaveUpload(ctx, desc, want); err != nil {
+		t.Fatalf("SaveUpload() error = %v", err)
+	}
+
+	// a new tracker on the same directory loads the session
+	tracker, err = NewFileUploadTracker(dir)
+	if err != nil {
+		t.Fatalf("NewFileUploadTracker() error = %v", err)
+	}
+	got, ok, err := tracker.LoadUpload(ctx, desc)
+	if err != nil || !ok {
+		t.Fatalf("LoadUpload() = %v, %v, want session", ok, err)
+	}
+	if got != want {
+		t.Errorf("LoadUpload() = %v, want %v", got, want)
+	}
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("os.ReadDir() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("session directory has %d entries, want 1", len(entries))
+	}
+}
+
+func TestFileUploadTracker_InvalidDigest(t *testing.T) {
+	tracker, err := NewFileUploadTracker(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileUploadTracker() error = %v", err)
+	}
+	desc := ocispec.Descriptor{Digest: "sha256:../../etc/passwd"}
+	if err := tracker.SaveUpload(context.Background(), desc, registry.UploadSession{}); err == nil {
+		t.Error("SaveUpload() error = nil, want error")
+	}
+}
@@ -81,6 +81,13 @@ type ReferenceFetcher interface {
 	FetchReference(ctx context.Context, reference string) (ocispec.Descriptor, io.ReadCloser, error)
 }
 
+// ReferenceExister checks the existence of references efficiently, without
+// fetching the referenced content.
+type ReferenceExister interface {
+	// ExistsReference returns true if the reference exists.
+	ExistsReference(ctx context.Context, reference string) (bool, error)
+}
+
 // ReferrerLister provides the Referrers API.
 // Reference: https://github.com/opencontainers/distribution-spec/blob/v1.1.0-rc1/spec.md#listing-referrers
 type ReferrerLister interface {
@@ -0,0 +1,114 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"fmt"
+	"strings"
+
+	"oras.land/oras-go/v2/errdef"
+)
+
+// TagDigestReference is a reference retaining both the tag and the digest of
+// references like `localhost:5000/hello:v1@sha256:...` (Valid Form B), whose
+// tag is dropped by ParseReference. The content is resolved by the digest,
+// while the tag remains available for naming the content, such as tagging the
+// content copied to a destination.
+type TagDigestReference struct {
+	// Reference references the content. For references with a digest,
+	// Reference.Reference is the digest.
+	Reference
+
+	// Tag is the tag of the reference. It is empty if the reference has no
+	// tag (Valid Form A or D).
+	Tag string
+}
+
+// ParseTagDigestReference parses a string (artifact) into a
+// TagDigestReference. All the valid forms of ParseReference are accepted,
+// where the tag of Valid Form B is validated and retained.
+func ParseTagDigestReference(artifact string) (TagDigestReference, error) {
+	ref, err := ParseReference(artifact)
+	if err != nil {
+		return TagDigestReference{}, err
+	}
+	tagDigestRef := TagDigestReference{Reference: ref}
+	if _, err := ref.Digest(); err != nil {
+		// Valid Form C or D
+		tagDigestRef.Tag = ref.Reference
+		return tagDigestRef, nil
+	}
+
+	// Valid Form A or B
+	_, path, _ := strings.Cut(artifact, "/")
+	path, _, _ = strings.Cut(path, "@")
+	if _, tag, ok := strings.Cut(path, ":"); ok {
+		if err := (Reference{Reference: tag}).ValidateReferenceAsTag(); err != nil {
+			return TagDigestReference{}, err
+		}
+		tagDigestRef.Tag = tag
+	}
+	return tagDigestRef, nil
+}
+
+// SplitTagDigest splits a reference in the form of `<tag>@<digest>` into the
+// tag and the digest, accepting either of them alone as well. It is the
+// counterpart of ParseTagDigestReference for the references relative to a
+// repository.
+func SplitTagDigest(reference string) (tag string, digest string, err error) {
+	tag, digest, ok := strings.Cut(reference, "@")
+	if !ok {
+		if strings.Contains(reference, ":") {
+			tag, digest = "", reference
+		}
+	}
+	if tag != "" {
+		if err := (Reference{Reference: tag}).ValidateReferenceAsTag(); err != nil {
+			return "", "", err
+		}
+	}
+	if digest != "" {
+		if err := (Reference{Reference: digest}).ValidateReferenceAsDigest(); err != nil {
+			return "", "", err
+		}
+	}
+	if tag == "" && digest == "" {
+		return "", "", fmt.Errorf("%w: empty reference", errdef.ErrInvalidReference)
+	}
+	return tag, digest, nil
+}
+
+// String returns the reference string with both the tag and the digest, e.g.
+// `localhost:5000/hello:v1@sha256:...`.
+func (r TagDigestReference) String() string {
+	if r.Tag == "" || r.Tag == r.Reference.Reference {
+		return r.Reference.String()
+	}
+	if _, err := r.Digest(); err != nil {
+		return r.Reference.String()
+	}
+	return r.Registry + "/" + r.Repository + ":" + r.Tag + "@" + r.Reference.Reference
+}
+
+// TagReference returns the reference with the tag instead of the digest.
+// The digest reference is returned if there is no tag.
+func (r TagDigestReference) TagReference() Reference {
+	ref := r.Reference
+	if r.Tag != "" {
+		ref.Reference = r.Tag
+	}
+	return ref
+}
@@ -0,0 +1,117 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"errors"
+	"testing"
+
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestParseTagDigestReference(t *testing.T) {
+	tests := []struct {
+		artifact   string
+		want       TagDigestReference
+		wantString string
+		wantTagRef string
+	}{
+		{
+			artifact: "localhost:5000/hello:v1@" + ValidDigest,
+			want: TagDigestReference{
+				Reference: Reference{Registry: "localhost:5000", Repository: "hello", Reference: ValidDigest},
+				Tag:       "v1",
+			},
+			wantString: "localhost:5000/hello:v1@" + ValidDigest,
+			wantTagRef: "localhost:5000/hello:v1",
+		},
+		{
+			artifact: "localhost:5000/hello@" + ValidDigest,
+			want: TagDigestReference{
+				Reference: Reference{Registry: "localhost:5000", Repository: "hello", Reference: ValidDigest},
+			},
+			wantString: "localhost:5000/hello@" + ValidDigest,
+			wantTagRef: "localhost:5000/hello@" + ValidDigest,
+		},
+		{
+			artifact: "localhost:5000/hello:v1",
+			want: TagDigestReference{
+				Reference: Reference{Registry: "localhost:5000", Repository: "hello", Reference: "v1"},
+				Tag:       "v1",
+			},
+			wantString: "localhost:5000/hello:v1",
+			wantTagRef: "localhost:5000/hello:v1",
+		},
+		{
+			artifact: "localhost:5000/hello",
+			want: TagDigestReference{
+				Reference: Reference{Registry: "localhost:5000", Repository: "hello"},
+			},
+			wantString: "localhost:5000/hello",
+			wantTagRef: "localhost:5000/hello",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.artifact, func(t *testing.T) {
+			got, err := ParseTagDigestReference(tt.artifact)
+			if err != nil {
+				t.Fatalf("ParseTagDigestReference() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTagDigestReference() = %v, want %v", got, tt.want)
+			}
+			if s := got.String(); s != tt.wantString {
+				t.Errorf("TagDigestReference.String() = %v, want %v", s, tt.wantString)
+			}
+			if s := got.TagReference().String(); s != tt.wantTagRef {
+				t.Errorf("TagDigestReference.TagReference() = %v, want %v", s, tt.wantTagRef)
+			}
+		})
+	}
+
+	// the tag of Valid Form B is validated
+	if _, err := ParseTagDigestReference("localhost:5000/hello:-v1@" + ValidDigest); !errors.Is(err, errdef.ErrInvalidReference) {
+		t.Errorf("ParseTagDigestReference() error = %v, want %v", err, errdef.ErrInvalidReference)
+	}
+}
+
+func TestSplitTagDigest(t *testing.T) {
+	tests := []struct {
+		reference  string
+		wantTag    string
+		wantDigest string
+		wantErr    bool
+	}{
+		{reference: "v1@" + ValidDigest, wantTag: "v1", wantDigest: ValidDigest},
+		{reference: ValidDigest, wantDigest: ValidDigest},
+		{reference: "v1", wantTag: "v1"},
+		{reference: "@" + ValidDigest, wantDigest: ValidDigest},
+		{reference: "v1@" + InvalidDigest, wantErr: true},
+		{reference: "-v1@" + ValidDigest, wantErr: true},
+		{reference: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reference, func(t *testing.T) {
+			tag, dgst, err := SplitTagDigest(tt.reference)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SplitTagDigest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tag != tt.wantTag || dgst != tt.wantDigest {
+				t.Errorf("SplitTagDigest() = %v, %v, want %v, %v", tag, dgst, tt.wantTag, tt.wantDigest)
+			}
+		})
+	}
+}
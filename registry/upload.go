@@ -0,0 +1,65 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// UploadSession is the state of an in-progress resumable blob upload.
+type UploadSession struct {
+	// Location is the URL of the upload session.
+	Location string `json:"location"`
+	// Offset is the number of bytes uploaded.
+	Offset int64 `json:"offset"`
+	// Digest is the digest of the blob being uploaded. Saved sessions of a
+	// different digest are not resumed.
+	Digest digest.Digest `json:"digest,omitempty"`
+}
+
+// UploadTracker persists the state of resumable blob uploads, so that
+// interrupted uploads can be resumed by later pushes of the same blobs.
+type UploadTracker interface {
+	// LoadUpload returns the saved upload session of the blob described by
+	// desc. Returns false if there is no saved session.
+	LoadUpload(ctx context.Context, desc ocispec.Descriptor) (UploadSession, bool, error)
+	// SaveUpload saves the upload session of the blob described by desc.
+	SaveUpload(ctx context.Context, desc ocispec.Descriptor, session UploadSession) error
+	// DeleteUpload deletes the upload session of the blob described by desc
+	// once the upload completes.
+	DeleteUpload(ctx context.Context, desc ocispec.Descriptor) error
+}
+
+// uploadTrackerKey is the context key of the upload tracker.
+type uploadTrackerKey struct{}
+
+// WithUploadTracker returns a context with the given upload tracker attached.
+// Blobs pushed to remote repositories with the returned context are uploaded
+// in chunks, and the upload sessions are saved to the tracker after each
+// chunk.
+func WithUploadTracker(ctx context.Context, tracker UploadTracker) context.Context {
+	return context.WithValue(ctx, uploadTrackerKey{}, tracker)
+}
+
+// UploadTrackerFromContext returns the upload tracker attached to the
+// context. Returns nil if there is none.
+func UploadTrackerFromContext(ctx context.Context) UploadTracker {
+	tracker, _ := ctx.Value(uploadTrackerKey{}).(UploadTracker)
+	return tracker
+}
@@ -0,0 +1,210 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/syncutil"
+	"oras.land/oras-go/v2/registry"
+)
+
+// defaultRepairConcurrency is the default value of RepairOptions.Concurrency.
+const defaultRepairConcurrency int = 3
+
+// RepairOptions contains parameters for [oras.RepairBlobs].
+type RepairOptions struct {
+	// Concurrency limits the maximum number of blobs repaired concurrently.
+	// If less than or equal to 0, a default (currently 3) is used.
+	Concurrency int
+	// TempDir is the directory where the blobs fetched from the source are
+	// staged before replacing the damaged ones.
+	// If empty, the default directory for temporary files is used.
+	TempDir string
+}
+
+// RepairFailure is a blob failing to be repaired.
+type RepairFailure struct {
+	// Descriptor describes the blob.
+	Descriptor ocispec.Descriptor
+	// Err is the reason of the failure.
+	Err error
+}
+
+// RepairReport is the result of repairing blobs.
+type RepairReport struct {
+	// Repaired are the blobs reinstated from the source.
+	Repaired []ocispec.Descriptor
+	// Failures are the blobs failing to be repaired.
+	Failures []RepairFailure
+}
+
+// RepairBlobs re-fetches the blobs described by descs, such as the corrupted
+// blobs found by scrub.Scrub or the nodes failing VerifyGraph, from src by
+// digest, and reinstates them in dst in place of the damaged or missing
+// copies, instead of re-copying every artifact.
+//
+// The blobs are fetched from src by the given descriptors. Since the media
+// types of the blobs found by scrubbing are unknown, src is expected to fetch
+// by digest, as registries and OCI layouts do.
+// Each blob is fully fetched and verified into a temporary file before the
+// damaged copy is deleted from dst, so a blob unavailable or corrupted in src
+// leaves dst untouched. If dst lists its tags, the tags pointing to a
+// repaired manifest are restored after it is pushed back.
+// Blobs failing to be repaired are listed in the returned report, and do not
+// make RepairBlobs return an error.
+// Returns ErrUnsupported if dst does not support deletion.
+func RepairBlobs(ctx context.Context, dst content.Storage, src content.ReadOnlyStorage, descs []ocispec.Descriptor, opts RepairOptions) (*RepairReport, error) {
+	deleter, ok := dst.(content.Deleter)
+	if !ok {
+		return nil, fmt.Errorf("blob repair: delete: %w", errdef.ErrUnsupported)
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultRepairConcurrency
+	}
+	tagged, err := taggedDescriptors(ctx, dst)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RepairReport{}
+	var mu sync.Mutex // protects report
+	eg, egCtx := syncutil.LimitGroup(ctx, concurrency)
+	for _, desc := range descs {
+		desc := desc
+		if tags := tagged[desc.Digest]; len(tags) > 0 {
+			// the media type of the blob may be unknown to the caller
+			desc = descriptor.Plain(tags[0].desc)
+		}
+		eg.Go(func() error {
+			err := repairBlob(egCtx, dst, deleter, src, desc, tagged[desc.Digest], opts.TempDir)
+			if ctxErr := egCtx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				report.Failures = append(report.Failures, RepairFailure{
+					Descriptor: desc,
+					Err:        err,
+				})
+			} else {
+				report.Repaired = append(report.Repaired, desc)
+			}
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// taggedDescriptor is a descriptor tagged with a reference.
+type taggedDescriptor struct {
+	desc      ocispec.Descriptor
+	reference string
+}
+
+// taggedDescriptors returns the tagged descriptors in the storage by digest,
+// or nil if the storage does not list its tags.
+func taggedDescriptors(ctx context.Context, storage content.Storage) (map[digest.Digest][]taggedDescriptor, error) {
+	resolver, ok := storage.(content.TagResolver)
+	if !ok {
+		return nil, nil
+	}
+	if _, ok := storage.(registry.TagLister); !ok {
+		return nil, nil
+	}
+	tagged := make(map[digest.Digest][]taggedDescriptor)
+	if err := registry.ListTags(ctx, resolver, "", func(tags []string) error {
+		for _, tag := range tags {
+			desc, err := resolver.Resolve(ctx, tag)
+			if err != nil {
+				if errors.Is(err, errdef.ErrNotFound) {
+					continue
+				}
+				return fmt.Errorf("failed to resolve %s: %w", tag, err)
+			}
+			tagged[desc.Digest] = append(tagged[desc.Digest], taggedDescriptor{
+				desc:      desc,
+				reference: tag,
+			})
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return tagged, nil
+}
+
+// repairBlob fetches the blob from src into a temporary file, and replaces
+// the copy in dst with it, restoring the given tags.
+func repairBlob(ctx context.Context, dst content.Storage, deleter content.Deleter, src content.ReadOnlyStorage, desc ocispec.Descriptor, tags []taggedDescriptor, tempDir string) (err error) {
+	fp, err := os.CreateTemp(tempDir, "oras_repair_*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		fp.Close()
+		if removeErr := os.Remove(fp.Name()); removeErr != nil && err == nil {
+			err = removeErr
+		}
+	}()
+
+	rc, err := src.Fetch(ctx, desc)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s from source: %w", desc.Digest, err)
+	}
+	defer rc.Close()
+	vr := content.NewVerifyReader(rc, desc)
+	if _, err := io.Copy(fp, vr); err != nil {
+		return fmt.Errorf("failed to fetch %s from source: %w", desc.Digest, err)
+	}
+	if err := vr.Verify(); err != nil {
+		return fmt.Errorf("failed to fetch %s from source: %w", desc.Digest, err)
+	}
+	if _, err := fp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if err := deleter.Delete(ctx, desc); err != nil && !errors.Is(err, errdef.ErrNotFound) {
+		return fmt.Errorf("failed to delete %s: %w", desc.Digest, err)
+	}
+	if err := dst.Push(ctx, desc, fp); err != nil {
+		return fmt.Errorf("failed to push %s: %w", desc.Digest, err)
+	}
+	if len(tags) > 0 {
+		tagger := dst.(content.Tagger)
+		for _, tag := range tags {
+			if err := tagger.Tag(ctx, tag.desc, tag.reference); err != nil {
+				return fmt.Errorf("failed to restore tag %s: %w", tag.reference, err)
+			}
+		}
+	}
+	return nil
+}
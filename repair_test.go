@@ -0,0 +1,143 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/content/scrub"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestRepairBlobs(t *testing.T) {
+	ctx := context.Background()
+	// scrubbed blobs are described as application/octet-stream, so the
+	// source must fetch by digest
+	src, err := oci.New(t.TempDir())
+	if err != nil {
+		t.Fatal("oci.New() error =", err)
+	}
+	layer := []byte("hello world")
+	layerDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, layer)
+	if err := src.Push(ctx, layerDesc, bytes.NewReader(layer)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	manifestDesc, err := oras.Pack(ctx, src, "application/vnd.test", []ocispec.Descriptor{layerDesc}, oras.PackOptions{PackImageManifest: true})
+	if err != nil {
+		t.Fatal("Pack() error =", err)
+	}
+	if err := src.Tag(ctx, manifestDesc, "latest"); err != nil {
+		t.Fatal("Tag() error =", err)
+	}
+
+	root := t.TempDir()
+	dst, err := oci.New(root)
+	if err != nil {
+		t.Fatal("oci.New() error =", err)
+	}
+	if _, err := oras.Copy(ctx, src, "latest", dst, "latest", oras.DefaultCopyOptions); err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+
+	// corrupt the layer and the manifest on disk
+	for _, desc := range []ocispec.Descriptor{layerDesc, manifestDesc} {
+		path := filepath.Join(root, "blobs", desc.Digest.Algorithm().String(), desc.Digest.Encoded())
+		if err := os.WriteFile(path, bytes.Repeat([]byte("x"), int(desc.Size)), 0666); err != nil {
+			t.Fatal("WriteFile() error =", err)
+		}
+	}
+	report, err := scrub.Scrub(ctx, dst, scrub.Options{})
+	if err != nil {
+		t.Fatal("Scrub() error =", err)
+	}
+	var damaged []ocispec.Descriptor
+	for _, corruption := range report.Corrupted {
+		damaged = append(damaged, corruption.Descriptor)
+	}
+	if len(damaged) != 2 {
+		t.Fatalf("Scrub() corrupted = %v, want 2 blobs", damaged)
+	}
+
+	repairReport, err := oras.RepairBlobs(ctx, dst, src, damaged, oras.RepairOptions{TempDir: t.TempDir()})
+	if err != nil {
+		t.Fatal("RepairBlobs() error =", err)
+	}
+	if len(repairReport.Failures) != 0 {
+		t.Fatalf("RepairBlobs() failures = %v", repairReport.Failures)
+	}
+	if len(repairReport.Repaired) != 2 {
+		t.Errorf("RepairBlobs() repaired = %v, want 2 blobs", repairReport.Repaired)
+	}
+	report, err = scrub.Scrub(ctx, dst, scrub.Options{})
+	if err != nil {
+		t.Fatal("Scrub() error =", err)
+	}
+	if len(report.Corrupted) != 0 {
+		t.Errorf("Scrub() corrupted = %v, want none", report.Corrupted)
+	}
+	// the tag of the repaired manifest is kept
+	got, err := dst.Resolve(ctx, "latest")
+	if err != nil {
+		t.Fatal("Resolve() error =", err)
+	}
+	if got.Digest != manifestDesc.Digest {
+		t.Errorf("Resolve() = %v, want %v", got.Digest, manifestDesc.Digest)
+	}
+	if _, err := oras.VerifyGraph(ctx, dst, manifestDesc, oras.VerifyGraphOptions{VerifyDigest: true}); err != nil {
+		t.Error("VerifyGraph() error =", err)
+	}
+}
+
+func TestRepairBlobs_SourceFailure(t *testing.T) {
+	ctx := context.Background()
+	dst := memory.New()
+	blob := []byte("hello")
+	desc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+	if err := dst.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+
+	// the blob is missing in the source
+	report, err := oras.RepairBlobs(ctx, dst, memory.New(), []ocispec.Descriptor{desc}, oras.RepairOptions{})
+	if err != nil {
+		t.Fatal("RepairBlobs() error =", err)
+	}
+	if len(report.Failures) != 1 || !errors.Is(report.Failures[0].Err, errdef.ErrNotFound) {
+		t.Fatalf("RepairBlobs() failures = %v, want not found", report.Failures)
+	}
+	// the copy in the destination is kept
+	if exists, err := dst.Exists(ctx, desc); err != nil || !exists {
+		t.Errorf("Exists() = %v, %v, want true", exists, err)
+	}
+}
+
+func TestRepairBlobs_Unsupported(t *testing.T) {
+	ctx := context.Background()
+	dst := struct{ content.Storage }{memory.New()}
+	if _, err := oras.RepairBlobs(ctx, dst, memory.New(), nil, oras.RepairOptions{}); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("RepairBlobs() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"io"
+	"path"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+)
+
+// Route routes the content matching its conditions to a storage.
+// A route without conditions matches all the content.
+type Route struct {
+	// MediaTypes, if not empty, matches the content of the media types.
+	// The media types are patterns in the syntax of path.Match, such as
+	// "application/vnd.oci.image.layer.*".
+	MediaTypes []string
+	// MinSize, if positive, matches the content of at least MinSize bytes.
+	MinSize int64
+	// MaxSize, if positive, matches the content of at most MaxSize bytes.
+	MaxSize int64
+	// Storage stores the matched content.
+	Storage content.Storage
+}
+
+// Match returns true if the described content matches the conditions of the
+// route.
+func (r Route) Match(desc ocispec.Descriptor) bool {
+	if r.MinSize > 0 && desc.Size < r.MinSize {
+		return false
+	}
+	if r.MaxSize > 0 && desc.Size > r.MaxSize {
+		return false
+	}
+	if len(r.MediaTypes) == 0 {
+		return true
+	}
+	for _, pattern := range r.MediaTypes {
+		if matched, _ := path.Match(pattern, desc.MediaType); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// routingTarget is a Target routing the content to storages by routes.
+type routingTarget struct {
+	Target
+	routes []Route
+}
+
+// NewRoutingTarget returns a Target storing the content in the storage of
+// the first matching route, or in t if no route matches, so that the content
+// of a graph can be spread over heterogeneous storages, such as small
+// configs in memory and large layers on disk, with plain Copy.
+//
+// Resolve and Tag are performed on t, so manifests are expected to be routed
+// to t, since targets commonly refuse to tag content they do not hold.
+// Content is fetched only from the storage it is routed to, so the routes
+// should not change over the lifetime of the stored content.
+// Optional interfaces implemented by t are not preserved by the returned
+// Target.
+func NewRoutingTarget(t Target, routes ...Route) Target {
+	return &routingTarget{
+		Target: t,
+		routes: routes,
+	}
+}
+
+// route returns the storage of the described content.
+func (t *routingTarget) route(desc ocispec.Descriptor) content.Storage {
+	for _, r := range t.routes {
+		if r.Match(desc) {
+			return r.Storage
+		}
+	}
+	return t.Target
+}
+
+// Fetch fetches the content identified by the descriptor from the storage it
+// is routed to.
+func (t *routingTarget) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	return t.route(target).Fetch(ctx, target)
+}
+
+// Push pushes the content, matching the expected descriptor, to the storage
+// it is routed to.
+func (t *routingTarget) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	return t.route(expected).Push(ctx, expected, content)
+}
+
+// Exists returns true if the described content exists in the storage it is
+// routed to.
+func (t *routingTarget) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	return t.route(target).Exists(ctx, target)
+}
@@ -0,0 +1,104 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestRoute_Match(t *testing.T) {
+	desc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageLayerGzip,
+		Size:      100,
+	}
+	tests := []struct {
+		name  string
+		route Route
+		want  bool
+	}{
+		{"no conditions", Route{}, true},
+		{"media type", Route{MediaTypes: []string{ocispec.MediaTypeImageLayerGzip}}, true},
+		{"media type pattern", Route{MediaTypes: []string{"application/vnd.oci.image.layer.*"}}, true},
+		{"other media type", Route{MediaTypes: []string{ocispec.MediaTypeImageConfig}}, false},
+		{"min size", Route{MinSize: 100}, true},
+		{"below min size", Route{MinSize: 101}, false},
+		{"max size", Route{MaxSize: 100}, true},
+		{"above max size", Route{MaxSize: 99}, false},
+		{"all conditions", Route{MediaTypes: []string{"application/*"}, MinSize: 1, MaxSize: 1000}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.route.Match(desc); got != tt.want {
+				t.Errorf("Route.Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRoutingTarget_Copy(t *testing.T) {
+	ctx := context.Background()
+	src, root := pushImage(t, []byte(`{"config":"x"}`), []byte("a large layer"), "latest")
+	manifests := memory.New()
+	configs := memory.New()
+	layers := memory.New()
+	dst := NewRoutingTarget(manifests,
+		Route{MediaTypes: []string{ocispec.MediaTypeImageConfig}, Storage: configs},
+		Route{MediaTypes: []string{"application/vnd.oci.image.layer.*"}, MinSize: 10, Storage: layers},
+	)
+	if _, err := Copy(ctx, src, "latest", dst, "latest", DefaultCopyOptions); err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+
+	manifestJSON, err := content.FetchAll(ctx, dst, root)
+	if err != nil {
+		t.Fatal("FetchAll() error =", err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		t.Fatal("json.Unmarshal() error =", err)
+	}
+	placements := []struct {
+		desc   ocispec.Descriptor
+		stored *memory.Store
+	}{
+		{root, manifests},
+		{manifest.Config, configs},
+		{manifest.Layers[0], layers},
+	}
+	for _, p := range placements {
+		for _, s := range []*memory.Store{manifests, configs, layers} {
+			exists, err := s.Exists(ctx, p.desc)
+			if err != nil {
+				t.Fatal("Exists() error =", err)
+			}
+			if want := s == p.stored; exists != want {
+				t.Errorf("%s: Exists() = %v, want %v", p.desc.MediaType, exists, want)
+			}
+		}
+		if exists, err := dst.Exists(ctx, p.desc); err != nil || !exists {
+			t.Errorf("%s: routed Exists() = %v, %v, want true", p.desc.MediaType, exists, err)
+		}
+	}
+	if got, err := dst.Resolve(ctx, "latest"); err != nil || got.Digest != root.Digest {
+		t.Errorf("Resolve() = %v, %v, want %v", got.Digest, err, root.Digest)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/sync/semaphore"
+)
+
+// Scheduler controls how the nodes of a graph are dispatched when copying.
+//
+// Schedulers must be safe for concurrent use. A node releases its slot while
+// waiting for its successors, and acquires it again before it is copied.
+// Therefore, a scheduler must not block Acquire forever while slots are held
+// by nodes waiting for their successors.
+type Scheduler interface {
+	// Submit is called with the nodes ready to be dispatched, which are
+	// either the root node or the successors of a node, and returns the
+	// nodes in the order that they should be dispatched.
+	// Implementations must return all the given nodes.
+	Submit(ctx context.Context, nodes []ocispec.Descriptor) []ocispec.Descriptor
+	// Acquire blocks until a slot to process the node is available or ctx is
+	// done.
+	Acquire(ctx context.Context, node ocispec.Descriptor) error
+	// Release releases the slot acquired for the node.
+	Release(node ocispec.Descriptor)
+}
+
+// NewSemaphoreScheduler returns the default Scheduler, which dispatches the
+// nodes in the submitted order, with at most concurrency nodes processed at
+// the same time.
+// If concurrency is not greater than 0, the default concurrency is used.
+func NewSemaphoreScheduler(concurrency int) Scheduler {
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	return &semaphoreScheduler{
+		limiter: semaphore.NewWeighted(int64(concurrency)),
+	}
+}
+
+// semaphoreScheduler is a Scheduler backed by a weighted semaphore.
+type semaphoreScheduler struct {
+	limiter *semaphore.Weighted
+}
+
+// Submit returns the nodes as is.
+func (s *semaphoreScheduler) Submit(_ context.Context, nodes []ocispec.Descriptor) []ocispec.Descriptor {
+	return nodes
+}
+
+// Acquire acquires a slot from the semaphore.
+func (s *semaphoreScheduler) Acquire(ctx context.Context, _ ocispec.Descriptor) error {
+	return s.limiter.Acquire(ctx, 1)
+}
+
+// Release releases a slot to the semaphore.
+func (s *semaphoreScheduler) Release(_ ocispec.Descriptor) {
+	s.limiter.Release(1)
+}
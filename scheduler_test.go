@@ -0,0 +1,133 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+// recordingScheduler is a serial Scheduler recording the calls.
+type recordingScheduler struct {
+	lock      sync.Mutex
+	slot      chan struct{}
+	submitted int
+	acquired  []ocispec.Descriptor
+	released  int
+}
+
+func newRecordingScheduler() *recordingScheduler {
+	return &recordingScheduler{
+		slot: make(chan struct{}, 1),
+	}
+}
+
+func (s *recordingScheduler) Submit(_ context.Context, nodes []ocispec.Descriptor) []ocispec.Descriptor {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.submitted += len(nodes)
+	// reverse the order
+	reversed := make([]ocispec.Descriptor, len(nodes))
+	for i, node := range nodes {
+		reversed[len(nodes)-1-i] = node
+	}
+	return reversed
+}
+
+func (s *recordingScheduler) Acquire(ctx context.Context, node ocispec.Descriptor) error {
+	select {
+	case s.slot <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.acquired = append(s.acquired, node)
+	return nil
+}
+
+func (s *recordingScheduler) Release(_ ocispec.Descriptor) {
+	s.lock.Lock()
+	s.released++
+	s.lock.Unlock()
+	<-s.slot
+}
+
+func TestCopyGraph_Scheduler(t *testing.T) {
+	ctx := context.Background()
+	src, root := pushImage(t, []byte(`{}`), []byte("hello world"), "latest")
+	dst := memory.New()
+
+	scheduler := newRecordingScheduler()
+	opts := CopyGraphOptions{
+		Scheduler: scheduler,
+	}
+	if err := CopyGraph(ctx, src, dst, root, opts); err != nil {
+		t.Fatalf("CopyGraph() error = %v", err)
+	}
+	if exists, err := dst.Exists(ctx, root); err != nil || !exists {
+		t.Errorf("dst.Exists() = %v, %v, want true", exists, err)
+	}
+
+	// root, config and layer
+	if want := 3; scheduler.submitted != want {
+		t.Errorf("submitted nodes = %d, want %d", scheduler.submitted, want)
+	}
+	// the root acquires a slot again after its successors are copied
+	if want := 4; len(scheduler.acquired) != want {
+		t.Fatalf("acquired slots = %d, want %d", len(scheduler.acquired), want)
+	}
+	if scheduler.released != len(scheduler.acquired) {
+		t.Errorf("released slots = %d, want %d", scheduler.released, len(scheduler.acquired))
+	}
+	// successors are dispatched in the reversed order
+	layer := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, []byte("hello world"))
+	if got, want := scheduler.acquired[1].Digest, layer.Digest; got != want {
+		t.Errorf("second acquired node = %v, want %v", got, want)
+	}
+	if got, want := scheduler.acquired[3].Digest, root.Digest; got != want {
+		t.Errorf("last acquired node = %v, want %v", got, want)
+	}
+}
+
+func TestNewSemaphoreScheduler(t *testing.T) {
+	ctx := context.Background()
+	s := NewSemaphoreScheduler(1)
+	node := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayer}
+	nodes := []ocispec.Descriptor{node}
+	if got := s.Submit(ctx, nodes); len(got) != 1 {
+		t.Fatalf("Submit() = %v, want %v", got, nodes)
+	}
+	if err := s.Acquire(ctx, node); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	// the second acquisition blocks until the context is done
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := s.Acquire(cancelCtx, node); err == nil {
+		t.Error("Acquire() error = nil, want context error")
+	}
+	s.Release(node)
+	if err := s.Acquire(ctx, node); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	s.Release(node)
+}
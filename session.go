@@ -0,0 +1,256 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/cas"
+	"oras.land/oras-go/v2/internal/descriptor"
+)
+
+// ErrSessionClosed is returned when a push session is used after it is
+// committed or rolled back.
+var ErrSessionClosed = errors.New("push session closed")
+
+// sessionTag is a tag staged in a push session.
+type sessionTag struct {
+	desc      ocispec.Descriptor
+	reference string
+}
+
+// PushSession is a Target staging the content pushed to the underlying
+// target, so that a multi-manifest artifact is published at once on Commit.
+//
+// Blobs are pushed to the underlying target immediately, where they are not
+// reachable until a manifest references them. Manifests and tags are staged
+// in memory, and are pushed and tagged in order on Commit. Rollback deletes
+// the blobs pushed by the session if the underlying target is a
+// content.Deleter.
+//
+// Commit is not atomic on the remote side: a failing Commit may leave some
+// of the manifests pushed, and can be retried.
+type PushSession struct {
+	target Target
+
+	lock      sync.Mutex
+	closed    bool
+	manifests *cas.Memory
+	order     []ocispec.Descriptor
+	tags      []sessionTag
+	blobs     []ocispec.Descriptor
+}
+
+// NewPushSession creates a push session on the target.
+func NewPushSession(target Target) *PushSession {
+	return &PushSession{
+		target:    target,
+		manifests: cas.NewMemory(),
+	}
+}
+
+// Fetch fetches the content identified by the descriptor from the staged
+// manifests or the underlying target.
+func (s *PushSession) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	rc, err := s.manifests.Fetch(ctx, target)
+	if err == nil || !errors.Is(err, errdef.ErrNotFound) {
+		return rc, err
+	}
+	return s.target.Fetch(ctx, target)
+}
+
+// Push stages a manifest, or pushes a blob to the underlying target.
+func (s *PushSession) Push(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	if descriptor.IsManifest(expected) {
+		return s.stageManifest(ctx, expected, r)
+	}
+
+	if s.isClosed() {
+		return ErrSessionClosed
+	}
+	if err := s.target.Push(ctx, expected, r); err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.blobs = append(s.blobs, expected)
+	return nil
+}
+
+// stageManifest stages the manifest in memory.
+func (s *PushSession) stageManifest(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return ErrSessionClosed
+	}
+	if err := s.manifests.Push(ctx, expected, r); err != nil {
+		return err
+	}
+	s.order = append(s.order, expected)
+	return nil
+}
+
+// isClosed reports whether the session is committed or rolled back.
+func (s *PushSession) isClosed() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.closed
+}
+
+// Exists returns true if the described content is staged or exists in the
+// underlying target.
+func (s *PushSession) Exists(ctx context.Context, target ocispec.Descriptor) (bool, error) {
+	exists, err := s.manifests.Exists(ctx, target)
+	if err != nil || exists {
+		return exists, err
+	}
+	return s.target.Exists(ctx, target)
+}
+
+// Resolve resolves the reference from the staged tags or the underlying
+// target.
+func (s *PushSession) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+	s.lock.Lock()
+	for i := len(s.tags) - 1; i >= 0; i-- {
+		if s.tags[i].reference == reference {
+			desc := s.tags[i].desc
+			s.lock.Unlock()
+			return desc, nil
+		}
+	}
+	s.lock.Unlock()
+	return s.target.Resolve(ctx, reference)
+}
+
+// Tag stages the tag, which is applied to the underlying target on Commit.
+// Returns ErrNotFound if the described content is neither staged nor exists
+// in the underlying target.
+func (s *PushSession) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	if reference == "" {
+		return errdef.ErrMissingReference
+	}
+	exists, err := s.Exists(ctx, desc)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, errdef.ErrNotFound)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return ErrSessionClosed
+	}
+	s.tags = append(s.tags, sessionTag{desc: desc, reference: reference})
+	return nil
+}
+
+// Commit pushes the staged manifests to the underlying target in the order
+// they were staged, and then applies the staged tags.
+// Manifests already existing in the underlying target are skipped. If Commit
+// fails, the session stays open and Commit can be retried.
+func (s *PushSession) Commit(ctx context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return ErrSessionClosed
+	}
+
+	for len(s.order) > 0 {
+		desc := s.order[0]
+		if err := s.commitManifest(ctx, desc); err != nil {
+			return err
+		}
+		s.order = s.order[1:]
+	}
+	for len(s.tags) > 0 {
+		tag := s.tags[0]
+		if err := s.target.Tag(ctx, tag.desc, tag.reference); err != nil {
+			return fmt.Errorf("failed to tag %s as %s: %w", tag.desc.Digest, tag.reference, err)
+		}
+		s.tags = s.tags[1:]
+	}
+	s.closed = true
+	s.manifests = cas.NewMemory()
+	s.blobs = nil
+	return nil
+}
+
+// commitManifest pushes a staged manifest to the underlying target.
+func (s *PushSession) commitManifest(ctx context.Context, desc ocispec.Descriptor) error {
+	exists, err := s.target.Exists(ctx, desc)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	rc, err := s.manifests.Fetch(ctx, desc)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := s.target.Push(ctx, desc, rc); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return err
+	}
+	return nil
+}
+
+// Rollback discards the staged manifests and tags, and deletes the blobs
+// pushed by the session if the underlying target is a content.Deleter.
+// Blobs failing to be deleted are reported in the returned error, while the
+// session is closed regardless.
+func (s *PushSession) Rollback(ctx context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return ErrSessionClosed
+	}
+	s.closed = true
+	blobs := s.blobs
+	s.manifests = cas.NewMemory()
+	s.order = nil
+	s.tags = nil
+	s.blobs = nil
+
+	deleter, ok := s.target.(content.Deleter)
+	if !ok {
+		return nil
+	}
+	var firstErr error
+	var failed int
+	for i := len(blobs) - 1; i >= 0; i-- {
+		if err := deleter.Delete(ctx, blobs[i]); err != nil && !errors.Is(err, errdef.ErrNotFound) {
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+		}
+	}
+	if firstErr != nil {
+		return fmt.Errorf("failed to delete %d staged blobs: %w", failed, firstErr)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestPushSession_Commit(t *testing.T) {
+	ctx := context.Background()
+	src, manifestDesc := pushImage(t, []byte(`{}`), []byte("foo"), "latest")
+	dst := memory.New()
+
+	session := NewPushSession(dst)
+	if _, err := Copy(ctx, src, "latest", session, "v1", DefaultCopyOptions); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+
+	// the manifest and the tag are not visible before commit
+	if exists, err := dst.Exists(ctx, manifestDesc); err != nil || exists {
+		t.Errorf("Store.Exists(manifest) = %v, %v, want false", exists, err)
+	}
+	if _, err := dst.Resolve(ctx, "v1"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Store.Resolve() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+	// but they are visible through the session
+	if desc, err := session.Resolve(ctx, "v1"); err != nil || !content.Equal(desc, manifestDesc) {
+		t.Errorf("PushSession.Resolve() = %v, %v, want %v", desc, err, manifestDesc)
+	}
+	if exists, err := session.Exists(ctx, manifestDesc); err != nil || !exists {
+		t.Errorf("PushSession.Exists(manifest) = %v, %v, want true", exists, err)
+	}
+
+	if err := session.Commit(ctx); err != nil {
+		t.Fatalf("PushSession.Commit() error = %v", err)
+	}
+	desc, err := dst.Resolve(ctx, "v1")
+	if err != nil {
+		t.Fatalf("Store.Resolve() error = %v", err)
+	}
+	if !content.Equal(desc, manifestDesc) {
+		t.Errorf("Store.Resolve() = %v, want %v", desc, manifestDesc)
+	}
+
+	// the session is closed after commit
+	if err := session.Commit(ctx); !errors.Is(err, ErrSessionClosed) {
+		t.Errorf("PushSession.Commit() error = %v, want %v", err, ErrSessionClosed)
+	}
+	if err := session.Rollback(ctx); !errors.Is(err, ErrSessionClosed) {
+		t.Errorf("PushSession.Rollback() error = %v, want %v", err, ErrSessionClosed)
+	}
+	if err := session.Push(ctx, desc, bytes.NewReader(nil)); !errors.Is(err, ErrSessionClosed) {
+		t.Errorf("PushSession.Push() error = %v, want %v", err, ErrSessionClosed)
+	}
+}
+
+func TestPushSession_Rollback(t *testing.T) {
+	ctx := context.Background()
+	src, manifestDesc := pushImage(t, []byte(`{}`), []byte("foo"), "latest")
+	dst := memory.New()
+
+	// an existing blob is not deleted by rollback
+	existing := content.NewDescriptorFromBytes("application/octet-stream", []byte("existing"))
+	if err := dst.Push(ctx, existing, bytes.NewReader([]byte("existing"))); err != nil {
+		t.Fatalf("Store.Push() error = %v", err)
+	}
+
+	session := NewPushSession(dst)
+	if _, err := Copy(ctx, src, "latest", session, "v1", DefaultCopyOptions); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	successors, err := content.Successors(ctx, src, manifestDesc)
+	if err != nil {
+		t.Fatalf("content.Successors() error = %v", err)
+	}
+	for _, blob := range successors {
+		if exists, err := dst.Exists(ctx, blob); err != nil || !exists {
+			t.Errorf("Store.Exists(%s) = %v, %v, want true", blob.Digest, exists, err)
+		}
+	}
+
+	if err := session.Rollback(ctx); err != nil {
+		t.Fatalf("PushSession.Rollback() error = %v", err)
+	}
+	for _, desc := range append(successors, manifestDesc) {
+		if exists, err := dst.Exists(ctx, desc); err != nil || exists {
+			t.Errorf("Store.Exists(%s) = %v, %v, want false", desc.Digest, exists, err)
+		}
+	}
+	if exists, err := dst.Exists(ctx, existing); err != nil || !exists {
+		t.Errorf("Store.Exists(existing) = %v, %v, want true", exists, err)
+	}
+	if _, err := dst.Resolve(ctx, "v1"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Store.Resolve() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+	if err := session.Commit(ctx); !errors.Is(err, ErrSessionClosed) {
+		t.Errorf("PushSession.Commit() error = %v, want %v", err, ErrSessionClosed)
+	}
+}
+
+func TestPushSession_Tag_NotFound(t *testing.T) {
+	ctx := context.Background()
+	session := NewPushSession(memory.New())
+	desc := content.NewDescriptorFromBytes("application/octet-stream", []byte("foo"))
+	if err := session.Tag(ctx, desc, "v1"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("PushSession.Tag() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+	if err := session.Tag(ctx, desc, ""); !errors.Is(err, errdef.ErrMissingReference) {
+		t.Errorf("PushSession.Tag() error = %v, want %v", err, errdef.ErrMissingReference)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestCopy_OnManifestPushed(t *testing.T) {
+	ctx := context.Background()
+	src, manifestDesc := pushImage(t, []byte("{}"), []byte("layer"), "image")
+	dst := memory.New()
+
+	emptyJSON := []byte("{}")
+	emptyDesc := content.NewDescriptorFromBytes("application/vnd.test.empty", emptyJSON)
+	var signed, signatures []ocispec.Descriptor
+	opts := CopyOptions{
+		OnManifestPushed: func(ctx context.Context, desc ocispec.Descriptor, pusher content.Pusher) error {
+			// the tag is not yet applied when the hook is called
+			if _, err := dst.Resolve(ctx, "signed"); !errors.Is(err, errdef.ErrNotFound) {
+				t.Errorf("Resolve() error = %v, want %v", err, errdef.ErrNotFound)
+			}
+			sigJSON, err := json.Marshal(ocispec.Manifest{
+				Versioned:    specs.Versioned{SchemaVersion: 2},
+				MediaType:    ocispec.MediaTypeImageManifest,
+				ArtifactType: "application/vnd.test.signature",
+				Config:       emptyDesc,
+				Layers:       []ocispec.Descriptor{emptyDesc},
+				Subject:      &desc,
+			})
+			if err != nil {
+				return err
+			}
+			if err := pusher.Push(ctx, emptyDesc, bytes.NewReader(emptyJSON)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+				return err
+			}
+			sigDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, sigJSON)
+			if err := pusher.Push(ctx, sigDesc, bytes.NewReader(sigJSON)); err != nil {
+				return err
+			}
+			signed = append(signed, desc)
+			signatures = append(signatures, sigDesc)
+			return nil
+		},
+	}
+	root, err := Copy(ctx, src, "image", dst, "signed", opts)
+	if err != nil {
+		t.Fatal("Copy() error =", err)
+	}
+	if len(signed) != 1 || signed[0].Digest != manifestDesc.Digest {
+		t.Fatalf("signed = %v, want [%v]", signed, manifestDesc.Digest)
+	}
+	if got, err := dst.Resolve(ctx, "signed"); err != nil || got.Digest != root.Digest {
+		t.Errorf("Resolve() = %v, %v, want %v", got.Digest, err, root.Digest)
+	}
+	predecessors, err := dst.Predecessors(ctx, root)
+	if err != nil {
+		t.Fatal("Predecessors() error =", err)
+	}
+	if len(predecessors) != 1 || predecessors[0].Digest != signatures[0].Digest {
+		t.Errorf("Predecessors() = %v, want [%v]", predecessors, signatures[0].Digest)
+	}
+}
+
+func TestCopy_OnManifestPushed_Error(t *testing.T) {
+	ctx := context.Background()
+	src, _ := pushImage(t, []byte("{}"), []byte("layer"), "image")
+	dst := memory.New()
+	errSign := errors.New("sign failed")
+	opts := CopyOptions{
+		OnManifestPushed: func(ctx context.Context, desc ocispec.Descriptor, pusher content.Pusher) error {
+			return errSign
+		},
+	}
+	if _, err := Copy(ctx, src, "image", dst, "signed", opts); !errors.Is(err, errSign) {
+		t.Fatalf("Copy() error = %v, want %v", err, errSign)
+	}
+	if _, err := dst.Resolve(ctx, "signed"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Resolve() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+}
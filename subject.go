@@ -0,0 +1,89 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+)
+
+// EnsureSubjectOptions contains parameters for [oras.EnsureSubject].
+type EnsureSubjectOptions struct {
+	CopyGraphOptions
+	// Source, if not nil, is the storage from which the graph of a subject
+	// missing in the destination is copied.
+	// If nil, a missing subject is an error.
+	Source content.ReadOnlyStorage
+}
+
+// EnsureSubject verifies that the subject exists in dst before a referrer of
+// it is pushed, so that the referrer does not dangle. If the subject does not
+// exist, its graph is copied from opts.Source, or an error wrapping
+// errdef.ErrNotFound is returned if opts.Source is nil.
+func EnsureSubject(ctx context.Context, dst content.Storage, subject ocispec.Descriptor, opts EnsureSubjectOptions) error {
+	exists, err := dst.Exists(ctx, subject)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	if opts.Source == nil {
+		return fmt.Errorf("subject %s: %s: %w", subject.Digest, subject.MediaType, errdef.ErrNotFound)
+	}
+	if err := CopyGraph(ctx, opts.Source, dst, subject, opts.CopyGraphOptions); err != nil {
+		return fmt.Errorf("failed to copy subject %s: %w", subject.Digest, err)
+	}
+	return nil
+}
+
+// EnsureSubjectPusher returns a content.PusherMiddleware ensuring the
+// subjects of the pushed manifests exist in dst with EnsureSubject before
+// pushing the manifests, so that raw pushes of referrers do not dangle
+// either. dst is usually the storage the middleware is applied to.
+func EnsureSubjectPusher(dst content.Storage, opts EnsureSubjectOptions) content.PusherMiddleware {
+	return func(next content.Pusher) content.Pusher {
+		return content.PusherFunc(func(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+			if !descriptor.IsManifest(expected) {
+				return next.Push(ctx, expected, r)
+			}
+			manifestJSON, err := content.ReadAll(r, expected)
+			if err != nil {
+				return err
+			}
+			var manifest struct {
+				Subject *ocispec.Descriptor `json:"subject,omitempty"`
+			}
+			if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+				return fmt.Errorf("failed to decode %s: %s: %w", expected.Digest, expected.MediaType, err)
+			}
+			if manifest.Subject != nil {
+				if err := EnsureSubject(ctx, dst, *manifest.Subject, opts); err != nil {
+					return err
+				}
+			}
+			return next.Push(ctx, expected, bytes.NewReader(manifestJSON))
+		})
+	}
+}
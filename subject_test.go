@@ -0,0 +1,135 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestEnsureSubject(t *testing.T) {
+	ctx := context.Background()
+	src, subject := pushImage(t, []byte("{}"), []byte("layer"), "latest")
+
+	// missing subject without source
+	dst := memory.New()
+	if err := EnsureSubject(ctx, dst, subject, EnsureSubjectOptions{}); !errors.Is(err, errdef.ErrNotFound) {
+		t.Fatalf("EnsureSubject() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+
+	// missing subject copied from source
+	if err := EnsureSubject(ctx, dst, subject, EnsureSubjectOptions{Source: src}); err != nil {
+		t.Fatal("EnsureSubject() error =", err)
+	}
+	successors, err := content.Successors(ctx, src, subject)
+	if err != nil {
+		t.Fatal("Successors() error =", err)
+	}
+	for _, desc := range append(successors, subject) {
+		if exists, err := dst.Exists(ctx, desc); err != nil || !exists {
+			t.Errorf("Exists(%s) = %v, %v, want true", desc.Digest, exists, err)
+		}
+	}
+
+	// existing subject
+	if err := EnsureSubject(ctx, dst, subject, EnsureSubjectOptions{}); err != nil {
+		t.Error("EnsureSubject() error =", err)
+	}
+}
+
+func TestPack_VerifySubject(t *testing.T) {
+	ctx := context.Background()
+	src, subject := pushImage(t, []byte("{}"), []byte("layer"), "latest")
+	dst := memory.New()
+	opts := PackOptions{
+		Subject:           &subject,
+		PackImageManifest: true,
+		VerifySubject:     true,
+	}
+	if _, err := Pack(ctx, dst, "application/vnd.test", nil, opts); !errors.Is(err, errdef.ErrNotFound) {
+		t.Fatalf("Pack() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+
+	opts.SubjectSource = src
+	referrer, err := Pack(ctx, dst, "application/vnd.test", nil, opts)
+	if err != nil {
+		t.Fatal("Pack() error =", err)
+	}
+	predecessors, err := dst.Predecessors(ctx, subject)
+	if err != nil {
+		t.Fatal("Predecessors() error =", err)
+	}
+	if len(predecessors) != 1 || predecessors[0].Digest != referrer.Digest {
+		t.Errorf("Predecessors() = %v, want [%v]", predecessors, referrer.Digest)
+	}
+	if exists, err := dst.Exists(ctx, subject); err != nil || !exists {
+		t.Errorf("Exists() = %v, %v, want true", exists, err)
+	}
+
+	// pushers not supporting existence checks
+	pusher := content.PusherFunc(dst.Push)
+	if _, err := Pack(ctx, pusher, "application/vnd.test", nil, opts); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("Pack() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+}
+
+func TestEnsureSubjectPusher(t *testing.T) {
+	ctx := context.Background()
+	src, subject := pushImage(t, []byte("{}"), []byte("layer"), "latest")
+	referrerJSON, err := json.Marshal(ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    content.NewDescriptorFromBytes("application/vnd.test", []byte("{}")),
+		Subject:   &subject,
+	})
+	if err != nil {
+		t.Fatal("json.Marshal() error =", err)
+	}
+	referrer := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, referrerJSON)
+
+	store := memory.New()
+	dst := content.WithPusherMiddleware(store, EnsureSubjectPusher(store, EnsureSubjectOptions{}))
+	if err := dst.Push(ctx, referrer, bytes.NewReader(referrerJSON)); !errors.Is(err, errdef.ErrNotFound) {
+		t.Fatalf("Push() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+	if exists, err := store.Exists(ctx, referrer); err != nil || exists {
+		t.Errorf("Exists() = %v, %v, want false", exists, err)
+	}
+
+	dst = content.WithPusherMiddleware(store, EnsureSubjectPusher(store, EnsureSubjectOptions{Source: src}))
+	if err := dst.Push(ctx, referrer, bytes.NewReader(referrerJSON)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	for _, desc := range []ocispec.Descriptor{subject, referrer} {
+		if exists, err := store.Exists(ctx, desc); err != nil || !exists {
+			t.Errorf("Exists(%s) = %v, %v, want true", desc.Digest, exists, err)
+		}
+	}
+
+	// blobs pass through
+	blob := []byte("blob")
+	blobDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+	if err := dst.Push(ctx, blobDesc, bytes.NewReader(blob)); err != nil {
+		t.Error("Push() error =", err)
+	}
+}
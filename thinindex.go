@@ -0,0 +1,135 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/docker"
+	"oras.land/oras-go/v2/internal/platform"
+)
+
+// ThinIndexOptions contains parameters for [oras.ThinIndex].
+type ThinIndexOptions struct {
+	CopyGraphOptions
+	// Platforms are the platforms to be kept in the index. A manifest is kept
+	// if its platform matches any of the platforms. See WithTargetPlatform
+	// for the matching rules.
+	Platforms []*ocispec.Platform
+}
+
+// ThinIndex copies the sub-graphs of the manifests of the selected platforms
+// in the multi-platform index tagged by srcRef in the source to the
+// destination, and then creates an index referencing only those manifests,
+// tagged by dstRef in the destination.
+// The destination reference will be the same as the source reference if the
+// destination reference is left blank.
+//
+// The new index retains the media type and the annotations of the source
+// index. Manifests without platforms are not kept. If all the manifests are
+// kept, the source index is copied as is.
+// Returns the descriptor of the new index on success.
+func ThinIndex(ctx context.Context, src ReadOnlyTarget, srcRef string, dst Target, dstRef string, opts ThinIndexOptions) (ocispec.Descriptor, error) {
+	if src == nil {
+		return ocispec.Descriptor{}, errors.New("nil source target")
+	}
+	if dst == nil {
+		return ocispec.Descriptor{}, errors.New("nil destination target")
+	}
+	if len(opts.Platforms) == 0 {
+		return ocispec.Descriptor{}, errors.New("no platforms to select")
+	}
+	if dstRef == "" {
+		dstRef = srcRef
+	}
+
+	root, err := src.Resolve(ctx, srcRef)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to resolve %s: %w", srcRef, err)
+	}
+	switch root.MediaType {
+	case ocispec.MediaTypeImageIndex, docker.MediaTypeManifestList:
+	default:
+		return ocispec.Descriptor{}, fmt.Errorf("%s: %s: %s: not a multi-platform index: %w", srcRef, root.Digest, root.MediaType, errdef.ErrUnsupported)
+	}
+	indexJSON, err := content.FetchAll(ctx, src, root)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(indexJSON, &index); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to decode index %s: %w", root.Digest, err)
+	}
+
+	var manifests []ocispec.Descriptor
+	for _, m := range index.Manifests {
+		if matchPlatforms(m.Platform, opts.Platforms) {
+			manifests = append(manifests, m)
+		}
+	}
+	if len(manifests) == 0 {
+		return ocispec.Descriptor{}, fmt.Errorf("%s: no manifest matches the platforms: %w", srcRef, errdef.ErrNotFound)
+	}
+	if len(manifests) == len(index.Manifests) {
+		if err := CopyGraph(ctx, src, dst, root, opts.CopyGraphOptions); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		if err := dst.Tag(ctx, root, dstRef); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		return root, nil
+	}
+
+	for _, m := range manifests {
+		if err := CopyGraph(ctx, src, dst, m, opts.CopyGraphOptions); err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("failed to copy %s: %w", m.Digest, err)
+		}
+	}
+	index.Manifests = manifests
+	thinJSON, err := json.Marshal(index)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to marshal index: %w", err)
+	}
+	thinDesc := content.NewDescriptorFromBytes(root.MediaType, thinJSON)
+	thinDesc.Annotations = index.Annotations
+	if err := dst.Push(ctx, thinDesc, bytes.NewReader(thinJSON)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to push index: %w", err)
+	}
+	if err := dst.Tag(ctx, thinDesc, dstRef); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return thinDesc, nil
+}
+
+// matchPlatforms returns true if p matches any of the wanted platforms.
+func matchPlatforms(p *ocispec.Platform, wanted []*ocispec.Platform) bool {
+	if p == nil {
+		return false
+	}
+	for _, want := range wanted {
+		if want != nil && platform.Match(p, want) {
+			return true
+		}
+	}
+	return false
+}
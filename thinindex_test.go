@@ -0,0 +1,118 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestThinIndex(t *testing.T) {
+	ctx := context.Background()
+	amd64, amd64Manifest := pushImage(t, []byte(`{"architecture":"amd64","os":"linux"}`), []byte("amd64"), "latest")
+	arm64, arm64Manifest := pushImage(t, []byte(`{"architecture":"arm64","os":"linux","variant":"v8"}`), []byte("arm64"), "latest")
+	src := memory.New()
+	annotations := map[string]string{"foo": "bar"}
+	root, err := AssembleIndex(ctx, []PlatformImage{
+		{Source: amd64, Reference: "latest"},
+		{Source: arm64, Reference: "latest"},
+	}, src, "v1", AssembleIndexOptions{
+		ManifestAnnotations: annotations,
+	})
+	if err != nil {
+		t.Fatal("AssembleIndex() error =", err)
+	}
+
+	dst := memory.New()
+	thin, err := ThinIndex(ctx, src, "v1", dst, "", ThinIndexOptions{
+		Platforms: []*ocispec.Platform{{Architecture: "amd64", OS: "linux"}},
+	})
+	if err != nil {
+		t.Fatal("ThinIndex() error =", err)
+	}
+	if content.Equal(thin, root) {
+		t.Errorf("ThinIndex() = %v, want a new index", thin)
+	}
+	if got, err := dst.Resolve(ctx, "v1"); err != nil || !content.Equal(got, thin) {
+		t.Errorf("Store.Resolve() = %v, %v, want %v", got, err, thin)
+	}
+	indexJSON, err := content.FetchAll(ctx, dst, thin)
+	if err != nil {
+		t.Fatal("Store.Fetch() error =", err)
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(indexJSON, &index); err != nil {
+		t.Fatal("json.Unmarshal() error =", err)
+	}
+	if !reflect.DeepEqual(index.Annotations, annotations) {
+		t.Errorf("Index.Annotations = %v, want %v", index.Annotations, annotations)
+	}
+	if len(index.Manifests) != 1 || index.Manifests[0].Digest != amd64Manifest.Digest {
+		t.Errorf("Index.Manifests = %v, want %s", index.Manifests, amd64Manifest.Digest)
+	}
+	if err := CopyGraph(ctx, dst, memory.New(), amd64Manifest, CopyGraphOptions{}); err != nil {
+		t.Errorf("image %s not fully copied: %v", amd64Manifest.Digest, err)
+	}
+	if exists, err := dst.Exists(ctx, arm64Manifest); err != nil || exists {
+		t.Errorf("Store.Exists(%s) = %v, %v, want false", arm64Manifest.Digest, exists, err)
+	}
+
+	// selecting all the platforms keeps the source index
+	dst = memory.New()
+	got, err := ThinIndex(ctx, src, "v1", dst, "v2", ThinIndexOptions{
+		Platforms: []*ocispec.Platform{
+			{Architecture: "amd64", OS: "linux"},
+			{Architecture: "arm64", OS: "linux"},
+		},
+	})
+	if err != nil {
+		t.Fatal("ThinIndex() error =", err)
+	}
+	if !content.Equal(got, root) {
+		t.Errorf("ThinIndex() = %v, want %v", got, root)
+	}
+	if got, err := dst.Resolve(ctx, "v2"); err != nil || !content.Equal(got, root) {
+		t.Errorf("Store.Resolve() = %v, %v, want %v", got, err, root)
+	}
+}
+
+func TestThinIndex_Invalid(t *testing.T) {
+	ctx := context.Background()
+	src, _ := pushImage(t, []byte(`{"architecture":"amd64","os":"linux"}`), []byte("amd64"), "image")
+	if _, err := AssembleIndex(ctx, []PlatformImage{{Source: src, Reference: "image"}}, src, "index", AssembleIndexOptions{}); err != nil {
+		t.Fatal("AssembleIndex() error =", err)
+	}
+	amd64 := []*ocispec.Platform{{Architecture: "amd64", OS: "linux"}}
+
+	if _, err := ThinIndex(ctx, src, "image", memory.New(), "", ThinIndexOptions{Platforms: amd64}); !errors.Is(err, errdef.ErrUnsupported) {
+		t.Errorf("ThinIndex() error = %v, want %v", err, errdef.ErrUnsupported)
+	}
+	riscv := []*ocispec.Platform{{Architecture: "riscv64", OS: "linux"}}
+	if _, err := ThinIndex(ctx, src, "index", memory.New(), "", ThinIndexOptions{Platforms: riscv}); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("ThinIndex() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+	if _, err := ThinIndex(ctx, src, "index", memory.New(), "", ThinIndexOptions{}); err == nil {
+		t.Error("ThinIndex() error = nil, want error")
+	}
+}
@@ -0,0 +1,39 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+// Attribute keys used by the ORAS library.
+const (
+	AttributeKeyDigest         = "oras.digest"
+	AttributeKeyMediaType      = "oras.media_type"
+	AttributeKeySize           = "oras.size"
+	AttributeKeyReference      = "oras.reference"
+	AttributeKeyRepository     = "oras.repository"
+	AttributeKeyHTTPMethod     = "http.method"
+	AttributeKeyHTTPURL        = "http.url"
+	AttributeKeyHTTPStatusCode = "http.status_code"
+)
+
+// DescriptorAttributes returns the attributes describing desc.
+func DescriptorAttributes(desc ocispec.Descriptor) []Attribute {
+	return []Attribute{
+		String(AttributeKeyDigest, desc.Digest.String()),
+		String(AttributeKeyMediaType, desc.MediaType),
+		Int64(AttributeKeySize, desc.Size),
+	}
+}
@@ -0,0 +1,21 @@
+module oras.land/oras-go/v2/tracing/otel
+
+go 1.19
+
+require (
+	go.opentelemetry.io/otel v1.14.0
+	go.opentelemetry.io/otel/sdk v1.14.0
+	go.opentelemetry.io/otel/trace v1.14.0
+	oras.land/oras-go/v2 v2.0.0
+)
+
+require (
+	github.com/go-logr/logr v1.2.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/opencontainers/go-digest v1.0.0 // indirect
+	github.com/opencontainers/image-spec v1.1.0-rc.3 // indirect
+	golang.org/x/sync v0.2.0 // indirect
+	golang.org/x/sys v0.5.0 // indirect
+)
+
+replace oras.land/oras-go/v2 => ../..
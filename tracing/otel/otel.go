@@ -0,0 +1,95 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package otel adapts OpenTelemetry tracers to the tracing package, so that
+// the operations of the ORAS library are traced by OpenTelemetry.
+//
+// Tracing is enabled for an operation by attaching the adapted tracer to its
+// context:
+//
+//	tracer := otel.NewTracer(otelapi.Tracer("oras"))
+//	ctx = tracing.WithTracer(ctx, tracer)
+//
+// where otelapi is the go.opentelemetry.io/otel package.
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"oras.land/oras-go/v2/tracing"
+)
+
+// tracer is a tracing.Tracer starting OpenTelemetry spans.
+type tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer returns a tracing.Tracer starting spans with the given
+// OpenTelemetry tracer.
+func NewTracer(t trace.Tracer) tracing.Tracer {
+	return &tracer{tracer: t}
+}
+
+// Start starts a span with the given name and attributes, and returns a
+// context containing the span.
+func (t *tracer) Start(ctx context.Context, name string, attrs ...tracing.Attribute) (context.Context, tracing.Span) {
+	ctx, s := t.tracer.Start(ctx, name, trace.WithAttributes(convertAttributes(attrs)...))
+	return ctx, &span{span: s}
+}
+
+// span is a tracing.Span wrapping an OpenTelemetry span.
+type span struct {
+	span trace.Span
+}
+
+// SetAttributes sets attributes on the span.
+func (s *span) SetAttributes(attrs ...tracing.Attribute) {
+	s.span.SetAttributes(convertAttributes(attrs)...)
+}
+
+// End ends the span. A non-nil err is recorded on the span, and marks the span
+// as failed.
+func (s *span) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+// convertAttributes converts attributes to OpenTelemetry attributes.
+func convertAttributes(attrs []tracing.Attribute) []attribute.KeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		switch value := attr.Value.(type) {
+		case string:
+			kvs = append(kvs, attribute.String(attr.Key, value))
+		case int64:
+			kvs = append(kvs, attribute.Int64(attr.Key, value))
+		case bool:
+			kvs = append(kvs, attribute.Bool(attr.Key, value))
+		default:
+			kvs = append(kvs, attribute.String(attr.Key, fmt.Sprint(value)))
+		}
+	}
+	return kvs
+}
@@ -0,0 +1,94 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package otel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/tracing"
+	"oras.land/oras-go/v2/tracing/otel"
+)
+
+func TestNewTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx := tracing.WithTracer(context.Background(), otel.NewTracer(provider.Tracer("test")))
+
+	// spans of the instrumented operations are started
+	store := memory.New()
+	desc := content.NewDescriptorFromBytes("test", []byte("hello"))
+	if _, err := store.Exists(ctx, desc); err != nil {
+		t.Fatal("Store.Exists() error =", err)
+	}
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if got, want := spans[0].Name(), "memory.Store.Exists"; got != want {
+		t.Errorf("span name = %v, want %v", got, want)
+	}
+	wantAttrs := []attribute.KeyValue{
+		attribute.String(tracing.AttributeKeyDigest, desc.Digest.String()),
+		attribute.String(tracing.AttributeKeyMediaType, "test"),
+		attribute.Int64(tracing.AttributeKeySize, 5),
+	}
+	if got := spans[0].Attributes(); !equalAttributes(got, wantAttrs) {
+		t.Errorf("span attributes = %v, want %v", got, wantAttrs)
+	}
+
+	// attributes and errors are recorded
+	_, span := tracing.Start(ctx, "test", tracing.Bool("foo", true))
+	span.SetAttributes(tracing.Int64("bar", 42))
+	span.End(errors.New("test error"))
+	spans = recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	got := spans[1]
+	wantAttrs = []attribute.KeyValue{
+		attribute.Bool("foo", true),
+		attribute.Int64("bar", 42),
+	}
+	if !equalAttributes(got.Attributes(), wantAttrs) {
+		t.Errorf("span attributes = %v, want %v", got.Attributes(), wantAttrs)
+	}
+	if status := got.Status(); status.Code != codes.Error || status.Description != "test error" {
+		t.Errorf("span status = %+v, want error with description %q", status, "test error")
+	}
+	if events := got.Events(); len(events) != 1 || events[0].Name != "exception" {
+		t.Errorf("span events = %v, want an exception event", events)
+	}
+}
+
+func equalAttributes(got, want []attribute.KeyValue) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
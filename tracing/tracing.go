@@ -0,0 +1,102 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing provides a minimal tracing abstraction used to instrument
+// the ORAS library.
+//
+// Tracing is disabled by default. It is enabled for an operation by attaching
+// a Tracer to the context using WithTracer. The Tracer interface is designed
+// to be easily adapted to tracing SDKs. The OpenTelemetry adapter is provided
+// by the oras.land/oras-go/v2/tracing/otel module.
+//
+// Copy, CopyGraph, the memory and OCI layout stores, remote repositories and
+// the HTTP requests of auth.Client are instrumented.
+package tracing
+
+import "context"
+
+// Attribute is a key-value pair describing a span.
+type Attribute struct {
+	// Key is the attribute key, such as "oras.digest".
+	Key string
+	// Value is the attribute value, which is one of string, int64, or bool.
+	Value interface{}
+}
+
+// String returns a string attribute.
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int64 returns an int64 attribute.
+func Int64(key string, value int64) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Bool returns a bool attribute.
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Tracer starts spans.
+type Tracer interface {
+	// Start starts a span with the given name and attributes, and returns a
+	// context containing the span.
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span represents a single operation within a trace.
+type Span interface {
+	// SetAttributes sets attributes on the span.
+	SetAttributes(attrs ...Attribute)
+	// End ends the span. A non-nil err marks the span as failed.
+	End(err error)
+}
+
+// tracerContextKey is the context key for the tracer.
+type tracerContextKey struct{}
+
+// WithTracer returns a context with the tracer attached.
+// All the instrumented operations using the returned context will be traced
+// by the tracer.
+func WithTracer(ctx context.Context, tracer Tracer) context.Context {
+	return context.WithValue(ctx, tracerContextKey{}, tracer)
+}
+
+// TracerFromContext returns the tracer attached to the context, or nil if no
+// tracer is attached.
+func TracerFromContext(ctx context.Context) Tracer {
+	tracer, _ := ctx.Value(tracerContextKey{}).(Tracer)
+	return tracer
+}
+
+// Start starts a span using the tracer attached to the context.
+// If no tracer is attached, ctx is returned with a no-op span.
+func Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	tracer := TracerFromContext(ctx)
+	if tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return tracer.Start(ctx, name, attrs...)
+}
+
+// noopSpan is a span doing nothing.
+type noopSpan struct{}
+
+// SetAttributes does nothing.
+func (noopSpan) SetAttributes(...Attribute) {}
+
+// End does nothing.
+func (noopSpan) End(error) {}
@@ -0,0 +1,107 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/tracing"
+)
+
+// recordedSpan is a span recorded by recordingTracer.
+type recordedSpan struct {
+	name   string
+	attrs  []tracing.Attribute
+	err    error
+	ended  bool
+	tracer *recordingTracer
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...tracing.Attribute) {
+	s.tracer.lock.Lock()
+	defer s.tracer.lock.Unlock()
+	s.attrs = append(s.attrs, attrs...)
+}
+
+func (s *recordedSpan) End(err error) {
+	s.tracer.lock.Lock()
+	defer s.tracer.lock.Unlock()
+	s.err = err
+	s.ended = true
+}
+
+// recordingTracer records all the started spans.
+type recordingTracer struct {
+	lock  sync.Mutex
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs ...tracing.Attribute) (context.Context, tracing.Span) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	span := &recordedSpan{
+		name:   name,
+		attrs:  attrs,
+		tracer: t,
+	}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func TestStart(t *testing.T) {
+	ctx := context.Background()
+	if tracer := tracing.TracerFromContext(ctx); tracer != nil {
+		t.Errorf("TracerFromContext() = %v, want nil", tracer)
+	}
+	// no tracer attached
+	gotCtx, span := tracing.Start(ctx, "test")
+	if gotCtx != ctx {
+		t.Error("Start() returns a different context without tracer")
+	}
+	span.SetAttributes(tracing.Bool("foo", true))
+	span.End(nil)
+
+	// tracer attached
+	tracer := &recordingTracer{}
+	ctx = tracing.WithTracer(ctx, tracer)
+	if got := tracing.TracerFromContext(ctx); got != tracer {
+		t.Errorf("TracerFromContext() = %v, want %v", got, tracer)
+	}
+	desc := content.NewDescriptorFromBytes("test", []byte("hello"))
+	_, span = tracing.Start(ctx, "test", tracing.DescriptorAttributes(desc)...)
+	span.SetAttributes(tracing.Int64("foo", 42))
+	errTest := errors.New("test")
+	span.End(errTest)
+
+	if len(tracer.spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(tracer.spans))
+	}
+	got := tracer.spans[0]
+	wantAttrs := []tracing.Attribute{
+		tracing.String(tracing.AttributeKeyDigest, desc.Digest.String()),
+		tracing.String(tracing.AttributeKeyMediaType, "test"),
+		tracing.Int64(tracing.AttributeKeySize, 5),
+		tracing.Int64("foo", 42),
+	}
+	if got.name != "test" || !got.ended || got.err != errTest || !reflect.DeepEqual(got.attrs, wantAttrs) {
+		t.Errorf("span = %+v, want name test, attributes %v, ended with %v", got, wantAttrs, errTest)
+	}
+}
@@ -0,0 +1,256 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/sync/semaphore"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/syncutil"
+)
+
+// TransferManagerOptions contains parameters for [oras.NewTransferManager].
+type TransferManagerOptions struct {
+	// MaxConcurrentFetches limits the number of content streams fetched
+	// concurrently across all the operations of the manager. A fetch holds
+	// a slot until its stream is closed.
+	// If less than or equal to 0, fetches are not limited.
+	MaxConcurrentFetches int64
+	// MaxConcurrentPushes limits the number of content pushed concurrently
+	// across all the operations of the manager.
+	// Fetches and pushes are limited separately, so that a copy streaming
+	// fetched content into a push never waits for a slot held by itself.
+	// If less than or equal to 0, pushes are not limited.
+	MaxConcurrentPushes int64
+	// FetchBytesPerSecond limits the total rate of the content read from the
+	// fetched streams across all the operations of the manager.
+	// If less than or equal to 0, the rate is not limited.
+	FetchBytesPerSecond int64
+	// PushBytesPerSecond limits the total rate of the content pushed across
+	// all the operations of the manager.
+	// If less than or equal to 0, the rate is not limited.
+	PushBytesPerSecond int64
+	// ExistenceCache is the existence cache shared by the copy operations of
+	// the manager, unless the operations specify their own.
+	// If nil, no cache is shared.
+	ExistenceCache *ExistenceCache
+}
+
+// TransferManager bounds the total resource usage of the transfers of many
+// concurrent operations in a process, such as a service running dozens of
+// replications at once, by sharing semaphores, rate limiters and caches
+// across the operations.
+//
+// A TransferManager is safe for concurrent use.
+type TransferManager struct {
+	opts         TransferManagerOptions
+	fetchSem     *semaphore.Weighted
+	pushSem      *semaphore.Weighted
+	fetchLimiter *syncutil.RateLimiter
+	pushLimiter  *syncutil.RateLimiter
+}
+
+// NewTransferManager creates a TransferManager.
+func NewTransferManager(opts TransferManagerOptions) *TransferManager {
+	m := &TransferManager{
+		opts:         opts,
+		fetchLimiter: syncutil.NewRateLimiter(opts.FetchBytesPerSecond),
+		pushLimiter:  syncutil.NewRateLimiter(opts.PushBytesPerSecond),
+	}
+	if opts.MaxConcurrentFetches > 0 {
+		m.fetchSem = semaphore.NewWeighted(opts.MaxConcurrentFetches)
+	}
+	if opts.MaxConcurrentPushes > 0 {
+		m.pushSem = semaphore.NewWeighted(opts.MaxConcurrentPushes)
+	}
+	return m
+}
+
+// FetcherMiddleware returns a content.FetcherMiddleware subjecting fetches to
+// the limits of the manager.
+func (m *TransferManager) FetcherMiddleware() content.FetcherMiddleware {
+	return func(next content.Fetcher) content.Fetcher {
+		return content.FetcherFunc(func(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+			if m.fetchSem != nil {
+				if err := m.fetchSem.Acquire(ctx, 1); err != nil {
+					return nil, err
+				}
+			}
+			rc, err := next.Fetch(ctx, target)
+			if err != nil {
+				m.releaseFetch()
+				return nil, err
+			}
+			return &managedReadCloser{
+				ctx:     ctx,
+				rc:      rc,
+				limiter: m.fetchLimiter,
+				release: m.releaseFetch,
+			}, nil
+		})
+	}
+}
+
+// PusherMiddleware returns a content.PusherMiddleware subjecting pushes to the
+// limits of the manager.
+func (m *TransferManager) PusherMiddleware() content.PusherMiddleware {
+	return func(next content.Pusher) content.Pusher {
+		return content.PusherFunc(func(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+			if m.pushSem != nil {
+				if err := m.pushSem.Acquire(ctx, 1); err != nil {
+					return err
+				}
+				defer m.pushSem.Release(1)
+			}
+			if m.pushLimiter != nil {
+				r = &managedReadCloser{
+					ctx:     ctx,
+					rc:      io.NopCloser(r),
+					limiter: m.pushLimiter,
+				}
+			}
+			return next.Push(ctx, expected, r)
+		})
+	}
+}
+
+// releaseFetch releases a fetch slot.
+func (m *TransferManager) releaseFetch() {
+	if m.fetchSem != nil {
+		m.fetchSem.Release(1)
+	}
+}
+
+// Source wraps t so that fetches from t are subject to the limits of the
+// manager.
+func (m *TransferManager) Source(t ReadOnlyTarget) ReadOnlyTarget {
+	return WithReadOnlyFetcherMiddleware(t, m.FetcherMiddleware())
+}
+
+// Destination wraps t so that fetches from and pushes to t are subject to the
+// limits of the manager.
+func (m *TransferManager) Destination(t Target) Target {
+	return WithPusherMiddleware(WithFetcherMiddleware(t, m.FetcherMiddleware()), m.PusherMiddleware())
+}
+
+// CopyGraphOptions returns opts with the shared existence cache of the
+// manager applied if opts has none.
+func (m *TransferManager) CopyGraphOptions(opts CopyGraphOptions) CopyGraphOptions {
+	if opts.ExistenceCache == nil {
+		opts.ExistenceCache = m.opts.ExistenceCache
+	}
+	return opts
+}
+
+// Copy copies a rooted directed acyclic graph like oras.Copy, subject to the
+// limits of the manager.
+func (m *TransferManager) Copy(ctx context.Context, src ReadOnlyTarget, srcRef string, dst Target, dstRef string, opts CopyOptions) (ocispec.Descriptor, error) {
+	opts.CopyGraphOptions = m.CopyGraphOptions(opts.CopyGraphOptions)
+	return Copy(ctx, m.Source(src), srcRef, m.Destination(dst), dstRef, opts)
+}
+
+// CopyGraph copies a rooted directed acyclic graph like oras.CopyGraph,
+// subject to the limits of the manager.
+func (m *TransferManager) CopyGraph(ctx context.Context, src content.ReadOnlyStorage, dst content.Storage, root ocispec.Descriptor, opts CopyGraphOptions) error {
+	src = content.WithFetcherMiddleware(readOnlyStorage{src}, m.FetcherMiddleware())
+	dst = content.WithPusherMiddleware(content.WithFetcherMiddleware(dst, m.FetcherMiddleware()), m.PusherMiddleware())
+	return CopyGraph(ctx, src, dst, root, m.CopyGraphOptions(opts))
+}
+
+// readOnlyStorage adapts a content.ReadOnlyStorage to a content.Storage
+// refusing pushes, so that storage middlewares can be applied.
+type readOnlyStorage struct {
+	content.ReadOnlyStorage
+}
+
+// Push returns ErrUnsupported.
+func (readOnlyStorage) Push(_ context.Context, expected ocispec.Descriptor, _ io.Reader) error {
+	return fmt.Errorf("%s: %s: push to read-only storage: %w", expected.Digest, expected.MediaType, errdef.ErrUnsupported)
+}
+
+// throttledStorage is a storage whose fetched content is read subject to a
+// rate limiter.
+type throttledStorage struct {
+	content.ReadOnlyStorage
+	limiter *syncutil.RateLimiter
+}
+
+// throttleSource wraps src to throttle the content fetched from it by
+// opts.MaxBytesPerSecond, and clears opts.MaxBytesPerSecond so that src is
+// throttled once by operations copying multiple graphs.
+func throttleSource(src content.ReadOnlyStorage, opts *CopyGraphOptions) content.ReadOnlyStorage {
+	if opts.MaxBytesPerSecond <= 0 {
+		return src
+	}
+	limiter := syncutil.NewRateLimiter(opts.MaxBytesPerSecond)
+	opts.MaxBytesPerSecond = 0
+	return &throttledStorage{
+		ReadOnlyStorage: src,
+		limiter:         limiter,
+	}
+}
+
+// Fetch fetches the content identified by the descriptor, throttling the
+// reads of the content.
+func (s *throttledStorage) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	rc, err := s.ReadOnlyStorage.Fetch(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	return &managedReadCloser{
+		ctx:     ctx,
+		rc:      rc,
+		limiter: s.limiter,
+	}, nil
+}
+
+// managedReadCloser is a stream subject to a rate limiter, releasing its
+// transfer slot on close.
+type managedReadCloser struct {
+	ctx       context.Context
+	rc        io.ReadCloser
+	limiter   *syncutil.RateLimiter
+	release   func()
+	closeOnce sync.Once
+}
+
+// Read reads from the stream, waiting for the rate limiter.
+func (r *managedReadCloser) Read(p []byte) (int, error) {
+	n, err := r.rc.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.Wait(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+// Close closes the stream and releases its transfer slot.
+func (r *managedReadCloser) Close() error {
+	err := r.rc.Close()
+	r.closeOnce.Do(func() {
+		if r.release != nil {
+			r.release()
+		}
+	})
+	return err
+}
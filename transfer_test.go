@@ -0,0 +1,155 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+// activeFetchTarget is a Target tracking the maximum number of content
+// streams open concurrently.
+type activeFetchTarget struct {
+	*memory.Store
+	active    int64
+	maxActive int64
+}
+
+func (t *activeFetchTarget) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	rc, err := t.Store.Fetch(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	active := atomic.AddInt64(&t.active, 1)
+	for {
+		prev := atomic.LoadInt64(&t.maxActive)
+		if active <= prev || atomic.CompareAndSwapInt64(&t.maxActive, prev, active) {
+			break
+		}
+	}
+	// hold the stream open for a while to overlap concurrent fetches
+	time.Sleep(10 * time.Millisecond)
+	return &activeReadCloser{ReadCloser: rc, active: &t.active}, nil
+}
+
+type activeReadCloser struct {
+	io.ReadCloser
+	active *int64
+	once   sync.Once
+}
+
+func (rc *activeReadCloser) Close() error {
+	rc.once.Do(func() {
+		atomic.AddInt64(rc.active, -1)
+	})
+	return rc.ReadCloser.Close()
+}
+
+func TestTransferManager_Copy(t *testing.T) {
+	ctx := context.Background()
+	var sources []*activeFetchTarget
+	for i := 0; i < 4; i++ {
+		store, _ := pushImage(t, []byte(fmt.Sprintf(`{"i":%d}`, i)), []byte(fmt.Sprintf("layer %d", i)), "latest")
+		sources = append(sources, &activeFetchTarget{Store: store})
+	}
+
+	cache := NewExistenceCache()
+	manager := NewTransferManager(TransferManagerOptions{
+		MaxConcurrentFetches: 1,
+		MaxConcurrentPushes:  1,
+		ExistenceCache:       cache,
+	})
+	dst := memory.New()
+	var wg sync.WaitGroup
+	errs := make([]error, len(sources))
+	for i, src := range sources {
+		wg.Add(1)
+		go func(i int, src *activeFetchTarget) {
+			defer wg.Done()
+			_, errs[i] = manager.Copy(ctx, src, "latest", dst, fmt.Sprintf("v%d", i), CopyOptions{})
+		}(i, src)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("TransferManager.Copy() #%d error = %v", i, err)
+		}
+	}
+
+	// the fetches across all copies are bounded by the manager
+	var maxActive int64
+	for _, src := range sources {
+		if src.maxActive > maxActive {
+			maxActive = src.maxActive
+		}
+		if src.active != 0 {
+			t.Errorf("%d fetched streams are not closed", src.active)
+		}
+	}
+	if maxActive != 1 {
+		t.Errorf("max concurrent fetches = %d, want 1", maxActive)
+	}
+	for i := range sources {
+		if _, err := dst.Resolve(ctx, fmt.Sprintf("v%d", i)); err != nil {
+			t.Errorf("Store.Resolve() error = %v", err)
+		}
+	}
+}
+
+func TestTransferManager_CopyGraph(t *testing.T) {
+	ctx := context.Background()
+	src, root := pushImage(t, []byte(`{}`), []byte("foo"), "latest")
+	manager := NewTransferManager(TransferManagerOptions{
+		MaxConcurrentFetches: 1,
+		PushBytesPerSecond:   1024 * 1024,
+	})
+	dst := memory.New()
+	if err := manager.CopyGraph(ctx, src, dst, root, CopyGraphOptions{}); err != nil {
+		t.Fatalf("TransferManager.CopyGraph() error = %v", err)
+	}
+	if exists, err := dst.Exists(ctx, root); err != nil || !exists {
+		t.Errorf("Store.Exists() = %v, %v, want true", exists, err)
+	}
+}
+
+func TestCopyGraph_MaxBytesPerSecond(t *testing.T) {
+	ctx := context.Background()
+	// 1.5 seconds of content at the rate, where the first second is a burst
+	layer := make([]byte, 15000)
+	src, manifest := pushImage(t, []byte("{}"), layer, "latest")
+
+	opts := CopyGraphOptions{
+		MaxBytesPerSecond: 10000,
+	}
+	start := time.Now()
+	if err := CopyGraph(ctx, src, memory.New(), manifest, opts); err != nil {
+		t.Fatal("CopyGraph() error =", err)
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("CopyGraph() took %v, want throttled to at least 400ms", elapsed)
+	}
+	if opts.MaxBytesPerSecond != 10000 {
+		t.Errorf("CopyGraphOptions.MaxBytesPerSecond = %d, want unchanged", opts.MaxBytesPerSecond)
+	}
+}
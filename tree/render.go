@@ -0,0 +1,89 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tree
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"oras.land/oras-go/v2/annotation"
+)
+
+// Tree drawing symbols.
+const (
+	branch     = "├── "
+	lastBranch = "└── "
+	vertical   = "│   "
+	space      = "    "
+)
+
+// WriteText renders the tree rooted at root as text, one node per line.
+// The root is rendered as its digest. Successors are rendered as their media
+// types and digests, and referrers as their artifact types and digests.
+// For example:
+//
+//	sha256:9d16...
+//	├── application/vnd.oci.image.config.v1+json sha256:44136...
+//	├── application/vnd.oci.image.layer.v1.tar+gzip sha256:a1b2... layer.tar.gz
+//	└── [referrer] application/spdx+json sha256:5c6d...
+func WriteText(w io.Writer, root *Node) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(root.Digest.String())
+	bw.WriteString("\n")
+	writeChildren(bw, root, "")
+	return bw.Flush()
+}
+
+// writeChildren writes the children of the node with the given prefix.
+func writeChildren(w *bufio.Writer, node *Node, prefix string) {
+	for i, child := range node.Children {
+		connector, indent := branch, vertical
+		if i == len(node.Children)-1 {
+			connector, indent = lastBranch, space
+		}
+		w.WriteString(prefix)
+		w.WriteString(connector)
+		w.WriteString(label(child))
+		w.WriteString("\n")
+		writeChildren(w, child, prefix+indent)
+	}
+}
+
+// label returns the label of a non-root node.
+func label(node *Node) string {
+	var s string
+	if node.Relation == RelationReferrer {
+		artifactType := node.ArtifactType
+		if artifactType == "" {
+			artifactType = node.MediaType
+		}
+		s = "[referrer] " + artifactType + " " + node.Digest.String()
+	} else {
+		s = node.MediaType + " " + node.Digest.String()
+	}
+	if title := annotation.Title(node.Annotations); title != "" {
+		s += " " + title
+	}
+	return s
+}
+
+// WriteJSON renders the tree rooted at root as indented JSON.
+func WriteJSON(w io.Writer, root *Node) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(root)
+}
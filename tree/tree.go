@@ -0,0 +1,157 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tree builds artifact graphs into trees of manifests, their
+// successors and their referrers, and renders the trees as text or JSON for
+// `oras discover`-style views.
+package tree
+
+import (
+	"context"
+	"errors"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+)
+
+// Relations of nodes to their parents.
+const (
+	// RelationSuccessor indicates that the node is a successor of its parent,
+	// such as a layer of a manifest or a manifest of an index.
+	RelationSuccessor = "successor"
+	// RelationReferrer indicates that the node is a referrer of its parent.
+	RelationReferrer = "referrer"
+)
+
+// Node is a node in the tree.
+type Node struct {
+	// MediaType is the media type of the node.
+	MediaType string `json:"mediaType"`
+	// Digest is the digest of the node.
+	Digest digest.Digest `json:"digest"`
+	// Size is the size of the node.
+	Size int64 `json:"size"`
+	// ArtifactType is the artifact type of the node, if any.
+	ArtifactType string `json:"artifactType,omitempty"`
+	// Annotations are the annotations of the node, if any.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Relation is the relation of the node to its parent.
+	// It is empty for the root.
+	Relation string `json:"relation,omitempty"`
+	// Children are the successors and the referrers of the node.
+	Children []*Node `json:"children,omitempty"`
+}
+
+// Descriptor returns the descriptor of the node.
+func (n *Node) Descriptor() ocispec.Descriptor {
+	return ocispec.Descriptor{
+		MediaType:    n.MediaType,
+		Digest:       n.Digest,
+		Size:         n.Size,
+		ArtifactType: n.ArtifactType,
+		Annotations:  n.Annotations,
+	}
+}
+
+// Options contains parameters for Build.
+type Options struct {
+	// MaxDepth limits the depth of the tree. The root is at depth 0.
+	// If less than or equal to 0, the depth is unlimited.
+	MaxDepth int
+	// ExcludeSuccessors excludes the successors of the nodes, so that only
+	// the referrers are included as in `oras discover`.
+	ExcludeSuccessors bool
+	// ExcludeReferrers excludes the referrers of the nodes.
+	ExcludeReferrers bool
+	// ArtifactType filters the referrers by artifact type, if not empty.
+	ArtifactType string
+}
+
+// Build builds the tree of the graph rooted at root.
+// Referrers are discovered by registry.Referrers, and are skipped if the
+// storage does not support discovering referrers.
+func Build(ctx context.Context, storage content.ReadOnlyStorage, root ocispec.Descriptor, opts Options) (*Node, error) {
+	b := &builder{
+		storage:   storage,
+		opts:      opts,
+		ancestors: make(map[digest.Digest]bool),
+	}
+	return b.build(ctx, root, "", 0)
+}
+
+// builder builds trees.
+type builder struct {
+	storage content.ReadOnlyStorage
+	opts    Options
+	// ancestors are the digests of the nodes on the path from the root.
+	ancestors map[digest.Digest]bool
+}
+
+// build builds the sub-tree rooted at desc at the given depth.
+// Successors which are ancestors, such as the subjects of referrers, are
+// omitted to break cycles.
+func (b *builder) build(ctx context.Context, desc ocispec.Descriptor, relation string, depth int) (*Node, error) {
+	node := &Node{
+		MediaType:    desc.MediaType,
+		Digest:       desc.Digest,
+		Size:         desc.Size,
+		ArtifactType: desc.ArtifactType,
+		Annotations:  desc.Annotations,
+		Relation:     relation,
+	}
+	if b.opts.MaxDepth > 0 && depth >= b.opts.MaxDepth {
+		return node, nil
+	}
+	b.ancestors[desc.Digest] = true
+	defer delete(b.ancestors, desc.Digest)
+
+	if !b.opts.ExcludeSuccessors {
+		successors, err := content.Successors(ctx, b.storage, desc)
+		if err != nil {
+			return nil, err
+		}
+		for _, successor := range successors {
+			if b.ancestors[successor.Digest] {
+				continue
+			}
+			child, err := b.build(ctx, successor, RelationSuccessor, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, child)
+		}
+	}
+
+	if !b.opts.ExcludeReferrers {
+		referrers, err := registry.Referrers(ctx, b.storage, desc, b.opts.ArtifactType)
+		if err != nil && !errors.Is(err, errdef.ErrUnsupported) {
+			return nil, err
+		}
+		for _, referrer := range referrers {
+			if b.ancestors[referrer.Digest] {
+				continue
+			}
+			child, err := b.build(ctx, referrer, RelationReferrer, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, child)
+		}
+	}
+	return node, nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tree
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+// testGraph pushes an image with a layer and an SBOM referrer, and returns
+// the descriptors of the image, its config, the layer and the referrer.
+func testGraph(t *testing.T, store *memory.Store) (image, config, layer, referrer ocispec.Descriptor) {
+	t.Helper()
+	ctx := context.Background()
+	blob := []byte("layer")
+	layer = content.NewDescriptorFromBytes(ocispec.MediaTypeImageLayer, blob)
+	layer.Annotations = map[string]string{ocispec.AnnotationTitle: "layer.tar"}
+	if err := store.Push(ctx, layer, bytes.NewReader(blob)); err != nil {
+		t.Fatal("Push() error =", err)
+	}
+	opts := oras.PackOptions{
+		PackImageManifest:   true,
+		ManifestAnnotations: map[string]string{ocispec.AnnotationCreated: "2000-01-01T00:00:00Z"},
+	}
+	image, err := oras.Pack(ctx, store, "application/vnd.test.config", []ocispec.Descriptor{layer}, opts)
+	if err != nil {
+		t.Fatal("Pack() error =", err)
+	}
+	opts.Subject = &image
+	referrer, err = oras.Pack(ctx, store, "application/spdx+json", nil, opts)
+	if err != nil {
+		t.Fatal("Pack() error =", err)
+	}
+	successors, err := content.Successors(ctx, store, image)
+	if err != nil {
+		t.Fatal("Successors() error =", err)
+	}
+	return image, successors[0], layer, referrer
+}
+
+func TestBuild(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	image, config, layer, referrer := testGraph(t, store)
+
+	root, err := Build(ctx, store, image, Options{})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	var got []string
+	var walk func(node *Node, depth int)
+	walk = func(node *Node, depth int) {
+		got = append(got, string(rune('0'+depth))+node.Relation+":"+node.Digest.String())
+		for _, child := range node.Children {
+			walk(child, depth+1)
+		}
+	}
+	walk(root, 0)
+	want := []string{
+		"0:" + image.Digest.String(),
+		"1successor:" + config.Digest.String(),
+		"1successor:" + layer.Digest.String(),
+		"1referrer:" + referrer.Digest.String(),
+		"2successor:" + config.Digest.String(),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Build() = %v, want %v", got, want)
+	}
+	if got := root.Children[2].ArtifactType; got != "application/spdx+json" {
+		t.Errorf("referrer ArtifactType = %v, want application/spdx+json", got)
+	}
+
+	// referrers only
+	root, err = Build(ctx, store, image, Options{ExcludeSuccessors: true})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if len(root.Children) != 1 || root.Children[0].Digest != referrer.Digest || len(root.Children[0].Children) != 0 {
+		t.Errorf("Build() children = %v, want the referrer only", root.Children)
+	}
+
+	// depth limited
+	root, err = Build(ctx, store, image, Options{MaxDepth: 1, ArtifactType: "application/vnd.unknown"})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if len(root.Children) != 2 {
+		t.Errorf("Build() children = %v, want 2 successors", root.Children)
+	}
+	for _, child := range root.Children {
+		if len(child.Children) != 0 {
+			t.Errorf("Build() child %v has children beyond max depth", child.Digest)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	image, config, layer, referrer := testGraph(t, store)
+	root, err := Build(ctx, store, image, Options{})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteText(&buf, root); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	want := image.Digest.String() + "\n" +
+		"├── application/vnd.test.config " + config.Digest.String() + "\n" +
+		"├── application/vnd.oci.image.layer.v1.tar " + layer.Digest.String() + " layer.tar\n" +
+		"└── [referrer] application/spdx+json " + referrer.Digest.String() + "\n" +
+		"    └── application/spdx+json " + config.Digest.String() + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteText() =\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := WriteJSON(&buf, root); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	var decoded Node
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+	if !reflect.DeepEqual(&decoded, root) {
+		t.Errorf("WriteJSON() = %s, want %v", buf.String(), root)
+	}
+}
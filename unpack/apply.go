@@ -0,0 +1,315 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unpack
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// whiteoutPrefix is the name prefix of the whiteout files, which remove
+	// the files of the same names without the prefix in the lower layers.
+	whiteoutPrefix = ".wh."
+	// whiteoutOpaque is the name of the opaque whiteout files, which remove
+	// all the files of the lower layers in the directories.
+	whiteoutOpaque = whiteoutPrefix + whiteoutPrefix + ".opq"
+	// paxXattrPrefix is the key prefix of the PAX records of extended
+	// attributes.
+	paxXattrPrefix = "SCHILY.xattr."
+)
+
+// ApplyLayer applies the uncompressed tar stream of a layer read from r into
+// the directory dir, which is created if not exist, on top of the layers
+// applied before.
+//
+// Whiteout files and opaque whiteout files remove the files of the lower
+// layers, and are not created. Existing files are replaced by the entries of
+// the same names, except that existing directories are merged with
+// directory entries.
+func ApplyLayer(r io.Reader, dir string, opts Options) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	a := &applier{
+		dir:   dir,
+		opts:  opts,
+		added: make(map[string]bool),
+	}
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		if err := a.apply(header, tr); err != nil {
+			return fmt.Errorf("%s: %w", header.Name, err)
+		}
+	}
+
+	// the modes and the times of the directories are set after all their
+	// children are created, from the deepest ones
+	for i := len(a.dirs) - 1; i >= 0; i-- {
+		dir := a.dirs[i]
+		name := cleanName(dir.header.Name)
+		// skip the directories replaced by later entries, such as symbolic
+		// links, since chmod and chtimes follow symbolic links
+		if err := a.checkParents(path.Dir(name)); err != nil {
+			continue
+		}
+		p := a.path(name)
+		fi, err := os.Lstat(p)
+		if err != nil || !fi.IsDir() || !os.SameFile(fi, dir.info) {
+			continue
+		}
+		if err := os.Chmod(p, fileMode(dir.header)); err != nil {
+			return err
+		}
+		setTimes(p, dir.header)
+	}
+	return nil
+}
+
+// appliedDir is a directory added by a layer.
+type appliedDir struct {
+	header *tar.Header
+	// info identifies the directory when it is added.
+	info os.FileInfo
+}
+
+// applier applies a layer into a directory.
+type applier struct {
+	dir  string
+	opts Options
+	// added records the entries added by the layer and their parents, which
+	// are kept by opaque whiteouts.
+	added map[string]bool
+	// dirs records the directories added by the layer.
+	dirs []appliedDir
+}
+
+// apply applies a single tar entry.
+func (a *applier) apply(header *tar.Header, r io.Reader) error {
+	name := cleanName(header.Name)
+	if name == "" {
+		// the root directory
+		return nil
+	}
+	parent, base := path.Dir(name), path.Base(name)
+	if err := a.checkParents(parent); err != nil {
+		return err
+	}
+
+	// whiteouts
+	if base == whiteoutOpaque {
+		return a.removeLower(parent)
+	}
+	if strings.HasPrefix(base, whiteoutPrefix) {
+		target := path.Join(parent, strings.TrimPrefix(base, whiteoutPrefix))
+		return os.RemoveAll(a.path(target))
+	}
+
+	if err := os.MkdirAll(a.path(parent), 0755); err != nil {
+		return err
+	}
+	p := a.path(name)
+	if fi, err := os.Lstat(p); err == nil {
+		if !fi.IsDir() || header.Typeflag != tar.TypeDir {
+			if err := os.RemoveAll(p); err != nil {
+				return err
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	switch header.Typeflag {
+	case tar.TypeReg:
+		if err := writeFile(p, r); err != nil {
+			return err
+		}
+	case tar.TypeDir:
+		// the directory is writable until all the children are created
+		if err := os.Mkdir(p, 0700); err != nil && !os.IsExist(err) {
+			return err
+		}
+	case tar.TypeSymlink:
+		// the target is not validated since symbolic links are not followed
+		// when applying layers
+		if err := os.Symlink(header.Linkname, p); err != nil {
+			return err
+		}
+	case tar.TypeLink:
+		target := cleanName(header.Linkname)
+		if target == "" {
+			return fmt.Errorf("invalid hard link target %q", header.Linkname)
+		}
+		if err := a.checkParents(path.Dir(target)); err != nil {
+			return err
+		}
+		if err := os.Link(a.path(target), p); err != nil {
+			return err
+		}
+		// hard links share the attributes of their targets
+		a.markAdded(name)
+		return nil
+	default:
+		// device files, FIFOs, and unknown types are skipped
+		return nil
+	}
+	a.markAdded(name)
+
+	if a.opts.SameOwner {
+		if err := os.Lchown(p, header.Uid, header.Gid); err != nil {
+			return err
+		}
+	}
+	if header.Typeflag == tar.TypeSymlink {
+		return nil
+	}
+	if a.opts.Xattrs {
+		for key, value := range header.PAXRecords {
+			if attr := strings.TrimPrefix(key, paxXattrPrefix); attr != key {
+				if err := setXattr(p, attr, []byte(value)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if header.Typeflag == tar.TypeDir {
+		fi, err := os.Lstat(p)
+		if err != nil {
+			return err
+		}
+		a.dirs = append(a.dirs, appliedDir{header: header, info: fi})
+		return nil
+	}
+	if err := os.Chmod(p, fileMode(header)); err != nil {
+		return err
+	}
+	setTimes(p, header)
+	return nil
+}
+
+// checkParents ensures that no symbolic link is in the parent path, so that
+// entries cannot be created outside of the directory.
+func (a *applier) checkParents(parent string) error {
+	if parent == "." {
+		return nil
+	}
+	current := ""
+	for _, elem := range strings.Split(parent, "/") {
+		current = path.Join(current, elem)
+		fi, err := os.Lstat(a.path(current))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if fi.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("no symbolic link allowed in the parent path %q", parent)
+		}
+		if !fi.IsDir() {
+			return fmt.Errorf("parent %q is not a directory", current)
+		}
+	}
+	return nil
+}
+
+// removeLower removes the entries of the directory not added by the layer.
+func (a *applier) removeLower(dir string) error {
+	entries, err := os.ReadDir(a.path(dir))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		name := path.Join(dir, entry.Name())
+		if a.added[name] {
+			continue
+		}
+		if err := os.RemoveAll(a.path(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markAdded marks the entry and its parents as added by the layer.
+func (a *applier) markAdded(name string) {
+	for name != "." && !a.added[name] {
+		a.added[name] = true
+		name = path.Dir(name)
+	}
+}
+
+// path returns the file path of the entry name.
+func (a *applier) path(name string) string {
+	return filepath.Join(a.dir, filepath.FromSlash(name))
+}
+
+// cleanName returns the cleaned relative slash-separated name of a tar entry,
+// which never resolves outside of the root. Returns an empty string for the
+// root.
+func cleanName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+}
+
+// fileMode returns the permission bits and the special bits of the entry.
+func fileMode(header *tar.Header) os.FileMode {
+	return header.FileInfo().Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+}
+
+// setTimes sets the access time and the modification time of the file, with
+// the error ignored.
+func setTimes(p string, header *tar.Header) {
+	atime := header.AccessTime
+	if atime.IsZero() {
+		atime = header.ModTime
+	}
+	if header.ModTime.IsZero() {
+		return
+	}
+	os.Chtimes(p, atime, header.ModTime)
+}
+
+// writeFile writes the content read from r to a new file.
+func writeFile(p string, r io.Reader) (err error) {
+	file, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeErr := file.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}()
+	_, err = io.Copy(file, r)
+	return err
+}
@@ -0,0 +1,263 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unpack
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// tarEntry is an entry of a test layer.
+type tarEntry struct {
+	name     string
+	typeflag byte
+	content  string
+	linkname string
+	mode     int64
+	pax      map[string]string
+}
+
+// buildLayer builds an uncompressed layer of the entries.
+func buildLayer(t *testing.T, entries ...tarEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		mode := e.mode
+		if mode == 0 {
+			mode = 0644
+			if e.typeflag == tar.TypeDir {
+				mode = 0755
+			}
+		}
+		header := &tar.Header{
+			Name:       e.name,
+			Typeflag:   e.typeflag,
+			Linkname:   e.linkname,
+			Mode:       mode,
+			Size:       int64(len(e.content)),
+			PAXRecords: e.pax,
+		}
+		if e.pax != nil {
+			header.Format = tar.FormatPAX
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// applyLayers applies the layers into dir.
+func applyLayers(t *testing.T, dir string, opts Options, layers ...[]byte) {
+	t.Helper()
+	for _, layer := range layers {
+		if err := ApplyLayer(bytes.NewReader(layer), dir, opts); err != nil {
+			t.Fatalf("ApplyLayer() error = %v", err)
+		}
+	}
+}
+
+// assertFile checks the content of the file.
+func assertFile(t *testing.T, dir, name, want string) {
+	t.Helper()
+	got, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Errorf("ReadFile(%s) error = %v", name, err)
+		return
+	}
+	if string(got) != want {
+		t.Errorf("ReadFile(%s) = %q, want %q", name, got, want)
+	}
+}
+
+// assertNotExist checks the file does not exist.
+func assertNotExist(t *testing.T, dir, name string) {
+	t.Helper()
+	if _, err := os.Lstat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+		t.Errorf("Lstat(%s) error = %v, want not exist", name, err)
+	}
+}
+
+func TestApplyLayer_Whiteout(t *testing.T) {
+	dir := t.TempDir()
+	base := buildLayer(t,
+		tarEntry{name: "etc/", typeflag: tar.TypeDir},
+		tarEntry{name: "etc/hosts", typeflag: tar.TypeReg, content: "hosts"},
+		tarEntry{name: "etc/passwd", typeflag: tar.TypeReg, content: "passwd"},
+		tarEntry{name: "var/lib/", typeflag: tar.TypeDir},
+		tarEntry{name: "var/lib/a", typeflag: tar.TypeReg, content: "a"},
+		tarEntry{name: "var/lib/b", typeflag: tar.TypeReg, content: "b"},
+	)
+	upper := buildLayer(t,
+		tarEntry{name: "etc/.wh.passwd", typeflag: tar.TypeReg},
+		tarEntry{name: "etc/hosts", typeflag: tar.TypeReg, content: "new hosts"},
+		// the entries added by the same layer are kept by opaque whiteouts
+		tarEntry{name: "var/lib/c", typeflag: tar.TypeReg, content: "c"},
+		tarEntry{name: "var/lib/.wh..wh..opq", typeflag: tar.TypeReg},
+	)
+	applyLayers(t, dir, Options{}, base, upper)
+
+	assertFile(t, dir, "etc/hosts", "new hosts")
+	assertNotExist(t, dir, "etc/passwd")
+	assertNotExist(t, dir, "etc/.wh.passwd")
+	assertFile(t, dir, "var/lib/c", "c")
+	assertNotExist(t, dir, "var/lib/a")
+	assertNotExist(t, dir, "var/lib/b")
+	assertNotExist(t, dir, "var/lib/.wh..wh..opq")
+}
+
+func TestApplyLayer_Links(t *testing.T) {
+	dir := t.TempDir()
+	layer := buildLayer(t,
+		tarEntry{name: "bin/", typeflag: tar.TypeDir},
+		tarEntry{name: "bin/busybox", typeflag: tar.TypeReg, content: "busybox", mode: 0755},
+		tarEntry{name: "bin/sh", typeflag: tar.TypeLink, linkname: "bin/busybox"},
+		tarEntry{name: "bin/ls", typeflag: tar.TypeSymlink, linkname: "/bin/busybox"},
+	)
+	applyLayers(t, dir, Options{}, layer)
+
+	busybox, err := os.Stat(filepath.Join(dir, "bin/busybox"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := busybox.Mode().Perm(); got != 0755 {
+		t.Errorf("mode = %v, want %v", got, os.FileMode(0755))
+	}
+	sh, err := os.Stat(filepath.Join(dir, "bin/sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(busybox, sh) {
+		t.Error("bin/sh is not a hard link to bin/busybox")
+	}
+	target, err := os.Readlink(filepath.Join(dir, "bin/ls"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target != "/bin/busybox" {
+		t.Errorf("Readlink() = %v, want %v", target, "/bin/busybox")
+	}
+}
+
+func TestApplyLayer_Replace(t *testing.T) {
+	dir := t.TempDir()
+	base := buildLayer(t,
+		tarEntry{name: "lib", typeflag: tar.TypeSymlink, linkname: "usr/lib"},
+		tarEntry{name: "data/", typeflag: tar.TypeDir},
+		tarEntry{name: "data/file", typeflag: tar.TypeReg, content: "file"},
+	)
+	upper := buildLayer(t,
+		// a directory replaces a symbolic link
+		tarEntry{name: "lib/", typeflag: tar.TypeDir},
+		tarEntry{name: "lib/libc.so", typeflag: tar.TypeReg, content: "libc"},
+		// a file replaces a directory
+		tarEntry{name: "data", typeflag: tar.TypeReg, content: "data"},
+	)
+	applyLayers(t, dir, Options{}, base, upper)
+
+	fi, err := os.Lstat(filepath.Join(dir, "lib"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fi.IsDir() {
+		t.Errorf("lib mode = %v, want directory", fi.Mode())
+	}
+	assertFile(t, dir, "lib/libc.so", "libc")
+	assertFile(t, dir, "data", "data")
+}
+
+func TestApplyLayer_PathTraversal(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "rootfs")
+
+	// names are confined to the directory
+	layer := buildLayer(t,
+		tarEntry{name: "../escape", typeflag: tar.TypeReg, content: "escape"},
+	)
+	applyLayers(t, dir, Options{}, layer)
+	assertNotExist(t, parent, "escape")
+	assertFile(t, dir, "escape", "escape")
+
+	// symbolic links are not followed
+	layer = buildLayer(t,
+		tarEntry{name: "link", typeflag: tar.TypeSymlink, linkname: parent},
+		tarEntry{name: "link/escape2", typeflag: tar.TypeReg, content: "escape"},
+	)
+	if err := ApplyLayer(bytes.NewReader(layer), dir, Options{}); err == nil {
+		t.Error("ApplyLayer() error = nil, want error")
+	}
+	assertNotExist(t, parent, "escape2")
+}
+
+func TestApplyLayer_DirReplacedBySymlink(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "rootfs")
+	outside := filepath.Join(parent, "outside")
+	if err := os.Mkdir(outside, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	// the mode of the directory is not applied through the symbolic link
+	// replacing it
+	layer := buildLayer(t,
+		tarEntry{name: "a/", typeflag: tar.TypeDir, mode: 0777},
+		tarEntry{name: "a/b/", typeflag: tar.TypeDir, mode: 0777},
+		tarEntry{name: "a", typeflag: tar.TypeSymlink, linkname: outside},
+	)
+	if err := os.Mkdir(filepath.Join(outside, "b"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	applyLayers(t, dir, Options{}, layer)
+	for _, p := range []string{outside, filepath.Join(outside, "b")} {
+		fi, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fi.Mode().Perm(); got != 0700 {
+			t.Errorf("mode of %s = %v, want %v", p, got, os.FileMode(0700))
+		}
+	}
+}
+
+func TestCleanName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"foo/bar", "foo/bar"},
+		{"./foo/bar/", "foo/bar"},
+		{"/foo", "foo"},
+		{"../../foo", "foo"},
+		{"foo/../../bar", "bar"},
+		{"./", ""},
+		{"/", ""},
+	}
+	for _, tt := range tests {
+		if got := cleanName(tt.name); got != tt.want {
+			t.Errorf("cleanName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
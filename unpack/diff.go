@@ -0,0 +1,329 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unpack
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// DiffOptions contains parameters for [unpack.Diff], [unpack.DiffImage] and
+// [unpack.PushDiff].
+type DiffOptions struct {
+	// Reproducible, if true, removes the times and the user and group names
+	// of the entries, so that the same changes always produce the same
+	// layer.
+	Reproducible bool
+	// Xattrs, if true, records the extended attributes of the files in the
+	// layer. Extended attributes are only supported on Linux, and are not
+	// recorded for symbolic links.
+	Xattrs bool
+}
+
+// Diff writes to w an uncompressed tar layer which, when applied by
+// ApplyLayer on top of the directory lower, results in the directory upper.
+// If lower is empty, the layer contains all the files in upper.
+//
+// Entries added to upper or changed are recorded. A regular file is
+// considered changed if its mode, size or modification time is changed, as
+// unchanged files keep their modification times. Entries removed from upper
+// are recorded as whiteout files. Files hard-linked within upper are
+// recorded as hard links.
+func Diff(lower, upper string, w io.Writer, opts DiffOptions) (err error) {
+	tw := tar.NewWriter(w)
+	defer func() {
+		closeErr := tw.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}()
+	d := &differ{
+		lower: lower,
+		upper: upper,
+		tw:    tw,
+		opts:  opts,
+		links: make(map[fileID]string),
+	}
+	if lower != "" {
+		if err := d.writeWhiteouts(); err != nil {
+			return err
+		}
+	}
+	return d.writeChanges()
+}
+
+// DiffImage writes to w an uncompressed tar layer which, when applied on top
+// of the layers of the image manifest described by base, results in the
+// directory upper. The base image is unpacked into a temporary directory to
+// be compared with upper.
+func DiffImage(ctx context.Context, fetcher content.Fetcher, base ocispec.Descriptor, upper string, w io.Writer, opts DiffOptions) (err error) {
+	lower, err := os.MkdirTemp("", "oras_unpack_*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		removeErr := os.RemoveAll(lower)
+		if err == nil {
+			err = removeErr
+		}
+	}()
+	if err := Unpack(ctx, fetcher, base, lower, Options{Xattrs: opts.Xattrs}); err != nil {
+		return err
+	}
+	return Diff(lower, upper, w, opts)
+}
+
+// PushDiff creates a gzip-compressed layer by Diff, and pushes it to pusher.
+// Returns the descriptor of the layer and its diff ID, which is the digest
+// of the uncompressed layer to be recorded in the image config.
+// The layer is not pushed again if it already exists.
+func PushDiff(ctx context.Context, pusher content.Pusher, lower, upper string, opts DiffOptions) (ocispec.Descriptor, digest.Digest, error) {
+	fp, err := os.CreateTemp("", "oras_layer_*")
+	if err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	defer func() {
+		fp.Close()
+		os.Remove(fp.Name())
+	}()
+
+	compressed := digest.Canonical.Digester()
+	zw := gzip.NewWriter(io.MultiWriter(fp, compressed.Hash()))
+	uncompressed := digest.Canonical.Digester()
+	if err := Diff(lower, upper, io.MultiWriter(zw, uncompressed.Hash()), opts); err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	if err := zw.Close(); err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	size, err := fp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+	if _, err := fp.Seek(0, io.SeekStart); err != nil {
+		return ocispec.Descriptor{}, "", err
+	}
+
+	desc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageLayerGzip,
+		Digest:    compressed.Digest(),
+		Size:      size,
+	}
+	if err := pusher.Push(ctx, desc, fp); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return ocispec.Descriptor{}, "", err
+	}
+	return desc, uncompressed.Digest(), nil
+}
+
+// differ writes the differences between two directories as a layer.
+type differ struct {
+	lower string
+	upper string
+	tw    *tar.Writer
+	opts  DiffOptions
+	// links maps the files written to their names, to record hard links.
+	links map[fileID]string
+}
+
+// writeWhiteouts writes whiteout files for the entries in lower but not in
+// upper.
+func (d *differ) writeWhiteouts() error {
+	return filepath.WalkDir(d.lower, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name, err := relName(d.lower, p)
+		if err != nil || name == "" {
+			return err
+		}
+		upperInfo, err := os.Lstat(filepath.Join(d.upper, filepath.FromSlash(name)))
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			if err := d.tw.WriteHeader(&tar.Header{
+				Name:     path.Join(path.Dir(name), whiteoutPrefix+path.Base(name)),
+				Typeflag: tar.TypeReg,
+				Mode:     0644,
+				Format:   tar.FormatPAX,
+			}); err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() && !upperInfo.IsDir() {
+			// the directory is replaced as a whole
+			return fs.SkipDir
+		}
+		return nil
+	})
+}
+
+// writeChanges writes the entries in upper added or changed.
+func (d *differ) writeChanges() error {
+	return filepath.WalkDir(d.upper, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name, err := relName(d.upper, p)
+		if err != nil || name == "" {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if d.lower != "" {
+			lowerPath := filepath.Join(d.lower, filepath.FromSlash(name))
+			changed, err := isChanged(lowerPath, p, info)
+			if err != nil {
+				return err
+			}
+			if !changed {
+				return nil
+			}
+		}
+		return d.writeEntry(name, p, info)
+	})
+}
+
+// writeEntry writes the entry of the file at p.
+func (d *differ) writeEntry(name, p string, info fs.FileInfo) error {
+	var link string
+	if info.Mode()&fs.ModeSymlink != 0 {
+		var err error
+		if link, err = os.Readlink(p); err != nil {
+			return err
+		}
+	}
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return fmt.Errorf("%s: %w", p, err)
+	}
+	header.Name = name
+	header.Format = tar.FormatPAX
+	if info.IsDir() {
+		header.Name += "/"
+	}
+	if d.opts.Reproducible {
+		header.Uname = ""
+		header.Gname = ""
+		header.ModTime = time.Time{}
+		header.AccessTime = time.Time{}
+		header.ChangeTime = time.Time{}
+	}
+
+	if info.Mode().IsRegular() {
+		if id, ok := getFileID(info); ok {
+			if target, ok := d.links[id]; ok {
+				header.Typeflag = tar.TypeLink
+				header.Linkname = target
+				header.Size = 0
+				return d.tw.WriteHeader(header)
+			}
+			d.links[id] = name
+		}
+	}
+	if d.opts.Xattrs && info.Mode()&fs.ModeSymlink == 0 {
+		xattrs, err := getXattrs(p)
+		if err != nil {
+			return err
+		}
+		for attr, value := range xattrs {
+			if header.PAXRecords == nil {
+				header.PAXRecords = make(map[string]string)
+			}
+			header.PAXRecords[paxXattrPrefix+attr] = value
+		}
+	}
+	if err := d.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+	fp, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	if _, err := io.Copy(d.tw, fp); err != nil {
+		return fmt.Errorf("failed to copy %s: %w", p, err)
+	}
+	return nil
+}
+
+// isChanged returns true if the file at upperPath with info is added or
+// changed compared to the file at lowerPath.
+func isChanged(lowerPath, upperPath string, info fs.FileInfo) (bool, error) {
+	lowerInfo, err := os.Lstat(lowerPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return true, nil
+		}
+		return false, err
+	}
+	if lowerInfo.Mode() != info.Mode() {
+		return true, nil
+	}
+	switch {
+	case info.Mode().IsRegular():
+		return lowerInfo.Size() != info.Size() || !lowerInfo.ModTime().Equal(info.ModTime()), nil
+	case info.Mode()&fs.ModeSymlink != 0:
+		lowerLink, err := os.Readlink(lowerPath)
+		if err != nil {
+			return false, err
+		}
+		upperLink, err := os.Readlink(upperPath)
+		if err != nil {
+			return false, err
+		}
+		return lowerLink != upperLink, nil
+	default:
+		// directories with the same modes are not recorded, while their
+		// children are compared
+		return false, nil
+	}
+}
+
+// relName returns the slash-separated name of p relative to root.
+func relName(root, p string) (string, error) {
+	name, err := filepath.Rel(root, p)
+	if err != nil {
+		return "", err
+	}
+	if name == "." {
+		return "", nil
+	}
+	return filepath.ToSlash(name), nil
+}
@@ -0,0 +1,298 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unpack
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+// testTime is the modification time of the test files.
+var testTime = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// writeTestFile writes a file with testTime as the modification time.
+func writeTestFile(t *testing.T, dir, name, data string) {
+	t.Helper()
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(p, testTime, testTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// createLower creates the lower directory of the tests.
+func createLower(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeTestFile(t, dir, "a", "a")
+	writeTestFile(t, dir, "b", "b")
+	writeTestFile(t, dir, "d/x", "x")
+	writeTestFile(t, dir, "d/y", "y")
+	writeTestFile(t, dir, "gone/file", "gone")
+	if err := os.Symlink("a", filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// listFiles lists the names and the contents of the files in dir.
+func listFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name, err := relName(dir, p)
+		if err != nil || name == "" {
+			return err
+		}
+		switch {
+		case info.Mode().IsRegular():
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			files[name] = string(data)
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			files[name] = "-> " + target
+		case info.IsDir():
+			files[name] = "/"
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// layerNames returns the sorted names of the entries of the layer.
+func layerNames(t *testing.T, layer []byte) []string {
+	t.Helper()
+	var names []string
+	tr := tar.NewReader(bytes.NewReader(layer))
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, header.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestDiff(t *testing.T) {
+	lower := createLower(t)
+	upper := createLower(t)
+	writeTestFile(t, upper, "b", "new b")
+	if err := os.Remove(filepath.Join(upper, "d/x")); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, upper, "d/z", "z")
+	if err := os.RemoveAll(filepath.Join(upper, "gone")); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, upper, "e/f", "f")
+	if err := os.Remove(filepath.Join(upper, "link")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("b", filepath.Join(upper, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Diff(lower, upper, &buf, DiffOptions{}); err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	want := []string{"b", "d/.wh.x", "d/z", "e/", "e/f", "link", ".wh.gone"}
+	sort.Strings(want)
+	if got := layerNames(t, buf.Bytes()); !equalStrings(got, want) {
+		t.Errorf("Diff() entries = %v, want %v", got, want)
+	}
+
+	// applying the diff on lower results in upper
+	target := createLower(t)
+	if err := ApplyLayer(bytes.NewReader(buf.Bytes()), target, Options{}); err != nil {
+		t.Fatalf("ApplyLayer() error = %v", err)
+	}
+	got, want2 := listFiles(t, target), listFiles(t, upper)
+	if len(got) != len(want2) {
+		t.Errorf("files = %v, want %v", got, want2)
+	}
+	for name, data := range want2 {
+		if got[name] != data {
+			t.Errorf("file %s = %q, want %q", name, got[name], data)
+		}
+	}
+}
+
+func TestDiff_NoLower(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hard links are not detected on Windows")
+	}
+	upper := t.TempDir()
+	writeTestFile(t, upper, "bin/busybox", "busybox")
+	if err := os.Link(filepath.Join(upper, "bin/busybox"), filepath.Join(upper, "bin/sh")); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Diff("", upper, &buf, DiffOptions{Reproducible: true}); err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	tr := tar.NewReader(bytes.NewReader(buf.Bytes()))
+	var links int
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !header.ModTime.IsZero() && header.ModTime.Unix() != 0 {
+			t.Errorf("%s: ModTime = %v, want zero", header.Name, header.ModTime)
+		}
+		if header.Typeflag == tar.TypeLink {
+			links++
+			if header.Linkname != "bin/busybox" {
+				t.Errorf("%s: Linkname = %v, want %v", header.Name, header.Linkname, "bin/busybox")
+			}
+		}
+	}
+	if links != 1 {
+		t.Errorf("hard links = %d, want 1", links)
+	}
+
+	target := t.TempDir()
+	if err := ApplyLayer(bytes.NewReader(buf.Bytes()), target, Options{}); err != nil {
+		t.Fatalf("ApplyLayer() error = %v", err)
+	}
+	assertFile(t, target, "bin/sh", "busybox")
+}
+
+func TestPushDiff(t *testing.T) {
+	ctx := context.Background()
+	upper := t.TempDir()
+	writeTestFile(t, upper, "hello", "world")
+
+	s := memory.New()
+	desc, diffID, err := PushDiff(ctx, s, "", upper, DiffOptions{Reproducible: true})
+	if err != nil {
+		t.Fatalf("PushDiff() error = %v", err)
+	}
+	compressed, err := content.FetchAll(ctx, s, desc)
+	if err != nil {
+		t.Fatalf("FetchAll() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := Diff("", upper, &buf, DiffOptions{Reproducible: true}); err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if want := digest.FromBytes(buf.Bytes()); diffID != want {
+		t.Errorf("PushDiff() diffID = %v, want %v", diffID, want)
+	}
+	if len(compressed) == 0 {
+		t.Error("PushDiff() pushed empty layer")
+	}
+}
+
+func TestDiffImage(t *testing.T) {
+	ctx := context.Background()
+	upper := createLower(t)
+
+	s := memory.New()
+	layer, _, err := PushDiff(ctx, s, "", upper, DiffOptions{})
+	if err != nil {
+		t.Fatalf("PushDiff() error = %v", err)
+	}
+	config := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, []byte("{}"))
+	if err := s.Push(ctx, config, bytes.NewReader([]byte("{}"))); err != nil {
+		t.Fatal(err)
+	}
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    []ocispec.Descriptor{layer},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, manifestJSON)
+	if err := s.Push(ctx, base, bytes.NewReader(manifestJSON)); err != nil {
+		t.Fatal(err)
+	}
+
+	// no changes against the base image
+	var buf bytes.Buffer
+	if err := DiffImage(ctx, s, base, upper, &buf, DiffOptions{}); err != nil {
+		t.Fatalf("DiffImage() error = %v", err)
+	}
+	if got := layerNames(t, buf.Bytes()); len(got) != 0 {
+		t.Errorf("DiffImage() entries = %v, want none", got)
+	}
+
+	writeTestFile(t, upper, "a", "new a")
+	buf.Reset()
+	if err := DiffImage(ctx, s, base, upper, &buf, DiffOptions{}); err != nil {
+		t.Fatalf("DiffImage() error = %v", err)
+	}
+	if got, want := layerNames(t, buf.Bytes()), []string{"a"}; !equalStrings(got, want) {
+		t.Errorf("DiffImage() entries = %v, want %v", got, want)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+//go:build !windows
+
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unpack
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileID identifies a file on a device.
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// getFileID returns the ID of the file if the file has multiple hard links.
+func getFileID(info fs.FileInfo) (fileID, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || stat.Nlink <= 1 {
+		return fileID{}, false
+	}
+	return fileID{
+		dev: uint64(stat.Dev),
+		ino: uint64(stat.Ino),
+	}, true
+}
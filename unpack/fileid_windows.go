@@ -0,0 +1,28 @@
+//go:build windows
+
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unpack
+
+import "io/fs"
+
+// fileID identifies a file on a device.
+type fileID struct{}
+
+// getFileID returns false since hard links are not detected on Windows.
+func getFileID(fs.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
@@ -0,0 +1,190 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unpack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/docker"
+)
+
+// defaultSquashCreatedBy is the default value of SquashOptions.CreatedBy.
+const defaultSquashCreatedBy = "oras squash"
+
+// SquashOptions contains parameters for [unpack.Squash].
+type SquashOptions struct {
+	DiffOptions
+	// CreatedBy is recorded in the history entry of the squashed layer.
+	// If empty, a default is used.
+	CreatedBy string
+	// Created, if not nil, is recorded as the creation time of the history
+	// entry of the squashed layer. Otherwise, the entry has no creation
+	// time, so that squashing is reproducible with DiffOptions.Reproducible.
+	Created *time.Time
+}
+
+// Squash squashes the layers of the image manifest described by
+// manifestDesc in the storage into a single gzip-compressed layer, by
+// unpacking the layers into a temporary directory and repacking it.
+//
+// A new config and a new manifest of the same media types are pushed to the
+// storage, and the descriptor of the new manifest is returned. In the new
+// config, the diff IDs are replaced by the one of the squashed layer, the
+// existing history entries are marked as empty layers, and an entry of the
+// squashed layer is appended. Other fields of the config and the annotations
+// of the manifest are kept. The new manifest is not tagged.
+func Squash(ctx context.Context, storage content.Storage, manifestDesc ocispec.Descriptor, opts SquashOptions) (desc ocispec.Descriptor, err error) {
+	switch manifestDesc.MediaType {
+	case ocispec.MediaTypeImageManifest, docker.MediaTypeManifest:
+	default:
+		return ocispec.Descriptor{}, fmt.Errorf("%s: %s: not an image manifest: %w", manifestDesc.Digest, manifestDesc.MediaType, errdef.ErrUnsupported)
+	}
+	manifestJSON, err := content.FetchAll(ctx, storage, manifestDesc)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to decode manifest %s: %w", manifestDesc.Digest, err)
+	}
+	configJSON, err := content.FetchAll(ctx, storage, manifest.Config)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	// squash the layers
+	dir, err := os.MkdirTemp("", "oras_squash_*")
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	defer func() {
+		removeErr := os.RemoveAll(dir)
+		if err == nil {
+			err = removeErr
+		}
+	}()
+	if err := Unpack(ctx, storage, manifestDesc, dir, Options{Xattrs: opts.Xattrs}); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	layer, diffID, err := PushDiff(ctx, storage, "", dir, opts.DiffOptions)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if manifestDesc.MediaType == docker.MediaTypeManifest {
+		layer.MediaType = docker.MediaTypeLayer
+	}
+
+	// update the config
+	configJSON, err = squashConfig(configJSON, diffID, opts)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to update config %s: %w", manifest.Config.Digest, err)
+	}
+	config := content.NewDescriptorFromBytes(manifest.Config.MediaType, configJSON)
+	if err := pushBytes(ctx, storage, config, configJSON); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	// update the manifest
+	manifest.Config = config
+	manifest.Layers = []ocispec.Descriptor{layer}
+	manifestJSON, err = json.Marshal(manifest)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	desc = content.NewDescriptorFromBytes(manifestDesc.MediaType, manifestJSON)
+	if err := pushBytes(ctx, storage, desc, manifestJSON); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return desc, nil
+}
+
+// squashConfig replaces the diff IDs in the config with diffID, and updates
+// the history. Unknown fields of the config are kept.
+func squashConfig(configJSON []byte, diffID digest.Digest, opts SquashOptions) ([]byte, error) {
+	var config map[string]json.RawMessage
+	if err := json.Unmarshal(configJSON, &config); err != nil {
+		return nil, err
+	}
+
+	var rootFS map[string]json.RawMessage
+	if raw, ok := config["rootfs"]; ok {
+		if err := json.Unmarshal(raw, &rootFS); err != nil {
+			return nil, fmt.Errorf("invalid rootfs: %w", err)
+		}
+	}
+	if rootFS == nil {
+		rootFS = make(map[string]json.RawMessage)
+	}
+	rootFS["type"] = json.RawMessage(`"layers"`)
+	diffIDsJSON, err := json.Marshal([]digest.Digest{diffID})
+	if err != nil {
+		return nil, err
+	}
+	rootFS["diff_ids"] = diffIDsJSON
+	if config["rootfs"], err = json.Marshal(rootFS); err != nil {
+		return nil, err
+	}
+
+	var history []map[string]json.RawMessage
+	if raw, ok := config["history"]; ok {
+		if err := json.Unmarshal(raw, &history); err != nil {
+			return nil, fmt.Errorf("invalid history: %w", err)
+		}
+	}
+	for _, entry := range history {
+		entry["empty_layer"] = json.RawMessage("true")
+	}
+	createdBy := opts.CreatedBy
+	if createdBy == "" {
+		createdBy = defaultSquashCreatedBy
+	}
+	entry := ocispec.History{
+		Created:   opts.Created,
+		CreatedBy: createdBy,
+	}
+	entryJSON, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	var rawEntry map[string]json.RawMessage
+	if err := json.Unmarshal(entryJSON, &rawEntry); err != nil {
+		return nil, err
+	}
+	history = append(history, rawEntry)
+	if config["history"], err = json.Marshal(history); err != nil {
+		return nil, err
+	}
+	return json.Marshal(config)
+}
+
+// pushBytes pushes the content described by desc, ignoring
+// errdef.ErrAlreadyExists.
+func pushBytes(ctx context.Context, pusher content.Pusher, desc ocispec.Descriptor, data []byte) error {
+	if err := pusher.Push(ctx, desc, bytes.NewReader(data)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return err
+	}
+	return nil
+}
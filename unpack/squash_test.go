@@ -0,0 +1,140 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unpack
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestSquash(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal("Store.Push() error =", err)
+		}
+		return desc
+	}
+
+	base := buildLayer(t,
+		tarEntry{name: "etc/", typeflag: tar.TypeDir},
+		tarEntry{name: "etc/hosts", typeflag: tar.TypeReg, content: "hosts"},
+		tarEntry{name: "etc/passwd", typeflag: tar.TypeReg, content: "passwd"},
+	)
+	upper := buildLayer(t,
+		tarEntry{name: "etc/.wh.passwd", typeflag: tar.TypeReg},
+		tarEntry{name: "app", typeflag: tar.TypeReg, content: "app"},
+	)
+	layers := []ocispec.Descriptor{
+		push(ocispec.MediaTypeImageLayer, base),
+		push(ocispec.MediaTypeImageLayer, upper),
+	}
+	configJSON := []byte(`{"architecture":"amd64","os":"linux","config":{"Cmd":["/app"]},` +
+		`"rootfs":{"type":"layers","diff_ids":["` + layers[0].Digest.String() + `","` + layers[1].Digest.String() + `"]},` +
+		`"history":[{"created_by":"base"},{"created_by":"app"}],"x-custom":"kept"}`)
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Versioned:   specs.Versioned{SchemaVersion: 2},
+		MediaType:   ocispec.MediaTypeImageManifest,
+		Config:      push(ocispec.MediaTypeImageConfig, configJSON),
+		Layers:      layers,
+		Annotations: map[string]string{"foo": "bar"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestDesc := push(ocispec.MediaTypeImageManifest, manifestJSON)
+
+	squashed, err := Squash(ctx, s, manifestDesc, SquashOptions{
+		DiffOptions: DiffOptions{Reproducible: true},
+	})
+	if err != nil {
+		t.Fatalf("Squash() error = %v", err)
+	}
+	if err := oras.VerifyDiffIDs(ctx, s, squashed); err != nil {
+		t.Errorf("VerifyDiffIDs() error = %v", err)
+	}
+
+	manifestJSON, err = content.FetchAll(ctx, s, squashed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Layers) != 1 {
+		t.Fatalf("layers = %d, want 1", len(manifest.Layers))
+	}
+	if manifest.Annotations["foo"] != "bar" {
+		t.Errorf("annotations = %v, want kept", manifest.Annotations)
+	}
+
+	configJSON, err = content.FetchAll(ctx, s, manifest.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config struct {
+		ocispec.Image
+		Custom string `json:"x-custom"`
+	}
+	if err := json.Unmarshal(configJSON, &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Custom != "kept" || len(config.Config.Cmd) != 1 {
+		t.Errorf("config = %s, want unknown fields kept", configJSON)
+	}
+	if got := len(config.History); got != 3 {
+		t.Fatalf("history = %d entries, want 3", got)
+	}
+	for i, entry := range config.History[:2] {
+		if !entry.EmptyLayer {
+			t.Errorf("history[%d].EmptyLayer = false, want true", i)
+		}
+	}
+	if got := config.History[2].CreatedBy; got != defaultSquashCreatedBy {
+		t.Errorf("history[2].CreatedBy = %v, want %v", got, defaultSquashCreatedBy)
+	}
+
+	dir := t.TempDir()
+	if err := Unpack(ctx, s, squashed, dir, Options{}); err != nil {
+		t.Fatalf("Unpack() error = %v", err)
+	}
+	assertFile(t, dir, "etc/hosts", "hosts")
+	assertFile(t, dir, "app", "app")
+	assertNotExist(t, dir, "etc/passwd")
+
+	// squashing is reproducible
+	again, err := Squash(ctx, s, manifestDesc, SquashOptions{
+		DiffOptions: DiffOptions{Reproducible: true},
+	})
+	if err != nil {
+		t.Fatalf("Squash() error = %v", err)
+	}
+	if again.Digest != squashed.Digest {
+		t.Errorf("Squash() = %v, want %v", again.Digest, squashed.Digest)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package unpack materializes the filesystems of images by applying their
+// layers in order into a directory, and creates layers from the changes
+// between directories, following the OCI image layer specification on
+// whiteouts.
+//
+// Reference: https://github.com/opencontainers/image-spec/blob/v1.1.0-rc2/layer.md
+package unpack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/compression"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/docker"
+	"oras.land/oras-go/v2/internal/pool"
+)
+
+// Options contains parameters for [unpack.Unpack] and [unpack.ApplyLayer].
+type Options struct {
+	// SameOwner, if true, changes the owners of the files to the user and
+	// group IDs recorded in the layers, which usually requires privileges.
+	// If false, the files are owned by the current user.
+	SameOwner bool
+	// Xattrs, if true, sets the extended attributes recorded in the layers
+	// on the files. Extended attributes are only supported on Linux, and
+	// are not set on symbolic links.
+	Xattrs bool
+	// OnLayer, if not nil, is called before each layer is applied.
+	OnLayer func(ctx context.Context, layer ocispec.Descriptor) error
+}
+
+// Unpack applies the layers of the image manifest described by manifestDesc
+// in order into the directory dir, which is created if not exist.
+//
+// The layers are decompressed by the decompressors registered in the
+// compression package, and are verified against their descriptors.
+// Entries of the layers resolving outside of dir are rejected. Device files
+// are skipped.
+//
+// To unpack an image in a multi-platform index, select the manifest first,
+// for example, by oras.FindManifest.
+func Unpack(ctx context.Context, fetcher content.Fetcher, manifestDesc ocispec.Descriptor, dir string, opts Options) error {
+	switch manifestDesc.MediaType {
+	case ocispec.MediaTypeImageManifest, docker.MediaTypeManifest:
+	default:
+		return fmt.Errorf("%s: %s: not an image manifest: %w", manifestDesc.Digest, manifestDesc.MediaType, errdef.ErrUnsupported)
+	}
+	manifestJSON, err := content.FetchAll(ctx, fetcher, manifestDesc)
+	if err != nil {
+		return err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return fmt.Errorf("failed to decode manifest %s: %w", manifestDesc.Digest, err)
+	}
+
+	for _, layer := range manifest.Layers {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if opts.OnLayer != nil {
+			if err := opts.OnLayer(ctx, layer); err != nil {
+				return err
+			}
+		}
+		if err := unpackLayer(ctx, fetcher, layer, dir, opts); err != nil {
+			return fmt.Errorf("failed to apply layer %s: %w", layer.Digest, err)
+		}
+	}
+	return nil
+}
+
+// unpackLayer fetches, decompresses, and applies the layer into dir.
+func unpackLayer(ctx context.Context, fetcher content.Fetcher, layer ocispec.Descriptor, dir string, opts Options) error {
+	rc, err := fetcher.Fetch(ctx, layer)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	vr := content.NewVerifyReader(rc, layer)
+	var zr io.ReadCloser
+	if alg, ok := compression.FromMediaType(layer.MediaType); ok {
+		zr, err = compression.Decompress(alg, vr)
+	} else {
+		zr, _, err = compression.NewReader(vr)
+	}
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	if err := ApplyLayer(zr, dir, opts); err != nil {
+		return err
+	}
+	// drain the content left, such as tar paddings and gzip trailers
+	if _, err := pool.Stream.Discard(vr); err != nil {
+		return err
+	}
+	return vr.Verify()
+}
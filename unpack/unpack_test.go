@@ -0,0 +1,96 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unpack
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"testing"
+
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func TestUnpack(t *testing.T) {
+	ctx := context.Background()
+	s := memory.New()
+	push := func(mediaType string, blob []byte) ocispec.Descriptor {
+		desc := content.NewDescriptorFromBytes(mediaType, blob)
+		if err := s.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatal("Store.Push() error =", err)
+		}
+		return desc
+	}
+
+	base := buildLayer(t,
+		tarEntry{name: "etc/", typeflag: tar.TypeDir},
+		tarEntry{name: "etc/hosts", typeflag: tar.TypeReg, content: "hosts"},
+		tarEntry{name: "etc/passwd", typeflag: tar.TypeReg, content: "passwd"},
+	)
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(base); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	upper := buildLayer(t,
+		tarEntry{name: "etc/.wh.passwd", typeflag: tar.TypeReg},
+	)
+	layers := []ocispec.Descriptor{
+		push(ocispec.MediaTypeImageLayerGzip, buf.Bytes()),
+		push(ocispec.MediaTypeImageLayer, upper),
+	}
+	manifestJSON, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    push(ocispec.MediaTypeImageConfig, []byte("{}")),
+		Layers:    layers,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestDesc := push(ocispec.MediaTypeImageManifest, manifestJSON)
+
+	dir := t.TempDir()
+	var applied []ocispec.Descriptor
+	opts := Options{
+		OnLayer: func(ctx context.Context, layer ocispec.Descriptor) error {
+			applied = append(applied, layer)
+			return nil
+		},
+	}
+	if err := Unpack(ctx, s, manifestDesc, dir, opts); err != nil {
+		t.Fatalf("Unpack() error = %v", err)
+	}
+	assertFile(t, dir, "etc/hosts", "hosts")
+	assertNotExist(t, dir, "etc/passwd")
+	if len(applied) != 2 || applied[0].Digest != layers[0].Digest || applied[1].Digest != layers[1].Digest {
+		t.Errorf("applied layers = %v, want %v", applied, layers)
+	}
+
+	// indexes are not unpacked
+	indexDesc := push(ocispec.MediaTypeImageIndex, []byte(`{"schemaVersion":2}`))
+	if err := Unpack(ctx, s, indexDesc, t.TempDir(), Options{}); err == nil {
+		t.Error("Unpack() error = nil, want error")
+	}
+}
@@ -0,0 +1,58 @@
+//go:build linux
+
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unpack
+
+import (
+	"strings"
+	"syscall"
+)
+
+// setXattr sets the extended attribute of the file.
+func setXattr(path, attr string, data []byte) error {
+	return syscall.Setxattr(path, attr, data, 0)
+}
+
+// getXattrs returns the extended attributes of the file.
+func getXattrs(path string) (map[string]string, error) {
+	size, err := syscall.Listxattr(path, nil)
+	if err != nil || size == 0 {
+		if err == syscall.ENOTSUP {
+			return nil, nil
+		}
+		return nil, err
+	}
+	buf := make([]byte, size)
+	size, err = syscall.Listxattr(path, buf)
+	if err != nil {
+		return nil, err
+	}
+	xattrs := make(map[string]string)
+	for _, attr := range strings.Split(strings.TrimRight(string(buf[:size]), "\x00"), "\x00") {
+		size, err := syscall.Getxattr(path, attr, nil)
+		if err != nil {
+			return nil, err
+		}
+		value := make([]byte, size)
+		size, err = syscall.Getxattr(path, attr, value)
+		if err != nil {
+			return nil, err
+		}
+		xattrs[attr] = string(value[:size])
+	}
+	return xattrs, nil
+}
@@ -0,0 +1,54 @@
+//go:build linux
+
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unpack
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestApplyLayer_Xattrs(t *testing.T) {
+	dir := t.TempDir()
+	layer := buildLayer(t,
+		tarEntry{
+			name:     "file",
+			typeflag: tar.TypeReg,
+			content:  "file",
+			pax:      map[string]string{"SCHILY.xattr.user.test": "value"},
+		},
+	)
+	if err := ApplyLayer(bytes.NewReader(layer), dir, Options{Xattrs: true}); err != nil {
+		if errors.Is(err, syscall.ENOTSUP) || errors.Is(err, syscall.EPERM) {
+			t.Skipf("extended attributes not supported: %v", err)
+		}
+		t.Fatalf("ApplyLayer() error = %v", err)
+	}
+
+	buf := make([]byte, 64)
+	n, err := syscall.Getxattr(filepath.Join(dir, "file"), "user.test", buf)
+	if err != nil {
+		t.Fatalf("Getxattr() error = %v", err)
+	}
+	if got := string(buf[:n]); got != "value" {
+		t.Errorf("Getxattr() = %q, want %q", got, "value")
+	}
+}
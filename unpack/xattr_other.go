@@ -0,0 +1,36 @@
+//go:build !linux
+
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unpack
+
+import (
+	"fmt"
+
+	"oras.land/oras-go/v2/errdef"
+)
+
+// setXattr returns ErrUnsupported since extended attributes are only
+// supported on Linux.
+func setXattr(path, attr string, _ []byte) error {
+	return fmt.Errorf("%s: extended attribute %s: %w", path, attr, errdef.ErrUnsupported)
+}
+
+// getXattrs returns no extended attributes since extended attributes are only
+// supported on Linux.
+func getXattrs(string) (map[string]string, error) {
+	return nil, nil
+}
@@ -0,0 +1,230 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/cas"
+	"oras.land/oras-go/v2/internal/container/set"
+	"oras.land/oras-go/v2/internal/descriptor"
+	"oras.land/oras-go/v2/internal/pool"
+	"oras.land/oras-go/v2/internal/syncutil"
+	"oras.land/oras-go/v2/registry"
+)
+
+// defaultVerifyGraphConcurrency is the default value of
+// VerifyGraphOptions.Concurrency.
+const defaultVerifyGraphConcurrency int = 3
+
+// VerifyGraphOptions contains parameters for [oras.VerifyGraph].
+type VerifyGraphOptions struct {
+	// Concurrency limits the maximum number of nodes verified concurrently.
+	// If less than or equal to 0, a default (currently 3) is used.
+	Concurrency int
+	// VerifyDigest, if true, fetches every blob to verify its size and
+	// digest. Otherwise, only the existence and the size of the blobs are
+	// verified, where the size is checked by HEAD requests on remote
+	// repositories. Manifests are always fetched and fully verified as they
+	// are needed to walk the graph.
+	VerifyDigest bool
+	// OnReport, if provided, is called with the verification report,
+	// whether or not the verification succeeds.
+	OnReport func(ctx context.Context, report *VerificationReport)
+}
+
+// VerificationFailure is a node failing the verification.
+type VerificationFailure struct {
+	// Descriptor describes the node.
+	Descriptor ocispec.Descriptor
+	// Err is the reason of the failure, such as errdef.ErrNotFound or
+	// content.ErrMismatchedDigest.
+	Err error
+}
+
+// VerificationReport is the result of verifying a graph.
+type VerificationReport struct {
+	// Root is the root node of the graph.
+	Root ocispec.Descriptor
+	// Verified are the nodes passing the verification.
+	Verified []ocispec.Descriptor
+	// Failures are the nodes failing the verification.
+	Failures []VerificationFailure
+}
+
+// OK reports whether all the nodes pass the verification.
+func (r *VerificationReport) OK() bool {
+	return len(r.Failures) == 0
+}
+
+// GraphVerificationError is returned when some nodes of a graph fail the
+// verification.
+type GraphVerificationError struct {
+	// Report is the verification report.
+	Report *VerificationReport
+}
+
+// Error returns the error message.
+func (e *GraphVerificationError) Error() string {
+	first := e.Report.Failures[0]
+	return fmt.Sprintf("%s: %d of %d nodes failed verification, first: %s: %v",
+		e.Report.Root.Digest,
+		len(e.Report.Failures),
+		len(e.Report.Failures)+len(e.Report.Verified),
+		first.Descriptor.Digest,
+		first.Err)
+}
+
+// VerifyGraph walks the graph rooted at root in the storage, verifying the
+// existence, the size and optionally the digest of every node.
+// The returned report lists the nodes failing the verification, which do
+// not make VerifyGraph return an error. Successors of manifests failing the
+// verification are not walked.
+func VerifyGraph(ctx context.Context, storage content.ReadOnlyStorage, root ocispec.Descriptor, opts VerifyGraphOptions) (*VerificationReport, error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultVerifyGraphConcurrency
+	}
+	report := &VerificationReport{Root: root}
+	var mu sync.Mutex // protects report and level
+	visited := set.New[descriptor.Descriptor]()
+	// manifests are cached to walk the graph after being verified
+	proxy := cas.NewProxy(storage, cas.NewMemory())
+
+	level := []ocispec.Descriptor{root}
+	visited.Add(descriptor.FromOCI(root))
+	for len(level) > 0 {
+		var next []ocispec.Descriptor
+		eg, egCtx := syncutil.LimitGroup(ctx, concurrency)
+		for _, node := range level {
+			node := node
+			eg.Go(func() error {
+				successors, err := verifyNode(egCtx, storage, proxy, node, opts.VerifyDigest)
+				if ctxErr := egCtx.Err(); ctxErr != nil {
+					return ctxErr
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					report.Failures = append(report.Failures, VerificationFailure{
+						Descriptor: node,
+						Err:        err,
+					})
+					return nil
+				}
+				report.Verified = append(report.Verified, node)
+				next = append(next, successors...)
+				return nil
+			})
+		}
+		if err := eg.Wait(); err != nil {
+			return nil, err
+		}
+
+		level = level[:0]
+		for _, node := range next {
+			key := descriptor.FromOCI(node)
+			if visited.Contains(key) {
+				continue
+			}
+			visited.Add(key)
+			level = append(level, node)
+		}
+	}
+	return report, nil
+}
+
+// blobStoreProvider is implemented by storages exposing their blob store,
+// such as remote repositories.
+type blobStoreProvider interface {
+	Blobs() registry.BlobStore
+}
+
+// verifyNode verifies a node and returns its successors.
+func verifyNode(ctx context.Context, storage content.ReadOnlyStorage, proxy *cas.Proxy, node ocispec.Descriptor, verifyDigest bool) ([]ocispec.Descriptor, error) {
+	if descriptor.IsManifest(node) {
+		// FetchAll verifies the size and the digest
+		if _, err := content.FetchAll(ctx, proxy, node); err != nil {
+			return nil, err
+		}
+		return content.Successors(ctx, proxy, node)
+	}
+	if verifyDigest {
+		rc, err := storage.Fetch(ctx, node)
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		vr := content.NewVerifyReader(rc, node)
+		if _, err := pool.Stream.Discard(vr); err != nil {
+			return nil, err
+		}
+		return nil, vr.Verify()
+	}
+
+	if provider, ok := storage.(blobStoreProvider); ok {
+		desc, err := provider.Blobs().Resolve(ctx, node.Digest.String())
+		if err != nil {
+			return nil, err
+		}
+		if desc.Size != node.Size {
+			return nil, fmt.Errorf("%s: %s: mismatched size %d, want %d", node.Digest, node.MediaType, desc.Size, node.Size)
+		}
+		return nil, nil
+	}
+	exists, err := storage.Exists(ctx, node)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s: %s: %w", node.Digest, node.MediaType, errdef.ErrNotFound)
+	}
+	return nil, nil
+}
+
+// WithGraphVerification configures opts.VerifyRoot to verify the whole graph
+// copied to the destination by VerifyGraph before the root node is tagged.
+// The copy fails with a *GraphVerificationError if any node fails the
+// verification. An existing VerifyRoot is called after the graph is verified.
+func (opts *CopyOptions) WithGraphVerification(verifyOpts VerifyGraphOptions) {
+	verifyRoot := opts.VerifyRoot
+	opts.VerifyRoot = func(ctx context.Context, fetcher content.Fetcher, root ocispec.Descriptor) error {
+		storage, ok := fetcher.(content.ReadOnlyStorage)
+		if !ok {
+			return errors.New("graph verification requires a readable storage destination")
+		}
+		report, err := VerifyGraph(ctx, storage, root, verifyOpts)
+		if err != nil {
+			return err
+		}
+		if verifyOpts.OnReport != nil {
+			verifyOpts.OnReport(ctx, report)
+		}
+		if !report.OK() {
+			return &GraphVerificationError{Report: report}
+		}
+		if verifyRoot != nil {
+			return verifyRoot(ctx, fetcher, root)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+func TestVerifyGraph(t *testing.T) {
+	ctx := context.Background()
+	store, manifestDesc := pushImage(t, []byte(`{}`), []byte("foo"), "latest")
+
+	for _, verifyDigest := range []bool{false, true} {
+		report, err := VerifyGraph(ctx, store, manifestDesc, VerifyGraphOptions{VerifyDigest: verifyDigest})
+		if err != nil {
+			t.Fatalf("VerifyGraph() error = %v", err)
+		}
+		if !report.OK() {
+			t.Errorf("VerifyGraph() failures = %v, want none", report.Failures)
+		}
+		if got, want := len(report.Verified), 3; got != want {
+			t.Errorf("VerifyGraph() verified %d nodes, want %d", got, want)
+		}
+	}
+}
+
+func TestVerifyGraph_Missing(t *testing.T) {
+	ctx := context.Background()
+	src, manifestDesc := pushImage(t, []byte(`{}`), []byte("foo"), "latest")
+	successors, err := content.Successors(ctx, src, manifestDesc)
+	if err != nil {
+		t.Fatalf("content.Successors() error = %v", err)
+	}
+
+	// copy the manifest and the config only
+	dst := memory.New()
+	for _, desc := range []ocispec.Descriptor{successors[0], manifestDesc} {
+		blob, err := content.FetchAll(ctx, src, desc)
+		if err != nil {
+			t.Fatalf("content.FetchAll() error = %v", err)
+		}
+		if err := dst.Push(ctx, desc, bytes.NewReader(blob)); err != nil {
+			t.Fatalf("Store.Push() error = %v", err)
+		}
+	}
+
+	report, err := VerifyGraph(ctx, dst, manifestDesc, VerifyGraphOptions{})
+	if err != nil {
+		t.Fatalf("VerifyGraph() error = %v", err)
+	}
+	if report.OK() {
+		t.Fatal("VerificationReport.OK() = true, want false")
+	}
+	if len(report.Failures) != 1 {
+		t.Fatalf("VerifyGraph() failures = %v, want 1", report.Failures)
+	}
+	failure := report.Failures[0]
+	if !content.Equal(failure.Descriptor, successors[1]) || !errors.Is(failure.Err, errdef.ErrNotFound) {
+		t.Errorf("VerifyGraph() failure = %v, want %v with %v", failure, successors[1], errdef.ErrNotFound)
+	}
+}
+
+// corruptStorage is a memory store serving corrupted content for a digest.
+type corruptStorage struct {
+	*memory.Store
+	corrupt ocispec.Descriptor
+}
+
+func (s *corruptStorage) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	if target.Digest == s.corrupt.Digest {
+		return io.NopCloser(bytes.NewReader(make([]byte, target.Size))), nil
+	}
+	return s.Store.Fetch(ctx, target)
+}
+
+func TestCopy_WithGraphVerification(t *testing.T) {
+	ctx := context.Background()
+	src, manifestDesc := pushImage(t, []byte(`{}`), []byte("foo"), "latest")
+	successors, err := content.Successors(ctx, src, manifestDesc)
+	if err != nil {
+		t.Fatalf("content.Successors() error = %v", err)
+	}
+
+	// successful copy
+	dst := memory.New()
+	var report *VerificationReport
+	opts := CopyOptions{}
+	opts.WithGraphVerification(VerifyGraphOptions{
+		VerifyDigest: true,
+		OnReport: func(_ context.Context, r *VerificationReport) {
+			report = r
+		},
+	})
+	if _, err := Copy(ctx, src, "latest", dst, "", opts); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if report == nil || !report.OK() || len(report.Verified) != 3 {
+		t.Errorf("Copy() verification report = %v, want 3 verified nodes", report)
+	}
+
+	// corrupted destination is not tagged
+	corrupted := &corruptStorage{Store: memory.New(), corrupt: successors[1]}
+	_, err = Copy(ctx, src, "latest", corrupted, "", opts)
+	var verifyErr *GraphVerificationError
+	if !errors.As(err, &verifyErr) {
+		t.Fatalf("Copy() error = %v, want %T", err, verifyErr)
+	}
+	if got := verifyErr.Report.Failures[0].Descriptor; !content.Equal(got, successors[1]) {
+		t.Errorf("GraphVerificationError failure = %v, want %v", got, successors[1])
+	}
+	if !errors.Is(verifyErr.Report.Failures[0].Err, content.ErrMismatchedDigest) {
+		t.Errorf("GraphVerificationError failure error = %v, want %v", verifyErr.Report.Failures[0].Err, content.ErrMismatchedDigest)
+	}
+	if _, err := corrupted.Resolve(ctx, "latest"); !errors.Is(err, errdef.ErrNotFound) {
+		t.Errorf("Store.Resolve() error = %v, want %v", err, errdef.ErrNotFound)
+	}
+}
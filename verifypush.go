@@ -0,0 +1,104 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+)
+
+// PushVerificationError is returned when the content pushed to the
+// destination of a copy fails the verification.
+// See also CopyGraphOptions.VerifyPushed.
+type PushVerificationError struct {
+	// Descriptor describes the content failing the verification.
+	Descriptor ocispec.Descriptor
+	// Err is the reason of the failure, such as errdef.ErrNotFound or
+	// content.ErrMismatchedDigest.
+	Err error
+}
+
+// Error returns the error message.
+func (e *PushVerificationError) Error() string {
+	return fmt.Sprintf("%s: %s: pushed content failed verification: %v", e.Descriptor.Digest, e.Descriptor.MediaType, e.Err)
+}
+
+// Unwrap returns the reason of the failure.
+func (e *PushVerificationError) Unwrap() error {
+	return e.Err
+}
+
+// verifyingStorage is a storage verifying the content after pushing it.
+type verifyingStorage struct {
+	content.Storage
+}
+
+// Push pushes the content to the storage, and verifies it on success.
+func (s *verifyingStorage) Push(ctx context.Context, expected ocispec.Descriptor, content io.Reader) error {
+	if err := s.Storage.Push(ctx, expected, content); err != nil {
+		return err
+	}
+	return verifyPushed(ctx, s.Storage, expected)
+}
+
+// verifyPushed verifies the content described by desc in the storage.
+// Manifests are fetched to verify their sizes and digests, while only the
+// existence and the sizes of blobs are verified, where the sizes are checked
+// by HEAD requests on remote repositories.
+// Returns a PushVerificationError if the verification fails.
+func verifyPushed(ctx context.Context, storage content.ReadOnlyStorage, desc ocispec.Descriptor) error {
+	err := doVerifyPushed(ctx, storage, desc)
+	if err != nil && ctx.Err() == nil {
+		return &PushVerificationError{
+			Descriptor: desc,
+			Err:        err,
+		}
+	}
+	return err
+}
+
+// doVerifyPushed verifies the content described by desc in the storage.
+func doVerifyPushed(ctx context.Context, storage content.ReadOnlyStorage, desc ocispec.Descriptor) error {
+	if descriptor.IsManifest(desc) {
+		// FetchAll verifies the size and the digest
+		_, err := content.FetchAll(ctx, storage, desc)
+		return err
+	}
+	if provider, ok := storage.(blobStoreProvider); ok {
+		got, err := provider.Blobs().Resolve(ctx, desc.Digest.String())
+		if err != nil {
+			return err
+		}
+		if got.Size != desc.Size {
+			return fmt.Errorf("mismatched size %d, want %d", got.Size, desc.Size)
+		}
+		return nil
+	}
+	exists, err := storage.Exists(ctx, desc)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errdef.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright The ORAS Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oras
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/internal/descriptor"
+)
+
+// corruptingStorage is a storage silently dropping the pushed blobs and
+// corrupting the fetched manifests.
+type corruptingStorage struct {
+	*memory.Store
+	dropBlobs        bool
+	corruptManifests bool
+}
+
+func (s *corruptingStorage) Push(ctx context.Context, expected ocispec.Descriptor, r io.Reader) error {
+	if s.dropBlobs && !descriptor.IsManifest(expected) {
+		_, err := io.Copy(io.Discard, r)
+		return err
+	}
+	return s.Store.Push(ctx, expected, r)
+}
+
+func (s *corruptingStorage) Fetch(ctx context.Context, target ocispec.Descriptor) (io.ReadCloser, error) {
+	if s.corruptManifests && descriptor.IsManifest(target) {
+		return io.NopCloser(bytes.NewReader(bytes.Repeat([]byte("x"), int(target.Size)))), nil
+	}
+	return s.Store.Fetch(ctx, target)
+}
+
+func TestCopyGraph_VerifyPushed(t *testing.T) {
+	ctx := context.Background()
+	config := []byte("{}")
+	src, manifestDesc := pushImage(t, config, []byte("layer"), "image")
+	configDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageConfig, config)
+
+	tests := []struct {
+		name     string
+		dst      *corruptingStorage
+		wantDesc ocispec.Descriptor
+		wantErr  error
+	}{
+		{
+			name: "verified",
+			dst:  &corruptingStorage{Store: memory.New()},
+		},
+		{
+			name:     "blob dropped",
+			dst:      &corruptingStorage{Store: memory.New(), dropBlobs: true},
+			wantDesc: configDesc,
+			wantErr:  errdef.ErrNotFound,
+		},
+		{
+			name:     "manifest corrupted",
+			dst:      &corruptingStorage{Store: memory.New(), corruptManifests: true},
+			wantDesc: manifestDesc,
+			wantErr:  content.ErrMismatchedDigest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := CopyGraphOptions{
+				Concurrency:  1,
+				VerifyPushed: true,
+			}
+			err := CopyGraph(ctx, src, tt.dst, manifestDesc, opts)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatal("CopyGraph() error =", err)
+				}
+				return
+			}
+			var verifyErr *PushVerificationError
+			if !errors.As(err, &verifyErr) {
+				t.Fatalf("CopyGraph() error = %v, want PushVerificationError", err)
+			}
+			if verifyErr.Descriptor.Digest != tt.wantDesc.Digest {
+				t.Errorf("PushVerificationError.Descriptor = %v, want %v", verifyErr.Descriptor.Digest, tt.wantDesc.Digest)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CopyGraph() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}